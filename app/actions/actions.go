@@ -0,0 +1,168 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+// Package actions implements the turn actions from php/pages/farm.php,
+// php/pages/cash.php, and php/pages/land.php.
+//
+// Each action spends a batch of turns focusing on one thing: farming produces
+// more food, making money earns more cash, and exploring gains land. The batch
+// stops early if the World Bank refuses an emergency loan or the empire runs
+// out of food.
+package actions
+
+import (
+	"fmt"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/orm"
+	"time"
+)
+
+// Config_t holds the settings from config.php used by the turn actions.
+type Config_t struct {
+	ClanEnable bool // Master enable for clans, clans at war pay war tax every turn
+}
+
+// Actions_t takes turns against the database.
+type Actions_t struct {
+	db     *orm.DB
+	e      *engine.Engine_t
+	tables *engine.Tables_t
+	cfg    Config_t
+}
+
+// New returns a turn action service.
+func New(db *orm.DB, e *engine.Engine_t, tables *engine.Tables_t, cfg Config_t) (*Actions_t, error) {
+	if db == nil {
+		return nil, fmt.Errorf("missing database")
+	} else if e == nil {
+		return nil, fmt.Errorf("missing engine")
+	} else if tables == nil {
+		return nil, fmt.Errorf("missing tables")
+	}
+	return &Actions_t{db: db, e: e, tables: tables, cfg: cfg}, nil
+}
+
+// prefixes maps the actions to the prefix of their message keys.
+var prefixes = map[engine.Action_t]string{
+	engine.ACTION_CASH: "CASH",
+	engine.ACTION_FARM: "FARM",
+	engine.ACTION_LAND: "LAND",
+}
+
+// State_t describes the turns the empire can spend.
+type State_t struct {
+	Turns   int
+	Tax     int // tax rate, which determines whether residents are leaving or arriving
+	Explore int // acres gained by the next turn spent exploring
+}
+
+// State returns the turns the empire can spend.
+func (a *Actions_t) State(empireId int, now time.Time) (*State_t, error) {
+	var state *State_t
+	err := a.db.Transaction(func(tx *orm.DB) error {
+		emp, err := tx.EmpireFetch(empireId)
+		if err != nil {
+			return err
+		}
+		fx, err := tx.EmpireEffectsFetch(emp.Id, a.tables.Effects)
+		if err != nil {
+			return err
+		}
+		state = a.state(emp, fx, now)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// Result_t is the result of taking turns.
+type Result_t struct {
+	Report   *engine.Report_t   // nil if no turns were taken
+	Messages []engine.Message_t // why the turns were or were not taken
+	State    *State_t           // turns the empire can spend afterward
+}
+
+// Take spends turns on farming, making money, or exploring.
+// It stops early if the empire is refused a loan or runs out of food.
+func (a *Actions_t) Take(empireId int, round model.RoundData_t, action engine.Action_t, turns int, now time.Time) (*Result_t, error) {
+	prefix, ok := prefixes[action]
+	if !ok {
+		return nil, cerr.ErrUnknownAction
+	} else if round.Finished {
+		return nil, cerr.ErrRoundFinished
+	} else if !round.Started {
+		return nil, cerr.ErrRoundNotStarted
+	}
+	res := &Result_t{}
+	err := a.db.Transaction(func(tx *orm.DB) error {
+		emp, err := tx.EmpireFetch(empireId)
+		if err != nil {
+			return err
+		}
+		fx, err := tx.EmpireEffectsFetch(emp.Id, a.tables.Effects)
+		if err != nil {
+			return err
+		}
+		res.State = a.state(emp, fx, now)
+		if turns < 0 || turns > emp.Turns {
+			res.Messages = append(res.Messages, engine.Message_t{Key: prefix + "_NOT_ENOUGH_TURNS"})
+			return nil
+		} else if turns == 0 {
+			res.Messages = append(res.Messages, engine.Message_t{Key: prefix + "_SPECIFY_TURNS"})
+			return nil
+		}
+
+		var wars int
+		if a.cfg.ClanEnable && emp.CId != 0 {
+			// the passive war tax only counts wars the clan declared
+			if wars, err = tx.ClanWarsDeclared(emp.CId); err != nil {
+				return err
+			}
+		}
+		updated, report := a.e.TakeTurns(*emp, a.tables.Modifiers(emp), engine.TakeTurns_t{
+			Turns:         turns,
+			Action:        action,
+			Interruptable: true,
+			Wars:          wars,
+			Round:         round,
+			Effects:       fx,
+			Now:           now,
+		})
+		*emp = updated
+		if err := tx.EmpireAttributesUpdate(emp); err != nil {
+			return err
+		} else if err := tx.EmpireEffectsSave(fx); err != nil {
+			return err
+		}
+		res.Report = report
+		if action == engine.ACTION_FARM {
+			// the message names the food of the empire's era
+			era, err := a.tables.Era(emp.Era)
+			if err != nil {
+				return err
+			}
+			res.Messages = append(res.Messages, engine.Message_t{Key: "FARM_COMPLETE", Args: []any{report.Result, era.Food, report.Taken}})
+		} else {
+			res.Messages = append(res.Messages, engine.Message_t{Key: prefix + "_COMPLETE", Args: []any{report.Result, report.Taken}})
+		}
+		res.State = a.state(emp, fx, now)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// state returns the turns the empire can spend.
+func (a *Actions_t) state(emp *model.Empire_t, fx *engine.Effects_t, now time.Time) *State_t {
+	mods := a.tables.Modifiers(emp).Add(fx.Modifiers(now))
+	return &State_t{
+		Turns:   emp.Turns,
+		Tax:     emp.Tax,
+		Explore: a.e.GiveLand(emp, mods),
+	}
+}
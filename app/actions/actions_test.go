@@ -0,0 +1,97 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package actions
+
+import (
+	"errors"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/orm"
+	"testing"
+	"time"
+)
+
+func TestTake(t *testing.T) {
+	db, err := orm.CreateMemoryDatabase()
+	if err != nil {
+		t.Fatalf("database: %v", err)
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+	e := engine.New(engine.Config_t{IndustryMult: 2.5}, nil)
+	a, err := New(db, e, engine.DefaultTables(), Config_t{})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	user, err := db.UserCreate("farmer", "farmer@example.com")
+	if err != nil {
+		t.Fatalf("user: %v", err)
+	}
+	emp, err := db.EmpireCreate(user, "farmer", "HUMAN")
+	if err != nil {
+		t.Fatalf("empire: %v", err)
+	}
+	emp.Era, emp.Turns, emp.Land, emp.Freeland = engine.ERA_PAST, 10, 250, 100
+	if err := db.EmpireAttributesUpdate(emp); err != nil {
+		t.Fatalf("empire: %v", err)
+	}
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	started := model.RoundData_t{Started: true}
+
+	if _, err := a.Take(emp.Id, started, engine.ACTION_MAGIC, 1, now); !errors.Is(err, cerr.ErrUnknownAction) {
+		t.Errorf("magic: want %v, got %v", cerr.ErrUnknownAction, err)
+	}
+	if _, err := a.Take(emp.Id, model.RoundData_t{}, engine.ACTION_FARM, 1, now); !errors.Is(err, cerr.ErrRoundNotStarted) {
+		t.Errorf("not started: want %v, got %v", cerr.ErrRoundNotStarted, err)
+	}
+	for _, tc := range []struct {
+		turns int
+		want  string
+	}{
+		{0, "FARM_SPECIFY_TURNS"},
+		{11, "FARM_NOT_ENOUGH_TURNS"},
+	} {
+		res, err := a.Take(emp.Id, started, engine.ACTION_FARM, tc.turns, now)
+		if err != nil {
+			t.Fatalf("farm %d: %v", tc.turns, err)
+		} else if res.Report != nil || len(res.Messages) != 1 || res.Messages[0].Key != tc.want {
+			t.Errorf("farm %d: want %s, got %+v", tc.turns, tc.want, res)
+		}
+	}
+
+	res, err := a.Take(emp.Id, started, engine.ACTION_LAND, 3, now)
+	if err != nil {
+		t.Fatalf("explore: %v", err)
+	} else if res.Report == nil || res.Report.Taken != 3 || len(res.Report.Turns) != 3 || res.Report.Halted {
+		t.Fatalf("explore: got %+v", res.Report)
+	} else if len(res.Messages) != 1 || res.Messages[0].Key != "LAND_COMPLETE" || res.Messages[0].Args[0] != res.Report.Result {
+		t.Errorf("explore: messages: got %+v", res.Messages)
+	} else if res.State.Turns != 7 {
+		t.Errorf("explore: want 7 turns left, got %d", res.State.Turns)
+	}
+	if got, err := db.EmpireFetch(emp.Id); err != nil {
+		t.Fatalf("fetch: %v", err)
+	} else if got.Land != 250+res.Report.Result || got.Turns != 7 || got.TurnsUsed != emp.TurnsUsed+3 {
+		t.Errorf("empire: got land %d, turns %d, used %d", got.Land, got.Turns, got.TurnsUsed)
+	}
+
+	// an empire without food stops after the first turn
+	starving, err := db.EmpireFetch(emp.Id)
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	starving.Food, starving.TrpArm = 0, 1_000_000
+	if err := db.EmpireAttributesUpdate(starving); err != nil {
+		t.Fatalf("empire: %v", err)
+	}
+	res, err = a.Take(emp.Id, started, engine.ACTION_FARM, 5, now)
+	if err != nil {
+		t.Fatalf("farm: %v", err)
+	} else if !res.Report.Halted || res.Report.Taken != 1 || res.Report.Trouble&engine.TURNS_TROUBLE_FOOD == 0 {
+		t.Errorf("farm: want halted after 1 turn, got %+v", res.Report)
+	} else if res.Messages[0].Key != "FARM_COMPLETE" || res.Messages[0].Args[1] != "ERA_PAST_FOOD" {
+		t.Errorf("farm: messages: got %+v", res.Messages)
+	}
+}
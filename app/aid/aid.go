@@ -0,0 +1,285 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+// Package aid implements foreign aid from php/pages/aid.php.
+//
+// Empires send troops, cash, runes, and food to other empires. Every shipment
+// takes two turns and needs a convoy of ships that grows with networth.
+// Shipments use aid credits, which are earned back over time; aid sent to
+// clanmates and allies is free until the round is closing. Ships beyond the
+// convoy are delivered with the goods, and the recipient sends back any of the
+// convoy that was not delivered when it receives its news.
+//
+// Clan officers can review the aid sent between members of their clan.
+package aid
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/orm"
+	"slices"
+	"time"
+)
+
+// Config_t holds the settings from config.php used by foreign aid.
+type Config_t struct {
+	ClanEnable  bool // Master enable for clans
+	ClanViewAid bool // Display summary of all foreign aid sent between clan members
+}
+
+// Aid_t sends foreign aid against the database.
+type Aid_t struct {
+	db     *orm.DB
+	e      *engine.Engine_t
+	tables *engine.Tables_t
+	cfg    Config_t
+}
+
+// New returns a foreign aid service.
+func New(db *orm.DB, e *engine.Engine_t, tables *engine.Tables_t, cfg Config_t) (*Aid_t, error) {
+	if db == nil {
+		return nil, fmt.Errorf("missing database")
+	} else if e == nil {
+		return nil, fmt.Errorf("missing engine")
+	} else if tables == nil {
+		return nil, fmt.Errorf("missing tables")
+	}
+	return &Aid_t{db: db, e: e, tables: tables, cfg: cfg}, nil
+}
+
+// State_t describes what the empire can send.
+type State_t struct {
+	Owned   engine.Cargo_t // goods held by the empire
+	CanSend engine.Cargo_t // most of each type of goods that can be sent in one shipment
+	Convoy  int            // ships needed to carry a shipment
+	Credits int            // shipments that can be sent right now
+	Turns   int
+}
+
+// State returns what the empire can send.
+func (a *Aid_t) State(empireId int, round model.RoundData_t, now time.Time) (*State_t, error) {
+	var state *State_t
+	err := a.db.Transaction(func(tx *orm.DB) error {
+		emp, err := tx.EmpireFetch(empireId)
+		if err != nil {
+			return err
+		} else if err := a.available(emp, round); err != nil {
+			return err
+		}
+		fx, err := tx.EmpireEffectsFetch(emp.Id, a.tables.Effects)
+		if err != nil {
+			return err
+		}
+		state = a.state(emp, fx, now)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// Result_t is the result of sending foreign aid.
+type Result_t struct {
+	Sent     bool           // false if the shipment was refused
+	Cargo    engine.Cargo_t // goods sent
+	Report   *engine.Report_t
+	Messages []engine.Message_t // why the shipment was or was not sent
+	State    *State_t           // what the empire can send after the shipment
+}
+
+// Send sends a shipment of foreign aid from the empire to the target.
+func (a *Aid_t) Send(empireId int, round model.RoundData_t, targetId int, cargo engine.Cargo_t, now time.Time) (*Result_t, error) {
+	res := &Result_t{}
+	err := a.db.Transaction(func(tx *orm.DB) error {
+		src, err := tx.EmpireFetch(empireId)
+		if err != nil {
+			return err
+		} else if err := a.available(src, round); err != nil {
+			return err
+		}
+		srcFx, err := tx.EmpireEffectsFetch(src.Id, a.tables.Effects)
+		if err != nil {
+			return err
+		}
+		res.State = a.state(src, srcFx, now)
+
+		var dst *model.Empire_t
+		if targetId != 0 {
+			if dst, err = tx.EmpireFetch(targetId); err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+		}
+		if dst == nil {
+			res.Messages = append(res.Messages, engine.Message_t{Key: "INPUT_EMPIRE_ID"})
+			return nil
+		}
+		dstFx, err := tx.EmpireEffectsFetch(dst.Id, a.tables.Effects)
+		if err != nil {
+			return err
+		}
+
+		req := engine.Aid_t{
+			Cargo:            cargo,
+			SameClan:         src.CId != 0 && src.CId == dst.CId,
+			Tables:           a.tables,
+			SenderEffects:    srcFx,
+			RecipientEffects: dstFx,
+			Round:            round,
+			Now:              now,
+		}
+		if a.cfg.ClanEnable && src.CId != 0 {
+			allies, err := tx.ClanAllies(src.CId)
+			if err != nil {
+				return err
+			}
+			wars, err := tx.ClanWars(src.CId)
+			if err != nil {
+				return err
+			}
+			declared, err := tx.ClanWarsDeclared(src.CId)
+			if err != nil {
+				return err
+			}
+			req.Allied, req.War, req.Wars = slices.Contains(allies, dst.CId), slices.Contains(wars, dst.CId), declared
+		}
+
+		updatedSrc, updatedDst, result, err := a.e.SendAid(*src, *dst, req)
+		if err != nil {
+			return err
+		}
+		res.Sent, res.Cargo, res.Report, res.Messages = result.Sent, result.Cargo, result.Report, result.Messages
+		if !result.Sent {
+			return nil
+		}
+		*src, *dst = updatedSrc, updatedDst
+		src.NetWorth, dst.NetWorth = a.e.Networth(src), a.e.Networth(dst)
+		if err := tx.EmpireAttributesUpdate(src); err != nil {
+			return err
+		} else if err := tx.EmpireAttributesUpdate(dst); err != nil {
+			return err
+		} else if err := tx.EmpireEffectsSave(srcFx); err != nil {
+			return err
+		} else if err := tx.EmpireNewsCreate(now, result.News...); err != nil {
+			return err
+		}
+		// the convoy depends on the new networth
+		res.State = a.state(src, srcFx, now)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ClanAid_t is a shipment of foreign aid sent between members of a clan.
+type ClanAid_t struct {
+	Time      time.Time
+	Sender    *model.Empire_t
+	Recipient *model.Empire_t
+	Cargo     engine.Cargo_t
+}
+
+// ClanAid returns the foreign aid sent between members of the empire's clan,
+// oldest first, from printClanAid in php/includes/news.php.
+// Only the clan's leader, assistant, and ministers may view it.
+func (a *Aid_t) ClanAid(empireId int, round model.RoundData_t) ([]*ClanAid_t, error) {
+	var list []*ClanAid_t
+	err := a.db.Transaction(func(tx *orm.DB) error {
+		emp, err := tx.EmpireFetch(empireId)
+		if err != nil {
+			return err
+		}
+		if round.Finished {
+			return cerr.ErrRoundFinished
+		} else if !round.Started {
+			return cerr.ErrRoundNotStarted
+		} else if a.e.IsProtected(emp, round) {
+			return cerr.ErrEmpireProtected
+		} else if emp.Flags.Admin {
+			return cerr.ErrEmpireAdmin
+		} else if !a.cfg.ClanEnable {
+			return cerr.ErrClansDisabled
+		} else if !a.cfg.ClanViewAid {
+			return cerr.ErrClanAidHidden
+		} else if emp.CId == 0 {
+			return cerr.ErrNotClanMember
+		}
+		clan, err := tx.ClanFetch(emp.CId)
+		if err != nil {
+			return err
+		} else if !slices.Contains([]int{clan.Leader, clan.Assistant, clan.Minister1, clan.Minister2}, emp.Id) {
+			return cerr.ErrNotClanOfficer
+		}
+
+		shipments, err := tx.ClanAid(clan.Id)
+		if err != nil {
+			return err
+		}
+		empires := map[int]*model.Empire_t{}
+		load := func(id int) (*model.Empire_t, error) {
+			if emp, ok := empires[id]; ok {
+				return emp, nil
+			}
+			emp, err := tx.EmpireFetch(id)
+			if err != nil {
+				return nil, err
+			}
+			empires[id] = emp
+			return emp, nil
+		}
+		for _, shipment := range shipments {
+			aid := &ClanAid_t{Time: shipment.Time, Cargo: shipment.Cargo}
+			if aid.Sender, err = load(shipment.Sender); err != nil {
+				return err
+			} else if aid.Recipient, err = load(shipment.Recipient); err != nil {
+				return err
+			}
+			list = append(list, aid)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// available returns an error if the empire may not use the foreign aid page.
+func (a *Aid_t) available(emp *model.Empire_t, round model.RoundData_t) error {
+	if round.Finished {
+		return cerr.ErrRoundFinished
+	} else if !round.Started {
+		return cerr.ErrRoundNotStarted
+	} else if a.e.IsProtected(emp, round) {
+		return cerr.ErrEmpireProtected
+	} else if emp.Flags.Admin {
+		return cerr.ErrEmpireAdmin
+	} else if !a.e.AidEnabled() {
+		return cerr.ErrAidDisabled
+	}
+	return nil
+}
+
+// state returns what the empire can send.
+func (a *Aid_t) state(emp *model.Empire_t, fx *engine.Effects_t, now time.Time) *State_t {
+	return &State_t{
+		Owned: engine.Cargo_t{
+			TrpArm: emp.TrpArm,
+			TrpLnd: emp.TrpLnd,
+			TrpFly: emp.TrpFly,
+			TrpSea: emp.TrpSea,
+			Cash:   emp.Cash,
+			Runes:  emp.Runes,
+			Food:   emp.Food,
+		},
+		CanSend: a.e.AidCanSend(emp),
+		Convoy:  a.e.AidConvoy(emp),
+		Credits: a.e.AidCredits(fx, now),
+		Turns:   emp.Turns,
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aid
+
+import (
+	"errors"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/news"
+	"github.com/mdhender/promisance/app/orm"
+	"testing"
+	"time"
+)
+
+func TestAid(t *testing.T) {
+	db, err := orm.CreateMemoryDatabase()
+	if err != nil {
+		t.Fatalf("database: %v", err)
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+	e := engine.New(engine.Config_t{AidEnable: true, AidMaxCredits: 5, AidDelay: 60 * 60, VacationStart: 12 * time.Hour, PvtmTrpArm: 500, PvtmTrpSea: 3000}, nil)
+	tables := engine.DefaultTables()
+	a, err := New(db, e, tables, Config_t{})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	var ids []int
+	for _, name := range []string{"sender", "recipient"} {
+		user, err := db.UserCreate(name, name+"@example.com")
+		if err != nil {
+			t.Fatalf("user: %v", err)
+		}
+		emp, err := db.EmpireCreate(user, name, "HUMAN")
+		if err != nil {
+			t.Fatalf("empire: %v", err)
+		}
+		emp.Era, emp.Turns, emp.Land, emp.Cash, emp.TrpSea = engine.ERA_PAST, 50, 250, 100000, 10000
+		emp.NetWorth = e.Networth(emp)
+		if err := db.EmpireAttributesUpdate(emp); err != nil {
+			t.Fatalf("empire: %v", err)
+		}
+		ids = append(ids, emp.Id)
+	}
+	sender, recipient := ids[0], ids[1]
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	started := model.RoundData_t{Started: true}
+
+	if _, err := a.Send(sender, model.RoundData_t{Finished: true}, recipient, engine.Cargo_t{}, now); !errors.Is(err, cerr.ErrRoundFinished) {
+		t.Errorf("finished: want %v, got %v", cerr.ErrRoundFinished, err)
+	}
+	state, err := a.State(sender, started, now)
+	if err != nil {
+		t.Fatalf("state: %v", err)
+	} else if state.Credits != 5 || state.CanSend.Cash != 20000 || state.CanSend.TrpSea != 2000 {
+		t.Errorf("state: got %+v", state)
+	}
+	if res, err := a.Send(sender, started, 0, engine.Cargo_t{Cash: 1000}, now); err != nil {
+		t.Fatalf("send: %v", err)
+	} else if res.Sent || len(res.Messages) != 1 || res.Messages[0].Key != "INPUT_EMPIRE_ID" {
+		t.Errorf("send: no target: got %+v", res)
+	}
+
+	res, err := a.Send(sender, started, recipient, engine.Cargo_t{Cash: 10000}, now)
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	} else if !res.Sent || res.Cargo.Cash != 10000 || res.State.Credits != 4 {
+		t.Errorf("send: got %+v", res)
+	}
+	if got, err := db.EmpireFetch(recipient); err != nil {
+		t.Fatalf("fetch: %v", err)
+	} else if got.Cash != 110000 {
+		t.Errorf("recipient: want cash 110000, got %d", got.Cash)
+	}
+	if got, err := db.EmpireFetch(sender); err != nil {
+		t.Fatalf("fetch: %v", err)
+	} else if got.Turns != 48 {
+		t.Errorf("sender: want 48 turns, got %d", got.Turns)
+	}
+
+	// the shipment is delivered with the news; nothing needs to be returned
+	if _, err := news.Give(db, e, tables, recipient, now); err != nil {
+		t.Fatalf("news: %v", err)
+	}
+
+	if _, err := a.ClanAid(sender, started); !errors.Is(err, cerr.ErrClansDisabled) {
+		t.Errorf("clan aid: want %v, got %v", cerr.ErrClansDisabled, err)
+	}
+}
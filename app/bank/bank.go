@@ -0,0 +1,130 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+// Package bank implements the World Bank from php/pages/bank.php.
+//
+// Empires can keep savings and take out loans, each limited by networth.
+// Interest is paid and charged every turn the empire takes, at rates that
+// depend on the empire's size, and savings do not earn interest while the
+// empire is protected. Loans are not available once the round is closing.
+package bank
+
+import (
+	"fmt"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/orm"
+)
+
+// Bank_t runs the bank against the database.
+type Bank_t struct {
+	db *orm.DB
+	e  *engine.Engine_t
+}
+
+// New returns a bank.
+func New(db *orm.DB, e *engine.Engine_t) (*Bank_t, error) {
+	if db == nil {
+		return nil, fmt.Errorf("missing database")
+	} else if e == nil {
+		return nil, fmt.Errorf("missing engine")
+	}
+	return &Bank_t{db: db, e: e}, nil
+}
+
+// Action_t is a bank transaction.
+type Action_t string
+
+const (
+	BORROW   Action_t = "borrow"
+	REPAY    Action_t = "repay"
+	DEPOSIT  Action_t = "deposit"
+	WITHDRAW Action_t = "withdraw"
+)
+
+// Account_t describes the empire's savings and loan.
+type Account_t struct {
+	Cash      int
+	Savings   int
+	Loan      int
+	SaveRate  float64 // savings interest rate, as an annual percentage
+	LoanRate  float64 // loan interest rate, as an annual percentage
+	MaxSave   int     // largest savings balance allowed
+	MaxLoan   int     // largest loan balance allowed
+	Protected bool    // savings do not earn interest while protected
+	CanBorrow bool    // loans are not available once the round is closing
+}
+
+// Account returns the state of the empire's accounts.
+func (b *Bank_t) Account(empireId int, round model.RoundData_t) (*Account_t, error) {
+	emp, err := b.db.EmpireFetch(empireId)
+	if err != nil {
+		return nil, err
+	}
+	return b.account(emp, round), nil
+}
+
+// Result_t is the result of a bank transaction.
+type Result_t struct {
+	Amount   int                // amount of money moved, zero if the transaction failed
+	Messages []engine.Message_t // empty if there was nothing to do
+	Account  *Account_t         // the state of the accounts after the transaction
+}
+
+// Transact performs a bank transaction for the empire.
+func (b *Bank_t) Transact(empireId int, round model.RoundData_t, action Action_t, amount int) (*Result_t, error) {
+	if round.Finished {
+		return nil, cerr.ErrRoundFinished
+	} else if !round.Started {
+		return nil, cerr.ErrRoundNotStarted
+	}
+	res := &Result_t{}
+	err := b.db.Transaction(func(tx *orm.DB) error {
+		emp, err := tx.EmpireFetch(empireId)
+		if err != nil {
+			return err
+		}
+		var msg engine.Message_t
+		switch action {
+		case BORROW:
+			res.Amount, msg = b.e.BankBorrow(emp, round, amount)
+		case REPAY:
+			res.Amount, msg = b.e.BankRepay(emp, amount)
+		case DEPOSIT:
+			res.Amount, msg = b.e.BankDeposit(emp, amount)
+		case WITHDRAW:
+			res.Amount, msg = b.e.BankWithdraw(emp, amount)
+		default:
+			return cerr.ErrUnknownAction
+		}
+		if msg.Key != "" {
+			res.Messages = append(res.Messages, msg)
+		}
+		if res.Amount != 0 {
+			emp.NetWorth = b.e.Networth(emp)
+			if err := tx.EmpireAttributesUpdate(emp); err != nil {
+				return err
+			}
+		}
+		res.Account = b.account(emp, round)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// account returns the state of the empire's accounts.
+func (b *Bank_t) account(emp *model.Empire_t, round model.RoundData_t) *Account_t {
+	acct := &Account_t{
+		Cash:      emp.Cash,
+		Savings:   emp.Bank,
+		Loan:      emp.Loan,
+		Protected: b.e.IsProtected(emp, round),
+		CanBorrow: !round.Closing,
+	}
+	acct.SaveRate, acct.LoanRate = b.e.BankRates(emp)
+	acct.MaxSave, acct.MaxLoan = b.e.BankLimits(emp)
+	return acct
+}
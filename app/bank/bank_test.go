@@ -0,0 +1,69 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package bank
+
+import (
+	"errors"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/orm"
+	"testing"
+)
+
+func TestTransact(t *testing.T) {
+	db, err := orm.CreateMemoryDatabase()
+	if err != nil {
+		t.Fatalf("database: %v", err)
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+	e := engine.New(engine.Config_t{BankSaveRate: 4.0, BankLoanRate: 7.5, PvtmTrpArm: 500}, nil)
+	b, err := New(db, e)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	user, err := db.UserCreate("banker", "banker@example.com")
+	if err != nil {
+		t.Fatalf("user: %v", err)
+	}
+	emp, err := db.EmpireCreate(user, "banker", "HUMAN")
+	if err != nil {
+		t.Fatalf("empire: %v", err)
+	}
+	emp.Cash = 500000
+	emp.NetWorth = e.Networth(emp)
+	if err := db.EmpireAttributesUpdate(emp); err != nil {
+		t.Fatalf("empire: %v", err)
+	}
+
+	started := model.RoundData_t{Started: true}
+	if _, err := b.Transact(emp.Id, model.RoundData_t{Finished: true}, DEPOSIT, 10); !errors.Is(err, cerr.ErrRoundFinished) {
+		t.Errorf("finished: want %v, got %v", cerr.ErrRoundFinished, err)
+	}
+	if _, err := b.Transact(emp.Id, started, "steal", 10); !errors.Is(err, cerr.ErrUnknownAction) {
+		t.Errorf("action: want %v, got %v", cerr.ErrUnknownAction, err)
+	}
+
+	// networth is 200, so up to 20,000 can be saved and 10,000 borrowed
+	res, err := b.Transact(emp.Id, started, DEPOSIT, 15000)
+	if err != nil {
+		t.Fatalf("deposit: %v", err)
+	} else if res.Amount != 15000 || res.Account.Savings != 15000 || res.Account.Cash != 485000 {
+		t.Errorf("deposit: got %+v, %+v", res, res.Account)
+	}
+	res, err = b.Transact(emp.Id, model.RoundData_t{Started: true, Closing: true}, BORROW, 1000)
+	if err != nil {
+		t.Fatalf("borrow: %v", err)
+	} else if res.Amount != 0 || len(res.Messages) != 1 || res.Messages[0].Key != "BANK_BORROW_TOO_LATE" || res.Account.CanBorrow {
+		t.Errorf("borrow: closing: got %+v, %+v", res, res.Account)
+	}
+
+	got, err := db.EmpireFetch(emp.Id)
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	} else if got.Bank != 15000 || got.Cash != 485000 || got.Loan != 0 {
+		t.Errorf("empire: want bank 15000, cash 485000, loan 0, got %d, %d, %d", got.Bank, got.Cash, got.Loan)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+// Package bbcode converts BBcode in forum posts to HTML and back again,
+// from php/includes/bbcode.php.
+//
+// Messages must be HTML-escaped before they are encoded. The HTML for each
+// tag carries a comment with the tag's name, so that Decode can restore the
+// BBcode when a post is edited.
+package bbcode
+
+import (
+	"regexp"
+	"strings"
+)
+
+// tags are the BBcode tags that are recognized.
+var tags = map[string]*regexp.Regexp{}
+
+func init() {
+	for _, tag := range []string{"url", "email", "b", "i", "u", "center", "quote"} {
+		// note that this will not handle nesting of a tag within itself
+		tags[tag] = regexp.MustCompile(`(?s)\[` + tag + `\](.*?)\[/` + tag + `\]`)
+	}
+}
+
+var (
+	reOpenTag   = regexp.MustCompile(`\[([^/][^\]]*?)\]`)
+	reSchemeURL = regexp.MustCompile(`(?i)^(https?|ftp)://\S*$`)
+	reNoSpace   = regexp.MustCompile(`^\S*$`)
+	reLink      = regexp.MustCompile(`<a href="[^"]*"(?: rel="external")?>(.*?)</a>`)
+	decoder     = strings.NewReplacer(
+		`<table style="border:0;font-size:smaller;margin-left:20px"><tr><td>Quote:<hr /></td></tr><tr><td><!--quote-->`, `[quote]`,
+		`<!--/quote--></td></tr><tr><td><hr /></td></tr></table>`, `[/quote]`,
+		`<div class="ac"><!--center-->`, `[center]`,
+		`<!--/center--></div>`, `[/center]`,
+		`<span style="text-decoration:underline"><!--u-->`, `[u]`,
+		`<!--/u--></span>`, `[/u]`,
+		`<span style="font-style:italic"><!--i-->`, `[i]`,
+		`<!--/i--></span>`, `[/i]`,
+		`<span style="font-weight:bold"><!--b-->`, `[b]`,
+		`<!--/b--></span>`, `[/b]`,
+		`<!--email-->`, `[email]`,
+		`<!--/email-->`, `[/email]`,
+		`<!--url-->`, `[url]`,
+		`<!--/url-->`, `[/url]`,
+	)
+)
+
+// cutset is the whitespace removed by PHP's trim.
+const cutset = " \t\n\r\x00\x0b"
+
+// Encode replaces the BBcode in the message with HTML.
+// Tags that are not recognized or not closed are left alone.
+// Unlike the PHP version, links must use http, https, or ftp, or have no
+// scheme at all, so that posts can't carry script links.
+func Encode(message string) string {
+	// if there aren't any square brackets, there's nothing to do
+	if !strings.Contains(message, "[") || !strings.Contains(message, "]") {
+		return strings.Trim(message, cutset)
+	}
+	return strings.Trim(parse(message, false), cutset)
+}
+
+// Decode replaces the HTML added by Encode with BBcode.
+func Decode(message string) string {
+	// strip out all links - they'll still have the bbcode comments inside so we can restore the tags below
+	message = reLink.ReplaceAllString(message, "$1")
+	return decoder.Replace(message)
+}
+
+// parse locates complete BBcode tags and formats their contents.
+// It works recursively so that elements are never nested improperly.
+// Block elements are not allowed inside inline ones.
+func parse(message string, inline bool) string {
+	var sb strings.Builder
+	for len(message) != 0 {
+		match := reOpenTag.FindStringSubmatchIndex(message)
+		if match == nil {
+			// dump out the rest of the message as plain text
+			sb.WriteString(message)
+			break
+		}
+		tag := message[match[2]:match[3]]
+		// we found a tag - is it one we recognize, and is it closed?
+		re, ok := tags[tag]
+		var found []int
+		if ok {
+			found = re.FindStringSubmatchIndex(message)
+		}
+		if found == nil {
+			sb.WriteString(message[:match[2]])
+			message = message[match[2]:]
+			continue
+		}
+		sb.WriteString(message[:found[0]])
+		sb.WriteString(encodeTag(tag, message[found[2]:found[3]], inline))
+		message = message[found[1]:]
+	}
+	return sb.String()
+}
+
+// encodeTag formats the contents of a single tag, looking for other tags inside it.
+func encodeTag(tag, contents string, inline bool) string {
+	switch tag {
+	case "url":
+		contents = strings.Trim(contents, cutset)
+		if reSchemeURL.MatchString(contents) {
+			// [url]http://www.example.com/path[/url] (no whitespace)
+			return `<a href="` + contents + `" rel="external"><!--url-->` + contents + `<!--/url--></a>`
+		} else if !strings.Contains(contents, ":") && reNoSpace.MatchString(contents) {
+			// [url]www.example.com/path[/url] (no whitespace)
+			return `<a href="http://` + contents + `" rel="external"><!--url-->` + contents + `<!--/url--></a>`
+		}
+		// there was whitespace or an unsafe scheme - fail it
+		return `[url]` + contents + `[/url]`
+	case "email":
+		contents = strings.Trim(contents, cutset)
+		if reNoSpace.MatchString(contents) {
+			return `<a href="mailto:` + contents + `"><!--email-->` + contents + `<!--/email--></a>`
+		}
+		return `[email]` + contents + `[/email]`
+	case "b":
+		return `<span style="font-weight:bold"><!--b-->` + parse(contents, true) + `<!--/b--></span>`
+	case "i":
+		return `<span style="font-style:italic"><!--i-->` + parse(contents, true) + `<!--/i--></span>`
+	case "u":
+		return `<span style="text-decoration:underline"><!--u-->` + parse(contents, true) + `<!--/u--></span>`
+	case "center":
+		if inline {
+			return `[center]` + contents + `[/center]`
+		}
+		return `<div class="ac"><!--center-->` + parse(contents, false) + `<!--/center--></div>`
+	case "quote":
+		if inline {
+			return `[quote]` + contents + `[/quote]`
+		}
+		return `<table style="border:0;font-size:smaller;margin-left:20px"><tr><td>Quote:<hr /></td></tr><tr><td><!--quote-->` + parse(contents, false) + `<!--/quote--></td></tr><tr><td><hr /></td></tr></table>`
+	}
+	return contents
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package bbcode
+
+import "testing"
+
+func TestEncode(t *testing.T) {
+	for _, tc := range []struct {
+		id      string
+		message string
+		want    string
+	}{
+		{"plain", "  hello, world \n", "hello, world"},
+		{"bold", "[b]hi[/b]", `<span style="font-weight:bold"><!--b-->hi<!--/b--></span>`},
+		{"unknown", "[s]hi[/s] [b]x[/b]", `[s]hi[/s] <span style="font-weight:bold"><!--b-->x<!--/b--></span>`},
+		{"unclosed", "[b]hi", "[b]hi"},
+		{"nested", "[i][u]x[/u][/i]", `<span style="font-style:italic"><!--i--><span style="text-decoration:underline"><!--u-->x<!--/u--></span><!--/i--></span>`},
+		{"block in inline", "[b][center]x[/center][/b]", `<span style="font-weight:bold"><!--b-->[center]x[/center]<!--/b--></span>`},
+		{"url", "[url]http://example.com/a[/url]", `<a href="http://example.com/a" rel="external"><!--url-->http://example.com/a<!--/url--></a>`},
+		{"url no scheme", "[url] www.example.com [/url]", `<a href="http://www.example.com" rel="external"><!--url-->www.example.com<!--/url--></a>`},
+		{"url spaces", "[url]a b[/url]", "[url]a b[/url]"},
+		{"url script", "[url]javascript:alert(1)[/url]", "[url]javascript:alert(1)[/url]"},
+		{"url script scheme", "[url]javascript://%0aalert(1)[/url]", "[url]javascript://%0aalert(1)[/url]"},
+		{"email", "[email]a@example.com[/email]", `<a href="mailto:a@example.com"><!--email-->a@example.com<!--/email--></a>`},
+	} {
+		if got := Encode(tc.message); got != tc.want {
+			t.Errorf("%s: want %q, got %q", tc.id, tc.want, got)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	for _, message := range []string{
+		"[b]bold[/b] and [i]italic[/i] and [u]underline[/u]",
+		"[quote]someone said [b]this[/b][/quote]\nand I agree",
+		"[center]middle[/center]",
+		"[url]http://example.com[/url] [url]www.example.com[/url] [email]a@example.com[/email]",
+	} {
+		if got := Decode(Encode(message)); got != message {
+			t.Errorf("want %q, got %q", message, got)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+// Package build implements construction and demolition from php/pages/build.php
+// and php/pages/demolish.php.
+//
+// Buildings are built on unused land and demolished to free it up again, a
+// number per turn that grows with the size of the empire. Unused land can be
+// dropped, but an empire that recently gained land drops it much more slowly.
+package build
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/orm"
+	"time"
+)
+
+// Build_t builds and demolishes against the database.
+type Build_t struct {
+	db     *orm.DB
+	e      *engine.Engine_t
+	tables *engine.Tables_t
+}
+
+// New returns a construction service.
+func New(db *orm.DB, e *engine.Engine_t, tables *engine.Tables_t) (*Build_t, error) {
+	if db == nil {
+		return nil, fmt.Errorf("missing database")
+	} else if e == nil {
+		return nil, fmt.Errorf("missing engine")
+	} else if tables == nil {
+		return nil, fmt.Errorf("missing tables")
+	}
+	return &Build_t{db: db, e: e, tables: tables}, nil
+}
+
+// State_t describes what the empire can build, demolish, and drop.
+type State_t struct {
+	Cash         int
+	Turns        int
+	Land         int
+	Buildings    engine.Buildings_t // buildings owned and unused land
+	BuildCost    int                // cost of each building
+	BuildRate    int                // buildings that can be built per turn
+	CanBuild     int                // buildings the empire can build
+	Salvage      int                // cash salvaged from each building demolished
+	DemolishRate int                // buildings that can be demolished per turn
+	CanDemolish  int                // buildings the empire can demolish
+	DropRate     int                // acres that can be dropped per turn
+	CanDrop      int                // acres the empire can drop
+}
+
+// State returns what the empire can build, demolish, and drop.
+func (b *Build_t) State(empireId int, now time.Time) (*State_t, error) {
+	var state *State_t
+	err := b.db.Transaction(func(tx *orm.DB) error {
+		emp, err := tx.EmpireFetch(empireId)
+		if err != nil {
+			return err
+		}
+		fx, err := tx.EmpireEffectsFetch(emp.Id, b.tables.Effects)
+		if err != nil {
+			return err
+		}
+		state = b.state(emp, fx, now)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// Result_t is the result of building, demolishing, or dropping land.
+type Result_t struct {
+	Turns    int                // turns spent
+	Total    int                // buildings built or demolished, or acres dropped
+	Cash     int                // cash spent building or salvaged demolishing
+	Report   *engine.Report_t   // from taking turns, nil if nothing was done
+	Messages []engine.Message_t // why the request was or was not completed
+	State    *State_t           // what the empire can do afterward
+}
+
+// Build constructs buildings for the empire.
+func (b *Build_t) Build(empireId int, round model.RoundData_t, buildings engine.Buildings_t, now time.Time) (*Result_t, error) {
+	return b.update(empireId, round, now, func(emp *model.Empire_t, fx *engine.Effects_t, wars int, res *Result_t) error {
+		updated, built, err := b.e.Build(*emp, b.tables.Modifiers(emp), engine.Build_t{
+			Buildings: buildings,
+			Wars:      wars,
+			Round:     round,
+			Effects:   fx,
+			Now:       now,
+		})
+		if errors.Is(err, cerr.ErrNeedInput) {
+			res.Messages = append(res.Messages, engine.Message_t{Key: "BUILD_NO_INPUT"})
+			return nil
+		} else if errors.Is(err, cerr.ErrBuildTooMany) {
+			res.Messages = append(res.Messages, engine.Message_t{Key: "BUILD_TOO_MANY"})
+			return nil
+		} else if err != nil {
+			return err
+		}
+		*emp = updated
+		res.Turns, res.Total, res.Cash, res.Report = built.Turns, built.Total, built.Spent, built.Report
+		if built.OutOfCash {
+			res.Messages = append(res.Messages, engine.Message_t{Key: "BUILD_OUT_OF_CASH"})
+		}
+		res.Messages = append(res.Messages, engine.Message_t{Key: "BUILD_COMPLETE", Args: []any{built.Spent, built.Turns, built.Total}})
+		return nil
+	})
+}
+
+// Demolish tears down buildings for the empire.
+func (b *Build_t) Demolish(empireId int, round model.RoundData_t, buildings engine.Buildings_t, now time.Time) (*Result_t, error) {
+	return b.update(empireId, round, now, func(emp *model.Empire_t, fx *engine.Effects_t, wars int, res *Result_t) error {
+		updated, demolished, err := b.e.Demolish(*emp, b.tables.Modifiers(emp), engine.Demolish_t{
+			Buildings: buildings,
+			Wars:      wars,
+			Round:     round,
+			Effects:   fx,
+			Now:       now,
+		})
+		if errors.Is(err, cerr.ErrNeedInput) {
+			// nothing to do, and nothing to say about it
+			return nil
+		} else if errors.Is(err, cerr.ErrDemolishTooMany) {
+			res.Messages = append(res.Messages, engine.Message_t{Key: "DEMOLISH_TOO_MANY"})
+			return nil
+		} else if errors.Is(err, cerr.ErrNeedTurns) {
+			res.Messages = append(res.Messages, engine.Message_t{Key: "DEMOLISH_NOT_ENOUGH_TURNS"})
+			return nil
+		} else if err != nil {
+			return err
+		}
+		*emp = updated
+		res.Turns, res.Total, res.Cash, res.Report = demolished.Turns, demolished.Total, demolished.Salvaged, demolished.Report
+		res.Messages = append(res.Messages, engine.Message_t{Key: "DEMOLISH_COMPLETE", Args: []any{demolished.Turns, demolished.Total, demolished.Salvaged}})
+		return nil
+	})
+}
+
+// DropLand abandons unused land for the empire.
+func (b *Build_t) DropLand(empireId int, round model.RoundData_t, acres int, now time.Time) (*Result_t, error) {
+	return b.update(empireId, round, now, func(emp *model.Empire_t, fx *engine.Effects_t, wars int, res *Result_t) error {
+		updated, dropped, err := b.e.DropLand(*emp, b.tables.Modifiers(emp), engine.DropLand_t{
+			Acres:   acres,
+			Wars:    wars,
+			Round:   round,
+			Effects: fx,
+			Now:     now,
+		})
+		if errors.Is(err, cerr.ErrNeedInput) {
+			// nothing to do, and nothing to say about it
+			return nil
+		} else if errors.Is(err, cerr.ErrDropTooMuch) {
+			res.Messages = append(res.Messages, engine.Message_t{Key: "DEMOLISH_DROP_TOO_MUCH"})
+			return nil
+		} else if err != nil {
+			return err
+		}
+		*emp = updated
+		res.Turns, res.Total, res.Report = dropped.Turns, dropped.Dropped, dropped.Report
+		res.Messages = append(res.Messages, engine.Message_t{Key: "DEMOLISH_DROP_COMPLETE", Args: []any{dropped.Turns, dropped.Dropped}})
+		return nil
+	})
+}
+
+// update applies a change to the empire and saves it along with its effects.
+// The change is given the number of wars the empire's clan has declared, for
+// the passive war tax. It leaves the result's report nil if it did nothing.
+func (b *Build_t) update(empireId int, round model.RoundData_t, now time.Time, change func(*model.Empire_t, *engine.Effects_t, int, *Result_t) error) (*Result_t, error) {
+	if round.Finished {
+		return nil, cerr.ErrRoundFinished
+	} else if !round.Started {
+		return nil, cerr.ErrRoundNotStarted
+	}
+	res := &Result_t{}
+	err := b.db.Transaction(func(tx *orm.DB) error {
+		emp, err := tx.EmpireFetch(empireId)
+		if err != nil {
+			return err
+		}
+		fx, err := tx.EmpireEffectsFetch(emp.Id, b.tables.Effects)
+		if err != nil {
+			return err
+		}
+		var wars int
+		if emp.CId != 0 {
+			// the passive war tax only counts wars the clan declared
+			if wars, err = tx.ClanWarsDeclared(emp.CId); err != nil {
+				return err
+			}
+		}
+		if err := change(emp, fx, wars, res); err != nil {
+			return err
+		}
+		if res.Report != nil {
+			emp.NetWorth = b.e.Networth(emp)
+			if err := tx.EmpireAttributesUpdate(emp); err != nil {
+				return err
+			} else if err := tx.EmpireEffectsSave(fx); err != nil {
+				return err
+			}
+		}
+		res.State = b.state(emp, fx, now)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// state returns what the empire can build, demolish, and drop.
+func (b *Build_t) state(emp *model.Empire_t, fx *engine.Effects_t, now time.Time) *State_t {
+	mods := b.tables.Modifiers(emp).Add(fx.Modifiers(now))
+	state := &State_t{
+		Cash:  emp.Cash,
+		Turns: emp.Turns,
+		Land:  emp.Land,
+		Buildings: engine.Buildings_t{
+			BldCash:  emp.BldCash,
+			BldPop:   emp.BldPop,
+			BldTrp:   emp.BldTrp,
+			BldCost:  emp.BldCost,
+			BldFood:  emp.BldFood,
+			BldWiz:   emp.BldWiz,
+			BldDef:   emp.BldDef,
+			Freeland: emp.Freeland,
+		},
+	}
+	state.BuildCost, state.BuildRate, state.CanBuild = b.e.BuildAmounts(emp, mods)
+	state.Salvage, state.DemolishRate, state.CanDemolish = b.e.DemolishAmounts(emp, mods)
+	state.DropRate, state.CanDrop = b.e.DropAmounts(emp, mods, fx, now)
+	return state
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package build
+
+import (
+	"errors"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/orm"
+	"testing"
+	"time"
+)
+
+func TestBuild(t *testing.T) {
+	db, err := orm.CreateMemoryDatabase()
+	if err != nil {
+		t.Fatalf("database: %v", err)
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+	e := engine.New(engine.Config_t{BuildCost: 3500}, nil)
+	b, err := New(db, e, engine.DefaultTables())
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	user, err := db.UserCreate("builder", "builder@example.com")
+	if err != nil {
+		t.Fatalf("user: %v", err)
+	}
+	emp, err := db.EmpireCreate(user, "builder", "HUMAN")
+	if err != nil {
+		t.Fatalf("empire: %v", err)
+	}
+	emp.Era, emp.Turns, emp.Cash = engine.ERA_PAST, 50, 1_000_000
+	emp.Land, emp.Freeland, emp.BldCash = 250, 100, 150
+	if err := db.EmpireAttributesUpdate(emp); err != nil {
+		t.Fatalf("empire: %v", err)
+	}
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	started := model.RoundData_t{Started: true}
+
+	if _, err := b.Build(emp.Id, model.RoundData_t{Finished: true}, engine.Buildings_t{BldCash: 1}, now); !errors.Is(err, cerr.ErrRoundFinished) {
+		t.Errorf("finished: want %v, got %v", cerr.ErrRoundFinished, err)
+	}
+	state, err := b.State(emp.Id, now)
+	if err != nil {
+		t.Fatalf("state: %v", err)
+	} else if state.BuildCost != 3525 || state.Salvage != 705 || state.CanDemolish != 150 || state.CanDrop != 0 {
+		t.Errorf("state: got %+v", state)
+	}
+
+	res, err := b.Build(emp.Id, started, engine.Buildings_t{}, now)
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	} else if res.Report != nil || len(res.Messages) != 1 || res.Messages[0].Key != "BUILD_NO_INPUT" {
+		t.Errorf("build: nothing: got %+v", res)
+	}
+	res, err = b.Build(emp.Id, started, engine.Buildings_t{BldCash: 5}, now)
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	} else if res.Total != 5 || res.Turns != 1 || res.Messages[len(res.Messages)-1].Key != "BUILD_COMPLETE" {
+		t.Errorf("build: got %+v", res)
+	} else if res.State.Buildings.BldCash != 155 || res.State.Buildings.Freeland != 95 {
+		t.Errorf("build: state: got %+v", res.State.Buildings)
+	}
+
+	res, err = b.Demolish(emp.Id, started, engine.Buildings_t{BldCash: 2}, now)
+	if err != nil {
+		t.Fatalf("demolish: %v", err)
+	} else if res.Total != 2 || res.Cash != 2*705 || len(res.Messages) != 1 || res.Messages[0].Key != "DEMOLISH_COMPLETE" {
+		t.Errorf("demolish: got %+v", res)
+	}
+	if got, err := db.EmpireFetch(emp.Id); err != nil {
+		t.Fatalf("fetch: %v", err)
+	} else if got.BldCash != 153 || got.Freeland != 97 || got.Turns != 48 {
+		t.Errorf("empire: got bldcash %d, freeland %d, turns %d", got.BldCash, got.Freeland, got.Turns)
+	}
+
+	// small empires can not drop land
+	res, err = b.DropLand(emp.Id, started, 10, now)
+	if err != nil {
+		t.Fatalf("drop: %v", err)
+	} else if res.Report != nil || len(res.Messages) != 1 || res.Messages[0].Key != "DEMOLISH_DROP_TOO_MUCH" {
+		t.Errorf("drop: got %+v", res)
+	}
+}
+
+func TestBuildWarTax(t *testing.T) {
+	db, err := orm.CreateMemoryDatabase()
+	if err != nil {
+		t.Fatalf("database: %v", err)
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+	e := engine.New(engine.Config_t{BuildCost: 3500, ClanEnable: true}, nil)
+	b, err := New(db, e, engine.DefaultTables())
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	started := model.RoundData_t{Started: true}
+
+	var clans []int
+	for _, name := range []string{"HAWKS", "DOVES"} {
+		clan := &model.Clan_t{Name: name, Members: 1}
+		if err := db.ClanCreate(clan); err != nil {
+			t.Fatalf("clan: %v", err)
+		}
+		clans = append(clans, clan.Id)
+	}
+	user, err := db.UserCreate("builder", "builder@example.com")
+	if err != nil {
+		t.Fatalf("user: %v", err)
+	}
+	emp, err := db.EmpireCreate(user, "builder", "HUMAN")
+	if err != nil {
+		t.Fatalf("empire: %v", err)
+	}
+	emp.Era, emp.Turns, emp.Cash, emp.CId = engine.ERA_PAST, 50, 1_000_000, clans[0]
+	emp.Land, emp.Freeland, emp.BldCash = 250, 100, 150
+	if err := db.EmpireAttributesUpdate(emp); err != nil {
+		t.Fatalf("empire: %v", err)
+	}
+
+	res, err := b.Build(emp.Id, started, engine.Buildings_t{BldCash: 5}, now)
+	if err != nil {
+		t.Fatalf("peace: %v", err)
+	} else if res.Report.Overall.WarTax != 0 {
+		t.Errorf("peace: want no war tax, got %d", res.Report.Overall.WarTax)
+	}
+
+	// declaring war costs the clan's members while they build and demolish
+	if err := db.ClanRelationCreate(&orm.ClanRelation_t{Clan1: clans[0], Clan2: clans[1], Flags: orm.CRFLAG_WAR | orm.CRFLAG_MUTUAL, Time: now}); err != nil {
+		t.Fatalf("war: %v", err)
+	}
+	res, err = b.Build(emp.Id, started, engine.Buildings_t{BldCash: 5}, now)
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	} else if res.Report.Overall.WarTax == 0 {
+		t.Errorf("build: want war tax, got none")
+	}
+	res, err = b.Demolish(emp.Id, started, engine.Buildings_t{BldCash: 5}, now)
+	if err != nil {
+		t.Fatalf("demolish: %v", err)
+	} else if res.Report.Overall.WarTax == 0 {
+		t.Errorf("demolish: want war tax, got none")
+	}
+}
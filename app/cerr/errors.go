@@ -9,13 +9,52 @@ func (e Error) Error() string {
 }
 
 const (
+	ErrAidDisabled         = Error("foreign aid disabled")
+	ErrAttackLimit         = Error("attack limit reached")
 	ErrBadPage             = Error("bad page")
 	ErrBadReferrer         = Error("bad referrer")
+	ErrBuildTooMany        = Error("can not build that many")
+	ErrClanAidHidden       = Error("clan aid summary disabled")
+	ErrClansDisabled       = Error("clans disabled")
 	ErrCreateSchema        = Error("schema exists")
 	ErrDatabaseExists      = Error("database exists")
+	ErrDemolishTooMany     = Error("can not demolish that many")
+	ErrDropTooMuch         = Error("can not drop that much land")
+	ErrDryRun              = Error("dry run")
+	ErrEmpireAdmin         = Error("administrative empire")
+	ErrEmpireProtected     = Error("empire is protected")
 	ErrForeignKeysDisabled = Error("foreign keys disabled")
+	ErrFriendMagicDisabled = Error("friendly magic disabled")
+	ErrIndustryTooHigh     = Error("industry allocated over 100 percent")
 	ErrMissingReferrer     = Error("missing referrer")
+	ErrNeedGate            = Error("time gate required")
+	ErrNeedHealth          = Error("not enough health")
+	ErrNeedInput           = Error("nothing to do")
+	ErrNeedRunes           = Error("not enough runes")
+	ErrNeedTurns           = Error("not enough turns")
+	ErrNeedUnits           = Error("no units")
+	ErrNeedWizards         = Error("no wizards")
+	ErrNotClanMember       = Error("not a clan member")
+	ErrNotClanOfficer      = Error("not a clan officer")
 	ErrNotImplemented      = Error("not implemented")
 	ErrPragmaReturnedNil   = Error("pragma returned nil")
+	ErrReleaseTooMany      = Error("can not release that many")
+	ErrRemoveNotAllowed    = Error("removal not allowed")
+	ErrRoundFinished       = Error("round has ended")
+	ErrRoundNotStarted     = Error("round has not started")
+	ErrSpellNotAllowed     = Error("spell not allowed")
+	ErrSpellTarget         = Error("spell can not be cast on target")
+	ErrTargetAlly          = Error("target is in an allied clan")
+	ErrTargetClanmate      = Error("target is in the same clan")
+	ErrTargetTooLarge      = Error("target too large")
+	ErrTargetTooSmall      = Error("target too small")
+	ErrUnknownAction       = Error("unknown action")
+	ErrUnknownAttack       = Error("unknown attack type")
+	ErrUnknownEra          = Error("unknown era")
+	ErrUnknownGood         = Error("unknown goods")
 	ErrUnknownLanguage     = Error("unknown language")
+	ErrUnknownRace         = Error("unknown race")
+	ErrUnknownSpell        = Error("unknown spell")
+	ErrUnknownStrategy     = Error("unknown strategy")
+	ErrUnknownWorldVar     = Error("unknown world variable")
 )
@@ -0,0 +1,586 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+// Package clans implements creating, joining, leaving, and disbanding clans
+// from php/pages/clan.php and php/classes/prom_clan.php, alliances, wars, and
+// invitations from php/pages/manage/clan.php, the clan forum from
+// php/pages/clanforum.php, and removing empires from their clans from
+// prom_turns::removeFromClan.
+//
+// Members may share their forces with the clan. A tenth of a sharing
+// member's units are sent to help defend clanmates who are attacked, and
+// a tenth of its own units may not defend it. Unsharing takes
+// CLAN_MINSHARE hours to take effect; e_sharing counts down the turn
+// updates until it does.
+//
+// Empires must stay in a clan for CLAN_MINJOIN hours before they can leave it,
+// and must wait CLAN_MINREJOIN hours after leaving before they can create or
+// join another; both are tracked by the m_clan effect.
+package clans
+
+import (
+	"fmt"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/orm"
+	"math"
+	"slices"
+	"time"
+)
+
+// Config_t holds the settings from config.php used by clans.
+type Config_t struct {
+	ClanEnable bool // Master enable for clans
+	MinJoin    int  // Empires can't leave clans until they've been a member for this many hours
+	MinRejoin  int  // Empires can't create/join a new clan until this many hours after they left
+	MaxNameLen int  // Longest allowed clan name, in bytes
+	MaxAlly    int  // Maximum number of alliances a clan may have
+	MaxWar     int  // Maximum number of wars a clan may declare
+	MinRelate  int  // Clans can't change a relation until it is this many hours old
+	MinShare   int  // Unsharing clan forces takes this many hours to take effect
+	TurnsFreq  int  // Minutes between turn updates, which count down unsharing
+	// DefaultTitle and DefaultMotd are formats for the title and the first
+	// news post of a new clan. The clan's name is the only argument.
+	DefaultTitle string
+	DefaultMotd  string
+	// Reserved returns true if the name may not be used for a clan.
+	// It may be nil.
+	Reserved func(name string) bool
+	// IsLangKey returns true if the text is a key in the language file;
+	// forum posts may not use them. It may be nil.
+	IsLangKey     func(text string) bool
+	TopicsPerPage int // Topics shown on each page of the forum's index
+	PostsPerPage  int // Posts shown on each page of a forum topic
+}
+
+// Clans_t manages clan membership against the database.
+type Clans_t struct {
+	db     *orm.DB
+	e      *engine.Engine_t
+	tables *engine.Tables_t
+	cfg    Config_t
+}
+
+// New returns a clan service.
+func New(db *orm.DB, e *engine.Engine_t, tables *engine.Tables_t, cfg Config_t) (*Clans_t, error) {
+	if db == nil {
+		return nil, fmt.Errorf("missing database")
+	} else if e == nil {
+		return nil, fmt.Errorf("missing engine")
+	} else if tables == nil {
+		return nil, fmt.Errorf("missing tables")
+	}
+	if cfg.MaxNameLen == 0 {
+		cfg.MaxNameLen = 8
+	}
+	if cfg.TurnsFreq == 0 {
+		cfg.TurnsFreq = 10
+	}
+	if cfg.TopicsPerPage == 0 {
+		cfg.TopicsPerPage = 25
+	}
+	if cfg.PostsPerPage == 0 {
+		cfg.PostsPerPage = 20
+	}
+	return &Clans_t{db: db, e: e, tables: tables, cfg: cfg}, nil
+}
+
+// State_t describes the empire's clan, or the clans it may join.
+type State_t struct {
+	Clan      *model.Clan_t       // nil if the empire is not in a clan
+	Members   []*orm.ClanMember_t // members of the empire's clan, strongest first
+	Relations []*Relation_t       // relations of the empire's clan with other clans
+	Clans     []*model.Clan_t     // other clans that have not been disbanded
+	Invites   []*Invite_t         // invitations the empire has received, or its clan has sent if it is an officer
+	Wait      int                 // seconds before the empire may leave its clan, or create or join another
+	Sharing   bool                // the empire's forces help defend its clanmates
+	Unshare   int                 // minutes before the empire's shared forces are its own again, if it is unsharing
+	Share     engine.Units_t      // forces the empire shares with its clanmates
+	Shared    engine.Units_t      // forces shared by every sharing member of the clan
+	Closing   bool                // the round is closing, so clans may not be created
+}
+
+// State returns the empire's clan, or the clans it may join.
+func (s *Clans_t) State(empireId int, round model.RoundData_t, now time.Time) (*State_t, error) {
+	var state *State_t
+	err := s.db.Transaction(func(tx *orm.DB) error {
+		emp, err := tx.EmpireFetch(empireId)
+		if err != nil {
+			return err
+		} else if err := s.available(emp, round); err != nil {
+			return err
+		}
+		fx, err := tx.EmpireEffectsFetch(emp.Id, s.tables.Effects)
+		if err != nil {
+			return err
+		}
+		state, err = s.state(tx, emp, fx, round, now)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// Result_t is the result of a clan action.
+type Result_t struct {
+	Success  bool
+	Messages []engine.Message_t
+	State    *State_t // clan after the action
+	Forum    *Forum_t // page of the clan forum after a forum action
+}
+
+// Create creates a new clan led by the empire.
+// The clan's first news post is added to a new news topic on the clan forum.
+func (s *Clans_t) Create(empireId int, round model.RoundData_t, name, password, verify string, now time.Time) (*Result_t, error) {
+	return s.update(empireId, round, now, func(tx *orm.DB, emp *model.Empire_t, fx *engine.Effects_t, res *Result_t) error {
+		if round.Closing {
+			return res.reject("CLAN_CREATE_TOO_LATE")
+		} else if emp.CId != 0 {
+			return res.reject("CLAN_ALREADY_MEMBER")
+		} else if fx.Active("m_clan", now) {
+			return res.reject("CLAN_CREATE_TOO_SOON", s.cfg.MinRejoin)
+		} else if name == "" {
+			return res.reject("CLAN_CREATE_NEED_NAME")
+		} else if s.cfg.Reserved != nil && s.cfg.Reserved(name) {
+			return res.reject("CLAN_CREATE_NAME_INVALID")
+		} else if len(name) > s.cfg.MaxNameLen {
+			return res.reject("CLAN_CREATE_NAME_TOO_LONG")
+		} else if password == "" {
+			return res.reject("INPUT_NEED_PASSWORD")
+		} else if password != verify {
+			return res.reject("INPUT_PASSWORD_MISMATCH")
+		}
+		if inUse, err := tx.ClanNameInUse(name); err != nil {
+			return err
+		} else if inUse {
+			return res.reject("CLAN_CREATE_NAME_IN_USE")
+		}
+
+		hash, err := HashPassword(password)
+		if err != nil {
+			return err
+		}
+		clan := &model.Clan_t{
+			Name:     name,
+			Password: hash,
+			Members:  1,
+			Leader:   emp.Id,
+			Title:    fmt.Sprintf(s.cfg.DefaultTitle, name),
+		}
+		if err := tx.ClanCreate(clan); err != nil {
+			return err
+		}
+		topicId, err := tx.ClanTopicCreate(clan.Id, "", orm.CTFLAG_NEWS)
+		if err != nil {
+			return err
+		} else if _, err := tx.ClanMessageCreate(topicId, emp.Id, fmt.Sprintf(s.cfg.DefaultMotd, name), 0, now); err != nil {
+			return err
+		}
+
+		emp.CId = clan.Id
+		if err := fx.Set("m_clan", 3600*s.cfg.MinJoin, now); err != nil {
+			return err
+		}
+		if err := tx.EmpireNewsCreate(now, engine.NewNews(engine.EMPNEWS_CLAN_CREATE, emp, emp)); err != nil {
+			return err
+		} else if err := tx.ClanNewsCreate(now, engine.NewClanNews(engine.CLANNEWS_MEMBER_CREATE, clan, emp, nil, nil)); err != nil {
+			return err
+		}
+		return res.succeed("CLAN_CREATE_COMPLETE", name)
+	})
+}
+
+// Join adds the empire to the clan if the password is correct.
+func (s *Clans_t) Join(empireId int, round model.RoundData_t, clanId int, password string, now time.Time) (*Result_t, error) {
+	return s.update(empireId, round, now, func(tx *orm.DB, emp *model.Empire_t, fx *engine.Effects_t, res *Result_t) error {
+		return s.join(tx, emp, fx, clanId, res, now, func(clan *model.Clan_t) (bool, error) {
+			ok, rehash := CheckPassword(password, clan.Password)
+			if !ok {
+				return false, res.reject("INPUT_INCORRECT_PASSWORD")
+			} else if rehash {
+				var err error
+				if clan.Password, err = HashPassword(password); err != nil {
+					return false, err
+				}
+			}
+			return true, nil
+		})
+	})
+}
+
+// join adds the empire to the clan if admit lets it in.
+// Admit adds a message explaining why when it doesn't.
+// The clan may hold 10 members, plus one for every 100 empires in the game.
+func (s *Clans_t) join(tx *orm.DB, emp *model.Empire_t, fx *engine.Effects_t, clanId int, res *Result_t, now time.Time, admit func(clan *model.Clan_t) (bool, error)) error {
+	if emp.CId != 0 {
+		return res.reject("CLAN_ALREADY_MEMBER")
+	} else if fx.Active("m_clan", now) {
+		return res.reject("CLAN_JOIN_TOO_SOON", s.cfg.MinRejoin)
+	} else if clanId == 0 {
+		return res.reject("CLAN_JOIN_NEED_CLAN")
+	}
+	clan, err := tx.ClanFetch(clanId)
+	if err != nil {
+		return err
+	} else if clan.Members < 1 {
+		return res.reject("CLAN_JOIN_DISBANDED")
+	} else if ok, err := admit(clan); err != nil || !ok {
+		return err
+	}
+	empires, err := tx.EmpireActiveCount()
+	if err != nil {
+		return err
+	} else if clan.Members >= int(math.Round(10+float64(empires)/100)) {
+		return res.reject("CLAN_JOIN_IS_FULL")
+	}
+
+	if err := tx.ClanInvitesDeleteTemporary(emp.Id); err != nil {
+		return err
+	}
+	emp.CId, emp.Sharing = clan.Id, 0
+	if err := fx.Set("m_clan", 3600*s.cfg.MinJoin, now); err != nil {
+		return err
+	}
+	clan.Members++
+	if err := tx.ClanUpdate(clan); err != nil {
+		return err
+	}
+	leader, err := tx.EmpireFetch(clan.Leader)
+	if err != nil {
+		return err
+	}
+	// send "join" notice to the leader, to yourself, and to the clan itself
+	if err := tx.EmpireNewsCreate(now,
+		engine.NewNews(engine.EMPNEWS_CLAN_JOIN, emp, leader),
+		engine.NewNews(engine.EMPNEWS_CLAN_JOIN, emp, emp)); err != nil {
+		return err
+	} else if err := tx.ClanNewsCreate(now, engine.NewClanNews(engine.CLANNEWS_MEMBER_JOIN, clan, emp, nil, nil)); err != nil {
+		return err
+	}
+	return res.succeed("CLAN_JOIN_COMPLETE", clan.Name)
+}
+
+// Leave removes the empire from its clan.
+// A leader may only leave a clan if they are its last member, which disbands it.
+func (s *Clans_t) Leave(empireId int, round model.RoundData_t, confirm bool, now time.Time) (*Result_t, error) {
+	return s.update(empireId, round, now, func(tx *orm.DB, emp *model.Empire_t, fx *engine.Effects_t, res *Result_t) error {
+		if emp.CId == 0 {
+			return res.reject("CLAN_NOT_MEMBER")
+		} else if fx.Active("m_clan", now) {
+			return res.reject("CLAN_LEAVE_TOO_SOON", s.cfg.MinJoin)
+		} else if !confirm {
+			return res.reject("CLAN_LEAVE_NEED_CONFIRM")
+		}
+		clan, err := tx.ClanFetch(emp.CId)
+		if err != nil {
+			return err
+		}
+
+		if clan.Leader == emp.Id {
+			if clan.Members != 1 {
+				return res.reject("CLAN_LEAVE_OTHER_MEMBERS")
+			}
+			if err := disband(tx, emp, clan, now); err != nil {
+				return err
+			} else if err := tx.EmpireNewsCreate(now, engine.NewNews(engine.EMPNEWS_CLAN_DISBAND, emp, emp)); err != nil {
+				return err
+			}
+			emp.CId, emp.Sharing = 0, 0
+			if err := fx.Set("m_clan", 3600*s.cfg.MinRejoin, now); err != nil {
+				return err
+			}
+			clan.Members, clan.Leader = 0, 0
+			if err := tx.ClanUpdate(clan); err != nil {
+				return err
+			}
+			return res.succeed("CLAN_LEAVE_COMPLETE_DISBAND", clan.Name)
+		}
+
+		vacate(clan, emp.Id)
+		clan.Members--
+		if err := tx.ClanUpdate(clan); err != nil {
+			return err
+		}
+		leader, err := tx.EmpireFetch(clan.Leader)
+		if err != nil {
+			return err
+		}
+		// send "left" notice to the leader, to yourself, and to the clan
+		if err := tx.EmpireNewsCreate(now,
+			engine.NewNews(engine.EMPNEWS_CLAN_LEAVE, emp, leader),
+			engine.NewNews(engine.EMPNEWS_CLAN_LEAVE, emp, emp)); err != nil {
+			return err
+		} else if err := tx.ClanNewsCreate(now, engine.NewClanNews(engine.CLANNEWS_MEMBER_LEAVE, clan, emp, nil, nil)); err != nil {
+			return err
+		}
+		emp.CId, emp.Sharing = 0, 0
+		if err := fx.Set("m_clan", 3600*s.cfg.MinRejoin, now); err != nil {
+			return err
+		}
+		return res.succeed("CLAN_LEAVE_COMPLETE", clan.Name)
+	})
+}
+
+// SetPassword changes the password needed to join the empire's clan.
+// Only the leader and the assistant leader may change it.
+func (s *Clans_t) SetPassword(empireId int, round model.RoundData_t, password, verify string, now time.Time) (*Result_t, error) {
+	return s.update(empireId, round, now, func(tx *orm.DB, emp *model.Empire_t, fx *engine.Effects_t, res *Result_t) error {
+		clan, err := officerClan(tx, emp)
+		if err != nil {
+			return err
+		} else if emp.Id != clan.Leader && emp.Id != clan.Assistant {
+			return res.reject("MANAGE_CLAN_PASSWORD_NEED_PERMISSION")
+		} else if password == "" {
+			return res.reject("INPUT_NEED_PASSWORD")
+		} else if password != verify {
+			return res.reject("INPUT_PASSWORD_MISMATCH")
+		}
+		if clan.Password, err = HashPassword(password); err != nil {
+			return err
+		} else if err := tx.ClanUpdate(clan); err != nil {
+			return err
+		} else if err := tx.ClanNewsCreate(now, engine.NewClanNews(engine.CLANNEWS_PROP_CHANGE_PASSWORD, clan, emp, nil, nil)); err != nil {
+			return err
+		}
+		return res.succeed("MANAGE_CLAN_PASSWORD_COMPLETE")
+	})
+}
+
+// Remove takes the empire out of its clan when it is being unlinked from its
+// user, from prom_turns::removeFromClan. It must be called inside a transaction.
+//
+// When the leader is removed, the assistant leader inherits the clan. If there
+// isn't one, the strongest member that is alive and not deleted or disabled
+// inherits it, then the strongest that is not disabled, then anybody at all.
+// A clan with no members left is abandoned. The empire is not saved.
+// It returns a short description of what happened, for the turn log.
+func Remove(tx *orm.DB, emp *model.Empire_t, now time.Time) (string, error) {
+	if emp.CId == 0 {
+		return "", nil
+	}
+	clan, err := tx.ClanFetch(emp.CId)
+	if err != nil {
+		return "", err
+	}
+	var reason string
+	var news []engine.News_t
+	var clanNews []engine.ClanNews_t
+	if clan.Leader == emp.Id {
+		// transfer ownership if possible
+		reason += "clan leader, "
+		// first candidate is the assistant leader, alive or not - if they're dead, it'll transfer again shortly
+		heir, event := clan.Assistant, engine.CLANNEWS_PERM_ASSISTANT_INHERIT
+		clan.Assistant = 0
+		if heir == 0 {
+			members, err := tx.ClanMembers(clan.Id)
+			if err != nil {
+				return "", err
+			}
+			heir = inheritor(members, emp.Id)
+			event = engine.CLANNEWS_PERM_MEMBER_INHERIT
+			if heir != 0 && (clan.Minister1 == heir || clan.Minister2 == heir) {
+				event = engine.CLANNEWS_PERM_MINISTER_INHERIT
+				vacate(clan, heir)
+			}
+		}
+		if heir != 0 {
+			leader, err := tx.EmpireFetch(heir)
+			if err != nil {
+				return "", err
+			}
+			clan.Leader = leader.Id
+			news = append(news, engine.NewNews(engine.EMPNEWS_CLAN_INHERIT_LEADER, emp, leader))
+			clanNews = append(clanNews, engine.NewClanNews(event, clan, leader, nil, emp))
+			reason += fmt.Sprintf("to %s (#%d), ", leader.Name, leader.Id)
+		} else {
+			// by now, the empire is guaranteed to be the last member, so the clan is abandoned
+			clan.Leader = 0
+			reason += "abandoned, "
+			if err := disband(tx, emp, clan, now); err != nil {
+				return "", err
+			}
+		}
+	} else if clan.Assistant == emp.Id {
+		reason += "clan asst, "
+	} else if clan.Minister1 == emp.Id {
+		reason += "clan fa1, "
+	} else if clan.Minister2 == emp.Id {
+		reason += "clan fa2, "
+	}
+	vacate(clan, emp.Id)
+	clan.Members--
+	if err := tx.ClanUpdate(clan); err != nil {
+		return "", err
+	} else if err := tx.EmpireNewsCreate(now, news...); err != nil {
+		return "", err
+	} else if err := tx.ClanNewsCreate(now, clanNews...); err != nil {
+		return "", err
+	}
+	emp.OldCId, emp.CId = emp.CId, 0
+	return reason, nil
+}
+
+// inheritor returns the member that should inherit the clan from the leader,
+// or zero if the leader is the only member left.
+func inheritor(members []*orm.ClanMember_t, leaderId int) int {
+	for _, ok := range []func(m *orm.ClanMember_t) bool{
+		// the strongest member who isn't dead, deleted, or disabled
+		func(m *orm.ClanMember_t) bool { return !m.Flags.Delete && !m.Flags.Disable && m.Land > 0 },
+		// if everybody's dead, then pick one of them anyway (as long as they aren't disabled)
+		func(m *orm.ClanMember_t) bool { return !m.Flags.Disable },
+		// last resort, NEED to choose a leader, even if it's disabled
+		func(m *orm.ClanMember_t) bool { return true },
+	} {
+		for _, m := range members {
+			if m.Id != leaderId && ok(m) {
+				return m.Id
+			}
+		}
+	}
+	return 0
+}
+
+// vacate removes the empire from any office it holds in the clan other than leader.
+func vacate(clan *model.Clan_t, empireId int) {
+	if clan.Assistant == empireId {
+		clan.Assistant = 0
+	}
+	if clan.Minister1 == empireId {
+		clan.Minister1 = 0
+	}
+	if clan.Minister2 == empireId {
+		clan.Minister2 = 0
+	}
+}
+
+// disband tells every empire invited to join the clan that it is gone, then
+// deletes the invitations.
+func disband(tx *orm.DB, emp *model.Empire_t, clan *model.Clan_t, now time.Time) error {
+	invited, err := tx.ClanInviteRecipients(clan.Id)
+	if err != nil {
+		return err
+	}
+	for _, id := range invited {
+		recipient, err := tx.EmpireFetch(id)
+		if err != nil {
+			return err
+		} else if err := tx.EmpireNewsCreate(now, engine.NewNews(engine.EMPNEWS_CLAN_INVITE_DISBANDED, emp, recipient)); err != nil {
+			return err
+		}
+	}
+	return tx.ClanInvitesDelete(clan.Id)
+}
+
+// update runs a clan action against the empire in a single transaction.
+// Rejected actions change nothing.
+func (s *Clans_t) update(empireId int, round model.RoundData_t, now time.Time, action func(tx *orm.DB, emp *model.Empire_t, fx *engine.Effects_t, res *Result_t) error) (*Result_t, error) {
+	res := &Result_t{}
+	err := s.db.Transaction(func(tx *orm.DB) error {
+		emp, err := tx.EmpireFetch(empireId)
+		if err != nil {
+			return err
+		} else if err := s.available(emp, round); err != nil {
+			return err
+		}
+		fx, err := tx.EmpireEffectsFetch(emp.Id, s.tables.Effects)
+		if err != nil {
+			return err
+		}
+		if err := action(tx, emp, fx, res); err != nil {
+			return err
+		}
+		if res.Success {
+			if err := tx.EmpireAttributesUpdate(emp); err != nil {
+				return err
+			} else if err := tx.EmpireEffectsSave(fx); err != nil {
+				return err
+			}
+		}
+		res.State, err = s.state(tx, emp, fx, round, now)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// reject adds a message explaining why the action was not taken.
+func (res *Result_t) reject(key string, args ...any) error {
+	res.Messages = append(res.Messages, engine.Message_t{Key: key, Args: args})
+	return nil
+}
+
+// succeed marks the action as taken and adds a message describing it.
+func (res *Result_t) succeed(key string, args ...any) error {
+	res.Success = true
+	res.Messages = append(res.Messages, engine.Message_t{Key: key, Args: args})
+	return nil
+}
+
+// available returns an error if the empire may not use the clan page.
+func (s *Clans_t) available(emp *model.Empire_t, round model.RoundData_t) error {
+	if round.Finished {
+		return cerr.ErrRoundFinished
+	} else if !round.Started {
+		return cerr.ErrRoundNotStarted
+	} else if s.e.IsProtected(emp, round) {
+		return cerr.ErrEmpireProtected
+	} else if emp.Flags.Admin {
+		return cerr.ErrEmpireAdmin
+	} else if !s.cfg.ClanEnable {
+		return cerr.ErrClansDisabled
+	}
+	return nil
+}
+
+// state returns the empire's clan and its relations, the other clans,
+// and the invitations the empire may see.
+func (s *Clans_t) state(tx *orm.DB, emp *model.Empire_t, fx *engine.Effects_t, round model.RoundData_t, now time.Time) (*State_t, error) {
+	state := &State_t{
+		Wait:    fx.Get("m_clan", now),
+		Closing: round.Closing,
+	}
+	clans, err := tx.Clans()
+	if err != nil {
+		return nil, err
+	}
+	for _, clan := range clans {
+		if clan.Id != emp.CId {
+			clan.Password = "" // never let the hash leave the service
+			state.Clans = append(state.Clans, clan)
+		}
+	}
+	if emp.CId == 0 {
+		received, err := tx.ClanInvitesReceived(emp.Id)
+		if err != nil {
+			return nil, err
+		} else if state.Invites, err = invites(tx, received); err != nil {
+			return nil, err
+		}
+		return state, nil
+	}
+	clan, err := tx.ClanFetch(emp.CId)
+	if err != nil {
+		return nil, err
+	}
+	clan.Password = ""
+	state.Clan = clan
+	if state.Members, err = tx.ClanMembers(clan.Id); err != nil {
+		return nil, err
+	} else if state.Relations, err = relations(tx, clan.Id); err != nil {
+		return nil, err
+	}
+	s.sharing(state, emp)
+	if slices.Contains([]int{clan.Leader, clan.Assistant, clan.Minister1, clan.Minister2}, emp.Id) {
+		sent, err := tx.ClanInvites(clan.Id)
+		if err != nil {
+			return nil, err
+		} else if state.Invites, err = invites(tx, sent); err != nil {
+			return nil, err
+		}
+	}
+	return state, nil
+}
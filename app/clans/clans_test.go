@@ -0,0 +1,366 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package clans
+
+import (
+	"errors"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/orm"
+	"golang.org/x/crypto/bcrypt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPassword(t *testing.T) {
+	hash, err := HashPassword("secret")
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	} else if cost, err := bcrypt.Cost([]byte(hash)); err != nil || cost != bcrypt.DefaultCost {
+		t.Errorf("hash: want cost %d, got %d %v", bcrypt.DefaultCost, cost, err)
+	}
+	cheap, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("cheap: %v", err)
+	}
+	for _, tc := range []struct {
+		id       string
+		password string
+		hash     string
+		ok       bool
+		rehash   bool
+	}{
+		{"current", "secret", hash, true, false},
+		{"current wrong", "Secret", hash, false, false},
+		{"cheap", "secret", string(cheap), true, true},
+		{"cheap wrong", "secret!", string(cheap), false, false},
+		{"md5", "secret", "5ebe2294ecd0e0f08eab7690d2a6ee69", false, false},
+		{"empty", "", "", false, false},
+	} {
+		if ok, rehash := CheckPassword(tc.password, tc.hash); ok != tc.ok || rehash != tc.rehash {
+			t.Errorf("%s: want %v %v, got %v %v", tc.id, tc.ok, tc.rehash, ok, rehash)
+		}
+	}
+}
+
+func TestLifecycle(t *testing.T) {
+	db, s := testService(t)
+	leader := testEmpire(t, db, "leader", 1000)
+	member := testEmpire(t, db, "member", 500)
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	started := model.RoundData_t{Started: true}
+
+	if _, err := s.State(leader.Id, model.RoundData_t{}, now); !errors.Is(err, cerr.ErrRoundNotStarted) {
+		t.Errorf("not started: want %v, got %v", cerr.ErrRoundNotStarted, err)
+	}
+	if _, err := s.Create(leader.Id, started, "PIRATES", "arr", "arr", now); !errors.Is(err, cerr.ErrClansDisabled) {
+		t.Errorf("disabled: want %v, got %v", cerr.ErrClansDisabled, err)
+	}
+
+	for _, tc := range []struct {
+		id                     string
+		round                  model.RoundData_t
+		name, password, verify string
+		want                   string
+	}{
+		{"closing", model.RoundData_t{Started: true, Closing: true}, "PIRATES", "arr", "arr", "CLAN_CREATE_TOO_LATE"},
+		{"no name", started, "", "arr", "arr", "CLAN_CREATE_NEED_NAME"},
+		{"reserved", started, "None", "arr", "arr", "CLAN_CREATE_NAME_INVALID"},
+		{"too long", started, "BUCCANEERS", "arr", "arr", "CLAN_CREATE_NAME_TOO_LONG"},
+		{"no password", started, "PIRATES", "", "", "INPUT_NEED_PASSWORD"},
+		{"mismatch", started, "PIRATES", "arr", "ARR", "INPUT_PASSWORD_MISMATCH"},
+		{"create", started, "PIRATES", "arr", "arr", "CLAN_CREATE_COMPLETE"},
+		{"already member", started, "NINJAS", "arr", "arr", "CLAN_ALREADY_MEMBER"},
+	} {
+		res, err := s.enabled().Create(leader.Id, tc.round, tc.name, tc.password, tc.verify, now)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.id, err)
+		} else if len(res.Messages) != 1 || res.Messages[0].Key != tc.want {
+			t.Errorf("%s: want %s, got %+v", tc.id, tc.want, res.Messages)
+		}
+	}
+	s = s.enabled()
+	state, err := s.State(leader.Id, started, now)
+	if err != nil {
+		t.Fatalf("state: %v", err)
+	} else if state.Clan == nil || state.Clan.Name != "PIRATES" || state.Clan.Title != "Clan PIRATES" || state.Clan.Leader != leader.Id || state.Clan.Members != 1 {
+		t.Fatalf("state: got %+v", state.Clan)
+	} else if state.Clan.Password != "" {
+		t.Errorf("state: password hash exposed")
+	} else if state.Wait != 72*3600 {
+		t.Errorf("state: wait: want %d, got %d", 72*3600, state.Wait)
+	}
+	clanId := state.Clan.Id
+
+	// the name is taken, even by another empire
+	res, err := s.Create(member.Id, started, "PIRATES", "arr", "arr", now)
+	if err != nil {
+		t.Fatalf("in use: %v", err)
+	} else if res.Messages[0].Key != "CLAN_CREATE_NAME_IN_USE" {
+		t.Errorf("in use: got %+v", res.Messages)
+	}
+
+	for _, tc := range []struct {
+		id       string
+		clanId   int
+		password string
+		want     string
+	}{
+		{"no clan", 0, "arr", "CLAN_JOIN_NEED_CLAN"},
+		{"wrong password", clanId, "yo ho", "INPUT_INCORRECT_PASSWORD"},
+		{"join", clanId, "arr", "CLAN_JOIN_COMPLETE"},
+		{"already member", clanId, "arr", "CLAN_ALREADY_MEMBER"},
+	} {
+		res, err := s.Join(member.Id, started, tc.clanId, tc.password, now)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.id, err)
+		} else if len(res.Messages) != 1 || res.Messages[0].Key != tc.want {
+			t.Errorf("%s: want %s, got %+v", tc.id, tc.want, res.Messages)
+		}
+	}
+
+	// members can't leave until they've been in the clan long enough
+	res, err = s.Leave(member.Id, started, true, now)
+	if err != nil {
+		t.Fatalf("leave: %v", err)
+	} else if res.Messages[0].Key != "CLAN_LEAVE_TOO_SOON" || res.Messages[0].Args[0] != 72 {
+		t.Errorf("leave: got %+v", res.Messages)
+	}
+	later := now.Add(73 * time.Hour)
+	for _, tc := range []struct {
+		id      string
+		empire  int
+		confirm bool
+		want    string
+	}{
+		{"unconfirmed", member.Id, false, "CLAN_LEAVE_NEED_CONFIRM"},
+		{"abandon", leader.Id, true, "CLAN_LEAVE_OTHER_MEMBERS"},
+		{"leave", member.Id, true, "CLAN_LEAVE_COMPLETE"},
+		{"not member", member.Id, true, "CLAN_NOT_MEMBER"},
+		{"disband", leader.Id, true, "CLAN_LEAVE_COMPLETE_DISBAND"},
+	} {
+		res, err := s.Leave(tc.empire, started, tc.confirm, later)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.id, err)
+		} else if len(res.Messages) != 1 || res.Messages[0].Key != tc.want {
+			t.Errorf("%s: want %s, got %+v", tc.id, tc.want, res.Messages)
+		}
+	}
+
+	// former members must wait before joining another clan
+	res, err = s.Join(member.Id, started, clanId, "arr", later)
+	if err != nil {
+		t.Fatalf("rejoin: %v", err)
+	} else if res.Messages[0].Key != "CLAN_JOIN_TOO_SOON" {
+		t.Errorf("rejoin: got %+v", res.Messages)
+	}
+	res, err = s.Join(member.Id, started, clanId, "arr", later.Add(25*time.Hour))
+	if err != nil {
+		t.Fatalf("disbanded: %v", err)
+	} else if res.Messages[0].Key != "CLAN_JOIN_DISBANDED" {
+		t.Errorf("disbanded: got %+v", res.Messages)
+	}
+
+	news, err := db.ClanNews(clanId, time.Time{})
+	if err != nil {
+		t.Fatalf("news: %v", err)
+	}
+	var events []int
+	for _, n := range news {
+		events = append(events, n.Event)
+	}
+	if want := []int{engine.CLANNEWS_MEMBER_CREATE, engine.CLANNEWS_MEMBER_JOIN, engine.CLANNEWS_MEMBER_LEAVE}; !slices.Equal(events, want) {
+		t.Errorf("news: want %v, got %v", want, events)
+	}
+}
+
+func TestSetPassword(t *testing.T) {
+	db, s := testService(t)
+	s = s.enabled()
+	leader := testEmpire(t, db, "leader", 1000)
+	member := testEmpire(t, db, "member", 500)
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	started := model.RoundData_t{Started: true}
+
+	res, err := s.Create(leader.Id, started, "PIRATES", "arr", "arr", now)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	clanId := res.State.Clan.Id
+	if _, err := s.Join(member.Id, started, clanId, "arr", now); err != nil {
+		t.Fatalf("join: %v", err)
+	}
+	if _, err := s.SetPassword(member.Id, started, "yo ho", "yo ho", now); !errors.Is(err, cerr.ErrNotClanOfficer) {
+		t.Errorf("member: want %v, got %v", cerr.ErrNotClanOfficer, err)
+	}
+	if res, err := s.SetPassword(leader.Id, started, "yo ho", "yo ho", now); err != nil {
+		t.Fatalf("leader: %v", err)
+	} else if !res.Success || res.Messages[0].Key != "MANAGE_CLAN_PASSWORD_COMPLETE" {
+		t.Errorf("leader: got %+v", res.Messages)
+	}
+	clan, err := db.ClanFetch(clanId)
+	if err != nil {
+		t.Fatalf("clan: %v", err)
+	} else if ok, _ := CheckPassword("yo ho", clan.Password); !ok {
+		t.Errorf("clan: password not changed")
+	}
+
+	// hashes with a lower cost are replaced the first time someone joins
+	cheap, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("cheap: %v", err)
+	}
+	clan.Password = string(cheap)
+	if err := db.ClanUpdate(clan); err != nil {
+		t.Fatalf("clan: %v", err)
+	}
+	third := testEmpire(t, db, "deckhand", 100)
+	if res, err := s.Join(third.Id, started, clanId, "secret", now); err != nil {
+		t.Fatalf("cheap: %v", err)
+	} else if !res.Success {
+		t.Errorf("cheap: got %+v", res.Messages)
+	} else if clan, err = db.ClanFetch(clanId); err != nil {
+		t.Fatalf("clan: %v", err)
+	} else if cost, err := bcrypt.Cost([]byte(clan.Password)); err != nil || cost != bcrypt.DefaultCost {
+		t.Errorf("cheap: not rehashed: %q", clan.Password)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	db, s := testService(t)
+	s = s.enabled()
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	started := model.RoundData_t{Started: true}
+
+	leader := testEmpire(t, db, "leader", 1000)
+	weak := testEmpire(t, db, "weakling", 100)
+	strong := testEmpire(t, db, "stalwart", 900)
+	minister := testEmpire(t, db, "minister", 500)
+	res, err := s.Create(leader.Id, started, "PIRATES", "arr", "arr", now)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	clanId := res.State.Clan.Id
+	for _, emp := range []*model.Empire_t{weak, strong, minister} {
+		if res, err := s.Join(emp.Id, started, clanId, "arr", now); err != nil || !res.Success {
+			t.Fatalf("join: %v %+v", err, res)
+		}
+	}
+	clan, err := db.ClanFetch(clanId)
+	if err != nil {
+		t.Fatalf("clan: %v", err)
+	}
+	clan.Assistant, clan.Minister1 = weak.Id, minister.Id
+	if err := db.ClanUpdate(clan); err != nil {
+		t.Fatalf("clan: %v", err)
+	}
+	// the strong member is disabled, so it is passed over
+	strong, _ = db.EmpireFetch(strong.Id)
+	strong.Flags.Disable = true
+	if err := db.EmpireUpdateFlags(strong); err != nil {
+		t.Fatalf("flags: %v", err)
+	}
+
+	remove := func(id int) *model.Empire_t {
+		emp, err := db.EmpireFetch(id)
+		if err != nil {
+			t.Fatalf("fetch: %v", err)
+		}
+		if _, err := Remove(db, emp, now); err != nil {
+			t.Fatalf("remove: %v", err)
+		} else if err := db.EmpireAttributesUpdate(emp); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+		return emp
+	}
+
+	// the assistant inherits first
+	if emp := remove(leader.Id); emp.CId != 0 || emp.OldCId != clanId {
+		t.Errorf("leader: got clan %d, old %d", emp.CId, emp.OldCId)
+	}
+	if clan, _ = db.ClanFetch(clanId); clan.Leader != weak.Id || clan.Assistant != 0 || clan.Members != 3 {
+		t.Errorf("assistant: got %+v", clan)
+	}
+	// then the strongest member that isn't dead or disabled, giving up their ministry
+	remove(weak.Id)
+	if clan, _ = db.ClanFetch(clanId); clan.Leader != minister.Id || clan.Minister1 != 0 || clan.Members != 2 {
+		t.Errorf("minister: got %+v", clan)
+	}
+	// then anybody at all
+	remove(minister.Id)
+	if clan, _ = db.ClanFetch(clanId); clan.Leader != strong.Id || clan.Members != 1 {
+		t.Errorf("disabled: got %+v", clan)
+	}
+	// and the last member abandons the clan
+	remove(strong.Id)
+	if clan, _ = db.ClanFetch(clanId); clan.Leader != 0 || clan.Members != 0 {
+		t.Errorf("abandoned: got %+v", clan)
+	}
+
+	news, err := db.ClanNews(clanId, time.Time{})
+	if err != nil {
+		t.Fatalf("news: %v", err)
+	}
+	var events []int
+	for _, n := range news {
+		if n.Event >= 200 {
+			events = append(events, n.Event)
+		}
+	}
+	if want := []int{engine.CLANNEWS_PERM_ASSISTANT_INHERIT, engine.CLANNEWS_PERM_MINISTER_INHERIT, engine.CLANNEWS_PERM_MEMBER_INHERIT}; !slices.Equal(events, want) {
+		t.Errorf("news: want %v, got %v", want, events)
+	}
+	if news[len(news)-1].Empire1 != strong.Id || news[len(news)-1].Empire2 != minister.Id {
+		t.Errorf("news: got %+v", news[len(news)-1])
+	}
+}
+
+// enabled returns a copy of the service with clans enabled.
+func (s *Clans_t) enabled() *Clans_t {
+	c := *s
+	c.cfg.ClanEnable = true
+	return &c
+}
+
+func testService(t *testing.T) (*orm.DB, *Clans_t) {
+	t.Helper()
+	db, err := orm.CreateMemoryDatabase()
+	if err != nil {
+		t.Fatalf("database: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	s, err := New(db, engine.New(engine.Config_t{}, nil), engine.DefaultTables(), Config_t{
+		MinJoin:      72,
+		MinRejoin:    24,
+		MinShare:     2,
+		DefaultTitle: "Clan %s",
+		DefaultMotd:  "Welcome to clan %s!",
+		Reserved:     func(name string) bool { return name == "None" },
+		IsLangKey:    func(text string) bool { return text == "COMMON_YES" },
+	})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	return db, s
+}
+
+func testEmpire(t *testing.T, db *orm.DB, name string, networth int) *model.Empire_t {
+	t.Helper()
+	user, err := db.UserCreate(name, name+"@example.com")
+	if err != nil {
+		t.Fatalf("user: %v", err)
+	}
+	emp, err := db.EmpireCreate(user, name, "HUMAN")
+	if err != nil {
+		t.Fatalf("empire: %v", err)
+	}
+	emp.Land, emp.NetWorth = 250, networth
+	if err := db.EmpireAttributesUpdate(emp); err != nil {
+		t.Fatalf("empire: %v", err)
+	}
+	return emp
+}
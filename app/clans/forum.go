@@ -0,0 +1,609 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package clans
+
+import (
+	"cmp"
+	"database/sql"
+	"errors"
+	"github.com/mdhender/promisance/app/bbcode"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/orm"
+	"html"
+	"regexp"
+	"slices"
+	"time"
+)
+
+// Forum_t is a page of the clan forum, from php/pages/clanforum.php.
+// The page shows the index of topics when Topic is nil.
+// Subjects and bodies are HTML; they were escaped before they were saved.
+type Forum_t struct {
+	Clan      *model.Clan_t
+	Moderator bool       // the leader and the assistant moderate the forum
+	Ranked    bool       // any of the clan's officers may post in the news topic
+	Topics    []*Topic_t // the topics on this page of the index
+	Topic     *Topic_t   // the topic being viewed
+	Posts     []*Post_t  // the posts on this page of the topic
+	CanPost   bool       // the empire may reply to the topic
+	Quote     string     // BBcode quoting a post, to start the reply with
+	Post      *Post_t    // the post being edited or deleted
+	First     bool       // Post is the first in the topic, so editing it changes the topic
+	KillTopic bool       // deleting Post deletes the whole topic
+	Page      int
+	Pages     int
+
+	topicId int // topic to view after the action, or zero for the index
+	focus   int // post whose page should be shown
+}
+
+// Topic_t summarizes a topic in the clan forum.
+type Topic_t struct {
+	Id             int
+	Subject        string // empty for the news topic
+	News           bool
+	Sticky         bool
+	Locked         bool
+	Author         int // author of the first post
+	AuthorName     string
+	Replies        int
+	LastPoster     int
+	LastPosterName string
+	LastPost       time.Time
+}
+
+// Post_t is a post in the clan forum.
+type Post_t struct {
+	Id         int
+	Author     int
+	AuthorName string
+	Label      string // language key for the author's rank in the clan
+	Body       string
+	Time       time.Time
+	Edited     bool
+	CanEdit    bool
+	CanDelete  bool
+}
+
+// reQuote matches quotes inside a quoted post; they are dropped so quotes don't nest.
+var reQuote = regexp.MustCompile(`(?s)\[quote\].*?\[/quote\]`)
+
+// Forum returns a page of the clan forum's index, or of a topic if topicId
+// is not zero. If quoteId is a post in the topic, the reply starts with a
+// quote of it and the page holding it is shown.
+func (s *Clans_t) Forum(empireId int, round model.RoundData_t, topicId, page, quoteId int) (*Result_t, error) {
+	return s.forum(empireId, round, func(tx *orm.DB, emp *model.Empire_t, f *Forum_t, res *Result_t) error {
+		f.topicId, f.Page, f.focus = topicId, page, quoteId
+		return nil
+	})
+}
+
+// NewTopic starts a new topic in the clan forum.
+// Only moderators may make the topic sticky or lock it.
+func (s *Clans_t) NewTopic(empireId int, round model.RoundData_t, subject, body string, sticky, locked bool, now time.Time) (*Result_t, error) {
+	return s.forum(empireId, round, func(tx *orm.DB, emp *model.Empire_t, f *Forum_t, res *Result_t) error {
+		subject, body = html.EscapeString(subject), html.EscapeString(body)
+		flags := 0
+		if f.Moderator {
+			flags = topicFlags(0, sticky, locked)
+		}
+		if !s.checkSubject(subject, res) {
+			return nil
+		}
+		encoded, ok := s.checkBody(body, res)
+		if !ok {
+			return nil
+		}
+		topicId, err := tx.ClanTopicCreate(emp.CId, subject, flags)
+		if err != nil {
+			return err
+		} else if _, err := tx.ClanMessageCreate(topicId, emp.Id, encoded, 0, now); err != nil {
+			return err
+		}
+		f.topicId = topicId
+		return res.succeed("CLANFORUM_POST_COMPLETE")
+	})
+}
+
+// Reply adds a post to the end of a topic.
+// Only moderators may reply to locked topics, and only officers to the news topic.
+func (s *Clans_t) Reply(empireId int, round model.RoundData_t, topicId int, body string, now time.Time) (*Result_t, error) {
+	return s.forum(empireId, round, func(tx *orm.DB, emp *model.Empire_t, f *Forum_t, res *Result_t) error {
+		topic, err := s.topic(tx, emp, topicId, f, res)
+		if err != nil || topic == nil {
+			return err
+		} else if topic.Flags&orm.CTFLAG_LOCK != 0 && !f.Moderator {
+			return res.reject("CLANFORUM_REPLY_LOCKED")
+		} else if topic.Flags&orm.CTFLAG_NEWS != 0 && !f.Ranked {
+			return res.reject("CLANFORUM_REPLY_NEWS")
+		}
+		encoded, ok := s.checkBody(html.EscapeString(body), res)
+		if !ok {
+			return nil
+		}
+		postId, err := tx.ClanMessageCreate(topic.Id, emp.Id, encoded, 0, now)
+		if err != nil {
+			return err
+		}
+		f.focus = postId
+		return res.succeed("CLANFORUM_REPLY_COMPLETE")
+	})
+}
+
+// Message checks that the empire may edit or delete the post, and returns
+// the topic with the post set for the form that does it.
+func (s *Clans_t) Message(empireId int, round model.RoundData_t, topicId, postId int, deleting bool) (*Result_t, error) {
+	return s.forum(empireId, round, func(tx *orm.DB, emp *model.Empire_t, f *Forum_t, res *Result_t) error {
+		prefix := "CLANFORUM_EDIT_"
+		if deleting {
+			prefix = "CLANFORUM_DELETE_"
+		}
+		topic, post, posts, err := s.message(tx, emp, topicId, postId, prefix, f, res)
+		if err != nil || post == nil {
+			return err
+		}
+		if deleting {
+			var ok bool
+			if f.KillTopic, ok = killTopic(topic, post, posts, res); !ok {
+				return nil
+			}
+		}
+		return s.setPost(tx, f, topic, post, posts)
+	})
+}
+
+// Edit changes the body of a post and marks it as edited. Editing the first
+// post of a topic also changes its subject and, for moderators, whether it is
+// sticky or locked. Members may only edit their own posts, and only until they
+// are replied to; moderators may edit any post.
+func (s *Clans_t) Edit(empireId int, round model.RoundData_t, topicId, postId int, subject, body string, sticky, locked bool, now time.Time) (*Result_t, error) {
+	return s.forum(empireId, round, func(tx *orm.DB, emp *model.Empire_t, f *Forum_t, res *Result_t) error {
+		topic, post, posts, err := s.message(tx, emp, topicId, postId, "CLANFORUM_EDIT_", f, res)
+		if err != nil || post == nil {
+			return err
+		}
+		// past this point, fall back to the edit form
+		if err := s.setPost(tx, f, topic, post, posts); err != nil {
+			return err
+		}
+		if f.First {
+			if f.Moderator && topic.Flags&orm.CTFLAG_NEWS == 0 {
+				topic.Flags = topicFlags(topic.Flags&^(orm.CTFLAG_STICKY|orm.CTFLAG_LOCK), sticky, locked)
+			}
+			// the news topic never has a subject
+			if topic.Flags&orm.CTFLAG_NEWS == 0 {
+				subject = html.EscapeString(subject)
+				if !s.checkSubject(subject, res) {
+					return nil
+				}
+				topic.Subject = subject
+			}
+		}
+		encoded, ok := s.checkBody(html.EscapeString(body), res)
+		if !ok {
+			return nil
+		}
+		// posts that have been replied to keep their place in the topic
+		if post.Id == posts[len(posts)-1].Id {
+			post.Time = now
+		}
+		post.Body, post.Flags = encoded, post.Flags|orm.CMFLAG_EDIT
+		if err := tx.ClanMessageUpdate(post); err != nil {
+			return err
+		} else if f.First {
+			if err := tx.ClanTopicUpdate(topic); err != nil {
+				return err
+			}
+		}
+		f.Post, f.First, f.focus = nil, false, post.Id
+		return res.succeed("CLANFORUM_EDIT_COMPLETE")
+	})
+}
+
+// Delete removes a post from its topic. Deleting the first post removes the
+// whole topic, except for the news topic, whose only post may not be deleted.
+// Members may only delete their own posts, and only until they are replied to;
+// moderators may delete any post.
+func (s *Clans_t) Delete(empireId int, round model.RoundData_t, topicId, postId int) (*Result_t, error) {
+	return s.forum(empireId, round, func(tx *orm.DB, emp *model.Empire_t, f *Forum_t, res *Result_t) error {
+		topic, post, posts, err := s.message(tx, emp, topicId, postId, "CLANFORUM_DELETE_", f, res)
+		if err != nil || post == nil {
+			return err
+		}
+		kill, ok := killTopic(topic, post, posts, res)
+		if !ok {
+			return nil
+		}
+		if kill {
+			topic.Flags |= orm.CTFLAG_DELETE
+			if err := tx.ClanTopicUpdate(topic); err != nil {
+				return err
+			} else if err := tx.ClanMessagesDelete(topic.Id); err != nil {
+				return err
+			}
+			f.topicId = 0
+			return res.succeed("CLANFORUM_DELETE_TOPIC_COMPLETE")
+		}
+		post.Flags |= orm.CMFLAG_DELETE
+		if err := tx.ClanMessageUpdate(post); err != nil {
+			return err
+		}
+		// stay on the page that held the post
+		i := slices.IndexFunc(posts, func(p *orm.ClanMessage_t) bool { return p.Id == post.Id })
+		f.focus = posts[max(i-1, 0)].Id
+		return res.succeed("CLANFORUM_DELETE_MESSAGE_COMPLETE")
+	})
+}
+
+// News returns the latest post in the news topic of the empire's clan, for
+// the main page. It returns nil if clans are disabled, the empire is not in a
+// clan, or the topic has no posts.
+func (s *Clans_t) News(empireId int) (*Post_t, error) {
+	if !s.cfg.ClanEnable {
+		return nil, nil
+	}
+	var news *Post_t
+	err := s.db.Transaction(func(tx *orm.DB) error {
+		emp, err := tx.EmpireFetch(empireId)
+		if err != nil || emp.CId == 0 {
+			return err
+		}
+		topics, err := tx.ClanTopics(emp.CId)
+		if err != nil {
+			return err
+		}
+		for _, topic := range topics {
+			if topic.Flags&orm.CTFLAG_NEWS == 0 {
+				continue
+			}
+			posts, err := tx.ClanMessages(topic.Id)
+			if err != nil {
+				return err
+			} else if len(posts) == 0 {
+				return nil
+			}
+			// edits don't move posts, so the latest is the one with the newest time
+			latest := slices.MaxFunc(posts, func(a, b *orm.ClanMessage_t) int {
+				return cmp.Or(a.Time.Compare(b.Time), cmp.Compare(a.Id, b.Id))
+			})
+			author, err := tx.EmpireFetch(latest.Empire)
+			if err != nil {
+				return err
+			}
+			news = &Post_t{
+				Id:         latest.Id,
+				Author:     author.Id,
+				AuthorName: author.Name,
+				Body:       latest.Body,
+				Time:       latest.Time,
+				Edited:     latest.Flags&orm.CMFLAG_EDIT != 0,
+			}
+			return nil
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return news, nil
+}
+
+// forum runs a forum action for a member of a clan in a single transaction,
+// then loads the page of the forum that the action leaves the empire on.
+func (s *Clans_t) forum(empireId int, round model.RoundData_t, action func(tx *orm.DB, emp *model.Empire_t, f *Forum_t, res *Result_t) error) (*Result_t, error) {
+	res := &Result_t{}
+	err := s.db.Transaction(func(tx *orm.DB) error {
+		emp, err := tx.EmpireFetch(empireId)
+		if err != nil {
+			return err
+		} else if err := s.available(emp, round); err != nil {
+			return err
+		} else if emp.CId == 0 {
+			return cerr.ErrNotClanMember
+		}
+		clan, err := tx.ClanFetch(emp.CId)
+		if err != nil {
+			return err
+		}
+		clan.Password = ""
+		f := &Forum_t{
+			Clan:      clan,
+			Moderator: leads(clan, emp),
+			Ranked:    slices.Contains([]int{clan.Leader, clan.Assistant, clan.Minister1, clan.Minister2}, emp.Id),
+		}
+		if err := action(tx, emp, f, res); err != nil {
+			return err
+		}
+		res.Forum = f
+		if f.topicId != 0 {
+			return s.view(tx, emp, f, res)
+		}
+		return s.index(tx, f)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// index loads a page of the forum's index. The news topic comes first, then
+// the sticky topics, then the rest, each with the most recent posts first.
+func (s *Clans_t) index(tx *orm.DB, f *Forum_t) error {
+	topics, err := tx.ClanTopics(f.Clan.Id)
+	if err != nil {
+		return err
+	}
+	names := map[int]string{}
+	var list []*Topic_t
+	for _, topic := range topics {
+		posts, err := tx.ClanMessages(topic.Id)
+		if err != nil {
+			return err
+		}
+		t, err := summarize(tx, topic, posts, names)
+		if err != nil {
+			return err
+		}
+		list = append(list, t)
+	}
+	group := func(t *Topic_t) int {
+		if t.News {
+			return 0
+		} else if t.Sticky {
+			return 1
+		}
+		return 2
+	}
+	slices.SortStableFunc(list, func(a, b *Topic_t) int {
+		return cmp.Or(cmp.Compare(group(a), group(b)), b.LastPost.Compare(a.LastPost), cmp.Compare(b.Id, a.Id))
+	})
+	var lo, hi int
+	f.Page, f.Pages, lo, hi = paginate(len(list), s.cfg.TopicsPerPage, f.Page)
+	f.Topics = list[lo:hi]
+	return nil
+}
+
+// view loads the page of the topic holding the focused post, or the requested
+// page if there isn't one. Topics that can't be viewed fall back to the index.
+func (s *Clans_t) view(tx *orm.DB, emp *model.Empire_t, f *Forum_t, res *Result_t) error {
+	topic, err := s.topic(tx, emp, f.topicId, f, res)
+	if err != nil {
+		return err
+	} else if topic == nil {
+		f.Post, f.Page = nil, 0
+		return s.index(tx, f)
+	}
+	posts, err := tx.ClanMessages(topic.Id)
+	if err != nil {
+		return err
+	}
+	names := map[int]string{}
+	if f.Topic, err = summarize(tx, topic, posts, names); err != nil {
+		return err
+	}
+	// if it's locked, you need to be a moderator; if it's the News thread, you need any special rank
+	f.CanPost = (topic.Flags&orm.CTFLAG_LOCK == 0 || f.Moderator) && (topic.Flags&orm.CTFLAG_NEWS == 0 || f.Ranked)
+
+	if i := slices.IndexFunc(posts, func(p *orm.ClanMessage_t) bool { return p.Id == f.focus }); i != -1 {
+		f.Page = i/s.cfg.PostsPerPage + 1
+	}
+	var lo, hi int
+	f.Page, f.Pages, lo, hi = paginate(len(posts), s.cfg.PostsPerPage, f.Page)
+	for i := lo; i < hi; i++ {
+		post, err := s.post(tx, emp, f, topic, posts, i, names)
+		if err != nil {
+			return err
+		}
+		f.Posts = append(f.Posts, post)
+	}
+	if f.CanPost && f.Post == nil {
+		for _, post := range posts {
+			if post.Id == f.focus {
+				f.Quote = "[quote]" + reQuote.ReplaceAllString(bbcode.Decode(post.Body), "") + "[/quote]"
+			}
+		}
+	}
+	return nil
+}
+
+// post converts a post in the topic, adding its author and what the empire may do with it.
+func (s *Clans_t) post(tx *orm.DB, emp *model.Empire_t, f *Forum_t, topic *orm.ClanTopic_t, posts []*orm.ClanMessage_t, i int, names map[int]string) (*Post_t, error) {
+	msg, first, last := posts[i], posts[0].Id, posts[len(posts)-1].Id
+	author, err := tx.EmpireFetch(msg.Empire)
+	if err != nil {
+		return nil, err
+	}
+	names[author.Id] = author.Name
+	post := &Post_t{
+		Id:         msg.Id,
+		Author:     author.Id,
+		AuthorName: author.Name,
+		Body:       msg.Body,
+		Time:       msg.Time,
+		Edited:     msg.Flags&orm.CMFLAG_EDIT != 0,
+	}
+	switch {
+	case author.CId != topic.Clan:
+		post.Label = "CLANFORUM_NONMEMBER_LABEL"
+	case author.Id == f.Clan.Leader:
+		post.Label = "CLAN_LEADER_LABEL"
+	case author.Id == f.Clan.Assistant:
+		post.Label = "CLAN_ASSISTANT_LABEL"
+	case author.Id == f.Clan.Minister1 || author.Id == f.Clan.Minister2:
+		post.Label = "CLANFORUM_FA_LABEL"
+	default:
+		post.Label = "CLANFORUM_MEMBER_LABEL"
+	}
+	if f.CanPost {
+		post.CanEdit = (msg.Empire == emp.Id && msg.Id == last) || f.Moderator
+		post.CanDelete = post.CanEdit && !(topic.Flags&orm.CTFLAG_NEWS != 0 && msg.Id == first && msg.Id == last)
+	}
+	return post, nil
+}
+
+// topic returns the topic if it exists and belongs to the empire's clan.
+// Otherwise it rejects the action and returns nil, which falls back to the index.
+func (s *Clans_t) topic(tx *orm.DB, emp *model.Empire_t, topicId int, f *Forum_t, res *Result_t) (*orm.ClanTopic_t, error) {
+	f.topicId = 0
+	topic, err := tx.ClanTopic(topicId)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && topic.Flags&orm.CTFLAG_DELETE != 0) {
+		return nil, res.reject("CLANFORUM_TOPIC_NOT_EXIST")
+	} else if err != nil {
+		return nil, err
+	} else if topic.Clan != emp.CId {
+		return nil, res.reject("CLANFORUM_TOPIC_WRONG_CLAN")
+	}
+	// topic is okay, can fall back to view from here on in
+	f.topicId = topic.Id
+	return topic, nil
+}
+
+// message returns the topic and the post if the empire may edit or delete it,
+// along with the posts in the topic. Otherwise it rejects the action, using
+// the messages with the given prefix, and returns a nil post.
+func (s *Clans_t) message(tx *orm.DB, emp *model.Empire_t, topicId, postId int, prefix string, f *Forum_t, res *Result_t) (*orm.ClanTopic_t, *orm.ClanMessage_t, []*orm.ClanMessage_t, error) {
+	topic, err := s.topic(tx, emp, topicId, f, res)
+	if err != nil || topic == nil {
+		return nil, nil, nil, err
+	} else if topic.Flags&orm.CTFLAG_LOCK != 0 && !f.Moderator {
+		return nil, nil, nil, res.reject(prefix + "LOCKED")
+	} else if topic.Flags&orm.CTFLAG_NEWS != 0 && !f.Ranked {
+		return nil, nil, nil, res.reject(prefix + "NEWS")
+	}
+	posts, err := tx.ClanMessages(topic.Id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	post, err := tx.ClanMessage(postId)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && (post.Topic != topic.Id || post.Flags&orm.CMFLAG_DELETE != 0)) {
+		return nil, nil, nil, res.reject("CLANFORUM_MESSAGE_NOT_EXIST")
+	} else if err != nil {
+		return nil, nil, nil, err
+	} else if post.Empire != emp.Id && !f.Moderator {
+		return nil, nil, nil, res.reject(prefix + "PERMISSION")
+	} else if post.Id != posts[len(posts)-1].Id && !f.Moderator {
+		// only moderators can change posts after they've been replied to
+		return nil, nil, nil, res.reject(prefix + "TOO_LATE")
+	}
+	return topic, post, posts, nil
+}
+
+// setPost sets the post being edited or deleted, showing the page that holds it.
+func (s *Clans_t) setPost(tx *orm.DB, f *Forum_t, topic *orm.ClanTopic_t, post *orm.ClanMessage_t, posts []*orm.ClanMessage_t) error {
+	author, err := tx.EmpireFetch(post.Empire)
+	if err != nil {
+		return err
+	}
+	f.Post = &Post_t{
+		Id:         post.Id,
+		Author:     author.Id,
+		AuthorName: author.Name,
+		Body:       post.Body,
+		Time:       post.Time,
+		Edited:     post.Flags&orm.CMFLAG_EDIT != 0,
+	}
+	f.First, f.focus = post.Id == posts[0].Id, post.Id
+	return nil
+}
+
+// killTopic returns true if deleting the post deletes the whole topic,
+// which happens when it is the first post. The news topic is never deleted,
+// so its only post may not be deleted either; ok is false if it is.
+func killTopic(topic *orm.ClanTopic_t, post *orm.ClanMessage_t, posts []*orm.ClanMessage_t, res *Result_t) (kill, ok bool) {
+	if post.Id != posts[0].Id {
+		return false, true
+	} else if topic.Flags&orm.CTFLAG_NEWS == 0 {
+		return true, true
+	} else if post.Id == posts[len(posts)-1].Id {
+		res.reject("CLANFORUM_DELETE_CANNOT_NEWS")
+		return false, false
+	}
+	return false, true
+}
+
+// checkSubject rejects subjects that are too long or that are language keys.
+func (s *Clans_t) checkSubject(subject string, res *Result_t) bool {
+	if len(subject) > 255 {
+		res.reject("CLANFORUM_SUBJECT_TOO_LONG")
+		return false
+	} else if s.cfg.IsLangKey != nil && s.cfg.IsLangKey(subject) {
+		res.reject("CLANFORUM_SUBJECT_INVALID")
+		return false
+	}
+	return true
+}
+
+// checkBody encodes the BBcode in the body. It rejects bodies that are too
+// long to save or that are language keys.
+func (s *Clans_t) checkBody(body string, res *Result_t) (string, bool) {
+	encoded := bbcode.Encode(body)
+	if len(encoded) > 65535 {
+		res.reject("CLANFORUM_BODY_TOO_LONG")
+		return "", false
+	} else if s.cfg.IsLangKey != nil && s.cfg.IsLangKey(encoded) {
+		res.reject("CLANFORUM_BODY_INVALID")
+		return "", false
+	}
+	return encoded, true
+}
+
+// topicFlags adds the sticky and locked flags to the topic's flags.
+func topicFlags(flags int, sticky, locked bool) int {
+	if sticky {
+		flags |= orm.CTFLAG_STICKY
+	}
+	if locked {
+		flags |= orm.CTFLAG_LOCK
+	}
+	return flags
+}
+
+// summarize converts the topic, adding the authors of its first and last posts.
+// Names holds the names of the empires that have already been fetched.
+func summarize(tx *orm.DB, topic *orm.ClanTopic_t, posts []*orm.ClanMessage_t, names map[int]string) (*Topic_t, error) {
+	t := &Topic_t{
+		Id:      topic.Id,
+		Subject: topic.Subject,
+		News:    topic.Flags&orm.CTFLAG_NEWS != 0,
+		Sticky:  topic.Flags&orm.CTFLAG_STICKY != 0,
+		Locked:  topic.Flags&orm.CTFLAG_LOCK != 0,
+	}
+	if t.News {
+		t.Subject = ""
+	}
+	if len(posts) == 0 {
+		return t, nil
+	}
+	name := func(empireId int) (string, error) {
+		if name, ok := names[empireId]; ok {
+			return name, nil
+		}
+		emp, err := tx.EmpireFetch(empireId)
+		if err != nil {
+			return "", err
+		}
+		names[empireId] = emp.Name
+		return emp.Name, nil
+	}
+	first, last := posts[0], posts[len(posts)-1]
+	var err error
+	t.Author, t.LastPoster = first.Empire, last.Empire
+	if t.AuthorName, err = name(first.Empire); err != nil {
+		return nil, err
+	} else if t.LastPosterName, err = name(last.Empire); err != nil {
+		return nil, err
+	}
+	t.Replies, t.LastPost = len(posts)-1, last.Time
+	return t, nil
+}
+
+// paginate returns the page, clamped to the pages needed to show n items,
+// the number of pages, and the range of items on the page.
+// Page zero or less is the first page.
+func paginate(n, perPage, page int) (int, int, int, int) {
+	pages := max(1, (n+perPage-1)/perPage)
+	page = min(max(page, 1), pages)
+	lo := (page - 1) * perPage
+	return page, pages, min(lo, n), min(lo+perPage, n)
+}
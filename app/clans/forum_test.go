@@ -0,0 +1,376 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package clans
+
+import (
+	"errors"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/orm"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestForumPost(t *testing.T) {
+	db, s := testService(t)
+	s = s.enabled()
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	started := model.RoundData_t{Started: true}
+	f := testForum(t, db, s, now)
+
+	if _, err := s.Forum(f.loner.Id, started, 0, 0, 0); !errors.Is(err, cerr.ErrNotClanMember) {
+		t.Errorf("loner: want %v, got %v", cerr.ErrNotClanMember, err)
+	}
+
+	for _, tc := range []struct {
+		id      string
+		subject string
+		body    string
+		want    string
+	}{
+		{"long subject", strings.Repeat("<", 64), "", "CLANFORUM_SUBJECT_TOO_LONG"},
+		{"lang subject", "COMMON_YES", "", "CLANFORUM_SUBJECT_INVALID"},
+		{"lang body", "", " COMMON_YES ", "CLANFORUM_BODY_INVALID"},
+		{"long body", "", strings.Repeat("&", 65535/5+1), "CLANFORUM_BODY_TOO_LONG"},
+	} {
+		res, err := s.NewTopic(f.deckhand.Id, started, tc.subject, tc.body, false, false, now)
+		testForumResult(t, tc.id, res, err, tc.want)
+	}
+
+	// members may not make topics sticky or lock them
+	res, err := s.NewTopic(f.deckhand.Id, started, "Ahoy <mates>", "[b]hi[/b] & bye", true, true, now)
+	forum := testForumResult(t, "post", res, err, "CLANFORUM_POST_COMPLETE")
+	if topic := forum.Topic; topic == nil || topic.Subject != "Ahoy &lt;mates&gt;" || topic.Sticky || topic.Locked || topic.AuthorName != "deckhand" {
+		t.Fatalf("post: got %+v", topic)
+	} else if len(forum.Posts) != 1 || forum.Posts[0].Body != `<span style="font-weight:bold"><!--b-->hi<!--/b--></span> &amp; bye` {
+		t.Errorf("post: got %+v", forum.Posts)
+	}
+	ahoy := forum.Topic.Id
+	res, err = s.NewTopic(f.captain.Id, started, "Rules", "obey", true, true, now)
+	forum = testForumResult(t, "rules", res, err, "CLANFORUM_POST_COMPLETE")
+	if topic := forum.Topic; !topic.Sticky || !topic.Locked {
+		t.Errorf("rules: got %+v", topic)
+	}
+	rules := forum.Topic.Id
+	res, err = s.Forum(f.ninja.Id, started, 0, 0, 0)
+	ninjas := testForumResult(t, "ninja", res, err, "")
+
+	for _, tc := range []struct {
+		id     string
+		empire int
+		topic  int
+		want   string
+	}{
+		{"no topic", f.deckhand.Id, 9999, "CLANFORUM_TOPIC_NOT_EXIST"},
+		{"wrong clan", f.deckhand.Id, ninjas.Topics[0].Id, "CLANFORUM_TOPIC_WRONG_CLAN"},
+		{"locked", f.deckhand.Id, rules, "CLANFORUM_REPLY_LOCKED"},
+		{"news", f.deckhand.Id, f.news, "CLANFORUM_REPLY_NEWS"},
+		{"minister news", f.minister.Id, f.news, "CLANFORUM_REPLY_COMPLETE"},
+		{"captain locked", f.captain.Id, rules, "CLANFORUM_REPLY_COMPLETE"},
+		{"reply", f.deckhand.Id, ahoy, "CLANFORUM_REPLY_COMPLETE"},
+	} {
+		res, err := s.Reply(tc.empire, started, tc.topic, "arr", now)
+		forum := testForumResult(t, tc.id, res, err, tc.want)
+		if tc.want == "CLANFORUM_REPLY_COMPLETE" && (forum.Topic == nil || forum.Topic.Id != tc.topic || forum.Topic.Replies != 1) {
+			t.Errorf("%s: got %+v", tc.id, forum.Topic)
+		} else if tc.want == "CLANFORUM_TOPIC_NOT_EXIST" && forum.Topic != nil {
+			t.Errorf("%s: want index, got %+v", tc.id, forum.Topic)
+		}
+	}
+
+	// members may not reply to the news topic, so they may not change its posts
+	res, err = s.Forum(f.deckhand.Id, started, f.news, 0, 0)
+	if err != nil {
+		t.Fatalf("news: %v", err)
+	} else if forum := res.Forum; forum.CanPost || forum.Posts[0].Label != "CLAN_LEADER_LABEL" || forum.Posts[1].Label != "CLANFORUM_FA_LABEL" || forum.Posts[1].CanEdit {
+		t.Errorf("news: got %+v %+v", forum, forum.Posts)
+	}
+}
+
+func TestForumEdit(t *testing.T) {
+	db, s := testService(t)
+	s = s.enabled()
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+	started := model.RoundData_t{Started: true}
+	f := testForum(t, db, s, now)
+
+	res, err := s.NewTopic(f.deckhand.Id, started, "Ahoy", "hello", false, false, now)
+	forum := testForumResult(t, "post", res, err, "CLANFORUM_POST_COMPLETE")
+	ahoy, first := forum.Topic.Id, forum.Posts[0].Id
+
+	// the last post may be edited by its author, and it moves to the time of the edit
+	res, err = s.Edit(f.deckhand.Id, started, ahoy, first, "Ahoy!", "[i]hello[/i]", true, false, later)
+	forum = testForumResult(t, "edit", res, err, "CLANFORUM_EDIT_COMPLETE")
+	if topic, post := forum.Topic, forum.Posts[0]; topic.Subject != "Ahoy!" || topic.Sticky || !post.Edited || !post.Time.Equal(later) || !post.CanEdit {
+		t.Errorf("edit: got %+v %+v", topic, post)
+	}
+	res, err = s.Reply(f.captain.Id, started, ahoy, "[quote]ahoy[/quote]welcome", later)
+	forum = testForumResult(t, "reply", res, err, "CLANFORUM_REPLY_COMPLETE")
+	reply := forum.Posts[1].Id
+	res, err = s.Forum(f.deckhand.Id, started, ahoy, 0, 0)
+	if forum := testForumResult(t, "view", res, err, ""); forum.Posts[0].CanEdit || forum.Posts[1].CanEdit {
+		t.Errorf("view: got %+v", forum.Posts)
+	}
+
+	for _, tc := range []struct {
+		id     string
+		empire int
+		topic  int
+		post   int
+		want   string
+	}{
+		{"no topic", f.deckhand.Id, 9999, first, "CLANFORUM_TOPIC_NOT_EXIST"},
+		{"no post", f.deckhand.Id, ahoy, 9999, "CLANFORUM_MESSAGE_NOT_EXIST"},
+		{"other topic", f.captain.Id, ahoy, f.motd, "CLANFORUM_MESSAGE_NOT_EXIST"},
+		{"too late", f.deckhand.Id, ahoy, first, "CLANFORUM_EDIT_TOO_LATE"},
+		{"permission", f.deckhand.Id, ahoy, reply, "CLANFORUM_EDIT_PERMISSION"},
+		{"news", f.deckhand.Id, f.news, f.motd, "CLANFORUM_EDIT_NEWS"},
+	} {
+		res, err := s.Edit(tc.empire, started, tc.topic, tc.post, "x", "x", false, false, later)
+		testForumResult(t, tc.id, res, err, tc.want)
+		res, err = s.Message(tc.empire, started, tc.topic, tc.post, false)
+		testForumResult(t, tc.id+" form", res, err, tc.want)
+	}
+
+	// the form shows the post being edited
+	res, err = s.Message(f.captain.Id, started, ahoy, first, false)
+	if forum := testForumResult(t, "form", res, err, ""); forum.Post == nil || forum.Post.Id != first || !forum.First {
+		t.Errorf("form: got %+v", forum.Post)
+	}
+	// rejected edits stay on the form
+	res, err = s.Edit(f.captain.Id, started, ahoy, first, "COMMON_YES", "hi", true, true, later)
+	if forum := testForumResult(t, "invalid", res, err, "CLANFORUM_SUBJECT_INVALID"); forum.Post == nil || forum.Post.Id != first {
+		t.Errorf("invalid: got %+v", forum.Post)
+	}
+
+	// moderators may edit any post, which keeps its time if it has been replied to
+	res, err = s.Edit(f.captain.Id, started, ahoy, first, "Ahoy", "hi", true, true, later.Add(time.Hour))
+	forum = testForumResult(t, "moderate", res, err, "CLANFORUM_EDIT_COMPLETE")
+	if topic, post := forum.Topic, forum.Posts[0]; topic.Subject != "Ahoy" || !topic.Sticky || !topic.Locked || post.Body != "hi" || !post.Time.Equal(later) {
+		t.Errorf("moderate: got %+v %+v", topic, post)
+	}
+	res, err = s.Edit(f.deckhand.Id, started, ahoy, first, "Ahoy", "hi", false, false, later)
+	testForumResult(t, "locked", res, err, "CLANFORUM_EDIT_LOCKED")
+}
+
+func TestForumDelete(t *testing.T) {
+	db, s := testService(t)
+	s = s.enabled()
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	started := model.RoundData_t{Started: true}
+	f := testForum(t, db, s, now)
+
+	// the only post in the news topic can't be deleted
+	res, err := s.Forum(f.captain.Id, started, f.news, 0, 0)
+	if err != nil {
+		t.Fatalf("news: %v", err)
+	} else if post := res.Forum.Posts[0]; !post.CanEdit || post.CanDelete {
+		t.Errorf("news: got %+v", post)
+	}
+	res, err = s.Message(f.captain.Id, started, f.news, f.motd, true)
+	testForumResult(t, "news form", res, err, "CLANFORUM_DELETE_CANNOT_NEWS")
+	res, err = s.Delete(f.captain.Id, started, f.news, f.motd)
+	testForumResult(t, "news", res, err, "CLANFORUM_DELETE_CANNOT_NEWS")
+	// once there is another post, the first one can be deleted without removing the topic
+	res, err = s.Reply(f.minister.Id, started, f.news, "news", now)
+	testForumResult(t, "news reply", res, err, "CLANFORUM_REPLY_COMPLETE")
+	res, err = s.Message(f.captain.Id, started, f.news, f.motd, true)
+	if forum := testForumResult(t, "news form", res, err, ""); forum.KillTopic {
+		t.Errorf("news form: want message, got topic")
+	}
+	res, err = s.Delete(f.captain.Id, started, f.news, f.motd)
+	if forum := testForumResult(t, "news", res, err, "CLANFORUM_DELETE_MESSAGE_COMPLETE"); forum.Topic == nil || len(forum.Posts) != 1 {
+		t.Errorf("news: got %+v", forum)
+	}
+
+	res, err = s.NewTopic(f.deckhand.Id, started, "Ahoy", "hello", false, false, now)
+	forum := testForumResult(t, "post", res, err, "CLANFORUM_POST_COMPLETE")
+	ahoy, first := forum.Topic.Id, forum.Posts[0].Id
+	res, err = s.Reply(f.minister.Id, started, ahoy, "welcome", now)
+	forum = testForumResult(t, "reply", res, err, "CLANFORUM_REPLY_COMPLETE")
+	reply := forum.Posts[1].Id
+
+	res, err = s.Delete(f.deckhand.Id, started, ahoy, first)
+	testForumResult(t, "too late", res, err, "CLANFORUM_DELETE_TOO_LATE")
+	res, err = s.Delete(f.deckhand.Id, started, ahoy, reply)
+	testForumResult(t, "permission", res, err, "CLANFORUM_DELETE_PERMISSION")
+	res, err = s.Delete(f.captain.Id, started, ahoy, reply)
+	if forum := testForumResult(t, "reply", res, err, "CLANFORUM_DELETE_MESSAGE_COMPLETE"); len(forum.Posts) != 1 || forum.Topic.Replies != 0 {
+		t.Errorf("reply: got %+v", forum.Posts)
+	}
+	if msg, err := db.ClanMessage(reply); err != nil || msg.Flags&orm.CMFLAG_DELETE == 0 {
+		t.Errorf("reply: got %+v %v", msg, err)
+	}
+
+	// deleting the first post removes the topic
+	res, err = s.Message(f.deckhand.Id, started, ahoy, first, true)
+	if forum := testForumResult(t, "form", res, err, ""); !forum.KillTopic {
+		t.Errorf("form: want topic, got message")
+	}
+	res, err = s.Delete(f.deckhand.Id, started, ahoy, first)
+	if forum := testForumResult(t, "topic", res, err, "CLANFORUM_DELETE_TOPIC_COMPLETE"); forum.Topic != nil || len(forum.Topics) != 1 {
+		t.Errorf("topic: got %+v", forum)
+	}
+	res, err = s.Forum(f.deckhand.Id, started, ahoy, 0, 0)
+	testForumResult(t, "deleted", res, err, "CLANFORUM_TOPIC_NOT_EXIST")
+}
+
+func TestForumPages(t *testing.T) {
+	db, s := testService(t)
+	s = s.enabled()
+	s.cfg.TopicsPerPage, s.cfg.PostsPerPage = 2, 2
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	started := model.RoundData_t{Started: true}
+	f := testForum(t, db, s, now)
+
+	post := func(empireId int, subject string, sticky bool, hours int) int {
+		res, err := s.NewTopic(empireId, started, subject, subject, sticky, false, now.Add(time.Duration(hours)*time.Hour))
+		return testForumResult(t, subject, res, err, "CLANFORUM_POST_COMPLETE").Topic.Id
+	}
+	reply := func(topicId int, body string, hours int) *Forum_t {
+		res, err := s.Reply(f.deckhand.Id, started, topicId, body, now.Add(time.Duration(hours)*time.Hour))
+		return testForumResult(t, body, res, err, "CLANFORUM_REPLY_COMPLETE")
+	}
+	first := post(f.deckhand.Id, "first", false, 1)
+	sticky := post(f.captain.Id, "sticky", true, 2)
+	second := post(f.deckhand.Id, "second", false, 3)
+	reply(first, "bump", 4)
+
+	// news first, then sticky topics, then the rest by their latest posts
+	for _, tc := range []struct {
+		page   int
+		want   []int
+		actual int
+	}{
+		{0, []int{f.news, sticky}, 1},
+		{2, []int{first, second}, 2},
+		{5, []int{first, second}, 2},
+	} {
+		res, err := s.Forum(f.deckhand.Id, started, 0, tc.page, 0)
+		forum := testForumResult(t, "index", res, err, "")
+		var got []int
+		for _, topic := range forum.Topics {
+			got = append(got, topic.Id)
+		}
+		if len(got) != 2 || got[0] != tc.want[0] || got[1] != tc.want[1] || forum.Page != tc.actual || forum.Pages != 2 {
+			t.Errorf("page %d: want %v, got %v (page %d of %d)", tc.page, tc.want, got, forum.Page, forum.Pages)
+		}
+	}
+
+	reply(first, "[quote]old[/quote]three", 5)
+	forum := reply(first, "four", 6)
+	if forum.Page != 2 || forum.Pages != 2 || len(forum.Posts) != 2 {
+		t.Errorf("reply: got page %d of %d, %d posts", forum.Page, forum.Pages, len(forum.Posts))
+	}
+	quoted := forum.Posts[0].Id
+	forum = reply(first, "five", 7)
+	if forum.Page != 3 || len(forum.Posts) != 1 {
+		t.Errorf("reply: got page %d of %d, %d posts", forum.Page, forum.Pages, len(forum.Posts))
+	}
+	res, err := s.Forum(f.deckhand.Id, started, first, 0, quoted)
+	forum = testForumResult(t, "quote", res, err, "")
+	if forum.Page != 2 || forum.Quote != "[quote]three[/quote]" {
+		t.Errorf("quote: got page %d, %q", forum.Page, forum.Quote)
+	}
+}
+
+func TestForumNews(t *testing.T) {
+	db, s := testService(t)
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	started := model.RoundData_t{Started: true}
+	f := testForum(t, db, s.enabled(), now)
+
+	if news, err := s.News(f.deckhand.Id); err != nil || news != nil {
+		t.Errorf("disabled: got %+v %v", news, err)
+	}
+	s = s.enabled()
+	if news, err := s.News(f.loner.Id); err != nil || news != nil {
+		t.Errorf("loner: got %+v %v", news, err)
+	}
+	if news, err := s.News(f.deckhand.Id); err != nil || news == nil || news.Id != f.motd || news.Body != "Welcome to clan PIRATES!" {
+		t.Errorf("motd: got %+v %v", news, err)
+	}
+	res, err := s.Reply(f.minister.Id, started, f.news, "arr", now.Add(time.Hour))
+	testForumResult(t, "news", res, err, "CLANFORUM_REPLY_COMPLETE")
+	// editing an old post doesn't move it
+	res, err = s.Edit(f.captain.Id, started, f.news, f.motd, "", "ahoy", false, false, now.Add(2*time.Hour))
+	testForumResult(t, "edit", res, err, "CLANFORUM_EDIT_COMPLETE")
+	if news, err := s.News(f.deckhand.Id); err != nil || news == nil || news.Body != "arr" || news.AuthorName != "minister" {
+		t.Errorf("news: got %+v %v", news, err)
+	}
+}
+
+// testForum_t is a clan with a forum, and empires to post in it.
+type testForum_t struct {
+	captain  *model.Empire_t // leader
+	minister *model.Empire_t
+	deckhand *model.Empire_t
+	ninja    *model.Empire_t // leader of another clan
+	loner    *model.Empire_t // not in a clan
+	news     int             // news topic
+	motd     int             // first post in the news topic
+}
+
+func testForum(t *testing.T, db *orm.DB, s *Clans_t, now time.Time) *testForum_t {
+	t.Helper()
+	started := model.RoundData_t{Started: true}
+	f := &testForum_t{
+		captain:  testEmpire(t, db, "captain", 1000),
+		minister: testEmpire(t, db, "minister", 500),
+		deckhand: testEmpire(t, db, "deckhand", 500),
+		ninja:    testEmpire(t, db, "ninja master", 500),
+		loner:    testEmpire(t, db, "lone wolf", 500),
+	}
+	res, err := s.Create(f.captain.Id, started, "PIRATES", "arr", "arr", now)
+	if err != nil || !res.Success {
+		t.Fatalf("create: %v %+v", err, res)
+	}
+	clan := res.State.Clan
+	for _, emp := range []*model.Empire_t{f.minister, f.deckhand} {
+		if res, err := s.Join(emp.Id, started, clan.Id, "arr", now); err != nil || !res.Success {
+			t.Fatalf("join: %v %+v", err, res)
+		}
+	}
+	clan, err = db.ClanFetch(clan.Id)
+	if err != nil {
+		t.Fatalf("clan: %v", err)
+	}
+	clan.Minister1 = f.minister.Id
+	if err := db.ClanUpdate(clan); err != nil {
+		t.Fatalf("clan: %v", err)
+	}
+	if res, err := s.Create(f.ninja.Id, started, "NINJAS", "hai", "hai", now); err != nil || !res.Success {
+		t.Fatalf("create: %v %+v", err, res)
+	}
+	res, err = s.Forum(f.captain.Id, started, 0, 0, 0)
+	if err != nil {
+		t.Fatalf("forum: %v", err)
+	} else if len(res.Forum.Topics) != 1 || !res.Forum.Topics[0].News {
+		t.Fatalf("forum: got %+v", res.Forum.Topics)
+	}
+	f.news = res.Forum.Topics[0].Id
+	res, err = s.Forum(f.captain.Id, started, f.news, 0, 0)
+	if err != nil {
+		t.Fatalf("forum: %v", err)
+	}
+	f.motd = res.Forum.Posts[0].Id
+	return f
+}
+
+// testForumResult checks that the forum action returned the message, or no
+// message if want is empty, and returns the forum page.
+func testForumResult(t *testing.T, id string, res *Result_t, err error, want string) *Forum_t {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("%s: %v", id, err)
+	} else if res == nil {
+		return nil
+	} else if want == "" && len(res.Messages) != 0 {
+		t.Errorf("%s: want no messages, got %+v", id, res.Messages)
+	} else if want != "" && (len(res.Messages) != 1 || res.Messages[0].Key != want) {
+		t.Errorf("%s: want %s, got %+v", id, want, res.Messages)
+	}
+	return res.Forum
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package clans
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/orm"
+	"time"
+)
+
+// Invite_t is an invitation for an empire to join a clan.
+type Invite_t struct {
+	Id            int
+	Clan          int
+	ClanName      string
+	ClanTitle     string
+	Sender        int
+	SenderName    string
+	Recipient     int
+	RecipientName string
+	Permanent     bool // permanent invitations survive joining the clan and never expire
+	Time          time.Time
+}
+
+// Invite invites an empire to join the empire's clan, from php/pages/manage/clan.php.
+// Any of the clan's officers may send temporary invitations, which expire after
+// CLAN_INVITE_TIME hours. Only the leader and the assistant may send permanent
+// ones, which whitelist the empire, or upgrade a temporary one to permanent.
+// Empires in other clans may only be invited permanently.
+func (s *Clans_t) Invite(empireId int, round model.RoundData_t, targetId int, permanent bool, now time.Time) (*Result_t, error) {
+	return s.update(empireId, round, now, func(tx *orm.DB, emp *model.Empire_t, fx *engine.Effects_t, res *Result_t) error {
+		clan, err := officerClan(tx, emp)
+		if err != nil {
+			return err
+		}
+		var target *model.Empire_t
+		if targetId != 0 {
+			if target, err = tx.EmpireFetch(targetId); err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+		}
+		if target == nil {
+			return res.reject("INPUT_EMPIRE_ID")
+		} else if target.Land == 0 {
+			return res.reject("MANAGE_CLAN_INVITE_DEAD")
+		} else if target.UserId == 0 {
+			return res.reject("MANAGE_CLAN_INVITE_DELETED")
+		} else if target.Flags.Admin {
+			return res.reject("MANAGE_CLAN_INVITE_ADMIN")
+		} else if target.Flags.Disable {
+			return res.reject("MANAGE_CLAN_INVITE_DISABLED")
+		} else if permanent && !leads(clan, emp) {
+			return res.reject("MANAGE_CLAN_INVITE_PERM_NEED_PERMISSION")
+		} else if !permanent && target.CId == clan.Id {
+			return res.reject("MANAGE_CLAN_INVITE_ALREADY_MEMBER")
+		} else if !permanent && target.CId != 0 {
+			return res.reject("MANAGE_CLAN_INVITE_WRONG_CLAN")
+		}
+
+		inv, err := tx.ClanInviteFind(clan.Id, target.Id)
+		if errors.Is(err, sql.ErrNoRows) {
+			inv = &orm.ClanInvite_t{Clan: clan.Id, Sender: emp.Id, Recipient: target.Id, Time: now}
+			if permanent {
+				inv.Flags = orm.CIFLAG_PERM
+			}
+			if err := tx.ClanInviteCreate(inv); err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		} else if inv.Flags&orm.CIFLAG_PERM != 0 {
+			return res.reject("MANAGE_CLAN_INVITE_ALREADY_PERM")
+		} else if !permanent {
+			return res.reject("MANAGE_CLAN_INVITE_ALREADY_INVITED")
+		} else {
+			// upgrade the invitation to a whitelist entry
+			inv.Sender, inv.Flags, inv.Time = emp.Id, orm.CIFLAG_PERM, now
+			if err := tx.ClanInviteUpdate(inv); err != nil {
+				return err
+			}
+		}
+
+		news, clanNews, key := engine.EMPNEWS_CLAN_INVITE_TEMP, engine.CLANNEWS_MEMBER_INVITE_TEMP, "MANAGE_CLAN_INVITE_COMPLETE_TEMP"
+		if permanent {
+			news, clanNews, key = engine.EMPNEWS_CLAN_INVITE_PERM, engine.CLANNEWS_MEMBER_INVITE_PERM, "MANAGE_CLAN_INVITE_COMPLETE_PERM"
+		}
+		if err := tx.EmpireNewsCreate(now, engine.NewNews(news, emp, target)); err != nil {
+			return err
+		} else if err := tx.ClanNewsCreate(now, engine.NewClanNews(clanNews, clan, emp, nil, target)); err != nil {
+			return err
+		}
+		return res.succeed(key, *target, clan.Name)
+	})
+}
+
+// Uninvite removes one of the clan's invitations.
+// Any of the clan's officers may remove temporary invitations,
+// but only the leader and the assistant may remove permanent ones.
+func (s *Clans_t) Uninvite(empireId int, round model.RoundData_t, inviteId int, now time.Time) (*Result_t, error) {
+	return s.update(empireId, round, now, func(tx *orm.DB, emp *model.Empire_t, fx *engine.Effects_t, res *Result_t) error {
+		clan, err := officerClan(tx, emp)
+		if err != nil {
+			return err
+		}
+		inv, err := tx.ClanInvite(inviteId)
+		if errors.Is(err, sql.ErrNoRows) {
+			return res.reject("MANAGE_CLAN_UNINVITE_NOT_EXIST")
+		} else if err != nil {
+			return err
+		} else if inv.Clan != clan.Id {
+			return res.reject("MANAGE_CLAN_UNINVITE_WRONG_CLAN")
+		}
+		permanent := inv.Flags&orm.CIFLAG_PERM != 0
+		if permanent && !leads(clan, emp) {
+			return res.reject("MANAGE_CLAN_UNINVITE_PERM_NEED_PERMISSION")
+		}
+		if err := tx.ClanInviteDelete(inv.Id); err != nil {
+			return err
+		}
+		recipient, err := tx.EmpireFetch(inv.Recipient)
+		if err != nil {
+			return err
+		}
+		news, clanNews := engine.EMPNEWS_CLAN_UNINVITE_TEMP, engine.CLANNEWS_MEMBER_UNINVITE_TEMP
+		if permanent {
+			news, clanNews = engine.EMPNEWS_CLAN_UNINVITE_PERM, engine.CLANNEWS_MEMBER_UNINVITE_PERM
+		}
+		if err := tx.EmpireNewsCreate(now, engine.NewNews(news, emp, recipient)); err != nil {
+			return err
+		} else if err := tx.ClanNewsCreate(now, engine.NewClanNews(clanNews, clan, emp, nil, recipient)); err != nil {
+			return err
+		}
+		return res.succeed("MANAGE_CLAN_UNINVITE_COMPLETE", *recipient)
+	})
+}
+
+// Accept adds the empire to the clan that sent it the invitation.
+// It follows the same rules as Join, but needs the invitation instead of the
+// clan's password. Joining removes the empire's temporary invitations.
+func (s *Clans_t) Accept(empireId int, round model.RoundData_t, clanId, inviteId int, now time.Time) (*Result_t, error) {
+	return s.update(empireId, round, now, func(tx *orm.DB, emp *model.Empire_t, fx *engine.Effects_t, res *Result_t) error {
+		return s.join(tx, emp, fx, clanId, res, now, func(clan *model.Clan_t) (bool, error) {
+			inv, err := tx.ClanInvite(inviteId)
+			if errors.Is(err, sql.ErrNoRows) {
+				return false, res.reject("CLAN_INVITE_NEED_INVITE")
+			} else if err != nil {
+				return false, err
+			} else if inv.Recipient != emp.Id {
+				return false, res.reject("CLAN_INVITE_WRONG_EMPIRE")
+			} else if inv.Clan != clan.Id {
+				return false, res.reject("CLAN_INVITE_WRONG_CLAN")
+			}
+			return true, nil
+		})
+	})
+}
+
+// ExpireInvites removes the temporary invitations that are older than
+// CLAN_INVITE_TIME hours, from prom_turns::doUpdate.
+// It returns the number of invitations removed.
+// It must be called inside a transaction.
+func ExpireInvites(tx *orm.DB, hours int, now time.Time) (int, error) {
+	return tx.ClanInvitesDeleteExpired(now.Add(-time.Duration(hours) * time.Hour))
+}
+
+// leads returns true if the empire is the clan's leader or assistant.
+func leads(clan *model.Clan_t, emp *model.Empire_t) bool {
+	return emp.Id == clan.Leader || emp.Id == clan.Assistant
+}
+
+// invites converts the invitations, adding the names of the clan and empires.
+func invites(tx *orm.DB, list []*orm.ClanInvite_t) ([]*Invite_t, error) {
+	var result []*Invite_t
+	for _, inv := range list {
+		clan, err := tx.ClanFetch(inv.Clan)
+		if err != nil {
+			return nil, err
+		}
+		sender, err := tx.EmpireFetch(inv.Sender)
+		if err != nil {
+			return nil, err
+		}
+		recipient, err := tx.EmpireFetch(inv.Recipient)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, &Invite_t{
+			Id:            inv.Id,
+			Clan:          clan.Id,
+			ClanName:      clan.Name,
+			ClanTitle:     clan.Title,
+			Sender:        sender.Id,
+			SenderName:    sender.Name,
+			Recipient:     recipient.Id,
+			RecipientName: recipient.Name,
+			Permanent:     inv.Flags&orm.CIFLAG_PERM != 0,
+			Time:          inv.Time,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,273 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package clans
+
+import (
+	"errors"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestInvite(t *testing.T) {
+	db, s := testService(t)
+	s = s.enabled()
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	started := model.RoundData_t{Started: true}
+
+	leader := testEmpire(t, db, "captain", 1000)
+	res, err := s.Create(leader.Id, started, "PIRATES", "arr", "arr", now)
+	if err != nil || !res.Success {
+		t.Fatalf("create: %v %+v", err, res)
+	}
+	pirates := res.State.Clan.Id
+	minister := testEmpire(t, db, "minister", 500)
+	deckhand := testEmpire(t, db, "deckhand", 500)
+	for _, emp := range []*model.Empire_t{minister, deckhand} {
+		if res, err := s.Join(emp.Id, started, pirates, "arr", now); err != nil || !res.Success {
+			t.Fatalf("join: %v %+v", err, res)
+		}
+	}
+	clan, err := db.ClanFetch(pirates)
+	if err != nil {
+		t.Fatalf("clan: %v", err)
+	}
+	clan.Minister1 = minister.Id
+	if err := db.ClanUpdate(clan); err != nil {
+		t.Fatalf("clan: %v", err)
+	}
+	other := testEmpire(t, db, "ninja master", 1000)
+	if res, err := s.Create(other.Id, started, "NINJAS", "hai", "hai", now); err != nil || !res.Success {
+		t.Fatalf("create: %v %+v", err, res)
+	}
+
+	sailor := testEmpire(t, db, "sailor", 100)
+	dead := testEmpire(t, db, "sunken", 100)
+	dead.Land = 0
+	if err := db.EmpireAttributesUpdate(dead); err != nil {
+		t.Fatalf("dead: %v", err)
+	}
+	locked := testEmpire(t, db, "locked up", 100)
+	locked.Flags.Disable = true
+	if err := db.EmpireUpdateFlags(locked); err != nil {
+		t.Fatalf("locked: %v", err)
+	}
+
+	for _, tc := range []struct {
+		id        string
+		empire    int
+		target    int
+		permanent bool
+		want      string
+	}{
+		{"no empire", minister.Id, 9999, false, "INPUT_EMPIRE_ID"},
+		{"dead", minister.Id, dead.Id, false, "MANAGE_CLAN_INVITE_DEAD"},
+		{"disabled", minister.Id, locked.Id, false, "MANAGE_CLAN_INVITE_DISABLED"},
+		{"member", minister.Id, deckhand.Id, false, "MANAGE_CLAN_INVITE_ALREADY_MEMBER"},
+		{"other clan", minister.Id, other.Id, false, "MANAGE_CLAN_INVITE_WRONG_CLAN"},
+		{"minister permanent", minister.Id, sailor.Id, true, "MANAGE_CLAN_INVITE_PERM_NEED_PERMISSION"},
+		{"temporary", minister.Id, sailor.Id, false, "MANAGE_CLAN_INVITE_COMPLETE_TEMP"},
+		{"invited again", minister.Id, sailor.Id, false, "MANAGE_CLAN_INVITE_ALREADY_INVITED"},
+		{"upgrade", leader.Id, sailor.Id, true, "MANAGE_CLAN_INVITE_COMPLETE_PERM"},
+		{"already permanent", leader.Id, sailor.Id, false, "MANAGE_CLAN_INVITE_ALREADY_PERM"},
+		// empires in other clans may be whitelisted
+		{"other clan permanent", leader.Id, other.Id, true, "MANAGE_CLAN_INVITE_COMPLETE_PERM"},
+	} {
+		res, err := s.Invite(tc.empire, started, tc.target, tc.permanent, now)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.id, err)
+		} else if len(res.Messages) != 1 || res.Messages[0].Key != tc.want {
+			t.Errorf("%s: want %s, got %+v", tc.id, tc.want, res.Messages)
+		}
+	}
+	if _, err := s.Invite(deckhand.Id, started, sailor.Id, false, now); !errors.Is(err, cerr.ErrNotClanOfficer) {
+		t.Errorf("deckhand: want %v, got %v", cerr.ErrNotClanOfficer, err)
+	}
+
+	// officers see the clan's invitations, and the upgrade kept the invitation
+	state, err := s.State(minister.Id, started, now)
+	if err != nil {
+		t.Fatalf("state: %v", err)
+	} else if len(state.Invites) != 2 {
+		t.Fatalf("state: want 2 invites, got %+v", state.Invites)
+	}
+	inv := state.Invites[0]
+	if inv.Recipient != sailor.Id || inv.Sender != leader.Id || !inv.Permanent || inv.RecipientName != "sailor" {
+		t.Errorf("state: got %+v", inv)
+	}
+	if state, err := s.State(deckhand.Id, started, now); err != nil {
+		t.Fatalf("state: %v", err)
+	} else if len(state.Invites) != 0 {
+		t.Errorf("deckhand: want no invites, got %+v", state.Invites)
+	}
+
+	// only the leader and assistant may remove permanent invitations
+	for _, tc := range []struct {
+		id     string
+		empire int
+		invite int
+		want   string
+	}{
+		{"no invite", minister.Id, 9999, "MANAGE_CLAN_UNINVITE_NOT_EXIST"},
+		{"wrong clan", other.Id, inv.Id, "MANAGE_CLAN_UNINVITE_WRONG_CLAN"},
+		{"minister permanent", minister.Id, inv.Id, "MANAGE_CLAN_UNINVITE_PERM_NEED_PERMISSION"},
+		{"uninvite", leader.Id, inv.Id, "MANAGE_CLAN_UNINVITE_COMPLETE"},
+		{"uninvited", leader.Id, inv.Id, "MANAGE_CLAN_UNINVITE_NOT_EXIST"},
+	} {
+		res, err := s.Uninvite(tc.empire, started, tc.invite, now)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.id, err)
+		} else if len(res.Messages) != 1 || res.Messages[0].Key != tc.want {
+			t.Errorf("%s: want %s, got %+v", tc.id, tc.want, res.Messages)
+		}
+	}
+
+	news, err := db.ClanNews(pirates, now.Add(-time.Second))
+	if err != nil {
+		t.Fatalf("news: %v", err)
+	}
+	var events []int
+	for _, n := range news {
+		if n.Empire2 == sailor.Id {
+			events = append(events, n.Event)
+		}
+	}
+	want := []int{engine.CLANNEWS_MEMBER_INVITE_TEMP, engine.CLANNEWS_MEMBER_INVITE_PERM, engine.CLANNEWS_MEMBER_UNINVITE_PERM}
+	if !slices.Equal(events, want) {
+		t.Errorf("news: want %v, got %v", want, events)
+	}
+}
+
+func TestAccept(t *testing.T) {
+	db, s := testService(t)
+	s = s.enabled()
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	started := model.RoundData_t{Started: true}
+
+	clan := func(name string) (*model.Empire_t, int) {
+		leader := testEmpire(t, db, "leader "+name, 1000)
+		res, err := s.Create(leader.Id, started, name, "arr", "arr", now)
+		if err != nil || !res.Success {
+			t.Fatalf("create: %v %+v", err, res)
+		}
+		return leader, res.State.Clan.Id
+	}
+	pirate, pirates := clan("PIRATES")
+	ninja, ninjas := clan("NINJAS")
+	sailor := testEmpire(t, db, "sailor", 100)
+	stowaway := testEmpire(t, db, "stowaway", 100)
+
+	invite := func(leaderId, empireId int, permanent bool) {
+		if res, err := s.Invite(leaderId, started, empireId, permanent, now); err != nil || !res.Success {
+			t.Fatalf("invite: %v %+v", err, res)
+		}
+	}
+	invite(pirate.Id, sailor.Id, true)
+	invite(ninja.Id, sailor.Id, false)
+	invite(pirate.Id, stowaway.Id, false)
+
+	// the invitations are shown to the empires that received them
+	state, err := s.State(sailor.Id, started, now)
+	if err != nil {
+		t.Fatalf("state: %v", err)
+	} else if len(state.Invites) != 2 || state.Invites[0].ClanName != "PIRATES" || state.Invites[1].ClanName != "NINJAS" {
+		t.Fatalf("state: got %+v", state.Invites)
+	}
+	fromPirates, fromNinjas := state.Invites[0].Id, state.Invites[1].Id
+	state, err = s.State(stowaway.Id, started, now)
+	if err != nil {
+		t.Fatalf("state: %v", err)
+	}
+	stowed := state.Invites[0].Id
+
+	for _, tc := range []struct {
+		id     string
+		empire int
+		clan   int
+		invite int
+		want   string
+	}{
+		{"no invite", sailor.Id, pirates, 9999, "CLAN_INVITE_NEED_INVITE"},
+		{"wrong empire", sailor.Id, pirates, stowed, "CLAN_INVITE_WRONG_EMPIRE"},
+		{"wrong clan", sailor.Id, pirates, fromNinjas, "CLAN_INVITE_WRONG_CLAN"},
+		{"accept", sailor.Id, pirates, fromPirates, "CLAN_JOIN_COMPLETE"},
+		{"already member", sailor.Id, ninjas, fromNinjas, "CLAN_ALREADY_MEMBER"},
+	} {
+		res, err := s.Accept(tc.empire, started, tc.clan, tc.invite, now)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.id, err)
+		} else if len(res.Messages) != 1 || res.Messages[0].Key != tc.want {
+			t.Errorf("%s: want %s, got %+v", tc.id, tc.want, res.Messages)
+		}
+	}
+
+	// joining removes the temporary invitations but keeps the permanent ones
+	if list, err := db.ClanInvitesReceived(sailor.Id); err != nil {
+		t.Fatalf("invites: %v", err)
+	} else if len(list) != 1 || list[0].Id != fromPirates {
+		t.Errorf("invites: got %+v", list)
+	}
+
+	// disbanding the clan tells the invited empires and removes the invitations
+	later := now.Add(73 * time.Hour)
+	if res, err := s.Leave(sailor.Id, started, true, later); err != nil || !res.Success {
+		t.Fatalf("leave: %v %+v", err, res)
+	} else if res, err := s.Leave(pirate.Id, started, true, later); err != nil || !res.Success {
+		t.Fatalf("disband: %v %+v", err, res)
+	}
+	for _, id := range []int{sailor.Id, stowaway.Id} {
+		if list, err := db.ClanInvitesReceived(id); err != nil {
+			t.Fatalf("invites: %v", err)
+		} else if len(list) != 0 {
+			t.Errorf("disband: got %+v", list)
+		}
+	}
+}
+
+func TestExpireInvites(t *testing.T) {
+	db, s := testService(t)
+	s = s.enabled()
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	started := model.RoundData_t{Started: true}
+
+	leader := testEmpire(t, db, "captain", 1000)
+	res, err := s.Create(leader.Id, started, "PIRATES", "arr", "arr", now)
+	if err != nil || !res.Success {
+		t.Fatalf("create: %v %+v", err, res)
+	}
+	pirates := res.State.Clan.Id
+	for _, tc := range []struct {
+		name      string
+		permanent bool
+		sent      time.Time
+	}{
+		{"friendly", true, now},
+		{"expired", false, now},
+		{"invited", false, now.Add(time.Hour)},
+	} {
+		emp := testEmpire(t, db, tc.name, 100)
+		if res, err := s.Invite(leader.Id, started, emp.Id, tc.permanent, tc.sent); err != nil || !res.Success {
+			t.Fatalf("invite: %v %+v", err, res)
+		}
+	}
+
+	if n, err := ExpireInvites(db, 48, now.Add(48*time.Hour)); err != nil {
+		t.Fatalf("expire: %v", err)
+	} else if n != 1 {
+		t.Errorf("expire: want 1, got %d", n)
+	}
+	list, err := db.ClanInvites(pirates)
+	if err != nil {
+		t.Fatalf("invites: %v", err)
+	}
+	var kept []bool
+	for _, inv := range list {
+		kept = append(kept, inv.Time.Equal(now))
+	}
+	if len(list) != 2 || !kept[0] || kept[1] {
+		t.Errorf("invites: got %+v", list)
+	}
+}
@@ -0,0 +1,27 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package clans
+
+import (
+	"golang.org/x/crypto/bcrypt"
+)
+
+// HashPassword returns the bcrypt hash of a clan password, from enc_password.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// CheckPassword returns true if the password matches the hash, from prom_clan::checkPassword.
+// Rehash is true when the hash matched but was made with a lower cost than
+// HashPassword uses, and should be replaced.
+func CheckPassword(password, hash string) (ok, rehash bool) {
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil {
+		return false, false
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	return true, err == nil && cost < bcrypt.DefaultCost
+}
@@ -0,0 +1,403 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package clans
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/orm"
+	"slices"
+	"time"
+)
+
+// Diplomacy_t is an action a clan's officers take on its relations with
+// another clan, from php/pages/manage/clan.php.
+//
+// Alliances need both clans to agree: one clan requests an alliance, which
+// the other accepts or declines. Either clan may end it. Wars are declared by
+// one clan; the declarer may offer to end the war, and the other clan may then
+// accept the treaty, or reject it and take over the war.
+type Diplomacy_t string
+
+const (
+	ALLY_ACCEPT  Diplomacy_t = "ally_accept"  // accept another clan's request for an alliance
+	ALLY_CANCEL  Diplomacy_t = "ally_cancel"  // retract the clan's request for an alliance
+	ALLY_DENY    Diplomacy_t = "ally_deny"    // decline another clan's request for an alliance
+	ALLY_REQUEST Diplomacy_t = "ally_request" // ask another clan for an alliance
+	ALLY_STOP    Diplomacy_t = "ally_stop"    // end an alliance
+	WAR_DECLARE  Diplomacy_t = "war_declare"  // declare war on another clan
+	WAR_REQUEST  Diplomacy_t = "war_request"  // offer to end a war the clan declared
+	WAR_RESTART  Diplomacy_t = "war_restart"  // reject another clan's offer to end a war, declaring it again
+	WAR_RESUME   Diplomacy_t = "war_resume"   // retract the clan's offer to end a war
+	WAR_STOP     Diplomacy_t = "war_stop"     // accept another clan's offer to end a war
+)
+
+// Status_t is the state of a relation as seen by one of its clans.
+type Status_t string
+
+const (
+	ALLY_MUTUAL         Status_t = "ally_mutual"         // standing alliance
+	ALLY_OUTBOUND       Status_t = "ally_outbound"       // the clan has requested an alliance
+	ALLY_INBOUND        Status_t = "ally_inbound"        // the other clan has requested an alliance
+	WAR_MUTUAL_OUTBOUND Status_t = "war_mutual_outbound" // war declared by the clan
+	WAR_MUTUAL_INBOUND  Status_t = "war_mutual_inbound"  // war declared by the other clan
+	WAR_OUTBOUND        Status_t = "war_outbound"        // the clan has offered to end the war it declared
+	WAR_INBOUND         Status_t = "war_inbound"         // the other clan has offered to end the war it declared
+)
+
+// statuses lists the relation statuses in the order they are shown.
+var statuses = []Status_t{ALLY_MUTUAL, ALLY_OUTBOUND, ALLY_INBOUND, WAR_MUTUAL_OUTBOUND, WAR_MUTUAL_INBOUND, WAR_OUTBOUND, WAR_INBOUND}
+
+// Ally returns true if the status is one of the alliance statuses.
+func (s Status_t) Ally() bool {
+	return s == ALLY_MUTUAL || s == ALLY_OUTBOUND || s == ALLY_INBOUND
+}
+
+// Relation_t is one of the clan's relations with another clan.
+type Relation_t struct {
+	Id     int
+	Clan   int    // the other clan
+	Name   string // the other clan's name
+	Status Status_t
+	Since  time.Time // when the relation last changed
+}
+
+// diplomacy describes each action: the relation it applies to, the messages
+// for rejecting and completing it, and the news sent to the other clan's
+// leader, to the clan, and to the other clan.
+var diplomacy = map[Diplomacy_t]struct {
+	status   Status_t // empty if the clans must not have a relation yet
+	confirm  string   // set if the action must be confirmed
+	tooSoon  string   // set if the relation must be older than CLAN_MINRELATE hours
+	complete string
+	news     [3]int
+}{
+	ALLY_REQUEST: {"", "", "", "MANAGE_CLAN_ALLY_REQUEST_COMPLETE",
+		[3]int{engine.EMPNEWS_CLAN_ALLY_REQUEST, engine.CLANNEWS_SEND_ALLY_REQUEST, engine.CLANNEWS_RECV_ALLY_REQUEST}},
+	ALLY_CANCEL: {ALLY_OUTBOUND, "", "MANAGE_CLAN_ALLY_CANCEL_TOO_SOON", "MANAGE_CLAN_ALLY_CANCEL_COMPLETE",
+		[3]int{engine.EMPNEWS_CLAN_ALLY_RETRACT, engine.CLANNEWS_SEND_ALLY_RETRACT, engine.CLANNEWS_RECV_ALLY_RETRACT}},
+	ALLY_ACCEPT: {ALLY_INBOUND, "", "", "MANAGE_CLAN_ALLY_ACCEPT_COMPLETE",
+		[3]int{engine.EMPNEWS_CLAN_ALLY_START, engine.CLANNEWS_SEND_ALLY_START, engine.CLANNEWS_RECV_ALLY_START}},
+	ALLY_DENY: {ALLY_INBOUND, "", "", "MANAGE_CLAN_ALLY_DENY_COMPLETE",
+		[3]int{engine.EMPNEWS_CLAN_ALLY_DECLINE, engine.CLANNEWS_SEND_ALLY_DECLINE, engine.CLANNEWS_RECV_ALLY_DECLINE}},
+	ALLY_STOP: {ALLY_MUTUAL, "MANAGE_CLAN_ALLY_STOP_NEED_CONFIRM", "MANAGE_CLAN_ALLY_STOP_TOO_SOON", "MANAGE_CLAN_ALLY_STOP_COMPLETE",
+		[3]int{engine.EMPNEWS_CLAN_ALLY_STOP, engine.CLANNEWS_SEND_ALLY_STOP, engine.CLANNEWS_RECV_ALLY_STOP}},
+	WAR_DECLARE: {"", "", "", "MANAGE_CLAN_WAR_DECLARE_COMPLETE",
+		[3]int{engine.EMPNEWS_CLAN_WAR_START, engine.CLANNEWS_SEND_WAR_START, engine.CLANNEWS_RECV_WAR_START}},
+	WAR_REQUEST: {WAR_MUTUAL_OUTBOUND, "MANAGE_CLAN_WAR_REQUEST_NEED_CONFIRM", "MANAGE_CLAN_WAR_REQUEST_TOO_SOON", "MANAGE_CLAN_WAR_REQUEST_COMPLETE",
+		[3]int{engine.EMPNEWS_CLAN_WAR_REQUEST, engine.CLANNEWS_SEND_WAR_REQUEST, engine.CLANNEWS_RECV_WAR_REQUEST}},
+	WAR_RESUME: {WAR_OUTBOUND, "", "MANAGE_CLAN_WAR_RESUME_TOO_SOON", "MANAGE_CLAN_WAR_RESUME_COMPLETE",
+		[3]int{engine.EMPNEWS_CLAN_WAR_RETRACT, engine.CLANNEWS_SEND_WAR_RETRACT, engine.CLANNEWS_RECV_WAR_RETRACT}},
+	WAR_RESTART: {WAR_INBOUND, "MANAGE_CLAN_WAR_REJECT_NEED_CONFIRM", "", "MANAGE_CLAN_WAR_REJECT_COMPLETE",
+		[3]int{engine.EMPNEWS_CLAN_WAR_REJECT, engine.CLANNEWS_SEND_WAR_REJECT, engine.CLANNEWS_RECV_WAR_REJECT}},
+	WAR_STOP: {WAR_INBOUND, "", "", "MANAGE_CLAN_WAR_STOP_COMPLETE",
+		[3]int{engine.EMPNEWS_CLAN_WAR_STOP, engine.CLANNEWS_SEND_WAR_STOP, engine.CLANNEWS_RECV_WAR_STOP}},
+}
+
+// Relate changes the relation between the empire's clan and another clan.
+// Any of the clan's officers may change its relations. Requests and
+// declarations need only the other clan; every other action also needs the
+// id of the relation it applies to. The other clan's leader is told of every change.
+func (s *Clans_t) Relate(empireId int, round model.RoundData_t, action Diplomacy_t, clanId, relationId int, confirm bool, now time.Time) (*Result_t, error) {
+	return s.update(empireId, round, now, func(tx *orm.DB, emp *model.Empire_t, fx *engine.Effects_t, res *Result_t) error {
+		spec, ok := diplomacy[action]
+		if !ok {
+			return cerr.ErrUnknownAction
+		}
+		clan, err := officerClan(tx, emp)
+		if err != nil {
+			return err
+		} else if spec.confirm != "" && !confirm {
+			return res.reject(spec.confirm)
+		}
+		var other *model.Clan_t
+		if clanId != 0 && clanId != clan.Id {
+			if other, err = tx.ClanFetch(clanId); err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+		}
+		if other == nil {
+			return res.reject("MANAGE_CLAN_NO_SUCH_CLAN")
+		}
+
+		var rel *orm.ClanRelation_t
+		if spec.status == "" {
+			if other.Members < 1 {
+				return res.reject("MANAGE_CLAN_CLAN_IS_GONE")
+			}
+			rels, err := tx.ClanRelations(clan.Id, 0, 0, orm.RELATION_BOTH)
+			if err != nil {
+				return err
+			}
+			// make sure we don't already have a relation with this clan
+			prefix := "MANAGE_CLAN_ALLY_REQUEST_ALREADY_"
+			if action == WAR_DECLARE {
+				prefix = "MANAGE_CLAN_WAR_DECLARE_ALREADY_"
+			}
+			for _, r := range rels {
+				if r.Other(clan.Id) == other.Id {
+					return res.reject(prefix+already[relationStatus(r, clan.Id)], other.Name)
+				}
+			}
+		} else {
+			if rel, err = tx.ClanRelation(relationId); errors.Is(err, sql.ErrNoRows) {
+				return res.reject("MANAGE_CLAN_NO_SUCH_RELATION")
+			} else if err != nil {
+				return err
+			} else if (rel.Clan1 != clan.Id && rel.Clan2 != clan.Id) || rel.Other(clan.Id) != other.Id || relationStatus(rel, clan.Id) != spec.status {
+				return res.reject("MANAGE_CLAN_INVALID_RELATION")
+			} else if spec.tooSoon != "" && !rel.Time.Before(now.Add(-time.Duration(s.cfg.MinRelate)*time.Hour)) {
+				return res.reject(spec.tooSoon)
+			}
+		}
+
+		switch action {
+		case ALLY_REQUEST:
+			// count all outgoing (mutual or not), as well as incoming mutual
+			outgoing, err := tx.ClanRelations(clan.Id, orm.CRFLAG_ALLY, orm.CRFLAG_ALLY, orm.RELATION_OUTBOUND)
+			if err != nil {
+				return err
+			}
+			incoming, err := tx.ClanRelations(clan.Id, orm.CRFLAG_ALLY|orm.CRFLAG_MUTUAL, orm.CRFLAG_ALLY|orm.CRFLAG_MUTUAL, orm.RELATION_INBOUND)
+			if err != nil {
+				return err
+			} else if len(outgoing)+len(incoming) >= s.cfg.MaxAlly {
+				return res.reject("MANAGE_CLAN_ALLY_REQUEST_TOO_MANY")
+			}
+			err = tx.ClanRelationCreate(&orm.ClanRelation_t{Clan1: clan.Id, Clan2: other.Id, Flags: orm.CRFLAG_ALLY, Time: now})
+			if err != nil {
+				return err
+			}
+		case ALLY_ACCEPT:
+			for _, c := range []struct {
+				id      int
+				tooMany string
+			}{
+				{clan.Id, "MANAGE_CLAN_ALLY_ACCEPT_SELF_TOO_MANY"},
+				{other.Id, "MANAGE_CLAN_ALLY_ACCEPT_OTHER_TOO_MANY"},
+			} {
+				allies, err := tx.ClanAllies(c.id)
+				if err != nil {
+					return err
+				} else if len(allies) >= s.cfg.MaxAlly {
+					return res.reject(c.tooMany)
+				}
+			}
+			rel.Flags, rel.Time = orm.CRFLAG_ALLY|orm.CRFLAG_MUTUAL, now
+			if err := tx.ClanRelationUpdate(rel); err != nil {
+				return err
+			}
+		case ALLY_CANCEL, ALLY_DENY, ALLY_STOP, WAR_STOP:
+			if err := tx.ClanRelationDelete(rel.Id); err != nil {
+				return err
+			}
+		case WAR_DECLARE:
+			if wars, err := s.warsDeclared(tx, clan.Id); err != nil {
+				return err
+			} else if wars >= s.cfg.MaxWar {
+				return res.reject("MANAGE_CLAN_WAR_DECLARE_TOO_MANY")
+			}
+			err = tx.ClanRelationCreate(&orm.ClanRelation_t{Clan1: clan.Id, Clan2: other.Id, Flags: orm.CRFLAG_WAR | orm.CRFLAG_MUTUAL, Time: now})
+			if err != nil {
+				return err
+			}
+		case WAR_REQUEST:
+			rel.Flags, rel.Time = orm.CRFLAG_WAR, now
+			if err := tx.ClanRelationUpdate(rel); err != nil {
+				return err
+			}
+		case WAR_RESUME:
+			rel.Flags, rel.Time = orm.CRFLAG_WAR|orm.CRFLAG_MUTUAL, now
+			if err := tx.ClanRelationUpdate(rel); err != nil {
+				return err
+			}
+		case WAR_RESTART:
+			if wars, err := s.warsDeclared(tx, clan.Id); err != nil {
+				return err
+			} else if wars >= s.cfg.MaxWar {
+				return res.reject("MANAGE_CLAN_WAR_REJECT_TOO_MANY")
+			}
+			// the clan takes over the war
+			rel.Clan1, rel.Clan2 = clan.Id, other.Id
+			rel.Flags, rel.Time = orm.CRFLAG_WAR|orm.CRFLAG_MUTUAL, now
+			if err := tx.ClanRelationUpdate(rel); err != nil {
+				return err
+			}
+		}
+
+		leader, err := tx.EmpireFetch(other.Leader)
+		if err != nil {
+			return err
+		} else if err := tx.EmpireNewsCreate(now, engine.NewNews(spec.news[0], emp, leader)); err != nil {
+			return err
+		} else if err := tx.ClanNewsCreate(now,
+			engine.NewClanNews(spec.news[1], clan, emp, other, nil),
+			engine.NewClanNews(spec.news[2], other, nil, clan, emp)); err != nil {
+			return err
+		}
+		return res.succeed(spec.complete, other.Name)
+	})
+}
+
+// already is the suffix of the message for trying to request an alliance
+// with or declare war on a clan the clan already has a relation with.
+var already = map[Status_t]string{
+	ALLY_MUTUAL:         "ALLY_ACCEPTED",
+	ALLY_OUTBOUND:       "ALLY_REQUESTED",
+	ALLY_INBOUND:        "ALLY_PENDING",
+	WAR_MUTUAL_OUTBOUND: "WAR_DECLARED_OUT",
+	WAR_MUTUAL_INBOUND:  "WAR_DECLARED_IN",
+	WAR_OUTBOUND:        "WAR_REQUESTED_OUT",
+	WAR_INBOUND:         "WAR_REQUESTED_IN",
+}
+
+// warsDeclared returns the number of wars the clan has declared, including
+// those it has offered to end.
+func (s *Clans_t) warsDeclared(tx *orm.DB, clanId int) (int, error) {
+	wars, err := tx.ClanRelations(clanId, orm.CRFLAG_WAR, orm.CRFLAG_WAR, orm.RELATION_OUTBOUND)
+	if err != nil {
+		return 0, err
+	}
+	return len(wars), nil
+}
+
+// relationStatus returns the status of the relation as seen by the clan.
+func relationStatus(rel *orm.ClanRelation_t, clanId int) Status_t {
+	outbound := rel.Clan1 == clanId
+	mutual := rel.Flags&orm.CRFLAG_MUTUAL != 0
+	if rel.Flags&orm.CRFLAG_ALLY != 0 {
+		if mutual {
+			return ALLY_MUTUAL
+		} else if outbound {
+			return ALLY_OUTBOUND
+		}
+		return ALLY_INBOUND
+	}
+	switch {
+	case mutual && outbound:
+		return WAR_MUTUAL_OUTBOUND
+	case mutual:
+		return WAR_MUTUAL_INBOUND
+	case outbound:
+		return WAR_OUTBOUND
+	}
+	return WAR_INBOUND
+}
+
+// relations returns the clan's relations with other clans, alliances first.
+func relations(tx *orm.DB, clanId int) ([]*Relation_t, error) {
+	rels, err := tx.ClanRelations(clanId, 0, 0, orm.RELATION_BOTH)
+	if err != nil {
+		return nil, err
+	}
+	var list []*Relation_t
+	for _, rel := range rels {
+		other, err := tx.ClanFetch(rel.Other(clanId))
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, &Relation_t{
+			Id:     rel.Id,
+			Clan:   other.Id,
+			Name:   other.Name,
+			Status: relationStatus(rel, clanId),
+			Since:  rel.Time,
+		})
+	}
+	slices.SortStableFunc(list, func(a, b *Relation_t) int {
+		return slices.Index(statuses, a.Status) - slices.Index(statuses, b.Status)
+	})
+	return list, nil
+}
+
+// officerClan returns the empire's clan, or an error if the empire
+// is not one of the clan's officers.
+func officerClan(tx *orm.DB, emp *model.Empire_t) (*model.Clan_t, error) {
+	if emp.CId == 0 {
+		return nil, cerr.ErrNotClanMember
+	}
+	clan, err := tx.ClanFetch(emp.CId)
+	if err != nil {
+		return nil, err
+	} else if !slices.Contains([]int{clan.Leader, clan.Assistant, clan.Minister1, clan.Minister2}, emp.Id) {
+		return nil, cerr.ErrNotClanOfficer
+	}
+	return clan, nil
+}
+
+// Attack applies the clan rules to an attack by one empire on another, from
+// php/pages/military.php. Empires may not attack members of their own clan or
+// of an allied clan, and attacks on a clan at war with the attacker's are
+// unrestricted. It also counts the wars the attacker's clan has declared, for
+// the passive war tax, and adds the defender's clanmates who share their
+// forces as allies. Callers should only use it when clans are enabled.
+// It must be called inside a transaction.
+func Attack(tx *orm.DB, att, def *model.Empire_t, req *engine.Attack_t) error {
+	if att.CId != 0 {
+		wars, err := tx.ClanWarsDeclared(att.CId)
+		if err != nil {
+			return err
+		}
+		req.Wars = wars
+	}
+	if att.CId != 0 && def.CId != 0 {
+		if def.CId == att.CId {
+			return cerr.ErrTargetClanmate
+		}
+		allies, err := tx.ClanAllies(att.CId)
+		if err != nil {
+			return err
+		} else if slices.Contains(allies, def.CId) {
+			return cerr.ErrTargetAlly
+		}
+		enemies, err := tx.ClanWars(att.CId)
+		if err != nil {
+			return err
+		}
+		req.War = slices.Contains(enemies, def.CId)
+	}
+	return reinforce(tx, def, req)
+}
+
+// Dissolve removes a clan whose last member has left from the game, from
+// prom_turns::cleanClans. The leaders of clans it had relations with are
+// told it is gone, and its relations are deleted. It must be called inside
+// a transaction.
+func Dissolve(tx *orm.DB, clan *model.Clan_t, now time.Time) error {
+	rels, err := tx.ClanRelations(clan.Id, 0, 0, orm.RELATION_BOTH)
+	if err != nil {
+		return err
+	}
+	// the news comes from the clan rather than from one of its members
+	gone := &model.Empire_t{CId: clan.Id}
+	for _, rel := range rels {
+		other, err := tx.ClanFetch(rel.Other(clan.Id))
+		if err != nil {
+			return err
+		} else if other.Leader == 0 {
+			// both clans were abandoned at the same time
+			continue
+		}
+		leader, err := tx.EmpireFetch(other.Leader)
+		if err != nil {
+			return err
+		}
+		event, clanEvent := engine.EMPNEWS_CLAN_WAR_GONE, engine.CLANNEWS_RECV_WAR_GONE
+		if rel.Flags&orm.CRFLAG_ALLY != 0 {
+			event, clanEvent = engine.EMPNEWS_CLAN_ALLY_GONE, engine.CLANNEWS_RECV_ALLY_GONE
+		}
+		if err := tx.EmpireNewsCreate(now, engine.NewNews(event, gone, leader)); err != nil {
+			return err
+		} else if err := tx.ClanNewsCreate(now, engine.NewClanNews(clanEvent, other, nil, clan, nil)); err != nil {
+			return err
+		}
+	}
+	if err := tx.ClanRelationsDelete(clan.Id); err != nil {
+		return err
+	}
+	clan.Members = -1
+	return tx.ClanUpdate(clan)
+}
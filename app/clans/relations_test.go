@@ -0,0 +1,232 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package clans
+
+import (
+	"errors"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/orm"
+	"testing"
+	"time"
+)
+
+func TestRelate(t *testing.T) {
+	db, s := testService(t)
+	s = s.enabled()
+	s.cfg.MaxAlly, s.cfg.MaxWar, s.cfg.MinRelate = 1, 1, 48
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	started := model.RoundData_t{Started: true}
+
+	var leaders []*model.Empire_t
+	var ids []int
+	for _, name := range []string{"PIRATES", "NINJAS", "ROBOTS", "ZOMBIES"} {
+		leader := testEmpire(t, db, "leader "+name, 1000)
+		res, err := s.Create(leader.Id, started, name, "arr", "arr", now)
+		if err != nil || !res.Success {
+			t.Fatalf("create: %v %+v", err, res)
+		}
+		leaders, ids = append(leaders, leader), append(ids, res.State.Clan.Id)
+	}
+	pirate, ninja, robot, zombie := leaders[0].Id, leaders[1].Id, leaders[2].Id, leaders[3].Id
+	pirates, ninjas, robots, zombies := ids[0], ids[1], ids[2], ids[3]
+
+	// relation returns the id and status of the empire's relation with the clan
+	relation := func(empireId, clanId int, when time.Time) (int, Status_t) {
+		state, err := s.State(empireId, started, when)
+		if err != nil {
+			t.Fatalf("state: %v", err)
+		}
+		for _, rel := range state.Relations {
+			if rel.Clan == clanId {
+				return rel.Id, rel.Status
+			}
+		}
+		return 0, ""
+	}
+
+	for _, tc := range []struct {
+		id      string
+		empire  int
+		action  Diplomacy_t
+		clan    int
+		confirm bool
+		after   time.Duration
+		want    string
+		status  Status_t // the empire's relation with the clan afterwards
+	}{
+		{"no clan", pirate, ALLY_REQUEST, 0, false, 0, "MANAGE_CLAN_NO_SUCH_CLAN", ""},
+		{"own clan", pirate, ALLY_REQUEST, pirates, false, 0, "MANAGE_CLAN_NO_SUCH_CLAN", ""},
+		{"request", pirate, ALLY_REQUEST, ninjas, false, 0, "MANAGE_CLAN_ALLY_REQUEST_COMPLETE", ALLY_OUTBOUND},
+		{"request again", pirate, ALLY_REQUEST, ninjas, false, 0, "MANAGE_CLAN_ALLY_REQUEST_ALREADY_ALLY_REQUESTED", ALLY_OUTBOUND},
+		{"request back", ninja, ALLY_REQUEST, pirates, false, 0, "MANAGE_CLAN_ALLY_REQUEST_ALREADY_ALLY_PENDING", ALLY_INBOUND},
+		{"too many requests", pirate, ALLY_REQUEST, robots, false, 0, "MANAGE_CLAN_ALLY_REQUEST_TOO_MANY", ""},
+		{"accept own request", pirate, ALLY_ACCEPT, ninjas, false, 0, "MANAGE_CLAN_INVALID_RELATION", ALLY_OUTBOUND},
+		{"cancel too soon", pirate, ALLY_CANCEL, ninjas, false, 0, "MANAGE_CLAN_ALLY_CANCEL_TOO_SOON", ALLY_OUTBOUND},
+		{"accept", ninja, ALLY_ACCEPT, pirates, false, 0, "MANAGE_CLAN_ALLY_ACCEPT_COMPLETE", ALLY_MUTUAL},
+		{"declare on ally", pirate, WAR_DECLARE, ninjas, false, 0, "MANAGE_CLAN_WAR_DECLARE_ALREADY_ALLY_ACCEPTED", ALLY_MUTUAL},
+		{"stop unconfirmed", ninja, ALLY_STOP, pirates, false, 0, "MANAGE_CLAN_ALLY_STOP_NEED_CONFIRM", ALLY_MUTUAL},
+		{"stop too soon", ninja, ALLY_STOP, pirates, true, 47 * time.Hour, "MANAGE_CLAN_ALLY_STOP_TOO_SOON", ALLY_MUTUAL},
+		{"stop", ninja, ALLY_STOP, pirates, true, 49 * time.Hour, "MANAGE_CLAN_ALLY_STOP_COMPLETE", ""},
+		{"declare", pirate, WAR_DECLARE, robots, false, 0, "MANAGE_CLAN_WAR_DECLARE_COMPLETE", WAR_MUTUAL_OUTBOUND},
+		{"declared on", robot, WAR_DECLARE, pirates, false, 0, "MANAGE_CLAN_WAR_DECLARE_ALREADY_WAR_DECLARED_IN", WAR_MUTUAL_INBOUND},
+		{"too many wars", pirate, WAR_DECLARE, zombies, false, 0, "MANAGE_CLAN_WAR_DECLARE_TOO_MANY", ""},
+		{"peace too soon", pirate, WAR_REQUEST, robots, true, 0, "MANAGE_CLAN_WAR_REQUEST_TOO_SOON", WAR_MUTUAL_OUTBOUND},
+		{"peace", pirate, WAR_REQUEST, robots, true, 49 * time.Hour, "MANAGE_CLAN_WAR_REQUEST_COMPLETE", WAR_OUTBOUND},
+		{"reject unconfirmed", robot, WAR_RESTART, pirates, false, 0, "MANAGE_CLAN_WAR_REJECT_NEED_CONFIRM", WAR_INBOUND},
+		{"reject", robot, WAR_RESTART, pirates, true, 0, "MANAGE_CLAN_WAR_REJECT_COMPLETE", WAR_MUTUAL_OUTBOUND},
+		{"peace offered", robot, WAR_REQUEST, pirates, true, 49 * time.Hour, "MANAGE_CLAN_WAR_REQUEST_COMPLETE", WAR_OUTBOUND},
+		{"accept peace", pirate, WAR_STOP, robots, false, 0, "MANAGE_CLAN_WAR_STOP_COMPLETE", ""},
+	} {
+		relId, _ := relation(tc.empire, tc.clan, now)
+		res, err := s.Relate(tc.empire, started, tc.action, tc.clan, relId, tc.confirm, now.Add(tc.after))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.id, err)
+		} else if len(res.Messages) != 1 || res.Messages[0].Key != tc.want {
+			t.Errorf("%s: want %s, got %+v", tc.id, tc.want, res.Messages)
+		} else if _, status := relation(tc.empire, tc.clan, now); status != tc.status {
+			t.Errorf("%s: status: want %q, got %q", tc.id, tc.status, status)
+		}
+		if tc.after != 0 {
+			now = now.Add(tc.after)
+		}
+	}
+
+	// members who aren't officers can't change relations
+	member := testEmpire(t, db, "deckhand", 100)
+	if res, err := s.Join(member.Id, started, pirates, "arr", now); err != nil || !res.Success {
+		t.Fatalf("join: %v %+v", err, res)
+	} else if _, err := s.Relate(member.Id, started, WAR_DECLARE, zombies, 0, false, now); !errors.Is(err, cerr.ErrNotClanOfficer) {
+		t.Errorf("member: want %v, got %v", cerr.ErrNotClanOfficer, err)
+	}
+	if res, err := s.Relate(zombie, started, ALLY_ACCEPT, pirates, 9999, false, now); err != nil {
+		t.Fatalf("no relation: %v", err)
+	} else if res.Messages[0].Key != "MANAGE_CLAN_NO_SUCH_RELATION" {
+		t.Errorf("no relation: got %+v", res.Messages)
+	}
+
+	news, err := db.ClanNews(robots, time.Time{})
+	if err != nil {
+		t.Fatalf("news: %v", err)
+	}
+	var events []int
+	for _, n := range news {
+		if n.Clan2 == pirates {
+			events = append(events, n.Event)
+		}
+	}
+	want := []int{engine.CLANNEWS_RECV_WAR_START, engine.CLANNEWS_RECV_WAR_REQUEST, engine.CLANNEWS_SEND_WAR_REJECT,
+		engine.CLANNEWS_SEND_WAR_REQUEST, engine.CLANNEWS_RECV_WAR_STOP}
+	if len(events) != len(want) {
+		t.Fatalf("news: want %v, got %v", want, events)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("news: want %v, got %v", want, events)
+			break
+		}
+	}
+}
+
+func TestAttack(t *testing.T) {
+	db, s := testService(t)
+	s = s.enabled()
+	s.cfg.MaxAlly, s.cfg.MaxWar = 3, 3
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	started := model.RoundData_t{Started: true}
+
+	clan := func(name string) (*model.Empire_t, int) {
+		leader := testEmpire(t, db, "leader "+name, 1000)
+		res, err := s.Create(leader.Id, started, name, "arr", "arr", now)
+		if err != nil || !res.Success {
+			t.Fatalf("create: %v %+v", err, res)
+		}
+		return leader, res.State.Clan.Id
+	}
+	pirate, pirates := clan("PIRATES")
+	ninja, ninjas := clan("NINJAS")
+	robot, robots := clan("ROBOTS")
+	loner := testEmpire(t, db, "lonely", 500)
+	deckhand := testEmpire(t, db, "deckhand", 500)
+	if res, err := s.Join(deckhand.Id, started, pirates, "arr", now); err != nil || !res.Success {
+		t.Fatalf("join: %v %+v", err, res)
+	}
+
+	relate := func(empireId int, action Diplomacy_t, clanId int) {
+		state, err := s.State(empireId, started, now)
+		if err != nil {
+			t.Fatalf("state: %v", err)
+		}
+		var relId int
+		for _, rel := range state.Relations {
+			if rel.Clan == clanId {
+				relId = rel.Id
+			}
+		}
+		if res, err := s.Relate(empireId, started, action, clanId, relId, true, now); err != nil || !res.Success {
+			t.Fatalf("%s: %v %+v", action, err, res)
+		}
+	}
+	relate(pirate.Id, ALLY_REQUEST, ninjas)
+	relate(ninja.Id, ALLY_ACCEPT, pirates)
+	relate(pirate.Id, WAR_DECLARE, robots)
+
+	fetch := func(id int) *model.Empire_t {
+		emp, err := db.EmpireFetch(id)
+		if err != nil {
+			t.Fatalf("fetch: %v", err)
+		}
+		return emp
+	}
+	for _, tc := range []struct {
+		id       string
+		att, def int
+		err      error
+		war      bool
+		wars     int
+	}{
+		{"no clan", loner.Id, pirate.Id, nil, false, 0},
+		{"unaligned", pirate.Id, loner.Id, nil, false, 1},
+		{"clanmate", deckhand.Id, pirate.Id, cerr.ErrTargetClanmate, false, 1},
+		{"ally", ninja.Id, pirate.Id, cerr.ErrTargetAlly, false, 0},
+		{"war", deckhand.Id, robot.Id, nil, true, 1},
+		// the clan that war was declared on is at war too, but pays no war tax
+		{"declared on", robot.Id, pirate.Id, nil, true, 0},
+		{"neutral", robot.Id, ninja.Id, nil, false, 0},
+	} {
+		var req engine.Attack_t
+		err := Attack(db, fetch(tc.att), fetch(tc.def), &req)
+		if !errors.Is(err, tc.err) {
+			t.Errorf("%s: want %v, got %v", tc.id, tc.err, err)
+		} else if err == nil && (req.War != tc.war || req.Wars != tc.wars) {
+			t.Errorf("%s: want war %v wars %d, got %v %d", tc.id, tc.war, tc.wars, req.War, req.Wars)
+		}
+	}
+
+	// a clan whose last member has left is removed, along with its relations
+	later := now.Add(73 * time.Hour)
+	if res, err := s.Leave(robot.Id, started, true, later); err != nil || !res.Success {
+		t.Fatalf("leave: %v %+v", err, res)
+	}
+	gone, err := db.ClanFetch(robots)
+	if err != nil {
+		t.Fatalf("clan: %v", err)
+	} else if err := Dissolve(db, gone, later); err != nil {
+		t.Fatalf("dissolve: %v", err)
+	}
+	if gone, _ = db.ClanFetch(robots); gone.Members != -1 {
+		t.Errorf("dissolve: members: want -1, got %d", gone.Members)
+	}
+	if rels, err := db.ClanRelations(pirates, 0, 0, orm.RELATION_BOTH); err != nil {
+		t.Fatalf("relations: %v", err)
+	} else if len(rels) != 1 || rels[0].Other(pirates) != ninjas {
+		t.Errorf("relations: got %+v", rels)
+	}
+	news, err := db.ClanNews(pirates, later.Add(-time.Second))
+	if err != nil {
+		t.Fatalf("news: %v", err)
+	} else if len(news) != 1 || news[0].Event != engine.CLANNEWS_RECV_WAR_GONE || news[0].Clan2 != robots {
+		t.Errorf("news: got %+v", news)
+	}
+}
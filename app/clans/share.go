@@ -0,0 +1,117 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package clans
+
+import (
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/orm"
+	"math"
+	"time"
+)
+
+// Share starts using the empire's forces to help defend its clanmates.
+// An empire that is unsharing may share again before the delay runs out.
+func (s *Clans_t) Share(empireId int, round model.RoundData_t, now time.Time) (*Result_t, error) {
+	return s.update(empireId, round, now, func(tx *orm.DB, emp *model.Empire_t, fx *engine.Effects_t, res *Result_t) error {
+		if emp.CId == 0 {
+			return res.reject("CLAN_NOT_MEMBER")
+		} else if emp.Sharing < 0 {
+			return res.reject("CLAN_SHARE_ALREADY")
+		}
+		clan, err := tx.ClanFetch(emp.CId)
+		if err != nil {
+			return err
+		}
+		emp.Sharing = -1
+		if err := tx.ClanNewsCreate(now, engine.NewClanNews(engine.CLANNEWS_MEMBER_SHARE, clan, emp, nil, nil)); err != nil {
+			return err
+		}
+		return res.succeed("CLAN_SHARE_COMPLETE")
+	})
+}
+
+// Unshare stops using the empire's forces to help defend its clanmates.
+// The forces stay shared for CLAN_MINSHARE hours; e_sharing holds the number
+// of turn updates left, and the empire stops sharing when it reaches zero.
+func (s *Clans_t) Unshare(empireId int, round model.RoundData_t, now time.Time) (*Result_t, error) {
+	return s.update(empireId, round, now, func(tx *orm.DB, emp *model.Empire_t, fx *engine.Effects_t, res *Result_t) error {
+		if emp.CId == 0 {
+			return res.reject("CLAN_NOT_MEMBER")
+		} else if emp.Sharing >= 0 {
+			// already unsharing, or never shared
+			return res.reject("CLAN_UNSHARE_NOT_SHARING")
+		}
+		clan, err := tx.ClanFetch(emp.CId)
+		if err != nil {
+			return err
+		}
+		// the next turn update ends the first interval of the delay
+		emp.Sharing = max(s.cfg.MinShare*(60/s.cfg.TurnsFreq)-1, 0)
+		if err := tx.ClanNewsCreate(now, engine.NewClanNews(engine.CLANNEWS_MEMBER_UNSHARE, clan, emp, nil, nil)); err != nil {
+			return err
+		}
+		return res.succeed("CLAN_UNSHARE_COMPLETE", s.cfg.MinShare)
+	})
+}
+
+// sharing adds the forces the empire and its clan share to the state.
+// A tenth of each sharing member's units are shared, as in php/pages/clan.php.
+func (s *Clans_t) sharing(state *State_t, emp *model.Empire_t) {
+	state.Sharing = emp.Sharing != 0
+	if emp.Sharing > 0 {
+		state.Unshare = emp.Sharing * s.cfg.TurnsFreq
+	}
+	if state.Sharing {
+		state.Share = engine.Units_t{
+			TrpArm: tenth(emp.TrpArm),
+			TrpLnd: tenth(emp.TrpLnd),
+			TrpFly: tenth(emp.TrpFly),
+			TrpSea: tenth(emp.TrpSea),
+		}
+	}
+	for _, member := range state.Members {
+		if member.Sharing == 0 {
+			continue
+		}
+		state.Shared.TrpArm += tenth(member.TrpArm)
+		state.Shared.TrpLnd += tenth(member.TrpLnd)
+		state.Shared.TrpFly += tenth(member.TrpFly)
+		state.Shared.TrpSea += tenth(member.TrpSea)
+	}
+}
+
+// tenth returns the number of units shared from a force.
+func tenth(n int) int {
+	return int(math.Round(float64(n) * 0.10))
+}
+
+// reinforce adds the defender's clanmates who share their forces to the
+// attack, from php/pages/military.php. Allies only help defenders who share
+// their own forces, and never against surprise attacks. The engine decides
+// which of them can reach the defender.
+func reinforce(tx *orm.DB, def *model.Empire_t, req *engine.Attack_t) error {
+	if def.CId == 0 || def.Sharing == 0 || req.Type == engine.ATTACK_SURPRISE {
+		return nil
+	}
+	ids, err := tx.ClanSharing(def.CId, def.Id)
+	if err != nil {
+		return err
+	}
+	var mods map[string]engine.Modifiers_t
+	if req.Tables != nil {
+		mods = req.Tables.Effects
+	}
+	for _, id := range ids {
+		ally, err := tx.EmpireFetch(id)
+		if err != nil {
+			return err
+		}
+		fx, err := tx.EmpireEffectsFetch(ally.Id, mods)
+		if err != nil {
+			return err
+		}
+		req.Allies = append(req.Allies, engine.Ally_t{Empire: *ally, Effects: fx})
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package clans
+
+import (
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestShare(t *testing.T) {
+	db, s := testService(t)
+	s = s.enabled()
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	started := model.RoundData_t{Started: true}
+
+	captain := testEmpire(t, db, "captain", 1000)
+	deckhand := testEmpire(t, db, "deckhand", 500)
+	loner := testEmpire(t, db, "lone wolf", 500)
+	res, err := s.Create(captain.Id, started, "PIRATES", "arr", "arr", now)
+	if err != nil || !res.Success {
+		t.Fatalf("create: %v %+v", err, res)
+	}
+	pirates := res.State.Clan.Id
+	if res, err := s.Join(deckhand.Id, started, pirates, "arr", now); err != nil || !res.Success {
+		t.Fatalf("join: %v %+v", err, res)
+	}
+
+	sharing := func(id int) int {
+		emp, err := db.EmpireFetch(id)
+		if err != nil {
+			t.Fatalf("fetch: %v", err)
+		}
+		return emp.Sharing
+	}
+	decrement := func(updates int) {
+		for range updates {
+			if err := db.EmpiresDecrementSharing(); err != nil {
+				t.Fatalf("decrement: %v", err)
+			}
+		}
+	}
+	for _, tc := range []struct {
+		id      string
+		empire  int
+		share   bool
+		want    string
+		sharing int
+	}{
+		{"no clan", loner.Id, true, "CLAN_NOT_MEMBER", 0},
+		{"no clan unshare", loner.Id, false, "CLAN_NOT_MEMBER", 0},
+		{"never shared", deckhand.Id, false, "CLAN_UNSHARE_NOT_SHARING", 0},
+		{"share", deckhand.Id, true, "CLAN_SHARE_COMPLETE", -1},
+		{"already sharing", deckhand.Id, true, "CLAN_SHARE_ALREADY", -1},
+		// 2 hours of turn updates every 10 minutes, the first of which ends with the next update
+		{"unshare", deckhand.Id, false, "CLAN_UNSHARE_COMPLETE", 11},
+		// unsharing again must not restart the delay
+		{"already unsharing", deckhand.Id, false, "CLAN_UNSHARE_NOT_SHARING", 11},
+	} {
+		run := s.Unshare
+		if tc.share {
+			run = s.Share
+		}
+		res, err := run(tc.empire, started, now)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.id, err)
+		} else if len(res.Messages) != 1 || res.Messages[0].Key != tc.want {
+			t.Errorf("%s: want %s, got %+v", tc.id, tc.want, res.Messages)
+		} else if got := sharing(tc.empire); got != tc.sharing {
+			t.Errorf("%s: sharing: want %d, got %d", tc.id, tc.sharing, got)
+		}
+	}
+	news, err := db.ClanNews(pirates, now.Add(-time.Second))
+	if err != nil {
+		t.Fatalf("news: %v", err)
+	}
+	var events []int
+	for _, n := range news {
+		if n.Event == engine.CLANNEWS_MEMBER_SHARE || n.Event == engine.CLANNEWS_MEMBER_UNSHARE {
+			events = append(events, n.Event)
+		}
+	}
+	if want := []int{engine.CLANNEWS_MEMBER_SHARE, engine.CLANNEWS_MEMBER_UNSHARE}; !slices.Equal(events, want) {
+		t.Errorf("news: want %v, got %v", want, events)
+	}
+
+	// the forces stay shared until the last turn update of the delay
+	state, err := s.State(deckhand.Id, started, now)
+	if err != nil {
+		t.Fatalf("state: %v", err)
+	} else if !state.Sharing || state.Unshare != 110 {
+		t.Errorf("unsharing: want sharing for 110 minutes, got %v %d", state.Sharing, state.Unshare)
+	}
+	decrement(10)
+	if state, err = s.State(deckhand.Id, started, now); err != nil {
+		t.Fatalf("state: %v", err)
+	} else if !state.Sharing || state.Unshare != 10 {
+		t.Errorf("last update: want sharing for 10 minutes, got %v %d", state.Sharing, state.Unshare)
+	}
+	decrement(1)
+	if state, err = s.State(deckhand.Id, started, now); err != nil {
+		t.Fatalf("state: %v", err)
+	} else if state.Sharing || state.Unshare != 0 {
+		t.Errorf("unshared: want not sharing, got %v %d", state.Sharing, state.Unshare)
+	}
+	decrement(1)
+	if got := sharing(deckhand.Id); got != 0 {
+		t.Errorf("unshared: want 0, got %d", got)
+	}
+
+	// sharing again while unsharing cancels the delay, and turn updates leave sharing empires alone
+	for _, run := range []func(int, model.RoundData_t, time.Time) (*Result_t, error){s.Share, s.Unshare} {
+		if res, err := run(deckhand.Id, started, now); err != nil || !res.Success {
+			t.Fatalf("share: %v %+v", err, res)
+		}
+	}
+	decrement(3)
+	if res, err := s.Share(deckhand.Id, started, now); err != nil || !res.Success {
+		t.Fatalf("reshare: %v %+v", err, res)
+	}
+	decrement(20)
+	if got := sharing(deckhand.Id); got != -1 {
+		t.Errorf("reshare: want -1, got %d", got)
+	}
+
+	// without a delay, unsharing takes effect at once
+	quick := *s
+	quick.cfg.MinShare = 0
+	if res, err := quick.Unshare(deckhand.Id, started, now); err != nil || !res.Success {
+		t.Fatalf("quick: %v %+v", err, res)
+	} else if got := sharing(deckhand.Id); got != 0 {
+		t.Errorf("quick: want 0, got %d", got)
+	} else if res.State.Sharing {
+		t.Errorf("quick: state: want not sharing")
+	}
+
+	// a tenth of each sharing member's forces are shared with the clan
+	for _, emp := range []struct {
+		id     int
+		troops int
+	}{{captain.Id, 1000}, {deckhand.Id, 255}} {
+		e, err := db.EmpireFetch(emp.id)
+		if err != nil {
+			t.Fatalf("fetch: %v", err)
+		}
+		e.TrpArm, e.TrpSea = emp.troops, emp.troops*2
+		if err := db.EmpireAttributesUpdate(e); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+	}
+	for _, id := range []int{captain.Id, deckhand.Id} {
+		if res, err := s.Share(id, started, now); err != nil || !res.Success {
+			t.Fatalf("share: %v %+v", err, res)
+		}
+	}
+	if state, err = s.State(deckhand.Id, started, now); err != nil {
+		t.Fatalf("state: %v", err)
+	} else if want := (engine.Units_t{TrpArm: 26, TrpSea: 51}); state.Share != want {
+		t.Errorf("share: want %+v, got %+v", want, state.Share)
+	} else if want = (engine.Units_t{TrpArm: 126, TrpSea: 251}); state.Shared != want {
+		t.Errorf("shared: want %+v, got %+v", want, state.Shared)
+	}
+
+	// leaving the clan stops sharing at once
+	if res, err := s.Leave(deckhand.Id, started, true, now.Add(73*time.Hour)); err != nil || !res.Success {
+		t.Fatalf("leave: %v %+v", err, res)
+	} else if got := sharing(deckhand.Id); got != 0 {
+		t.Errorf("leave: want 0, got %d", got)
+	}
+}
+
+func TestShareAttack(t *testing.T) {
+	db, s := testService(t)
+	s = s.enabled()
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	started := model.RoundData_t{Started: true}
+
+	captain := testEmpire(t, db, "captain", 1000)
+	res, err := s.Create(captain.Id, started, "PIRATES", "arr", "arr", now)
+	if err != nil || !res.Success {
+		t.Fatalf("create: %v %+v", err, res)
+	}
+	pirates := res.State.Clan.Id
+	var crew []int
+	for _, name := range []string{"deckhand", "bosun mate", "stowaway", "castaway", "mutineer"} {
+		emp := testEmpire(t, db, name, 500)
+		if res, err := s.Join(emp.Id, started, pirates, "arr", now); err != nil || !res.Success {
+			t.Fatalf("join: %v %+v", err, res)
+		}
+		crew = append(crew, emp.Id)
+	}
+	deckhand, bosun, stowaway, castaway, mutineer := crew[0], crew[1], crew[2], crew[3], crew[4]
+	for _, id := range []int{deckhand, bosun, castaway, mutineer} {
+		if res, err := s.Share(id, started, now); err != nil || !res.Success {
+			t.Fatalf("share: %v %+v", err, res)
+		}
+	}
+	// forces being unshared still help until the delay runs out
+	if res, err := s.Unshare(bosun, started, now); err != nil || !res.Success {
+		t.Fatalf("unshare: %v %+v", err, res)
+	}
+	// dead and disabled empires can't send help
+	dead, err := db.EmpireFetch(castaway)
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	dead.Land = 0
+	if err := db.EmpireAttributesUpdate(dead); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	disabled, err := db.EmpireFetch(mutineer)
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	disabled.Flags.Disable = true
+	if err := db.EmpireUpdateFlags(disabled); err != nil {
+		t.Fatalf("flags: %v", err)
+	}
+	raider := testEmpire(t, db, "raider", 800)
+
+	attack := func(defender int, kind engine.AttackType_t) []int {
+		def, err := db.EmpireFetch(defender)
+		if err != nil {
+			t.Fatalf("fetch: %v", err)
+		}
+		req := engine.Attack_t{Type: kind, Tables: s.tables}
+		if err := Attack(db, raider, def, &req); err != nil {
+			t.Fatalf("attack: %v", err)
+		}
+		var allies []int
+		for _, ally := range req.Allies {
+			if ally.Effects == nil {
+				t.Errorf("attack: ally %d: missing effects", ally.Empire.Id)
+			}
+			allies = append(allies, ally.Empire.Id)
+		}
+		return allies
+	}
+	for _, tc := range []struct {
+		id       string
+		defender int
+		kind     engine.AttackType_t
+		want     []int
+	}{
+		// defenders who don't share get no help
+		{"not sharing", stowaway, engine.ATTACK_STANDARD, nil},
+		{"sharing", deckhand, engine.ATTACK_STANDARD, []int{bosun}},
+		{"unsharing", bosun, engine.ATTACK_TRPARM, []int{deckhand}},
+		{"surprise", deckhand, engine.ATTACK_SURPRISE, nil},
+		{"no clan", raider.Id, engine.ATTACK_STANDARD, nil},
+	} {
+		if got := attack(tc.defender, tc.kind); !slices.Equal(got, tc.want) {
+			t.Errorf("%s: want %v, got %v", tc.id, tc.want, got)
+		}
+	}
+
+	// once the delay runs out, the forces are no longer shared
+	for range 11 {
+		if err := db.EmpiresDecrementSharing(); err != nil {
+			t.Fatalf("decrement: %v", err)
+		}
+	}
+	if got := attack(deckhand, engine.ATTACK_STANDARD); got != nil {
+		t.Errorf("unshared: want no allies, got %v", got)
+	}
+	if got := attack(bosun, engine.ATTACK_STANDARD); got != nil {
+		t.Errorf("unshared defender: want no allies, got %v", got)
+	}
+}
@@ -52,8 +52,7 @@ const (
 	TURNS_OFFSET        = 0       // offset (in minutes) for giving turns, relative to round start
 	TURNS_OFFSET_HOURLY = 0       // offset (in minutes) for performing hourly events, relative to round start
 	TURNS_OFFSET_DAILY  = 12 * 60 // offset (in minutes) for performing daily events, relative to round start
-	TURNS_CRONTAB       = TRUE    // use "turns.php" to give out turns, scheduled via crontab; otherwise, trigger on page loads
-	TURNS_CRONLOG       = TRUE    // if TURNS_CRONTAB is disabled, store turn logs in the database for retrieval by turns.php
+	TURNS_CRONLOG       = TRUE    // store turn logs from the scheduler in the database
 	TURNS_COUNT         = 1       // how many turns to give during each period
 	TURNS_UNSTORE       = 1       // how many turns to release from Stored Turns at once
 
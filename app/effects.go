@@ -0,0 +1,82 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// effectDescription_t is an effect as shown on the magic and military pages.
+type effectDescription_t struct {
+	Id       string
+	Name     string
+	Desc     string
+	Duration string
+	kind     int // permanent effects come first, then timed, then turn-based
+	remains  int // time or turns remaining
+}
+
+// effectDescriptions returns the effects on the empire that the user is allowed to see.
+// Administrators see every effect, moderators see all known effects, and
+// normal users only see known effects that are not marked as admin-only.
+func (s *server) effectDescriptions(user *model.User_t, emp *model.Empire_t, fx *engine.Effects_t, now time.Time) []effectDescription_t {
+	era, err := s.tables.Era(emp.Era)
+	if err != nil {
+		log.Printf("effects: empire %d: era %d: %v\n", emp.Id, emp.Era, err)
+	}
+
+	var descs []effectDescription_t
+	for _, id := range fx.Names() {
+		info, known := engine.EffectInfo(id)
+		if !user.Flags.Admin && !(known && (user.Flags.Mod || !info.Admin)) {
+			continue
+		}
+
+		desc := effectDescription_t{Id: id, Name: id}
+		switch {
+		case strings.HasPrefix(id, engine.EMPIRE_EFFECT_TIME):
+			desc.kind, desc.remains = 1, fx.Get(id, now)
+			if desc.remains <= 0 {
+				continue
+			}
+			desc.Duration = s.language.Duration(desc.remains)
+		case strings.HasPrefix(id, engine.EMPIRE_EFFECT_TURN):
+			desc.kind, desc.remains = 2, fx.Get(id, now)
+			if desc.remains <= 0 {
+				continue
+			}
+			desc.Duration = s.language.Plural(desc.remains, "TURNS_SINGLE", "TURNS_PLURAL", "")
+		case strings.HasPrefix(id, engine.EMPIRE_EFFECT_PERM):
+			desc.Duration = s.language.Printf("EFFECT_PERMANENT")
+		default:
+			log.Printf("effects: empire %d: effect %q: unrecognized type\n", emp.Id, id)
+			continue
+		}
+
+		if era != nil && era.EffectNames[info.EraName] != "" {
+			desc.Name = s.language.Printf(era.EffectNames[info.EraName])
+		} else if info.Name != "" {
+			desc.Name = s.language.Printf(info.Name)
+		}
+		if era != nil && era.EffectDescs[info.EraDesc] != "" {
+			desc.Desc = s.language.Printf(era.EffectDescs[info.EraDesc])
+		} else if info.Desc != "" {
+			desc.Desc = s.language.Printf(info.Desc)
+		}
+		descs = append(descs, desc)
+	}
+
+	// sort descending by duration remaining within each kind of effect
+	sort.SliceStable(descs, func(i, j int) bool {
+		if descs[i].kind != descs[j].kind {
+			return descs[i].kind < descs[j].kind
+		}
+		return descs[i].remains > descs[j].remains
+	})
+	return descs
+}
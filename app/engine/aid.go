@@ -0,0 +1,224 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package engine
+
+import (
+	"github.com/mdhender/promisance/app/model"
+	"math"
+	"time"
+)
+
+// Cargo_t holds an amount of each type of goods that can be sent as foreign aid.
+type Cargo_t struct {
+	TrpArm int `json:"trparm"`
+	TrpLnd int `json:"trplnd"`
+	TrpFly int `json:"trpfly"`
+	TrpSea int `json:"trpsea"`
+	Cash   int `json:"cash"`
+	Runes  int `json:"runes"`
+	Food   int `json:"food"`
+}
+
+// aidType is a type of goods that can be sent as foreign aid, from lookup('list_aid').
+type aidType struct {
+	name  func(*Era_t) string // language key for the goods, empty for cash
+	emp   func(*model.Empire_t) *int
+	cargo func(*Cargo_t) *int
+}
+
+var aidTypes = []aidType{
+	{func(era *Era_t) string { return era.TrpArm }, func(e *model.Empire_t) *int { return &e.TrpArm }, func(c *Cargo_t) *int { return &c.TrpArm }},
+	{func(era *Era_t) string { return era.TrpLnd }, func(e *model.Empire_t) *int { return &e.TrpLnd }, func(c *Cargo_t) *int { return &c.TrpLnd }},
+	{func(era *Era_t) string { return era.TrpFly }, func(e *model.Empire_t) *int { return &e.TrpFly }, func(c *Cargo_t) *int { return &c.TrpFly }},
+	{func(era *Era_t) string { return era.TrpSea }, func(e *model.Empire_t) *int { return &e.TrpSea }, func(c *Cargo_t) *int { return &c.TrpSea }},
+	{func(era *Era_t) string { return "" }, func(e *model.Empire_t) *int { return &e.Cash }, func(c *Cargo_t) *int { return &c.Cash }},
+	{func(era *Era_t) string { return era.Runes }, func(e *model.Empire_t) *int { return &e.Runes }, func(c *Cargo_t) *int { return &c.Runes }},
+	{func(era *Era_t) string { return era.Food }, func(e *model.Empire_t) *int { return &e.Food }, func(c *Cargo_t) *int { return &c.Food }},
+}
+
+// AidEnabled returns true if foreign aid is enabled.
+func (e *Engine_t) AidEnabled() bool {
+	return e.cfg.AidEnable
+}
+
+// AidConvoy returns the number of ships needed to carry a shipment of foreign aid.
+func (e *Engine_t) AidConvoy(emp *model.Empire_t) int {
+	return 2 * (emp.NetWorth / 10000)
+}
+
+// AidCanSend returns the most of each type of goods the empire can send in one shipment.
+func (e *Engine_t) AidCanSend(emp *model.Empire_t) Cargo_t {
+	var cansend Cargo_t
+	for _, t := range aidTypes {
+		*t.cargo(&cansend) = round(float64(*t.emp(emp)) * 0.20)
+	}
+	return cansend
+}
+
+// AidCredits returns the number of shipments the empire can send right now.
+// A credit is used for every shipment, and a new one is earned every AidDelay seconds.
+func (e *Engine_t) AidCredits(fx *Effects_t, now time.Time) int {
+	delay := max(e.cfg.AidDelay, 1)
+	return max(0, e.cfg.AidMaxCredits-int(math.Ceil(float64(fx.Get("m_sendaid", now))/float64(delay))))
+}
+
+// IsVacation returns true if the empire is protected by being on vacation.
+func (e *Engine_t) IsVacation(emp *model.Empire_t) bool {
+	return emp.Vacation >= int(e.cfg.VacationStart/time.Hour)+1
+}
+
+// Aid_t describes a shipment of foreign aid to another empire.
+type Aid_t struct {
+	Cargo            Cargo_t // goods to send; the ships always include at least the convoy
+	SameClan         bool    // the recipient is in the sender's clan
+	Allied           bool    // the recipient's clan is allied with the sender's clan
+	War              bool    // the recipient's clan is at war with the sender's clan
+	Tables           *Tables_t
+	SenderEffects    *Effects_t        // must not be nil
+	RecipientEffects *Effects_t        // may be nil
+	Round            model.RoundData_t // passed on when taking turns
+	Wars             int               // passed on when taking turns
+	Now              time.Time
+}
+
+// AidResult_t is the result of sending foreign aid.
+type AidResult_t struct {
+	Sent     bool      // false if the shipment was refused
+	Convoy   int       // ships needed to carry the shipment
+	Cargo    Cargo_t   // goods sent
+	Report   *Report_t // from taking turns after sending the shipment
+	Messages []Message_t
+	News     []News_t
+}
+
+// SendAid sends foreign aid from one empire to another and returns the updated empires,
+// from php/pages/aid.php. The empires passed in are not modified, but the sender's
+// effects are.
+//
+// Callers are responsible for the checks that don't depend on the recipient:
+// the round is in progress, aid is enabled, and the sender is neither protected
+// nor an administrator. Callers also supply the clan relations between the two.
+// If the shipment is refused, the result has a message explaining why.
+// Amounts of cash in messages are money; other amounts are numbers of units.
+func (e *Engine_t) SendAid(src, dst model.Empire_t, req Aid_t) (model.Empire_t, model.Empire_t, *AidResult_t, error) {
+	srcEra, err := req.Tables.Era(src.Era)
+	if err != nil {
+		return src, dst, nil, err
+	}
+	res := &AidResult_t{Convoy: e.AidConvoy(&src)}
+	refuse := func(key string, args ...any) (model.Empire_t, model.Empire_t, *AidResult_t, error) {
+		res.Messages = append(res.Messages, Message_t{Key: key, Args: args})
+		return src, dst, res, nil
+	}
+
+	if src.Id == dst.Id {
+		return refuse("AID_SELF")
+	} else if src.Turns < 2 {
+		return refuse("AID_NEED_TURNS")
+	} else if dst.Land == 0 {
+		return refuse("AID_TARGET_DEAD")
+	} else if dst.UserId == 0 {
+		return refuse("AID_TARGET_DELETED")
+	}
+	if !e.EraReachable(&src, &dst, req.SenderEffects, req.RecipientEffects, req.Now) {
+		return refuse("AID_TARGET_GATE")
+	} else if dst.Flags.Admin {
+		return refuse("AID_TARGET_ADMIN")
+	} else if dst.Flags.Disable {
+		return refuse("AID_TARGET_DISABLED")
+	} else if e.IsProtected(&dst, req.Round) {
+		return refuse("AID_TARGET_PROTECTED")
+	} else if e.IsVacation(&dst) {
+		return refuse("AID_TARGET_VACATION")
+	}
+
+	// Threshold beyond which an empire doesn't need your aid
+	netmultRefuse, aidCost := 10, 1
+	if e.cfg.ClanEnable && src.CId != 0 && dst.CId != 0 {
+		if req.SameClan || req.Allied {
+			netmultRefuse = 50
+			if !req.Round.Closing {
+				aidCost = 0 // aid to allies is free, except during the final week
+			}
+		}
+		if req.War {
+			return refuse("AID_TARGET_WAR")
+		}
+	}
+	if aidCost != 0 && req.SenderEffects.Get("m_sendaid", req.Now) >= (e.cfg.AidMaxCredits-1)*e.cfg.AidDelay {
+		return refuse("AID_NEED_CREDITS")
+	} else if dst.NetWorth > src.NetWorth*netmultRefuse {
+		return refuse("AID_TARGET_TOO_BIG")
+	}
+	cansend := e.AidCanSend(&src)
+	if cansend.TrpSea < res.Convoy {
+		return refuse("AID_NEED_CONVOY", srcEra.TrpSea)
+	}
+
+	send := req.Cargo
+	// must send at least the convoy size
+	send.TrpSea = max(send.TrpSea, res.Convoy)
+	var cargo bool
+	for _, t := range aidTypes {
+		amount := max(*t.cargo(&send), 0)
+		if limit := *t.cargo(&cansend); amount > limit {
+			if name := t.name(srcEra); name == "" {
+				res.Messages = append(res.Messages, Message_t{Key: "AID_TOO_MUCH_CASH", Args: []any{limit}})
+			} else {
+				res.Messages = append(res.Messages, Message_t{Key: "AID_TOO_MUCH_UNIT", Args: []any{name, limit}})
+			}
+			amount = limit
+		}
+		*t.cargo(&send) = amount
+		if amount != 0 && t.cargo(&send) != &send.TrpSea {
+			cargo = true
+		}
+	}
+	// if the only thing being sent is ships with the quantity being the minimum convoy size, abort
+	if !cargo && send.TrpSea == res.Convoy {
+		return refuse("AID_NO_CARGO")
+	}
+
+	for _, t := range aidTypes {
+		*t.emp(&src) -= *t.cargo(&send)
+		*t.emp(&dst) += *t.cargo(&send)
+	}
+	res.Sent, res.Cargo = true, send
+
+	event := EMPNEWS_ATTACH_AID_SEND
+	if src.CId != 0 && src.CId == dst.CId {
+		event = EMPNEWS_ATTACH_AID_SENDCLAN
+	}
+	res.News = append(res.News, NewNews(event, &src, &dst, res.Convoy, send.TrpArm, send.TrpLnd, send.TrpFly, send.TrpSea, send.Cash, send.Runes, send.Food))
+	if err := req.SenderEffects.Set("m_sendaid", req.SenderEffects.Get("m_sendaid", req.Now)+aidCost*e.cfg.AidDelay, req.Now); err != nil {
+		return src, dst, nil, err
+	}
+	src, res.Report = e.TakeTurns(src, req.Tables.Modifiers(&src), TakeTurns_t{
+		Turns:   2,
+		Action:  ACTION_AID,
+		Wars:    req.Wars,
+		Round:   req.Round,
+		Effects: req.SenderEffects,
+		Now:     req.Now,
+	})
+	res.Messages = append(res.Messages, Message_t{Key: "AID_COMPLETE", Args: []any{max(res.Convoy, send.TrpSea), srcEra.TrpSea, dst}})
+	if send.TrpSea < res.Convoy {
+		res.Messages = append(res.Messages, Message_t{Key: "AID_WILL_RETURN", Args: []any{res.Convoy - send.TrpSea, srcEra.TrpSea}})
+	}
+	return src, dst, res, nil
+}
+
+// returnAid has the recipient of a shipment of foreign aid send back any ships
+// beyond those that carried the shipment, from prom_empire::giveNews.
+// It returns the number of ships sent back and the news for the sender.
+func returnAid(emp *model.Empire_t, n News_t) (int, *News_t) {
+	convoy, ships := n.Data[0], n.Data[4]
+	if ships >= convoy {
+		return 0, nil
+	}
+	toReturn := convoy - ships
+	returned := min(toReturn, emp.TrpSea)
+	emp.TrpSea -= returned
+	news := NewNews(EMPNEWS_ATTACH_AID_RETURN, emp, &model.Empire_t{Id: n.Source, CId: n.SourceClan}, toReturn, returned)
+	return returned, &news
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package engine
+
+import (
+	"github.com/mdhender/promisance/app/model"
+	"testing"
+)
+
+// aidEmpires returns a sender that needs a convoy of 20 ships and can send
+// up to 100 of them, and a smaller recipient in the same era.
+func aidEmpires() (model.Empire_t, model.Empire_t) {
+	src, dst := testEmpire(), testEmpire()
+	src.Era, dst.Era = ERA_PRESENT, ERA_PRESENT
+	src.UserId, dst.Id, dst.UserId, dst.Name = 1, 2, 2, "Recipient"
+	src.NetWorth, dst.NetWorth = 100_000, 50_000
+	src.TrpSea = 500
+	return src, dst
+}
+
+func TestSendAidRefused(t *testing.T) {
+	tables := DefaultTables()
+	for _, tc := range []struct {
+		name  string
+		setup func(src, dst *model.Empire_t, req *Aid_t)
+		want  string
+	}{
+		{name: "self", setup: func(_, dst *model.Empire_t, _ *Aid_t) { dst.Id = 1 }, want: "AID_SELF"},
+		{name: "turns", setup: func(src, _ *model.Empire_t, _ *Aid_t) { src.Turns = 1 }, want: "AID_NEED_TURNS"},
+		{name: "dead", setup: func(_, dst *model.Empire_t, _ *Aid_t) { dst.Land = 0 }, want: "AID_TARGET_DEAD"},
+		{name: "deleted", setup: func(_, dst *model.Empire_t, _ *Aid_t) { dst.UserId = 0 }, want: "AID_TARGET_DELETED"},
+		{name: "gate", setup: func(_, dst *model.Empire_t, _ *Aid_t) { dst.Era = ERA_FUTURE }, want: "AID_TARGET_GATE"},
+		{name: "admin", setup: func(_, dst *model.Empire_t, _ *Aid_t) { dst.Flags.Admin = true }, want: "AID_TARGET_ADMIN"},
+		{name: "protected", setup: func(_, _ *model.Empire_t, req *Aid_t) { req.Round.Signup = true }, want: "AID_TARGET_PROTECTED"},
+		{name: "vacation", setup: func(_, dst *model.Empire_t, _ *Aid_t) { dst.Vacation = 13 }, want: "AID_TARGET_VACATION"},
+		{name: "war", setup: func(src, dst *model.Empire_t, req *Aid_t) { src.CId, dst.CId, req.War = 1, 2, true }, want: "AID_TARGET_WAR"},
+		{name: "credits", setup: func(_, _ *model.Empire_t, req *Aid_t) {
+			_ = req.SenderEffects.Set("m_sendaid", 4*60*60, spellTime)
+		}, want: "AID_NEED_CREDITS"},
+		{name: "too big", setup: func(_, dst *model.Empire_t, _ *Aid_t) { dst.NetWorth = 1_000_001 }, want: "AID_TARGET_TOO_BIG"},
+		{name: "convoy", setup: func(src, _ *model.Empire_t, _ *Aid_t) { src.TrpSea = 90 }, want: "AID_NEED_CONVOY"},
+		{name: "no cargo", setup: func(_, _ *model.Empire_t, req *Aid_t) { req.Cargo = Cargo_t{} }, want: "AID_NO_CARGO"},
+	} {
+		src, dst := aidEmpires()
+		req := Aid_t{
+			Cargo:         Cargo_t{Cash: 1000},
+			Tables:        tables,
+			SenderEffects: NewEffects(src.Id, nil, tables.Effects),
+			Now:           spellTime,
+		}
+		tc.setup(&src, &dst, &req)
+		gotSrc, gotDst, res, err := New(testConfig, nil).SendAid(src, dst, req)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		} else if res.Sent || len(res.Messages) != 1 || res.Messages[0].Key != tc.want {
+			t.Errorf("%s: want %s, got %+v", tc.name, tc.want, res)
+		} else if gotSrc != src || gotDst != dst {
+			t.Errorf("%s: empires changed", tc.name)
+		}
+	}
+}
+
+func TestSendAid(t *testing.T) {
+	tables := DefaultTables()
+	e := New(testConfig, nil)
+	src, dst := aidEmpires()
+	fx := NewEffects(src.Id, nil, tables.Effects)
+	// too much cash is capped at 20% of the sender's cash
+	src, dst, res, err := e.SendAid(src, dst, Aid_t{
+		Cargo:         Cargo_t{TrpArm: 10, Cash: 50_000, Food: 500},
+		Tables:        tables,
+		SenderEffects: fx,
+		Now:           spellTime,
+	})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	} else if !res.Sent || res.Convoy != 20 {
+		t.Fatalf("send: want sent with convoy 20, got %+v", res)
+	}
+	if want := (Cargo_t{TrpArm: 10, TrpSea: 20, Cash: 20_000, Food: 500}); res.Cargo != want {
+		t.Errorf("cargo: want %+v, got %+v", want, res.Cargo)
+	}
+	if len(res.Messages) != 2 || res.Messages[0].Key != "AID_TOO_MUCH_CASH" || res.Messages[1].Key != "AID_COMPLETE" {
+		t.Errorf("messages: got %+v", res.Messages)
+	}
+	if dst.TrpArm != 110 || dst.TrpSea != 30 || dst.Cash != 120_000 || dst.Food != 10_500 {
+		t.Errorf("recipient: got arm %d sea %d cash %d food %d", dst.TrpArm, dst.TrpSea, dst.Cash, dst.Food)
+	}
+	if src.TrpArm != 90 || src.TrpSea != 480 || src.Turns != 98 {
+		t.Errorf("sender: got arm %d sea %d turns %d", src.TrpArm, src.TrpSea, src.Turns)
+	}
+	if len(res.News) != 1 || res.News[0].Event != EMPNEWS_ATTACH_AID_SEND || res.News[0].Data[0] != 20 || res.News[0].Data[5] != 20_000 {
+		t.Errorf("news: got %+v", res.News)
+	}
+	if got := e.AidCredits(fx, spellTime); got != 4 {
+		t.Errorf("credits: want 4, got %d", got)
+	}
+
+	// aid to a clanmate is free until the round is closing
+	src, dst = aidEmpires()
+	src.CId, dst.CId = 7, 7
+	fx = NewEffects(src.Id, nil, tables.Effects)
+	_, _, res, err = e.SendAid(src, dst, Aid_t{Cargo: Cargo_t{Food: 500}, SameClan: true, Tables: tables, SenderEffects: fx, Now: spellTime})
+	if err != nil {
+		t.Fatalf("clan: %v", err)
+	} else if !res.Sent || res.News[0].Event != EMPNEWS_ATTACH_AID_SENDCLAN {
+		t.Errorf("clan: got %+v", res)
+	} else if got := e.AidCredits(fx, spellTime); got != 5 {
+		t.Errorf("clan: credits: want 5, got %d", got)
+	}
+}
+
+func TestGiveNewsAid(t *testing.T) {
+	e := New(testConfig, nil)
+	era, err := DefaultTables().Era(ERA_PRESENT)
+	if err != nil {
+		t.Fatalf("era: %v", err)
+	}
+	src, dst := aidEmpires()
+	// the recipient has fewer ships than the convoy that was not delivered
+	dst.TrpSea = 5
+	sent := NewNews(EMPNEWS_ATTACH_AID_SEND, &src, &dst, 20, 0, 0, 0, 12, 1000, 0, 0)
+	messages, created := e.GiveNews(&dst, era, []News_t{sent}, map[int]*model.Empire_t{src.Id: &src})
+	if dst.TrpSea != 0 {
+		t.Errorf("recipient: want 0 ships, got %d", dst.TrpSea)
+	} else if len(messages) != 1 || messages[0].Key != "EMPNEWS_GIVE_AID_SEND" {
+		t.Errorf("recipient: messages: got %+v", messages)
+	} else if len(created) != 1 || created[0].Event != EMPNEWS_ATTACH_AID_RETURN || created[0].Target != src.Id || created[0].Data[0] != 8 || created[0].Data[1] != 5 {
+		t.Fatalf("recipient: news: got %+v", created)
+	}
+
+	messages, created = e.GiveNews(&src, era, created, nil)
+	if src.TrpSea != 505 || len(created) != 0 {
+		t.Errorf("sender: want 505 ships, got %d", src.TrpSea)
+	} else if len(messages) != 1 || messages[0].Key != "EMPNEWS_GIVE_AID_RETURN_SOME" {
+		t.Errorf("sender: messages: got %+v", messages)
+	}
+}
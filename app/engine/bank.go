@@ -0,0 +1,75 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package engine
+
+import (
+	"github.com/mdhender/promisance/app/model"
+)
+
+// BankRates returns the empire's savings and loan interest rates, as annual percentages.
+// Larger empires earn less on savings and pay more on loans.
+func (e *Engine_t) BankRates(emp *model.Empire_t) (saveRate, loanRate float64) {
+	size := e.SizeBonus(emp)
+	return e.cfg.BankSaveRate - size, e.cfg.BankLoanRate + size
+}
+
+// BankLimits returns the largest savings and loan balances the empire can hold.
+func (e *Engine_t) BankLimits(emp *model.Empire_t) (maxSave, maxLoan int) {
+	return emp.NetWorth * 100, emp.NetWorth * 50
+}
+
+// The bank transactions are from php/pages/bank.php. Each returns the amount
+// moved and a message describing the transaction. If there was nothing to do,
+// the amount is zero and the message is empty.
+
+// BankBorrow takes out a loan. Loans are not available once the round is closing.
+func (e *Engine_t) BankBorrow(emp *model.Empire_t, round model.RoundData_t, amount int) (int, Message_t) {
+	if round.Closing {
+		return 0, Message_t{Key: "BANK_BORROW_TOO_LATE"}
+	} else if amount <= 0 {
+		return 0, Message_t{}
+	} else if _, maxLoan := e.BankLimits(emp); amount+emp.Loan > maxLoan {
+		return 0, Message_t{Key: "BANK_BORROW_TOO_MUCH"}
+	}
+	emp.Cash += amount
+	emp.Loan += amount
+	return amount, Message_t{Key: "BANK_BORROW_COMPLETE", Args: []any{amount}}
+}
+
+// BankRepay pays off part of a loan. Paying more than is owed pays off the whole loan.
+func (e *Engine_t) BankRepay(emp *model.Empire_t, amount int) (int, Message_t) {
+	amount = min(amount, emp.Loan)
+	if amount <= 0 {
+		return 0, Message_t{}
+	} else if amount > emp.Cash {
+		return 0, Message_t{Key: "BANK_REPAY_NOT_ENOUGH"}
+	}
+	emp.Cash -= amount
+	emp.Loan -= amount
+	return amount, Message_t{Key: "BANK_REPAY_COMPLETE", Args: []any{amount}}
+}
+
+// BankDeposit moves cash into savings.
+func (e *Engine_t) BankDeposit(emp *model.Empire_t, amount int) (int, Message_t) {
+	if amount <= 0 {
+		return 0, Message_t{}
+	} else if amount > emp.Cash {
+		return 0, Message_t{Key: "BANK_DEPOSIT_NOT_ENOUGH"}
+	} else if maxSave, _ := e.BankLimits(emp); amount+emp.Bank > maxSave {
+		return 0, Message_t{Key: "BANK_DEPOSIT_TOO_MUCH"}
+	}
+	emp.Cash -= amount
+	emp.Bank += amount
+	return amount, Message_t{Key: "BANK_DEPOSIT_COMPLETE", Args: []any{amount}}
+}
+
+// BankWithdraw moves savings into cash. Withdrawing more than is saved withdraws everything.
+func (e *Engine_t) BankWithdraw(emp *model.Empire_t, amount int) (int, Message_t) {
+	amount = min(amount, emp.Bank)
+	if amount <= 0 {
+		return 0, Message_t{}
+	}
+	emp.Cash += amount
+	emp.Bank -= amount
+	return amount, Message_t{Key: "BANK_WITHDRAW_COMPLETE", Args: []any{amount}}
+}
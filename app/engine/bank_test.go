@@ -0,0 +1,55 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package engine
+
+import (
+	"github.com/mdhender/promisance/app/model"
+	"testing"
+)
+
+func TestBank(t *testing.T) {
+	e := New(testConfig, nil)
+	emp := testEmpire()
+	emp.NetWorth = 1000
+	if saveRate, loanRate := e.BankRates(&emp); saveRate != 3.5 || loanRate != 8.0 {
+		t.Errorf("rates: want 3.5, 8.0, got %v, %v", saveRate, loanRate)
+	}
+	if maxSave, maxLoan := e.BankLimits(&emp); maxSave != 100000 || maxLoan != 50000 {
+		t.Errorf("limits: want 100000, 50000, got %d, %d", maxSave, maxLoan)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		do     func() (int, Message_t)
+		amount int
+		key    string
+		cash   int
+		bank   int
+		loan   int
+	}{
+		{"borrow closing", func() (int, Message_t) { return e.BankBorrow(&emp, model.RoundData_t{Closing: true}, 10) }, 0, "BANK_BORROW_TOO_LATE", 100000, 0, 0},
+		{"borrow too much", func() (int, Message_t) { return e.BankBorrow(&emp, model.RoundData_t{}, 50001) }, 0, "BANK_BORROW_TOO_MUCH", 100000, 0, 0},
+		{"borrow", func() (int, Message_t) { return e.BankBorrow(&emp, model.RoundData_t{}, 20000) }, 20000, "BANK_BORROW_COMPLETE", 120000, 0, 20000},
+		{"borrow nothing", func() (int, Message_t) { return e.BankBorrow(&emp, model.RoundData_t{}, 0) }, 0, "", 120000, 0, 20000},
+		{"deposit too much", func() (int, Message_t) { return e.BankDeposit(&emp, 100001) }, 0, "BANK_DEPOSIT_TOO_MUCH", 120000, 0, 20000},
+		{"deposit", func() (int, Message_t) { return e.BankDeposit(&emp, 100000) }, 100000, "BANK_DEPOSIT_COMPLETE", 20000, 100000, 20000},
+		{"deposit not enough", func() (int, Message_t) { return e.BankDeposit(&emp, 20001) }, 0, "BANK_DEPOSIT_NOT_ENOUGH", 20000, 100000, 20000},
+		{"repay all", func() (int, Message_t) { return e.BankRepay(&emp, 25000) }, 20000, "BANK_REPAY_COMPLETE", 0, 100000, 0},
+		{"repay nothing", func() (int, Message_t) { return e.BankRepay(&emp, 100) }, 0, "", 0, 100000, 0},
+		{"withdraw all", func() (int, Message_t) { return e.BankWithdraw(&emp, 250000) }, 100000, "BANK_WITHDRAW_COMPLETE", 100000, 0, 0},
+	} {
+		amount, msg := tc.do()
+		if amount != tc.amount || msg.Key != tc.key {
+			t.Errorf("%s: want %d, %q, got %d, %q", tc.name, tc.amount, tc.key, amount, msg.Key)
+		}
+		if emp.Cash != tc.cash || emp.Bank != tc.bank || emp.Loan != tc.loan {
+			t.Errorf("%s: want cash %d, bank %d, loan %d, got %d, %d, %d", tc.name, tc.cash, tc.bank, tc.loan, emp.Cash, emp.Bank, emp.Loan)
+		}
+	}
+
+	// loans can only be repaid with cash on hand
+	emp.Cash, emp.Loan = 100, 1000
+	if amount, msg := e.BankRepay(&emp, 1000); amount != 0 || msg.Key != "BANK_REPAY_NOT_ENOUGH" {
+		t.Errorf("repay: want 0, %q, got %d, %q", "BANK_REPAY_NOT_ENOUGH", amount, msg.Key)
+	}
+}
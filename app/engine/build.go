@@ -0,0 +1,328 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package engine
+
+import (
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/model"
+	"math"
+	"time"
+)
+
+// buildTypes lists the buildings in the order they are built, from php/pages/build.php.
+var buildTypes = []struct {
+	emp func(*model.Empire_t) *int
+	bld func(*Buildings_t) *int
+}{
+	{func(e *model.Empire_t) *int { return &e.BldPop }, func(b *Buildings_t) *int { return &b.BldPop }},
+	{func(e *model.Empire_t) *int { return &e.BldCash }, func(b *Buildings_t) *int { return &b.BldCash }},
+	{func(e *model.Empire_t) *int { return &e.BldTrp }, func(b *Buildings_t) *int { return &b.BldTrp }},
+	{func(e *model.Empire_t) *int { return &e.BldCost }, func(b *Buildings_t) *int { return &b.BldCost }},
+	{func(e *model.Empire_t) *int { return &e.BldWiz }, func(b *Buildings_t) *int { return &b.BldWiz }},
+	{func(e *model.Empire_t) *int { return &e.BldFood }, func(b *Buildings_t) *int { return &b.BldFood }},
+	{func(e *model.Empire_t) *int { return &e.BldDef }, func(b *Buildings_t) *int { return &b.BldDef }},
+}
+
+// BuildAmounts returns the cost of a single building, the number of buildings
+// that can be built per turn, and the number the empire can afford to build.
+// The modifiers should include the empire's race, era, and effects.
+func (e *Engine_t) BuildAmounts(emp *model.Empire_t, mods Modifiers_t) (cost, rate, canBuild int) {
+	cost = round(float64(e.cfg.BuildCost) + float64(emp.Land)*0.1)
+	rate = round(Modifier(mods.Buildrate) * (float64(emp.Land)*0.015 + 4))
+	// limit by available cash, available turns, and available land
+	canBuild = min(emp.Cash/max(cost, 1), rate*emp.Turns, emp.Freeland)
+	return cost, rate, canBuild
+}
+
+// Build_t describes a request to construct buildings.
+type Build_t struct {
+	Buildings Buildings_t       // number of each building to construct, Freeland is ignored
+	Wars      int               // passed on when taking turns
+	Round     model.RoundData_t // passed on when taking turns
+	Effects   *Effects_t        // passed on when taking turns
+	Now       time.Time
+}
+
+// BuildResult_t is the result of constructing buildings.
+type BuildResult_t struct {
+	Report    *Report_t   // from taking turns while building
+	Built     Buildings_t // number of each building constructed
+	Total     int         // number of buildings constructed
+	Spent     int         // cash spent
+	Turns     int         // turns spent building
+	OutOfCash bool        // construction stopped early because the empire ran out of cash
+}
+
+// Build constructs buildings on unused land, one turn at a time.
+// It returns the updated empire; the empire passed in is not modified, but the effects are.
+// Construction stops early if the empire runs into trouble or out of cash.
+func (e *Engine_t) Build(emp model.Empire_t, base Modifiers_t, req Build_t) (model.Empire_t, *BuildResult_t, error) {
+	mods := base
+	if req.Effects != nil {
+		mods = mods.Add(req.Effects.Modifiers(req.Now))
+	}
+	cost, rate, canBuild := e.BuildAmounts(&emp, mods)
+
+	var amounts []int // remaining to build for each building type
+	total := 0
+	for _, b := range buildTypes {
+		n := max(*b.bld(&req.Buildings), 0)
+		amounts = append(amounts, n)
+		total += n
+	}
+	if total == 0 {
+		return emp, nil, cerr.ErrNeedInput
+	} else if total > canBuild {
+		return emp, nil, cerr.ErrBuildTooMany
+	}
+
+	res := &BuildResult_t{Report: &Report_t{}}
+	turns := int(math.Ceil(float64(total) / float64(rate)))
+build:
+	for i := 0; i < turns; i++ {
+		var report *Report_t
+		emp, report = e.TakeTurns(emp, base, TakeTurns_t{
+			Turns:   1,
+			Action:  ACTION_BUILD,
+			Wars:    req.Wars,
+			Round:   req.Round,
+			Effects: req.Effects,
+			Now:     req.Now,
+		})
+		res.Turns++
+		res.Report.Taken += report.Taken
+		res.Report.Trouble = report.Trouble
+		res.Report.Turns = append(res.Report.Turns, report.Turns...)
+		res.Report.Overall.add(report.Overall)
+		if report.Trouble != 0 {
+			// trouble? stop building (and cancel anything that was to happen this turn)
+			break
+		}
+		unbuilt := rate
+		for unbuilt != 0 && res.Total < total {
+			// spread the construction evenly over the building types that remain
+			remaining, least := 0, 0
+			for _, n := range amounts {
+				if n != 0 {
+					if remaining == 0 || n < least {
+						least = n
+					}
+					remaining++
+				}
+			}
+			buildPer := max(min(rate/remaining, least), 1)
+			for j, b := range buildTypes {
+				if amounts[j] == 0 {
+					continue
+				}
+				toBuild := min(amounts[j], buildPer, unbuilt)
+				if emp.Cash < toBuild*cost {
+					res.OutOfCash = true
+					break build
+				}
+				*b.emp(&emp) += toBuild
+				*b.bld(&res.Built) += toBuild
+				emp.Freeland -= toBuild
+				emp.Cash -= toBuild * cost
+				res.Spent += toBuild * cost
+				amounts[j] -= toBuild
+				unbuilt -= toBuild
+				res.Total += toBuild
+				if unbuilt == 0 {
+					break
+				}
+			}
+		}
+	}
+	emp.NetWorth = e.Networth(&emp)
+	return emp, res, nil
+}
+
+// DemolishAmounts returns the cash salvaged from each building demolished, the
+// number of buildings that can be demolished per turn, and the number the
+// empire can demolish, from php/pages/demolish.php.
+// The modifiers should include the empire's race, era, and effects.
+func (e *Engine_t) DemolishAmounts(emp *model.Empire_t, mods Modifiers_t) (salvage, rate, canDemolish int) {
+	salvage = round((float64(e.cfg.BuildCost) + float64(emp.Land)*0.1) / 5)
+	rate = min(int(math.Floor((float64(emp.Land)*0.02+2)*Modifier(mods.Buildrate))), 200)
+	canDemolish = min(rate*emp.Turns, emp.Land-emp.Freeland)
+	return salvage, rate, canDemolish
+}
+
+// DropAmounts returns the number of acres of unused land that can be dropped
+// per turn and the number the empire can drop, from php/pages/demolish.php.
+// Empires that recently gained land drop it much more slowly. Empires can't
+// drop land below 1,000 acres.
+func (e *Engine_t) DropAmounts(emp *model.Empire_t, mods Modifiers_t, fx *Effects_t, now time.Time) (rate, canDrop int) {
+	// 200/turn for demolition is a maximum, but 50/turn for dropping is a minimum
+	rate = max(int(math.Ceil((float64(emp.Land)*0.02+2)*Modifier(mods.Buildrate)/10)), 50)
+	if fx != nil && fx.Active("m_droptime", now) {
+		rate = int(math.Ceil(float64(rate) / 10))
+	}
+	canDrop = min(rate*emp.Turns, emp.Freeland, max(0, emp.Land-1000))
+	return rate, canDrop
+}
+
+// Demolish_t describes a request to demolish buildings.
+type Demolish_t struct {
+	Buildings Buildings_t       // number of each building to demolish, Freeland is ignored
+	Wars      int               // passed on when taking turns
+	Round     model.RoundData_t // passed on when taking turns
+	Effects   *Effects_t        // passed on when taking turns
+	Now       time.Time
+}
+
+// DemolishResult_t is the result of demolishing buildings.
+type DemolishResult_t struct {
+	Report     *Report_t   // from taking turns while demolishing
+	Demolished Buildings_t // number of each building demolished
+	Total      int         // number of buildings demolished
+	Salvaged   int         // cash salvaged
+	Turns      int         // turns spent demolishing
+}
+
+// Demolish tears down buildings, one turn at a time, from php/pages/demolish.php.
+// It returns the updated empire; the empire passed in is not modified, but the effects are.
+// Demolition stops early if the empire runs into trouble.
+func (e *Engine_t) Demolish(emp model.Empire_t, base Modifiers_t, req Demolish_t) (model.Empire_t, *DemolishResult_t, error) {
+	mods := base
+	if req.Effects != nil {
+		mods = mods.Add(req.Effects.Modifiers(req.Now))
+	}
+	salvage, rate, _ := e.DemolishAmounts(&emp, mods)
+
+	var amounts []int // remaining to demolish for each building type
+	total := 0
+	for _, b := range buildTypes {
+		n := max(*b.bld(&req.Buildings), 0)
+		if n > *b.emp(&emp) {
+			return emp, nil, cerr.ErrDemolishTooMany
+		}
+		amounts = append(amounts, n)
+		total += n
+	}
+	turns := int(math.Ceil(float64(total) / float64(max(rate, 1))))
+	if turns > emp.Turns {
+		return emp, nil, cerr.ErrNeedTurns
+	} else if total == 0 {
+		return emp, nil, cerr.ErrNeedInput
+	}
+
+	res := &DemolishResult_t{Report: &Report_t{}}
+	for i := 0; i < turns; i++ {
+		undemolished := rate
+		for undemolished != 0 && res.Total < total {
+			// spread the demolition evenly over the building types that remain
+			remaining, least := 0, 0
+			for _, n := range amounts {
+				if n != 0 {
+					if remaining == 0 || n < least {
+						least = n
+					}
+					remaining++
+				}
+			}
+			demolishPer := max(min(rate/remaining, least), 1)
+			for j, b := range buildTypes {
+				if amounts[j] == 0 {
+					continue
+				}
+				toDemolish := min(amounts[j], demolishPer, undemolished)
+				*b.emp(&emp) -= toDemolish
+				*b.bld(&res.Demolished) += toDemolish
+				emp.Freeland += toDemolish
+				emp.Cash += toDemolish * salvage
+				res.Salvaged += toDemolish * salvage
+				amounts[j] -= toDemolish
+				undemolished -= toDemolish
+				res.Total += toDemolish
+				if undemolished == 0 {
+					break
+				}
+			}
+		}
+		var report *Report_t
+		emp, report = e.TakeTurns(emp, base, TakeTurns_t{
+			Turns:   1,
+			Action:  ACTION_DEMOLISH,
+			Wars:    req.Wars,
+			Round:   req.Round,
+			Effects: req.Effects,
+			Now:     req.Now,
+		})
+		res.Report.Taken += report.Taken
+		res.Report.Trouble = report.Trouble
+		res.Report.Turns = append(res.Report.Turns, report.Turns...)
+		res.Report.Overall.add(report.Overall)
+		if report.Trouble != 0 {
+			// the turn in trouble is not counted as spent demolishing
+			break
+		}
+		res.Turns++
+	}
+	emp.NetWorth = e.Networth(&emp)
+	return emp, res, nil
+}
+
+// DropLand_t describes a request to drop unused land.
+type DropLand_t struct {
+	Acres   int               // acres of unused land to drop
+	Wars    int               // passed on when taking turns
+	Round   model.RoundData_t // passed on when taking turns
+	Effects *Effects_t        // passed on when taking turns, and to check for recently gained land
+	Now     time.Time
+}
+
+// DropLandResult_t is the result of dropping unused land.
+type DropLandResult_t struct {
+	Report  *Report_t // from taking turns while dropping land
+	Dropped int       // acres dropped
+	Turns   int       // turns spent dropping land
+}
+
+// DropLand abandons unused land, one turn at a time, from php/pages/demolish.php.
+// It returns the updated empire; the empire passed in is not modified, but the effects are.
+// Dropping land stops early if the empire runs into trouble.
+func (e *Engine_t) DropLand(emp model.Empire_t, base Modifiers_t, req DropLand_t) (model.Empire_t, *DropLandResult_t, error) {
+	mods := base
+	if req.Effects != nil {
+		mods = mods.Add(req.Effects.Modifiers(req.Now))
+	}
+	rate, canDrop := e.DropAmounts(&emp, mods, req.Effects, req.Now)
+	acres := max(req.Acres, 0)
+	if acres > canDrop {
+		return emp, nil, cerr.ErrDropTooMuch
+	} else if acres == 0 {
+		return emp, nil, cerr.ErrNeedInput
+	}
+
+	res := &DropLandResult_t{Report: &Report_t{}}
+	turns := int(math.Ceil(float64(acres) / float64(rate)))
+	for i := 0; i < turns; i++ {
+		toDrop := min(rate, acres)
+		emp.Land -= toDrop
+		emp.Freeland -= toDrop
+		acres -= toDrop
+		res.Dropped += toDrop
+		var report *Report_t
+		emp, report = e.TakeTurns(emp, base, TakeTurns_t{
+			Turns:   1,
+			Action:  ACTION_DROPLAND,
+			Wars:    req.Wars,
+			Round:   req.Round,
+			Effects: req.Effects,
+			Now:     req.Now,
+		})
+		res.Report.Taken += report.Taken
+		res.Report.Trouble = report.Trouble
+		res.Report.Turns = append(res.Report.Turns, report.Turns...)
+		res.Report.Overall.add(report.Overall)
+		if report.Trouble != 0 {
+			// the turn in trouble is not counted as spent dropping land
+			break
+		}
+		res.Turns++
+	}
+	emp.NetWorth = e.Networth(&emp)
+	return emp, res, nil
+}
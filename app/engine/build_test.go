@@ -0,0 +1,160 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package engine
+
+import (
+	"errors"
+	"github.com/mdhender/promisance/app/cerr"
+	"testing"
+)
+
+func TestBuild(t *testing.T) {
+	cfg := testConfig
+	cfg.BuildCost = 3500
+	e := New(cfg, nil)
+	emp := testEmpire()
+	cost, rate, canBuild := e.BuildAmounts(&emp, Modifiers_t{})
+	if cost != 3525 || rate != 8 || canBuild != 28 {
+		t.Fatalf("amounts: want 3525, 8, 28, got %d, %d, %d", cost, rate, canBuild)
+	}
+
+	if _, _, err := e.Build(emp, Modifiers_t{}, Build_t{}); !errors.Is(err, cerr.ErrNeedInput) {
+		t.Errorf("nothing: want %v, got %v", cerr.ErrNeedInput, err)
+	}
+	if _, _, err := e.Build(emp, Modifiers_t{}, Build_t{Buildings: Buildings_t{BldCash: 29}}); !errors.Is(err, cerr.ErrBuildTooMany) {
+		t.Errorf("too many: want %v, got %v", cerr.ErrBuildTooMany, err)
+	}
+
+	got, res, err := e.Build(emp, Modifiers_t{}, Build_t{Buildings: Buildings_t{BldCash: 10, BldFood: 5, Freeland: 100}})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if res.Turns != 2 || res.Total != 15 || res.Built.BldCash != 10 || res.Built.BldFood != 5 || res.Built.Freeland != 0 {
+		t.Errorf("build: got turns %d, built %+v", res.Turns, res.Built)
+	}
+	if got.BldCash != emp.BldCash+10 || got.BldFood != emp.BldFood+5 || got.Freeland != emp.Freeland-15 || got.Turns != emp.Turns-2 {
+		t.Errorf("empire: got cash %d, food %d, freeland %d, turns %d", got.BldCash, got.BldFood, got.Freeland, got.Turns)
+	}
+	if res.Spent != 15*cost || got.Cash != emp.Cash+res.Report.Overall.Money-res.Spent {
+		t.Errorf("cash: spent %d, got %d", res.Spent, got.Cash)
+	}
+}
+
+func TestDemolish(t *testing.T) {
+	cfg := testConfig
+	cfg.BuildCost = 3500
+	e := New(cfg, nil)
+	emp := testEmpire()
+	salvage, rate, canDemolish := e.DemolishAmounts(&emp, Modifiers_t{})
+	if salvage != 705 || rate != 7 || canDemolish != 50 {
+		t.Fatalf("amounts: want 705, 7, 50, got %d, %d, %d", salvage, rate, canDemolish)
+	}
+
+	if _, _, err := e.Demolish(emp, Modifiers_t{}, Demolish_t{}); !errors.Is(err, cerr.ErrNeedInput) {
+		t.Errorf("nothing: want %v, got %v", cerr.ErrNeedInput, err)
+	}
+	if _, _, err := e.Demolish(emp, Modifiers_t{}, Demolish_t{Buildings: Buildings_t{BldCash: 11}}); !errors.Is(err, cerr.ErrDemolishTooMany) {
+		t.Errorf("too many: want %v, got %v", cerr.ErrDemolishTooMany, err)
+	}
+	short := emp
+	short.Turns = 1
+	if _, _, err := e.Demolish(short, Modifiers_t{}, Demolish_t{Buildings: Buildings_t{BldCash: 8}}); !errors.Is(err, cerr.ErrNeedTurns) {
+		t.Errorf("turns: want %v, got %v", cerr.ErrNeedTurns, err)
+	}
+
+	got, res, err := e.Demolish(emp, Modifiers_t{}, Demolish_t{Buildings: Buildings_t{BldCash: 5, BldFood: 5, Freeland: 100}})
+	if err != nil {
+		t.Fatalf("demolish: %v", err)
+	}
+	if res.Turns != 2 || res.Total != 10 || res.Demolished.BldCash != 5 || res.Demolished.BldFood != 5 || res.Salvaged != 10*salvage {
+		t.Errorf("demolish: got turns %d, demolished %+v, salvaged %d", res.Turns, res.Demolished, res.Salvaged)
+	}
+	if got.BldCash != emp.BldCash-5 || got.BldFood != emp.BldFood-5 || got.Freeland != emp.Freeland+10 || got.Turns != emp.Turns-2 {
+		t.Errorf("empire: got cash %d, food %d, freeland %d, turns %d", got.BldCash, got.BldFood, got.Freeland, got.Turns)
+	}
+}
+
+func TestDropLand(t *testing.T) {
+	e := New(testConfig, nil)
+	tables := DefaultTables()
+	emp := testEmpire()
+	emp.Land, emp.Freeland = 1500, 1200
+	fx := NewEffects(emp.Id, nil, tables.Effects)
+	rate, canDrop := e.DropAmounts(&emp, Modifiers_t{}, fx, spellTime)
+	if rate != 50 || canDrop != 500 {
+		t.Fatalf("amounts: want 50, 500, got %d, %d", rate, canDrop)
+	}
+
+	if _, _, err := e.DropLand(emp, Modifiers_t{}, DropLand_t{Acres: 501, Effects: fx, Now: spellTime}); !errors.Is(err, cerr.ErrDropTooMuch) {
+		t.Errorf("too much: want %v, got %v", cerr.ErrDropTooMuch, err)
+	}
+	got, res, err := e.DropLand(emp, Modifiers_t{}, DropLand_t{Acres: 120, Effects: fx, Now: spellTime})
+	if err != nil {
+		t.Fatalf("drop: %v", err)
+	} else if res.Dropped != 120 || res.Turns != 3 || got.Land != 1380 || got.Freeland != 1080 || got.Turns != emp.Turns-3 {
+		t.Errorf("drop: got dropped %d, turns %d, land %d, freeland %d", res.Dropped, res.Turns, got.Land, got.Freeland)
+	}
+
+	// recently gained land is dropped much more slowly
+	if err := fx.Set("m_droptime", 60*60, spellTime); err != nil {
+		t.Fatalf("effect: %v", err)
+	}
+	if rate, canDrop := e.DropAmounts(&emp, Modifiers_t{}, fx, spellTime); rate != 5 || canDrop != 500 {
+		t.Errorf("droptime: want 5, 500, got %d, %d", rate, canDrop)
+	}
+}
+
+func TestBuildWarTax(t *testing.T) {
+	cfg := testConfig
+	cfg.BuildCost = 3500
+	e := New(cfg, nil)
+	tables := DefaultTables()
+	emp := testEmpire()
+	emp.CId = 1
+	emp.Land, emp.Freeland = 1500, 1200
+	emp.NetWorth = e.Networth(&emp)
+	fx := NewEffects(emp.Id, nil, tables.Effects)
+
+	// each war the clan has declared costs a percent of networth every turn
+	for _, tc := range []struct {
+		id  string
+		run func(wars int) (*Report_t, error)
+	}{
+		{"build", func(wars int) (*Report_t, error) {
+			_, res, err := e.Build(emp, Modifiers_t{}, Build_t{Buildings: Buildings_t{BldCash: 10}, Wars: wars})
+			if err != nil {
+				return nil, err
+			}
+			return res.Report, nil
+		}},
+		{"demolish", func(wars int) (*Report_t, error) {
+			_, res, err := e.Demolish(emp, Modifiers_t{}, Demolish_t{Buildings: Buildings_t{BldCash: 5}, Wars: wars})
+			if err != nil {
+				return nil, err
+			}
+			return res.Report, nil
+		}},
+		{"drop", func(wars int) (*Report_t, error) {
+			_, res, err := e.DropLand(emp, Modifiers_t{}, DropLand_t{Acres: 20, Wars: wars, Effects: fx, Now: spellTime})
+			if err != nil {
+				return nil, err
+			}
+			return res.Report, nil
+		}},
+	} {
+		peace, err := tc.run(0)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.id, err)
+		}
+		war, err := tc.run(2)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.id, err)
+		}
+		if peace.Overall.WarTax != 0 {
+			t.Errorf("%s: peace: want no war tax, got %d", tc.id, peace.Overall.WarTax)
+		}
+		if war.Overall.WarTax == 0 {
+			t.Errorf("%s: war: want war tax, got none", tc.id)
+		}
+	}
+}
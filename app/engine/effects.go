@@ -0,0 +1,167 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// All effect names must be prefixed with one of these distinct strings.
+const (
+	EMPIRE_EFFECT_PERM = "p_" // permanent, value is stored as-is
+	EMPIRE_EFFECT_TIME = "m_" // timed, value is the time the effect expires
+	EMPIRE_EFFECT_TURN = "r_" // turn-based, value is decremented every turn taken
+)
+
+// Effects_t holds the effects on a single empire, as stored in the empire_effect table.
+// Timed effects are stored as Unix times and reported as seconds remaining.
+type Effects_t struct {
+	EmpireId int
+	values   map[string]int
+	changed  map[string]bool
+	mods     map[string]Modifiers_t // modifiers granted by active effects
+}
+
+// NewEffects returns the effects for an empire. Values are the raw values from the
+// database and mods are the modifiers granted while each effect is active.
+func NewEffects(empireId int, values map[string]int, mods map[string]Modifiers_t) *Effects_t {
+	fx := &Effects_t{
+		EmpireId: empireId,
+		values:   map[string]int{},
+		changed:  map[string]bool{},
+		mods:     mods,
+	}
+	for name, value := range values {
+		fx.values[name] = value
+	}
+	return fx
+}
+
+// EffectKind returns the prefix of the effect name, or an error if it is not recognized.
+func EffectKind(name string) (string, error) {
+	for _, kind := range []string{EMPIRE_EFFECT_PERM, EMPIRE_EFFECT_TIME, EMPIRE_EFFECT_TURN} {
+		if strings.HasPrefix(name, kind) {
+			return kind, nil
+		}
+	}
+	return "", fmt.Errorf("effect %q: unrecognized type", name)
+}
+
+// Changed returns the raw values of the effects that have been changed since
+// the effects were loaded or last marked as saved.
+func (fx *Effects_t) Changed() map[string]int {
+	changed := map[string]int{}
+	for name := range fx.changed {
+		changed[name] = fx.values[name]
+	}
+	return changed
+}
+
+// Saved clears the list of changed effects.
+func (fx *Effects_t) Saved() {
+	fx.changed = map[string]bool{}
+}
+
+// Get returns the value of an effect.
+// Timed effects return the number of seconds remaining, never less than zero.
+// Turn-based and permanent effects are returned as-is.
+// Effects that are not set return zero.
+func (fx *Effects_t) Get(name string, now time.Time) int {
+	value, ok := fx.values[name]
+	if strings.HasPrefix(name, EMPIRE_EFFECT_TIME) {
+		if !ok {
+			return 0
+		}
+		return max(value-int(now.Unix()), 0)
+	}
+	return value
+}
+
+// Active returns true if the effect has time, turns, or a value remaining.
+func (fx *Effects_t) Active(name string, now time.Time) bool {
+	return fx.Get(name, now) > 0
+}
+
+// Set updates the value of an effect, creating it if needed.
+// Timed effects are set as the number of seconds from now.
+// Turn-based and permanent effects are set as-is.
+func (fx *Effects_t) Set(name string, value int, now time.Time) error {
+	kind, err := EffectKind(name)
+	if err != nil {
+		return err
+	}
+	if kind == EMPIRE_EFFECT_TIME {
+		value += int(now.Unix())
+	}
+	fx.values[name] = value
+	fx.changed[name] = true
+	return nil
+}
+
+// Clear ends an effect. The effect is removed from the database by the next cleanup.
+func (fx *Effects_t) Clear(name string) {
+	fx.values[name] = 0
+	fx.changed[name] = true
+}
+
+// TakeTurn subtracts one turn from every active turn-based effect.
+func (fx *Effects_t) TakeTurn() {
+	for name, value := range fx.values {
+		if strings.HasPrefix(name, EMPIRE_EFFECT_TURN) && value > 0 {
+			fx.values[name] = value - 1
+			fx.changed[name] = true
+		}
+	}
+}
+
+// Modifiers returns the sum of the modifiers granted by all active effects.
+func (fx *Effects_t) Modifiers(now time.Time) Modifiers_t {
+	var mods Modifiers_t
+	for name, m := range fx.mods {
+		if fx.Active(name, now) {
+			mods = mods.Add(m)
+		}
+	}
+	return mods
+}
+
+// Names returns the names of all effects in order.
+func (fx *Effects_t) Names() []string {
+	var names []string
+	for name := range fx.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// EffectInfo_t describes how an effect is shown to players. Names are language keys.
+type EffectInfo_t struct {
+	Name    string // name of the effect
+	Desc    string // description of the effect
+	EraName string // if set, the effect's name is taken from the era's effect names
+	EraDesc string // if set, the effect's description is taken from the era's effect descriptions
+	Admin   bool   // only shown to moderators and administrators
+}
+
+// effectInfo lists the effects from php/classes/prom_empire_effects.php.
+var effectInfo = map[string]EffectInfo_t{
+	"r_newera":     {Name: "EFFECT_NAME_NEWERA", Desc: "EFFECT_DESC_NEWERA"},
+	"m_gate":       {Name: "EFFECT_NAME_GATE", Desc: "EFFECT_DESC_GATE", EraName: "gate"},
+	"m_shield":     {Name: "EFFECT_NAME_SHIELD", Desc: "EFFECT_DESC_SHIELD", EraName: "shield", EraDesc: "shield"},
+	"m_clan":       {Name: "EFFECT_NAME_CLAN", Desc: "EFFECT_DESC_CLAN", Admin: true},
+	"m_revalidate": {Name: "EFFECT_NAME_REVALIDATE", Desc: "EFFECT_DESC_REVALIDATE", Admin: true},
+	"m_droptime":   {Name: "EFFECT_NAME_DROPTIME", Desc: "EFFECT_DESC_DROPTIME", Admin: true},
+	"m_sendaid":    {Name: "EFFECT_NAME_SENDAID", Desc: "EFFECT_DESC_SENDAID", Admin: true},
+	"m_message":    {Name: "EFFECT_NAME_MESSAGE", Desc: "EFFECT_DESC_MESSAGE", Admin: true},
+	"m_freeturns":  {Name: "EFFECT_NAME_FREETURNS", Desc: "EFFECT_DESC_FREETURNS", Admin: true},
+}
+
+// EffectInfo returns the description of a known effect.
+func EffectInfo(name string) (EffectInfo_t, bool) {
+	info, ok := effectInfo[name]
+	return info, ok
+}
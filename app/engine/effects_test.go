@@ -0,0 +1,86 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package engine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEffects(t *testing.T) {
+	now := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	fx := NewEffects(1, map[string]int{
+		"m_shield": int(now.Add(time.Hour).Unix()),
+		"m_gate":   int(now.Add(-time.Hour).Unix()),
+		"r_newera": 2,
+	}, map[string]Modifiers_t{
+		"r_newera": {Income: 10},
+		"m_gate":   {Explore: 50},
+	})
+
+	if got := fx.Get("m_shield", now); got != 3600 {
+		t.Errorf("m_shield: want 3600, got %d", got)
+	}
+	if got := fx.Get("m_gate", now); got != 0 {
+		t.Errorf("m_gate: want 0, got %d", got)
+	}
+	if got := fx.Get("m_clan", now); got != 0 {
+		t.Errorf("m_clan: want 0, got %d", got)
+	}
+	if err := fx.Set("x_bogus", 1, now); err == nil {
+		t.Errorf("x_bogus: want error, got nil")
+	}
+	if mods := fx.Modifiers(now); mods != (Modifiers_t{Income: 10}) {
+		t.Errorf("modifiers: want income 10, got %+v", mods)
+	}
+
+	if err := fx.Set("m_gate", 600, now); err != nil {
+		t.Fatalf("m_gate: %v", err)
+	}
+	if got := fx.Get("m_gate", now.Add(time.Minute)); got != 540 {
+		t.Errorf("m_gate: want 540, got %d", got)
+	}
+	if got := fx.Changed()["m_gate"]; got != int(now.Unix())+600 {
+		t.Errorf("m_gate: changed: want %d, got %d", now.Unix()+600, got)
+	}
+
+	fx.TakeTurn()
+	fx.TakeTurn()
+	fx.TakeTurn()
+	if got := fx.Get("r_newera", now); got != 0 {
+		t.Errorf("r_newera: want 0, got %d", got)
+	}
+	if mods := fx.Modifiers(now); mods != (Modifiers_t{Explore: 50}) {
+		t.Errorf("modifiers: want explore 50, got %+v", mods)
+	}
+	if len(fx.Changed()) != 2 {
+		t.Errorf("changed: want 2, got %v", fx.Changed())
+	}
+	fx.Saved()
+	if len(fx.Changed()) != 0 {
+		t.Errorf("saved: want 0, got %v", fx.Changed())
+	}
+}
+
+func TestTakeTurnsEffects(t *testing.T) {
+	now := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	emp := testEmpire()
+	fx := NewEffects(emp.Id, map[string]int{"r_newera": 2}, map[string]Modifiers_t{"r_newera": {Explore: 100}})
+
+	// the bonus applies to the first two turns only
+	_, report := New(testConfig, nil).TakeTurns(emp, Modifiers_t{}, TakeTurns_t{Turns: 1, Action: ACTION_LAND, Effects: fx, Now: now})
+	if report.Result != 132 {
+		t.Errorf("explore: want 132, got %d", report.Result)
+	}
+	if got := fx.Get("r_newera", now); got != 1 {
+		t.Errorf("r_newera: want 1, got %d", got)
+	}
+	got, _ := New(testConfig, nil).TakeTurns(emp, Modifiers_t{}, TakeTurns_t{Turns: 3, Action: ACTION_LAND, Effects: fx, Now: now})
+	plain, _ := New(testConfig, nil).TakeTurns(emp, Modifiers_t{}, TakeTurns_t{Turns: 3, Action: ACTION_LAND})
+	if got.Land <= plain.Land {
+		t.Errorf("explore: want more than %d acres, got %d", plain.Land, got.Land)
+	}
+	if got := fx.Get("r_newera", now); got != 0 {
+		t.Errorf("r_newera: want 0, got %d", got)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package engine
+
+import (
+	"github.com/mdhender/promisance/app/model"
+	"math"
+)
+
+// Finances returns the empire's income, loan payment, and expenses for one turn.
+func (e *Engine_t) Finances(emp *model.Empire_t, mods Modifiers_t) (income, loan, expenses int) {
+	income = round((float64(e.PCI(emp, mods))*(float64(emp.Tax)/100)*(float64(emp.Health)/100)*float64(emp.Peasants) + float64(emp.BldCash)*500) / e.SizeBonus(emp))
+	loan = round(float64(emp.Loan) / 200)
+	expenses = round(float64(emp.TrpArm)*1 + float64(emp.TrpLnd)*2.5 + float64(emp.TrpFly)*4 + float64(emp.TrpSea)*7 + float64(emp.Land)*8 + float64(emp.TrpWiz)*0.5)
+	expbonus := math.Min(0.5, (Modifier(mods.Expenses)-1)+float64(emp.BldCost)/float64(max(emp.Land, 1)))
+	expenses -= round(float64(expenses) * expbonus)
+	return income, loan, expenses
+}
+
+// GiveLand returns how much land the empire gains from one turn of exploration.
+func (e *Engine_t) GiveLand(emp *model.Empire_t, mods Modifiers_t) int {
+	return int(math.Ceil((1 / (float64(emp.Land)*0.00022 + 0.25)) * 20 * Modifier(mods.Explore)))
+}
+
+// IsProtected returns true if the empire is under protection due to being a new empire.
+// Once you've used N+1 turns, you're out of protection.
+// Once signups have been closed, new empire protection is dropped.
+func (e *Engine_t) IsProtected(emp *model.Empire_t, round model.RoundData_t) bool {
+	return emp.TurnsUsed <= e.cfg.TurnsProtection && round.Signup
+}
+
+// Networth returns the empire's networth.
+func (e *Engine_t) Networth(emp *model.Empire_t) int {
+	trpArm := float64(max(e.cfg.PvtmTrpArm, 1))
+	var net float64
+	// Troops
+	net += float64(emp.TrpArm) * 1
+	net += float64(emp.TrpLnd) * float64(e.cfg.PvtmTrpLnd) / trpArm
+	net += float64(emp.TrpFly) * float64(e.cfg.PvtmTrpFly) / trpArm
+	net += float64(emp.TrpSea) * float64(e.cfg.PvtmTrpSea) / trpArm
+	net += float64(emp.TrpWiz) * 2
+	net += float64(emp.Peasants) * 3
+	// Cash
+	net += (float64(emp.Cash) + float64(emp.Bank)/2 - float64(emp.Loan)*2) / (5 * trpArm)
+	net += float64(emp.Land) * 500
+	net += float64(emp.Freeland) * 100
+	// Food, reduced using logarithm to prevent it from boosting networth to ludicrous levels
+	net += float64(emp.Food) / math.Log10(math.Max(10, float64(emp.Food))) * (float64(e.cfg.PvtmFood) / trpArm)
+	return max(0, int(math.Floor(net)))
+}
+
+// PCI returns the empire's per capita income.
+func (e *Engine_t) PCI(emp *model.Empire_t, mods Modifiers_t) int {
+	return round(25 * (1 + float64(emp.BldCash)/float64(max(emp.Land, 1))) * Modifier(mods.Income))
+}
+
+// Provisions returns the empire's food production and consumption for one turn.
+func (e *Engine_t) Provisions(emp *model.Empire_t, mods Modifiers_t) (production, consumption int) {
+	pro := 10*float64(emp.Freeland) + (float64(emp.BldFood)*85)*math.Sqrt(1-0.75*float64(emp.BldFood)/float64(max(emp.Land, 1)))
+	production = round(pro * Modifier(mods.Foodpro))
+
+	con := float64(emp.TrpArm)*0.05 + float64(emp.TrpLnd)*0.03 + float64(emp.TrpFly)*0.02 + float64(emp.TrpSea)*0.01 + float64(emp.Peasants)*0.01 + float64(emp.TrpWiz)*0.25
+	consumption = round(con * (2 - Modifier(mods.Foodcon)))
+	return production, consumption
+}
+
+// ScorePoints returns the base number of points the empire earns for a successful
+// action against another empire. Larger targets are worth more points.
+func (e *Engine_t) ScorePoints(emp, other *model.Empire_t) int {
+	if other == nil {
+		return 0
+	}
+	ratio := float64(other.NetWorth) / float64(max(1, emp.NetWorth))
+	if ratio <= 1 {
+		return 1
+	}
+	return 1 + int(math.Floor((ratio-1)*2))
+}
+
+// SizeBonus returns the empire size bonus/penalty, mainly used for interest rates.
+// Ranges from 0.5 to 1.7, rounded to 3 decimal places.
+func (e *Engine_t) SizeBonus(emp *model.Empire_t) float64 {
+	networth := float64(max(emp.NetWorth, 1)) // must be 1 or greater
+	size := math.Atan(math.Log(networth)/math.Log(1000)-1)*2.1 - 0.65
+	return math.Round(math.Min(math.Max(0.5, size), 1.7)*1000) / 1000
+}
+
+// round rounds half away from zero, like PHP's round.
+func round(f float64) int {
+	return int(math.Round(f))
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+// Package engine implements the game rules from php/classes/prom_empire.php.
+// Functions in this package do not touch the database; they take an empire
+// and return the updated copy so that they can be tested and reused by
+// handlers and tools.
+package engine
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	TURNS_TROUBLE_CASH = 1
+	TURNS_TROUBLE_FOOD = 4
+	TURNS_TROUBLE_LOAN = 2
+)
+
+// Action_t is the action an empire performs while taking turns.
+type Action_t string
+
+const (
+	ACTION_AID      Action_t = "aid"      // sending foreign aid, no special effects
+	ACTION_ATTACK   Action_t = "attack"   // attacking another empire, no special effects
+	ACTION_BUILD    Action_t = "build"    // constructing buildings, no special effects
+	ACTION_CASH     Action_t = "cash"     // gain 25% more cash than usual
+	ACTION_DEMOLISH Action_t = "demolish" // demolishing buildings, no special effects
+	ACTION_DROPLAND Action_t = "dropland" // dropping unused land, no special effects
+	ACTION_FARM     Action_t = "farm"     // gain 25% more food than usual
+	ACTION_LAND     Action_t = "land"     // explore for more land
+	ACTION_MAGIC    Action_t = "magic"    // casting spells, no special effects
+	ACTION_WAR      Action_t = "war"      // attacking somebody you're at war with, incur 10% increased costs
+)
+
+// Config_t holds the settings from config.php used by the game rules.
+type Config_t struct {
+	BankSaveRate      float64 // Base savings interest rate
+	BankLoanRate      float64 // Base loan interest rate
+	IndustryMult      float64 // Industry output multiplier
+	BuildCost         int     // Base building cost
+	TurnsProtection   int     // Duration of protection
+	ClanEnable        bool    // Master enable for clans
+	MaxAttacks        int     // Maximum number of attacks, zero for no limit
+	LotteryMaxTickets int     // Maximum number of lottery tickets per empire
+	LotteryJackpot    int     // Base jackpot
+	PvtmTrpArm        int     // Base market costs for each unit
+	PvtmTrpLnd        int
+	PvtmTrpFly        int
+	PvtmTrpSea        int
+	PvtmFood          int
+	PvtmMaxSell       int           // Percentage of troops that can be sold on private market (0-10000)
+	PvtmShopBonus     float64       // Percentage of private market cost bonus for which shops are responsible (0-1)
+	PubmktMinSell     int           // Minimum percentage of troops, per shipment, that can be sold on public market (0-100)
+	PubmktMaxSell     int           // Maximum percentage of troops, total, that can be sold on public market (0-100)
+	PubmktMinFood     int           // Same as PubmktMinSell, except for food
+	PubmktMaxFood     int           // Same as PubmktMaxSell, except for food
+	TurnsEra          int           // Minimum number of turns that must be spent in an era before one can advance or regress
+	DropDelay         int           // Minimum delay (in seconds) between gaining land and dropping it
+	FriendMagicEnable bool          // Enable casting spells on friendly empires
+	MagicAllowRegress bool          // Enables usage of the "Regress to Previous Era" spell
+	ScoreEnable       bool          // Enable keeping score for empires attacking each other
+	AidEnable         bool          // Enable sending foreign aid
+	AidMaxCredits     int           // Maximum number of stored foreign aid credits
+	AidDelay          int           // Delay (in seconds) between earning foreign aid credits
+	VacationStart     time.Duration // Delay before an empire on vacation is protected
+}
+
+// Engine_t applies the game rules using a configuration and a random number source.
+// Given the same seed, it produces the same results.
+type Engine_t struct {
+	cfg   Config_t
+	rngMu sync.Mutex // the server's handlers share the engine
+	rng   *rand.Rand
+}
+
+// New returns an engine. If rng is nil, a source seeded with 1 is used.
+func New(cfg Config_t, rng *rand.Rand) *Engine_t {
+	if rng == nil {
+		rng = rand.New(rand.NewSource(1))
+	}
+	return &Engine_t{cfg: cfg, rng: rng}
+}
+
+// randRange returns a random integer in the range [lo, hi], like mt_rand.
+func (e *Engine_t) randRange(lo, hi int) int {
+	if hi <= lo {
+		return lo
+	}
+	e.rngMu.Lock()
+	defer e.rngMu.Unlock()
+	return lo + e.rng.Intn(hi-lo+1)
+}
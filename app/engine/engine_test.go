@@ -0,0 +1,199 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package engine
+
+import (
+	"github.com/mdhender/promisance/app/model"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var testConfig = Config_t{
+	BankSaveRate:      4.0,
+	BankLoanRate:      7.5,
+	IndustryMult:      2.5,
+	TurnsProtection:   200,
+	ClanEnable:        true,
+	PvtmTrpArm:        500,
+	PvtmTrpLnd:        1000,
+	PvtmTrpFly:        2000,
+	PvtmTrpSea:        3000,
+	PvtmFood:          30,
+	PvtmMaxSell:       8000,
+	PvtmShopBonus:     0.20,
+	TurnsEra:          500,
+	LotteryMaxTickets: 3,
+	LotteryJackpot:    1000000,
+	DropDelay:         12 * 60 * 60,
+	AidEnable:         true,
+	AidMaxCredits:     5,
+	AidDelay:          60 * 60,
+	VacationStart:     12 * time.Hour,
+}
+
+// testEmpire returns an empire with the defaults from config.php.
+func testEmpire() model.Empire_t {
+	return model.Empire_t{
+		Id:       1,
+		Turns:    100,
+		Cash:     100000,
+		Food:     10000,
+		Peasants: 500,
+		TrpArm:   100,
+		TrpLnd:   50,
+		TrpFly:   20,
+		TrpSea:   10,
+		Land:     250,
+		BldPop:   20,
+		BldCash:  10,
+		BldCost:  5,
+		BldFood:  15,
+		Freeland: 200,
+		IndArm:   25,
+		IndLnd:   25,
+		IndFly:   25,
+		IndSea:   25,
+		Health:   100,
+		Tax:      10,
+	}
+}
+
+func TestCalculations(t *testing.T) {
+	e := New(testConfig, nil)
+	emp := testEmpire()
+	if got := e.Networth(&emp); got != 147030 {
+		t.Errorf("networth: want 147030, got %d", got)
+	}
+	if got := e.PCI(&emp, Modifiers_t{}); got != 26 {
+		t.Errorf("pci: want 26, got %d", got)
+	}
+	if pro, con := e.Provisions(&emp, Modifiers_t{}); pro != 3246 || con != 12 {
+		t.Errorf("provisions: want 3246/12, got %d/%d", pro, con)
+	}
+	if got := e.GiveLand(&emp, Modifiers_t{}); got != 66 {
+		t.Errorf("giveLand: want 66, got %d", got)
+	}
+	if got := e.GiveLand(&emp, Modifiers_t{Explore: 40}); got != 92 {
+		t.Errorf("giveLand: explore +40: want 92, got %d", got)
+	}
+}
+
+func TestModifiers(t *testing.T) {
+	for _, tc := range []struct {
+		pct       int
+		mod, mod2 float64
+	}{
+		{0, 1, 1},
+		{100, 2, 2},
+		{-100, 0, 0.5},
+		{-200, 0, 1.0 / 3},
+	} {
+		if got := Modifier(tc.pct); got != tc.mod {
+			t.Errorf("modifier(%d): want %v, got %v", tc.pct, tc.mod, got)
+		}
+		if got := Modifier2(tc.pct); got != tc.mod2 {
+			t.Errorf("modifier2(%d): want %v, got %v", tc.pct, tc.mod2, got)
+		}
+	}
+	sum := Modifiers_t{Offense: 10, Magic: -5}.Add(Modifiers_t{Offense: 5}, Modifiers_t{Magic: 20})
+	if sum.Offense != 15 || sum.Magic != 15 {
+		t.Errorf("add: want 15/15, got %d/%d", sum.Offense, sum.Magic)
+	}
+}
+
+func TestTakeTurnsNotEnoughTurns(t *testing.T) {
+	emp := testEmpire()
+	emp.Turns = 5
+	got, report := New(testConfig, nil).TakeTurns(emp, Modifiers_t{}, TakeTurns_t{Turns: 10, Action: ACTION_CASH})
+	if report.Taken != 0 || len(report.Turns) != 0 {
+		t.Errorf("taken: want 0, got %d", report.Taken)
+	}
+	if got != emp {
+		t.Errorf("empire: want unchanged")
+	}
+}
+
+func TestTakeTurnsExplore(t *testing.T) {
+	emp := testEmpire()
+	got, report := New(testConfig, nil).TakeTurns(emp, Modifiers_t{}, TakeTurns_t{Turns: 3, Action: ACTION_LAND})
+	if report.Taken != 3 || report.Trouble != 0 {
+		t.Fatalf("taken: want 3 without trouble, got %d (%d)", report.Taken, report.Trouble)
+	}
+	if got.Land-emp.Land != report.Result || got.Freeland-emp.Freeland != report.Result {
+		t.Errorf("land: result %d, land %d, freeland %d", report.Result, got.Land-emp.Land, got.Freeland-emp.Freeland)
+	}
+	if report.Result < 3*60 {
+		t.Errorf("land: want at least 180 acres, got %d", report.Result)
+	}
+	if got.Turns != 97 || got.TurnsUsed != 3 {
+		t.Errorf("turns: want 97/3, got %d/%d", got.Turns, got.TurnsUsed)
+	}
+	if emp.Land != 250 {
+		t.Errorf("input empire was modified")
+	}
+	var cash int
+	for _, turn := range report.Turns {
+		cash += turn.Money
+	}
+	if cash != report.Overall.Money || got.Cash-emp.Cash != cash {
+		t.Errorf("cash: turns %d, overall %d, empire %d", cash, report.Overall.Money, got.Cash-emp.Cash)
+	}
+}
+
+func TestTakeTurnsFarm(t *testing.T) {
+	emp := testEmpire()
+	got, report := New(testConfig, nil).TakeTurns(emp, Modifiers_t{}, TakeTurns_t{Turns: 1, Action: ACTION_FARM})
+	// 3246 produced with a 25% bonus, less 12 consumed
+	if report.Result != 4046 {
+		t.Errorf("farm: want 4046, got %d", report.Result)
+	}
+	if got.Food != emp.Food+4046 {
+		t.Errorf("food: want %d, got %d", emp.Food+4046, got.Food)
+	}
+}
+
+func TestTakeTurnsStarvation(t *testing.T) {
+	emp := testEmpire()
+	emp.Food, emp.Freeland, emp.BldFood, emp.TrpArm = 0, 0, 0, 100000
+	for _, tc := range []struct {
+		interruptable bool
+		taken         int
+		deserted      int
+	}{
+		{true, 1, 3},
+		{false, 3, 9},
+	} {
+		got, report := New(testConfig, nil).TakeTurns(emp, Modifiers_t{}, TakeTurns_t{Turns: 3, Interruptable: tc.interruptable})
+		if report.Taken != tc.taken || report.Halted != tc.interruptable {
+			t.Errorf("interruptable %v: taken: want %d, got %d", tc.interruptable, tc.taken, report.Taken)
+		}
+		if report.Trouble&TURNS_TROUBLE_FOOD == 0 {
+			t.Errorf("interruptable %v: trouble: want food, got %d", tc.interruptable, report.Trouble)
+		}
+		if report.Deserted != tc.deserted {
+			t.Errorf("interruptable %v: deserted: want %d, got %d", tc.interruptable, tc.deserted, report.Deserted)
+		}
+		if got.Food != 0 || got.TrpArm >= emp.TrpArm {
+			t.Errorf("interruptable %v: food %d, trparm %d", tc.interruptable, got.Food, got.TrpArm)
+		}
+	}
+}
+
+func TestTakeTurnsDeterministic(t *testing.T) {
+	emp := testEmpire()
+	// enough towers to use the random rune production
+	emp.BldWiz, emp.Freeland = 60, 140
+	req := TakeTurns_t{Turns: 20, Action: ACTION_CASH}
+	mods := Modifiers_t{Runepro: 20, Industry: -5}
+
+	got1, report1 := New(testConfig, rand.New(rand.NewSource(42))).TakeTurns(emp, mods, req)
+	got2, report2 := New(testConfig, rand.New(rand.NewSource(42))).TakeTurns(emp, mods, req)
+	if got1 != got2 || !reflect.DeepEqual(report1, report2) {
+		t.Errorf("same seed: want identical results")
+	}
+	if got1.Runes == 0 || got1.TrpWiz == 0 {
+		t.Errorf("runes %d, wizards %d: want both to increase", got1.Runes, got1.TrpWiz)
+	}
+}
@@ -0,0 +1,221 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package engine
+
+import (
+	"github.com/mdhender/promisance/app/model"
+	"time"
+)
+
+// Era identifiers from php/classes/prom_era.php.
+// These must be consecutive and in chronological order.
+const (
+	ERA_PAST    = 1
+	ERA_PRESENT = 2
+	ERA_FUTURE  = 3
+)
+
+// Era_t defines an era: the names of its units, buildings, spells, and
+// effects, the modifiers it grants to empires, and the strength of its units.
+// Names are language keys.
+type Era_t struct {
+	Id          int               `json:"-" yaml:"-"`
+	Name        string            `json:"name" yaml:"name"`
+	Peasants    string            `json:"peasants" yaml:"peasants"`
+	Food        string            `json:"food" yaml:"food"`
+	Runes       string            `json:"runes" yaml:"runes"`
+	TrpArm      string            `json:"trparm" yaml:"trparm"`
+	TrpLnd      string            `json:"trplnd" yaml:"trplnd"`
+	TrpFly      string            `json:"trpfly" yaml:"trpfly"`
+	TrpSea      string            `json:"trpsea" yaml:"trpsea"`
+	TrpWiz      string            `json:"trpwiz" yaml:"trpwiz"`
+	BldPop      string            `json:"bldpop" yaml:"bldpop"`
+	BldCash     string            `json:"bldcash" yaml:"bldcash"`
+	BldTrp      string            `json:"bldtrp" yaml:"bldtrp"`
+	BldCost     string            `json:"bldcost" yaml:"bldcost"`
+	BldWiz      string            `json:"bldwiz" yaml:"bldwiz"`
+	BldFood     string            `json:"bldfood" yaml:"bldfood"`
+	BldDef      string            `json:"blddef" yaml:"blddef"`
+	Spells      map[string]string `json:"spells" yaml:"spells"`             // spell names, keyed by spell
+	EffectNames map[string]string `json:"effect_names" yaml:"effect_names"` // effect names, keyed by effect
+	EffectDescs map[string]string `json:"effect_descs" yaml:"effect_descs"` // effect descriptions, keyed by effect
+	Modifiers   Modifiers_t       `json:"modifiers" yaml:"modifiers"`
+	Offense     Units_t           `json:"offense" yaml:"offense"` // offensive power of each unit
+	Defense     Units_t           `json:"defense" yaml:"defense"` // defensive power of each unit
+	Prev        int               `json:"prev" yaml:"prev"`       // previous era, zero if none
+	Next        int               `json:"next" yaml:"next"`       // next era, zero if none
+}
+
+// Units_t holds a value for each type of military unit.
+type Units_t struct {
+	TrpArm int `json:"trparm" yaml:"trparm"`
+	TrpLnd int `json:"trplnd" yaml:"trplnd"`
+	TrpFly int `json:"trpfly" yaml:"trpfly"`
+	TrpSea int `json:"trpsea" yaml:"trpsea"`
+}
+
+// defaultEras returns the eras from php/classes/prom_era.php.
+func defaultEras() map[int]*Era_t {
+	return map[int]*Era_t{
+		ERA_PAST: {
+			Name:     "ERA_PAST_NAME",
+			Peasants: "ERA_PAST_PEASANTS",
+			Food:     "ERA_PAST_FOOD",
+			Runes:    "ERA_PAST_RUNES",
+			TrpArm:   "ERA_PAST_TRPARM",
+			TrpLnd:   "ERA_PAST_TRPLND",
+			TrpFly:   "ERA_PAST_TRPFLY",
+			TrpSea:   "ERA_PAST_TRPSEA",
+			TrpWiz:   "ERA_PAST_TRPWIZ",
+			BldPop:   "ERA_PAST_BLDPOP",
+			BldCash:  "ERA_PAST_BLDCASH",
+			BldTrp:   "ERA_PAST_BLDTRP",
+			BldCost:  "ERA_PAST_BLDCOST",
+			BldWiz:   "ERA_PAST_BLDWIZ",
+			BldFood:  "ERA_PAST_BLDFOOD",
+			BldDef:   "ERA_PAST_BLDDEF",
+			Spells: map[string]string{
+				"spy":     "ERA_PAST_SPELL_SPY",
+				"blast":   "ERA_PAST_SPELL_BLAST",
+				"shield":  "ERA_PAST_SPELL_SHIELD",
+				"storm":   "ERA_PAST_SPELL_STORM",
+				"runes":   "ERA_PAST_SPELL_RUNES",
+				"struct":  "ERA_PAST_SPELL_STRUCT",
+				"food":    "ERA_PAST_SPELL_FOOD",
+				"cash":    "ERA_PAST_SPELL_CASH",
+				"gate":    "ERA_PAST_SPELL_GATE",
+				"ungate":  "ERA_PAST_SPELL_UNGATE",
+				"fight":   "ERA_PAST_SPELL_FIGHT",
+				"steal":   "ERA_PAST_SPELL_STEAL",
+				"advance": "ERA_PAST_SPELL_ADVANCE",
+				"regress": "ERA_PAST_SPELL_REGRESS",
+			},
+			EffectNames: map[string]string{
+				"shield": "ERA_PAST_EFFECT_NAME_SHIELD",
+				"gate":   "ERA_PAST_EFFECT_NAME_GATE",
+			},
+			EffectDescs: map[string]string{
+				"shield": "ERA_PAST_EFFECT_DESC_SHIELD",
+			},
+			Modifiers: Modifiers_t{
+				Explore:  0,
+				Industry: -5,
+				Runepro:  20,
+			},
+			Offense: Units_t{TrpArm: 1, TrpLnd: 3, TrpFly: 7, TrpSea: 7},
+			Defense: Units_t{TrpArm: 2, TrpLnd: 2, TrpFly: 5, TrpSea: 6},
+			Prev:    0,
+			Next:    ERA_PRESENT,
+		},
+		ERA_PRESENT: {
+			Name:     "ERA_PRESENT_NAME",
+			Peasants: "ERA_PRESENT_PEASANTS",
+			Food:     "ERA_PRESENT_FOOD",
+			Runes:    "ERA_PRESENT_RUNES",
+			TrpArm:   "ERA_PRESENT_TRPARM",
+			TrpLnd:   "ERA_PRESENT_TRPLND",
+			TrpFly:   "ERA_PRESENT_TRPFLY",
+			TrpSea:   "ERA_PRESENT_TRPSEA",
+			TrpWiz:   "ERA_PRESENT_TRPWIZ",
+			BldPop:   "ERA_PRESENT_BLDPOP",
+			BldCash:  "ERA_PRESENT_BLDCASH",
+			BldTrp:   "ERA_PRESENT_BLDTRP",
+			BldCost:  "ERA_PRESENT_BLDCOST",
+			BldWiz:   "ERA_PRESENT_BLDWIZ",
+			BldFood:  "ERA_PRESENT_BLDFOOD",
+			BldDef:   "ERA_PRESENT_BLDDEF",
+			Spells: map[string]string{
+				"spy":     "ERA_PRESENT_SPELL_SPY",
+				"blast":   "ERA_PRESENT_SPELL_BLAST",
+				"shield":  "ERA_PRESENT_SPELL_SHIELD",
+				"storm":   "ERA_PRESENT_SPELL_STORM",
+				"runes":   "ERA_PRESENT_SPELL_RUNES",
+				"struct":  "ERA_PRESENT_SPELL_STRUCT",
+				"food":    "ERA_PRESENT_SPELL_FOOD",
+				"cash":    "ERA_PRESENT_SPELL_CASH",
+				"gate":    "ERA_PRESENT_SPELL_GATE",
+				"ungate":  "ERA_PRESENT_SPELL_UNGATE",
+				"fight":   "ERA_PRESENT_SPELL_FIGHT",
+				"steal":   "ERA_PRESENT_SPELL_STEAL",
+				"advance": "ERA_PRESENT_SPELL_ADVANCE",
+				"regress": "ERA_PRESENT_SPELL_REGRESS",
+			},
+			EffectNames: map[string]string{
+				"shield": "ERA_PRESENT_EFFECT_NAME_SHIELD",
+				"gate":   "ERA_PRESENT_EFFECT_NAME_GATE",
+			},
+			EffectDescs: map[string]string{
+				"shield": "ERA_PRESENT_EFFECT_DESC_SHIELD",
+			},
+			Modifiers: Modifiers_t{
+				Explore:  40,
+				Industry: 0,
+				Runepro:  0,
+			},
+			Offense: Units_t{TrpArm: 2, TrpLnd: 2, TrpFly: 5, TrpSea: 6},
+			Defense: Units_t{TrpArm: 1, TrpLnd: 6, TrpFly: 3, TrpSea: 8},
+			Prev:    ERA_PAST,
+			Next:    ERA_FUTURE,
+		},
+		ERA_FUTURE: {
+			Name:     "ERA_FUTURE_NAME",
+			Peasants: "ERA_FUTURE_PEASANTS",
+			Food:     "ERA_FUTURE_FOOD",
+			Runes:    "ERA_FUTURE_RUNES",
+			TrpArm:   "ERA_FUTURE_TRPARM",
+			TrpLnd:   "ERA_FUTURE_TRPLND",
+			TrpFly:   "ERA_FUTURE_TRPFLY",
+			TrpSea:   "ERA_FUTURE_TRPSEA",
+			TrpWiz:   "ERA_FUTURE_TRPWIZ",
+			BldPop:   "ERA_FUTURE_BLDPOP",
+			BldCash:  "ERA_FUTURE_BLDCASH",
+			BldTrp:   "ERA_FUTURE_BLDTRP",
+			BldCost:  "ERA_FUTURE_BLDCOST",
+			BldWiz:   "ERA_FUTURE_BLDWIZ",
+			BldFood:  "ERA_FUTURE_BLDFOOD",
+			BldDef:   "ERA_FUTURE_BLDDEF",
+			Spells: map[string]string{
+				"spy":     "ERA_FUTURE_SPELL_SPY",
+				"blast":   "ERA_FUTURE_SPELL_BLAST",
+				"shield":  "ERA_FUTURE_SPELL_SHIELD",
+				"storm":   "ERA_FUTURE_SPELL_STORM",
+				"runes":   "ERA_FUTURE_SPELL_RUNES",
+				"struct":  "ERA_FUTURE_SPELL_STRUCT",
+				"food":    "ERA_FUTURE_SPELL_FOOD",
+				"cash":    "ERA_FUTURE_SPELL_CASH",
+				"gate":    "ERA_FUTURE_SPELL_GATE",
+				"ungate":  "ERA_FUTURE_SPELL_UNGATE",
+				"fight":   "ERA_FUTURE_SPELL_FIGHT",
+				"steal":   "ERA_FUTURE_SPELL_STEAL",
+				"advance": "ERA_FUTURE_SPELL_ADVANCE",
+				"regress": "ERA_FUTURE_SPELL_REGRESS",
+			},
+			EffectNames: map[string]string{
+				"shield": "ERA_FUTURE_EFFECT_NAME_SHIELD",
+				"gate":   "ERA_FUTURE_EFFECT_NAME_GATE",
+			},
+			EffectDescs: map[string]string{
+				"shield": "ERA_FUTURE_EFFECT_DESC_SHIELD",
+			},
+			Modifiers: Modifiers_t{
+				Explore:  80,
+				Industry: 15,
+				Runepro:  0,
+			},
+			Offense: Units_t{TrpArm: 1, TrpLnd: 5, TrpFly: 6, TrpSea: 7},
+			Defense: Units_t{TrpArm: 2, TrpLnd: 2, TrpFly: 3, TrpSea: 7},
+			Prev:    ERA_PRESENT,
+			Next:    0,
+		},
+	}
+}
+
+// EraReachable returns true if the two empires may interact with each other.
+// Empires in different eras may only interact while either one has a time gate open.
+// The target's effects may be nil.
+func (e *Engine_t) EraReachable(src, dst *model.Empire_t, srcFx, dstFx *Effects_t, now time.Time) bool {
+	if src.Era == dst.Era {
+		return true
+	}
+	return (srcFx != nil && srcFx.Active("m_gate", now)) || (dstFx != nil && dstFx.Active("m_gate", now))
+}
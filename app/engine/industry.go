@@ -0,0 +1,56 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package engine
+
+import (
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/model"
+	"math"
+)
+
+// Industry returns the empire's share of industry, as a percentage, allocated to each type of unit.
+func (e *Engine_t) Industry(emp *model.Empire_t) Units_t {
+	return Units_t{TrpArm: emp.IndArm, TrpLnd: emp.IndLnd, TrpFly: emp.IndFly, TrpSea: emp.IndSea}
+}
+
+// Production returns the units the empire's barracks produce each turn.
+// The modifiers should include the empire's race and era.
+func (e *Engine_t) Production(emp *model.Empire_t, mods Modifiers_t) Units_t {
+	industry := Modifier(mods.Industry) * e.cfg.IndustryMult
+	return Units_t{
+		TrpArm: int(math.Ceil(float64(emp.BldTrp) * (float64(emp.IndArm) / 100) * 1.2 * industry)),
+		TrpLnd: int(math.Ceil(float64(emp.BldTrp) * (float64(emp.IndLnd) / 100) * 0.6 * industry)),
+		TrpFly: int(math.Ceil(float64(emp.BldTrp) * (float64(emp.IndFly) / 100) * 0.3 * industry)),
+		TrpSea: int(math.Ceil(float64(emp.BldTrp) * (float64(emp.IndSea) / 100) * 0.2 * industry)),
+	}
+}
+
+// SetIndustry allocates the empire's industry, from php/pages/manage/empire.php.
+// The percentages may not total more than 100; any remainder is left idle.
+// It returns the updated empire or an error if the allocation is not valid.
+func (e *Engine_t) SetIndustry(emp model.Empire_t, alloc Units_t) (model.Empire_t, error) {
+	if alloc.TrpArm < 0 || alloc.TrpLnd < 0 || alloc.TrpFly < 0 || alloc.TrpSea < 0 {
+		return emp, cerr.ErrNeedInput
+	} else if alloc.total() > 100 {
+		return emp, cerr.ErrIndustryTooHigh
+	}
+	emp.IndArm, emp.IndLnd, emp.IndFly, emp.IndSea = alloc.TrpArm, alloc.TrpLnd, alloc.TrpFly, alloc.TrpSea
+	return emp, nil
+}
+
+// Release discharges military units, who return home as peasants.
+// It returns the updated empire or an error if the empire does not have the units.
+func (e *Engine_t) Release(emp model.Empire_t, units Units_t) (model.Empire_t, error) {
+	if units.TrpArm < 0 || units.TrpLnd < 0 || units.TrpFly < 0 || units.TrpSea < 0 || units.total() == 0 {
+		return emp, cerr.ErrNeedInput
+	} else if units.TrpArm > emp.TrpArm || units.TrpLnd > emp.TrpLnd || units.TrpFly > emp.TrpFly || units.TrpSea > emp.TrpSea {
+		return emp, cerr.ErrReleaseTooMany
+	}
+	emp.TrpArm -= units.TrpArm
+	emp.TrpLnd -= units.TrpLnd
+	emp.TrpFly -= units.TrpFly
+	emp.TrpSea -= units.TrpSea
+	emp.Peasants += units.total()
+	emp.NetWorth = e.Networth(&emp)
+	return emp, nil
+}
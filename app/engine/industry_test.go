@@ -0,0 +1,62 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package engine
+
+import (
+	"errors"
+	"github.com/mdhender/promisance/app/cerr"
+	"testing"
+)
+
+func TestProduction(t *testing.T) {
+	e := New(testConfig, nil)
+	emp := testEmpire()
+	emp.BldTrp = 40
+	if got, want := e.Production(&emp, Modifiers_t{}), (Units_t{TrpArm: 30, TrpLnd: 15, TrpFly: 8, TrpSea: 5}); got != want {
+		t.Errorf("production: want %+v, got %+v", want, got)
+	}
+	if got, want := e.Production(&emp, Modifiers_t{Industry: 12}), (Units_t{TrpArm: 34, TrpLnd: 17, TrpFly: 9, TrpSea: 6}); got != want {
+		t.Errorf("production: industry +12: want %+v, got %+v", want, got)
+	}
+
+	emp.IndArm, emp.IndLnd, emp.IndFly, emp.IndSea = 100, 0, 0, 0
+	if got, want := e.Production(&emp, Modifiers_t{}), (Units_t{TrpArm: 120}); got != want {
+		t.Errorf("production: all arm: want %+v, got %+v", want, got)
+	}
+}
+
+func TestSetIndustry(t *testing.T) {
+	e := New(testConfig, nil)
+	emp := testEmpire()
+	if _, err := e.SetIndustry(emp, Units_t{TrpArm: 50, TrpLnd: 30, TrpFly: 20, TrpSea: 1}); !errors.Is(err, cerr.ErrIndustryTooHigh) {
+		t.Errorf("too high: want %v, got %v", cerr.ErrIndustryTooHigh, err)
+	}
+	if _, err := e.SetIndustry(emp, Units_t{TrpArm: -10, TrpLnd: 110}); !errors.Is(err, cerr.ErrNeedInput) {
+		t.Errorf("negative: want %v, got %v", cerr.ErrNeedInput, err)
+	}
+	got, err := e.SetIndustry(emp, Units_t{TrpArm: 50, TrpLnd: 30, TrpSea: 10})
+	if err != nil {
+		t.Fatalf("set: %v", err)
+	} else if e.Industry(&got) != (Units_t{TrpArm: 50, TrpLnd: 30, TrpSea: 10}) {
+		t.Errorf("set: got %+v", e.Industry(&got))
+	}
+}
+
+func TestRelease(t *testing.T) {
+	e := New(testConfig, nil)
+	emp := testEmpire()
+	if _, err := e.Release(emp, Units_t{}); !errors.Is(err, cerr.ErrNeedInput) {
+		t.Errorf("nothing: want %v, got %v", cerr.ErrNeedInput, err)
+	}
+	if _, err := e.Release(emp, Units_t{TrpSea: 11}); !errors.Is(err, cerr.ErrReleaseTooMany) {
+		t.Errorf("too many: want %v, got %v", cerr.ErrReleaseTooMany, err)
+	}
+	got, err := e.Release(emp, Units_t{TrpArm: 40, TrpSea: 10})
+	if err != nil {
+		t.Fatalf("release: %v", err)
+	} else if got.TrpArm != 60 || got.TrpSea != 0 || got.Peasants != 550 {
+		t.Errorf("release: got arm %d, sea %d, peasants %d", got.TrpArm, got.TrpSea, got.Peasants)
+	} else if got.NetWorth != e.Networth(&got) {
+		t.Errorf("release: networth not updated")
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package engine
+
+import (
+	"github.com/mdhender/promisance/app/model"
+	"math"
+)
+
+// LotteryMaxTickets returns the number of lottery tickets an empire may hold for each drawing.
+func (e *Engine_t) LotteryMaxTickets() int {
+	return e.cfg.LotteryMaxTickets
+}
+
+// LotteryTicketCost returns the price of the empire's next lottery ticket,
+// from php/pages/lottery.php. Larger empires pay more.
+func (e *Engine_t) LotteryTicketCost(emp *model.Empire_t) int {
+	networth := float64(max(emp.NetWorth, 2)) // the logarithm must not be zero
+	return round(networth / (math.Log(networth) / math.Log(25)))
+}
+
+// LotteryNumber returns a random ticket number. There are enough numbers
+// for every empire to hold the maximum number of tickets.
+func (e *Engine_t) LotteryNumber(empires int) int {
+	return e.randRange(1, e.cfg.LotteryMaxTickets*max(empires, 1))
+}
+
+// LotteryRollover updates the lottery variables after a drawing, from prom_turns::lottery.
+// The winner is the empire holding the picked ticket, or zero if no one held it.
+// If there was a winner, the jackpot is reset to its base amount.
+// It returns the amount won, which is zero if there was no winner.
+func (e *Engine_t) LotteryRollover(world *model.World_t, picked, winner int) int {
+	jackpot, lastJackpot := world.LottoCurrentJackpot, world.LottoYesterdayJackpot
+	// if the minimum jackpot gets increased, apply it now
+	if lastJackpot > jackpot {
+		lastJackpot = e.cfg.LotteryJackpot
+	}
+	world.LottoLastPicked = picked
+	world.LottoYesterdayJackpot = jackpot
+	world.LottoJackpotIncrease = jackpot - lastJackpot
+	world.LottoLastWinner = winner
+	if winner == 0 {
+		return 0
+	}
+	world.LottoCurrentJackpot = e.cfg.LotteryJackpot
+	return jackpot
+}
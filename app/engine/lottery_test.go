@@ -0,0 +1,55 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package engine
+
+import (
+	"github.com/mdhender/promisance/app/model"
+	"testing"
+)
+
+func TestLotteryTicketCost(t *testing.T) {
+	e := New(testConfig, nil)
+	for _, tc := range []struct {
+		networth int
+		cost     int
+	}{
+		{0, 9},
+		{2, 9},
+		{200, 122},
+		{100000, 27959},
+	} {
+		emp := testEmpire()
+		emp.NetWorth = tc.networth
+		if got := e.LotteryTicketCost(&emp); got != tc.cost {
+			t.Errorf("networth %d: want %d, got %d", tc.networth, tc.cost, got)
+		}
+	}
+}
+
+func TestLotteryRollover(t *testing.T) {
+	e := New(testConfig, nil)
+	for _, tc := range []struct {
+		name     string
+		world    model.World_t
+		winner   int
+		won      int
+		jackpot  int
+		increase int
+	}{
+		{"no winner", model.World_t{LottoCurrentJackpot: 1250000, LottoYesterdayJackpot: 1100000}, 0, 0, 1250000, 150000},
+		{"winner", model.World_t{LottoCurrentJackpot: 1250000, LottoYesterdayJackpot: 1100000}, 7, 1250000, 1000000, 150000},
+		// the jackpot was reset by the previous winner
+		{"after winner", model.World_t{LottoCurrentJackpot: 1000500, LottoYesterdayJackpot: 1250000}, 0, 0, 1000500, 500},
+	} {
+		world := tc.world
+		if won := e.LotteryRollover(&world, 42, tc.winner); won != tc.won {
+			t.Errorf("%s: won: want %d, got %d", tc.name, tc.won, won)
+		}
+		if world.LottoCurrentJackpot != tc.jackpot || world.LottoJackpotIncrease != tc.increase {
+			t.Errorf("%s: jackpot: want %d, %d, got %d, %d", tc.name, tc.jackpot, tc.increase, world.LottoCurrentJackpot, world.LottoJackpotIncrease)
+		}
+		if world.LottoLastPicked != 42 || world.LottoLastWinner != tc.winner || world.LottoYesterdayJackpot != tc.world.LottoCurrentJackpot {
+			t.Errorf("%s: last: want 42, %d, %d, got %d, %d, %d", tc.name, tc.winner, tc.world.LottoCurrentJackpot, world.LottoLastPicked, world.LottoLastWinner, world.LottoYesterdayJackpot)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package engine
+
+import (
+	"github.com/mdhender/promisance/app/model"
+	"math"
+)
+
+// Good_t is a type of goods traded on the markets.
+// The values are stored in the market table, from php/includes/constants.php.
+type Good_t int
+
+const (
+	MARKET_TRPARM Good_t = 0
+	MARKET_TRPLND Good_t = 1
+	MARKET_TRPFLY Good_t = 2
+	MARKET_TRPSEA Good_t = 3
+	MARKET_FOOD   Good_t = 4
+)
+
+// PUBMKT_TAX is the share of every public market sale that goes into the lottery jackpot.
+const PUBMKT_TAX = 0.05
+
+// Goods returns the types of goods in the order they are shown to players.
+func Goods() []Good_t {
+	return []Good_t{MARKET_TRPARM, MARKET_TRPLND, MARKET_TRPFLY, MARKET_TRPSEA, MARKET_FOOD}
+}
+
+// marketGood describes one type of goods.
+type marketGood struct {
+	name string
+	emp  func(*model.Empire_t) *int
+	era  func(*Era_t) string
+}
+
+var marketGoods = map[Good_t]marketGood{
+	MARKET_TRPARM: {"trparm", func(e *model.Empire_t) *int { return &e.TrpArm }, func(era *Era_t) string { return era.TrpArm }},
+	MARKET_TRPLND: {"trplnd", func(e *model.Empire_t) *int { return &e.TrpLnd }, func(era *Era_t) string { return era.TrpLnd }},
+	MARKET_TRPFLY: {"trpfly", func(e *model.Empire_t) *int { return &e.TrpFly }, func(era *Era_t) string { return era.TrpFly }},
+	MARKET_TRPSEA: {"trpsea", func(e *model.Empire_t) *int { return &e.TrpSea }, func(era *Era_t) string { return era.TrpSea }},
+	MARKET_FOOD:   {"food", func(e *model.Empire_t) *int { return &e.Food }, func(era *Era_t) string { return era.Food }},
+}
+
+// IsValid returns true if the goods can be traded on the markets.
+func (g Good_t) IsValid() bool {
+	_, ok := marketGoods[g]
+	return ok
+}
+
+// String implements the Stringer interface.
+func (g Good_t) String() string {
+	if mg, ok := marketGoods[g]; ok {
+		return mg.name
+	}
+	return "unknown"
+}
+
+// Owned returns the number of goods held by the empire.
+func (g Good_t) Owned(emp *model.Empire_t) int {
+	return *marketGoods[g].emp(emp)
+}
+
+// Give adds goods to the empire. Use a negative amount to take them away.
+func (g Good_t) Give(emp *model.Empire_t, amount int) {
+	*marketGoods[g].emp(emp) += amount
+}
+
+// Name returns the language key for the goods in the given era.
+func (g Good_t) Name(era *Era_t) string {
+	return marketGoods[g].era(era)
+}
+
+// MarketCost returns the base market cost of one unit of the goods.
+func (e *Engine_t) MarketCost(g Good_t) int {
+	switch g {
+	case MARKET_TRPARM:
+		return e.cfg.PvtmTrpArm
+	case MARKET_TRPLND:
+		return e.cfg.PvtmTrpLnd
+	case MARKET_TRPFLY:
+		return e.cfg.PvtmTrpFly
+	case MARKET_TRPSEA:
+		return e.cfg.PvtmTrpSea
+	case MARKET_FOOD:
+		return e.cfg.PvtmFood
+	}
+	return 0
+}
+
+// MarketPriceLimits returns the lowest and highest prices the goods can be listed for on the public market.
+func (e *Engine_t) MarketPriceLimits(g Good_t) (minPrice, maxPrice int) {
+	cost := float64(e.MarketCost(g))
+	return int(math.Ceil(cost * 0.2)), int(math.Floor(cost * 2.5))
+}
+
+// MarketSellLimits returns the fewest goods the empire can list in a single
+// shipment and the most it can add to the public market, given the number
+// it already has on sale.
+func (e *Engine_t) MarketSellLimits(emp *model.Empire_t, g Good_t, onSale int) (minSell, maxSell int) {
+	minPct, maxPct := e.cfg.PubmktMinSell, e.cfg.PubmktMaxSell
+	if g == MARKET_FOOD {
+		minPct, maxPct = e.cfg.PubmktMinFood, e.cfg.PubmktMaxFood
+	}
+	total := float64(g.Owned(emp) + onSale)
+	minSell = max(int(math.Round(total*float64(minPct)/100)), 0)
+	maxSell = max(int(math.Round(total*float64(maxPct)/100))-onSale, 0)
+	return minSell, maxSell
+}
+
+// MarketReturn returns the number of unsold goods that make it back to the
+// seller when they expire from the public market. Overpriced goods are more
+// likely to be lost. Lost goods fund the jackpot at 20% of their base value.
+func (e *Engine_t) MarketReturn(g Good_t, amount, price int) (returned, jackpot int) {
+	cost := float64(max(e.MarketCost(g), 1))
+	lost := int(math.Floor(float64(amount) * (min(max(float64(price)-cost, 0)/cost, 0.3) + 0.2)))
+	return amount - lost, int(math.Round(float64(lost) * cost / 5))
+}
+
+// MarketRemove returns the number of goods that make it back to the seller
+// when they are removed from the public market by hand.
+// Lost goods fund the jackpot at 20% of their base value.
+func (e *Engine_t) MarketRemove(g Good_t, amount int) (returned, jackpot int) {
+	lost := int(math.Floor(float64(amount) * 0.2))
+	return amount - lost, int(math.Round(float64(lost) * float64(e.MarketCost(g)) / 5))
+}
@@ -0,0 +1,545 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package engine
+
+import (
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/model"
+	"math"
+	"time"
+)
+
+// AttackType_t is the type of attack launched on another empire.
+// Unit attacks are restricted to the unit type with the same name.
+type AttackType_t string
+
+const (
+	ATTACK_STANDARD AttackType_t = "standard"
+	ATTACK_SURPRISE AttackType_t = "surprise"
+	ATTACK_TRPARM   AttackType_t = "trparm"
+	ATTACK_TRPLND   AttackType_t = "trplnd"
+	ATTACK_TRPFLY   AttackType_t = "trpfly"
+	ATTACK_TRPSEA   AttackType_t = "trpsea"
+)
+
+// AttackTypes returns the attack types in the order they are shown to players.
+func AttackTypes() []AttackType_t {
+	return []AttackType_t{ATTACK_STANDARD, ATTACK_SURPRISE, ATTACK_TRPARM, ATTACK_TRPLND, ATTACK_TRPFLY, ATTACK_TRPSEA}
+}
+
+// total returns the number of units.
+func (u Units_t) total() int {
+	return u.TrpArm + u.TrpLnd + u.TrpFly + u.TrpSea
+}
+
+// militaryUnit describes one type of military unit.
+type militaryUnit struct {
+	kind   AttackType_t // unit attack using only this type of unit
+	emp    func(*model.Empire_t) *int
+	units  func(*Units_t) *int
+	oper   float64 // base attacker losses
+	dper   float64 // base defender losses
+	event  int     // news event for unit attacks
+	single float64 // base attacker losses for unit attacks
+	dsingl float64 // base defender losses for unit attacks
+}
+
+var militaryUnits = []militaryUnit{
+	{ATTACK_TRPARM, func(e *model.Empire_t) *int { return &e.TrpArm }, func(u *Units_t) *int { return &u.TrpArm }, 0.1455, 0.0805, EMPNEWS_MILITARY_ARM, 0.1155, 0.0705},
+	{ATTACK_TRPLND, func(e *model.Empire_t) *int { return &e.TrpLnd }, func(u *Units_t) *int { return &u.TrpLnd }, 0.1285, 0.0730, EMPNEWS_MILITARY_LND, 0.0985, 0.0530},
+	{ATTACK_TRPFLY, func(e *model.Empire_t) *int { return &e.TrpFly }, func(u *Units_t) *int { return &u.TrpFly }, 0.0788, 0.0675, EMPNEWS_MILITARY_FLY, 0.0688, 0.0445},
+	{ATTACK_TRPSEA, func(e *model.Empire_t) *int { return &e.TrpSea }, func(u *Units_t) *int { return &u.TrpSea }, 0.0650, 0.0555, EMPNEWS_MILITARY_SEA, 0.0450, 0.0355},
+}
+
+// Buildings_t holds a count for each type of building, plus unused land.
+type Buildings_t struct {
+	BldCash  int
+	BldPop   int
+	BldTrp   int
+	BldCost  int
+	BldFood  int
+	BldWiz   int
+	BldDef   int
+	Freeland int
+}
+
+// total returns the number of buildings plus unused land.
+func (b Buildings_t) total() int {
+	return b.BldCash + b.BldPop + b.BldTrp + b.BldCost + b.BldFood + b.BldWiz + b.BldDef + b.Freeland
+}
+
+// attackBuildings lists the buildings destroyed or captured by a successful attack,
+// with the base percentage lost by the defender and gained by the attacker.
+var attackBuildings = []struct {
+	emp    func(*model.Empire_t) *int
+	bld    func(*Buildings_t) *int
+	pcloss float64
+	pcgain float64
+	tower  bool // more likely to be destroyed by land and sea attacks
+}{
+	{func(e *model.Empire_t) *int { return &e.BldCash }, func(b *Buildings_t) *int { return &b.BldCash }, 0.07, 0.70, false},
+	{func(e *model.Empire_t) *int { return &e.BldPop }, func(b *Buildings_t) *int { return &b.BldPop }, 0.07, 0.70, false},
+	{func(e *model.Empire_t) *int { return &e.BldTrp }, func(b *Buildings_t) *int { return &b.BldTrp }, 0.07, 0.50, false},
+	{func(e *model.Empire_t) *int { return &e.BldCost }, func(b *Buildings_t) *int { return &b.BldCost }, 0.07, 0.70, false},
+	{func(e *model.Empire_t) *int { return &e.BldFood }, func(b *Buildings_t) *int { return &b.BldFood }, 0.07, 0.30, false},
+	{func(e *model.Empire_t) *int { return &e.BldWiz }, func(b *Buildings_t) *int { return &b.BldWiz }, 0.07, 0.60, true},
+	// towers more likely to be taken, since they are encountered first
+	{func(e *model.Empire_t) *int { return &e.BldDef }, func(b *Buildings_t) *int { return &b.BldDef }, 0.11, 0.60, true},
+	// the gain MUST be 0 (for Standard attacks)
+	{func(e *model.Empire_t) *int { return &e.Freeland }, func(b *Buildings_t) *int { return &b.Freeland }, 0.10, 0.00, false},
+}
+
+// Ally_t is a member of the defender's clan who shares their forces.
+type Ally_t struct {
+	Empire  model.Empire_t
+	Effects *Effects_t // may be nil
+}
+
+// Attack_t describes an attack on another empire.
+type Attack_t struct {
+	Type            AttackType_t
+	Units           Units_t  // units to send, capped at the units available
+	SendAll         bool     // send every available unit instead
+	War             bool     // the defender's clan is at war with the attacker's clan
+	Allies          []Ally_t // the defender's clanmates who may send reinforcements
+	Tables          *Tables_t
+	AttackerEffects *Effects_t        // must not be nil
+	DefenderEffects *Effects_t        // may be nil
+	Round           model.RoundData_t // passed on when taking turns
+	Wars            int               // passed on when taking turns
+	Now             time.Time
+}
+
+// AttackResult_t is the result of an attack.
+type AttackResult_t struct {
+	Type         AttackType_t
+	Report       *Report_t // from taking turns before the attack
+	Aborted      bool      // turns were interrupted by trouble, so the attack was not made
+	Won          bool
+	Killed       bool    // the defender was destroyed
+	Deserted     Units_t // attacker's units lost to desertion before the attack
+	Attacking    Units_t // units sent by the attacker
+	Defending    Units_t // units defending
+	OffPower     float64
+	DefPower     float64
+	Allies       int // number of clanmates who sent reinforcements
+	AttackLosses Units_t
+	DefendLosses Units_t
+	BuildLoss    Buildings_t // buildings and land lost by the defender
+	BuildGain    Buildings_t // buildings and unused land gained by the attacker
+	Land         int         // acres of land captured
+	Points       int         // score points gained, negative if lost
+	Messages     []Message_t
+	News         []News_t
+}
+
+// Attack has the attacker attack the defender and returns the updated empires.
+// The empires passed in are not modified, but the effects are.
+//
+// This covers everything on the military page after the target has been
+// checked. An attack on an empire in another era requires a time gate, from
+// either side. Callers are responsible for making sure the defender is a legal
+// target (alive, not protected, not in the same clan or an allied clan, and so
+// on) and for supplying the war flag and any clanmates able to reinforce the
+// defender.
+// Messages with unit losses pass a Units_t, which the caller formats as a list.
+func (e *Engine_t) Attack(att, def model.Empire_t, req Attack_t) (model.Empire_t, model.Empire_t, *AttackResult_t, error) {
+	var units []militaryUnit
+	switch req.Type {
+	case ATTACK_STANDARD, ATTACK_SURPRISE:
+		units = militaryUnits
+	default:
+		// unit attacks only send units of the matching type
+		for _, u := range militaryUnits {
+			if u.kind == req.Type {
+				units = append(units, u)
+			}
+		}
+		if units == nil {
+			return att, def, nil, cerr.ErrUnknownAttack
+		}
+	}
+	if att.Turns < 2 {
+		return att, def, nil, cerr.ErrNeedTurns
+	} else if att.Health <= 10 {
+		return att, def, nil, cerr.ErrNeedHealth
+	}
+	for _, emp := range []*model.Empire_t{&att, &def} {
+		if _, err := req.Tables.Era(emp.Era); err != nil {
+			return att, def, nil, err
+		}
+	}
+	if !e.EraReachable(&att, &def, req.AttackerEffects, req.DefenderEffects, req.Now) {
+		return att, def, nil, cerr.ErrNeedGate
+	}
+
+	netmultRefuse, netmultDesert := 50.0, 5.0
+	if e.cfg.ClanEnable {
+		netmultRefuse, netmultDesert = 20, 2.5
+	}
+	if req.War {
+		netmultRefuse = 50
+	}
+	// deleted empires cannot defend themselves
+	alive := !def.Flags.Delete && def.NetWorth != 0
+	if alive && e.cfg.ClanEnable && float64(def.NetWorth) < float64(att.NetWorth)/netmultRefuse {
+		return att, def, nil, cerr.ErrTargetTooSmall
+	} else if alive && float64(def.NetWorth) > float64(att.NetWorth)*netmultRefuse {
+		return att, def, nil, cerr.ErrTargetTooLarge
+	}
+
+	res := &AttackResult_t{Type: req.Type}
+	for _, u := range units {
+		send := *u.emp(&att)
+		if !req.SendAll {
+			// if not opting to send all units, just cap at max unit count
+			send = max(min(send, *u.units(&req.Units)), 0)
+		}
+		*u.units(&res.Attacking) = send
+		// shared forces cannot be used for defense, though they CAN be used for offense
+		defend := *u.emp(&def)
+		if e.cfg.ClanEnable && def.Sharing != 0 {
+			defend = ceilPct(defend, 0.9)
+		}
+		// if target is dead, they have no units to defend with
+		if !alive {
+			defend = 0
+		}
+		*u.units(&res.Defending) = defend
+	}
+	if res.Attacking.total() == 0 {
+		return att, def, nil, cerr.ErrNeedUnits
+	}
+
+	if !req.War && alive {
+		if e.cfg.MaxAttacks > 0 && att.Attacks >= 2*e.cfg.MaxAttacks {
+			return att, def, nil, cerr.ErrAttackLimit
+		}
+		var revolt float64
+		if float64(def.NetWorth) < float64(att.NetWorth)/netmultDesert {
+			// shame is less powerful than fear
+			res.Messages = append(res.Messages, Message_t{Key: "MILITARY_DESERT_SMALL"})
+			revolt = float64(att.NetWorth) / float64(def.NetWorth) / 125
+		}
+		if float64(def.NetWorth) > float64(att.NetWorth)*netmultDesert {
+			res.Messages = append(res.Messages, Message_t{Key: "MILITARY_DESERT_LARGE"})
+			revolt = float64(def.NetWorth) / float64(max(att.NetWorth, 1)) / 100
+		}
+		// half losses if no clans
+		if !e.cfg.ClanEnable {
+			revolt *= 0.5
+		}
+		// limit to 10% loss
+		revolt = math.Min(revolt, 0.1)
+		for _, u := range militaryUnits {
+			loss := ceilPct(*u.emp(&att), revolt)
+			*u.emp(&att) -= loss
+			*u.units(&res.Deserted) = loss
+			// if your military deserts, they won't be participating in the attack
+			if sent := u.units(&res.Attacking); *sent > *u.emp(&att) {
+				*sent = *u.emp(&att)
+			}
+		}
+	}
+
+	action := ACTION_ATTACK
+	if req.War {
+		action = ACTION_WAR
+	}
+	att, res.Report = e.TakeTurns(att, req.Tables.Modifiers(&att), TakeTurns_t{
+		Turns:         2,
+		Action:        action,
+		Interruptable: true,
+		Wars:          req.Wars,
+		Round:         req.Round,
+		Effects:       req.AttackerEffects,
+		Now:           req.Now,
+	})
+	if res.Report.Taken != 2 {
+		res.Aborted = true
+		return att, def, res, nil
+	}
+
+	e.performAttack(&att, &def, units, req, res)
+
+	if e.cfg.MaxAttacks > 0 && !req.War && alive {
+		att.Attacks += 2
+		def.Attacks--
+	}
+	att.Health -= 8
+	att.OffTotal++
+	def.DefTotal++
+	return att, def, res, nil
+}
+
+// performAttack resolves the battle and captures land and buildings.
+func (e *Engine_t) performAttack(att, def *model.Empire_t, units []militaryUnit, req Attack_t, res *AttackResult_t) {
+	message := func(key string, args ...any) {
+		res.Messages = append(res.Messages, Message_t{Key: key, Args: args})
+	}
+	attEra, _ := req.Tables.Era(att.Era)
+	defEra, _ := req.Tables.Era(def.Era)
+	attGate := req.AttackerEffects.Active("m_gate", req.Now)
+	defGate := req.DefenderEffects != nil && req.DefenderEffects.Active("m_gate", req.Now)
+
+	// calculate power levels
+	var offpower, defpower float64
+	for _, u := range units {
+		offpower += float64(*u.units(&attEra.Offense) * *u.units(&res.Attacking))
+		defpower += float64(*u.units(&defEra.Defense) * *u.units(&res.Defending))
+	}
+
+	// apply race bonus
+	attMods := req.Tables.Modifiers(att).Add(req.AttackerEffects.Modifiers(req.Now))
+	defMods := req.Tables.Modifiers(def)
+	if req.DefenderEffects != nil {
+		defMods = defMods.Add(req.DefenderEffects.Modifiers(req.Now))
+	}
+	offpower *= Modifier(attMods.Offense)
+	defpower *= Modifier(defMods.Defense)
+
+	// reduce power with health levels
+	offpower *= float64(att.Health) / 100
+	defpower *= float64(def.Health) / 100
+
+	// grant 20% offense power when at war with the target
+	if req.War {
+		offpower *= 1.20
+	}
+
+	// eras and time gates have already been checked - now we build the messages to display
+	if att.Era != def.Era {
+		// use your own time gate first, then try your target's gate
+		if attGate {
+			message("MILITARY_TIMEGATE_SELF")
+		} else if defGate {
+			message("MILITARY_TIMEGATE_OTHER")
+		}
+	}
+
+	if req.Type == ATTACK_SURPRISE {
+		// additional 25% offense power bonus on surprise attacks, and block ally help
+		offpower *= 1.25
+		// but take an additional 5% health loss (after health taken into account above)
+		att.Health -= 5
+	} else if e.cfg.ClanEnable && def.CId != 0 && def.Sharing != 0 && defpower > 0 {
+		// otherwise, enemy clanmates are permitted to send reinforcements
+		// assuming they are shared AND the defender actually has something to defend with
+		var defbonus float64
+		for _, ally := range req.Allies {
+			allyGate := ally.Effects != nil && ally.Effects.Active("m_gate", req.Now)
+			allyEra, err := req.Tables.Era(ally.Empire.Era)
+			if err != nil || ally.Empire.Id == def.Id || ally.Empire.Land == 0 || ally.Empire.Flags.Delete || ally.Empire.Flags.Disable {
+				continue
+			} else if def.Era != ally.Empire.Era && !defGate && !allyGate {
+				continue
+			}
+			res.Allies++
+			res.News = append(res.News, NewNews(EMPNEWS_MILITARY_AID, att, &ally.Empire, def.Id))
+			var allydef float64
+			for _, u := range units {
+				amt := ceilPct(*u.emp(&ally.Empire), 0.10)
+				allydef += float64(*u.units(&allyEra.Defense) * amt)
+			}
+			allyMods := req.Tables.Modifiers(&ally.Empire)
+			if ally.Effects != nil {
+				allyMods = allyMods.Add(ally.Effects.Modifiers(req.Now))
+			}
+			allydef *= Modifier(allyMods.Defense)
+			allydef *= float64(ally.Empire.Health) / 100
+			defbonus += allydef
+		}
+		// and limit the overall defense bonus from allies
+		defpower += math.Min(defbonus, defpower)
+		if res.Allies > 0 {
+			message("MILITARY_ALLY_DEFENSE", res.Allies)
+		}
+	}
+
+	// add defense from guard towers - max 450 defense per tower, provided there's at least 150 soldiers per tower
+	// (soldiers inside the towers will provide their standard defense against enemy soldiers, but not against other units)
+	towerdef := float64(def.BldDef) * 450 * math.Min(1, float64(def.TrpArm)/(150*float64(def.BldDef)+1))
+	defpower += towerdef
+	res.OffPower, res.DefPower = offpower, defpower
+
+	// determine how many units each empire is about to lose
+	omod := math.Sqrt((defpower - towerdef) / (offpower + 1)) // modification to attacker losses (towers excluded)
+	dmod := math.Sqrt(offpower / (defpower + 1))              // modification to enemy losses
+	if req.Type == ATTACK_SURPRISE {
+		// surprise attack hurts the attacker 20% more
+		omod *= 1.2
+	}
+	for _, u := range units {
+		oper, dper := u.oper, u.dper
+		if req.Type == u.kind {
+			oper, dper = u.single, u.dsingl
+		}
+		e.unitLoss(res, u, oper, dper, omod, dmod)
+	}
+
+	// offense needs to be at least 5% stronger than defense
+	if offpower > defpower*1.05 {
+		res.Won = true
+		for _, b := range attackBuildings {
+			e.destroyBuildings(att, def, b.emp, b.bld, b.pcloss, b.pcgain, b.tower, req.Type, res)
+		}
+		if e.cfg.DropDelay != 0 {
+			_ = req.AttackerEffects.Set("m_droptime", e.cfg.DropDelay, req.Now)
+		}
+		res.Land = res.BuildGain.total()
+		message("MILITARY_SUCCESS_HEADER", def.Name, res.Land)
+		if e.cfg.ScoreEnable {
+			points := e.ScorePoints(att, def)
+			att.Score += points
+			def.Score--
+			res.Points += points
+		}
+		att.OffSucc++
+		for _, u := range militaryUnits {
+			*u.emp(def) -= *u.units(&res.DefendLosses)
+			*u.emp(att) -= *u.units(&res.AttackLosses)
+		}
+		if res.DefendLosses.total() > 0 {
+			message("MILITARY_SUCCESS_DESTROYED", res.DefendLosses)
+		}
+		if res.AttackLosses.total() > 0 {
+			message("MILITARY_SUCCESS_LOSSES", res.AttackLosses)
+		}
+	} else {
+		message("MILITARY_FAILURE_HEADER", def.Name)
+		if e.cfg.ScoreEnable {
+			points := round(float64(e.ScorePoints(att, def)) / 2)
+			att.Score -= points
+			def.Score++
+			res.Points -= points
+		}
+		def.DefSucc++
+		for _, u := range militaryUnits {
+			*u.emp(att) -= *u.units(&res.AttackLosses)
+			*u.emp(def) -= *u.units(&res.DefendLosses)
+		}
+		if res.AttackLosses.total() > 0 {
+			message("MILITARY_FAILURE_LOSSES", res.AttackLosses)
+		}
+		if res.DefendLosses.total() > 0 {
+			message("MILITARY_FAILURE_DESTROYED", res.DefendLosses)
+		}
+	}
+
+	if res.Won {
+		// if anything other than freeland was gained, report it
+		if bldgain := res.BuildGain.total() - res.BuildGain.Freeland; bldgain > 0 {
+			message("MILITARY_STRUCTURES_GAINED", bldgain)
+		}
+		// and if a negative amount of freeland was lost, report it
+		if blddest := -res.BuildLoss.Freeland; blddest > 0 {
+			message("MILITARY_STRUCTURES_DESTROYED", blddest)
+		}
+		if def.Land == 0 {
+			// if they deleted less than 30 minutes ago, it still counts as a kill
+			if !def.Flags.Delete || int64(def.Idle) > req.Now.Unix()-60*30 {
+				res.Killed = true
+				message("MILITARY_SUCCESS_KILLED", def)
+				if e.cfg.ScoreEnable {
+					points := max(round(float64(def.Score)/5), 100)
+					att.Score += points
+					res.Points += points
+				}
+				att.Kills++
+				def.KilledBy = att.Id
+				if e.cfg.ClanEnable {
+					def.KillClan = att.CId
+				}
+			} else {
+				message("MILITARY_SUCCESS_KILLED_LATE", def)
+			}
+		}
+	}
+
+	switch req.Type {
+	case ATTACK_STANDARD, ATTACK_SURPRISE:
+		event := EMPNEWS_MILITARY_STANDARD
+		if req.Type == ATTACK_SURPRISE {
+			event = EMPNEWS_MILITARY_SURPRISE
+		}
+		d, a := res.DefendLosses, res.AttackLosses
+		res.News = append(res.News, NewNews(event, att, def, res.Land,
+			d.TrpArm, d.TrpLnd, d.TrpFly, d.TrpSea,
+			a.TrpArm, a.TrpLnd, a.TrpFly, a.TrpSea))
+	default:
+		u := units[0]
+		res.News = append(res.News, NewNews(u.event, att, def, res.Land, *u.units(&res.DefendLosses), *u.units(&res.AttackLosses)))
+	}
+	if res.Killed {
+		res.News = append(res.News, NewNews(EMPNEWS_MILITARY_KILL, att, def, 0))
+	}
+}
+
+// unitLoss calculates the number of units lost by the attacker and defender.
+func (e *Engine_t) unitLoss(res *AttackResult_t, u militaryUnit, oper, dper, omod, dmod float64) {
+	attack, defend := *u.units(&res.Attacking), *u.units(&res.Defending)
+	*u.units(&res.AttackLosses) = min(e.randRange(0, int(math.Ceil(float64(attack)*oper*omod))+1), attack)
+
+	// defender cannot lose more than 90-110% of the units the attacker sent (random)
+	maxkill := round(0.9*float64(attack)) + e.randRange(0, round(0.2*float64(attack))+1)
+	*u.units(&res.DefendLosses) = min(e.randRange(0, int(math.Ceil(float64(defend)*dper*dmod))+1), defend, maxkill)
+}
+
+// destroyBuildings destroys the defender's buildings and/or land, possibly giving some to the attacker.
+func (e *Engine_t) destroyBuildings(att, def *model.Empire_t, emp func(*model.Empire_t) *int, bld func(*Buildings_t) *int, pcloss, pcgain float64, tower bool, kind AttackType_t, res *AttackResult_t) {
+	freeland := emp(def) == &def.Freeland
+	switch kind {
+	case ATTACK_TRPLND, ATTACK_TRPFLY, ATTACK_TRPSEA:
+		// these attacks are special - they destroy buildings (loss), but only steal some of the empty land (gain)
+		if kind == ATTACK_TRPFLY {
+			// air strikes destroy more, take more land, but gain fewer buildings
+			pcloss *= 1.25
+			pcgain *= 0.72
+		} else if tower {
+			// towers are even more likely to be destroyed by land/sea attacks
+			pcloss *= 1.30
+			pcgain *= 0.70
+		} else {
+			// while land/sea attacks simply have a higher chance of destroying the buildings stolen
+			pcgain *= 0.90
+		}
+	}
+
+	loss := min(e.randRange(1, int(math.Ceil(float64(*emp(def))*pcloss+2))), *emp(def))
+	gain := int(math.Ceil(float64(loss) * pcgain))
+
+	switch kind {
+	case ATTACK_STANDARD:
+		def.Land -= loss
+		*emp(def) -= loss
+		*bld(&res.BuildLoss) += loss
+
+		att.Land += loss
+		*emp(att) += gain
+		*bld(&res.BuildGain) += gain
+		att.Freeland += loss - gain
+		res.BuildGain.Freeland += loss - gain
+	case ATTACK_SURPRISE, ATTACK_TRPARM:
+		def.Land -= loss
+		*emp(def) -= loss
+		*bld(&res.BuildLoss) += loss
+
+		att.Land += loss
+		att.Freeland += loss
+		res.BuildGain.Freeland += loss
+	case ATTACK_TRPLND, ATTACK_TRPFLY, ATTACK_TRPSEA:
+		if freeland {
+			// for stealing unused land, the 'gain' percent is zero, so we need to use the 'loss' value instead
+			gain = loss
+		}
+		def.Land -= gain
+		*emp(def) -= loss
+		*bld(&res.BuildLoss) += loss
+		def.Freeland += loss - gain
+		res.BuildLoss.Freeland -= loss - gain
+
+		att.Land += gain
+		att.Freeland += gain
+		res.BuildGain.Freeland += gain
+	}
+}
@@ -0,0 +1,240 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package engine
+
+import (
+	"errors"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/model"
+	"testing"
+)
+
+// combatants returns an attacker and a defender in the same era.
+// A strong attacker overwhelms the defender; a weak one does not.
+func combatants(strong bool) (model.Empire_t, model.Empire_t) {
+	att, def := testEmpire(), testEmpire()
+	att.Race, att.Era, def.Race, def.Era = RACE_HUMAN, ERA_PRESENT, RACE_HUMAN, ERA_PRESENT
+	def.Id, def.Name = 2, "Target"
+	att.NetWorth, def.NetWorth = 150_000, 150_000
+	def.BldDef, def.BldWiz, def.Land = 20, 10, 280
+	if strong {
+		att.TrpArm, att.TrpLnd, att.TrpFly, att.TrpSea = 50_000, 20_000, 10_000, 5_000
+	} else {
+		def.TrpArm, def.TrpLnd, def.TrpFly, def.TrpSea = 50_000, 20_000, 10_000, 5_000
+	}
+	return att, def
+}
+
+func TestAttackErrors(t *testing.T) {
+	tables := DefaultTables()
+	for _, tc := range []struct {
+		name  string
+		kind  AttackType_t
+		setup func(att, def *model.Empire_t, req *Attack_t)
+		want  error
+	}{
+		{name: "unknown", kind: "nuclear", want: cerr.ErrUnknownAttack},
+		{name: "turns", kind: ATTACK_STANDARD, setup: func(att, _ *model.Empire_t, _ *Attack_t) { att.Turns = 1 }, want: cerr.ErrNeedTurns},
+		{name: "health", kind: ATTACK_STANDARD, setup: func(att, _ *model.Empire_t, _ *Attack_t) { att.Health = 10 }, want: cerr.ErrNeedHealth},
+		{name: "too small", kind: ATTACK_STANDARD, setup: func(_, def *model.Empire_t, _ *Attack_t) { def.NetWorth = 7_000 }, want: cerr.ErrTargetTooSmall},
+		{name: "too large", kind: ATTACK_STANDARD, setup: func(_, def *model.Empire_t, _ *Attack_t) { def.NetWorth = 3_100_000 }, want: cerr.ErrTargetTooLarge},
+		{name: "no units", kind: ATTACK_TRPSEA, setup: func(att, _ *model.Empire_t, _ *Attack_t) { att.TrpSea = 0 }, want: cerr.ErrNeedUnits},
+		{name: "attack limit", kind: ATTACK_STANDARD, setup: func(att, _ *model.Empire_t, _ *Attack_t) { att.Attacks = 30 }, want: cerr.ErrAttackLimit},
+		{name: "attack limit at war", kind: ATTACK_STANDARD, setup: func(att, _ *model.Empire_t, req *Attack_t) { att.Attacks, req.War = 30, true }},
+		{name: "dead target", kind: ATTACK_STANDARD, setup: func(att, def *model.Empire_t, _ *Attack_t) { att.Attacks, def.NetWorth = 30, 0 }},
+		{name: "other era", kind: ATTACK_STANDARD, setup: func(_, def *model.Empire_t, _ *Attack_t) { def.Era = ERA_FUTURE }, want: cerr.ErrNeedGate},
+		{name: "other era gated", kind: ATTACK_STANDARD, setup: func(_, def *model.Empire_t, req *Attack_t) {
+			def.Era = ERA_FUTURE
+			_ = req.AttackerEffects.Set("m_gate", 3600, spellTime)
+		}},
+	} {
+		cfg := testConfig
+		cfg.MaxAttacks = 15
+		att, def := combatants(true)
+		req := Attack_t{Type: tc.kind, SendAll: true, Tables: tables, AttackerEffects: NewEffects(att.Id, nil, tables.Effects), Now: spellTime}
+		if tc.setup != nil {
+			tc.setup(&att, &def, &req)
+		}
+		_, _, _, err := New(cfg, nil).Attack(att, def, req)
+		if !errors.Is(err, tc.want) {
+			t.Errorf("%s: want %v, got %v", tc.name, tc.want, err)
+		}
+	}
+}
+
+func TestAttack(t *testing.T) {
+	tables := DefaultTables()
+	for _, tc := range []struct {
+		kind   AttackType_t
+		strong bool
+		event  int
+	}{
+		{ATTACK_STANDARD, true, EMPNEWS_MILITARY_STANDARD},
+		{ATTACK_STANDARD, false, EMPNEWS_MILITARY_STANDARD},
+		{ATTACK_SURPRISE, true, EMPNEWS_MILITARY_SURPRISE},
+		{ATTACK_SURPRISE, false, EMPNEWS_MILITARY_SURPRISE},
+		{ATTACK_TRPARM, true, EMPNEWS_MILITARY_ARM},
+		{ATTACK_TRPARM, false, EMPNEWS_MILITARY_ARM},
+		{ATTACK_TRPLND, true, EMPNEWS_MILITARY_LND},
+		{ATTACK_TRPLND, false, EMPNEWS_MILITARY_LND},
+		{ATTACK_TRPFLY, true, EMPNEWS_MILITARY_FLY},
+		{ATTACK_TRPFLY, false, EMPNEWS_MILITARY_FLY},
+		{ATTACK_TRPSEA, true, EMPNEWS_MILITARY_SEA},
+		{ATTACK_TRPSEA, false, EMPNEWS_MILITARY_SEA},
+	} {
+		name := string(tc.kind)
+		if !tc.strong {
+			name += " weak"
+		}
+		t.Run(name, func(t *testing.T) {
+			cfg := testConfig
+			cfg.MaxAttacks, cfg.ScoreEnable = 15, true
+			att, def := combatants(tc.strong)
+			effects := NewEffects(att.Id, nil, tables.Effects)
+			a, d, res, err := New(cfg, nil).Attack(att, def, Attack_t{Type: tc.kind, SendAll: true, Tables: tables, AttackerEffects: effects, Now: spellTime})
+			if err != nil {
+				t.Fatalf("attack: %v", err)
+			} else if res.Aborted {
+				t.Fatalf("attack: aborted")
+			}
+			if res.Won != tc.strong {
+				t.Errorf("won: want %v, got %v (offense %.0f, defense %.0f)", tc.strong, res.Won, res.OffPower, res.DefPower)
+			}
+			if a.Turns != att.Turns-2 || d.DefTotal != 1 || a.OffTotal != 1 {
+				t.Errorf("totals: got turns %d, offense %d, defense %d", a.Turns, a.OffTotal, d.DefTotal)
+			}
+			if a.Attacks != 2 || d.Attacks != -1 {
+				t.Errorf("attacks: want 2 and -1, got %d and %d", a.Attacks, d.Attacks)
+			}
+			wantHealth := att.Health - 8
+			if tc.kind == ATTACK_SURPRISE {
+				wantHealth -= 5
+			}
+			// health regenerates while taking turns
+			if a.Health < wantHealth || a.Health > wantHealth+2 {
+				t.Errorf("health: want about %d, got %d", wantHealth, a.Health)
+			}
+
+			// land is never created, and buildings only ever move or disappear
+			if a.Land+d.Land != att.Land+def.Land {
+				t.Errorf("land: want %d total, got %d + %d", att.Land+def.Land, a.Land, d.Land)
+			}
+			if got := d.Land - (d.BldPop + d.BldCash + d.BldTrp + d.BldCost + d.BldFood + d.BldWiz + d.BldDef + d.Freeland); got != 0 {
+				t.Errorf("defender: land and buildings differ by %d", got)
+			}
+			if tc.strong {
+				if res.Land == 0 || a.Land-att.Land != res.Land || def.Land-d.Land != res.Land {
+					t.Errorf("land: captured %d, attacker %d -> %d, defender %d -> %d", res.Land, att.Land, a.Land, def.Land, d.Land)
+				}
+				if a.OffSucc != 1 || res.Points <= 0 || d.Score != -1 {
+					t.Errorf("success: got offsucc %d, points %d, defender score %d", a.OffSucc, res.Points, d.Score)
+				}
+				if got := effects.Get("m_droptime", spellTime); got != cfg.DropDelay {
+					t.Errorf("droptime: want %d, got %d", cfg.DropDelay, got)
+				}
+			} else {
+				if res.Land != 0 || d.Land != def.Land {
+					t.Errorf("land: want nothing captured, got %d", res.Land)
+				}
+				if d.DefSucc != 1 || res.Points >= 0 || d.Score != 1 {
+					t.Errorf("failure: got defsucc %d, points %d, defender score %d", d.DefSucc, res.Points, d.Score)
+				}
+			}
+
+			// units lost match the news
+			if len(res.News) != 1 || res.News[0].Event != tc.event || res.News[0].Target != def.Id {
+				t.Fatalf("news: want event %d, got %+v", tc.event, res.News)
+			}
+			n := res.News[0]
+			if n.Data[0] != res.Land {
+				t.Errorf("news: want land %d, got %d", res.Land, n.Data[0])
+			}
+			switch tc.kind {
+			case ATTACK_STANDARD, ATTACK_SURPRISE:
+				dl, al := res.DefendLosses, res.AttackLosses
+				if n.Data[1] != dl.TrpArm || n.Data[4] != dl.TrpSea || n.Data[5] != al.TrpArm || n.Data[8] != al.TrpSea {
+					t.Errorf("news: want losses %+v and %+v, got %v", dl, al, n.Data)
+				}
+				if att.TrpArm-a.TrpArm != al.TrpArm || def.TrpLnd-d.TrpLnd != dl.TrpLnd {
+					t.Errorf("units: losses not applied")
+				}
+			default:
+				for _, u := range militaryUnits {
+					if u.kind != tc.kind && *u.units(&res.Attacking) != 0 {
+						t.Errorf("units: sent %s on a %s attack", u.kind, tc.kind)
+					}
+					if u.kind == tc.kind && (n.Data[1] != *u.units(&res.DefendLosses) || n.Data[2] != *u.units(&res.AttackLosses)) {
+						t.Errorf("news: want losses %d and %d, got %v", *u.units(&res.DefendLosses), *u.units(&res.AttackLosses), n.Data)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestAttackKill(t *testing.T) {
+	tables := DefaultTables()
+	att, def := combatants(true)
+	def.Land, def.BldPop, def.BldCash, def.BldCost, def.BldFood, def.BldDef, def.BldWiz, def.Freeland = 1, 0, 0, 0, 0, 0, 0, 1
+	def.CId = 0
+	_, d, res, err := New(testConfig, nil).Attack(att, def, Attack_t{Type: ATTACK_STANDARD, SendAll: true, Tables: tables, AttackerEffects: NewEffects(att.Id, nil, tables.Effects), Now: spellTime})
+	if err != nil {
+		t.Fatalf("attack: %v", err)
+	}
+	if !res.Killed || d.KilledBy != att.Id {
+		t.Fatalf("kill: want killed by %d, got %v and %d", att.Id, res.Killed, d.KilledBy)
+	}
+	if len(res.News) != 2 || res.News[1].Event != EMPNEWS_MILITARY_KILL {
+		t.Errorf("news: want kill, got %+v", res.News)
+	}
+}
+
+func TestAttackAllies(t *testing.T) {
+	tables := DefaultTables()
+	att, def := combatants(true)
+	def.CId, def.Sharing = 1, 1
+	def.TrpArm = 20_000
+	ally, _ := combatants(false)
+	ally.Id, ally.CId = 3, 1
+	e := New(testConfig, nil)
+	req := Attack_t{Type: ATTACK_STANDARD, SendAll: true, Tables: tables, AttackerEffects: NewEffects(att.Id, nil, tables.Effects), Now: spellTime}
+	_, _, alone, err := e.Attack(att, def, req)
+	if err != nil {
+		t.Fatalf("attack: %v", err)
+	}
+	req.AttackerEffects = NewEffects(att.Id, nil, tables.Effects)
+	req.Allies = []Ally_t{{Empire: ally}}
+	_, _, helped, err := e.Attack(att, def, req)
+	if err != nil {
+		t.Fatalf("attack: %v", err)
+	}
+	if helped.Allies != 1 || helped.DefPower <= alone.DefPower || helped.DefPower > 2*alone.DefPower {
+		t.Errorf("allies: got %d, defense %.0f alone, %.0f helped", helped.Allies, alone.DefPower, helped.DefPower)
+	}
+	if len(helped.News) != 2 || helped.News[0].Event != EMPNEWS_MILITARY_AID || helped.News[0].Target != ally.Id || helped.News[0].Data[0] != def.Id {
+		t.Errorf("news: want aid, got %+v", helped.News)
+	}
+
+	// allies can't help against surprise attacks
+	req.Type = ATTACK_SURPRISE
+	req.AttackerEffects = NewEffects(att.Id, nil, tables.Effects)
+	if _, _, res, _ := e.Attack(att, def, req); res.Allies != 0 {
+		t.Errorf("surprise: want no allies, got %d", res.Allies)
+	}
+}
+
+func TestAttackDeterministic(t *testing.T) {
+	tables := DefaultTables()
+	att, def := combatants(true)
+	var lands []int
+	for range 2 {
+		a, _, _, err := New(testConfig, nil).Attack(att, def, Attack_t{Type: ATTACK_STANDARD, SendAll: true, Tables: tables, AttackerEffects: NewEffects(att.Id, nil, tables.Effects), Now: spellTime})
+		if err != nil {
+			t.Fatalf("attack: %v", err)
+		}
+		lands = append(lands, a.Land)
+	}
+	if lands[0] != lands[1] {
+		t.Errorf("deterministic: got %d and %d", lands[0], lands[1])
+	}
+}
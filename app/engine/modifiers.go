@@ -0,0 +1,54 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package engine
+
+// Modifiers_t holds the percentage bonuses (or penalties) granted by
+// an empire's race, era, and effects.
+type Modifiers_t struct {
+	Offense   int `json:"offense,omitempty" yaml:"offense,omitempty"`
+	Defense   int `json:"defense,omitempty" yaml:"defense,omitempty"`
+	Buildrate int `json:"buildrate,omitempty" yaml:"buildrate,omitempty"`
+	Expenses  int `json:"expenses,omitempty" yaml:"expenses,omitempty"`
+	Magic     int `json:"magic,omitempty" yaml:"magic,omitempty"`
+	Industry  int `json:"industry,omitempty" yaml:"industry,omitempty"`
+	Income    int `json:"income,omitempty" yaml:"income,omitempty"`
+	Explore   int `json:"explore,omitempty" yaml:"explore,omitempty"`
+	Market    int `json:"market,omitempty" yaml:"market,omitempty"`
+	Foodcon   int `json:"foodcon,omitempty" yaml:"foodcon,omitempty"`
+	Runepro   int `json:"runepro,omitempty" yaml:"runepro,omitempty"`
+	Foodpro   int `json:"foodpro,omitempty" yaml:"foodpro,omitempty"`
+}
+
+// Add returns the sum of the modifiers.
+func (m Modifiers_t) Add(mods ...Modifiers_t) Modifiers_t {
+	for _, o := range mods {
+		m.Offense += o.Offense
+		m.Defense += o.Defense
+		m.Buildrate += o.Buildrate
+		m.Expenses += o.Expenses
+		m.Magic += o.Magic
+		m.Industry += o.Industry
+		m.Income += o.Income
+		m.Explore += o.Explore
+		m.Market += o.Market
+		m.Foodcon += o.Foodcon
+		m.Runepro += o.Runepro
+		m.Foodpro += o.Foodpro
+	}
+	return m
+}
+
+// Modifier converts a percentage bonus into a multiplier, never less than zero.
+func Modifier(pct int) float64 {
+	return float64(max(100+pct, 0)) / 100
+}
+
+// Modifier2 converts a percentage bonus into a multiplier, converting
+// penalties into dividers equivalent to bonuses
+// (e.g. +100% == *2, -100% = /2, +200% = *3, -200% = /3).
+func Modifier2(pct int) float64 {
+	if pct < 0 {
+		return 100 / float64(100-pct)
+	}
+	return float64(pct+100) / 100
+}
@@ -0,0 +1,242 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package engine
+
+import (
+	"github.com/mdhender/promisance/app/model"
+	"time"
+)
+
+// Results reported in the first argument of successful magic news events.
+// Failed spells report the number of wizards lost as a negative number instead.
+const (
+	SPELLRESULT_NOEFFECT = 0
+	SPELLRESULT_SHIELDED = 1
+	SPELLRESULT_SUCCESS  = 2
+)
+
+// Empire news events with goods attached, from php/includes/news.php.
+// The goods are given to the recipient the next time they log in.
+const (
+	EMPNEWS_ATTACH_FIRST         = 100 // First attachment event, MUST be equal to the event below
+	EMPNEWS_ATTACH_LAST          = 105 // Last attachment event, MUST be equal to the event above
+	EMPNEWS_ATTACH_AID_RETURN    = 104 // 0:convoy to return, 1:convoy returned
+	EMPNEWS_ATTACH_AID_SEND      = 103 // 0:convoy, 1:trparm, 2:trplnd, 3:trpfly, 4:trpsea, 5:cash, 6:runes, 7:food
+	EMPNEWS_ATTACH_AID_SENDCLAN  = 105 // same as above, but to a clanmate
+	EMPNEWS_ATTACH_LOTTERY       = 101 // 0:winnings
+	EMPNEWS_ATTACH_MARKET_RETURN = 102 // 0:type, 1:amount, 2:price, 3:returned
+	EMPNEWS_ATTACH_MARKET_SELL   = 100 // 0:type, 1:amount, 2:paid, 3:earned (minus tax)
+)
+
+// Empire news events for magic and military actions, from php/includes/news.php.
+const (
+	EMPNEWS_MAGIC_ADVANCE     = 213 // unused
+	EMPNEWS_MAGIC_BLAST       = 202 // 0:result
+	EMPNEWS_MAGIC_CASH        = 208 // unused
+	EMPNEWS_MAGIC_FIGHT       = 211 // 0:result (>0 = acres taken), 1:target trpwiz loss, 2:attacker trpwiz loss
+	EMPNEWS_MAGIC_FOOD        = 207 // unused
+	EMPNEWS_MAGIC_GATE        = 209 // unused
+	EMPNEWS_MAGIC_REGRESS     = 214 // unused
+	EMPNEWS_MAGIC_RUNES       = 205 // 0:result, 1:runes
+	EMPNEWS_MAGIC_SHIELD      = 203 // unused
+	EMPNEWS_MAGIC_SPY         = 201 // 0:result
+	EMPNEWS_MAGIC_STEAL       = 212 // 0:result, 1:cash
+	EMPNEWS_MAGIC_STORM       = 204 // 0:result, 1:food, 2:cash
+	EMPNEWS_MAGIC_STRUCT      = 206 // 0:result, 1:buildings
+	EMPNEWS_MAGIC_UNGATE      = 210 // unused
+	EMPNEWS_MILITARY_AID      = 300 // 0:empire protected
+	EMPNEWS_MILITARY_ARM      = 304 // 0:acres, 1:target trparm loss, 2:attacker trparm loss
+	EMPNEWS_MILITARY_FLY      = 306 // 0:acres, 1:target trpfly loss, 2:attacker trpfly loss
+	EMPNEWS_MILITARY_KILL     = 301 // no arguments
+	EMPNEWS_MILITARY_LND      = 305 // 0:acres, 1:target trplnd loss, 2:attacker trplnd loss
+	EMPNEWS_MILITARY_SEA      = 307 // 0:acres, 1:target trpsea loss, 2:attacker trpsea loss
+	EMPNEWS_MILITARY_STANDARD = 302 // 0:acres, 1:target trparm loss, 2:target trplnd loss, 3:target trpfly loss, 4:target trpsea loss,
+	EMPNEWS_MILITARY_SURPRISE = 303 // 0:acres, 1:target trparm loss, 2:target trplnd loss, 3:target trpfly loss, 4:target trpsea loss,
+)
+
+// Empire news events for clans, from php/includes/news.php.
+const (
+	EMPNEWS_CLAN_ALLY_DECLINE     = 421 // no arguments
+	EMPNEWS_CLAN_ALLY_GONE        = 422 // no arguments
+	EMPNEWS_CLAN_ALLY_REQUEST     = 417 // no arguments
+	EMPNEWS_CLAN_ALLY_RETRACT     = 420 // no arguments
+	EMPNEWS_CLAN_ALLY_START       = 418 // no arguments
+	EMPNEWS_CLAN_ALLY_STOP        = 419 // no arguments
+	EMPNEWS_CLAN_CREATE           = 400 // no arguments
+	EMPNEWS_CLAN_DISBAND          = 401 // no arguments
+	EMPNEWS_CLAN_GRANT_ASSISTANT  = 407 // no arguments
+	EMPNEWS_CLAN_GRANT_LEADER     = 405 // no arguments
+	EMPNEWS_CLAN_GRANT_MINISTER   = 409 // no arguments
+	EMPNEWS_CLAN_INHERIT_LEADER   = 406 // no arguments
+	EMPNEWS_CLAN_INVITE_DISBANDED = 428 // no arguments
+	EMPNEWS_CLAN_INVITE_PERM      = 425 // no arguments
+	EMPNEWS_CLAN_INVITE_TEMP      = 424 // no arguments
+	EMPNEWS_CLAN_JOIN             = 402 // no arguments
+	EMPNEWS_CLAN_LEAVE            = 403 // no arguments
+	EMPNEWS_CLAN_REMOVE           = 404 // no arguments
+	EMPNEWS_CLAN_REVOKE_ASSISTANT = 408 // no arguments
+	EMPNEWS_CLAN_REVOKE_LEADER    = 423 // no arguments
+	EMPNEWS_CLAN_REVOKE_MINISTER  = 410 // no arguments
+	EMPNEWS_CLAN_UNINVITE_PERM    = 427 // no arguments
+	EMPNEWS_CLAN_UNINVITE_TEMP    = 426 // no arguments
+	EMPNEWS_CLAN_WAR_GONE         = 416 // no arguments
+	EMPNEWS_CLAN_WAR_REJECT       = 415 // no arguments
+	EMPNEWS_CLAN_WAR_REQUEST      = 412 // no arguments
+	EMPNEWS_CLAN_WAR_RETRACT      = 414 // no arguments
+	EMPNEWS_CLAN_WAR_START        = 411 // no arguments
+	EMPNEWS_CLAN_WAR_STOP         = 413 // no arguments
+)
+
+// Clan news events, from php/includes/news.php.
+const (
+	CLANNEWS_MEMBER_CREATE          = 100 // e1:founder
+	CLANNEWS_MEMBER_DEAD            = 104 // e1:dead member
+	CLANNEWS_MEMBER_INVITE_PERM     = 108 // e1:inviter, e2:recipient
+	CLANNEWS_MEMBER_INVITE_TEMP     = 107 // e1:inviter, e2:recipient
+	CLANNEWS_MEMBER_JOIN            = 101 // e1:new member
+	CLANNEWS_MEMBER_LEAVE           = 102 // e1:former member
+	CLANNEWS_MEMBER_REMOVE          = 103 // e1:former member, e2:kicker
+	CLANNEWS_MEMBER_SHARE           = 105 // e1:member
+	CLANNEWS_MEMBER_UNINVITE_PERM   = 110 // e1:uninviter, e2:recipient
+	CLANNEWS_MEMBER_UNINVITE_TEMP   = 109 // e1:uninviter, e2:recipient
+	CLANNEWS_MEMBER_UNSHARE         = 106 // e1:member
+	CLANNEWS_PERM_ASSISTANT_INHERIT = 206 // e1:new leader, e2:old leader
+	CLANNEWS_PERM_GRANT_ASSISTANT   = 202 // e1:new asst, e2:grantor
+	CLANNEWS_PERM_GRANT_LEADER      = 200 // e1:new leader, e2:grantor
+	CLANNEWS_PERM_GRANT_MINISTER    = 204 // e1:new fa, e2:grantor
+	CLANNEWS_PERM_MEMBER_INHERIT    = 208 // e1:new leader, e2:old leader
+	CLANNEWS_PERM_MINISTER_INHERIT  = 207 // e1:new leader, e2:old leader
+	CLANNEWS_PERM_REVOKE_ASSISTANT  = 203 // e1:old asst, e2:grantor
+	CLANNEWS_PERM_REVOKE_LEADER     = 201 // e1:old leader, e2:grantor
+	CLANNEWS_PERM_REVOKE_MINISTER   = 205 // e1:old fa, e2:grantor
+	CLANNEWS_PROP_CHANGE_LOGO       = 303 // e1:changer
+	CLANNEWS_PROP_CHANGE_PASSWORD   = 300 // e1:changer
+	CLANNEWS_PROP_CHANGE_TITLE      = 301 // e1:changer
+	CLANNEWS_PROP_CHANGE_URL        = 302 // e1:changer
+	CLANNEWS_RECV_ALLY_DECLINE      = 410 // e2:declarer, c2:ally
+	CLANNEWS_RECV_ALLY_GONE         = 411 // c2:ally
+	CLANNEWS_RECV_ALLY_REQUEST      = 406 // e2:declarer, c2:ally
+	CLANNEWS_RECV_ALLY_RETRACT      = 409 // e2:declarer, c2:ally
+	CLANNEWS_RECV_ALLY_START        = 407 // e2:declarer, c2:ally
+	CLANNEWS_RECV_ALLY_STOP         = 408 // e2:declarer, c2:ally
+	CLANNEWS_RECV_WAR_GONE          = 405 // c2:opponent
+	CLANNEWS_RECV_WAR_REJECT        = 404 // e2:declarer, c2:opponent
+	CLANNEWS_RECV_WAR_REQUEST       = 401 // e2:declarer, c2:opponent
+	CLANNEWS_RECV_WAR_RETRACT       = 403 // e2:declarer, c2:opponent
+	CLANNEWS_RECV_WAR_START         = 400 // e2:declarer, c2:opponent
+	CLANNEWS_RECV_WAR_STOP          = 402 // e2:declarer, c2:opponent
+	CLANNEWS_SEND_ALLY_DECLINE      = 509 // e1:declarer, c2:ally
+	CLANNEWS_SEND_ALLY_REQUEST      = 505 // e1:declarer, c2:ally
+	CLANNEWS_SEND_ALLY_RETRACT      = 508 // e1:declarer, c2:ally
+	CLANNEWS_SEND_ALLY_START        = 506 // e1:declarer, c2:ally
+	CLANNEWS_SEND_ALLY_STOP         = 507 // e1:declarer, c2:ally
+	CLANNEWS_SEND_WAR_REJECT        = 504 // e1:declarer, c2:opponent
+	CLANNEWS_SEND_WAR_REQUEST       = 501 // e1:declarer, c2:opponent
+	CLANNEWS_SEND_WAR_RETRACT       = 503 // e1:declarer, c2:opponent
+	CLANNEWS_SEND_WAR_START         = 500 // e1:declarer, c2:opponent
+	CLANNEWS_SEND_WAR_STOP          = 502 // e1:declarer, c2:opponent
+)
+
+// News_t is an event to be added to the empire_news table.
+// Empire and clan ids are captured when the event is created.
+type News_t struct {
+	Event      int // EMPNEWS_* event
+	Source     int // empire causing the event, zero if none
+	SourceClan int
+	Target     int // empire receiving the event
+	TargetClan int
+	Data       [9]int // event arguments, see the comments on each event
+}
+
+// NewNews returns a news event from src to dst. Src may be nil.
+func NewNews(event int, src, dst *model.Empire_t, data ...int) News_t {
+	n := News_t{Event: event, Target: dst.Id, TargetClan: dst.CId}
+	if src != nil {
+		n.Source, n.SourceClan = src.Id, src.CId
+	}
+	copy(n.Data[:], data)
+	return n
+}
+
+// ClanNews_t is an event in the clan_news table.
+// The time is set when the event is read back from the table.
+type ClanNews_t struct {
+	Time    time.Time
+	Event   int // CLANNEWS_* event
+	Clan    int // clan the event is reported to
+	Empire1 int // see the comments on each event, zero if none
+	Clan2   int
+	Empire2 int
+}
+
+// NewClanNews returns a news event for the clan, from addClanNews.
+// The empires and the other clan may be nil.
+func NewClanNews(event int, c1 *model.Clan_t, e1 *model.Empire_t, c2 *model.Clan_t, e2 *model.Empire_t) ClanNews_t {
+	n := ClanNews_t{Event: event, Clan: c1.Id}
+	if e1 != nil {
+		n.Empire1 = e1.Id
+	}
+	if c2 != nil {
+		n.Clan2 = c2.Id
+	}
+	if e2 != nil {
+		n.Empire2 = e2.Id
+	}
+	return n
+}
+
+// GiveNews gives the empire the goods attached to its news events.
+// Senders holds the empires that sent foreign aid, keyed by id; a shipment
+// from an empire missing from it is delivered without naming the sender.
+// It returns a message describing each event that changed the empire and
+// the news events created by the delivery, such as returning an aid convoy.
+func (e *Engine_t) GiveNews(emp *model.Empire_t, era *Era_t, news []News_t, senders map[int]*model.Empire_t) ([]Message_t, []News_t) {
+	var messages []Message_t
+	var created []News_t
+	for _, n := range news {
+		switch n.Event {
+		case EMPNEWS_ATTACH_AID_SEND, EMPNEWS_ATTACH_AID_SENDCLAN:
+			// the goods were delivered when the aid was sent; send back the rest of the convoy
+			if returned, reply := returnAid(emp, n); reply != nil {
+				created = append(created, *reply)
+				if returned > 0 {
+					sender := model.Empire_t{Id: n.Source}
+					if src, ok := senders[n.Source]; ok {
+						sender = *src
+					}
+					messages = append(messages, Message_t{Key: "EMPNEWS_GIVE_AID_SEND", Args: []any{sender, era.TrpSea, returned}})
+				}
+			}
+		case EMPNEWS_ATTACH_AID_RETURN:
+			emp.TrpSea += n.Data[1]
+			if n.Data[0] == n.Data[1] {
+				messages = append(messages, Message_t{Key: "EMPNEWS_GIVE_AID_RETURN_ALL", Args: []any{era.TrpSea}})
+			} else {
+				messages = append(messages, Message_t{Key: "EMPNEWS_GIVE_AID_RETURN_SOME", Args: []any{n.Data[1], n.Data[0], era.TrpSea}})
+			}
+		case EMPNEWS_ATTACH_LOTTERY:
+			emp.Cash += n.Data[0]
+			messages = append(messages, Message_t{Key: "EMPNEWS_GIVE_LOTTERY"})
+		case EMPNEWS_ATTACH_MARKET_SELL:
+			if g := Good_t(n.Data[0]); g.IsValid() {
+				emp.Cash += n.Data[3]
+				messages = append(messages, Message_t{Key: "EMPNEWS_GIVE_MARKET_SELL", Args: []any{n.Data[1], g.Name(era)}})
+			}
+		case EMPNEWS_ATTACH_MARKET_RETURN:
+			if g := Good_t(n.Data[0]); g.IsValid() {
+				g.Give(emp, n.Data[3])
+				messages = append(messages, Message_t{Key: "EMPNEWS_GIVE_MARKET_RETURN", Args: []any{n.Data[1], g.Name(era)}})
+			}
+		}
+	}
+	return messages, created
+}
+
+// Message_t is a message to be shown to the player.
+// Key is a language key. Args are numbers, language keys (such as era
+// specific unit names), empires, or lists of messages; the caller formats
+// them for display.
+type Message_t struct {
+	Key  string
+	Args []any
+}
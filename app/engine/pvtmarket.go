@@ -0,0 +1,107 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package engine
+
+import (
+	"github.com/mdhender/promisance/app/model"
+	"math"
+)
+
+// pvtmGoods describes the private market for each type of goods, from
+// php/pages/pvtmarketbuy.php and php/pages/pvtmarketsell.php.
+// Food has no limit on sales.
+var pvtmGoods = map[Good_t]struct {
+	stock func(*model.Empire_t) *int // goods the market has for the empire to buy
+	limit func(*model.Empire_t) *int // share of the empire's goods sold recently, in hundredths of a percent
+	sell  float64                    // share of the base cost paid for goods sold to the market
+}{
+	MARKET_TRPARM: {func(e *model.Empire_t) *int { return &e.MktArm }, func(e *model.Empire_t) *int { return &e.MktPerArm }, 0.32},
+	MARKET_TRPLND: {func(e *model.Empire_t) *int { return &e.MktLnd }, func(e *model.Empire_t) *int { return &e.MktPerLnd }, 0.34},
+	MARKET_TRPFLY: {func(e *model.Empire_t) *int { return &e.MktFly }, func(e *model.Empire_t) *int { return &e.MktPerFly }, 0.36},
+	MARKET_TRPSEA: {func(e *model.Empire_t) *int { return &e.MktSea }, func(e *model.Empire_t) *int { return &e.MktPerSea }, 0.38},
+	MARKET_FOOD:   {func(e *model.Empire_t) *int { return &e.MktFood }, nil, 0.20},
+}
+
+// PvtmStock returns the number of goods the private market has for the empire to buy.
+func (e *Engine_t) PvtmStock(emp *model.Empire_t, g Good_t) int {
+	return *pvtmGoods[g].stock(emp)
+}
+
+// pvtmShopBonus returns the share of the empire's land that is covered by
+// shops and markets, weighted by the configured shop bonus.
+func (e *Engine_t) pvtmShopBonus(emp *model.Empire_t) float64 {
+	land := float64(max(emp.Land, 1))
+	return (1-e.cfg.PvtmShopBonus)*float64(emp.BldCost)/land + e.cfg.PvtmShopBonus*float64(emp.BldCash)/land
+}
+
+// PvtmBuyPrice returns the price the empire pays for one unit of goods on the private market.
+// Markets, shops, and the race's market bonus lower the price of troops, down to 60% of their base cost.
+func (e *Engine_t) PvtmBuyPrice(emp *model.Empire_t, mods Modifiers_t, g Good_t) int {
+	base := float64(e.MarketCost(g))
+	if g == MARKET_FOOD {
+		return round(base)
+	}
+	cost := base * (1 - e.pvtmShopBonus(emp)) * (2 - Modifier(mods.Market))
+	return round(max(cost, base*0.6))
+}
+
+// PvtmSellPrice returns the price the private market pays the empire for one unit of goods.
+// Markets, shops, and the race's market bonus raise the price of troops, up to 50% of their base cost.
+func (e *Engine_t) PvtmSellPrice(emp *model.Empire_t, mods Modifiers_t, g Good_t) int {
+	base := float64(e.MarketCost(g))
+	if g == MARKET_FOOD {
+		return round(base * pvtmGoods[g].sell)
+	}
+	cost := base * pvtmGoods[g].sell * (1 + e.pvtmShopBonus(emp)) / (2 - Modifier(mods.Market))
+	return round(min(cost, base*0.5))
+}
+
+// PvtmCanBuy returns the number of goods the empire can afford to buy from the private market.
+func (e *Engine_t) PvtmCanBuy(emp *model.Empire_t, mods Modifiers_t, g Good_t) int {
+	return max(min(emp.Cash/max(e.PvtmBuyPrice(emp, mods, g), 1), e.PvtmStock(emp, g)), 0)
+}
+
+// PvtmCanSell returns the number of goods the empire can sell to the private market.
+// Troops are limited by the share of them sold recently; food is not limited.
+func (e *Engine_t) PvtmCanSell(emp *model.Empire_t, g Good_t) int {
+	limit := pvtmGoods[g].limit
+	if limit == nil {
+		return g.Owned(emp)
+	}
+	return max(int(math.Floor(float64(e.cfg.PvtmMaxSell-*limit(emp))/10000*float64(g.Owned(emp)))), 0)
+}
+
+// PvtmBuy buys goods from the private market for the empire.
+// It returns the cash spent, which is zero if the empire could not buy the goods,
+// and a message describing the purchase.
+func (e *Engine_t) PvtmBuy(emp *model.Empire_t, era *Era_t, mods Modifiers_t, g Good_t, amount int) (int, Message_t) {
+	cost := amount * e.PvtmBuyPrice(emp, mods, g)
+	if amount > e.PvtmStock(emp, g) {
+		return 0, Message_t{Key: "PVTMARKETBUY_NOT_ENOUGH_UNITS", Args: []any{g.Name(era)}}
+	} else if cost > emp.Cash {
+		return 0, Message_t{Key: "PVTMARKETBUY_NOT_ENOUGH_MONEY", Args: []any{g.Name(era)}}
+	}
+	emp.Cash -= cost
+	g.Give(emp, amount)
+	*pvtmGoods[g].stock(emp) -= amount
+	return cost, Message_t{Key: "PVTMARKETBUY_COMPLETE", Args: []any{amount, g.Name(era), cost}}
+}
+
+// PvtmSell sells goods from the empire to the private market.
+// It returns the cash earned, which is zero if the empire could not sell the goods,
+// and a message describing the sale. Goods sold to the market can not be bought back.
+func (e *Engine_t) PvtmSell(emp *model.Empire_t, era *Era_t, mods Modifiers_t, g Good_t, amount int) (int, Message_t) {
+	cost := amount * e.PvtmSellPrice(emp, mods, g)
+	limit := pvtmGoods[g].limit
+	if limit != nil && amount > e.PvtmCanSell(emp, g) {
+		return 0, Message_t{Key: "PVTMARKETSELL_TOO_MANY_UNITS", Args: []any{g.Name(era)}}
+	} else if limit == nil && amount > g.Owned(emp) {
+		return 0, Message_t{Key: "PVTMARKETSELL_NOT_ENOUGH_UNITS", Args: []any{g.Name(era)}}
+	}
+	emp.Cash += cost
+	if limit != nil {
+		*limit(emp) += round(float64(amount) / float64(g.Owned(emp)) * 10000)
+	}
+	g.Give(emp, -amount)
+	return cost, Message_t{Key: "PVTMARKETSELL_COMPLETE", Args: []any{amount, g.Name(era), cost}}
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package engine
+
+import (
+	"testing"
+)
+
+func TestPvtmPrices(t *testing.T) {
+	e := New(testConfig, nil)
+	emp := testEmpire()
+	for _, tc := range []struct {
+		good      Good_t
+		market    int
+		buy, sell int
+	}{
+		{MARKET_TRPARM, 0, 488, 164},
+		{MARKET_TRPARM, 20, 390, 205},
+		{MARKET_TRPARM, -20, 586, 137},
+		{MARKET_TRPSEA, 0, 2928, 1167},
+		{MARKET_TRPSEA, 100, 1800, 1500},
+		{MARKET_FOOD, 20, 30, 6},
+	} {
+		mods := Modifiers_t{Market: tc.market}
+		if got := e.PvtmBuyPrice(&emp, mods, tc.good); got != tc.buy {
+			t.Errorf("%s %d: buy: want %d, got %d", tc.good, tc.market, tc.buy, got)
+		}
+		if got := e.PvtmSellPrice(&emp, mods, tc.good); got != tc.sell {
+			t.Errorf("%s %d: sell: want %d, got %d", tc.good, tc.market, tc.sell, got)
+		}
+	}
+}
+
+func TestPvtmTrade(t *testing.T) {
+	e := New(testConfig, nil)
+	era := DefaultTables().Eras[ERA_PAST]
+	emp := testEmpire()
+	emp.MktArm, emp.MktFood, emp.MktPerArm = 100, 5000, 3000
+
+	// buying is limited by the market's stock and the empire's cash
+	if got := e.PvtmCanBuy(&emp, Modifiers_t{}, MARKET_TRPARM); got != 100 {
+		t.Errorf("can buy: want 100, got %d", got)
+	}
+	if spent, msg := e.PvtmBuy(&emp, era, Modifiers_t{}, MARKET_TRPARM, 101); spent != 0 || msg.Key != "PVTMARKETBUY_NOT_ENOUGH_UNITS" {
+		t.Errorf("buy: too many: got %d, %+v", spent, msg)
+	}
+	if spent, msg := e.PvtmBuy(&emp, era, Modifiers_t{}, MARKET_FOOD, 4000); spent != 0 || msg.Key != "PVTMARKETBUY_NOT_ENOUGH_MONEY" {
+		t.Errorf("buy: too expensive: got %d, %+v", spent, msg)
+	}
+	if spent, msg := e.PvtmBuy(&emp, era, Modifiers_t{}, MARKET_TRPARM, 40); spent != 19520 || msg.Key != "PVTMARKETBUY_COMPLETE" {
+		t.Errorf("buy: got %d, %+v", spent, msg)
+	} else if emp.TrpArm != 140 || emp.MktArm != 60 || emp.Cash != 80480 {
+		t.Errorf("buy: want trparm 140, mktarm 60, cash 80480, got %d, %d, %d", emp.TrpArm, emp.MktArm, emp.Cash)
+	}
+
+	// selling troops is limited by the share sold recently
+	if got := e.PvtmCanSell(&emp, MARKET_TRPARM); got != 70 {
+		t.Errorf("can sell: want 70, got %d", got)
+	}
+	if earned, msg := e.PvtmSell(&emp, era, Modifiers_t{}, MARKET_TRPARM, 71); earned != 0 || msg.Key != "PVTMARKETSELL_TOO_MANY_UNITS" {
+		t.Errorf("sell: too many: got %d, %+v", earned, msg)
+	}
+	if earned, msg := e.PvtmSell(&emp, era, Modifiers_t{}, MARKET_TRPARM, 70); earned != 11480 || msg.Key != "PVTMARKETSELL_COMPLETE" {
+		t.Errorf("sell: got %d, %+v", earned, msg)
+	} else if emp.TrpArm != 70 || emp.MktPerArm != 8000 || emp.MktArm != 60 {
+		t.Errorf("sell: want trparm 70, mktperarm 8000, mktarm 60, got %d, %d, %d", emp.TrpArm, emp.MktPerArm, emp.MktArm)
+	}
+	if got := e.PvtmCanSell(&emp, MARKET_TRPARM); got != 0 {
+		t.Errorf("can sell: want 0, got %d", got)
+	}
+
+	// food is only limited by the amount on hand
+	if earned, msg := e.PvtmSell(&emp, era, Modifiers_t{}, MARKET_FOOD, 10001); earned != 0 || msg.Key != "PVTMARKETSELL_NOT_ENOUGH_UNITS" {
+		t.Errorf("sell: food: too many: got %d, %+v", earned, msg)
+	}
+	if earned, _ := e.PvtmSell(&emp, era, Modifiers_t{}, MARKET_FOOD, 10000); earned != 60000 || emp.Food != 0 {
+		t.Errorf("sell: food: want 60000, 0, got %d, %d", earned, emp.Food)
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package engine
+
+// Race identifiers from php/classes/prom_race.php.
+// These are stored in the database and must not change.
+const (
+	RACE_HUMAN   = 1
+	RACE_ELF     = 2
+	RACE_DWARF   = 3
+	RACE_TROLL   = 4
+	RACE_GNOME   = 5
+	RACE_GREMLIN = 6
+	RACE_ORC     = 7
+	RACE_DROW    = 8
+	RACE_GOBLIN  = 9
+)
+
+// Race_t defines a race and the modifiers it grants to empires.
+type Race_t struct {
+	Id        int         `json:"-" yaml:"-"`
+	Name      string      `json:"name" yaml:"name"` // language key for the race's name
+	Modifiers Modifiers_t `json:"modifiers" yaml:"modifiers"`
+}
+
+// defaultRaces returns the races from php/classes/prom_race.php.
+func defaultRaces() map[int]*Race_t {
+	return map[int]*Race_t{
+		RACE_HUMAN: {
+			Name:      "RACE_HUMAN",
+			Modifiers: Modifiers_t{},
+		},
+		RACE_ELF: {
+			Name: "RACE_ELF",
+			Modifiers: Modifiers_t{
+				Offense:   -14,
+				Defense:   -2,
+				Buildrate: -10,
+				Expenses:  0,
+				Magic:     18,
+				Industry:  -12,
+				Income:    2,
+				Explore:   12,
+				Market:    0,
+				Foodcon:   0,
+				Runepro:   12,
+				Foodpro:   -6,
+			},
+		},
+		RACE_DWARF: {
+			Name: "RACE_DWARF",
+			Modifiers: Modifiers_t{
+				Offense:   6,
+				Defense:   16,
+				Buildrate: 16,
+				Expenses:  -8,
+				Magic:     -16,
+				Industry:  12,
+				Income:    0,
+				Explore:   -18,
+				Market:    -8,
+				Foodcon:   0,
+				Runepro:   0,
+				Foodpro:   0,
+			},
+		},
+		RACE_TROLL: {
+			Name: "RACE_TROLL",
+			Modifiers: Modifiers_t{
+				Offense:   24,
+				Defense:   -10,
+				Buildrate: 8,
+				Expenses:  0,
+				Magic:     -12,
+				Industry:  0,
+				Income:    4,
+				Explore:   14,
+				Market:    -12,
+				Foodcon:   0,
+				Runepro:   -8,
+				Foodpro:   -8,
+			},
+		},
+		RACE_GNOME: {
+			Name: "RACE_GNOME",
+			Modifiers: Modifiers_t{
+				Offense:   -16,
+				Defense:   10,
+				Buildrate: 0,
+				Expenses:  6,
+				Magic:     0,
+				Industry:  -10,
+				Income:    10,
+				Explore:   -12,
+				Market:    24,
+				Foodcon:   0,
+				Runepro:   -12,
+				Foodpro:   0,
+			},
+		},
+		RACE_GREMLIN: {
+			Name: "RACE_GREMLIN",
+			Modifiers: Modifiers_t{
+				Offense:   10,
+				Defense:   -6,
+				Buildrate: 0,
+				Expenses:  0,
+				Magic:     -10,
+				Industry:  -14,
+				Income:    -20,
+				Explore:   0,
+				Market:    8,
+				Foodcon:   14,
+				Runepro:   0,
+				Foodpro:   18,
+			},
+		},
+		RACE_ORC: {
+			Name: "RACE_ORC",
+			Modifiers: Modifiers_t{
+				Offense:   16,
+				Defense:   0,
+				Buildrate: 4,
+				Expenses:  -14,
+				Magic:     -4,
+				Industry:  8,
+				Income:    0,
+				Explore:   22,
+				Market:    0,
+				Foodcon:   -10,
+				Runepro:   -14,
+				Foodpro:   -8,
+			},
+		},
+		RACE_DROW: {
+			Name: "RACE_DROW",
+			Modifiers: Modifiers_t{
+				Offense:   14,
+				Defense:   6,
+				Buildrate: -12,
+				Expenses:  -10,
+				Magic:     18,
+				Industry:  0,
+				Income:    0,
+				Explore:   -16,
+				Market:    0,
+				Foodcon:   0,
+				Runepro:   6,
+				Foodpro:   -6,
+			},
+		},
+		RACE_GOBLIN: {
+			Name: "RACE_GOBLIN",
+			Modifiers: Modifiers_t{
+				Offense:   -18,
+				Defense:   -16,
+				Buildrate: 0,
+				Expenses:  18,
+				Magic:     0,
+				Industry:  14,
+				Income:    0,
+				Explore:   0,
+				Market:    -6,
+				Foodcon:   8,
+				Runepro:   0,
+				Foodpro:   0,
+			},
+		},
+	}
+}
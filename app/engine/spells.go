@@ -0,0 +1,407 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package engine
+
+import (
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/model"
+	"math"
+	"time"
+)
+
+// Target_t is the kind of empire a spell is cast on.
+type Target_t string
+
+const (
+	TARGET_SELF   Target_t = "self"
+	TARGET_FRIEND Target_t = "friend"
+	TARGET_ENEMY  Target_t = "enemy"
+)
+
+// Spell_i is implemented by every spell. Each spell also implements
+// at least one of SelfSpell_i, FriendSpell_i, or EnemySpell_i.
+type Spell_i interface {
+	Name() string    // key for the spell's name in Era_t.Spells
+	Event() int      // EMPNEWS_MAGIC_* event, also used to identify the spell in the news
+	Points() float64 // scales the score points gained when casting the spell on another empire
+}
+
+// SelfSpell_i is implemented by spells which can be cast on yourself.
+type SelfSpell_i interface {
+	Spell_i
+	CostSelf(c *Cast_t) int
+	TurnsSelf(c *Cast_t) int
+	AllowSelf(c *Cast_t) bool
+	CastSelf(c *Cast_t) bool
+}
+
+// FriendSpell_i is implemented by spells which can be cast on a friendly empire.
+type FriendSpell_i interface {
+	Spell_i
+	CostFriend(c *Cast_t) int
+	TurnsFriend(c *Cast_t) int
+	AllowFriend(c *Cast_t) bool
+	CastFriend(c *Cast_t) bool
+}
+
+// EnemySpell_i is implemented by spells which can be cast on enemies.
+type EnemySpell_i interface {
+	Spell_i
+	CostEnemy(c *Cast_t) int
+	TurnsEnemy(c *Cast_t) int
+	AllowEnemy(c *Cast_t) bool
+	CastEnemy(c *Cast_t) bool
+}
+
+// VaryCostSpell_i is implemented by spells whose rune and turn costs are
+// determined based on the target. Has no effect when applied to self-cast spells.
+type VaryCostSpell_i interface {
+	Spell_i
+	VaryCost()
+}
+
+// SpySpell_i is implemented by offensive spells which do not count as attacks
+// (no health loss, no networth restrictions).
+// Has no effect when applied to self-cast or friend-cast spells.
+type SpySpell_i interface {
+	EnemySpell_i
+	Spy()
+}
+
+// spells lists every spell, in order by news event.
+var spells = []Spell_i{
+	spySpell{},
+	blastSpell{},
+	shieldSpell{},
+	stormSpell{},
+	runesSpell{},
+	structSpell{},
+	foodSpell{},
+	cashSpell{},
+	gateSpell{},
+	ungateSpell{},
+	fightSpell{},
+	stealSpell{},
+	advanceSpell{},
+	regressSpell{},
+}
+
+// Spells returns the spells available in this game, in order by news event.
+// The regress spell is only available if the configuration allows it.
+func (e *Engine_t) Spells() []Spell_i {
+	var list []Spell_i
+	for _, spell := range spells {
+		if spell.Event() == EMPNEWS_MAGIC_REGRESS && !e.cfg.MagicAllowRegress {
+			continue
+		}
+		list = append(list, spell)
+	}
+	return list
+}
+
+// Spell returns the named spell, or an error if it is not available in this game.
+func (e *Engine_t) Spell(name string) (Spell_i, error) {
+	for _, spell := range e.Spells() {
+		if spell.Name() == name {
+			return spell, nil
+		}
+	}
+	return nil, cerr.ErrUnknownSpell
+}
+
+// SelfSpellCost returns the runes and turns needed to cast a spell on yourself,
+// and whether the spell may be cast right now. Nothing is updated.
+func (e *Engine_t) SelfSpellCost(name string, self *Caster_t, tables *Tables_t, now time.Time) (cost, turns int, allowed bool, err error) {
+	spell, err := e.Spell(name)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	s, ok := spell.(SelfSpell_i)
+	if !ok {
+		return 0, 0, false, cerr.ErrSpellTarget
+	} else if _, err := tables.Era(self.Empire.Era); err != nil {
+		return 0, 0, false, err
+	}
+	c := &Cast_t{e: e, spell: spell, Self: self, tables: tables, now: now}
+	return s.CostSelf(c), s.TurnsSelf(c), s.AllowSelf(c), nil
+}
+
+// Caster_t is an empire taking part in a spell.
+// The empire and effects are updated in place; neither may be nil.
+type Caster_t struct {
+	Empire  *model.Empire_t
+	Effects *Effects_t
+}
+
+// CastSpell_t describes a request to cast a spell.
+type CastSpell_t struct {
+	Spell  string
+	Target Target_t
+	Self   *Caster_t
+	Other  *Caster_t // required for friend and enemy spells
+	Tables *Tables_t
+	Round  model.RoundData_t // passed on when taking turns
+	Wars   int               // passed on when taking turns
+	War    bool              // the spell is an act of war and turns are taken as ACTION_WAR
+	Now    time.Time
+}
+
+// SpellResult_t is the result of casting a spell.
+type SpellResult_t struct {
+	Spell    string
+	Target   Target_t
+	Cost     int       // runes spent
+	Turns    int       // turns spent
+	Report   *Report_t // from taking turns before the spell is cast
+	Aborted  bool      // turns were interrupted by trouble, so the spell was not cast
+	Success  bool      // false if the spell failed or was repelled, and casting should stop
+	Failed   bool      // the spell fizzled and wizards were lost
+	Result   int       // SPELLRESULT_* if the spell did not fizzle
+	WizLoss  int       // wizards lost when the spell fizzled
+	Points   int       // score points gained, negative if lost
+	Killed   bool      // the target was destroyed
+	Messages []Message_t
+	News     []News_t
+}
+
+// Cast_t holds the state of a spell while it is being cast.
+type Cast_t struct {
+	e      *Engine_t
+	spell  Spell_i
+	Self   *Caster_t
+	Other  *Caster_t // nil when casting on yourself
+	tables *Tables_t
+	round  model.RoundData_t
+	now    time.Time
+	result *SpellResult_t
+}
+
+// CastSpell casts a spell once, updating the empires and effects in place.
+//
+// The caster's runes and turns are spent before the spell is cast.
+// If taking turns runs into trouble, the result is returned with Aborted set.
+// Callers are responsible for checking that the target is a legal one (not
+// protected, in the same era or behind a time gate, not in the same clan, and
+// so on) and for applying health and attack penalties for enemy spells.
+func (e *Engine_t) CastSpell(req CastSpell_t) (*SpellResult_t, error) {
+	spell, err := e.Spell(req.Spell)
+	if err != nil {
+		return nil, err
+	}
+	if req.Target == TARGET_FRIEND && !e.cfg.FriendMagicEnable {
+		return nil, cerr.ErrFriendMagicDisabled
+	}
+	c := &Cast_t{e: e, spell: spell, Self: req.Self, tables: req.Tables, round: req.Round, now: req.Now}
+	if req.Target != TARGET_SELF {
+		if req.Other == nil {
+			return nil, cerr.ErrSpellTarget
+		}
+		c.Other = req.Other
+	}
+	for _, p := range []*Caster_t{c.Self, c.Other} {
+		if p == nil {
+			continue
+		} else if _, err := req.Tables.Era(p.Empire.Era); err != nil {
+			return nil, err
+		}
+	}
+
+	var cost, turns int
+	var allowed bool
+	var cast func(*Cast_t) bool
+	switch req.Target {
+	case TARGET_SELF:
+		s, ok := spell.(SelfSpell_i)
+		if !ok {
+			return nil, cerr.ErrSpellTarget
+		}
+		allowed, cost, turns, cast = s.AllowSelf(c), s.CostSelf(c), s.TurnsSelf(c), s.CastSelf
+	case TARGET_FRIEND:
+		s, ok := spell.(FriendSpell_i)
+		if !ok {
+			return nil, cerr.ErrSpellTarget
+		}
+		allowed, cost, turns, cast = s.AllowFriend(c), s.CostFriend(c), s.TurnsFriend(c), s.CastFriend
+	case TARGET_ENEMY:
+		s, ok := spell.(EnemySpell_i)
+		if !ok {
+			return nil, cerr.ErrSpellTarget
+		}
+		allowed, cost, turns, cast = s.AllowEnemy(c), s.CostEnemy(c), s.TurnsEnemy(c), s.CastEnemy
+	default:
+		return nil, cerr.ErrSpellTarget
+	}
+	emp := c.Self.Empire
+	if !allowed {
+		return nil, cerr.ErrSpellNotAllowed
+	} else if emp.TrpWiz == 0 {
+		return nil, cerr.ErrNeedWizards
+	} else if emp.Runes < cost {
+		return nil, cerr.ErrNeedRunes
+	} else if emp.Turns < turns {
+		return nil, cerr.ErrNeedTurns
+	} else if emp.Health < 20 {
+		return nil, cerr.ErrNeedHealth
+	}
+
+	c.result = &SpellResult_t{Spell: spell.Name(), Target: req.Target, Cost: cost, Turns: turns}
+	emp.Runes -= cost
+	action := ACTION_MAGIC
+	if req.War {
+		action = ACTION_WAR
+	}
+	*emp, c.result.Report = e.TakeTurns(*emp, req.Tables.Modifiers(emp), TakeTurns_t{
+		Turns:         turns,
+		Action:        action,
+		Interruptable: true,
+		Wars:          req.Wars,
+		Round:         req.Round,
+		Effects:       c.Self.Effects,
+		Now:           req.Now,
+	})
+	// if we ran into trouble, the spell is not cast
+	if c.result.Report.Taken != turns {
+		c.result.Aborted = true
+		return c.result, nil
+	}
+	c.result.Success = cast(c)
+	return c.result, nil
+}
+
+// era returns the empire's current era. CastSpell has already verified it.
+func (c *Cast_t) era(p *Caster_t) *Era_t {
+	era, _ := c.tables.Era(p.Empire.Era)
+	return era
+}
+
+// magic returns the empire's magic modifier, including race, era, and effects.
+func (c *Cast_t) magic(p *Caster_t) float64 {
+	mods := c.tables.Modifiers(p.Empire)
+	if p.Effects != nil {
+		mods = mods.Add(p.Effects.Modifiers(c.now))
+	}
+	return Modifier(mods.Magic)
+}
+
+// shielded returns true if the target has an active spell shield.
+func (c *Cast_t) shielded() bool {
+	return c.Other.Effects != nil && c.Other.Effects.Active("m_shield", c.now)
+}
+
+// baseCost is the base cost for all spells, scales as empire size increases.
+func (c *Cast_t) baseCost() float64 {
+	emp := c.Self.Empire
+	return float64(emp.Land)*0.10 + 100 + float64(emp.BldWiz)*0.20*c.magic(c.Self)*c.e.SizeBonus(emp)
+}
+
+// cost returns the rune cost of a spell as a multiple of the base cost.
+func (c *Cast_t) cost(mult float64) int {
+	return int(math.Ceil(mult * c.baseCost()))
+}
+
+// powerSelf determines wizard power when casting spells on self.
+func (c *Cast_t) powerSelf() float64 {
+	emp := c.Self.Empire
+	return float64(emp.TrpWiz) * c.magic(c.Self) / float64(max(emp.BldWiz, 1))
+}
+
+// powerFriend determines wizard power when casting spells on a friend.
+func (c *Cast_t) powerFriend() float64 {
+	self, other := c.Self.Empire, c.Other.Empire
+	uratio := float64(self.TrpWiz) / (float64(self.Land+other.Land) / 2) * c.magic(c.Self)
+	eratio := float64(max(other.TrpWiz, 1)) / float64(max(other.Land, 1)) * c.magic(c.Other)
+	return uratio / eratio
+}
+
+// powerEnemy determines wizard power when casting spells on an enemy.
+func (c *Cast_t) powerEnemy() float64 {
+	self, other := c.Self.Empire, c.Other.Empire
+	uratio := float64(self.TrpWiz) / (float64(self.Land+other.Land) / 2) * c.magic(c.Self)
+	eratio := float64(max(other.TrpWiz, 1)) / float64(max(other.Land, 1)) * 1.05 * c.magic(c.Other)
+	return uratio / eratio
+}
+
+// wizLoss determines wizard loss when failing to cast a spell.
+func (c *Cast_t) wizLoss() int {
+	trpwiz := c.Self.Empire.TrpWiz
+	loss := c.e.randRange(int(math.Ceil(float64(trpwiz)*0.01)), int(math.Ceil(float64(trpwiz)*0.05+1)))
+	return min(loss, trpwiz)
+}
+
+// scorePoints returns the base number of points for casting a spell on the target.
+// Spells cast on yourself are not worth any points.
+func (c *Cast_t) scorePoints() int {
+	if c.Other == nil {
+		return 0
+	}
+	return c.e.ScorePoints(c.Self.Empire, c.Other.Empire)
+}
+
+// message adds a message for the player.
+func (c *Cast_t) message(key string, args ...any) {
+	c.result.Messages = append(c.result.Messages, Message_t{Key: key, Args: args})
+}
+
+// news adds a news event from the caster to the target.
+func (c *Cast_t) news(event int, data ...int) {
+	c.result.News = append(c.result.News, NewNews(event, c.Self.Empire, c.Other.Empire, data...))
+}
+
+// success records that the spell was cast successfully.
+func (c *Cast_t) success(key string, args ...any) {
+	c.result.Result = SPELLRESULT_SUCCESS
+	c.message("SPELL_GENERIC_SUCCESS")
+	if c.e.cfg.ScoreEnable {
+		points := int(math.Ceil(float64(c.scorePoints()) * c.spell.Points()))
+		if points > 0 {
+			c.Self.Empire.Score += points
+			c.Other.Empire.Score--
+			c.result.Points += points
+		}
+	}
+	c.message(key, args...)
+}
+
+// failed records that the spell fizzled and the caster lost wizards.
+func (c *Cast_t) failed(loss int) {
+	c.result.Failed, c.result.WizLoss = true, loss
+	c.message("SPELL_GENERIC_FAILED")
+	if c.e.cfg.ScoreEnable {
+		points := int(math.Ceil(float64(c.scorePoints()) * c.spell.Points() / 2))
+		if points > 0 {
+			c.Self.Empire.Score -= points
+			c.Other.Empire.Score++
+			c.result.Points -= points
+		}
+	}
+	c.message("SPELL_GENERIC_FAILED_LOSSES", loss, c.era(c.Self).TrpWiz)
+	c.Self.Empire.TrpWiz -= loss
+}
+
+// shield records that the spell was partially blocked by the target's shield.
+func (c *Cast_t) shield(key string, args ...any) {
+	c.result.Result = SPELLRESULT_SHIELDED
+	c.message("SPELL_GENERIC_SHIELDED")
+	if c.e.cfg.ScoreEnable && c.spell.Points() > 0 {
+		c.Self.Empire.Score++
+		c.Other.Empire.Score--
+		c.result.Points++
+	}
+	c.message(key, args...)
+}
+
+// offense records the outcome of an offensive spell in both empires' statistics.
+func (c *Cast_t) offense(succeeded bool) {
+	c.Self.Empire.OffTotal++
+	c.Other.Empire.DefTotal++
+	if succeeded {
+		c.Self.Empire.OffSucc++
+	} else {
+		c.Other.Empire.DefSucc++
+	}
+}
+
+// ceilPct returns pct percent of n, rounded up.
+func ceilPct(n int, pct float64) int {
+	return int(math.Ceil(float64(n) * pct))
+}
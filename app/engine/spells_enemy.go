@@ -0,0 +1,357 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package engine
+
+import (
+	"math"
+)
+
+// Spells which can be cast on enemies, from php/spells.
+// Unless noted, they are not allowed while the caster is under protection.
+
+// blastSpell eliminates a portion of the target's military.
+type blastSpell struct{}
+
+func (blastSpell) Name() string    { return "blast" }
+func (blastSpell) Event() int      { return EMPNEWS_MAGIC_BLAST }
+func (blastSpell) Points() float64 { return 0.2 }
+
+func (blastSpell) CostEnemy(c *Cast_t) int   { return c.cost(2.50) }
+func (blastSpell) TurnsEnemy(c *Cast_t) int  { return 2 }
+func (blastSpell) AllowEnemy(c *Cast_t) bool { return !c.e.IsProtected(c.Self.Empire, c.round) }
+
+func (blastSpell) CastEnemy(c *Cast_t) bool {
+	if c.powerEnemy() > 1.15 {
+		shielded, pct := c.shielded(), 0.03
+		if shielded {
+			pct = 0.01
+		}
+		other := c.Other.Empire
+		other.TrpArm -= ceilPct(other.TrpArm, pct)
+		other.TrpLnd -= ceilPct(other.TrpLnd, pct)
+		other.TrpFly -= ceilPct(other.TrpFly, pct)
+		other.TrpSea -= ceilPct(other.TrpSea, pct)
+		other.TrpWiz -= ceilPct(other.TrpWiz, pct)
+		if shielded {
+			c.shield("SPELL_BLAST_SHIELDED")
+		} else {
+			c.success("SPELL_BLAST_SUCCESS")
+		}
+		c.news(EMPNEWS_MAGIC_BLAST, c.result.Result)
+		c.offense(true)
+		return true
+	}
+	return c.repelled(EMPNEWS_MAGIC_BLAST)
+}
+
+// repelled records an enemy spell that fizzled.
+func (c *Cast_t) repelled(event int) bool {
+	loss := c.wizLoss()
+	c.failed(loss)
+	c.news(event, -loss)
+	c.offense(false)
+	return false
+}
+
+// fightSpell has the caster's wizards attack the target's wizards to capture land.
+type fightSpell struct{}
+
+func (fightSpell) Name() string    { return "fight" }
+func (fightSpell) Event() int      { return EMPNEWS_MAGIC_FIGHT }
+func (fightSpell) Points() float64 { return 1.0 }
+
+func (fightSpell) CostEnemy(c *Cast_t) int   { return c.cost(22.50) }
+func (fightSpell) TurnsEnemy(c *Cast_t) int  { return 2 }
+func (fightSpell) AllowEnemy(c *Cast_t) bool { return !c.e.IsProtected(c.Self.Empire, c.round) }
+
+// destroy removes buildings (or unused land) from the target.
+// Losses are 33% compared to normal attacks.
+func (fightSpell) destroy(c *Cast_t, bld *int, pcloss float64) int {
+	pcloss /= 3
+	var loss int
+	if *bld > 0 {
+		loss = c.e.randRange(1, int(math.Ceil(float64(*bld)*pcloss+2)))
+	}
+	loss = min(loss, *bld)
+	*bld -= loss
+	return loss
+}
+
+// losses returns the wizards lost by the caster and the target.
+func (fightSpell) losses(c *Cast_t, upct, epct float64) (uloss, eloss int) {
+	self, other := c.Self.Empire, c.Other.Empire
+	uloss = c.e.randRange(0, round(float64(self.TrpWiz)*upct+1))
+	eloss = c.e.randRange(0, round(float64(other.TrpWiz)*epct+1))
+	uloss = min(uloss, self.TrpWiz)
+	if eloss > 50*uloss {
+		eloss = c.e.randRange(0, 50*uloss+1) // to weaken MUF suiciders
+	}
+	eloss = min(eloss, other.TrpWiz)
+	self.TrpWiz -= uloss
+	other.TrpWiz -= eloss
+	return uloss, eloss
+}
+
+func (s fightSpell) CastEnemy(c *Cast_t) bool {
+	if c.powerSelf() < 50 {
+		loss := c.wizLoss()
+		c.failed(loss)
+		c.news(EMPNEWS_MAGIC_FIGHT, -loss)
+		return false
+	}
+	self, other := c.Self.Empire, c.Other.Empire
+	selfWiz, otherWiz := c.era(c.Self).TrpWiz, c.era(c.Other).TrpWiz
+	self.OffTotal++
+	other.DefTotal++
+	// this spell does not use success, since it only gives points when you break their defense
+	c.message("SPELL_FIGHT_ATTACKING", selfWiz, otherWiz, other)
+	if c.powerEnemy() > 2.2 {
+		uloss, eloss := s.losses(c, 0.05, 0.07)
+		var bldloss int
+		bldloss += s.destroy(c, &other.BldCash, 0.05)
+		bldloss += s.destroy(c, &other.BldPop, 0.07)
+		bldloss += s.destroy(c, &other.BldTrp, 0.07)
+		bldloss += s.destroy(c, &other.BldCost, 0.07)
+		bldloss += s.destroy(c, &other.BldFood, 0.08)
+		bldloss += s.destroy(c, &other.BldWiz, 0.07)
+		bldloss += s.destroy(c, &other.BldDef, 0.11)
+		bldloss += s.destroy(c, &other.Freeland, 0.10)
+		other.Land -= bldloss
+		self.Land += bldloss
+		self.Freeland += bldloss
+		if c.e.cfg.DropDelay != 0 {
+			_ = c.Self.Effects.Set("m_droptime", c.e.cfg.DropDelay, c.now)
+		}
+		c.result.Result = SPELLRESULT_SUCCESS
+		c.message("SPELL_FIGHT_SUCCESS_HEADER", other, selfWiz, bldloss)
+		if c.e.cfg.ScoreEnable {
+			points := int(math.Ceil(float64(c.scorePoints()) * s.Points()))
+			self.Score += points
+			other.Score--
+			c.result.Points += points
+		}
+		c.message("SPELL_FIGHT_SUCCESS_LOSSES", uloss, selfWiz, eloss, otherWiz)
+		if other.Land == 0 {
+			// if killed within 30 minutes of deletion, still give credit
+			if !other.Flags.Delete || c.now.Unix() < int64(other.Idle+60*30) {
+				c.message("MILITARY_SUCCESS_KILLED", other)
+				c.result.Killed = true
+				self.Kills++
+				other.KilledBy = self.Id
+				if c.e.cfg.ClanEnable {
+					other.KillClan = self.CId
+				}
+				if c.e.cfg.ScoreEnable {
+					points := max(round(float64(other.Score)/5), 100)
+					self.Score += points
+					c.result.Points += points
+				}
+			} else {
+				c.message("MILITARY_SUCCESS_KILLED_LATE", other)
+			}
+		}
+		c.news(EMPNEWS_MAGIC_FIGHT, bldloss, eloss, uloss)
+		if c.result.Killed {
+			c.news(EMPNEWS_MILITARY_KILL, 0)
+		}
+		self.OffSucc++
+		return true
+	}
+
+	uloss, eloss := s.losses(c, 0.08, 0.04)
+	c.result.Result = SPELLRESULT_NOEFFECT
+	c.message("SPELL_FIGHT_BLOCKED_HEADER", other, otherWiz)
+	if c.e.cfg.ScoreEnable {
+		points := int(math.Ceil(float64(c.scorePoints()) * s.Points() / 3))
+		self.Score -= points
+		other.Score++
+		c.result.Points -= points
+	}
+	c.message("SPELL_FIGHT_BLOCKED_LOSSES", uloss, selfWiz, eloss, otherWiz)
+	c.news(EMPNEWS_MAGIC_FIGHT, SPELLRESULT_NOEFFECT, eloss, uloss)
+	other.DefSucc++
+	return false // even though the cast was successful, the battle failed
+}
+
+// runesSpell destroys a portion of the target's runes.
+type runesSpell struct{}
+
+func (runesSpell) Name() string    { return "runes" }
+func (runesSpell) Event() int      { return EMPNEWS_MAGIC_RUNES }
+func (runesSpell) Points() float64 { return 0.4 }
+
+func (runesSpell) CostEnemy(c *Cast_t) int   { return c.cost(9.50) }
+func (runesSpell) TurnsEnemy(c *Cast_t) int  { return 2 }
+func (runesSpell) AllowEnemy(c *Cast_t) bool { return !c.e.IsProtected(c.Self.Empire, c.round) }
+
+func (runesSpell) CastEnemy(c *Cast_t) bool {
+	if c.powerEnemy() > 1.3 {
+		other := c.Other.Empire
+		if c.shielded() {
+			runes := ceilPct(other.Runes, 0.01)
+			other.Runes -= runes
+			c.shield("SPELL_RUNES_SHIELDED", runes, c.era(c.Other).Runes)
+			c.news(EMPNEWS_MAGIC_RUNES, SPELLRESULT_SHIELDED, runes)
+		} else {
+			runes := ceilPct(other.Runes, 0.03)
+			other.Runes -= runes
+			c.success("SPELL_RUNES_SUCCESS", runes, c.era(c.Other).Runes)
+			c.news(EMPNEWS_MAGIC_RUNES, SPELLRESULT_SUCCESS, runes)
+		}
+		c.offense(true)
+		return true
+	}
+	return c.repelled(EMPNEWS_MAGIC_RUNES)
+}
+
+// spySpell reveals the target's statistics. It does not count as an attack.
+// The caller is responsible for showing the statistics when the spell succeeds.
+type spySpell struct{}
+
+func (spySpell) Name() string    { return "spy" }
+func (spySpell) Event() int      { return EMPNEWS_MAGIC_SPY }
+func (spySpell) Points() float64 { return 0 }
+func (spySpell) Spy()            {}
+
+func (spySpell) CostEnemy(c *Cast_t) int   { return c.cost(1.00) }
+func (spySpell) TurnsEnemy(c *Cast_t) int  { return 2 }
+func (spySpell) AllowEnemy(c *Cast_t) bool { return !c.e.IsProtected(c.Self.Empire, c.round) }
+
+func (spySpell) CastEnemy(c *Cast_t) bool {
+	if c.powerEnemy() > 1 {
+		c.success("SPELL_SPY_SUCCESS")
+		c.news(EMPNEWS_MAGIC_SPY, SPELLRESULT_SUCCESS)
+		return true
+	}
+	loss := c.wizLoss()
+	c.failed(loss)
+	c.news(EMPNEWS_MAGIC_SPY, -loss)
+	return false
+}
+
+// stealSpell embezzles cash from the target's treasury.
+type stealSpell struct{}
+
+func (stealSpell) Name() string    { return "steal" }
+func (stealSpell) Event() int      { return EMPNEWS_MAGIC_STEAL }
+func (stealSpell) Points() float64 { return 0.15 }
+
+func (stealSpell) CostEnemy(c *Cast_t) int   { return c.cost(25.75) }
+func (stealSpell) TurnsEnemy(c *Cast_t) int  { return 2 }
+func (stealSpell) AllowEnemy(c *Cast_t) bool { return !c.e.IsProtected(c.Self.Empire, c.round) }
+
+func (stealSpell) CastEnemy(c *Cast_t) bool {
+	if c.powerEnemy() > 1.75 {
+		self, other := c.Self.Empire, c.Other.Empire
+		if c.shielded() {
+			cash := round(float64(other.Cash) / 100000 * float64(c.e.randRange(3000, 5000)))
+			other.Cash -= cash
+			self.Cash += cash
+			c.shield("SPELL_STEAL_SHIELDED", cash)
+			c.news(EMPNEWS_MAGIC_STEAL, SPELLRESULT_SHIELDED, cash)
+		} else {
+			cash := round(float64(other.Cash) / 100000 * float64(c.e.randRange(10000, 15000)))
+			other.Cash -= cash
+			self.Cash += cash
+			c.success("SPELL_STEAL_SUCCESS", cash)
+			c.news(EMPNEWS_MAGIC_STEAL, SPELLRESULT_SUCCESS, cash)
+		}
+		c.offense(true)
+		return true
+	}
+	return c.repelled(EMPNEWS_MAGIC_STEAL)
+}
+
+// stormSpell blows away a portion of the target's food and cash.
+type stormSpell struct{}
+
+func (stormSpell) Name() string    { return "storm" }
+func (stormSpell) Event() int      { return EMPNEWS_MAGIC_STORM }
+func (stormSpell) Points() float64 { return 0.3 }
+
+func (stormSpell) CostEnemy(c *Cast_t) int   { return c.cost(7.25) }
+func (stormSpell) TurnsEnemy(c *Cast_t) int  { return 2 }
+func (stormSpell) AllowEnemy(c *Cast_t) bool { return !c.e.IsProtected(c.Self.Empire, c.round) }
+
+func (stormSpell) CastEnemy(c *Cast_t) bool {
+	if c.powerEnemy() > 1.21 {
+		other := c.Other.Empire
+		if c.shielded() {
+			food, cash := ceilPct(other.Food, 0.0304), ceilPct(other.Cash, 0.0422)
+			other.Food -= food
+			other.Cash -= cash
+			c.shield("SPELL_STORM_SHIELDED", food, c.era(c.Other).Food, cash)
+			c.news(EMPNEWS_MAGIC_STORM, SPELLRESULT_SHIELDED, food, cash)
+		} else {
+			food, cash := ceilPct(other.Food, 0.0912), ceilPct(other.Cash, 0.1266)
+			other.Food -= food
+			other.Cash -= cash
+			c.success("SPELL_STORM_SUCCESS", food, c.era(c.Other).Food, cash)
+			c.news(EMPNEWS_MAGIC_STORM, SPELLRESULT_SUCCESS, food, cash)
+		}
+		c.offense(true)
+		return true
+	}
+	return c.repelled(EMPNEWS_MAGIC_STORM)
+}
+
+// structSpell destroys a portion of the target's buildings.
+type structSpell struct{}
+
+func (structSpell) Name() string    { return "struct" }
+func (structSpell) Event() int      { return EMPNEWS_MAGIC_STRUCT }
+func (structSpell) Points() float64 { return 0.5 }
+
+func (structSpell) CostEnemy(c *Cast_t) int   { return c.cost(18.00) }
+func (structSpell) TurnsEnemy(c *Cast_t) int  { return 2 }
+func (structSpell) AllowEnemy(c *Cast_t) bool { return !c.e.IsProtected(c.Self.Empire, c.round) }
+
+// destroy removes a percentage of a type of building, but only if the target
+// has at least one building of that type for every acres of land.
+func (structSpell) destroy(c *Cast_t, bld *int, pct float64, acres int) int {
+	other := c.Other.Empire
+	loss := ceilPct(*bld, pct)
+	if float64(*bld) < float64(other.Land)/float64(acres) {
+		return 0
+	}
+	*bld -= loss
+	other.Freeland += loss
+	return loss
+}
+
+func (s structSpell) CastEnemy(c *Cast_t) bool {
+	if c.powerEnemy() > 1.7 {
+		other := c.Other.Empire
+		shielded, pct := c.shielded(), 0.03
+		if shielded {
+			pct = 0.01
+		}
+		var build int
+		build += s.destroy(c, &other.BldCash, pct, 100)
+		build += s.destroy(c, &other.BldPop, pct, 100)
+		build += s.destroy(c, &other.BldTrp, pct, 100)
+		build += s.destroy(c, &other.BldCost, pct, 100)
+		build += s.destroy(c, &other.BldFood, pct, 100)
+		build += s.destroy(c, &other.BldWiz, pct, 100)
+		build += s.destroy(c, &other.BldDef, pct, 150)
+		switch {
+		case build == 0 && shielded:
+			c.shield("SPELL_STRUCT_NOEFFECT")
+		case build == 0:
+			c.success("SPELL_STRUCT_NOEFFECT")
+		case shielded:
+			c.shield("SPELL_STRUCT_SHIELDED", build)
+		default:
+			c.success("SPELL_STRUCT_SUCCESS", build)
+		}
+		if build == 0 {
+			c.result.Result = SPELLRESULT_NOEFFECT
+			c.news(EMPNEWS_MAGIC_STRUCT, SPELLRESULT_NOEFFECT)
+		} else {
+			c.news(EMPNEWS_MAGIC_STRUCT, c.result.Result, build)
+			c.offense(true)
+		}
+		return true
+	}
+	return c.repelled(EMPNEWS_MAGIC_STRUCT)
+}
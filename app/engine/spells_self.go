@@ -0,0 +1,197 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package engine
+
+import (
+	"math"
+)
+
+// Spells which can only be cast on yourself, from php/spells.
+
+// advanceSpell moves the empire to the next era.
+type advanceSpell struct{}
+
+func (advanceSpell) Name() string    { return "advance" }
+func (advanceSpell) Event() int      { return EMPNEWS_MAGIC_ADVANCE }
+func (advanceSpell) Points() float64 { return 0 }
+
+func (advanceSpell) CostSelf(c *Cast_t) int  { return c.cost(47.50) }
+func (advanceSpell) TurnsSelf(c *Cast_t) int { return 2 }
+
+func (advanceSpell) AllowSelf(c *Cast_t) bool {
+	// make sure there's an era to advance to,
+	// and can't advance until you've spent enough time in your current era
+	return c.era(c.Self).Next != 0 && !c.Self.Effects.Active("r_newera", c.now)
+}
+
+func (advanceSpell) CastSelf(c *Cast_t) bool {
+	if c.powerSelf() >= 90 {
+		c.success("SPELL_ADVANCE_SUCCESS")
+		c.Self.Empire.Era = c.era(c.Self).Next
+		_ = c.Self.Effects.Set("r_newera", c.e.cfg.TurnsEra, c.now)
+		return true
+	}
+	c.failed(c.wizLoss())
+	return false
+}
+
+// cashSpell creates cash.
+type cashSpell struct{}
+
+func (cashSpell) Name() string    { return "cash" }
+func (cashSpell) Event() int      { return EMPNEWS_MAGIC_CASH }
+func (cashSpell) Points() float64 { return 0 }
+
+func (cashSpell) CostSelf(c *Cast_t) int   { return c.cost(17.50) }
+func (cashSpell) TurnsSelf(c *Cast_t) int  { return 2 }
+func (cashSpell) AllowSelf(c *Cast_t) bool { return true }
+
+func (cashSpell) CastSelf(c *Cast_t) bool {
+	if c.powerSelf() >= 30 {
+		cash := round(c.conjured())
+		c.Self.Empire.Cash += cash
+		c.success("SPELL_CASH_SUCCESS", cash)
+		return true
+	}
+	c.failed(c.wizLoss())
+	return false
+}
+
+// foodSpell creates food.
+type foodSpell struct{}
+
+func (foodSpell) Name() string    { return "food" }
+func (foodSpell) Event() int      { return EMPNEWS_MAGIC_FOOD }
+func (foodSpell) Points() float64 { return 0 }
+
+func (foodSpell) CostSelf(c *Cast_t) int   { return c.cost(17.00) }
+func (foodSpell) TurnsSelf(c *Cast_t) int  { return 2 }
+func (foodSpell) AllowSelf(c *Cast_t) bool { return true }
+
+func (foodSpell) CastSelf(c *Cast_t) bool {
+	if c.powerSelf() >= 30 {
+		food := round(c.conjured() / float64(max(c.e.cfg.PvtmFood, 1)))
+		c.Self.Empire.Food += food
+		c.success("SPELL_FOOD_SUCCESS", food, c.era(c.Self).Food)
+		return true
+	}
+	c.failed(c.wizLoss())
+	return false
+}
+
+// conjured returns the value of the cash (or food, after dividing by its
+// market price) created by the caster's wizards.
+func (c *Cast_t) conjured() float64 {
+	emp := c.Self.Empire
+	size := c.e.SizeBonus(emp)
+	return float64(emp.TrpWiz) * (float64(emp.Health) / 100) * 65 * (1 + math.Sqrt(float64(emp.BldWiz)/float64(max(emp.Land, 1)))/2) * c.magic(c.Self) / (size * size)
+}
+
+// gateSpell opens a time gate, allowing the empire to interact with empires in other eras.
+type gateSpell struct{}
+
+func (gateSpell) Name() string    { return "gate" }
+func (gateSpell) Event() int      { return EMPNEWS_MAGIC_GATE }
+func (gateSpell) Points() float64 { return 0 }
+
+func (gateSpell) CostSelf(c *Cast_t) int   { return c.cost(20.00) }
+func (gateSpell) TurnsSelf(c *Cast_t) int  { return 2 }
+func (gateSpell) AllowSelf(c *Cast_t) bool { return true }
+
+func (gateSpell) CastSelf(c *Cast_t) bool {
+	if c.powerSelf() >= 75 {
+		c.extend("m_gate", "SPELL_GATE_CREATE", "SPELL_GATE_RENEW", "SPELL_GATE_EXTEND")
+		return true
+	}
+	c.failed(c.wizLoss())
+	return false
+}
+
+// regressSpell moves the empire to the previous era.
+type regressSpell struct{}
+
+func (regressSpell) Name() string    { return "regress" }
+func (regressSpell) Event() int      { return EMPNEWS_MAGIC_REGRESS }
+func (regressSpell) Points() float64 { return 0 }
+
+func (regressSpell) CostSelf(c *Cast_t) int  { return c.cost(47.50) }
+func (regressSpell) TurnsSelf(c *Cast_t) int { return 2 }
+
+func (regressSpell) AllowSelf(c *Cast_t) bool {
+	// make sure there's an era to regress to,
+	// and can't regress until you've spent enough time in your current era
+	return c.era(c.Self).Prev != 0 && !c.Self.Effects.Active("r_newera", c.now)
+}
+
+func (regressSpell) CastSelf(c *Cast_t) bool {
+	if c.powerSelf() >= 90 {
+		c.success("SPELL_REGRESS_SUCCESS")
+		c.Self.Empire.Era = c.era(c.Self).Prev
+		_ = c.Self.Effects.Set("r_newera", c.e.cfg.TurnsEra, c.now)
+		return true
+	}
+	c.failed(c.wizLoss())
+	return false
+}
+
+// shieldSpell protects the empire from enemy spells.
+type shieldSpell struct{}
+
+func (shieldSpell) Name() string    { return "shield" }
+func (shieldSpell) Event() int      { return EMPNEWS_MAGIC_SHIELD }
+func (shieldSpell) Points() float64 { return 0 }
+
+func (shieldSpell) CostSelf(c *Cast_t) int   { return c.cost(4.90) }
+func (shieldSpell) TurnsSelf(c *Cast_t) int  { return 2 }
+func (shieldSpell) AllowSelf(c *Cast_t) bool { return true }
+
+func (shieldSpell) CastSelf(c *Cast_t) bool {
+	if c.powerSelf() >= 15 {
+		c.extend("m_shield", "SPELL_SHIELD_CREATE", "SPELL_SHIELD_RENEW", "SPELL_SHIELD_EXTEND")
+		return true
+	}
+	c.failed(c.wizLoss())
+	return false
+}
+
+// extend creates a 12 hour timed effect on the caster. An effect with less
+// than 9 hours remaining is renewed to 12 hours, otherwise it is extended
+// by another 3 hours.
+func (c *Cast_t) extend(name, create, renew, extend string) {
+	remaining := c.Self.Effects.Get(name, c.now)
+	if remaining == 0 {
+		c.success(create)
+		_ = c.Self.Effects.Set(name, 3600*12, c.now)
+	} else if remaining < 3600*9 {
+		c.success(renew)
+		_ = c.Self.Effects.Set(name, 3600*12, c.now)
+	} else {
+		c.success(extend)
+		_ = c.Self.Effects.Set(name, remaining+3600*3, c.now)
+	}
+}
+
+// ungateSpell closes a time gate.
+type ungateSpell struct{}
+
+func (ungateSpell) Name() string    { return "ungate" }
+func (ungateSpell) Event() int      { return EMPNEWS_MAGIC_UNGATE }
+func (ungateSpell) Points() float64 { return 0 }
+
+func (ungateSpell) CostSelf(c *Cast_t) int  { return c.cost(14.50) }
+func (ungateSpell) TurnsSelf(c *Cast_t) int { return 2 }
+
+func (ungateSpell) AllowSelf(c *Cast_t) bool {
+	// can't close a time gate you don't have open
+	return c.Self.Effects.Active("m_gate", c.now)
+}
+
+func (ungateSpell) CastSelf(c *Cast_t) bool {
+	if c.powerSelf() >= 80 {
+		c.success("SPELL_UNGATE_SUCCESS")
+		c.Self.Effects.Clear("m_gate")
+		return true
+	}
+	c.failed(c.wizLoss())
+	return false
+}
@@ -0,0 +1,417 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package engine
+
+import (
+	"errors"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/model"
+	"testing"
+	"time"
+)
+
+var spellTime = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// spellCasters returns a caster and a target in the same era.
+// A strong caster overwhelms the target's wizards; a weak one does not.
+func spellCasters(tables *Tables_t, strong bool) (*Caster_t, *Caster_t) {
+	self, other := testEmpire(), testEmpire()
+	self.Race, self.Era, other.Race, other.Era = RACE_HUMAN, ERA_PRESENT, RACE_HUMAN, ERA_PRESENT
+	other.Id = 2
+	self.Runes, other.Runes = 1_000_000, 10_000
+	self.BldWiz, other.BldWiz = 100, 100
+	self.NetWorth, other.NetWorth = 150_000, 150_000
+	if strong {
+		self.TrpWiz, other.TrpWiz = 10_000, 10
+	} else {
+		self.TrpWiz, other.TrpWiz = 100, 10_000
+	}
+	return &Caster_t{Empire: &self, Effects: NewEffects(self.Id, nil, tables.Effects)},
+		&Caster_t{Empire: &other, Effects: NewEffects(other.Id, nil, tables.Effects)}
+}
+
+func TestSpellRegistry(t *testing.T) {
+	if got := len(New(testConfig, nil).Spells()); got != 13 {
+		t.Errorf("spells: want 13, got %d", got)
+	}
+	if _, err := New(testConfig, nil).Spell("regress"); !errors.Is(err, cerr.ErrUnknownSpell) {
+		t.Errorf("regress: want %v, got %v", cerr.ErrUnknownSpell, err)
+	}
+	cfg := testConfig
+	cfg.MagicAllowRegress = true
+	e := New(cfg, nil)
+	if got := len(e.Spells()); got != 14 {
+		t.Errorf("spells: allow regress: want 14, got %d", got)
+	}
+	for _, spell := range e.Spells() {
+		if _, ok := DefaultTables().Eras[ERA_PAST].Spells[spell.Name()]; !ok {
+			t.Errorf("%s: missing from era spell names", spell.Name())
+		}
+		_, self := spell.(SelfSpell_i)
+		_, enemy := spell.(EnemySpell_i)
+		if self == enemy {
+			t.Errorf("%s: want exactly one of self or enemy", spell.Name())
+		}
+	}
+	if spell, _ := e.Spell("spy"); spell == nil {
+		t.Errorf("spy: not found")
+	} else if _, ok := spell.(SpySpell_i); !ok {
+		t.Errorf("spy: want SpySpell_i")
+	}
+}
+
+func TestCastSpellErrors(t *testing.T) {
+	tables := DefaultTables()
+	for _, tc := range []struct {
+		name   string
+		cfg    func(*Config_t)
+		spell  string
+		target Target_t
+		setup  func(self, other *Caster_t, round *model.RoundData_t)
+		want   error
+	}{
+		{name: "unknown", spell: "teleport", target: TARGET_SELF, want: cerr.ErrUnknownSpell},
+		{name: "friend disabled", spell: "shield", target: TARGET_FRIEND, want: cerr.ErrFriendMagicDisabled},
+		{name: "friend enabled", cfg: func(c *Config_t) { c.FriendMagicEnable = true }, spell: "shield", target: TARGET_FRIEND, want: cerr.ErrSpellTarget},
+		{name: "self on enemy", spell: "shield", target: TARGET_ENEMY, want: cerr.ErrSpellTarget},
+		{name: "enemy on self", spell: "blast", target: TARGET_SELF, want: cerr.ErrSpellTarget},
+		{name: "ungate without gate", spell: "ungate", target: TARGET_SELF, want: cerr.ErrSpellNotAllowed},
+		{name: "advance too soon", spell: "advance", target: TARGET_SELF, want: cerr.ErrSpellNotAllowed,
+			setup: func(self, _ *Caster_t, _ *model.RoundData_t) { _ = self.Effects.Set("r_newera", 10, spellTime) }},
+		{name: "advance past last era", spell: "advance", target: TARGET_SELF, want: cerr.ErrSpellNotAllowed,
+			setup: func(self, _ *Caster_t, _ *model.RoundData_t) { self.Empire.Era = ERA_FUTURE }},
+		{name: "protected", spell: "blast", target: TARGET_ENEMY, want: cerr.ErrSpellNotAllowed,
+			setup: func(_, _ *Caster_t, round *model.RoundData_t) { round.Signup = true }},
+		{name: "no wizards", spell: "shield", target: TARGET_SELF, want: cerr.ErrNeedWizards,
+			setup: func(self, _ *Caster_t, _ *model.RoundData_t) { self.Empire.TrpWiz = 0 }},
+		{name: "no runes", spell: "shield", target: TARGET_SELF, want: cerr.ErrNeedRunes,
+			setup: func(self, _ *Caster_t, _ *model.RoundData_t) { self.Empire.Runes = 10 }},
+		{name: "no turns", spell: "shield", target: TARGET_SELF, want: cerr.ErrNeedTurns,
+			setup: func(self, _ *Caster_t, _ *model.RoundData_t) { self.Empire.Turns = 1 }},
+		{name: "no health", spell: "shield", target: TARGET_SELF, want: cerr.ErrNeedHealth,
+			setup: func(self, _ *Caster_t, _ *model.RoundData_t) { self.Empire.Health = 19 }},
+	} {
+		cfg := testConfig
+		if tc.cfg != nil {
+			tc.cfg(&cfg)
+		}
+		self, other := spellCasters(tables, true)
+		var round model.RoundData_t
+		if tc.setup != nil {
+			tc.setup(self, other, &round)
+		}
+		before := *self.Empire
+		_, err := New(cfg, nil).CastSpell(CastSpell_t{Spell: tc.spell, Target: tc.target, Self: self, Other: other, Tables: tables, Round: round, Now: spellTime})
+		if !errors.Is(err, tc.want) {
+			t.Errorf("%s: want %v, got %v", tc.name, tc.want, err)
+		}
+		if *self.Empire != before {
+			t.Errorf("%s: caster was changed", tc.name)
+		}
+	}
+}
+
+func TestSelfSpellCost(t *testing.T) {
+	tables := DefaultTables()
+	e := New(testConfig, nil)
+	self, _ := spellCasters(tables, true)
+	cost, turns, allowed, err := e.SelfSpellCost("advance", self, tables, spellTime)
+	if err != nil {
+		t.Fatalf("advance: %v", err)
+	} else if cost <= 0 || turns != 2 || !allowed {
+		t.Errorf("advance: got cost %d, turns %d, allowed %v", cost, turns, allowed)
+	}
+	res, err := e.CastSpell(CastSpell_t{Spell: "advance", Target: TARGET_SELF, Self: self, Tables: tables, Now: spellTime})
+	if err != nil {
+		t.Fatalf("cast: %v", err)
+	} else if res.Cost != cost {
+		t.Errorf("cast: want cost %d, got %d", cost, res.Cost)
+	}
+	if _, _, allowed, err := e.SelfSpellCost("advance", self, tables, spellTime); err != nil || allowed {
+		t.Errorf("advance again: want not allowed, got %v, %v", allowed, err)
+	}
+	if _, _, _, err := e.SelfSpellCost("blast", self, tables, spellTime); !errors.Is(err, cerr.ErrSpellTarget) {
+		t.Errorf("blast: want %v, got %v", cerr.ErrSpellTarget, err)
+	}
+}
+
+func TestEraReachable(t *testing.T) {
+	tables := DefaultTables()
+	e := New(testConfig, nil)
+	self, other := spellCasters(tables, true)
+	if !e.EraReachable(self.Empire, other.Empire, self.Effects, nil, spellTime) {
+		t.Errorf("same era: want reachable")
+	}
+	other.Empire.Era = ERA_FUTURE
+	if e.EraReachable(self.Empire, other.Empire, self.Effects, other.Effects, spellTime) {
+		t.Errorf("other era: want unreachable")
+	}
+	_ = other.Effects.Set("m_gate", 3600, spellTime)
+	if !e.EraReachable(self.Empire, other.Empire, self.Effects, other.Effects, spellTime) {
+		t.Errorf("other era, target gated: want reachable")
+	} else if e.EraReachable(self.Empire, other.Empire, self.Effects, other.Effects, spellTime.Add(2*time.Hour)) {
+		t.Errorf("other era, gate expired: want unreachable")
+	}
+}
+
+func TestCastSpell(t *testing.T) {
+	tables := DefaultTables()
+	type check_t func(t *testing.T, self, other *Caster_t, before [2]model.Empire_t, res *SpellResult_t)
+	const failed = -1
+	for _, tc := range []struct {
+		name   string
+		spell  string
+		target Target_t
+		strong bool
+		setup  func(self, other *Caster_t)
+		result int // SPELLRESULT_* or failed
+		event  int // news event, zero for none
+		msg    string
+		check  check_t
+	}{
+		// self spells
+		{name: "shield create", spell: "shield", target: TARGET_SELF, strong: true, result: SPELLRESULT_SUCCESS, msg: "SPELL_SHIELD_CREATE",
+			check: wantEffect("m_shield", 12*3600)},
+		{name: "shield renew", spell: "shield", target: TARGET_SELF, strong: true, result: SPELLRESULT_SUCCESS, msg: "SPELL_SHIELD_RENEW",
+			setup: setEffect("m_shield", 3600), check: wantEffect("m_shield", 12*3600)},
+		{name: "shield extend", spell: "shield", target: TARGET_SELF, strong: true, result: SPELLRESULT_SUCCESS, msg: "SPELL_SHIELD_EXTEND",
+			setup: setEffect("m_shield", 10*3600), check: wantEffect("m_shield", 13*3600)},
+		{name: "shield failed", spell: "shield", target: TARGET_SELF, result: failed, check: wantEffect("m_shield", 0)},
+		{name: "gate create", spell: "gate", target: TARGET_SELF, strong: true, result: SPELLRESULT_SUCCESS, msg: "SPELL_GATE_CREATE",
+			check: wantEffect("m_gate", 12*3600)},
+		{name: "gate failed", spell: "gate", target: TARGET_SELF, result: failed, check: wantEffect("m_gate", 0)},
+		{name: "ungate", spell: "ungate", target: TARGET_SELF, strong: true, result: SPELLRESULT_SUCCESS, msg: "SPELL_UNGATE_SUCCESS",
+			setup: setEffect("m_gate", 3600), check: wantEffect("m_gate", 0)},
+		{name: "ungate failed", spell: "ungate", target: TARGET_SELF, result: failed,
+			setup: setEffect("m_gate", 3600), check: wantEffect("m_gate", 3600)},
+		{name: "cash", spell: "cash", target: TARGET_SELF, strong: true, result: SPELLRESULT_SUCCESS, msg: "SPELL_CASH_SUCCESS",
+			check: func(t *testing.T, self, _ *Caster_t, before [2]model.Empire_t, res *SpellResult_t) {
+				if cash := res.Messages[1].Args[0].(int); cash <= 0 {
+					t.Errorf("cash: want > 0, got %d", cash)
+				}
+			}},
+		{name: "cash failed", spell: "cash", target: TARGET_SELF, result: failed},
+		{name: "food", spell: "food", target: TARGET_SELF, strong: true, result: SPELLRESULT_SUCCESS, msg: "SPELL_FOOD_SUCCESS",
+			check: func(t *testing.T, self, _ *Caster_t, before [2]model.Empire_t, res *SpellResult_t) {
+				if food := res.Messages[1].Args[0].(int); food <= 0 || res.Messages[1].Args[1] != "ERA_PRESENT_FOOD" {
+					t.Errorf("food: got %v", res.Messages[1].Args)
+				}
+			}},
+		{name: "food failed", spell: "food", target: TARGET_SELF, result: failed},
+		{name: "advance", spell: "advance", target: TARGET_SELF, strong: true, result: SPELLRESULT_SUCCESS, msg: "SPELL_ADVANCE_SUCCESS",
+			check: func(t *testing.T, self, _ *Caster_t, before [2]model.Empire_t, res *SpellResult_t) {
+				if self.Empire.Era != ERA_FUTURE || self.Effects.Get("r_newera", spellTime) != testConfig.TurnsEra {
+					t.Errorf("advance: got era %d, r_newera %d", self.Empire.Era, self.Effects.Get("r_newera", spellTime))
+				}
+			}},
+		{name: "advance failed", spell: "advance", target: TARGET_SELF, result: failed,
+			check: func(t *testing.T, self, _ *Caster_t, before [2]model.Empire_t, res *SpellResult_t) {
+				if self.Empire.Era != ERA_PRESENT {
+					t.Errorf("advance: got era %d", self.Empire.Era)
+				}
+			}},
+		{name: "regress", spell: "regress", target: TARGET_SELF, strong: true, result: SPELLRESULT_SUCCESS, msg: "SPELL_REGRESS_SUCCESS",
+			check: func(t *testing.T, self, _ *Caster_t, before [2]model.Empire_t, res *SpellResult_t) {
+				if self.Empire.Era != ERA_PAST {
+					t.Errorf("regress: got era %d", self.Empire.Era)
+				}
+			}},
+		{name: "regress failed", spell: "regress", target: TARGET_SELF, result: failed},
+
+		// enemy spells
+		{name: "spy", spell: "spy", target: TARGET_ENEMY, strong: true, result: SPELLRESULT_SUCCESS, event: EMPNEWS_MAGIC_SPY, msg: "SPELL_SPY_SUCCESS",
+			check: func(t *testing.T, self, other *Caster_t, before [2]model.Empire_t, res *SpellResult_t) {
+				if self.Empire.OffTotal != 0 || other.Empire.DefTotal != 0 {
+					t.Errorf("spy: counted as an attack")
+				}
+			}},
+		{name: "spy failed", spell: "spy", target: TARGET_ENEMY, result: failed, event: EMPNEWS_MAGIC_SPY},
+		{name: "blast", spell: "blast", target: TARGET_ENEMY, strong: true, result: SPELLRESULT_SUCCESS, event: EMPNEWS_MAGIC_BLAST, msg: "SPELL_BLAST_SUCCESS",
+			check: wantOther(func(e *model.Empire_t) int { return e.TrpArm }, 97)},
+		{name: "blast shielded", spell: "blast", target: TARGET_ENEMY, strong: true, result: SPELLRESULT_SHIELDED, event: EMPNEWS_MAGIC_BLAST, msg: "SPELL_BLAST_SHIELDED",
+			setup: shieldOther, check: wantOther(func(e *model.Empire_t) int { return e.TrpArm }, 99)},
+		{name: "blast failed", spell: "blast", target: TARGET_ENEMY, result: failed, event: EMPNEWS_MAGIC_BLAST,
+			check: wantOther(func(e *model.Empire_t) int { return e.TrpArm }, 100)},
+		{name: "storm", spell: "storm", target: TARGET_ENEMY, strong: true, result: SPELLRESULT_SUCCESS, event: EMPNEWS_MAGIC_STORM, msg: "SPELL_STORM_SUCCESS",
+			check: wantOther(func(e *model.Empire_t) int { return e.Food*1_000_000 + e.Cash }, 9088*1_000_000+87340)},
+		{name: "storm shielded", spell: "storm", target: TARGET_ENEMY, strong: true, result: SPELLRESULT_SHIELDED, event: EMPNEWS_MAGIC_STORM, msg: "SPELL_STORM_SHIELDED",
+			setup: shieldOther, check: wantOther(func(e *model.Empire_t) int { return e.Food*1_000_000 + e.Cash }, 9696*1_000_000+95780)},
+		{name: "storm failed", spell: "storm", target: TARGET_ENEMY, result: failed, event: EMPNEWS_MAGIC_STORM},
+		{name: "runes", spell: "runes", target: TARGET_ENEMY, strong: true, result: SPELLRESULT_SUCCESS, event: EMPNEWS_MAGIC_RUNES, msg: "SPELL_RUNES_SUCCESS",
+			check: wantOther(func(e *model.Empire_t) int { return e.Runes }, 9700)},
+		{name: "runes shielded", spell: "runes", target: TARGET_ENEMY, strong: true, result: SPELLRESULT_SHIELDED, event: EMPNEWS_MAGIC_RUNES, msg: "SPELL_RUNES_SHIELDED",
+			setup: shieldOther, check: wantOther(func(e *model.Empire_t) int { return e.Runes }, 9900)},
+		{name: "runes failed", spell: "runes", target: TARGET_ENEMY, result: failed, event: EMPNEWS_MAGIC_RUNES},
+		{name: "struct", spell: "struct", target: TARGET_ENEMY, strong: true, result: SPELLRESULT_SUCCESS, event: EMPNEWS_MAGIC_STRUCT, msg: "SPELL_STRUCT_SUCCESS",
+			check: wantOther(func(e *model.Empire_t) int { return e.Freeland }, 207)},
+		{name: "struct shielded", spell: "struct", target: TARGET_ENEMY, strong: true, result: SPELLRESULT_SHIELDED, event: EMPNEWS_MAGIC_STRUCT, msg: "SPELL_STRUCT_SHIELDED",
+			setup: shieldOther, check: wantOther(func(e *model.Empire_t) int { return e.Freeland }, 205)},
+		{name: "struct no effect", spell: "struct", target: TARGET_ENEMY, strong: true, result: SPELLRESULT_NOEFFECT, event: EMPNEWS_MAGIC_STRUCT, msg: "SPELL_STRUCT_NOEFFECT",
+			setup: func(_, other *Caster_t) {
+				other.Empire.BldPop, other.Empire.BldCash, other.Empire.BldCost, other.Empire.BldFood, other.Empire.BldWiz = 0, 0, 0, 0, 0
+			},
+			check: wantOther(func(e *model.Empire_t) int { return e.Freeland }, 200)},
+		{name: "struct failed", spell: "struct", target: TARGET_ENEMY, result: failed, event: EMPNEWS_MAGIC_STRUCT},
+		{name: "steal", spell: "steal", target: TARGET_ENEMY, strong: true, result: SPELLRESULT_SUCCESS, event: EMPNEWS_MAGIC_STEAL, msg: "SPELL_STEAL_SUCCESS",
+			check: wantStolen(10_000, 15_000)},
+		{name: "steal shielded", spell: "steal", target: TARGET_ENEMY, strong: true, result: SPELLRESULT_SHIELDED, event: EMPNEWS_MAGIC_STEAL, msg: "SPELL_STEAL_SHIELDED",
+			setup: shieldOther, check: wantStolen(3_000, 5_000)},
+		{name: "steal failed", spell: "steal", target: TARGET_ENEMY, result: failed, event: EMPNEWS_MAGIC_STEAL,
+			check: wantOther(func(e *model.Empire_t) int { return e.Cash }, 100_000)},
+		{name: "fight", spell: "fight", target: TARGET_ENEMY, strong: true, result: SPELLRESULT_SUCCESS, event: EMPNEWS_MAGIC_FIGHT, msg: "SPELL_FIGHT_ATTACKING",
+			check: func(t *testing.T, self, other *Caster_t, before [2]model.Empire_t, res *SpellResult_t) {
+				taken := res.News[0].Data[0]
+				if taken <= 0 || other.Empire.Land != before[1].Land-taken || self.Empire.Land != before[0].Land+taken {
+					t.Errorf("fight: taken %d, land %d and %d", taken, self.Empire.Land, other.Empire.Land)
+				}
+				if self.Effects.Get("m_droptime", spellTime) != testConfig.DropDelay {
+					t.Errorf("fight: droptime: got %d", self.Effects.Get("m_droptime", spellTime))
+				}
+				if self.Empire.OffSucc != 1 || self.Empire.OffTotal != 1 || other.Empire.DefTotal != 1 {
+					t.Errorf("fight: got offsucc %d, offtotal %d, deftotal %d", self.Empire.OffSucc, self.Empire.OffTotal, other.Empire.DefTotal)
+				}
+			}},
+		{name: "fight blocked", spell: "fight", target: TARGET_ENEMY, strong: true, result: SPELLRESULT_NOEFFECT, event: EMPNEWS_MAGIC_FIGHT, msg: "SPELL_FIGHT_ATTACKING",
+			setup: func(_, other *Caster_t) { other.Empire.TrpWiz = 1_000_000 },
+			check: func(t *testing.T, self, other *Caster_t, before [2]model.Empire_t, res *SpellResult_t) {
+				if res.Success || other.Empire.Land != before[1].Land || other.Empire.DefSucc != 1 {
+					t.Errorf("fight: blocked: got success %v, land %d, defsucc %d", res.Success, other.Empire.Land, other.Empire.DefSucc)
+				}
+			}},
+		{name: "fight failed", spell: "fight", target: TARGET_ENEMY, result: failed, event: EMPNEWS_MAGIC_FIGHT,
+			setup: func(self, _ *Caster_t) { self.Empire.TrpWiz = 1_000 }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := testConfig
+			cfg.MagicAllowRegress = true
+			self, other := spellCasters(tables, tc.strong)
+			if tc.setup != nil {
+				tc.setup(self, other)
+			}
+			before := [2]model.Empire_t{*self.Empire, *other.Empire}
+			req := CastSpell_t{Spell: tc.spell, Target: tc.target, Self: self, Tables: tables, Now: spellTime}
+			if tc.target == TARGET_ENEMY {
+				req.Other = other
+			}
+			res, err := New(cfg, nil).CastSpell(req)
+			if err != nil {
+				t.Fatalf("cast: %v", err)
+			}
+			if res.Aborted || res.Report.Taken != 2 || self.Empire.Turns != before[0].Turns-2 || self.Empire.Runes != before[0].Runes-res.Cost+res.Report.Overall.Runes {
+				t.Errorf("cost: got aborted %v, taken %d, turns %d, runes %d", res.Aborted, res.Report.Taken, self.Empire.Turns, self.Empire.Runes)
+			}
+			if tc.result == failed {
+				if !res.Failed || res.Success || res.WizLoss <= 0 || res.Messages[0].Key != "SPELL_GENERIC_FAILED" {
+					t.Errorf("failed: got %+v", res)
+				}
+				if want := before[0].TrpWiz + res.Report.Overall.TrpWiz - res.WizLoss; self.Empire.TrpWiz != want {
+					t.Errorf("failed: trpwiz: want %d, got %d", want, self.Empire.TrpWiz)
+				}
+			} else {
+				if res.Failed || res.Result != tc.result {
+					t.Errorf("result: want %d, got %d (failed %v)", tc.result, res.Result, res.Failed)
+				}
+				if !hasMessage(res, tc.msg) {
+					t.Errorf("messages: want %s, got %+v", tc.msg, res.Messages)
+				}
+			}
+			if tc.event == 0 {
+				if len(res.News) != 0 {
+					t.Errorf("news: want none, got %+v", res.News)
+				}
+			} else if len(res.News) == 0 {
+				t.Errorf("news: want %d, got none", tc.event)
+			} else {
+				n := res.News[0]
+				want := tc.result
+				if tc.result == failed {
+					want = -res.WizLoss
+				} else if tc.event == EMPNEWS_MAGIC_FIGHT && tc.result == SPELLRESULT_SUCCESS {
+					want = n.Data[0] // acres taken
+				}
+				if n.Event != tc.event || n.Source != self.Empire.Id || n.Target != other.Empire.Id || n.Data[0] != want {
+					t.Errorf("news: want event %d result %d, got %+v", tc.event, want, n)
+				}
+			}
+			if tc.check != nil {
+				tc.check(t, self, other, before, res)
+			}
+		})
+	}
+}
+
+func TestCastSpellScore(t *testing.T) {
+	tables := DefaultTables()
+	cfg := testConfig
+	cfg.ScoreEnable = true
+	for _, tc := range []struct {
+		name   string
+		strong bool
+		shield bool
+		want   int
+	}{
+		{"success", true, false, 1},  // ceil(1 * 0.2)
+		{"shielded", true, true, 1},  // one point for a shielded spell
+		{"failed", false, false, -1}, // ceil(1 * 0.2 / 2)
+	} {
+		self, other := spellCasters(tables, tc.strong)
+		if tc.shield {
+			shieldOther(self, other)
+		}
+		res, err := New(cfg, nil).CastSpell(CastSpell_t{Spell: "blast", Target: TARGET_ENEMY, Self: self, Other: other, Tables: tables, Now: spellTime})
+		if err != nil {
+			t.Fatalf("%s: cast: %v", tc.name, err)
+		}
+		if res.Points != tc.want || self.Empire.Score != tc.want || other.Empire.Score != -tc.want {
+			t.Errorf("%s: want %d points, got %d (scores %d and %d)", tc.name, tc.want, res.Points, self.Empire.Score, other.Empire.Score)
+		}
+	}
+}
+
+func hasMessage(res *SpellResult_t, key string) bool {
+	for _, msg := range res.Messages {
+		if msg.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func setEffect(name string, value int) func(self, other *Caster_t) {
+	return func(self, _ *Caster_t) {
+		_ = self.Effects.Set(name, value, spellTime)
+	}
+}
+
+func shieldOther(_, other *Caster_t) {
+	_ = other.Effects.Set("m_shield", 3600, spellTime)
+}
+
+func wantEffect(name string, value int) func(t *testing.T, self, other *Caster_t, before [2]model.Empire_t, res *SpellResult_t) {
+	return func(t *testing.T, self, _ *Caster_t, _ [2]model.Empire_t, _ *SpellResult_t) {
+		if got := self.Effects.Get(name, spellTime); got != value {
+			t.Errorf("%s: want %d, got %d", name, value, got)
+		}
+	}
+}
+
+func wantOther(field func(*model.Empire_t) int, value int) func(t *testing.T, self, other *Caster_t, before [2]model.Empire_t, res *SpellResult_t) {
+	return func(t *testing.T, _, other *Caster_t, _ [2]model.Empire_t, _ *SpellResult_t) {
+		if got := field(other.Empire); got != value {
+			t.Errorf("target: want %d, got %d", value, got)
+		}
+	}
+}
+
+// wantStolen checks that the cash stolen is within the range (out of 100,000) and moved to the caster.
+func wantStolen(lo, hi int) func(t *testing.T, self, other *Caster_t, before [2]model.Empire_t, res *SpellResult_t) {
+	return func(t *testing.T, self, other *Caster_t, before [2]model.Empire_t, res *SpellResult_t) {
+		stolen := before[1].Cash - other.Empire.Cash
+		if stolen < before[1].Cash/100000*lo || stolen > before[1].Cash/100000*hi || res.News[0].Data[1] != stolen {
+			t.Errorf("steal: got %d", stolen)
+		}
+		if want := before[0].Cash + res.Report.Overall.Money + stolen; self.Empire.Cash != want {
+			t.Errorf("steal: caster cash: want %d, got %d", want, self.Empire.Cash)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/model"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// tablesFiles are the names of the files in the data directory that may
+// override the built-in race and era tables. The first one found is used.
+var tablesFiles = []string{"tables.json", "tables.yaml", "tables.yml"}
+
+// Tables_t holds the race and era definitions for a world,
+// along with the modifiers granted by empire effects.
+type Tables_t struct {
+	Races   map[int]*Race_t
+	Eras    map[int]*Era_t
+	Effects map[string]Modifiers_t // modifiers granted while an effect is active
+}
+
+// DefaultTables returns the built-in race and era tables.
+func DefaultTables() *Tables_t {
+	t := &Tables_t{Races: defaultRaces(), Eras: defaultEras(), Effects: map[string]Modifiers_t{}}
+	for id, race := range t.Races {
+		race.Id = id
+	}
+	for id, era := range t.Eras {
+		era.Id = id
+	}
+	return t
+}
+
+// LoadTables returns the built-in tables updated with the overrides from the
+// data directory. Overrides only need to specify the values being changed:
+//
+//	races:
+//	  4:              # RACE_TROLL
+//	    modifiers:
+//	      offense: 20
+//
+// New races and eras may be added, but the eras must still form a single chain.
+// Effects are replaced rather than merged:
+//
+//	effects:
+//	  r_sample:
+//	    income: 10
+func LoadTables(path string) (*Tables_t, error) {
+	t := DefaultTables()
+	for _, name := range tablesFiles {
+		filename := filepath.Join(path, name)
+		data, err := os.ReadFile(filename)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if filepath.Ext(name) == ".json" {
+			err = t.mergeJSON(data)
+		} else {
+			err = t.mergeYAML(data)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+		break
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *Tables_t) mergeJSON(data []byte) error {
+	var doc struct {
+		Races   map[int]json.RawMessage `json:"races"`
+		Eras    map[int]json.RawMessage `json:"eras"`
+		Effects map[string]Modifiers_t  `json:"effects"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	for id, raw := range doc.Races {
+		if err := json.Unmarshal(raw, t.race(id)); err != nil {
+			return fmt.Errorf("race %d: %w", id, err)
+		}
+	}
+	for id, raw := range doc.Eras {
+		if err := json.Unmarshal(raw, t.era(id)); err != nil {
+			return fmt.Errorf("era %d: %w", id, err)
+		}
+	}
+	for name, mods := range doc.Effects {
+		t.Effects[name] = mods
+	}
+	return nil
+}
+
+func (t *Tables_t) mergeYAML(data []byte) error {
+	var doc struct {
+		Races   map[int]yaml.Node      `yaml:"races"`
+		Eras    map[int]yaml.Node      `yaml:"eras"`
+		Effects map[string]Modifiers_t `yaml:"effects"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	for id, node := range doc.Races {
+		if err := node.Decode(t.race(id)); err != nil {
+			return fmt.Errorf("race %d: %w", id, err)
+		}
+	}
+	for id, node := range doc.Eras {
+		if err := node.Decode(t.era(id)); err != nil {
+			return fmt.Errorf("era %d: %w", id, err)
+		}
+	}
+	for name, mods := range doc.Effects {
+		t.Effects[name] = mods
+	}
+	return nil
+}
+
+// race returns the race to merge an override into, adding it if needed.
+func (t *Tables_t) race(id int) *Race_t {
+	if race, ok := t.Races[id]; ok {
+		return race
+	}
+	t.Races[id] = &Race_t{Id: id}
+	return t.Races[id]
+}
+
+// era returns the era to merge an override into, adding it if needed.
+func (t *Tables_t) era(id int) *Era_t {
+	if era, ok := t.Eras[id]; ok {
+		return era
+	}
+	t.Eras[id] = &Era_t{Id: id}
+	return t.Eras[id]
+}
+
+// Validate returns an error if a race or era is missing its name or
+// if the eras are not linked in a single chain from the first era.
+func (t *Tables_t) Validate() error {
+	if len(t.Races) == 0 {
+		return fmt.Errorf("no races defined")
+	}
+	for _, id := range t.RaceIds() {
+		if id <= 0 {
+			return fmt.Errorf("race %d: id must be positive", id)
+		} else if t.Races[id].Name == "" {
+			return fmt.Errorf("race %d: missing name", id)
+		}
+	}
+	if _, ok := t.Eras[ERA_PAST]; !ok {
+		return fmt.Errorf("era %d: missing first era", ERA_PAST)
+	}
+	seen := map[int]bool{}
+	for id, prev := ERA_PAST, 0; id != 0; id, prev = t.Eras[id].Next, id {
+		era, ok := t.Eras[id]
+		if !ok {
+			return fmt.Errorf("era %d: next era %d: %w", prev, id, cerr.ErrUnknownEra)
+		} else if seen[id] {
+			return fmt.Errorf("era %d: eras form a loop", id)
+		} else if era.Name == "" {
+			return fmt.Errorf("era %d: missing name", id)
+		} else if era.Prev != prev {
+			return fmt.Errorf("era %d: previous era: want %d, got %d", id, prev, era.Prev)
+		}
+		seen[id] = true
+	}
+	if len(seen) != len(t.Eras) {
+		return fmt.Errorf("eras: %d of %d eras are not linked from the first era", len(t.Eras)-len(seen), len(t.Eras))
+	}
+	for name := range t.Effects {
+		if _, err := EffectKind(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Era returns the era with the given id.
+func (t *Tables_t) Era(id int) (*Era_t, error) {
+	if era, ok := t.Eras[id]; ok {
+		return era, nil
+	}
+	return nil, cerr.ErrUnknownEra
+}
+
+// Race returns the race with the given id.
+func (t *Tables_t) Race(id int) (*Race_t, error) {
+	if race, ok := t.Races[id]; ok {
+		return race, nil
+	}
+	return nil, cerr.ErrUnknownRace
+}
+
+// RaceIds returns the ids of all races in order.
+func (t *Tables_t) RaceIds() []int {
+	var ids []int
+	for id := range t.Races {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// NewEffects returns the effects for an empire, granting the modifiers from the tables.
+func (t *Tables_t) NewEffects(empireId int, values map[string]int) *Effects_t {
+	return NewEffects(empireId, values, t.Effects)
+}
+
+// Modifiers returns the sum of the modifiers from the empire's race and era.
+// Unknown races and eras do not contribute modifiers.
+func (t *Tables_t) Modifiers(emp *model.Empire_t) Modifiers_t {
+	var mods Modifiers_t
+	if race, ok := t.Races[emp.Race]; ok {
+		mods = mods.Add(race.Modifiers)
+	}
+	if era, ok := t.Eras[emp.Era]; ok {
+		mods = mods.Add(era.Modifiers)
+	}
+	return mods
+}
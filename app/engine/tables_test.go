@@ -0,0 +1,82 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package engine
+
+import (
+	"errors"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/model"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultTables(t *testing.T) {
+	tables := DefaultTables()
+	if err := tables.Validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if len(tables.Races) != 9 || len(tables.Eras) != 3 {
+		t.Errorf("tables: want 9 races and 3 eras, got %d and %d", len(tables.Races), len(tables.Eras))
+	}
+	if race, err := tables.Race(RACE_TROLL); err != nil || race.Name != "RACE_TROLL" || race.Modifiers.Offense != 24 {
+		t.Errorf("race: troll: got %+v, %v", race, err)
+	}
+	if era, err := tables.Era(ERA_PRESENT); err != nil || era.Spells["gate"] != "ERA_PRESENT_SPELL_GATE" || era.Defense.TrpSea != 8 {
+		t.Errorf("era: present: got %+v, %v", era, err)
+	}
+	if _, err := tables.Race(10); !errors.Is(err, cerr.ErrUnknownRace) {
+		t.Errorf("race: 10: want %v, got %v", cerr.ErrUnknownRace, err)
+	}
+	if _, err := tables.Era(0); !errors.Is(err, cerr.ErrUnknownEra) {
+		t.Errorf("era: 0: want %v, got %v", cerr.ErrUnknownEra, err)
+	}
+
+	mods := tables.Modifiers(&model.Empire_t{Race: RACE_ELF, Era: ERA_PAST})
+	if mods.Industry != -17 || mods.Runepro != 32 || mods.Explore != 12 || mods.Magic != 18 {
+		t.Errorf("modifiers: elf/past: got %+v", mods)
+	}
+}
+
+func TestLoadTables(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		data string
+	}{
+		{"tables.json", `{"races": {"4": {"modifiers": {"offense": 20}}, "10": {"name": "RACE_GIANT", "modifiers": {"defense": 30}}}}`},
+		{"tables.yaml", "races:\n  4:\n    modifiers:\n      offense: 20\n  10:\n    name: RACE_GIANT\n    modifiers:\n      defense: 30\n"},
+	} {
+		path := t.TempDir()
+		if err := os.WriteFile(filepath.Join(path, tc.name), []byte(tc.data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		tables, err := LoadTables(path)
+		if err != nil {
+			t.Fatalf("%s: load: %v", tc.name, err)
+		}
+		troll, _ := tables.Race(RACE_TROLL)
+		if troll.Modifiers.Offense != 20 || troll.Modifiers.Defense != -10 || troll.Name != "RACE_TROLL" {
+			t.Errorf("%s: troll: got %+v", tc.name, troll)
+		}
+		if giant, err := tables.Race(10); err != nil || giant.Id != 10 || giant.Modifiers.Defense != 30 {
+			t.Errorf("%s: giant: got %+v, %v", tc.name, giant, err)
+		}
+		if human, _ := tables.Race(RACE_HUMAN); human.Modifiers != (Modifiers_t{}) {
+			t.Errorf("%s: human: got %+v", tc.name, human)
+		}
+	}
+
+	// no overrides
+	if tables, err := LoadTables(t.TempDir()); err != nil || len(tables.Races) != 9 {
+		t.Errorf("defaults: got %v", err)
+	}
+
+	// the eras must still form a chain
+	path := t.TempDir()
+	if err := os.WriteFile(filepath.Join(path, "tables.json"), []byte(`{"eras": {"3": {"next": 4}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTables(path); !errors.Is(err, cerr.ErrUnknownEra) {
+		t.Errorf("broken chain: want %v, got %v", cerr.ErrUnknownEra, err)
+	}
+}
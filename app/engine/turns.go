@@ -0,0 +1,283 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package engine
+
+import (
+	"github.com/mdhender/promisance/app/model"
+	"math"
+	"time"
+)
+
+// TakeTurns_t describes a request to take turns.
+type TakeTurns_t struct {
+	Turns         int               // number of turns to take
+	Action        Action_t          // other actions are accepted but only change the report
+	Interruptable bool              // stop taking turns if the empire is refused a loan or runs out of food
+	Wars          int               // number of wars the empire's clan has declared, for the passive war tax
+	Round         model.RoundData_t // Signup and Closing are used
+	Effects       *Effects_t        // if not nil, turn-based effects are consumed and effect modifiers are applied
+	Now           time.Time         // current time, used for timed effects
+}
+
+// Report_t is the result of taking turns.
+type Report_t struct {
+	Taken    int      // number of turns taken
+	Trouble  int      // TURNS_TROUBLE_* flags from the last turn taken
+	Result   int      // land explored, net cash, or net food, depending on the action
+	Deserted int      // percentage of population and military that deserted
+	Halted   bool     // true if turns were interrupted due to trouble
+	Turns    []Turn_t // results for each turn taken
+	Overall  Turn_t   // results accumulated over all turns taken
+}
+
+// Turn_t is the result of taking a single turn.
+type Turn_t struct {
+	Withdraw  int // savings above the limit moved to cash
+	Income    int
+	Expenses  int
+	WarTax    int
+	LoanPayed int
+	Money     int // net income
+	TrpArm    int
+	TrpLnd    int
+	TrpFly    int
+	TrpSea    int
+	FoodPro   int
+	FoodCon   int
+	Food      int // net food
+	Peasants  int
+	Runes     int
+	TrpWiz    int
+	Trouble   int // TURNS_TROUBLE_* flags
+}
+
+// add accumulates the results of another turn.
+func (t *Turn_t) add(o Turn_t) {
+	t.Withdraw += o.Withdraw
+	t.Income += o.Income
+	t.Expenses += o.Expenses
+	t.WarTax += o.WarTax
+	t.LoanPayed += o.LoanPayed
+	t.Money += o.Money
+	t.TrpArm += o.TrpArm
+	t.TrpLnd += o.TrpLnd
+	t.TrpFly += o.TrpFly
+	t.TrpSea += o.TrpSea
+	t.FoodPro += o.FoodPro
+	t.FoodCon += o.FoodCon
+	t.Food += o.Food
+	t.Peasants += o.Peasants
+	t.Runes += o.Runes
+	t.TrpWiz += o.TrpWiz
+	t.Trouble |= o.Trouble
+}
+
+// TakeTurns takes the requested number of turns and returns the updated empire.
+// The empire passed in is not modified, but the effects are.
+// The modifiers should include the empire's race and era; modifiers from effects are added each turn.
+// If the empire does not have enough turns, nothing is taken.
+func (e *Engine_t) TakeTurns(emp model.Empire_t, base Modifiers_t, req TakeTurns_t) (model.Empire_t, *Report_t) {
+	report := &Report_t{}
+	if req.Turns <= 0 || req.Turns > emp.Turns {
+		return emp, report
+	}
+
+	deserted := 1.0
+	for report.Taken < req.Turns {
+		var current Turn_t
+		report.Taken++
+		mods := base
+		if req.Effects != nil {
+			mods = mods.Add(req.Effects.Modifiers(req.Now))
+		}
+		emp.NetWorth = e.Networth(&emp)
+
+		if req.Action == ACTION_LAND {
+			land := e.GiveLand(&emp, mods)
+			emp.Land += land
+			emp.Freeland += land
+			report.Result += land
+		}
+
+		saveRate, loanRate := e.BankRates(&emp) // adjusted for size bonus/penalty
+		bankMax, loanMax := e.BankLimits(&emp)
+
+		// savings interest
+		if !e.IsProtected(&emp, req.Round) {
+			if emp.Bank > bankMax {
+				// if your savings account is above its limit, automatically withdraw the remainder
+				current.Withdraw = emp.Bank - bankMax
+				emp.Bank -= current.Withdraw
+				emp.Cash += current.Withdraw
+			} else {
+				// otherwise, earn interest up to the limit
+				interest := round(float64(emp.Bank) * (saveRate / 52 / 100))
+				emp.Bank = min(emp.Bank+interest, bankMax)
+			}
+		}
+
+		// loan interest
+		emp.Loan += round(float64(emp.Loan) * (loanRate / 52 / 100))
+
+		// income/expenses/loan
+		income, _, expenses := e.Finances(&emp, mods)
+		if req.Action == ACTION_CASH {
+			income = round(float64(income) * 1.25)
+		}
+
+		// war tax
+		if e.cfg.ClanEnable && emp.CId != 0 {
+			// passive war tax, applied for each war your clan has declared
+			wartax := float64(req.Wars) * (float64(emp.NetWorth) / 100)
+			// active war tax, applied when you attack somebody you're at war with
+			if req.Action == ACTION_WAR {
+				wartax += float64(expenses) / 10
+			}
+			current.WarTax = int(math.Ceil(wartax))
+		}
+
+		// net income
+		money := income - (expenses + current.WarTax)
+		emp.Cash += money
+
+		// simply running out of money doesn't halt turns; instead, it just adds it to your loan,
+		// but if your loan exceeds the limit, then you're in trouble
+		if emp.Cash < 0 {
+			current.Trouble |= TURNS_TROUBLE_CASH
+		}
+		// for emergencies, the loan limit is doubled (except during the final week, when loans are otherwise unavailable)
+		loanEmergencyLimit := loanMax * 2
+		if req.Round.Closing {
+			loanEmergencyLimit = loanMax
+		}
+		var loanPayed int
+		if current.Trouble&TURNS_TROUBLE_CASH != 0 && emp.Loan > loanEmergencyLimit {
+			current.Trouble |= TURNS_TROUBLE_LOAN
+			emp.Cash = 0
+		} else {
+			// if cash is negative, then the loan payment will also be negative
+			loanPayed = min(round(float64(emp.Loan)/200), emp.Cash)
+		}
+		emp.Cash -= loanPayed
+		emp.Loan -= loanPayed
+
+		// adjust net income
+		money -= loanPayed
+		if req.Action == ACTION_CASH {
+			report.Result += money
+		}
+		current.Income, current.Expenses, current.LoanPayed, current.Money = income, expenses, loanPayed, money
+
+		// industry
+		produced := e.Production(&emp, mods)
+		current.TrpArm, current.TrpLnd, current.TrpFly, current.TrpSea = produced.TrpArm, produced.TrpLnd, produced.TrpFly, produced.TrpSea
+		emp.TrpArm += current.TrpArm
+		emp.TrpLnd += current.TrpLnd
+		emp.TrpFly += current.TrpFly
+		emp.TrpSea += current.TrpSea
+
+		// update food
+		foodpro, foodcon := e.Provisions(&emp, mods)
+		if req.Action == ACTION_FARM {
+			foodpro = round(1.25 * float64(foodpro))
+		}
+		food := foodpro - foodcon
+		emp.Food += food
+		if req.Action == ACTION_FARM {
+			report.Result += food
+		}
+		if emp.Food < 0 {
+			emp.Food = 0
+			current.Trouble |= TURNS_TROUBLE_FOOD
+		}
+		current.FoodPro, current.FoodCon, current.Food = foodpro, foodcon, food
+
+		// health
+		if float64(emp.Health) < 100-math.Max(float64(emp.Tax-10)/2, 0) {
+			emp.Health++
+		}
+
+		// update population
+		taxRate := float64(emp.Tax) / 100
+		var taxPenalty float64
+		if taxRate > 0.40 {
+			taxPenalty = (taxRate - 0.40) / 2
+		} else if taxRate < 0.20 {
+			taxPenalty = (taxRate - 0.20) / 2
+		}
+		popBase := round((float64(emp.Land)*2 + float64(emp.Freeland)*5 + float64(emp.BldPop)*60) / (0.95 + taxRate + taxPenalty))
+		var peasants float64
+		peasMult := 1.0
+		if emp.Peasants != popBase {
+			peasants = float64(popBase-emp.Peasants) / 20
+		}
+		if peasants > 0 {
+			peasMult = (4 / ((float64(emp.Tax) + 15) / 20)) - (7.0 / 9)
+		} else if peasants < 0 {
+			peasMult = 1 / ((4 / ((float64(emp.Tax) + 15) / 20)) - (7.0 / 9))
+		}
+		current.Peasants = round(peasants * peasMult * peasMult)
+		// don't let population reach zero
+		if emp.Peasants+current.Peasants < 1 {
+			current.Peasants = 1 - emp.Peasants
+		}
+		emp.Peasants += current.Peasants
+
+		// gain magic energy
+		wizRatio := float64(emp.BldWiz) / float64(max(emp.Land, 1))
+		var runes int
+		if wizRatio > 0.15 {
+			runes = e.randRange(round(float64(emp.BldWiz)*1.1), round(float64(emp.BldWiz)*1.5))
+		} else {
+			runes = round(float64(emp.BldWiz) * 1.1)
+		}
+		current.Runes = round(float64(runes) * Modifier(mods.Runepro))
+		emp.Runes += current.Runes
+
+		// wizards are added based on buildings, or lost when there are too many
+		var trpwiz float64
+		if emp.TrpWiz < emp.BldWiz*25 {
+			trpwiz = float64(emp.BldWiz) * 0.45
+		} else if emp.TrpWiz < emp.BldWiz*50 {
+			trpwiz = float64(emp.BldWiz) * 0.30
+		} else if emp.TrpWiz < emp.BldWiz*90 {
+			trpwiz = float64(emp.BldWiz) * 0.15
+		} else if emp.TrpWiz < emp.BldWiz*100 {
+			trpwiz = float64(emp.BldWiz) * 0.10
+		} else if emp.TrpWiz > emp.BldWiz*175 {
+			trpwiz = float64(emp.TrpWiz) * -0.05
+		}
+		current.TrpWiz = round(trpwiz * math.Sqrt(math.Max(0, 1-trpwiz/math.Max(1, math.Abs(trpwiz))*0.75*wizRatio)))
+		emp.TrpWiz += current.TrpWiz
+
+		if req.Effects != nil {
+			req.Effects.TakeTurn()
+		}
+		emp.TurnsUsed++
+		emp.Turns--
+
+		// only punish for refused loans or starvation
+		halt := false
+		if current.Trouble&(TURNS_TROUBLE_LOAN|TURNS_TROUBLE_FOOD) != 0 {
+			emp.Peasants -= round(float64(emp.Peasants) * 0.03)
+			emp.TrpArm -= round(float64(emp.TrpArm) * 0.03)
+			emp.TrpLnd -= round(float64(emp.TrpLnd) * 0.03)
+			emp.TrpFly -= round(float64(emp.TrpFly) * 0.03)
+			emp.TrpSea -= round(float64(emp.TrpSea) * 0.03)
+			emp.TrpWiz -= round(float64(emp.TrpWiz) * 0.03)
+			deserted *= 1 - 0.03
+			halt = req.Interruptable
+		}
+
+		report.Trouble = current.Trouble
+		report.Turns = append(report.Turns, current)
+		report.Overall.add(current)
+		if halt {
+			report.Halted = true
+			break
+		}
+	}
+	report.Deserted = round((1 - deserted) * 100)
+	emp.NetWorth = e.Networth(&emp)
+	return emp, report
+}
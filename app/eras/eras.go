@@ -0,0 +1,238 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+// Package eras implements moving an empire between eras with the advance and
+// regress spells from php/spells/advance.php and php/spells/regress.php.
+//
+// Changing eras costs runes and turns, and an empire must spend TURNS_ERA
+// turns in an era (tracked by the r_newera effect) before it can change again.
+// Empires in different eras may only interact while a time gate is open.
+package eras
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/orm"
+	"time"
+)
+
+// Config_t holds the settings from config.php used when changing eras.
+type Config_t struct {
+	ClanEnable bool // Master enable for clans, clans at war pay war tax every turn
+}
+
+// Eras_t changes eras against the database.
+type Eras_t struct {
+	db     *orm.DB
+	e      *engine.Engine_t
+	tables *engine.Tables_t
+	cfg    Config_t
+}
+
+// New returns an era change service.
+func New(db *orm.DB, e *engine.Engine_t, tables *engine.Tables_t, cfg Config_t) (*Eras_t, error) {
+	if db == nil {
+		return nil, fmt.Errorf("missing database")
+	} else if e == nil {
+		return nil, fmt.Errorf("missing engine")
+	} else if tables == nil {
+		return nil, fmt.Errorf("missing tables")
+	}
+	return &Eras_t{db: db, e: e, tables: tables, cfg: cfg}, nil
+}
+
+// Change_t describes moving to the next or previous era.
+type Change_t struct {
+	Spell   string // "advance" or "regress"
+	Era     int    // era the empire would move to, zero if there is none
+	Enabled bool   // false if the spell is not available in this game
+	Allowed bool   // the spell may be cast right now
+	Cost    int    // runes needed
+	Turns   int    // turns needed
+}
+
+// State_t describes the empire's era and what it takes to change it.
+type State_t struct {
+	Era       int
+	Runes     int
+	Wizards   int
+	Turns     int
+	Health    int
+	TurnsLeft int  // turns remaining before the empire may change eras
+	Gate      bool // a time gate is open, so the empire may interact with other eras
+	Advance   Change_t
+	Regress   Change_t
+}
+
+// State returns the empire's era and what it takes to change it.
+func (s *Eras_t) State(empireId int, now time.Time) (*State_t, error) {
+	var state *State_t
+	err := s.db.Transaction(func(tx *orm.DB) error {
+		emp, err := tx.EmpireFetch(empireId)
+		if err != nil {
+			return err
+		}
+		fx, err := tx.EmpireEffectsFetch(emp.Id, s.tables.Effects)
+		if err != nil {
+			return err
+		}
+		state, err = s.state(emp, fx, now)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// Result_t is the result of trying to change eras.
+type Result_t struct {
+	Spell    *engine.SpellResult_t // nil if the spell was not cast
+	Messages []engine.Message_t
+	State    *State_t // era after the attempt
+}
+
+// Advance casts the spell to move the empire to the next era.
+func (s *Eras_t) Advance(empireId int, round model.RoundData_t, now time.Time) (*Result_t, error) {
+	return s.change(empireId, round, "advance", now)
+}
+
+// Regress casts the spell to move the empire to the previous era.
+func (s *Eras_t) Regress(empireId int, round model.RoundData_t, now time.Time) (*Result_t, error) {
+	return s.change(empireId, round, "regress", now)
+}
+
+// change casts the advance or regress spell in a single transaction.
+// Requests that can not be honored are rejected with a message and nothing is spent.
+func (s *Eras_t) change(empireId int, round model.RoundData_t, spell string, now time.Time) (*Result_t, error) {
+	if round.Finished {
+		return nil, cerr.ErrRoundFinished
+	} else if !round.Started {
+		return nil, cerr.ErrRoundNotStarted
+	}
+	res := &Result_t{}
+	err := s.db.Transaction(func(tx *orm.DB) error {
+		emp, err := tx.EmpireFetch(empireId)
+		if err != nil {
+			return err
+		}
+		fx, err := tx.EmpireEffectsFetch(emp.Id, s.tables.Effects)
+		if err != nil {
+			return err
+		}
+		era, err := s.tables.Era(emp.Era)
+		if err != nil {
+			return err
+		}
+		reject := func(key string, args ...any) error {
+			res.Messages = append(res.Messages, engine.Message_t{Key: key, Args: args})
+			state, err := s.state(emp, fx, now)
+			res.State = state
+			return err
+		}
+
+		// check the reasons the spell is not allowed first, so that we can explain them
+		if _, err := s.e.Spell(spell); errors.Is(err, cerr.ErrUnknownSpell) {
+			return reject("ERACHANGE_DISABLED")
+		} else if spell == "advance" && era.Next == 0 {
+			return reject("ERACHANGE_NO_NEXT")
+		} else if spell == "regress" && era.Prev == 0 {
+			return reject("ERACHANGE_NO_PREV")
+		} else if left := fx.Get("r_newera", now); left > 0 {
+			return reject("ERACHANGE_TOO_SOON", left, era.Name)
+		}
+
+		var wars int
+		if s.cfg.ClanEnable && emp.CId != 0 {
+			// the passive war tax only counts wars the clan declared
+			if wars, err = tx.ClanWarsDeclared(emp.CId); err != nil {
+				return err
+			}
+		}
+		result, err := s.e.CastSpell(engine.CastSpell_t{
+			Spell:  spell,
+			Target: engine.TARGET_SELF,
+			Self:   &engine.Caster_t{Empire: emp, Effects: fx},
+			Tables: s.tables,
+			Round:  round,
+			Wars:   wars,
+			Now:    now,
+		})
+		switch {
+		case errors.Is(err, cerr.ErrNeedWizards):
+			return reject("MAGIC_NEED_WIZARDS", era.TrpWiz)
+		case errors.Is(err, cerr.ErrNeedRunes):
+			return reject("MAGIC_NEED_RUNES", era.Runes)
+		case errors.Is(err, cerr.ErrNeedTurns):
+			return reject("MAGIC_NEED_TURNS")
+		case errors.Is(err, cerr.ErrNeedHealth):
+			return reject("MAGIC_NEED_HEALTH", era.TrpWiz)
+		case errors.Is(err, cerr.ErrSpellNotAllowed):
+			return reject("MAGIC_SELF_INVALID")
+		case err != nil:
+			return err
+		}
+
+		emp.NetWorth = s.e.Networth(emp)
+		if err := tx.EmpireAttributesUpdate(emp); err != nil {
+			return err
+		} else if err := tx.EmpireEffectsSave(fx); err != nil {
+			return err
+		} else if err := tx.EmpireNewsCreate(now, result.News...); err != nil {
+			return err
+		}
+		res.Spell = result
+		res.Messages = append(res.Messages, engine.Message_t{Key: "MAGIC_BEGIN", Args: []any{era.TrpWiz}})
+		if result.Aborted {
+			res.Messages = append(res.Messages, engine.Message_t{Key: "MAGIC_TROUBLE_ABORT", Args: []any{era.TrpWiz}})
+		} else {
+			res.Messages = append(res.Messages, result.Messages...)
+		}
+		if result.Success {
+			// the new era's names are used from here on
+			era, err = s.tables.Era(emp.Era)
+			if err != nil {
+				return err
+			}
+			res.Messages = append(res.Messages, engine.Message_t{Key: "ERACHANGE_COMPLETE", Args: []any{era.Name, fx.Get("r_newera", now)}})
+		}
+		res.State, err = s.state(emp, fx, now)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// state returns the empire's era and what it takes to change it.
+func (s *Eras_t) state(emp *model.Empire_t, fx *engine.Effects_t, now time.Time) (*State_t, error) {
+	era, err := s.tables.Era(emp.Era)
+	if err != nil {
+		return nil, err
+	}
+	state := &State_t{
+		Era:       emp.Era,
+		Runes:     emp.Runes,
+		Wizards:   emp.TrpWiz,
+		Turns:     emp.Turns,
+		Health:    emp.Health,
+		TurnsLeft: fx.Get("r_newera", now),
+		Gate:      fx.Active("m_gate", now),
+		Advance:   Change_t{Spell: "advance", Era: era.Next},
+		Regress:   Change_t{Spell: "regress", Era: era.Prev},
+	}
+	self := &engine.Caster_t{Empire: emp, Effects: fx}
+	for _, change := range []*Change_t{&state.Advance, &state.Regress} {
+		cost, turns, allowed, err := s.e.SelfSpellCost(change.Spell, self, s.tables, now)
+		if errors.Is(err, cerr.ErrUnknownSpell) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		change.Enabled, change.Allowed, change.Cost, change.Turns = true, allowed, cost, turns
+	}
+	return state, nil
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package eras
+
+import (
+	"errors"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/orm"
+	"testing"
+	"time"
+)
+
+func TestChange(t *testing.T) {
+	db, err := orm.CreateMemoryDatabase()
+	if err != nil {
+		t.Fatalf("database: %v", err)
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+	e := engine.New(engine.Config_t{IndustryMult: 2.5, TurnsEra: 500}, nil)
+	s, err := New(db, e, engine.DefaultTables(), Config_t{})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	user, err := db.UserCreate("wizard", "wizard@example.com")
+	if err != nil {
+		t.Fatalf("user: %v", err)
+	}
+	emp, err := db.EmpireCreate(user, "wizard", "HUMAN")
+	if err != nil {
+		t.Fatalf("empire: %v", err)
+	}
+	emp.Era, emp.Turns, emp.Health, emp.Land, emp.Freeland = engine.ERA_PAST, 10, 100, 250, 100
+	emp.TrpWiz, emp.BldWiz, emp.Runes = 10, 100, 1_000_000
+	if err := db.EmpireAttributesUpdate(emp); err != nil {
+		t.Fatalf("empire: %v", err)
+	}
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	started := model.RoundData_t{Started: true}
+
+	state, err := s.State(emp.Id, now)
+	if err != nil {
+		t.Fatalf("state: %v", err)
+	} else if state.Advance.Era != engine.ERA_PRESENT || !state.Advance.Allowed || state.Advance.Cost <= 0 || state.Advance.Turns != 2 {
+		t.Errorf("state: advance: got %+v", state.Advance)
+	} else if state.Regress.Era != 0 || state.Regress.Enabled {
+		t.Errorf("state: regress: got %+v", state.Regress)
+	}
+
+	if _, err := s.Advance(emp.Id, model.RoundData_t{}, now); !errors.Is(err, cerr.ErrRoundNotStarted) {
+		t.Errorf("not started: want %v, got %v", cerr.ErrRoundNotStarted, err)
+	}
+	res, err := s.Regress(emp.Id, started, now)
+	if err != nil {
+		t.Fatalf("regress: %v", err)
+	} else if res.Spell != nil || len(res.Messages) != 1 || res.Messages[0].Key != "ERACHANGE_DISABLED" {
+		t.Errorf("regress: got %+v", res.Messages)
+	}
+
+	// weak wizards fizzle and are lost, but the runes and turns are still spent
+	res, err = s.Advance(emp.Id, started, now)
+	if err != nil {
+		t.Fatalf("weak: %v", err)
+	} else if res.Spell == nil || !res.Spell.Failed || res.Spell.WizLoss == 0 || res.State.Era != engine.ERA_PAST || res.State.Turns != 8 {
+		t.Errorf("weak: got %+v", res.State)
+	}
+
+	// an army of wizards needs to be fed
+	emp, err = db.EmpireFetch(emp.Id)
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	emp.TrpWiz, emp.Food, emp.Cash = 10_000, 1_000_000, 1_000_000
+	if err := db.EmpireAttributesUpdate(emp); err != nil {
+		t.Fatalf("empire: %v", err)
+	}
+	res, err = s.Advance(emp.Id, started, now)
+	if err != nil {
+		t.Fatalf("advance: %v", err)
+	} else if res.Spell == nil || !res.Spell.Success {
+		t.Fatalf("advance: got %+v", res.Messages)
+	} else if last := res.Messages[len(res.Messages)-1]; last.Key != "ERACHANGE_COMPLETE" || last.Args[0] != "ERA_PRESENT_NAME" || last.Args[1] != 500 {
+		t.Errorf("advance: got %+v", last)
+	} else if res.State.Era != engine.ERA_PRESENT || res.State.TurnsLeft != 500 || res.State.Advance.Allowed {
+		t.Errorf("advance: state: got %+v", res.State)
+	}
+	if got, err := db.EmpireFetch(emp.Id); err != nil {
+		t.Fatalf("fetch: %v", err)
+	} else if got.Era != engine.ERA_PRESENT || got.Turns != 6 {
+		t.Errorf("empire: got era %d, turns %d", got.Era, got.Turns)
+	}
+
+	res, err = s.Advance(emp.Id, started, now)
+	if err != nil {
+		t.Fatalf("advance again: %v", err)
+	} else if len(res.Messages) != 1 || res.Messages[0].Key != "ERACHANGE_TOO_SOON" || res.Messages[0].Args[0] != 500 {
+		t.Errorf("advance again: got %+v", res.Messages)
+	}
+}
@@ -158,14 +158,14 @@ func (p *PHP) gauss_intrand(min, max, dev, mean float64) {
 	//return intval(gauss_rand($min, $max, $dev, $mean));
 }
 
+// notice adds a message to the notices shown at the top of the page.
 func (p *PHP) notice(format string, args ...any) {
-	panic("not implemented")
 	//global $notices;
 	//if (strlen($notices) > 0)
 	//	$notices .= "<br />\n";
 	//$notices .= $msg;
 	if len(p.globals.notices) > 0 {
-		p.globals.notices += "<br />"
+		p.globals.notices += "<br />\n"
 	}
 	p.globals.notices += fmt.Sprintf(format, args...)
 }
@@ -338,7 +338,7 @@ var (
 		`COMMON_POINTS_LOSE_SINGLE`:   `(-%spt)`,
 		`COMMON_POINTS_LOSE_PLURAL`:   `(-%spts)`,
 		`COMMON_USER_NAMEID`:          `%1$s (%2$s)`,
-		`COMMON_EMPIRE_NAMEID`:        `%1$s (%2$s)`,
+		`COMMON_EMPIRE_NAMEID`:        `%[1]s (%[2]s)`,
 		`COMMON_CLAN_NAMEID`:          `%1$s (%2$s)`,
 		`COMMON_USER_UNINITIALIZED`:   `Uninitialized User`,
 		`COMMON_EMPIRE_UNINITIALIZED`: `Uninitialized Empire`,
@@ -375,16 +375,16 @@ var (
 		`HEADER_GUIDE`:        `[Game Guide]`,
 		`HEADER_REFRESH`:      `[Refresh]`,
 
-		`VACATION_NOT_LOCKED`:    `This empire was placed on vacation %1$s ago.<br />It will be frozen in %2$s.<br />`,
-		`VACATION_LOCKED`:        `This empire is on vacation and has been frozen for %1$s.<br />`,
-		`VACATION_CANNOT_UNLOCK`: `It may be unlocked in %1$s.<br />`,
+		`VACATION_NOT_LOCKED`:    `This empire was placed on vacation %[1]s ago.<br />It will be frozen in %[2]s.<br />`,
+		`VACATION_LOCKED`:        `This empire is on vacation and has been frozen for %[1]s.<br />`,
+		`VACATION_CANNOT_UNLOCK`: `It may be unlocked in %[1]s.<br />`,
 		`VACATION_CAN_UNLOCK`:    `You may resume playing at your own convenience.<br />`,
 		`VACATION_UNLOCK_SUBMIT`: `Return from Vacation`,
 
 		`DEAD_UNNOTIFIED`:        `You arrive at your empire, only to find it is in ruins. A wounded messenger staggers toward you and tells you what happened...`,
 		`DEAD_NOTIFIED`:          `Your empire has been destroyed. There is nothing more for you to do other than review the events which led to your destruction...`,
-		`DEAD_DELETE_NOTICE`:     `Your empire will be deleted in %1$s, after which you will have the opportunity to create a new empire.`,
-		`DEAD_DELETE_NOTICE_END`: `Your empire will be deleted in %1$s, after which you must wait until the next round to create a new empire.`,
+		`DEAD_DELETE_NOTICE`:     `Your empire will be deleted in %[1]s, after which you will have the opportunity to create a new empire.`,
+		`DEAD_DELETE_NOTICE_END`: `Your empire will be deleted in %[1]s, after which you must wait until the next round to create a new empire.`,
 
 		`UNVALIDATED_UNNOTIFIED`: `It is now necessary to validate your account. If you did not receive your validation email, you can have it resent.`,
 		`UNVALIDATED_NOTIFIED`:   `You are not validated and cannot continue from here. If you did not receive your validation e-mail, you can have it resent.`,
@@ -392,10 +392,10 @@ var (
 
 		`DISABLED_DEFAULT_REASON`:       `an unspecified reason`,
 		`DISABLED_DEFAULT_REASON_MULTI`: `abuse of multiple accounts`,
-		`DISABLED_BY_ADMIN`:             `This empire has been disabled by %1$s due to %2$s.<br />You are advised to email this Administrator at <a href="mailto:%3$s?subject=%4$s">%3$s</a> and explain your actions if you wish to continue playing.<br />`,
-		`DISABLED_BY_SCRIPT`:            `This empire has been automatically disabled due to %1$s.<br />Please contact the game administrator at <a href="mailto:%2$s?subject=%3$s">%2$s</a> for more information.<br />`,
-		`DISABLED_EMAIL_SUBJECT`:        `%1$s - disabled %2$s`,
-		`DISABLED_EMAIL_REMINDER`:       `Be sure to include your empire name and number and put the game name (%1$s) in the Subject line or you may be ignored.<br />`,
+		`DISABLED_BY_ADMIN`:             `This empire has been disabled by %[1]s due to %[2]s.<br />You are advised to email this Administrator at <a href="mailto:%[3]s?subject=%[4]s">%[3]s</a> and explain your actions if you wish to continue playing.<br />`,
+		`DISABLED_BY_SCRIPT`:            `This empire has been automatically disabled due to %[1]s.<br />Please contact the game administrator at <a href="mailto:%[2]s?subject=%[3]s">%[2]s</a> for more information.<br />`,
+		`DISABLED_EMAIL_SUBJECT`:        `%[1]s - disabled %[2]s`,
+		`DISABLED_EMAIL_REMINDER`:       `Be sure to include your empire name and number and put the game name (%[1]s) in the Subject line or you may be ignored.<br />`,
 
 		`HEADER_EMPIRE_DELETED`: `Your empire has been marked for deletion. Thanks for playing!`,
 
@@ -414,7 +414,7 @@ var (
 		// includes/magic
 		`SPELL_GENERIC_SUCCESS`:       `You cast the spell and it is <span class="cgood">successful</span>!`,
 		`SPELL_GENERIC_FAILED`:        `You cast the spell and it <span class="cbad">fizzles</span>!`,
-		`SPELL_GENERIC_FAILED_LOSSES`: `%1$s %2$s are killed in a magical explosion!`,
+		`SPELL_GENERIC_FAILED_LOSSES`: `%[1]s %[2]s are killed in a magical explosion!`,
 		`SPELL_GENERIC_SHIELDED`:      `You cast the spell and it is <span class="cgood">successful</span>, but <span class="cwarn">partially blocked</span>.`,
 
 		// spells/*
@@ -424,31 +424,31 @@ var (
 		`SPELL_SHIELD_CREATE`:        `There is now a protective shield around your empire for 12 hours!`,
 		`SPELL_SHIELD_EXTEND`:        `You have extended your shield for another 3 hours!`,
 		`SPELL_SHIELD_RENEW`:         `Your shield has been renewed to 12 hours!`,
-		`SPELL_STORM_SUCCESS`:        `Your storms have blown away %1$s %2$s and %3$s!`,
-		`SPELL_STORM_SHIELDED`:       `Your storms have blown away %1$s %2$s and %3$s.`,
-		`SPELL_RUNES_SUCCESS`:        `You have destroyed %1$s of your enemy's %2$s!`,
-		`SPELL_RUNES_SHIELDED`:       `You have destroyed %1$s of your enemy's %2$s.`,
-		`SPELL_STRUCT_SUCCESS`:       `You have destroyed %1$s of your enemy's structures!`,
-		`SPELL_STRUCT_SHIELDED`:      `You have destroyed %1$s of your enemy's structures.`,
+		`SPELL_STORM_SUCCESS`:        `Your storms have blown away %[1]s %[2]s and %[3]s!`,
+		`SPELL_STORM_SHIELDED`:       `Your storms have blown away %[1]s %[2]s and %[3]s.`,
+		`SPELL_RUNES_SUCCESS`:        `You have destroyed %[1]s of your enemy's %[2]s!`,
+		`SPELL_RUNES_SHIELDED`:       `You have destroyed %[1]s of your enemy's %[2]s.`,
+		`SPELL_STRUCT_SUCCESS`:       `You have destroyed %[1]s of your enemy's structures!`,
+		`SPELL_STRUCT_SHIELDED`:      `You have destroyed %[1]s of your enemy's structures.`,
 		`SPELL_STRUCT_NOEFFECT`:      `Unfortunately, your spell had no effect.`,
-		`SPELL_FOOD_SUCCESS`:         `%1$s %2$s materializes in your empire's stockpiles!`,
-		`SPELL_CASH_SUCCESS`:         `%1$s materializes in your empire's treasury!`,
+		`SPELL_FOOD_SUCCESS`:         `%[1]s %[2]s materializes in your empire's stockpiles!`,
+		`SPELL_CASH_SUCCESS`:         `%[1]s materializes in your empire's treasury!`,
 		`SPELL_GATE_CREATE`:          `You have opened a time gate. You may now interact with any empire for 12 hours!`,
 		`SPELL_GATE_EXTEND`:          `You have extended your time gate for another 3 hours!`,
 		`SPELL_GATE_RENEW`:           `Your time gate has been renewed to 12 hours!`,
 		`SPELL_UNGATE_SUCCESS`:       `Your time gate has been closed!`,
-		`SPELL_FIGHT_ATTACKING`:      `Your %1$s focus all of their energy directly upon the %2$s of %3$s...`,
-		`SPELL_FIGHT_SUCCESS_HEADER`: `A massive field of energy covers the lands of %1$s, and your %2$s capture %3$s acres of land!`,
-		`SPELL_FIGHT_SUCCESS_LOSSES`: `%3$s of your opponent's %4$s are eliminated in the resulting shockwave, but not before destroying %1$s of your %2$s.`,
-		`SPELL_FIGHT_BLOCKED_HEADER`: `A massive field of energy begins to form over the lands of %1$s, but their %2$s reflect it back upon you!`,
-		`SPELL_FIGHT_BLOCKED_LOSSES`: `The resulting shockwave eliminates %1$s of your %2$s, while %3$s of your opponent's %4$s are destroyed from the initial energy field.`,
-		`SPELL_STEAL_SUCCESS`:        `You have embezzled %1$s from your enemy's treasury!`,
-		`SPELL_STEAL_SHIELDED`:       `You have embezzled %1$s from your enemy's treasury.`,
+		`SPELL_FIGHT_ATTACKING`:      `Your %[1]s focus all of their energy directly upon the %[2]s of %[3]s...`,
+		`SPELL_FIGHT_SUCCESS_HEADER`: `A massive field of energy covers the lands of %[1]s, and your %[2]s capture %[3]s acres of land!`,
+		`SPELL_FIGHT_SUCCESS_LOSSES`: `%[3]s of your opponent's %[4]s are eliminated in the resulting shockwave, but not before destroying %[1]s of your %[2]s.`,
+		`SPELL_FIGHT_BLOCKED_HEADER`: `A massive field of energy begins to form over the lands of %[1]s, but their %[2]s reflect it back upon you!`,
+		`SPELL_FIGHT_BLOCKED_LOSSES`: `The resulting shockwave eliminates %[1]s of your %[2]s, while %[3]s of your opponent's %[4]s are destroyed from the initial energy field.`,
+		`SPELL_STEAL_SUCCESS`:        `You have embezzled %[1]s from your enemy's treasury!`,
+		`SPELL_STEAL_SHIELDED`:       `You have embezzled %[1]s from your enemy's treasury.`,
 		`SPELL_ADVANCE_SUCCESS`:      `You have advanced to the next age!`,
 		`SPELL_REGRESS_SUCCESS`:      `You have regressed to the previous age!`,
 
 		// turns output
-		`TURNS_WITHDRAW`:                `The World Bank notifies you that your savings balance has exceeded the insured limit, so you withdraw %1$s.`,
+		`TURNS_WITHDRAW`:                `The World Bank notifies you that your savings balance has exceeded the insured limit, so you withdraw %[1]s.`,
 		`TURNS_CASH_HEADER`:             `Economic Status`,
 		`TURNS_CASH_INCOME`:             `Income:`,
 		`TURNS_CASH_EXPENSE`:            `Expenses:`,
@@ -466,17 +466,17 @@ var (
 		`TURNS_TROUBLE_CASH`:            `You have insufficient funds to maintain your empire! You rush to the World Bank to take out a loan...`,
 		`TURNS_TROUBLE_LOAN`:            `The World Bank refuses your request for a loan, despite your emergency!`,
 		`TURNS_TROUBLE_FOOD`:            `You have run out of food! Your people are starving!`,
-		`TURNS_TROUBLE_LOSSES_CONTINUE`: `%1$s of your population and military have deserted your empire! Unfortunately, you have no choice but to continue...`,
-		`TURNS_TROUBLE_LOSSES_HALT`:     `%1$s of your population and military have deserted your empire! You immediately stop what you are doing, preventing the problem from getting any worse.`,
+		`TURNS_TROUBLE_LOSSES_CONTINUE`: `%[1]s of your population and military have deserted your empire! Unfortunately, you have no choice but to continue...`,
+		`TURNS_TROUBLE_LOSSES_HALT`:     `%[1]s of your population and military have deserted your empire! You immediately stop what you are doing, preventing the problem from getting any worse.`,
 
 		// empire news reports
 		`EMPNEWS_DATE_FORMAT`:                    `%1$s ago`,
-		`EMPNEWS_GIVE_MARKET_SELL`:               `You sold %1$s %2$s on the market.`,
+		`EMPNEWS_GIVE_MARKET_SELL`:               `You sold %[1]s %[2]s on the market.`,
 		`EMPNEWS_GIVE_LOTTERY`:                   `You won the lottery!`,
-		`EMPNEWS_GIVE_MARKET_RETURN`:             `Your %1$s %2$s failed to sell on the market.`,
-		`EMPNEWS_GIVE_AID_SEND`:                  `%1$s's %2$s drop off their shipment, then %3$s turn back and return home.`,
-		`EMPNEWS_GIVE_AID_RETURN_ALL`:            `Your %1$s returned from their aid shipment.`,
-		`EMPNEWS_GIVE_AID_RETURN_SOME`:           `%1$s of your %2$s %3$s have returned from their aid shipment.`,
+		`EMPNEWS_GIVE_MARKET_RETURN`:             `Your %[1]s %[2]s failed to sell on the market.`,
+		`EMPNEWS_GIVE_AID_SEND`:                  `%[1]s's %[2]s drop off their shipment, then %[3]s turn back and return home.`,
+		`EMPNEWS_GIVE_AID_RETURN_ALL`:            `Your %[1]s returned from their aid shipment.`,
+		`EMPNEWS_GIVE_AID_RETURN_SOME`:           `%[1]s of your %[2]s %[3]s have returned from their aid shipment.`,
 		`EMPNEWS_GIVE_COLUMN_DATE`:               `Date`,
 		`EMPNEWS_GIVE_COLUMN_GAIN`:               `Gain`,
 		`EMPNEWS_GIVE_COLUMN_DESCRIBE`:           `Description`,
@@ -23,13 +23,13 @@ var (
 		`AID_TARGET_VACATION`:     `You cannot send aid to an empire under vacation protection!`,
 		`AID_TARGET_WAR`:          `Your Generals laugh at the very idea of sending aid to a sworn enemy.`,
 		`AID_TARGET_TOO_BIG`:      `Such a powerful empire would have no need for your aid.`,
-		`AID_NEED_CONVOY`:         `You do not have enough %1$s to send an aid shipment!`,
+		`AID_NEED_CONVOY`:         `You do not have enough %[1]s to send an aid shipment!`,
 		`AID_NO_CARGO`:            `You have not selected anything to include in this shipment!`,
-		`AID_TOO_MUCH_CASH`:       `You cannot send that much money in a single shipment - only %1$s will be sent.`,
-		`AID_TOO_MUCH_UNIT`:       `You cannot send that many %1$s in a single shipment - only %2$s will be sent.`,
-		`AID_COMPLETE`:            `%1$s %2$s have departed with your shipment to <b>%3$s</b>`,
-		`AID_WILL_RETURN`:         `Once your shipment is received, your remaining %1$s %2$s will be returned to your empire.`,
-		`AID_HEADER`:              `Sending aid requires 2 turns and at least %1$s %2$s.<br />We can send %3$s at this time.<br />We can send an additional shipment every hour.`,
+		`AID_TOO_MUCH_CASH`:       `You cannot send that much money in a single shipment - only %[1]s will be sent.`,
+		`AID_TOO_MUCH_UNIT`:       `You cannot send that many %[1]s in a single shipment - only %[2]s will be sent.`,
+		`AID_COMPLETE`:            `%[1]s %[2]s have departed with your shipment to <b>%[3]s</b>`,
+		`AID_WILL_RETURN`:         `Once your shipment is received, your remaining %[1]s %[2]s will be returned to your empire.`,
+		`AID_HEADER`:              `Sending aid requires 2 turns and at least %[1]s %[2]s.<br />We can send %[3]s at this time.<br />We can send an additional shipment every hour.`,
 		`AID_SUBMIT`:              `Send Assistance`,
 
 		// pages/bank
@@ -38,13 +38,13 @@ var (
 		`BANK_UNAVAILABLE_END`:          `The bank cannot be accessed after the round has ended.`,
 		`BANK_BORROW_TOO_LATE`:          `Money cannot be borrowed this close to the end of the round!`,
 		`BANK_BORROW_TOO_MUCH`:          `You cannot borrow that much money!`,
-		`BANK_BORROW_COMPLETE`:          `You have taken out a loan for %1$s. 0.5%% of the loan will be paid off each turn.`,
+		`BANK_BORROW_COMPLETE`:          `You have taken out a loan for %[1]s. 0.5%% of the loan will be paid off each turn.`,
 		`BANK_REPAY_NOT_ENOUGH`:         `You do not have that much money!`,
-		`BANK_REPAY_COMPLETE`:           `Thank you for your %1$s payment. It will be credited to your account immediately.`,
+		`BANK_REPAY_COMPLETE`:           `Thank you for your %[1]s payment. It will be credited to your account immediately.`,
 		`BANK_DEPOSIT_NOT_ENOUGH`:       `You do not have that much money!`,
 		`BANK_DEPOSIT_TOO_MUCH`:         `You cannot deposit that much money!`,
-		`BANK_DEPOSIT_COMPLETE`:         `You have deposited %1$s into your savings account.`,
-		`BANK_WITHDRAW_COMPLETE`:        `You have withdrawn %1$s from your savings account.`,
+		`BANK_DEPOSIT_COMPLETE`:         `You have deposited %[1]s into your savings account.`,
+		`BANK_WITHDRAW_COMPLETE`:        `You have withdrawn %[1]s from your savings account.`,
 		`BANK_HEADER_SAVINGS`:           `Savings`,
 		`BANK_HEADER_LOAN`:              `Loan`,
 		`BANK_LABEL_SAVINGS_INTEREST`:   `Interest APR:`,
@@ -72,8 +72,8 @@ var (
 		`BUILD_NO_INPUT`:          `Please specify how many structures you wish to build.`,
 		`BUILD_TOO_MANY`:          `You cannot build that many structures!`,
 		`BUILD_OUT_OF_CASH`:       `You do not have enough money to finish building!`,
-		`BUILD_COMPLETE`:          `You spent %1$s and %2$s building %3$s.`,
-		`BUILD_HEADER`:            `Each structure consumes one acre of free land and costs %1$s to build.<br />You can build %2$s structures per turn.<br />With our resources, we can build <span class="cgood">%3$s</span> structures.<br />`,
+		`BUILD_COMPLETE`:          `You spent %[1]s and %[2]s building %[3]s.`,
+		`BUILD_HEADER`:            `Each structure consumes one acre of free land and costs %[1]s to build.<br />You can build %[2]s structures per turn.<br />With our resources, we can build <span class="cgood">%[3]s</span> structures.<br />`,
 		`BUILD_LABEL_FREELAND`:    `Unused Land`,
 		`BUILD_SUBMIT`:            `Begin Construction`,
 		`BUILD_LINK_DEMOLISH`:     `Demolish Structures`,
@@ -82,8 +82,8 @@ var (
 		`CASH_TITLE`:            `Economic Focus`,
 		`CASH_NOT_ENOUGH_TURNS`: `You cannot spend that many turns making money!`,
 		`CASH_SPECIFY_TURNS`:    `Please specify a number of turns to spend making money.`,
-		`CASH_COMPLETE`:         `You earned a total of %1$s in %2$s.`,
-		`CASH_HEADER`:           `For each turn you spend focusing on your economy, your %1$s will make 25%% more money.`,
+		`CASH_COMPLETE`:         `You earned a total of %[1]s in %[2]s.`,
+		`CASH_HEADER`:           `For each turn you spend focusing on your economy, your %[1]s will make 25%% more money.`,
 		`CASH_LABEL`:            `Spend how many turns making money?`,
 		`CASH_SUBMIT`:           `Make Money`,
 
@@ -99,41 +99,43 @@ var (
 		`CLAN_NOT_MEMBER`:     `You are not in a clan!`,
 
 		`CLAN_CREATE_TOO_LATE`:      `You cannot create a clan this late in the game.`,
-		`CLAN_CREATE_TOO_SOON`:      `You cannot create a new clan until at least %1$s hours have passed since leaving your previous clan.`,
+		`CLAN_CREATE_TOO_SOON`:      `You cannot create a new clan until at least %[1]s hours have passed since leaving your previous clan.`,
 		`CLAN_CREATE_NEED_NAME`:     `You must specify a name for your new clan.`,
 		`CLAN_CREATE_NAME_INVALID`:  `That clan name is not allowed!`,
 		`CLAN_CREATE_NAME_TOO_LONG`: `That clan name is too long. Please choose a shorter one.`,
 		`CLAN_CREATE_NAME_IN_USE`:   `The clan name specified is already in use. Please choose another one.`,
 		// Only used with default language
-		`CLAN_CREATE_DEFAULT_TITLE`: `Clan %1$s`,
+		`CLAN_CREATE_DEFAULT_TITLE`: `Clan %[1]s`,
 		// Only used with default language
-		`CLAN_CREATE_DEFAULT_MOTD`: `Welcome to clan %1$s!`,
-		`CLAN_CREATE_COMPLETE`:     `You have successfully created the clan %1$s.`,
+		`CLAN_CREATE_DEFAULT_MOTD`: `Welcome to clan %[1]s!`,
+		`CLAN_CREATE_COMPLETE`:     `You have successfully created the clan %[1]s.`,
 
-		`CLAN_JOIN_TOO_SOON`:  `You cannot join a new clan until at least %1$s hours have passed since leaving your previous clan.`,
+		`CLAN_JOIN_TOO_SOON`:  `You cannot join a new clan until at least %[1]s hours have passed since leaving your previous clan.`,
 		`CLAN_JOIN_NEED_CLAN`: `You must select a clan to join!`,
 		`CLAN_JOIN_DISBANDED`: `The clan you selected has already been disbanded.`,
 		`CLAN_JOIN_IS_FULL`:   `Sorry, that clan is currently full. Wait until somebody leaves the clan or more empires have been created, then try again.`,
-		`CLAN_JOIN_COMPLETE`:  `You are now a member of %1$s.`,
+		`CLAN_JOIN_COMPLETE`:  `You are now a member of %[1]s.`,
 
 		`CLAN_INVITE_NEED_INVITE`:  `You have not been invited to join that clan!`,
 		`CLAN_INVITE_WRONG_EMPIRE`: `The invitation you selected was for another empire!`,
 		`CLAN_INVITE_WRONG_CLAN`:   `The invitation you selected was for a different clan!`,
 
-		`CLAN_LEAVE_TOO_SOON`:         `You cannot leave this clan until you have been a member for at least %1$s hours.`,
+		`CLAN_LEAVE_TOO_SOON`:         `You cannot leave this clan until you have been a member for at least %[1]s hours.`,
 		`CLAN_LEAVE_NEED_CONFIRM`:     `You must check the confirmation box in order to leave your clan!`,
 		`CLAN_LEAVE_OTHER_MEMBERS`:    `You cannot simply abandon the other members of your clan!`,
-		`CLAN_LEAVE_COMPLETE_DISBAND`: `Your clan, %1$s, has been disbanded.`,
-		`CLAN_LEAVE_COMPLETE`:         `You have left the clan %1$s.`,
+		`CLAN_LEAVE_COMPLETE_DISBAND`: `Your clan, %[1]s, has been disbanded.`,
+		`CLAN_LEAVE_COMPLETE`:         `You have left the clan %[1]s.`,
 
-		`CLAN_SHARE_COMPLETE`:   `You are now using your forces to help fellow clan members.`,
-		`CLAN_UNSHARE_COMPLETE`: `Your forces will be available for your own defense in %1$s hours.`,
+		`CLAN_SHARE_ALREADY`:       `You are already using your forces to help fellow clan members!`,
+		`CLAN_SHARE_COMPLETE`:      `You are now using your forces to help fellow clan members.`,
+		`CLAN_UNSHARE_NOT_SHARING`: `You are not using your forces to help fellow clan members!`,
+		`CLAN_UNSHARE_COMPLETE`:    `Your forces will be available for your own defense in %[1]s hours.`,
 
-		`CLAN_LINK_LABEL`:          `%1$s's Home Page`,
-		`CLAN_MEMBER_HEADER`:       `Clan Info for <i>%1$s</i>`,
+		`CLAN_LINK_LABEL`:          `%[1]s's Home Page`,
+		`CLAN_MEMBER_HEADER`:       `Clan Info for <i>%[1]s</i>`,
 		`CLAN_LINK_FORUM`:          `Clan Forum`,
 		`CLAN_LINK_MANAGE`:         `Clan Management`,
-		`CLAN_RELATIONS_HEADER`:    `%1$s Relations`,
+		`CLAN_RELATIONS_HEADER`:    `%[1]s Relations`,
 		`CLAN_ALLY_LABEL`:          `Alliances`,
 		`CLAN_ALLY_DESC`:           `Cannot attack<br />Unlimited aid`,
 		`CLAN_WAR_LABEL`:           `Wars`,
@@ -148,13 +150,13 @@ var (
 		`CLAN_WAR_NONE_LABEL`:      `No Wars`,
 		`CLAN_UNSHARE_SUBMIT`:      `Don't Use my Forces for Clan Defense`,
 		`CLAN_SHARE_SUBMIT`:        `Use my Forces for Clan Defense`,
-		`CLAN_MEMBERS_HEADER`:      `%1$s currently has %2$s members.`,
+		`CLAN_MEMBERS_HEADER`:      `%[1]s currently has %[2]s members.`,
 		`CLAN_MEMBERS_LABEL`:       `Member List`,
 		`CLAN_LEADER_LABEL`:        `Leader`,
 		`CLAN_ASSISTANT_LABEL`:     `Assistant`,
 		`CLAN_FA_LABEL`:            `Minister`,
 		`CLAN_SHARE_HEADER`:        `Clan Shared Defense`,
-		`CLAN_UNSHARE_HEADER`:      ` (for %1$s minutes longer)`,
+		`CLAN_UNSHARE_HEADER`:      ` (for %[1]s minutes longer)`,
 		`CLAN_DISBAND_SUBMIT`:      `Disband Clan`,
 		`CLAN_DISBAND_CONFIRM`:     `Yes, I really want to disband!`,
 		`CLAN_LEAVE_SUBMIT`:        `Leave Clan`,
@@ -162,7 +164,7 @@ var (
 
 		`CLAN_NONMEMBER_HEADER`:      `The world can be a dangerous place - you would be wise to create or join a clan for added protection.`,
 		`CLAN_JOIN_LABEL`:            `Join a Clan`,
-		`CLAN_JOIN_LABEL_WITH_TITLE`: `%1$s - %2$s`,
+		`CLAN_JOIN_LABEL_WITH_TITLE`: `%[1]s - %[2]s`,
 		`CLAN_JOIN_SUBMIT`:           `Join Clan`,
 
 		`CLAN_INVITE_HEADER`:     `Clan Invitations`,
@@ -276,24 +278,41 @@ var (
 		`DELETE_SUBMIT`:          `Delete Empire`,
 
 		// pages/demolish
-		`DEMOLISH_TITLE`:            `Demolition`,
-		`DEMOLISH_TOO_MANY`:         `You cannot demolish more structures than you have!`,
-		`DEMOLISH_NOT_ENOUGH_TURNS`: `You do not have enough turns to demolish that many structures!`,
-		`DEMOLISH_COMPLETE`:         `You spent %1$s demolishing %2$s and salvaged %3$s in the process.`,
-		`DEMOLISH_DROP_TOO_MUCH`:    `You cannot drop that much land!`,
-		`DEMOLISH_DROP_COMPLETE`:    `You spent %1$s dropping %2$s of land.`,
-		`DEMOLISH_HEADER`:           `Each structure demolished frees up one acre of land and %1$s can be salvaged.<br />We can demolish %2$s structures per turn.<br />With our resources, we can demolish <span class="cbad">%3$s</span> structures.<br />`,
-		`DEMOLISH_SUBMIT`:           `Begin Demolition`,
-		`DEMOLISH_DROP_HEADER`:      `We can also drop up to <span class="cbad">%1$s</span> unused acres of land, at %2$s acres per turn.<br />`,
-		`DEMOLISH_DROP_SUBMIT`:      `Drop Land`,
-		`DEMOLISH_LINK_BUILD`:       `Build Structures`,
+		`DEMOLISH_TITLE`:             `Demolition`,
+		`DEMOLISH_UNAVAILABLE_START`: `Structures cannot be demolished before the round has begun.`,
+		`DEMOLISH_UNAVAILABLE_END`:   `Structures cannot be demolished after the round has ended.`,
+		`DEMOLISH_TOO_MANY`:          `You cannot demolish more structures than you have!`,
+		`DEMOLISH_NOT_ENOUGH_TURNS`:  `You do not have enough turns to demolish that many structures!`,
+		`DEMOLISH_COMPLETE`:          `You spent %[1]s demolishing %[2]s and salvaged %[3]s in the process.`,
+		`DEMOLISH_DROP_TOO_MUCH`:     `You cannot drop that much land!`,
+		`DEMOLISH_DROP_COMPLETE`:     `You spent %[1]s dropping %[2]s of land.`,
+		`DEMOLISH_HEADER`:            `Each structure demolished frees up one acre of land and %[1]s can be salvaged.<br />We can demolish %[2]s structures per turn.<br />With our resources, we can demolish <span class="cbad">%[3]s</span> structures.<br />`,
+		`DEMOLISH_SUBMIT`:            `Begin Demolition`,
+		`DEMOLISH_DROP_HEADER`:       `We can also drop up to <span class="cbad">%[1]s</span> unused acres of land, at %[2]s acres per turn.<br />`,
+		`DEMOLISH_DROP_SUBMIT`:       `Drop Land`,
+		`DEMOLISH_LINK_BUILD`:        `Build Structures`,
+
+		// pages/era
+		`ERACHANGE_TITLE`:             `Era Change`,
+		`ERACHANGE_UNAVAILABLE_START`: `You cannot change eras before the round has begun.`,
+		`ERACHANGE_UNAVAILABLE_END`:   `You cannot change eras after the round has ended.`,
+		`ERACHANGE_DISABLED`:          `Regressing to a previous era is not enabled in this game.`,
+		`ERACHANGE_NO_NEXT`:           `There is no era for you to advance to!`,
+		`ERACHANGE_NO_PREV`:           `There is no era for you to regress to!`,
+		`ERACHANGE_TOO_SOON`:          `Your empire must spend another %[1]s in the %[2]s before it can change eras.`,
+		`ERACHANGE_COMPLETE`:          `Your empire is now in the %[1]s, and must spend %[2]s here before it can change eras again.`,
+		`ERACHANGE_HEADER`:            `Your empire is in the %[1]s. Your %[2]s can move it to another era, but it must then spend at least %[3]s there before moving again.`,
+		`ERACHANGE_COST`:              `Requires %[1]s %[2]s and %[3]s.`,
+		`ERACHANGE_WAIT`:              `Available in %[1]s.`,
+		`ERACHANGE_GATE_OPEN`:         `Your %[1]s is open; you may interact with empires in any era.`,
+		`ERACHANGE_GATE_CLOSED`:       `You may only interact with empires in other eras while a %[1]s is open.`,
 
 		// pages/farm
 		`FARM_TITLE`:            `Agricultural Focus`,
 		`FARM_NOT_ENOUGH_TURNS`: `You cannot spend that many turns farming!`,
 		`FARM_SPECIFY_TURNS`:    `Please specify a number of turns to spend farming.`,
-		`FARM_COMPLETE`:         `You produced a total of %1$s %2$s in %3$s.`,
-		`FARM_HEADER`:           `For each turn you spend focusing on agriculture, your %1$s will produce 25%% more %2$s.`,
+		`FARM_COMPLETE`:         `You produced a total of %[1]s %[2]s in %[3]s.`,
+		`FARM_HEADER`:           `For each turn you spend focusing on agriculture, your %[1]s will produce 25%% more %[2]s.`,
 		`FARM_LABEL`:            `Spend how many turns farming?`,
 		`FARM_SUBMIT`:           `Farm`,
 
@@ -335,8 +354,8 @@ var (
 		`LAND_TITLE`:            `Exploration`,
 		`LAND_NOT_ENOUGH_TURNS`: `You cannot spend that many turns exploring!`,
 		`LAND_SPECIFY_TURNS`:    `Please specify a number of turns to spend exploring.`,
-		`LAND_COMPLETE`:         `Your empire grew by %1$s in %2$s.`,
-		`LAND_HEADER`:           `For each turn you spend exploring, your empire will expand by up to %1$s.`,
+		`LAND_COMPLETE`:         `Your empire grew by %[1]s in %[2]s.`,
+		`LAND_HEADER`:           `For each turn you spend exploring, your empire will expand by up to %[1]s.`,
 		`LAND_LABEL`:            `Spend how many turns exploring?`,
 		`LAND_SUBMIT`:           `Explore`,
 
@@ -365,17 +384,17 @@ var (
 		`LOTTERY_END_ROUND`:         `The final lottery of the round has been drawn - no more tickets can be purchased.`,
 		`LOTTERY_TOO_MANY_TICKETS`:  `You cannot buy any more tickets today.`,
 		`LOTTERY_NOT_ENOUGH_MONEY`:  `You do not have enough money to buy a lottery ticket.`,
-		`LOTTERY_COMPLETE`:          `You have purchased ticket %1$s for %2$s.`,
-		`LOTTERY_DESC`:              `Buying a lottery ticket is a way to potentially make a lot of extra money.<br />Money spent on lottery tickets is added to the Jackpot, which will continue to increase until a winner is selected.<br />Every day at %1$s, a number will be randomly selected - if a matching ticket has been purchased, the jackpot will be awarded to the empire holding it.`,
-		`LOTTERY_NEXTTICKET`:        `Your next ticket will cost %1$s and will be valid for one drawing.`,
-		`LOTTERY_MAXALLOWED`:        `You can buy up to %1$s every day.`,
+		`LOTTERY_COMPLETE`:          `You have purchased ticket %[1]s for %[2]s.`,
+		`LOTTERY_DESC`:              `Buying a lottery ticket is a way to potentially make a lot of extra money.<br />Money spent on lottery tickets is added to the Jackpot, which will continue to increase until a winner is selected.<br />Every day at %[1]s, a number will be randomly selected - if a matching ticket has been purchased, the jackpot will be awarded to the empire holding it.`,
+		`LOTTERY_NEXTTICKET`:        `Your next ticket will cost %[1]s and will be valid for one drawing.`,
+		`LOTTERY_MAXALLOWED`:        `You can buy up to %[1]s every day.`,
 		`LOTTERY_CURJACKPOT`:        `Current Jackpot:`,
 		`LOTTERY_TOTALBOUGHT`:       `Tickets purchased for next drawing:`,
-		`LOTTERY_LAST_WINNER`:       `The last lottery number selected was %1$s, and a matching ticket was found - %2$s has won %3$s!`,
-		`LOTTERY_LAST_NOWINNER`:     `The last lottery number selected was %1$s; however, no matching ticket was found - the jackpot has increased by %2$s.`,
-		`LOTTERY_LAST_NOWINNER_END`: `The last lottery number selected was %1$s; however, no matching ticket was found - the final jackpot rests at %2$s.`,
+		`LOTTERY_LAST_WINNER`:       `The last lottery number selected was %[1]s, and a matching ticket was found - %[2]s has won %[3]s!`,
+		`LOTTERY_LAST_NOWINNER`:     `The last lottery number selected was %[1]s; however, no matching ticket was found - the jackpot has increased by %[2]s.`,
+		`LOTTERY_LAST_NOWINNER_END`: `The last lottery number selected was %[1]s; however, no matching ticket was found - the final jackpot rests at %[2]s.`,
 		`LOTTERY_TICKET_LISTSEP`:    ` : `,
-		`LOTTERY_HAVE_TICKETS`:      `You have the following lottery tickets: %1$s`,
+		`LOTTERY_HAVE_TICKETS`:      `You have the following lottery tickets: %[1]s`,
 		`LOTTERY_HAVE_NO_TICKETS`:   `You currently have no lottery tickets.`,
 		`LOTTERY_CANT_AFFORD`:       `You check your empire's treasury and find you don't have enough for a lottery ticket.`,
 		`LOTTERY_SUBMIT`:            `Buy a Ticket`,
@@ -387,15 +406,15 @@ var (
 		`MAGIC_SELF_ILLEGAL`:        `You cannot cast that spell on yourself!`,
 		`MAGIC_SELF_INVALID`:        `You cannot cast that spell on yourself right now!`,
 		`MAGIC_SELF_INVALID_AGAIN`:  `You cannot cast that spell on yourself again!`,
-		`MAGIC_NEED_RUNES`:          `You do not have enough %1$s to cast that spell!`,
-		`MAGIC_NEED_RUNES_AGAIN`:    `You do not have enough %1$s to cast that spell again!`,
+		`MAGIC_NEED_RUNES`:          `You do not have enough %[1]s to cast that spell!`,
+		`MAGIC_NEED_RUNES_AGAIN`:    `You do not have enough %[1]s to cast that spell again!`,
 		`MAGIC_NEED_TURNS`:          `You do not have enough turns to cast that spell!`,
 		`MAGIC_NEED_TURNS_AGAIN`:    `You do not have enough turns to cast that spell again!`,
-		`MAGIC_NEED_HEALTH`:         `Your %1$s are too weak to cast any spells!`,
-		`MAGIC_NEED_HEALTH_AGAIN`:   `Your %1$s are too weak to cast any more spells!`,
-		`MAGIC_NEED_WIZARDS`:        `You have no %1$s with which to cast spells!`,
-		`MAGIC_BEGIN`:               `Your %1$s begin to concentrate...`,
-		`MAGIC_TROUBLE_ABORT`:       `Faced with such a crisis, your %1$s lose their concentration and fail to cast the spell.`,
+		`MAGIC_NEED_HEALTH`:         `Your %[1]s are too weak to cast any spells!`,
+		`MAGIC_NEED_HEALTH_AGAIN`:   `Your %[1]s are too weak to cast any more spells!`,
+		`MAGIC_NEED_WIZARDS`:        `You have no %[1]s with which to cast spells!`,
+		`MAGIC_BEGIN`:               `Your %[1]s begin to concentrate...`,
+		`MAGIC_TROUBLE_ABORT`:       `Faced with such a crisis, your %[1]s lose their concentration and fail to cast the spell.`,
 		`MAGIC_SELF_COMPLETE`:       `Finished casting spell.`,
 		`MAGIC_SELF_COMPLETE_AGAIN`: `Finished casting spells.`,
 
@@ -494,42 +513,42 @@ var (
 		`MANAGE_CLAN_CLAN_IS_GONE`:     `The clan you selected no longer exists!`,
 
 		`MANAGE_CLAN_ALLY_REQUEST_TOO_MANY`:                  `Your clan cannot request any more alliances at this time.`,
-		`MANAGE_CLAN_ALLY_REQUEST_ALREADY_ALLY_ACCEPTED`:     `You have already established an alliance with <b>%1$s</b>!`,
-		`MANAGE_CLAN_ALLY_REQUEST_ALREADY_ALLY_REQUESTED`:    `You have already requested an alliance with <b>%1$s</b>!`,
-		`MANAGE_CLAN_ALLY_REQUEST_ALREADY_ALLY_PENDING`:      `<b>%1$s</b> has already requested an alliance with you!`,
-		`MANAGE_CLAN_ALLY_REQUEST_ALREADY_WAR_DECLARED_IN`:   `You cannot request an alliance with <b>%1$s</b> - they are at war with you!`,
-		`MANAGE_CLAN_ALLY_REQUEST_ALREADY_WAR_DECLARED_OUT`:  `You cannot request an alliance with <b>%1$s</b> - you are at war with them!`,
-		`MANAGE_CLAN_ALLY_REQUEST_ALREADY_WAR_REQUESTED_IN`:  `You cannot request an alliance with <b>%1$s</b> yet - you must first accept their peace treaty!`,
-		`MANAGE_CLAN_ALLY_REQUEST_ALREADY_WAR_REQUESTED_OUT`: `You cannot request an alliance with <b>%1$s</b> yet - you must first allow them to accept your peace treaty!`,
-		`MANAGE_CLAN_ALLY_REQUEST_COMPLETE`:                  `You have requested an alliance with <b>%1$s</b>.`,
+		`MANAGE_CLAN_ALLY_REQUEST_ALREADY_ALLY_ACCEPTED`:     `You have already established an alliance with <b>%[1]s</b>!`,
+		`MANAGE_CLAN_ALLY_REQUEST_ALREADY_ALLY_REQUESTED`:    `You have already requested an alliance with <b>%[1]s</b>!`,
+		`MANAGE_CLAN_ALLY_REQUEST_ALREADY_ALLY_PENDING`:      `<b>%[1]s</b> has already requested an alliance with you!`,
+		`MANAGE_CLAN_ALLY_REQUEST_ALREADY_WAR_DECLARED_IN`:   `You cannot request an alliance with <b>%[1]s</b> - they are at war with you!`,
+		`MANAGE_CLAN_ALLY_REQUEST_ALREADY_WAR_DECLARED_OUT`:  `You cannot request an alliance with <b>%[1]s</b> - you are at war with them!`,
+		`MANAGE_CLAN_ALLY_REQUEST_ALREADY_WAR_REQUESTED_IN`:  `You cannot request an alliance with <b>%[1]s</b> yet - you must first accept their peace treaty!`,
+		`MANAGE_CLAN_ALLY_REQUEST_ALREADY_WAR_REQUESTED_OUT`: `You cannot request an alliance with <b>%[1]s</b> yet - you must first allow them to accept your peace treaty!`,
+		`MANAGE_CLAN_ALLY_REQUEST_COMPLETE`:                  `You have requested an alliance with <b>%[1]s</b>.`,
 		`MANAGE_CLAN_ALLY_CANCEL_TOO_SOON`:                   `You cannot cancel an alliance request so soon!`,
-		`MANAGE_CLAN_ALLY_CANCEL_COMPLETE`:                   `You have retracted your clan's request for alliance with <b>%1$s</b>.`,
+		`MANAGE_CLAN_ALLY_CANCEL_COMPLETE`:                   `You have retracted your clan's request for alliance with <b>%[1]s</b>.`,
 		`MANAGE_CLAN_ALLY_ACCEPT_SELF_TOO_MANY`:              `Your clan cannot form any more alliances at this time.`,
 		`MANAGE_CLAN_ALLY_ACCEPT_OTHER_TOO_MANY`:             `Your companion clan cannot form any more alliances at this time.`,
-		`MANAGE_CLAN_ALLY_ACCEPT_COMPLETE`:                   `You have accepted <b>%1$s</b>'s request for an alliance!`,
-		`MANAGE_CLAN_ALLY_DENY_COMPLETE`:                     `You have declined <b>%1$s</b>'s request for an alliance.`,
+		`MANAGE_CLAN_ALLY_ACCEPT_COMPLETE`:                   `You have accepted <b>%[1]s</b>'s request for an alliance!`,
+		`MANAGE_CLAN_ALLY_DENY_COMPLETE`:                     `You have declined <b>%[1]s</b>'s request for an alliance.`,
 		`MANAGE_CLAN_ALLY_STOP_NEED_CONFIRM`:                 `You must check the confirmation box in order to terminate an alliance.`,
 		`MANAGE_CLAN_ALLY_STOP_TOO_SOON`:                     `You cannot terminate an alliance so soon!`,
-		`MANAGE_CLAN_ALLY_STOP_COMPLETE`:                     `You have terminated your clan's alliance with <b>%1$s</b>.`,
+		`MANAGE_CLAN_ALLY_STOP_COMPLETE`:                     `You have terminated your clan's alliance with <b>%[1]s</b>.`,
 
 		`MANAGE_CLAN_WAR_DECLARE_TOO_MANY`:                  `Your clan does not have the resources to engage in any more wars.`,
-		`MANAGE_CLAN_WAR_DECLARE_ALREADY_ALLY_ACCEPTED`:     `You cannot declare war with <b>%1$s</b> - they are your allies!`,
-		`MANAGE_CLAN_WAR_DECLARE_ALREADY_ALLY_REQUESTED`:    `You cannot declare war with <b>%1$s</b> yet - you must first retract your request for alliance!`,
-		`MANAGE_CLAN_WAR_DECLARE_ALREADY_ALLY_PENDING`:      `You cannot declare war with <b>%1$s</b> yet - you must first decline their request for alliance!`,
-		`MANAGE_CLAN_WAR_DECLARE_ALREADY_WAR_DECLARED_IN`:   `<b>%1$s</b> is already at war with you!`,
-		`MANAGE_CLAN_WAR_DECLARE_ALREADY_WAR_DECLARED_OUT`:  `You are already at war with <b>%1$s</b>!`,
-		`MANAGE_CLAN_WAR_DECLARE_ALREADY_WAR_REQUESTED_IN`:  `<b>%1$s</b> has already requested a peace treaty with you!`,
-		`MANAGE_CLAN_WAR_DECLARE_ALREADY_WAR_REQUESTED_OUT`: `You have already requested a peace treaty with <b>%1$s</b>!`,
-		`MANAGE_CLAN_WAR_DECLARE_COMPLETE`:                  `You have declared war with <b>%1$s</b>.`,
+		`MANAGE_CLAN_WAR_DECLARE_ALREADY_ALLY_ACCEPTED`:     `You cannot declare war with <b>%[1]s</b> - they are your allies!`,
+		`MANAGE_CLAN_WAR_DECLARE_ALREADY_ALLY_REQUESTED`:    `You cannot declare war with <b>%[1]s</b> yet - you must first retract your request for alliance!`,
+		`MANAGE_CLAN_WAR_DECLARE_ALREADY_ALLY_PENDING`:      `You cannot declare war with <b>%[1]s</b> yet - you must first decline their request for alliance!`,
+		`MANAGE_CLAN_WAR_DECLARE_ALREADY_WAR_DECLARED_IN`:   `<b>%[1]s</b> is already at war with you!`,
+		`MANAGE_CLAN_WAR_DECLARE_ALREADY_WAR_DECLARED_OUT`:  `You are already at war with <b>%[1]s</b>!`,
+		`MANAGE_CLAN_WAR_DECLARE_ALREADY_WAR_REQUESTED_IN`:  `<b>%[1]s</b> has already requested a peace treaty with you!`,
+		`MANAGE_CLAN_WAR_DECLARE_ALREADY_WAR_REQUESTED_OUT`: `You have already requested a peace treaty with <b>%[1]s</b>!`,
+		`MANAGE_CLAN_WAR_DECLARE_COMPLETE`:                  `You have declared war with <b>%[1]s</b>.`,
 		`MANAGE_CLAN_WAR_REQUEST_NEED_CONFIRM`:              `You must check the confirmation box in order to propose a treaty.`,
 		`MANAGE_CLAN_WAR_REQUEST_TOO_SOON`:                  `You cannot end a war so soon!`,
-		`MANAGE_CLAN_WAR_REQUEST_COMPLETE`:                  `You have proposed a peace treaty with <b>%1$s</b>.`,
+		`MANAGE_CLAN_WAR_REQUEST_COMPLETE`:                  `You have proposed a peace treaty with <b>%[1]s</b>.`,
 		`MANAGE_CLAN_WAR_RESUME_TOO_SOON`:                   `You cannot resume a war so soon!`,
-		`MANAGE_CLAN_WAR_RESUME_COMPLETE`:                   `You have retracted your peace treaty proposition from <b>%1$s</b>.`,
+		`MANAGE_CLAN_WAR_RESUME_COMPLETE`:                   `You have retracted your peace treaty proposition from <b>%[1]s</b>.`,
 		`MANAGE_CLAN_WAR_REJECT_NEED_CONFIRM`:               `You must check the confirmation box in order to reject a treaty.`,
 		`MANAGE_CLAN_WAR_REJECT_TOO_MANY`:                   `Your clan does not have the resources to take the offensive in this war.`,
-		`MANAGE_CLAN_WAR_REJECT_COMPLETE`:                   `You have rejected <b>%1$s</b>'s peace treaty!`,
-		`MANAGE_CLAN_WAR_STOP_COMPLETE`:                     `You accept <b>%1$s</b>'s peace treaty and end the war.`,
+		`MANAGE_CLAN_WAR_REJECT_COMPLETE`:                   `You have rejected <b>%[1]s</b>'s peace treaty!`,
+		`MANAGE_CLAN_WAR_STOP_COMPLETE`:                     `You accept <b>%[1]s</b>'s peace treaty and end the war.`,
 
 		`MANAGE_CLAN_PASSWORD_NEED_PERMISSION`: `You are not allowed to change your clan's password!`,
 		`MANAGE_CLAN_PASSWORD_COMPLETE`:        `Your clan password has been changed.`,
@@ -550,20 +569,20 @@ var (
 		`MANAGE_CLAN_RANKS_NEED_PERMISSION`:          `You are not allowed to alter clan ranks or remove members!`,
 		`MANAGE_CLAN_RANKS_REMOVE_NOT_IN_CLAN`:       `That empire is not in this clan!`,
 		`MANAGE_CLAN_RANKS_REMOVE_IS_SPECIAL`:        `You may not remove a member who is in a position of authority!`,
-		`MANAGE_CLAN_RANKS_REMOVE_COMPLETE`:          `You have removed <b>%1$s</b> from your clan.`,
+		`MANAGE_CLAN_RANKS_REMOVE_COMPLETE`:          `You have removed <b>%[1]s</b> from your clan.`,
 		`MANAGE_CLAN_RANKS_CHANGE_LEADER_PERMISSION`: `Only the current clan leader can appoint a new leader!`,
 		`MANAGE_CLAN_RANKS_CHANGE_ASST_PERMISSION`:   `Only the current clan leader can appoint a new assistant leader!`,
-		`MANAGE_CLAN_RANKS_ASST_REMOVED`:             `<b>%1$s</b> is no longer the Assistant Leader of <b>%2$s</b>.`,
-		`MANAGE_CLAN_RANKS_FA_REMOVED`:               `<b>%1$s</b> is no longer a Minister of Foreign Affairs of <b>%2$s</b>.`,
+		`MANAGE_CLAN_RANKS_ASST_REMOVED`:             `<b>%[1]s</b> is no longer the Assistant Leader of <b>%[2]s</b>.`,
+		`MANAGE_CLAN_RANKS_FA_REMOVED`:               `<b>%[1]s</b> is no longer a Minister of Foreign Affairs of <b>%[2]s</b>.`,
 		`MANAGE_CLAN_RANKS_NEED_LEADER`:              `You must select an empire to be your clan leader!`,
 		`MANAGE_CLAN_RANKS_CONFLICT`:                 `You cannot assign multiple positions of authority to a single empire!`,
 		`MANAGE_CLAN_RANKS_WRONG_CLAN`:               `You cannot assign a position of authority to an empire not in our clan!`,
 		`MANAGE_CLAN_RANKS_LEADER_GONE`:              `The empire you selected for clan leader does not exist!`,
-		`MANAGE_CLAN_RANKS_LEADER_ADDED`:             `<b>%1$s</b> is now the Leader of <b>%2$s</b>.`,
+		`MANAGE_CLAN_RANKS_LEADER_ADDED`:             `<b>%[1]s</b> is now the Leader of <b>%[2]s</b>.`,
 		`MANAGE_CLAN_RANKS_ASST_GONE`:                `The empire you selected for assistant leader does not exist!`,
-		`MANAGE_CLAN_RANKS_ASST_ADDED`:               `<b>%1$s</b> is now the Assistant Leader of <b>%2$s</b>.`,
+		`MANAGE_CLAN_RANKS_ASST_ADDED`:               `<b>%[1]s</b> is now the Assistant Leader of <b>%[2]s</b>.`,
 		`MANAGE_CLAN_RANKS_FA_GONE`:                  `The empire you selected for Minister of Foreign Affairs does not exist!`,
-		`MANAGE_CLAN_RANKS_FA_ADDED`:                 `<b>%1$s</b> is now a Minister of Foreign Affairs of <b>%2$s</b>.`,
+		`MANAGE_CLAN_RANKS_FA_ADDED`:                 `<b>%[1]s</b> is now a Minister of Foreign Affairs of <b>%[2]s</b>.`,
 
 		`MANAGE_CLAN_INVITE_DEAD`:                 `That empire has already been destroyed.`,
 		`MANAGE_CLAN_INVITE_DELETED`:              `That empire has already been deleted.`,
@@ -574,16 +593,16 @@ var (
 		`MANAGE_CLAN_INVITE_WRONG_CLAN`:           `That empire has already joined another clan!`,
 		`MANAGE_CLAN_INVITE_ALREADY_PERM`:         `That empire has already been permanently invited to this clan.`,
 		`MANAGE_CLAN_INVITE_ALREADY_INVITED`:      `That empire has already been invited to this clan.`,
-		`MANAGE_CLAN_INVITE_COMPLETE_PERM`:        `<b>%1$s</b> has been permanently invited to join <b>%2$s</b>.`,
-		`MANAGE_CLAN_INVITE_COMPLETE_TEMP`:        `<b>%1$s</b> has been invited to join <b>%2$s</b>.`,
+		`MANAGE_CLAN_INVITE_COMPLETE_PERM`:        `<b>%[1]s</b> has been permanently invited to join <b>%[2]s</b>.`,
+		`MANAGE_CLAN_INVITE_COMPLETE_TEMP`:        `<b>%[1]s</b> has been invited to join <b>%[2]s</b>.`,
 
 		`MANAGE_CLAN_UNINVITE_NOT_EXIST`:            `The invitation you selected does not exist!`,
 		`MANAGE_CLAN_UNINVITE_WRONG_CLAN`:           `The invitation you selected does not belong to this clan!`,
 		`MANAGE_CLAN_UNINVITE_PERM_NEED_PERMISSION`: `You do not have permission to remove permanent invitations!`,
-		`MANAGE_CLAN_UNINVITE_COMPLETE`:             `<b>%1$s</b>'s invitation has been removed.`,
+		`MANAGE_CLAN_UNINVITE_COMPLETE`:             `<b>%[1]s</b>'s invitation has been removed.`,
 
-		`MANAGE_CLAN_HEADER`:                              `Clan Management for <i>%1$s</i>`,
-		`MANAGE_CLAN_RELATION_STATUS`:                     `Status: <b>%1$s</b><br />since %2$s`,
+		`MANAGE_CLAN_HEADER`:                              `Clan Management for <i>%[1]s</i>`,
+		`MANAGE_CLAN_RELATION_STATUS`:                     `Status: <b>%[1]s</b><br />since %[2]s`,
 		`MANAGE_CLAN_RELATION_STATUS_ALLY_MUTUAL`:         `Active`,
 		`MANAGE_CLAN_RELATION_STATUS_ALLY_OUTBOUND`:       `Requested`,
 		`MANAGE_CLAN_RELATION_STATUS_ALLY_INBOUND`:        `Pending`,
@@ -609,7 +628,7 @@ var (
 		`MANAGE_CLAN_WAR_DECLARE_LABEL`:   `Declare War on`,
 		`MANAGE_CLAN_WAR_DECLARE_SUBMIT`:  `Send Declaration`,
 
-		`MANAGE_CLAN_RELATION_WARNING`:       `The leaders of these clans will be notified of the actions you take here!<br />Once you take action on a relation, you must wait %1$s hours before you will be allowed to change it again!`,
+		`MANAGE_CLAN_RELATION_WARNING`:       `The leaders of these clans will be notified of the actions you take here!<br />Once you take action on a relation, you must wait %[1]s hours before you will be allowed to change it again!`,
 		`MANAGE_CLAN_RELATION_REMOVE`:        `Remove?`,
 		`MANAGE_CLAN_PASSWORD_LABEL`:         `Change Clan Password`,
 		`MANAGE_CLAN_PASSWORD_SUBMIT`:        `Change Password`,
@@ -619,7 +638,7 @@ var (
 		`MANAGE_CLAN_LOGO_SUBMIT`:            `Change Logo`,
 		`MANAGE_CLAN_URL_LABEL`:              `Change Clan Homepage URL`,
 		`MANAGE_CLAN_URL_SUBMIT`:             `Change URL`,
-		`MANAGE_CLAN_INVITE_LABEL`:           `Send an invitation to empire %1$s`,
+		`MANAGE_CLAN_INVITE_LABEL`:           `Send an invitation to empire %[1]s`,
 		`MANAGE_CLAN_INVITE_PERM_LABEL`:      `Make this invitation permanent`,
 		`MANAGE_CLAN_INVITE_SUBMIT`:          `Invite`,
 		`MANAGE_CLAN_UNINVITE_EMPIRE_LABEL`:  `Empire`,
@@ -650,8 +669,12 @@ var (
 		`MANAGE_EMPIRE_TAX_TOO_LOW`:            `Cannot set your tax that low!`,
 		`MANAGE_EMPIRE_TAX_TOO_HIGH`:           `Cannot set your tax that high!`,
 		`MANAGE_EMPIRE_TAX_COMPLETE`:           `Tax rate updated.`,
-		`MANAGE_EMPIRE_INDUSTRY_TOO_HIGH`:      `You cannot allocate more than 100% of your resources!`,
+		`MANAGE_EMPIRE_INDUSTRY_TOO_HIGH`:      `You cannot allocate more than 100%% of your resources!`,
 		`MANAGE_EMPIRE_INDUSTRY_COMPLETE`:      `Industrial resource allocation updated.`,
+		`MANAGE_EMPIRE_RELEASE_UNAVAILABLE`:    `Units cannot be released before the round has begun or after it has ended.`,
+		`MANAGE_EMPIRE_RELEASE_NEED_UNITS`:     `You must specify the units to release!`,
+		`MANAGE_EMPIRE_RELEASE_TOO_MANY`:       `You cannot release more units than you have!`,
+		`MANAGE_EMPIRE_RELEASE_COMPLETE`:       `%[1]s units have been released from service and returned home as peasants.`,
 		`MANAGE_EMPIRE_VACATION_DISABLED`:      `You cannot go on vacation in this game.`,
 		`MANAGE_EMPIRE_VACATION_UNAVAILABLE`:   `You cannot go on vacation at this time.`,
 		`MANAGE_EMPIRE_VACATION_NEED_CONFIRM`:  `You must check the confirmation box in order to go on vacation.`,
@@ -670,7 +693,11 @@ var (
 		`MANAGE_EMPIRE_TAX_LABEL`:              `Tax Rate:`,
 		`MANAGE_EMPIRE_TAX_SUBMIT`:             `Change Tax Rate`,
 		`MANAGE_EMPIRE_INDUSTRY_LABEL`:         `Industry Settings`,
+		`MANAGE_EMPIRE_INDUSTRY_PRODUCTION`:    `Per Turn`,
+		`MANAGE_EMPIRE_INDUSTRY_PERCENT`:       `Allocation`,
 		`MANAGE_EMPIRE_INDUSTRY_SUBMIT`:        `Update Industry`,
+		`MANAGE_EMPIRE_RELEASE_LABEL`:          `Release Units`,
+		`MANAGE_EMPIRE_RELEASE_SUBMIT`:         `Release Units`,
 		`MANAGE_EMPIRE_VACATION_LABEL`:         `Vacation`,
 		`MANAGE_EMPIRE_VACATION_EXPLAIN`:       `Vacation will be active for a minimum of %1$s hours.<br />When set, you will be immediately locked out of your account, and your empire will be placed under protection after %2$s hours have elapsed.<br />Once the end of the round draws near, your empire will be automatically removed from vacation after the %1$s hours have elapsed.`,
 		`MANAGE_EMPIRE_VACATION_CONFIRM`:       `Yes, I really want to go on vacation!`,
@@ -787,18 +814,18 @@ var (
 		`MILITARY_BEGIN`:                `You send forth your army...`,
 		`MILITARY_TIMEGATE_SELF`:        `Your Time Gate shimmers, allowing your army to pass through...`,
 		`MILITARY_TIMEGATE_OTHER`:       `Your target's Time Gate shimmers, allowing your army to pass through...`,
-		`MILITARY_ALLY_DEFENSE`:         `As your army moves forward, your target calls its clanmates for help, and reinforcements arrive from %1$s...`,
+		`MILITARY_ALLY_DEFENSE`:         `As your army moves forward, your target calls its clanmates for help, and reinforcements arrive from %[1]s...`,
 		`MILITARY_UNITLOSS_ROW`:         `%1$s %2$s`,
-		`MILITARY_SUCCESS_HEADER`:       `Your army breaks through %1$s's defenses and captures %2$s acres of land!`,
-		`MILITARY_SUCCESS_DESTROYED`:    `During your attack, you destroyed %1$s.`,
-		`MILITARY_SUCCESS_LOSSES`:       `In the struggle, you lost %1$s.`,
-		`MILITARY_FAILURE_HEADER`:       `After a failing struggle, your army is repelled by %1$s's defenses.`,
-		`MILITARY_FAILURE_DESTROYED`:    `Amidst the chaos, you managed to destroy %1$s.`,
-		`MILITARY_FAILURE_LOSSES`:       `In the attempt, you lost %1$s.`,
-		`MILITARY_STRUCTURES_GAINED`:    `On the land you captured, you gained %1$s!`,
-		`MILITARY_STRUCTURES_DESTROYED`: `You also demolished an additional %1$s on land you failed to capture!`,
-		`MILITARY_SUCCESS_KILLED`:       `<b>%1$s</b> has been destroyed!`,
-		`MILITARY_SUCCESS_KILLED_LATE`:  `<b>%1$s</b> has no remaining land.`,
+		`MILITARY_SUCCESS_HEADER`:       `Your army breaks through %[1]s's defenses and captures %[2]s acres of land!`,
+		`MILITARY_SUCCESS_DESTROYED`:    `During your attack, you destroyed %[1]s.`,
+		`MILITARY_SUCCESS_LOSSES`:       `In the struggle, you lost %[1]s.`,
+		`MILITARY_FAILURE_HEADER`:       `After a failing struggle, your army is repelled by %[1]s's defenses.`,
+		`MILITARY_FAILURE_DESTROYED`:    `Amidst the chaos, you managed to destroy %[1]s.`,
+		`MILITARY_FAILURE_LOSSES`:       `In the attempt, you lost %[1]s.`,
+		`MILITARY_STRUCTURES_GAINED`:    `On the land you captured, you gained %[1]s!`,
+		`MILITARY_STRUCTURES_DESTROYED`: `You also demolished an additional %[1]s on land you failed to capture!`,
+		`MILITARY_SUCCESS_KILLED`:       `<b>%[1]s</b> has been destroyed!`,
+		`MILITARY_SUCCESS_KILLED_LATE`:  `<b>%[1]s</b> has no remaining land.`,
 
 		`MILITARY_SELF`:             `You cannot attack yourself!`,
 		`MILITARY_INVALID`:          `Invalid attack type selected!`,
@@ -875,13 +902,13 @@ var (
 		`PUBMARKETBUY_UNAVAILABLE_END`:     `The public market cannot be accessed after the round has ended.`,
 		`PUBMARKETBUY_UNAVAILABLE_PROTECT`: `The public market cannot be accessed while still under protection.`,
 		`PUBMARKETBUY_UNAVAILABLE_ADMIN`:   `The public market cannot be accessed by administrative accounts.`,
-		`PUBMARKETBUY_NOT_ENOUGH_MONEY`:    `You do not have enough money to buy that many %1$s!`,
-		`PUBMARKETBUY_CHEAPER_ARRIVED`:     `While you were browsing, less expensive %1$s arrived at the market!`,
-		`PUBMARKETBUY_BOUGHT_LINE`:         `%1$s %2$s for %3$s each`,
-		`PUBMARKETBUY_BOUGHT_SINGLE`:       `You purchased %1$s (total %2$s).`,
-		`PUBMARKETBUY_BOUGHT_MULTIPLE`:     `You purchased %1$s (total %2$s %3$s for %4$s).`,
-		`PUBMARKETBUY_BOUGHT_NOT_ALL`:      `Unfortunately, you were unable to purchase the remaining %1$s of your desired %2$s.`,
-		`PUBMARKETBUY_BOUGHT_NONE`:         `You were unable to purchase %1$s %2$s - none could be found on the market for %1$s.`,
+		`PUBMARKETBUY_NOT_ENOUGH_MONEY`:    `You do not have enough money to buy that many %[1]s!`,
+		`PUBMARKETBUY_CHEAPER_ARRIVED`:     `While you were browsing, less expensive %[1]s arrived at the market!`,
+		`PUBMARKETBUY_BOUGHT_LINE`:         `%[1]s %[2]s for %[3]s each`,
+		`PUBMARKETBUY_BOUGHT_SINGLE`:       `You purchased %[1]s (total %[2]s).`,
+		`PUBMARKETBUY_BOUGHT_MULTIPLE`:     `You purchased %[1]s (total %[2]s %[3]s for %[4]s).`,
+		`PUBMARKETBUY_BOUGHT_NOT_ALL`:      `Unfortunately, you were unable to purchase the remaining %[1]s of your desired %[2]s.`,
+		`PUBMARKETBUY_BOUGHT_NONE`:         `You were unable to purchase %[1]s %[2]s - none could be found on the market for %[1]s.`,
 		`PUBMARKETBUY_SUBMIT`:              `Purchase Goods`,
 
 		// pages/pubmarketsell
@@ -890,18 +917,18 @@ var (
 		`PUBMARKETSELL_UNAVAILABLE_END`:     `The public market cannot be accessed after the round has ended.`,
 		`PUBMARKETSELL_UNAVAILABLE_PROTECT`: `The public market cannot be accessed while still under protection.`,
 		`PUBMARKETSELL_UNAVAILABLE_ADMIN`:   `The public market cannot be accessed by administrative accounts.`,
-		`PUBMARKETSELL_TOO_MANY_UNITS`:      `You cannot sell more than %1$s %2$s!`,
-		`PUBMARKETSELL_TOO_FEW_UNITS`:       `You must sell at least %1$s %2$s in each shipment!`,
-		`PUBMARKETSELL_PRICE_TOO_LOW`:       `You cannot sell %1$s for less than %2$s each!`,
-		`PUBMARKETSELL_PROCE_TOO_HIGH`:      `You cannot sell %1$s for more than %2$s each!`,
+		`PUBMARKETSELL_TOO_MANY_UNITS`:      `You cannot sell more than %[1]s %[2]s!`,
+		`PUBMARKETSELL_TOO_FEW_UNITS`:       `You must sell at least %[1]s %[2]s in each shipment!`,
+		`PUBMARKETSELL_PRICE_TOO_LOW`:       `You cannot sell %[1]s for less than %[2]s each!`,
+		`PUBMARKETSELL_PROCE_TOO_HIGH`:      `You cannot sell %[1]s for more than %[2]s each!`,
 		`PUBMARKETSELL_CANNOT_REMOVE`:       `Unable to remove units from the market - either somebody is currently trying to buy them, or they are not yours to remove.`,
-		`PUBMARKETSELL_REMOVE_COMPLETE`:     `You have removed %1$s %2$s from the market. %3$s were returned to you.`,
+		`PUBMARKETSELL_REMOVE_COMPLETE`:     `You have removed %[1]s %[2]s from the market. %[3]s were returned to you.`,
 		`PUBMARKETSELL_REMOVE_TOO_SOON`:     `You cannot remove those items from the market yet.`,
 		`PUBMARKETSELL_SALES_HEADER`:        `On the market (or on the way), we have:`,
-		`PUBMARKETSELL_SALES_ONSALE`:        `On Sale, %1$s hour(s)`,
+		`PUBMARKETSELL_SALES_ONSALE`:        `On Sale, %[1]s hour(s)`,
 		`PUBMARKETSELL_SALES_REMOVE`:        `[Remove]`,
-		`PUBMARKETSELL_SALES_INTRANSIT`:     `In Transit, %1$s hour(s) remaining`,
-		`PUBMARKETSELL_SALES_DELAY`:         `It will take %1$s hour(s) for your goods to reach the market.`,
+		`PUBMARKETSELL_SALES_INTRANSIT`:     `In Transit, %[1]s hour(s) remaining`,
+		`PUBMARKETSELL_SALES_DELAY`:         `It will take %[1]s hour(s) for your goods to reach the market.`,
 		`PUBMARKETSELL_SUBMIT`:              `Sell Goods`,
 
 		// pages/pvtmarketbuy
@@ -911,18 +938,18 @@ var (
 		`PVTMARKETBUY_TITLE`:             `Private Market - Buy`,
 		`PVTMARKETBUY_UNAVAILABLE_START`: `The private market cannot be accessed before the round has begun.`,
 		`PVTMARKETBUY_UNAVAILABLE_END`:   `The private market cannot be accessed after the round has ended.`,
-		`PVTMARKETBUY_NOT_ENOUGH_UNITS`:  `There are not enough %1$s available on the market to buy!`,
-		`PVTMARKETBUY_NOT_ENOUGH_MONEY`:  `You do not have enough money to buy that many %1$s!`,
-		`PVTMARKETBUY_COMPLETE`:          `You purchased %1$s %2$s for a total of %3$s.`,
+		`PVTMARKETBUY_NOT_ENOUGH_UNITS`:  `There are not enough %[1]s available on the market to buy!`,
+		`PVTMARKETBUY_NOT_ENOUGH_MONEY`:  `You do not have enough money to buy that many %[1]s!`,
+		`PVTMARKETBUY_COMPLETE`:          `You purchased %[1]s %[2]s for a total of %[3]s.`,
 		`PVTMARKETBUY_SUBMIT`:            `Purchase Goods`,
 
 		// pages/pvtmarketsell
 		`PVTMARKETSELL_TITLE`:             `Private Market - Sell`,
 		`PVTMARKETSELL_UNAVAILABLE_START`: `The private market cannot be accessed before the round has begun.`,
 		`PVTMARKETSELL_UNAVAILABLE_END`:   `The private market cannot be accessed after the round has ended.`,
-		`PVTMARKETSELL_TOO_MANY_UNITS`:    `You cannot sell that many %1$s right now!`,
-		`PVTMARKETSELL_NOT_ENOUGH_UNITS`:  `You do not have that many %1$s!`,
-		`PVTMARKETSELL_COMPLETE`:          `You sold %1$s %2$s for a total of %3$s.`,
+		`PVTMARKETSELL_TOO_MANY_UNITS`:    `You cannot sell that many %[1]s right now!`,
+		`PVTMARKETSELL_NOT_ENOUGH_UNITS`:  `You do not have that many %[1]s!`,
+		`PVTMARKETSELL_COMPLETE`:          `You sold %[1]s %[2]s for a total of %[3]s.`,
 		`PVTMARKETSELL_SUBMIT`:            `Sell Goods`,
 
 		// pages/revalidate
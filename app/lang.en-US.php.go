@@ -5,6 +5,7 @@ package main
 import (
 	"fmt"
 	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/engine"
 	"html/template"
 	"log"
 	"strings"
@@ -58,6 +59,144 @@ func (lm *LanguageManager_t) PrintfHTML(msg string, args ...any) template.HTML {
 	return template.HTML(lm.Printf(msg, args...))
 }
 
+// Duration formats a number of seconds as "N days, N hours, N minutes, N seconds".
+func (lm *LanguageManager_t) Duration(seconds int) string {
+	prefix := ""
+	if seconds < 0 {
+		prefix, seconds = "-", -seconds
+	}
+	var dur []string
+	for _, unit := range []struct {
+		divisor          int
+		singular, plural string
+	}{
+		{60 * 60 * 24, "day", "days"},
+		{60 * 60, "hour", "hours"},
+		{60, "minute", "minutes"},
+		{1, "second", "seconds"},
+	} {
+		x := seconds / unit.divisor
+		seconds -= x * unit.divisor
+		if x == 1 {
+			dur = append(dur, fmt.Sprintf("%d %s", x, unit.singular))
+		} else if x != 0 || (unit.divisor == 1 && len(dur) == 0) {
+			dur = append(dur, fmt.Sprintf("%d %s", x, unit.plural))
+		}
+	}
+	return prefix + strings.Join(dur, ", ")
+}
+
+// Number formats an integer with thousands separators.
+func (lm *LanguageManager_t) Number(num int) string {
+	digits := fmt.Sprintf("%d", num)
+	sign := ""
+	if num < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	for i := len(digits) - 3; i > 0; i -= 3 {
+		digits = digits[:i] + "," + digits[i:]
+	}
+	return sign + digits
+}
+
+// Money formats an integer as an amount of money.
+func (lm *LanguageManager_t) Money(num int) string {
+	if num < 0 {
+		return "-$" + lm.Number(-num)
+	}
+	return "$" + lm.Number(num)
+}
+
+// Prenum formats an integer with a number sign, for ticket and empire numbers.
+func (lm *LanguageManager_t) Prenum(num int) string {
+	return "#" + lm.Number(num)
+}
+
+// Percent formats a number as a percentage with the given number of decimal places.
+func (lm *LanguageManager_t) Percent(num float64, decimals int) string {
+	return fmt.Sprintf("%.*f%%", decimals, num)
+}
+
+// Plural pluralizes a string, with the formatted number substituted into the string if requested.
+// The singular, plural, and zero forms may be literal strings or string IDs.
+// If zero is empty, the plural form is used for zero.
+func (lm *LanguageManager_t) Plural(num int, singular, plural, zero string) string {
+	msg := plural
+	if num == 1 {
+		msg = singular
+	} else if num == 0 && zero != "" {
+		msg = zero
+	}
+	if xlat, ok := lm.DefaultMap[msg]; ok {
+		msg = xlat
+	}
+	if !strings.Contains(msg, "%") {
+		return msg
+	}
+	return fmt.Sprintf(msg, lm.Number(num))
+}
+
+// EraTerm returns the era's name for a term, which is one of the
+// Era_t JSON field names ("name", "peasants", "food", "trparm", "bldpop", ...).
+// Terms that the era does not define are returned unchanged.
+func (lm *LanguageManager_t) EraTerm(era *engine.Era_t, term string) string {
+	var key string
+	switch term {
+	case "name":
+		key = era.Name
+	case "peasants":
+		key = era.Peasants
+	case "food":
+		key = era.Food
+	case "runes":
+		key = era.Runes
+	case "trparm":
+		key = era.TrpArm
+	case "trplnd":
+		key = era.TrpLnd
+	case "trpfly":
+		key = era.TrpFly
+	case "trpsea":
+		key = era.TrpSea
+	case "trpwiz":
+		key = era.TrpWiz
+	case "bldpop":
+		key = era.BldPop
+	case "bldcash":
+		key = era.BldCash
+	case "bldtrp":
+		key = era.BldTrp
+	case "bldcost":
+		key = era.BldCost
+	case "bldwiz":
+		key = era.BldWiz
+	case "bldfood":
+		key = era.BldFood
+	case "blddef":
+		key = era.BldDef
+	}
+	if key == "" {
+		return term
+	}
+	return lm.Printf(key)
+}
+
+// EraSpell returns the era's name for a spell, or the spell if the era does not name it.
+func (lm *LanguageManager_t) EraSpell(era *engine.Era_t, spell string) string {
+	if key, ok := era.Spells[spell]; ok {
+		return lm.Printf(key)
+	}
+	return spell
+}
+
+// EraEffect returns the era's name for an effect, or the effect if the era does not name it.
+func (lm *LanguageManager_t) EraEffect(era *engine.Era_t, effect string) string {
+	if key, ok := era.EffectNames[effect]; ok {
+		return lm.Printf(key)
+	}
+	return effect
+}
+
 var (
 	lang_en_US = map[string]string{
 		// Display name for language (within Preferences)
@@ -0,0 +1,217 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+// Package lottery implements the daily lottery from php/pages/lottery.php
+// and prom_turns::lottery.
+//
+// Empires buy tickets, up to a daily limit, at a price that grows with their
+// networth. Ticket sales are added to the jackpot. Every day a number is drawn;
+// if an empire holds the matching ticket, the jackpot is paid through a news
+// attachment and reset, otherwise it rolls over to the next drawing.
+package lottery
+
+import (
+	"fmt"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/orm"
+	"time"
+)
+
+// Lottery_t runs the lottery against the database.
+type Lottery_t struct {
+	db *orm.DB
+	e  *engine.Engine_t
+}
+
+// New returns a lottery.
+func New(db *orm.DB, e *engine.Engine_t) (*Lottery_t, error) {
+	if db == nil {
+		return nil, fmt.Errorf("missing database")
+	} else if e == nil {
+		return nil, fmt.Errorf("missing engine")
+	}
+	return &Lottery_t{db: db, e: e}, nil
+}
+
+// State_t describes the lottery from the point of view of an empire.
+type State_t struct {
+	Cash        int
+	TicketCost  int       // price of the empire's next ticket
+	MaxTickets  int       // tickets an empire may hold for each drawing
+	Tickets     []int     // tickets held by the empire
+	TicketsSold int       // tickets purchased for the next drawing
+	Jackpot     int       // current jackpot
+	NextDraw    time.Time // time of the next drawing
+	Closed      bool      // the final drawing of the round has been held
+	LastPicked  int       // number picked in the last drawing
+	LastWinner  int       // empire that won the last drawing, zero if no one won
+	LastName    string    // name of the empire that won the last drawing
+	LastJackpot int       // jackpot at the time of the last drawing
+	Increase    int       // increase in the jackpot from the last drawing
+}
+
+// State returns the state of the lottery for the empire.
+func (l *Lottery_t) State(empireId int) (*State_t, error) {
+	var state *State_t
+	err := l.db.Transaction(func(tx *orm.DB) error {
+		emp, err := tx.EmpireFetch(empireId)
+		if err != nil {
+			return err
+		}
+		state, err = l.state(tx, emp)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// Result_t is the result of buying a lottery ticket.
+type Result_t struct {
+	Ticket   int                // the ticket number, zero if no ticket was bought
+	Cost     int                // amount paid for the ticket
+	Messages []engine.Message_t // why the ticket was or was not bought
+	State    *State_t           // the state of the lottery after the purchase
+}
+
+// Buy buys a lottery ticket for the empire.
+func (l *Lottery_t) Buy(empireId int, round model.RoundData_t) (*Result_t, error) {
+	if round.Finished {
+		return nil, cerr.ErrRoundFinished
+	} else if !round.Started {
+		return nil, cerr.ErrRoundNotStarted
+	}
+	res := &Result_t{}
+	err := l.db.Transaction(func(tx *orm.DB) error {
+		emp, err := tx.EmpireFetch(empireId)
+		if err != nil {
+			return err
+		}
+		state, err := l.state(tx, emp)
+		if err != nil {
+			return err
+		}
+		if state.Closed {
+			res.Messages, res.State = append(res.Messages, engine.Message_t{Key: "LOTTERY_END_ROUND"}), state
+			return nil
+		} else if emp.Cash < state.TicketCost {
+			res.Messages, res.State = append(res.Messages, engine.Message_t{Key: "LOTTERY_NOT_ENOUGH_MONEY"}), state
+			return nil
+		} else if len(state.Tickets) >= state.MaxTickets {
+			res.Messages, res.State = append(res.Messages, engine.Message_t{Key: "LOTTERY_TOO_MANY_TICKETS"}), state
+			return nil
+		}
+
+		empires, err := tx.EmpireCount()
+		if err != nil {
+			return err
+		}
+		// really should improve this - ideally, pre-insert all valid tickets into the database
+		// and then randomly select one that hasn't yet been purchased
+		for {
+			res.Ticket = l.e.LotteryNumber(empires)
+			if holder, err := tx.LotteryTicketHolder(res.Ticket); err != nil {
+				return err
+			} else if holder == 0 {
+				break
+			}
+		}
+		res.Cost = state.TicketCost
+		if err := tx.LotteryTicketCreate(emp.Id, res.Ticket, res.Cost); err != nil {
+			return err
+		}
+		emp.Cash -= res.Cost
+		if err := tx.WorldVarsAdjust("lotto_current_jackpot", res.Cost); err != nil {
+			return err
+		}
+		emp.NetWorth = l.e.Networth(emp)
+		if err := tx.EmpireAttributesUpdate(emp); err != nil {
+			return err
+		}
+		res.Messages = append(res.Messages, engine.Message_t{Key: "LOTTERY_COMPLETE", Args: []any{res.Ticket, res.Cost}})
+
+		// display the price of the next ticket, which depends on the new networth
+		res.State, err = l.state(tx, emp)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// History returns the most recent drawings, newest first.
+func (l *Lottery_t) History(limit int) ([]*model.LotteryDraw_t, error) {
+	return l.db.LotteryDraws(limit)
+}
+
+// Draw holds the daily drawing for the turn interval, from prom_turns::lottery.
+// The winner, if any, is paid through a news attachment. The lottery variables
+// are updated in the world, which the caller must save. Every ticket is
+// discarded once the drawing is recorded.
+func Draw(tx *orm.DB, e *engine.Engine_t, world *model.World_t, interval, now time.Time) (*model.LotteryDraw_t, error) {
+	empires, err := tx.EmpireCount()
+	if err != nil {
+		return nil, err
+	}
+	draw := &model.LotteryDraw_t{Time: interval, Ticket: e.LotteryNumber(empires)}
+	if draw.Tickets, err = tx.LotteryTicketsSold(); err != nil {
+		return nil, err
+	} else if draw.EmpireId, err = tx.LotteryTicketHolder(draw.Ticket); err != nil {
+		return nil, err
+	}
+	draw.Won = e.LotteryRollover(world, draw.Ticket, draw.EmpireId)
+	draw.Jackpot, draw.Increase = world.LottoYesterdayJackpot, world.LottoJackpotIncrease
+	if draw.EmpireId != 0 {
+		winner, err := tx.EmpireFetch(draw.EmpireId)
+		if err != nil {
+			return nil, err
+		}
+		draw.EmpireName = winner.Name
+		if err := tx.EmpireNewsCreate(now, engine.NewNews(engine.EMPNEWS_ATTACH_LOTTERY, nil, winner, draw.Won)); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.LotteryDrawCreate(draw); err != nil {
+		return nil, err
+	} else if err := tx.LotteryClear(); err != nil {
+		return nil, err
+	}
+	return draw, nil
+}
+
+// state returns the state of the lottery for the empire.
+// The world is read from the database because the turn updates change it.
+func (l *Lottery_t) state(tx *orm.DB, emp *model.Empire_t) (*State_t, error) {
+	world, err := tx.WorldVarsFetch()
+	if err != nil {
+		return nil, err
+	}
+	state := &State_t{
+		Cash:        emp.Cash,
+		TicketCost:  l.e.LotteryTicketCost(emp),
+		MaxTickets:  l.e.LotteryMaxTickets(),
+		Jackpot:     world.LottoCurrentJackpot,
+		NextDraw:    world.TurnsNextDaily,
+		Closed:      world.TurnsNextDaily.After(world.RoundTimeEnd),
+		LastPicked:  world.LottoLastPicked,
+		LastWinner:  world.LottoLastWinner,
+		LastJackpot: world.LottoYesterdayJackpot,
+		Increase:    world.LottoJackpotIncrease,
+	}
+	if state.Tickets, err = tx.LotteryEmpireTickets(emp.Id); err != nil {
+		return nil, err
+	} else if state.TicketsSold, err = tx.LotteryTicketsSold(); err != nil {
+		return nil, err
+	}
+	if state.LastWinner != 0 {
+		winner, err := tx.EmpireFetch(state.LastWinner)
+		if err != nil {
+			return nil, err
+		}
+		state.LastName = winner.Name
+	}
+	return state, nil
+}
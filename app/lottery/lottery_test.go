@@ -0,0 +1,114 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package lottery
+
+import (
+	"errors"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/news"
+	"github.com/mdhender/promisance/app/orm"
+	"testing"
+	"time"
+)
+
+func TestLottery(t *testing.T) {
+	db, err := orm.CreateMemoryDatabase()
+	if err != nil {
+		t.Fatalf("database: %v", err)
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+	begin := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	if err := db.WorldVarsInitialize(&model.World_t{
+		LottoCurrentJackpot:   1000000,
+		LottoYesterdayJackpot: 1000000,
+		RoundTimeBegin:        begin,
+		RoundTimeClosing:      begin.Add(30 * 24 * time.Hour),
+		RoundTimeEnd:          begin.Add(35 * 24 * time.Hour),
+		TurnsNextDaily:        begin.Add(12 * time.Hour),
+	}); err != nil {
+		t.Fatalf("world: %v", err)
+	}
+	// with a single empire allowed a single ticket, the only number is #1
+	e := engine.New(engine.Config_t{LotteryMaxTickets: 1, LotteryJackpot: 1000000, PvtmTrpArm: 500}, nil)
+	l, err := New(db, e)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	user, err := db.UserCreate("gambler", "gambler@example.com")
+	if err != nil {
+		t.Fatalf("user: %v", err)
+	}
+	emp, err := db.EmpireCreate(user, "gambler", "HUMAN")
+	if err != nil {
+		t.Fatalf("empire: %v", err)
+	}
+	emp.Era, emp.Cash = engine.ERA_PAST, 500000
+	emp.NetWorth = e.Networth(emp)
+	if err := db.EmpireAttributesUpdate(emp); err != nil {
+		t.Fatalf("empire: %v", err)
+	}
+
+	if _, err := l.Buy(emp.Id, model.RoundData_t{Finished: true}); !errors.Is(err, cerr.ErrRoundFinished) {
+		t.Errorf("finished: want %v, got %v", cerr.ErrRoundFinished, err)
+	}
+	started := model.RoundData_t{Started: true}
+	res, err := l.Buy(emp.Id, started)
+	if err != nil {
+		t.Fatalf("buy: %v", err)
+	} else if res.Ticket != 1 || res.Cost != 122 || len(res.Messages) != 1 || res.Messages[0].Key != "LOTTERY_COMPLETE" {
+		t.Errorf("buy: want ticket 1 for 122, got %+v", res)
+	} else if res.State.Jackpot != 1000000 || res.State.TicketsSold != 1 || len(res.State.Tickets) != 1 {
+		t.Errorf("buy: state: got %+v", res.State)
+	}
+	if res, err := l.Buy(emp.Id, started); err != nil {
+		t.Fatalf("buy: %v", err)
+	} else if res.Ticket != 0 || len(res.Messages) != 1 || res.Messages[0].Key != "LOTTERY_TOO_MANY_TICKETS" {
+		t.Errorf("buy: limit: got %+v", res)
+	}
+
+	// ticket sales are added to the jackpot by the turn updates before the drawing,
+	// which pays the jackpot to the holder of ticket #1
+	var draw *model.LotteryDraw_t
+	err = db.Transaction(func(tx *orm.DB) error {
+		world, err := tx.WorldVarsFetch()
+		if err != nil {
+			return err
+		} else if _, err := tx.WorldVarsApplyAdjustments(world); err != nil {
+			return err
+		}
+		draw, err = Draw(tx, e, world, world.TurnsNextDaily, world.TurnsNextDaily)
+		if err != nil {
+			return err
+		}
+		return tx.WorldVarsUpdate(world)
+	})
+	if err != nil {
+		t.Fatalf("draw: %v", err)
+	} else if draw.Ticket != 1 || draw.Tickets != 1 || draw.EmpireId != emp.Id || draw.EmpireName != "gambler" || draw.Won != 1000122 || draw.Increase != 122 {
+		t.Errorf("draw: got %+v", draw)
+	}
+	state, err := l.State(emp.Id)
+	if err != nil {
+		t.Fatalf("state: %v", err)
+	} else if state.Jackpot != 1000000 || state.TicketsSold != 0 || len(state.Tickets) != 0 || state.LastWinner != emp.Id || state.LastName != "gambler" {
+		t.Errorf("state: got %+v", state)
+	}
+	if history, err := l.History(10); err != nil {
+		t.Fatalf("history: %v", err)
+	} else if len(history) != 1 || history[0].Won != 1000122 || !history[0].Time.Equal(begin.Add(12*time.Hour)) {
+		t.Errorf("history: got %+v", history)
+	}
+
+	// the winnings are delivered with the news
+	if _, err := news.Give(db, e, engine.DefaultTables(), emp.Id, begin.Add(12*time.Hour)); err != nil {
+		t.Fatalf("news: %v", err)
+	} else if got, err := db.EmpireFetch(emp.Id); err != nil {
+		t.Fatalf("fetch: %v", err)
+	} else if got.Cash != 500000-122+1000122 {
+		t.Errorf("cash: want %d, got %d", 500000-122+1000122, got.Cash)
+	}
+}
@@ -4,13 +4,28 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/mdhender/promisance/app/actions"
+	"github.com/mdhender/promisance/app/aid"
 	"github.com/mdhender/promisance/app/authn"
+	"github.com/mdhender/promisance/app/bank"
+	"github.com/mdhender/promisance/app/build"
+	"github.com/mdhender/promisance/app/clans"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/eras"
 	"github.com/mdhender/promisance/app/jot"
+	"github.com/mdhender/promisance/app/lottery"
+	"github.com/mdhender/promisance/app/market"
+	"github.com/mdhender/promisance/app/military"
 	"github.com/mdhender/promisance/app/model"
 	"github.com/mdhender/promisance/app/orm"
+	"github.com/mdhender/promisance/app/ranks"
+	"github.com/mdhender/promisance/app/sim"
+	"github.com/mdhender/promisance/app/turns"
 	"github.com/spf13/cobra"
+	"math/rand"
 	"net"
 	"net/http"
 	"net/mail"
@@ -36,12 +51,19 @@ type config struct{}
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute(cfg *config) error {
+	rootCmd.AddCommand(fixranksCmd)
+	fixranksCmd.Flags().StringVar(&fixranksArgs.data, "data", "", "path to data files")
+	if err := fixranksCmd.MarkFlagRequired("data"); err != nil {
+		log.Fatalf("fixranks: markFlagRequired: %v\n", err)
+	}
+
 	rootCmd.AddCommand(serverCmd)
 	serverCmd.Flags().StringVar(&serverArgs.data, "data", "", "path to data files")
 	serverCmd.Flags().StringVar(&serverArgs.host, "host", "localhost", "host to bind listener to")
 	serverCmd.Flags().StringVar(&serverArgs.port, "port", "8080", "port to bind listener to")
 	serverCmd.Flags().StringVar(&serverArgs.public, "public", "", "path to public files")
 	serverCmd.Flags().StringVar(&serverArgs.templates, "templates", "", "path to template files")
+	serverCmd.Flags().BoolVar(&serverArgs.turns, "turns", true, "run the turn scheduler")
 	if err := serverCmd.MarkFlagRequired("data"); err != nil {
 		log.Fatalf("setup: markFlagRequired: %v\n", err)
 	} else if err = serverCmd.MarkFlagRequired("public"); err != nil {
@@ -57,8 +79,30 @@ func Execute(cfg *config) error {
 		log.Fatalf("setup: markFlagRequired: %v\n", err)
 	}
 
+	rootCmd.AddCommand(simulateCmd)
+	simulateCmd.Flags().StringVar(&simulateArgs.data, "data", "", "path to data files with race and era overrides")
+	simulateCmd.Flags().IntVar(&simulateArgs.empires, "empires", 8, "number of empires to create")
+	simulateCmd.Flags().StringVar(&simulateArgs.format, "format", "csv", "output format (csv or json)")
+	simulateCmd.Flags().StringVar(&simulateArgs.output, "output", "", "output file (default stdout)")
+	simulateCmd.Flags().StringVar(&simulateArgs.races, "races", "human", "comma separated list of races assigned to empires in turn")
+	simulateCmd.Flags().Int64Var(&simulateArgs.seed, "seed", 1, "seed for the random number generator")
+	simulateCmd.Flags().IntVar(&simulateArgs.step, "step", 10, "number of turns between samples")
+	simulateCmd.Flags().StringVar(&simulateArgs.strategies, "strategies", strings.Join(sim.Strategies(), ","), "comma separated list of strategies assigned to empires in turn")
+	simulateCmd.Flags().IntVar(&simulateArgs.turns, "turns", 500, "number of turns each empire plays")
+	simulateCmd.Flags().IntVar(&simulateArgs.buildCost, "build-cost", BUILD_COST, "base building cost")
+	simulateCmd.Flags().Float64Var(&simulateArgs.industryMult, "industry-mult", INDUSTRY_MULT, "industry output multiplier")
+	simulateCmd.Flags().Float64Var(&simulateArgs.bankSaveRate, "bank-saverate", BANK_SAVERATE, "base savings interest rate")
+	simulateCmd.Flags().Float64Var(&simulateArgs.bankLoanRate, "bank-loanrate", BANK_LOANRATE, "base loan interest rate")
+
 	rootCmd.AddCommand(timeZoneCmd)
 
+	rootCmd.AddCommand(turnsCmd)
+	turnsCmd.Flags().StringVar(&turnsArgs.data, "data", "", "path to data files")
+	turnsCmd.Flags().BoolVar(&turnsArgs.dryRun, "dry-run", false, "show what the update would do without committing it")
+	if err := turnsCmd.MarkFlagRequired("data"); err != nil {
+		log.Fatalf("turns: markFlagRequired: %v\n", err)
+	}
+
 	return rootCmd.Execute()
 }
 
@@ -72,12 +116,73 @@ var rootCmd = &cobra.Command{
 	Run:   func(cmd *cobra.Command, args []string) {},
 }
 
+var fixranksArgs struct {
+	data string
+}
+
+// fixranksCmd implements a command to repair the empire and user rankings.
+// It is intended to be run while the server is offline.
+var fixranksCmd = &cobra.Command{
+	Use:   "fixranks",
+	Short: "repair rankings",
+	Long:  `Recompute the networth and rank of every empire, then repair the user rankings from the round history.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		startedAt := time.Now()
+
+		// verify data path
+		if fixranksArgs.data = strings.TrimSpace(fixranksArgs.data); fixranksArgs.data == "" {
+			log.Fatal("error: no data path specified\n")
+		} else if path, err := filepath.Abs(fixranksArgs.data); err != nil {
+			log.Fatalf("error: data: %v\n", err)
+		} else if sb, err := os.Stat(path); err != nil {
+			log.Fatalf("error: data: %s: no such directory\n", fixranksArgs.data)
+		} else if !sb.IsDir() {
+			log.Fatalf("error: data: %s: not a directory\n", fixranksArgs.data)
+		} else {
+			fixranksArgs.data = path
+		}
+
+		dbFile := filepath.Join(fixranksArgs.data, "promisance.sqlite")
+		log.Printf("fixranks: connecting to database: %s\n", dbFile)
+		db, err := orm.OpenSqliteDatabase(dbFile)
+		if err != nil {
+			log.Fatalf("fixranks: database: %v\n", err)
+		}
+		defer func() {
+			_ = db.Close()
+		}()
+
+		if world, err := db.WorldVarsFetch(); err != nil {
+			log.Fatalf("fixranks: failed to fetch vars: %v\n", err)
+		} else if world == nil {
+			log.Fatalf("fixranks: Game setup is not yet complete\n")
+		}
+
+		var flags int
+		if SCORE_ENABLE {
+			flags |= orm.HRFLAG_SCORE
+		}
+		result, err := ranks.Update(db, engine.New(engineConfig(), nil), flags)
+		if err != nil {
+			log.Fatalf("fixranks: empires: %v\n", err)
+		}
+		log.Printf("fixranks: corrected networth for %d empires, ranked %d empires\n", result.Networth, result.Ranked)
+
+		fixed, err := ranks.FixUsers(db)
+		if err != nil {
+			log.Fatalf("fixranks: users: %v\n", err)
+		}
+		log.Printf("fixranks: done fixing user rankings (%d records modified) in %v\n", fixed, time.Now().Sub(startedAt))
+	},
+}
+
 var serverArgs struct {
 	data      string
 	host      string
 	port      string
 	templates string // path to template files
 	public    string // path to public files
+	turns     bool   // run the turn scheduler
 }
 
 var serverCmd = &cobra.Command{
@@ -136,6 +241,13 @@ var serverCmd = &cobra.Command{
 		}
 		log.Printf("app: server time zone is %s (logs are UTC)\n", s.tz)
 
+		// race and era tables may be overridden by files in the data directory
+		s.tables, err = engine.LoadTables(serverArgs.data)
+		if err != nil {
+			log.Fatalf("server: tables: %v\n", err)
+		}
+		log.Printf("server: loaded %d races and %d eras\n", len(s.tables.Races), len(s.tables.Eras))
+
 		dbFile := filepath.Join(serverArgs.data, "promisance.sqlite")
 		log.Printf("server: connecting to database: %s\n", dbFile)
 		s.db, err = orm.OpenSqliteDatabase(dbFile)
@@ -151,6 +263,65 @@ var serverCmd = &cobra.Command{
 
 		s.sessions = NewSessionStore(s.db, 7*24*time.Hour, "en-US")
 
+		// the game services share a single engine
+		e := engine.New(engineConfig(), rand.New(rand.NewSource(time.Now().UnixNano())))
+		s.e = e
+		s.actions, err = actions.New(s.db, e, s.tables, actions.Config_t{ClanEnable: CLAN_ENABLE})
+		if err != nil {
+			log.Fatalf("server: actions: %v\n", err)
+		}
+		s.aid, err = aid.New(s.db, e, s.tables, aid.Config_t{ClanEnable: CLAN_ENABLE, ClanViewAid: CLAN_VIEW_AID})
+		if err != nil {
+			log.Fatalf("server: aid: %v\n", err)
+		}
+		s.bank, err = bank.New(s.db, e)
+		if err != nil {
+			log.Fatalf("server: bank: %v\n", err)
+		}
+		s.build, err = build.New(s.db, e, s.tables)
+		if err != nil {
+			log.Fatalf("server: build: %v\n", err)
+		}
+		s.clans, err = clans.New(s.db, e, s.tables, clans.Config_t{
+			ClanEnable:   CLAN_ENABLE,
+			MinJoin:      CLAN_MINJOIN,
+			MinRejoin:    CLAN_MINREJOIN,
+			MaxAlly:      CLAN_MAXALLY,
+			MaxWar:       CLAN_MAXWAR,
+			MinRelate:    CLAN_MINRELATE,
+			MinShare:     CLAN_MINSHARE,
+			TurnsFreq:    TURNS_FREQ,
+			DefaultTitle: s.language.DefaultMap["CLAN_CREATE_DEFAULT_TITLE"],
+			DefaultMotd:  s.language.DefaultMap["CLAN_CREATE_DEFAULT_MOTD"],
+			Reserved: func(name string) bool {
+				// the name shown for empires without a clan may not be used
+				return strings.EqualFold(name, s.language.Printf("CLAN_NONE"))
+			},
+			IsLangKey: func(text string) bool {
+				_, ok := s.language.DefaultMap[text]
+				return ok
+			},
+		})
+		if err != nil {
+			log.Fatalf("server: clans: %v\n", err)
+		}
+		s.eras, err = eras.New(s.db, e, s.tables, eras.Config_t{ClanEnable: CLAN_ENABLE})
+		if err != nil {
+			log.Fatalf("server: eras: %v\n", err)
+		}
+		s.lottery, err = lottery.New(s.db, e)
+		if err != nil {
+			log.Fatalf("server: lottery: %v\n", err)
+		}
+		s.market, err = market.New(s.db, e, s.tables, marketConfig())
+		if err != nil {
+			log.Fatalf("server: market: %v\n", err)
+		}
+		s.military, err = military.New(s.db, e, s.tables)
+		if err != nil {
+			log.Fatalf("server: military: %v\n", err)
+		}
+
 		handler := s.routes()
 
 		s.authenticator, err = authn.New(s.db)
@@ -158,22 +329,23 @@ var serverCmd = &cobra.Command{
 			log.Fatalf("error: authn.New: %v\n", err)
 		}
 
-		// world data is a one time load that is shared with all the handlers
-		s.world, err = s.db.WorldVarsFetch()
-		if err != nil {
-			log.Fatalf("server: failed to fetch vars: %v\n", err)
-		} else if s.world == nil {
-			log.Fatalf("server: database: world_vars undefined\n")
-		} else if s.world.Id != 1 {
+		// world data is shared with all the handlers, which reload it as the turn updates change it
+		if world := s.worldVars(); world == nil {
+			log.Fatalf("server: failed to fetch vars\n")
+		} else if world.Id != 1 {
 			log.Fatalf("server: database: world_vars corrupted\n")
 		}
 		log.Printf("server: fetched world variables\n")
 
-		// If we're configured for cronless turn updates, check them now
-		//turns, err := s.db.P prom_turns();
-		//if !TURNS_CRONTAB {
-		//	turns.doUpdate()
-		//}
+		// start the turn scheduler, which catches up on any updates missed while we were down
+		if serverArgs.turns {
+			t, err := turns.New(s.db, turnsConfig(), turns.SystemClock{})
+			if err != nil {
+				log.Fatalf("server: turns: %v\n", err)
+			}
+			go t.Run(context.Background(), time.Minute, nil)
+			log.Printf("server: started turn scheduler\n")
+		}
 
 		log.Printf("server: serving on http://%s/index.php?location=main\n", s.addr)
 		log.Fatalln(http.ListenAndServe(s.addr, handler))
@@ -380,9 +552,9 @@ var setupCmd = &cobra.Command{
 			RoundTimeBegin:        roundBegin,
 			RoundTimeClosing:      roundClosing,
 			RoundTimeEnd:          roundEnd,
-			TurnsNext:             roundBegin.Add(TURNS_OFFSET * 60 * time.Second),
-			TurnsNextHourly:       roundBegin.Add(TURNS_OFFSET_HOURLY * 60 * time.Hour),
-			TurnsNextDaily:        roundBegin.Add(TURNS_OFFSET_DAILY * 24 * 60 * time.Hour),
+			TurnsNext:             roundBegin.Add(TURNS_OFFSET * time.Minute),
+			TurnsNextHourly:       roundBegin.Add(TURNS_OFFSET_HOURLY * time.Minute),
+			TurnsNextDaily:        roundBegin.Add(TURNS_OFFSET_DAILY * time.Minute),
 		}
 		if err := db.WorldVarsInitialize(world); err != nil {
 			log.Fatalf("setup: failed to initialize world variables: %v\n", err)
@@ -420,6 +592,109 @@ var setupCmd = &cobra.Command{
 	},
 }
 
+var simulateArgs struct {
+	data         string
+	empires      int
+	format       string
+	output       string
+	races        string
+	seed         int64
+	step         int
+	strategies   string
+	turns        int
+	buildCost    int
+	industryMult float64
+	bankSaveRate float64
+	bankLoanRate float64
+}
+
+// simulateCmd implements a command to play scripted strategies for synthetic empires.
+// It is intended for evaluating changes to the game settings before shipping a round.
+var simulateCmd = &cobra.Command{
+	Use:   "simulate",
+	Short: "simulate empires for balance testing",
+	Long:  `Create synthetic empires in an in-memory database, play scripted strategies for them, and report networth, land, and army over time.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		startedAt := time.Now()
+
+		if simulateArgs.format != "csv" && simulateArgs.format != "json" {
+			log.Fatalf("simulate: format: must be csv or json\n")
+		}
+
+		// race and era tables may be overridden by files in the data directory
+		tables := engine.DefaultTables()
+		if simulateArgs.data = strings.TrimSpace(simulateArgs.data); simulateArgs.data != "" {
+			var err error
+			if tables, err = engine.LoadTables(simulateArgs.data); err != nil {
+				log.Fatalf("simulate: tables: %v\n", err)
+			}
+		}
+
+		cfg := sim.Config_t{
+			Engine:   engineConfig(),
+			Tables:   tables,
+			Empires:  simulateArgs.empires,
+			Turns:    simulateArgs.turns,
+			Step:     simulateArgs.step,
+			Interval: time.Duration(simulateArgs.step*TURNS_FREQ/TURNS_COUNT) * time.Minute,
+			Seed:     simulateArgs.seed,
+			Defaults: empireDefaults(),
+		}
+		cfg.Engine.BuildCost = simulateArgs.buildCost
+		cfg.Engine.IndustryMult = simulateArgs.industryMult
+		cfg.Engine.BankSaveRate = simulateArgs.bankSaveRate
+		cfg.Engine.BankLoanRate = simulateArgs.bankLoanRate
+		for _, race := range strings.Split(simulateArgs.races, ",") {
+			if race = strings.ToUpper(strings.TrimSpace(race)); race != "" {
+				cfg.Races = append(cfg.Races, race)
+			}
+		}
+		for _, strategy := range strings.Split(simulateArgs.strategies, ",") {
+			if strategy = strings.ToLower(strings.TrimSpace(strategy)); strategy != "" {
+				cfg.Strategies = append(cfg.Strategies, strategy)
+			}
+		}
+
+		db, err := orm.CreateMemoryDatabase()
+		if err != nil {
+			log.Fatalf("simulate: database: %v\n", err)
+		}
+		defer func() {
+			_ = db.Close()
+		}()
+
+		s, err := sim.New(db, cfg)
+		if err != nil {
+			log.Fatalf("simulate: %v\n", err)
+		}
+		samples, err := s.Run()
+		if err != nil {
+			log.Fatalf("simulate: %v\n", err)
+		}
+
+		w := os.Stdout
+		if simulateArgs.output != "" {
+			if w, err = os.Create(simulateArgs.output); err != nil {
+				log.Fatalf("simulate: output: %v\n", err)
+			}
+		}
+		if simulateArgs.format == "json" {
+			err = sim.WriteJSON(w, samples)
+		} else {
+			err = sim.WriteCSV(w, samples)
+		}
+		if err != nil {
+			log.Fatalf("simulate: output: %v\n", err)
+		}
+		if w != os.Stdout {
+			if err := w.Close(); err != nil {
+				log.Fatalf("simulate: output: %v\n", err)
+			}
+		}
+		log.Printf("simulate: %d empires played %d turns in %v\n", cfg.Empires, cfg.Turns, time.Now().Sub(startedAt))
+	},
+}
+
 // timeZoneCmd implements a command to show the current time zone data
 var timeZoneCmd = &cobra.Command{
 	Use:   "tz",
@@ -449,3 +724,183 @@ var timeZoneCmd = &cobra.Command{
 		}
 	},
 }
+
+var turnsArgs struct {
+	data   string
+	dryRun bool
+}
+
+// turnsCmd implements a command to run any turn updates that are due.
+// It is intended to be run from an external timer when the server's scheduler is disabled.
+var turnsCmd = &cobra.Command{
+	Use:   "turns",
+	Short: "run turn updates",
+	Long:  `Run any normal, hourly, and daily turn updates that are due, then exit.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		startedAt := time.Now()
+
+		// verify data path
+		if turnsArgs.data = strings.TrimSpace(turnsArgs.data); turnsArgs.data == "" {
+			log.Fatal("error: no data path specified\n")
+		} else if path, err := filepath.Abs(turnsArgs.data); err != nil {
+			log.Fatalf("error: data: %v\n", err)
+		} else if sb, err := os.Stat(path); err != nil {
+			log.Fatalf("error: data: %s: no such directory\n", turnsArgs.data)
+		} else if !sb.IsDir() {
+			log.Fatalf("error: data: %s: not a directory\n", turnsArgs.data)
+		} else {
+			turnsArgs.data = path
+		}
+
+		dbFile := filepath.Join(turnsArgs.data, "promisance.sqlite")
+		log.Printf("turns: connecting to database: %s\n", dbFile)
+		db, err := orm.OpenSqliteDatabase(dbFile)
+		if err != nil {
+			log.Fatalf("turns: database: %v\n", err)
+		}
+		defer func() {
+			_ = db.Close()
+		}()
+
+		t, err := turns.New(db, turnsConfig(), turns.SystemClock{})
+		if err != nil {
+			log.Fatalf("turns: %v\n", err)
+		}
+		t.SetOutput(func(e turns.Entry_t) {
+			if e.Type != turns.TURN_END {
+				fmt.Println(e.String())
+			}
+		})
+
+		if turnsArgs.dryRun {
+			runs, err := t.DryRun()
+			if err != nil {
+				log.Fatalf("turns: dry run: %v\n", err)
+			}
+			log.Printf("turns: dry run: %d intervals would be processed, no changes committed\n", runs)
+			return
+		}
+
+		runs, err := t.DoUpdate()
+		if err != nil {
+			log.Fatalf("turns: update: %v\n", err)
+		}
+		log.Printf("turns: processed %d intervals in %v\n", runs, time.Now().Sub(startedAt))
+	},
+}
+
+// turnsConfig returns the turn settings from config.php.
+func turnsConfig() turns.Config_t {
+	return turns.Config_t{
+		Freq:          TURNS_FREQ,
+		Offset:        TURNS_OFFSET,
+		OffsetHourly:  TURNS_OFFSET_HOURLY,
+		OffsetDaily:   TURNS_OFFSET_DAILY,
+		Count:         TURNS_COUNT,
+		Unstore:       TURNS_UNSTORE,
+		MaxTurns:      TURNS_MAXIMUM,
+		MaxStored:     TURNS_STORED,
+		MaxAttacks:    MAX_ATTACKS,
+		ScoreEnable:   SCORE_ENABLE,
+		ClanEnable:    CLAN_ENABLE,
+		InviteTime:    CLAN_INVITE_TIME,
+		VacationStart: VACATION_START,
+		VacationLimit: VACATION_LIMIT,
+		CronLog:       TURNS_CRONLOG,
+		PubmktMaxTime: PUBMKT_MAXTIME,
+		IdleNew:       IDLE_TIMEOUT_NEW,
+		IdleValidate:  IDLE_TIMEOUT_VALIDATE,
+		IdleAbandon:   IDLE_TIMEOUT_ABANDON,
+		IdleKilled:    IDLE_TIMEOUT_KILLED,
+		IdleDelete:    IDLE_TIMEOUT_DELETE,
+		Seed:          time.Now().UnixNano(),
+		Engine:        engineConfig(),
+	}
+}
+
+// marketConfig returns the public market settings from config.php.
+func marketConfig() market.Config_t {
+	return market.Config_t{
+		Start:   PUBMKT_START,
+		MinTime: PUBMKT_MINTIME,
+		MaxTime: PUBMKT_MAXTIME,
+	}
+}
+
+func engineConfig() engine.Config_t {
+	return engine.Config_t{
+		BankSaveRate:      BANK_SAVERATE,
+		BankLoanRate:      BANK_LOANRATE,
+		IndustryMult:      INDUSTRY_MULT,
+		BuildCost:         BUILD_COST,
+		TurnsProtection:   TURNS_PROTECTION,
+		ClanEnable:        CLAN_ENABLE,
+		MaxAttacks:        MAX_ATTACKS,
+		LotteryMaxTickets: LOTTERY_MAXTICKETS,
+		LotteryJackpot:    LOTTERY_JACKPOT,
+		PvtmTrpArm:        PVTM_TRPARM,
+		PvtmTrpLnd:        PVTM_TRPLND,
+		PvtmTrpFly:        PVTM_TRPFLY,
+		PvtmTrpSea:        PVTM_TRPSEA,
+		PvtmFood:          PVTM_FOOD,
+		PvtmMaxSell:       PVTM_MAXSELL,
+		PvtmShopBonus:     PVTM_SHOPBONUS,
+		PubmktMinSell:     PUBMKT_MINSELL,
+		PubmktMaxSell:     PUBMKT_MAXSELL,
+		PubmktMinFood:     PUBMKT_MINFOOD,
+		PubmktMaxFood:     PUBMKT_MAXFOOD,
+		TurnsEra:          TURNS_ERA,
+		DropDelay:         DROP_DELAY,
+		FriendMagicEnable: FRIEND_MAGIC_ENABLE,
+		MagicAllowRegress: MAGIC_ALLOW_REGRESS,
+		ScoreEnable:       SCORE_ENABLE,
+		AidEnable:         AID_ENABLE,
+		AidMaxCredits:     AID_MAXCREDITS,
+		AidDelay:          AID_DELAY,
+		VacationStart:     VACATION_START,
+	}
+}
+
+// empireDefaults returns the default values for newly created empires from config.php.
+func empireDefaults() model.Empire_t {
+	return model.Empire_t{
+		// Resources
+		Cash:  100000,
+		Food:  10000,
+		Runes: 0,
+
+		// Units
+		Peasants: 500,
+		TrpArm:   100,
+		TrpLnd:   50,
+		TrpFly:   20,
+		TrpSea:   10,
+		TrpWiz:   0,
+
+		// Buildings
+		Land:     250, // acre counts below MUST add up to this value!
+		BldPop:   20,
+		BldCash:  10,
+		BldTrp:   0,
+		BldCost:  5,
+		BldWiz:   0,
+		BldFood:  15,
+		BldDef:   0,
+		Freeland: 200,
+
+		// Private Market supplies
+		MktArm:  4000,
+		MktLnd:  3000,
+		MktFly:  2000,
+		MktSea:  1000,
+		MktFood: 100000,
+
+		// Others
+		IndArm: 25,
+		IndLnd: 25,
+		IndFly: 25,
+		IndSea: 25,
+		Health: 100,
+		Tax:    10,
+	}
+}
@@ -0,0 +1,425 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+// Package market implements the public market from php/pages/pubmarketbuy.php,
+// php/pages/pubmarketsell.php, and prom_turns::cleanMarket.
+//
+// Sellers ship goods to the market, where they arrive after a delay.
+// Buyers are sold the cheapest goods first, and the oldest goods at any one price.
+// Sellers are paid (less a tax that funds the lottery) through news attachments,
+// and goods that go unsold for too long are returned the same way.
+package market
+
+import (
+	"fmt"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/orm"
+	"math"
+	"time"
+)
+
+// Config_t holds the settings from config.php that control the public market.
+type Config_t struct {
+	Start   int // Hours before goods will arrive on public market
+	MinTime int // Number of hours before users can manually remove items (-1 to disallow)
+	MaxTime int // Number of hours before items are automatically removed (-1 to disallow)
+}
+
+// Market_t runs the public market against the database.
+type Market_t struct {
+	db     *orm.DB
+	e      *engine.Engine_t
+	tables *engine.Tables_t
+	cfg    Config_t
+}
+
+// New returns a public market.
+func New(db *orm.DB, e *engine.Engine_t, tables *engine.Tables_t, cfg Config_t) (*Market_t, error) {
+	if db == nil {
+		return nil, fmt.Errorf("missing database")
+	} else if e == nil {
+		return nil, fmt.Errorf("missing engine")
+	} else if tables == nil {
+		return nil, fmt.Errorf("missing tables")
+	}
+	return &Market_t{db: db, e: e, tables: tables, cfg: cfg}, nil
+}
+
+// Order_t is a request to buy or sell goods.
+type Order_t struct {
+	Good   engine.Good_t
+	Amount int
+	Price  int // the price the buyer was shown, or the seller's asking price
+}
+
+// Quote_t describes the market for one type of goods from the point of view of an empire.
+type Quote_t struct {
+	Good      engine.Good_t
+	BaseCost  int // base market cost of one unit
+	Owned     int // goods held by the empire
+	Price     int // lowest price of goods on the market, zero if there are none
+	Available int // goods on the market at the lowest price
+	CanBuy    int // goods the empire can afford at the lowest price
+	OnSale    int // goods the empire has on the market or on the way to it
+	MinSell   int // fewest goods the empire can sell in one shipment
+	MaxSell   int // most goods the empire can add to the market
+}
+
+// Quotes returns the current state of the market for each type of goods.
+// The empire's own shipments are not included in the prices or availability.
+func (m *Market_t) Quotes(empireId int, now time.Time) ([]Quote_t, error) {
+	emp, err := m.db.EmpireFetch(empireId)
+	if err != nil {
+		return nil, err
+	}
+	prices, err := m.db.MarketPrices(emp.Id, now)
+	if err != nil {
+		return nil, err
+	}
+	onSale, err := m.onSale(m.db, emp.Id)
+	if err != nil {
+		return nil, err
+	}
+	var quotes []Quote_t
+	for _, g := range engine.Goods() {
+		price := prices[int(g)]
+		q := Quote_t{
+			Good:      g,
+			BaseCost:  m.e.MarketCost(g),
+			Owned:     g.Owned(emp),
+			Price:     price.Price,
+			Available: price.Amount,
+			CanBuy:    min(emp.Cash/max(price.Price, 1), price.Amount),
+			OnSale:    onSale[g],
+		}
+		q.MinSell, q.MaxSell = m.e.MarketSellLimits(emp, g, q.OnSale)
+		quotes = append(quotes, q)
+	}
+	return quotes, nil
+}
+
+// Shipments returns the goods the empire has on the market or on the way to it.
+func (m *Market_t) Shipments(empireId int) ([]*model.MarketItem_t, error) {
+	return m.db.MarketEmpireItems(empireId)
+}
+
+// Purchase_t is the goods bought at a single price.
+type Purchase_t struct {
+	Good   engine.Good_t
+	Amount int
+	Price  int
+}
+
+// BuyResult_t is the result of buying goods.
+type BuyResult_t struct {
+	Purchases []Purchase_t
+	Spent     int
+	Messages  []engine.Message_t
+}
+
+// Buy purchases goods for the empire. Goods are bought from the lowest price
+// up to the price in each order, oldest shipments first. The empire must be
+// able to afford the whole order at that price.
+func (m *Market_t) Buy(empireId int, round model.RoundData_t, orders []Order_t, now time.Time) (*BuyResult_t, error) {
+	res := &BuyResult_t{}
+	message := func(key string, args ...any) {
+		res.Messages = append(res.Messages, engine.Message_t{Key: key, Args: args})
+	}
+	err := m.db.Transaction(func(tx *orm.DB) error {
+		emp, era, err := m.load(tx, empireId, round)
+		if err != nil {
+			return err
+		}
+		var jackpot int
+		for _, order := range orders {
+			g, amount, price := order.Good, order.Amount, order.Price
+			if !g.IsValid() {
+				return cerr.ErrUnknownGood
+			} else if amount <= 0 {
+				continue
+			}
+			// require that they have enough money to buy what they wanted at the price they saw.
+			// if prices go down, well, lucky them - they won't have to pay as much.
+			if amount*price > emp.Cash {
+				message("PUBMARKETBUY_NOT_ENOUGH_MONEY", g.Name(era))
+				continue
+			}
+
+			items, err := tx.MarketItemsForSale(int(g), price, emp.Id, now)
+			if err != nil {
+				return err
+			}
+			var bought []Purchase_t // how many units were bought at each price
+			var total, totalSpent int
+			for _, item := range items {
+				buyAmt := min(item.Amount, amount)
+				if n := len(bought); n == 0 || bought[n-1].Price != item.Price {
+					bought = append(bought, Purchase_t{Good: g, Price: item.Price})
+				}
+				bought[len(bought)-1].Amount += buyAmt
+				total += buyAmt
+
+				spent := buyAmt * item.Price
+				taxed := int(math.Round(float64(spent) * engine.PUBMKT_TAX))
+				totalSpent += spent
+
+				g.Give(emp, buyAmt)
+				emp.Cash -= spent
+				if item.Amount == buyAmt {
+					err = tx.MarketItemDelete(item.Id)
+				} else {
+					err = tx.MarketItemUpdateAmount(item.Id, item.Amount-buyAmt)
+				}
+				if err != nil {
+					return err
+				}
+
+				// the seller is paid the next time they log in
+				seller, err := tx.EmpireFetch(item.EmpireId)
+				if err != nil {
+					return err
+				}
+				news := engine.NewNews(engine.EMPNEWS_ATTACH_MARKET_SELL, emp, seller, int(g), buyAmt, spent, spent-taxed)
+				if err := tx.EmpireNewsCreate(now, news); err != nil {
+					return err
+				}
+
+				// Public Market taxes go into the lottery
+				jackpot += taxed
+
+				amount -= buyAmt
+				if amount == 0 {
+					break
+				}
+			}
+			if total == 0 {
+				message("PUBMARKETBUY_BOUGHT_NONE", amount, g.Name(era), price)
+				continue
+			}
+			if bought[0].Price < price {
+				message("PUBMARKETBUY_CHEAPER_ARRIVED", g.Name(era))
+			}
+			var lines []engine.Message_t
+			for _, b := range bought {
+				lines = append(lines, engine.Message_t{Key: "PUBMARKETBUY_BOUGHT_LINE", Args: []any{b.Amount, g.Name(era), b.Price}})
+			}
+			if len(bought) == 1 {
+				message("PUBMARKETBUY_BOUGHT_SINGLE", lines, totalSpent)
+			} else {
+				message("PUBMARKETBUY_BOUGHT_MULTIPLE", lines, total, g.Name(era), totalSpent)
+			}
+			if amount > 0 {
+				message("PUBMARKETBUY_BOUGHT_NOT_ALL", amount, g.Name(era))
+			}
+			res.Purchases = append(res.Purchases, bought...)
+			res.Spent += totalSpent
+		}
+		if jackpot != 0 {
+			if err := tx.WorldVarsAdjust("lotto_current_jackpot", jackpot); err != nil {
+				return err
+			}
+		}
+		return m.save(tx, emp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// SellResult_t is the result of selling goods.
+type SellResult_t struct {
+	Shipments []*model.MarketItem_t // goods sent to the market
+	Messages  []engine.Message_t
+}
+
+// Sell sends goods from the empire to the market, where they arrive after
+// the configured delay. Orders outside the empire's limits are rejected
+// with a message and the remaining orders are still processed.
+func (m *Market_t) Sell(empireId int, round model.RoundData_t, orders []Order_t, now time.Time) (*SellResult_t, error) {
+	res := &SellResult_t{}
+	message := func(key string, args ...any) {
+		res.Messages = append(res.Messages, engine.Message_t{Key: key, Args: args})
+	}
+	err := m.db.Transaction(func(tx *orm.DB) error {
+		emp, era, err := m.load(tx, empireId, round)
+		if err != nil {
+			return err
+		}
+		onSale, err := m.onSale(tx, emp.Id)
+		if err != nil {
+			return err
+		}
+		// limits are set by the goods on hand before any are sold
+		minSell, maxSell := map[engine.Good_t]int{}, map[engine.Good_t]int{}
+		for _, g := range engine.Goods() {
+			minSell[g], maxSell[g] = m.e.MarketSellLimits(emp, g, onSale[g])
+		}
+		for _, order := range orders {
+			g, amount, price := order.Good, order.Amount, order.Price
+			if !g.IsValid() {
+				return cerr.ErrUnknownGood
+			} else if amount <= 0 {
+				continue
+			}
+			minPrice, maxPrice := m.e.MarketPriceLimits(g)
+			if amount > maxSell[g] {
+				message("PUBMARKETSELL_TOO_MANY_UNITS", maxSell[g], g.Name(era))
+				continue
+			} else if amount < minSell[g] {
+				message("PUBMARKETSELL_TOO_FEW_UNITS", minSell[g], g.Name(era))
+				continue
+			} else if price < minPrice {
+				message("PUBMARKETSELL_PRICE_TOO_LOW", g.Name(era), minPrice)
+				continue
+			} else if price > maxPrice {
+				message("PUBMARKETSELL_PROCE_TOO_HIGH", g.Name(era), maxPrice)
+				continue
+			}
+			g.Give(emp, -amount)
+			maxSell[g] -= amount
+
+			item := &model.MarketItem_t{
+				Type:     int(g),
+				EmpireId: emp.Id,
+				Amount:   amount,
+				Price:    price,
+				Time:     now.Add(time.Duration(m.cfg.Start) * time.Hour),
+			}
+			if err := tx.MarketItemCreate(item); err != nil {
+				return err
+			}
+			res.Shipments = append(res.Shipments, item)
+		}
+		return m.save(tx, emp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// RemoveResult_t is the result of removing goods from the market.
+type RemoveResult_t struct {
+	Returned int // goods returned to the empire
+	Messages []engine.Message_t
+}
+
+// Remove takes a shipment off the market and returns it to the empire.
+// Some of the goods are lost on the way back.
+func (m *Market_t) Remove(empireId int, round model.RoundData_t, itemId int, now time.Time) (*RemoveResult_t, error) {
+	if m.cfg.MinTime < 0 {
+		// not allowed to manually remove units
+		return nil, cerr.ErrRemoveNotAllowed
+	}
+	res := &RemoveResult_t{}
+	message := func(key string, args ...any) {
+		res.Messages = append(res.Messages, engine.Message_t{Key: key, Args: args})
+	}
+	err := m.db.Transaction(func(tx *orm.DB) error {
+		emp, era, err := m.load(tx, empireId, round)
+		if err != nil {
+			return err
+		}
+		item, err := tx.MarketItemFetch(itemId, emp.Id)
+		if err != nil {
+			return err
+		} else if item == nil {
+			message("PUBMARKETSELL_CANNOT_REMOVE")
+			return nil
+		} else if item.Time.After(now.Add(-time.Duration(m.cfg.MinTime) * time.Hour)) {
+			message("PUBMARKETSELL_REMOVE_TOO_SOON")
+			return nil
+		}
+
+		// remove it from the market, so as to not hold up other buyers
+		if err := tx.MarketItemDelete(item.Id); err != nil {
+			return err
+		}
+		g := engine.Good_t(item.Type)
+		returned, jackpot := m.e.MarketRemove(g, item.Amount)
+		g.Give(emp, returned)
+		res.Returned = returned
+		message("PUBMARKETSELL_REMOVE_COMPLETE", item.Amount, g.Name(era), returned)
+		// lost goods fund the jackpot
+		if err := tx.WorldVarsAdjust("lotto_current_jackpot", jackpot); err != nil {
+			return err
+		}
+		return m.save(tx, emp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Clean returns goods that have been on the market for more than maxTime
+// hours to their sellers through news attachments. Some of the goods are
+// lost on the way back, and the lost goods fund the lottery jackpot.
+// It returns the number of shipments returned.
+func Clean(tx *orm.DB, e *engine.Engine_t, maxTime int, world *model.World_t, now time.Time) (int, error) {
+	if maxTime < 0 {
+		return 0, nil
+	}
+	cutoff := now.Add(-time.Duration(maxTime) * time.Hour)
+	items, err := tx.MarketExpiredFetch(cutoff)
+	if err != nil {
+		return 0, err
+	}
+	for _, item := range items {
+		seller, err := tx.EmpireFetch(item.EmpireId)
+		if err != nil {
+			return 0, err
+		}
+		g := engine.Good_t(item.Type)
+		returned, jackpot := e.MarketReturn(g, item.Amount, item.Price)
+		news := engine.NewNews(engine.EMPNEWS_ATTACH_MARKET_RETURN, nil, seller, item.Type, item.Amount, item.Price, returned)
+		if err := tx.EmpireNewsCreate(now, news); err != nil {
+			return 0, err
+		}
+		world.LottoCurrentJackpot += jackpot
+	}
+	return tx.MarketExpiredDelete(cutoff)
+}
+
+// load fetches the empire and its era, and verifies that it can use the market.
+func (m *Market_t) load(tx *orm.DB, empireId int, round model.RoundData_t) (*model.Empire_t, *engine.Era_t, error) {
+	if round.Finished {
+		return nil, nil, cerr.ErrRoundFinished
+	} else if !round.Started {
+		return nil, nil, cerr.ErrRoundNotStarted
+	}
+	emp, err := tx.EmpireFetch(empireId)
+	if err != nil {
+		return nil, nil, err
+	} else if m.e.IsProtected(emp, round) {
+		return nil, nil, cerr.ErrEmpireProtected
+	} else if emp.Flags.Admin {
+		return nil, nil, cerr.ErrEmpireAdmin
+	}
+	era, err := m.tables.Era(emp.Era)
+	if err != nil {
+		return nil, nil, err
+	}
+	return emp, era, nil
+}
+
+// onSale returns the number of goods of each type the empire has on the market or on the way to it.
+func (m *Market_t) onSale(tx *orm.DB, empireId int) (map[engine.Good_t]int, error) {
+	items, err := tx.MarketEmpireItems(empireId)
+	if err != nil {
+		return nil, err
+	}
+	onSale := map[engine.Good_t]int{}
+	for _, item := range items {
+		onSale[engine.Good_t(item.Type)] += item.Amount
+	}
+	return onSale, nil
+}
+
+// save updates the empire's networth and writes it to the database.
+func (m *Market_t) save(tx *orm.DB, emp *model.Empire_t) error {
+	emp.NetWorth = m.e.Networth(emp)
+	return tx.EmpireAttributesUpdate(emp)
+}
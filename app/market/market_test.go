@@ -0,0 +1,242 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package market
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/news"
+	"github.com/mdhender/promisance/app/orm"
+	"testing"
+	"time"
+)
+
+var testRound = model.RoundData_t{Started: true}
+
+func setup(t *testing.T) (*orm.DB, *engine.Engine_t, *Market_t, []int) {
+	t.Helper()
+	db, err := orm.CreateMemoryDatabase()
+	if err != nil {
+		t.Fatalf("database: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	if err := db.WorldVarsInitialize(&model.World_t{}); err != nil {
+		t.Fatalf("world: %v", err)
+	}
+	e := engine.New(engine.Config_t{
+		PvtmTrpArm: 500, PvtmTrpLnd: 1000, PvtmTrpFly: 2000, PvtmTrpSea: 3000, PvtmFood: 30,
+		PubmktMaxSell: 25, PubmktMaxFood: 90,
+	}, nil)
+	m, err := New(db, e, engine.DefaultTables(), Config_t{Start: 6, MinTime: -1, MaxTime: 72})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	var ids []int
+	for n := 1; n <= 3; n++ {
+		user, err := db.UserCreate(fmt.Sprintf("trader%d", n), fmt.Sprintf("trader%d@example.com", n))
+		if err != nil {
+			t.Fatalf("user: %v", err)
+		}
+		emp, err := db.EmpireCreate(user, fmt.Sprintf("trader %d", n), "HUMAN")
+		if err != nil {
+			t.Fatalf("empire: %v", err)
+		}
+		emp.Era, emp.Cash, emp.TrpArm, emp.Food = engine.ERA_PAST, 100000, 1000, 5000
+		if err := db.EmpireAttributesUpdate(emp); err != nil {
+			t.Fatalf("empire: %v", err)
+		}
+		ids = append(ids, emp.Id)
+	}
+	return db, e, m, ids
+}
+
+func fetch(t *testing.T, db *orm.DB, id int) *model.Empire_t {
+	t.Helper()
+	emp, err := db.EmpireFetch(id)
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	return emp
+}
+
+func TestMarket(t *testing.T) {
+	db, e, m, ids := setup(t)
+	sellerA, sellerB, buyer := ids[0], ids[1], ids[2]
+	t0 := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	// sales are capped at 25% of the troops on hand, including those already on sale
+	res, err := m.Sell(sellerA, testRound, []Order_t{{Good: engine.MARKET_TRPARM, Amount: 300, Price: 300}}, t0)
+	if err != nil {
+		t.Fatalf("sell: %v", err)
+	} else if len(res.Shipments) != 0 || len(res.Messages) != 1 || res.Messages[0].Key != "PUBMARKETSELL_TOO_MANY_UNITS" {
+		t.Errorf("sell: too many: got %+v", res)
+	}
+	res, err = m.Sell(sellerA, testRound, []Order_t{{Good: engine.MARKET_TRPARM, Amount: 100, Price: 1500}}, t0)
+	if err != nil {
+		t.Fatalf("sell: %v", err)
+	} else if len(res.Messages) != 1 || res.Messages[0].Key != "PUBMARKETSELL_PROCE_TOO_HIGH" {
+		t.Errorf("sell: too high: got %+v", res)
+	}
+	for _, s := range []struct {
+		id     int
+		amount int
+		price  int
+		at     time.Time
+	}{
+		{sellerA, 100, 300, t0},
+		{sellerA, 40, 200, t0},
+		{sellerB, 50, 200, t0.Add(time.Hour)},
+	} {
+		res, err := m.Sell(s.id, testRound, []Order_t{{Good: engine.MARKET_TRPARM, Amount: s.amount, Price: s.price}}, s.at)
+		if err != nil {
+			t.Fatalf("sell: %v", err)
+		} else if len(res.Shipments) != 1 || !res.Shipments[0].Time.Equal(s.at.Add(6*time.Hour)) {
+			t.Fatalf("sell: got %+v", res)
+		}
+	}
+	if emp := fetch(t, db, sellerA); emp.TrpArm != 860 {
+		t.Errorf("seller: trparm: want 860, got %d", emp.TrpArm)
+	}
+	quotes, err := m.Quotes(sellerA, t0)
+	if err != nil {
+		t.Fatalf("quotes: %v", err)
+	} else if q := quotes[engine.MARKET_TRPARM]; q.OnSale != 140 || q.MaxSell != 110 {
+		t.Errorf("quotes: want on sale 140, max sell 110, got %+v", q)
+	}
+
+	// goods are not for sale until they arrive
+	bought, err := m.Buy(buyer, testRound, []Order_t{{Good: engine.MARKET_TRPARM, Amount: 10, Price: 300}}, t0.Add(5*time.Hour))
+	if err != nil {
+		t.Fatalf("buy: %v", err)
+	} else if len(bought.Purchases) != 0 || bought.Messages[0].Key != "PUBMARKETBUY_BOUGHT_NONE" {
+		t.Errorf("buy: early: got %+v", bought)
+	}
+
+	now := t0.Add(8 * time.Hour)
+	quotes, err = m.Quotes(buyer, now)
+	if err != nil {
+		t.Fatalf("quotes: %v", err)
+	} else if q := quotes[engine.MARKET_TRPARM]; q.Price != 200 || q.Available != 90 || q.CanBuy != 90 {
+		t.Errorf("quotes: want price 200, available 90, got %+v", q)
+	}
+
+	// cheapest goods first, then oldest, with the last shipment partially filled
+	bought, err = m.Buy(buyer, testRound, []Order_t{{Good: engine.MARKET_TRPARM, Amount: 120, Price: 300}}, now)
+	if err != nil {
+		t.Fatalf("buy: %v", err)
+	}
+	want := []Purchase_t{{engine.MARKET_TRPARM, 90, 200}, {engine.MARKET_TRPARM, 30, 300}}
+	if len(bought.Purchases) != 2 || bought.Purchases[0] != want[0] || bought.Purchases[1] != want[1] || bought.Spent != 27000 {
+		t.Errorf("buy: want %+v, got %+v", want, bought)
+	}
+	if emp := fetch(t, db, buyer); emp.TrpArm != 1120 || emp.Cash != 73000 {
+		t.Errorf("buyer: want trparm 1120, cash 73000, got %d, %d", emp.TrpArm, emp.Cash)
+	}
+	if items, err := m.Shipments(sellerA); err != nil {
+		t.Fatalf("shipments: %v", err)
+	} else if len(items) != 1 || items[0].Amount != 70 || items[0].Price != 300 {
+		t.Errorf("shipments: got %+v", items)
+	}
+
+	// sellers are paid, less taxes, when they log in
+	for _, s := range []struct {
+		id   int
+		cash int
+	}{
+		{sellerA, 100000 + 7600 + 8550},
+		{sellerB, 100000 + 9500},
+	} {
+		if _, err := news.Give(db, e, engine.DefaultTables(), s.id, now); err != nil {
+			t.Fatalf("give: %v", err)
+		} else if emp := fetch(t, db, s.id); emp.Cash != s.cash {
+			t.Errorf("seller %d: cash: want %d, got %d", s.id, s.cash, emp.Cash)
+		}
+	}
+	// and only once
+	if messages, err := news.Give(db, e, engine.DefaultTables(), sellerA, now); err != nil {
+		t.Fatalf("give: %v", err)
+	} else if len(messages) != 0 {
+		t.Errorf("give: again: got %+v", messages)
+	}
+
+	// unsold goods are returned, less losses, once they expire
+	world, err := db.WorldVarsFetch()
+	if err != nil {
+		t.Fatalf("world: %v", err)
+	} else if n, err := db.WorldVarsApplyAdjustments(world); err != nil {
+		t.Fatalf("world: adjust: %v", err)
+	} else if n != 1 || world.LottoCurrentJackpot != 1350 {
+		t.Errorf("jackpot: want 1350, got %d", world.LottoCurrentJackpot)
+	}
+	if n, err := Clean(db, e, 72, world, t0.Add(77*time.Hour)); err != nil || n != 0 {
+		t.Errorf("clean: early: want 0, got %d, %v", n, err)
+	}
+	if n, err := Clean(db, e, 72, world, t0.Add(78*time.Hour)); err != nil || n != 1 {
+		t.Errorf("clean: want 1, got %d, %v", n, err)
+	} else if world.LottoCurrentJackpot != 1350+1400 {
+		t.Errorf("clean: jackpot: want 2750, got %d", world.LottoCurrentJackpot)
+	}
+	if _, err := news.Give(db, e, engine.DefaultTables(), sellerA, now); err != nil {
+		t.Fatalf("give: %v", err)
+	} else if emp := fetch(t, db, sellerA); emp.TrpArm != 860+56 {
+		t.Errorf("returned: trparm: want 916, got %d", emp.TrpArm)
+	}
+}
+
+func TestMarketErrors(t *testing.T) {
+	_, _, m, ids := setup(t)
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	order := []Order_t{{Good: engine.MARKET_FOOD, Amount: 10, Price: 30}}
+	if _, err := m.Buy(ids[0], model.RoundData_t{Finished: true}, order, now); !errors.Is(err, cerr.ErrRoundFinished) {
+		t.Errorf("finished: want %v, got %v", cerr.ErrRoundFinished, err)
+	}
+	if _, err := m.Sell(ids[0], model.RoundData_t{}, order, now); !errors.Is(err, cerr.ErrRoundNotStarted) {
+		t.Errorf("not started: want %v, got %v", cerr.ErrRoundNotStarted, err)
+	}
+	if _, err := m.Sell(ids[0], testRound, []Order_t{{Good: 9, Amount: 1, Price: 1}}, now); !errors.Is(err, cerr.ErrUnknownGood) {
+		t.Errorf("goods: want %v, got %v", cerr.ErrUnknownGood, err)
+	}
+	if _, err := m.Remove(ids[0], testRound, 1, now); !errors.Is(err, cerr.ErrRemoveNotAllowed) {
+		t.Errorf("remove: want %v, got %v", cerr.ErrRemoveNotAllowed, err)
+	}
+}
+
+func TestPrivateMarket(t *testing.T) {
+	db, _, m, ids := setup(t)
+	emp := fetch(t, db, ids[0])
+	emp.MktArm = 100
+	if err := db.EmpireAttributesUpdate(emp); err != nil {
+		t.Fatalf("empire: %v", err)
+	}
+
+	if _, err := m.PrivateBuy(emp.Id, model.RoundData_t{}, nil); !errors.Is(err, cerr.ErrRoundNotStarted) {
+		t.Errorf("not started: want %v, got %v", cerr.ErrRoundNotStarted, err)
+	}
+	quotes, err := m.PrivateQuotes(emp.Id)
+	if err != nil {
+		t.Fatalf("quotes: %v", err)
+	} else if q := quotes[engine.MARKET_TRPARM]; q.Stock != 100 || q.BuyPrice != 500 || q.CanBuy != 100 || q.SellPrice != 160 || q.CanSell != 0 {
+		t.Errorf("quotes: got %+v", q)
+	}
+
+	res, err := m.PrivateBuy(emp.Id, testRound, []Order_t{{Good: engine.MARKET_TRPARM, Amount: 60}, {Good: engine.MARKET_TRPARM, Amount: 60}})
+	if err != nil {
+		t.Fatalf("buy: %v", err)
+	} else if res.Cash != 30000 || len(res.Messages) != 2 || res.Messages[1].Key != "PVTMARKETBUY_NOT_ENOUGH_UNITS" {
+		t.Errorf("buy: got %+v", res)
+	}
+	res, err = m.PrivateSell(emp.Id, testRound, []Order_t{{Good: engine.MARKET_FOOD, Amount: 5000}})
+	if err != nil {
+		t.Fatalf("sell: %v", err)
+	} else if res.Cash != 30000 {
+		t.Errorf("sell: got %+v", res)
+	}
+	if emp := fetch(t, db, emp.Id); emp.TrpArm != 1060 || emp.MktArm != 40 || emp.Food != 0 || emp.Cash != 100000 {
+		t.Errorf("empire: want trparm 1060, mktarm 40, food 0, cash 100000, got %d, %d, %d, %d", emp.TrpArm, emp.MktArm, emp.Food, emp.Cash)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package market
+
+import (
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/orm"
+)
+
+// The private market, from php/pages/pvtmarketbuy.php and php/pages/pvtmarketsell.php,
+// trades directly with the empire at prices set by its markets, shops, and race.
+// The market restocks and forgets recent sales every turn.
+
+// PrivateQuote_t describes the private market for one type of goods from the point of view of an empire.
+type PrivateQuote_t struct {
+	Good      engine.Good_t
+	Owned     int // goods held by the empire
+	Stock     int // goods the market has for the empire to buy
+	BuyPrice  int // price the empire pays for one unit
+	CanBuy    int // goods the empire can afford to buy
+	SellPrice int // price the market pays for one unit
+	CanSell   int // goods the empire can sell
+}
+
+// PrivateQuotes returns the current state of the private market for each type of goods.
+func (m *Market_t) PrivateQuotes(empireId int) ([]PrivateQuote_t, error) {
+	emp, err := m.db.EmpireFetch(empireId)
+	if err != nil {
+		return nil, err
+	}
+	mods := m.tables.Modifiers(emp)
+	var quotes []PrivateQuote_t
+	for _, g := range engine.Goods() {
+		quotes = append(quotes, PrivateQuote_t{
+			Good:      g,
+			Owned:     g.Owned(emp),
+			Stock:     m.e.PvtmStock(emp, g),
+			BuyPrice:  m.e.PvtmBuyPrice(emp, mods, g),
+			CanBuy:    m.e.PvtmCanBuy(emp, mods, g),
+			SellPrice: m.e.PvtmSellPrice(emp, mods, g),
+			CanSell:   m.e.PvtmCanSell(emp, g),
+		})
+	}
+	return quotes, nil
+}
+
+// PrivateResult_t is the result of trading on the private market.
+type PrivateResult_t struct {
+	Cash     int // cash spent buying goods or earned selling them
+	Messages []engine.Message_t
+}
+
+// PrivateBuy buys goods from the private market for the empire.
+// The price in each order is ignored; goods are bought at the current price.
+func (m *Market_t) PrivateBuy(empireId int, round model.RoundData_t, orders []Order_t) (*PrivateResult_t, error) {
+	return m.private(empireId, round, orders, m.e.PvtmBuy)
+}
+
+// PrivateSell sells goods from the empire to the private market.
+// The price in each order is ignored; goods are sold at the current price.
+func (m *Market_t) PrivateSell(empireId int, round model.RoundData_t, orders []Order_t) (*PrivateResult_t, error) {
+	return m.private(empireId, round, orders, m.e.PvtmSell)
+}
+
+// private applies a trade to each order and saves the empire.
+func (m *Market_t) private(empireId int, round model.RoundData_t, orders []Order_t, trade func(*model.Empire_t, *engine.Era_t, engine.Modifiers_t, engine.Good_t, int) (int, engine.Message_t)) (*PrivateResult_t, error) {
+	if round.Finished {
+		return nil, cerr.ErrRoundFinished
+	} else if !round.Started {
+		return nil, cerr.ErrRoundNotStarted
+	}
+	res := &PrivateResult_t{}
+	err := m.db.Transaction(func(tx *orm.DB) error {
+		emp, err := tx.EmpireFetch(empireId)
+		if err != nil {
+			return err
+		}
+		era, err := m.tables.Era(emp.Era)
+		if err != nil {
+			return err
+		}
+		mods := m.tables.Modifiers(emp)
+		for _, order := range orders {
+			if !order.Good.IsValid() {
+				return cerr.ErrUnknownGood
+			} else if order.Amount <= 0 {
+				continue
+			}
+			cash, msg := trade(emp, era, mods, order.Good, order.Amount)
+			res.Cash += cash
+			res.Messages = append(res.Messages, msg)
+		}
+		return m.save(tx, emp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
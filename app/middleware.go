@@ -50,12 +50,3 @@ func (s *server) setRoundTimes() func(http.Handler) http.Handler {
 		})
 	}
 }
-
-func (s *server) turnsCrontab() func(http.Handler) http.Handler {
-	log.Printf("todo: implement turnsCrontab middleware\n")
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			next.ServeHTTP(w, r)
-		})
-	}
-}
@@ -0,0 +1,162 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+// Package military implements military management from php/pages/manage/empire.php.
+//
+// Players allocate their barracks' industry across the four types of units
+// and may release units from service, who return home as peasants.
+package military
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/orm"
+	"time"
+)
+
+// Military_t manages military units against the database.
+type Military_t struct {
+	db     *orm.DB
+	e      *engine.Engine_t
+	tables *engine.Tables_t
+}
+
+// New returns a military management service.
+func New(db *orm.DB, e *engine.Engine_t, tables *engine.Tables_t) (*Military_t, error) {
+	if db == nil {
+		return nil, fmt.Errorf("missing database")
+	} else if e == nil {
+		return nil, fmt.Errorf("missing engine")
+	} else if tables == nil {
+		return nil, fmt.Errorf("missing tables")
+	}
+	return &Military_t{db: db, e: e, tables: tables}, nil
+}
+
+// State_t describes the empire's military.
+type State_t struct {
+	Units      engine.Units_t // units in service
+	Industry   engine.Units_t // percentage of industry allocated to each unit
+	Production engine.Units_t // units produced each turn
+	Peasants   int
+}
+
+// State returns the empire's military.
+func (m *Military_t) State(empireId int, now time.Time) (*State_t, error) {
+	var state *State_t
+	err := m.db.Transaction(func(tx *orm.DB) error {
+		emp, err := tx.EmpireFetch(empireId)
+		if err != nil {
+			return err
+		}
+		fx, err := tx.EmpireEffectsFetch(emp.Id, m.tables.Effects)
+		if err != nil {
+			return err
+		}
+		state = m.state(emp, fx, now)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// Result_t is the result of changing the empire's military.
+type Result_t struct {
+	Messages []engine.Message_t
+	State    *State_t // military after the change
+}
+
+// SetIndustry allocates the empire's industry.
+// Allocations totaling more than 100 percent are rejected with a message.
+func (m *Military_t) SetIndustry(empireId int, alloc engine.Units_t, now time.Time) (*Result_t, error) {
+	res := &Result_t{}
+	err := m.db.Transaction(func(tx *orm.DB) error {
+		emp, err := tx.EmpireFetch(empireId)
+		if err != nil {
+			return err
+		}
+		fx, err := tx.EmpireEffectsFetch(emp.Id, m.tables.Effects)
+		if err != nil {
+			return err
+		}
+		updated, err := m.e.SetIndustry(*emp, alloc)
+		if errors.Is(err, cerr.ErrNeedInput) || errors.Is(err, cerr.ErrIndustryTooHigh) {
+			res.Messages = append(res.Messages, engine.Message_t{Key: "MANAGE_EMPIRE_INDUSTRY_TOO_HIGH"})
+			res.State = m.state(emp, fx, now)
+			return nil
+		} else if err != nil {
+			return err
+		}
+		*emp = updated
+		if err := tx.EmpireAttributesUpdate(emp); err != nil {
+			return err
+		}
+		res.Messages = append(res.Messages, engine.Message_t{Key: "MANAGE_EMPIRE_INDUSTRY_COMPLETE"})
+		res.State = m.state(emp, fx, now)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Release discharges units from the empire's military.
+// Requests for more units than the empire has are rejected with a message.
+func (m *Military_t) Release(empireId int, round model.RoundData_t, units engine.Units_t, now time.Time) (*Result_t, error) {
+	if round.Finished {
+		return nil, cerr.ErrRoundFinished
+	} else if !round.Started {
+		return nil, cerr.ErrRoundNotStarted
+	}
+	res := &Result_t{}
+	err := m.db.Transaction(func(tx *orm.DB) error {
+		emp, err := tx.EmpireFetch(empireId)
+		if err != nil {
+			return err
+		}
+		fx, err := tx.EmpireEffectsFetch(emp.Id, m.tables.Effects)
+		if err != nil {
+			return err
+		}
+		updated, err := m.e.Release(*emp, units)
+		if errors.Is(err, cerr.ErrNeedInput) {
+			res.Messages = append(res.Messages, engine.Message_t{Key: "MANAGE_EMPIRE_RELEASE_NEED_UNITS"})
+			res.State = m.state(emp, fx, now)
+			return nil
+		} else if errors.Is(err, cerr.ErrReleaseTooMany) {
+			res.Messages = append(res.Messages, engine.Message_t{Key: "MANAGE_EMPIRE_RELEASE_TOO_MANY"})
+			res.State = m.state(emp, fx, now)
+			return nil
+		} else if err != nil {
+			return err
+		}
+		released := emp.TrpArm + emp.TrpLnd + emp.TrpFly + emp.TrpSea - (updated.TrpArm + updated.TrpLnd + updated.TrpFly + updated.TrpSea)
+		*emp = updated
+		if err := tx.EmpireAttributesUpdate(emp); err != nil {
+			return err
+		}
+		res.Messages = append(res.Messages, engine.Message_t{Key: "MANAGE_EMPIRE_RELEASE_COMPLETE", Args: []any{released}})
+		res.State = m.state(emp, fx, now)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// state returns the empire's military.
+func (m *Military_t) state(emp *model.Empire_t, fx *engine.Effects_t, now time.Time) *State_t {
+	mods := m.tables.Modifiers(emp).Add(fx.Modifiers(now))
+	return &State_t{
+		Units:      engine.Units_t{TrpArm: emp.TrpArm, TrpLnd: emp.TrpLnd, TrpFly: emp.TrpFly, TrpSea: emp.TrpSea},
+		Industry:   m.e.Industry(emp),
+		Production: m.e.Production(emp, mods),
+		Peasants:   emp.Peasants,
+	}
+}
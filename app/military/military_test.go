@@ -0,0 +1,78 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package military
+
+import (
+	"errors"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/orm"
+	"testing"
+	"time"
+)
+
+func TestMilitary(t *testing.T) {
+	db, err := orm.CreateMemoryDatabase()
+	if err != nil {
+		t.Fatalf("database: %v", err)
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+	e := engine.New(engine.Config_t{IndustryMult: 2.5}, nil)
+	m, err := New(db, e, engine.DefaultTables())
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	user, err := db.UserCreate("general", "general@example.com")
+	if err != nil {
+		t.Fatalf("user: %v", err)
+	}
+	emp, err := db.EmpireCreate(user, "general", "HUMAN")
+	if err != nil {
+		t.Fatalf("empire: %v", err)
+	}
+	emp.BldTrp, emp.TrpArm, emp.TrpSea, emp.Peasants = 40, 100, 10, 500
+	if err := db.EmpireAttributesUpdate(emp); err != nil {
+		t.Fatalf("empire: %v", err)
+	}
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	started := model.RoundData_t{Started: true}
+
+	res, err := m.SetIndustry(emp.Id, engine.Units_t{TrpArm: 60, TrpLnd: 50}, now)
+	if err != nil {
+		t.Fatalf("industry: %v", err)
+	} else if len(res.Messages) != 1 || res.Messages[0].Key != "MANAGE_EMPIRE_INDUSTRY_TOO_HIGH" {
+		t.Errorf("industry: too high: got %+v", res.Messages)
+	}
+	res, err = m.SetIndustry(emp.Id, engine.Units_t{TrpArm: 100}, now)
+	if err != nil {
+		t.Fatalf("industry: %v", err)
+	} else if len(res.Messages) != 1 || res.Messages[0].Key != "MANAGE_EMPIRE_INDUSTRY_COMPLETE" {
+		t.Errorf("industry: got %+v", res.Messages)
+	} else if res.State.Industry != (engine.Units_t{TrpArm: 100}) || res.State.Production.TrpArm == 0 || res.State.Production.TrpLnd != 0 {
+		t.Errorf("industry: got %+v", res.State)
+	}
+
+	if _, err := m.Release(emp.Id, model.RoundData_t{}, engine.Units_t{TrpArm: 1}, now); !errors.Is(err, cerr.ErrRoundNotStarted) {
+		t.Errorf("release: want %v, got %v", cerr.ErrRoundNotStarted, err)
+	}
+	res, err = m.Release(emp.Id, started, engine.Units_t{TrpSea: 11}, now)
+	if err != nil {
+		t.Fatalf("release: %v", err)
+	} else if len(res.Messages) != 1 || res.Messages[0].Key != "MANAGE_EMPIRE_RELEASE_TOO_MANY" {
+		t.Errorf("release: too many: got %+v", res.Messages)
+	}
+	res, err = m.Release(emp.Id, started, engine.Units_t{TrpArm: 40, TrpSea: 10}, now)
+	if err != nil {
+		t.Fatalf("release: %v", err)
+	} else if len(res.Messages) != 1 || res.Messages[0].Key != "MANAGE_EMPIRE_RELEASE_COMPLETE" || res.Messages[0].Args[0] != 50 {
+		t.Errorf("release: got %+v", res.Messages)
+	}
+	if got, err := db.EmpireFetch(emp.Id); err != nil {
+		t.Fatalf("fetch: %v", err)
+	} else if got.TrpArm != 60 || got.TrpSea != 0 || got.Peasants != 550 || got.IndArm != 100 {
+		t.Errorf("empire: got arm %d, sea %d, peasants %d, industry %d", got.TrpArm, got.TrpSea, got.Peasants, got.IndArm)
+	}
+}
@@ -73,6 +73,21 @@ type Empire_t struct {
 	MktPerSea   int
 }
 
+// Clan_t is a clan of empires.
+type Clan_t struct {
+	Id        int
+	Name      string
+	Password  string
+	Members   int
+	Leader    int // empire id of the leader
+	Assistant int // empire id of the assistant leader
+	Minister1 int // empire ids of the ministers of foreign affairs
+	Minister2 int
+	Title     string
+	URL       string
+	Pic       string
+}
+
 type EmpireFlag_t struct {
 	// Unused
 	Mod bool
@@ -96,6 +111,29 @@ type EmpireFlag_t struct {
 	Logged bool
 }
 
+// LotteryDraw_t is the result of a lottery drawing.
+type LotteryDraw_t struct {
+	Id         int
+	Time       time.Time // the turn interval of the drawing
+	Ticket     int       // the number picked
+	Tickets    int       // tickets purchased for the drawing
+	Jackpot    int       // jackpot at the time of the drawing
+	Increase   int       // increase in the jackpot since the previous drawing
+	EmpireId   int       // the winning empire, zero if no one held the ticket
+	EmpireName string
+	Won        int // amount won, zero if no one held the ticket
+}
+
+// MarketItem_t is a shipment of goods on the public market.
+type MarketItem_t struct {
+	Id       int
+	Type     int // one of the MARKET_* goods
+	EmpireId int // the seller
+	Amount   int
+	Price    int
+	Time     time.Time // when the goods arrive on the market
+}
+
 type RoundData_t struct {
 	Signup     bool
 	Started    bool
@@ -104,6 +142,15 @@ type RoundData_t struct {
 	TimeNotice string
 }
 
+type TurnLog_t struct {
+	Id       int
+	Time     time.Time // when the entry was logged
+	Interval time.Time // the turn interval being processed, zero if none
+	Type     int       // one of the TURN_* constants
+	Updates  int       // TURNS_NEED_* flags for the interval being processed
+	Text     string
+}
+
 type User_t struct {
 	Id         int
 	UserName   string
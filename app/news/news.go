@@ -0,0 +1,62 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+// Package news delivers the goods attached to empire news events,
+// from prom_empire::giveNews in php/classes/prom_empire.php.
+package news
+
+import (
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/orm"
+	"time"
+)
+
+// Give gives the empire the goods attached to news events it has not yet
+// received, and marks the events as received. The server calls it on every
+// game page request, like page_header in php/includes/auth.php.
+// Delivering foreign aid may create news events, which are dated now.
+// It returns a message describing each event that changed the empire.
+func Give(db *orm.DB, e *engine.Engine_t, tables *engine.Tables_t, empireId int, now time.Time) ([]engine.Message_t, error) {
+	var messages []engine.Message_t
+	err := db.Transaction(func(tx *orm.DB) error {
+		news, err := tx.EmpireNewsAttachmentsLock(empireId)
+		if err != nil {
+			return err
+		} else if len(news) == 0 {
+			return nil
+		}
+		emp, err := tx.EmpireFetch(empireId)
+		if err != nil {
+			return err
+		}
+		era, err := tables.Era(emp.Era)
+		if err != nil {
+			return err
+		}
+		// the messages for returned aid convoys name the empire that sent the aid
+		senders := map[int]*model.Empire_t{}
+		for _, n := range news {
+			if n.Event != engine.EMPNEWS_ATTACH_AID_SEND && n.Event != engine.EMPNEWS_ATTACH_AID_SENDCLAN {
+				continue
+			} else if _, ok := senders[n.Source]; ok {
+				continue
+			}
+			if senders[n.Source], err = tx.EmpireFetch(n.Source); err != nil {
+				return err
+			}
+		}
+		var created []engine.News_t
+		messages, created = e.GiveNews(emp, era, news, senders)
+		emp.NetWorth = e.Networth(emp)
+		if err := tx.EmpireAttributesUpdate(emp); err != nil {
+			return err
+		} else if err := tx.EmpireNewsCreate(now, created...); err != nil {
+			return err
+		}
+		return tx.EmpireNewsAttachmentsGotten(empireId)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
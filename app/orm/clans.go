@@ -0,0 +1,652 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package orm
+
+import (
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/orm/sqlc"
+	"time"
+)
+
+// ClanAid_t is a shipment of foreign aid sent between members of a clan.
+type ClanAid_t struct {
+	Id        int
+	Time      time.Time
+	Sender    int // empire id
+	Recipient int // empire id
+	Convoy    int
+	Cargo     engine.Cargo_t
+}
+
+// ClanAid returns the foreign aid sent between members of the clan, oldest first.
+func (db *DB) ClanAid(clanId int) ([]*ClanAid_t, error) {
+	rows, err := db.db.EmpireNewsClanAidFetch(db.ctx, sqlc.EmpireNewsClanAidFetchParams{
+		CIDSrc: int64(clanId),
+		NEvent: engine.EMPNEWS_ATTACH_AID_SENDCLAN,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var list []*ClanAid_t
+	for _, row := range rows {
+		list = append(list, &ClanAid_t{
+			Id:        int(row.NID),
+			Time:      time.Unix(row.NTime, 0).UTC(),
+			Sender:    int(row.EIDSrc),
+			Recipient: int(row.EIDDst),
+			Convoy:    int(row.ND0),
+			Cargo: engine.Cargo_t{
+				TrpArm: int(row.ND1),
+				TrpLnd: int(row.ND2),
+				TrpFly: int(row.ND3),
+				TrpSea: int(row.ND4),
+				Cash:   int(row.ND5),
+				Runes:  int(row.ND6),
+				Food:   int(row.ND7),
+			},
+		})
+	}
+	return list, nil
+}
+
+// ClanAllies returns the ids of the clans in a mutual alliance with the clan,
+// from prom_clan::getAllies.
+func (db *DB) ClanAllies(clanId int) ([]int, error) {
+	rels, err := db.ClanRelations(clanId, CRFLAG_ALLY|CRFLAG_MUTUAL, CRFLAG_ALLY|CRFLAG_MUTUAL, RELATION_BOTH)
+	if err != nil {
+		return nil, err
+	}
+	var allies []int
+	for _, rel := range rels {
+		allies = append(allies, rel.Other(clanId))
+	}
+	return allies, nil
+}
+
+// ClanCreate adds the clan to the clan table and sets its id.
+func (db *DB) ClanCreate(clan *model.Clan_t) error {
+	id, err := db.db.ClanCreate(db.ctx, sqlc.ClanCreateParams{
+		CName:     clan.Name,
+		CPassword: clan.Password,
+		CMembers:  int64(clan.Members),
+		EIDLeader: int64(clan.Leader),
+		CTitle:    clan.Title,
+	})
+	if err != nil {
+		return err
+	}
+	clan.Id = int(id)
+	return nil
+}
+
+// ClanFetch returns the clan.
+func (db *DB) ClanFetch(clanId int) (*model.Clan_t, error) {
+	row, err := db.db.ClanFetch(db.ctx, int64(clanId))
+	if err != nil {
+		return nil, err
+	}
+	return clanFromRow(row), nil
+}
+
+// ClanInvite_t is an invitation for an empire to join a clan.
+type ClanInvite_t struct {
+	Id        int
+	Clan      int
+	Sender    int // the officer who sent the invitation
+	Recipient int
+	Flags     int // CIFLAG_PERM
+	Time      time.Time
+}
+
+// ClanInvite returns the invitation.
+func (db *DB) ClanInvite(inviteId int) (*ClanInvite_t, error) {
+	row, err := db.db.ClanInviteFetch(db.ctx, int64(inviteId))
+	if err != nil {
+		return nil, err
+	}
+	return clanInviteFromRow(row), nil
+}
+
+// ClanInviteCreate adds the invitation to the clan_invite table and sets its id.
+func (db *DB) ClanInviteCreate(inv *ClanInvite_t) error {
+	id, err := db.db.ClanInviteCreate(db.ctx, sqlc.ClanInviteCreateParams{
+		CID:     int64(inv.Clan),
+		EID1:    int64(inv.Sender),
+		EID2:    int64(inv.Recipient),
+		CiFlags: int64(inv.Flags),
+		CiTime:  inv.Time.Unix(),
+	})
+	if err != nil {
+		return err
+	}
+	inv.Id = int(id)
+	return nil
+}
+
+// ClanInviteDelete removes the invitation.
+func (db *DB) ClanInviteDelete(inviteId int) error {
+	return db.db.ClanInviteDelete(db.ctx, int64(inviteId))
+}
+
+// ClanInviteFind returns the clan's invitation to the empire.
+// It returns sql.ErrNoRows if the empire has not been invited.
+func (db *DB) ClanInviteFind(clanId, empireId int) (*ClanInvite_t, error) {
+	row, err := db.db.ClanInviteFind(db.ctx, sqlc.ClanInviteFindParams{
+		CID:  int64(clanId),
+		EID2: int64(empireId),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return clanInviteFromRow(row), nil
+}
+
+// ClanInviteRecipients returns the ids of the empires invited to join the clan.
+func (db *DB) ClanInviteRecipients(clanId int) ([]int, error) {
+	rows, err := db.db.ClanInviteRecipientsFetch(db.ctx, int64(clanId))
+	if err != nil {
+		return nil, err
+	}
+	var list []int
+	for _, row := range rows {
+		list = append(list, int(row))
+	}
+	return list, nil
+}
+
+// ClanInviteUpdate saves the sender, flags, and time of the invitation.
+func (db *DB) ClanInviteUpdate(inv *ClanInvite_t) error {
+	return db.db.ClanInviteUpdate(db.ctx, sqlc.ClanInviteUpdateParams{
+		EID1:    int64(inv.Sender),
+		CiFlags: int64(inv.Flags),
+		CiTime:  inv.Time.Unix(),
+		CiID:    int64(inv.Id),
+	})
+}
+
+// ClanInvites returns the invitations to join the clan.
+func (db *DB) ClanInvites(clanId int) ([]*ClanInvite_t, error) {
+	rows, err := db.db.ClanInvitesFetch(db.ctx, int64(clanId))
+	if err != nil {
+		return nil, err
+	}
+	var list []*ClanInvite_t
+	for _, row := range rows {
+		list = append(list, clanInviteFromRow(row))
+	}
+	return list, nil
+}
+
+// ClanInvitesDelete removes every invitation to join the clan.
+func (db *DB) ClanInvitesDelete(clanId int) error {
+	return db.db.ClanInvitesDelete(db.ctx, int64(clanId))
+}
+
+// ClanInvitesDeleteExpired removes the temporary invitations sent at or
+// before the given time. It returns the number of invitations removed.
+func (db *DB) ClanInvitesDeleteExpired(before time.Time) (int, error) {
+	n, err := db.db.ClanInvitesDeleteExpired(db.ctx, sqlc.ClanInvitesDeleteExpiredParams{
+		Flags:  CIFLAG_PERM,
+		CiTime: before.Unix(),
+	})
+	return int(n), err
+}
+
+// ClanInvitesDeleteTemporary removes the empire's invitations that are not permanent.
+func (db *DB) ClanInvitesDeleteTemporary(empireId int) error {
+	return db.db.ClanInvitesDeleteTemporary(db.ctx, sqlc.ClanInvitesDeleteTemporaryParams{
+		EID2:  int64(empireId),
+		Flags: CIFLAG_PERM,
+	})
+}
+
+// ClanInvitesReceived returns the invitations the empire has received.
+func (db *DB) ClanInvitesReceived(empireId int) ([]*ClanInvite_t, error) {
+	rows, err := db.db.ClanInvitesReceivedFetch(db.ctx, int64(empireId))
+	if err != nil {
+		return nil, err
+	}
+	var list []*ClanInvite_t
+	for _, row := range rows {
+		list = append(list, clanInviteFromRow(row))
+	}
+	return list, nil
+}
+
+func clanInviteFromRow(row sqlc.ClanInvite) *ClanInvite_t {
+	return &ClanInvite_t{
+		Id:        int(row.CiID),
+		Clan:      int(row.CID),
+		Sender:    int(row.EID1),
+		Recipient: int(row.EID2),
+		Flags:     int(row.CiFlags),
+		Time:      time.Unix(row.CiTime, 0).UTC(),
+	}
+}
+
+// ClanMember_t is a member of a clan.
+type ClanMember_t struct {
+	Id       int
+	Name     string
+	Flags    model.EmpireFlag_t
+	Land     int
+	Networth int
+	Sharing  int // negative while sharing, counting down while unsharing
+	TrpArm   int
+	TrpLnd   int
+	TrpFly   int
+	TrpSea   int
+}
+
+// ClanMembers returns the members of the clan, strongest first.
+func (db *DB) ClanMembers(clanId int) ([]*ClanMember_t, error) {
+	rows, err := db.db.ClanMembersFetch(db.ctx, int64(clanId))
+	if err != nil {
+		return nil, err
+	}
+	var list []*ClanMember_t
+	for _, row := range rows {
+		list = append(list, &ClanMember_t{
+			Id:       int(row.EID),
+			Name:     row.EName,
+			Flags:    intToEmpireFlags(row.EFlags),
+			Land:     nvlInt(row.ELand),
+			Networth: nvlInt(row.ENetworth),
+			Sharing:  nvlInt(row.ESharing),
+			TrpArm:   nvlInt(row.ETrparm),
+			TrpLnd:   nvlInt(row.ETrplnd),
+			TrpFly:   nvlInt(row.ETrpfly),
+			TrpSea:   nvlInt(row.ETrpsea),
+		})
+	}
+	return list, nil
+}
+
+// ClanMessage_t is a post in the clan forum.
+type ClanMessage_t struct {
+	Id     int
+	Topic  int
+	Empire int // the author
+	Body   string
+	Time   time.Time
+	Flags  int // CMFLAG_DELETE, CMFLAG_EDIT
+}
+
+// ClanMessage returns the post.
+func (db *DB) ClanMessage(messageId int) (*ClanMessage_t, error) {
+	row, err := db.db.ClanMessageFetch(db.ctx, int64(messageId))
+	if err != nil {
+		return nil, err
+	}
+	return clanMessageFromRow(row), nil
+}
+
+// ClanMessageCreate adds a post to a topic in the clan forum and returns its id.
+func (db *DB) ClanMessageCreate(topicId, empireId int, body string, flags int, now time.Time) (int, error) {
+	id, err := db.db.ClanMessageCreate(db.ctx, sqlc.ClanMessageCreateParams{
+		CtID:    int64(topicId),
+		EID:     int64(empireId),
+		CmBody:  body,
+		CmTime:  now.Unix(),
+		CmFlags: int64(flags),
+	})
+	return int(id), err
+}
+
+// ClanMessageUpdate saves the body, time, and flags of the post.
+func (db *DB) ClanMessageUpdate(msg *ClanMessage_t) error {
+	return db.db.ClanMessageUpdate(db.ctx, sqlc.ClanMessageUpdateParams{
+		CmBody:  msg.Body,
+		CmTime:  msg.Time.Unix(),
+		CmFlags: int64(msg.Flags),
+		CmID:    int64(msg.Id),
+	})
+}
+
+// ClanMessages returns the posts in the topic that are not deleted, oldest first.
+func (db *DB) ClanMessages(topicId int) ([]*ClanMessage_t, error) {
+	rows, err := db.db.ClanMessagesFetch(db.ctx, sqlc.ClanMessagesFetchParams{
+		CtID:  int64(topicId),
+		Flags: CMFLAG_DELETE,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var list []*ClanMessage_t
+	for _, row := range rows {
+		list = append(list, clanMessageFromRow(row))
+	}
+	return list, nil
+}
+
+// ClanMessagesDelete marks every post in the topic as deleted.
+func (db *DB) ClanMessagesDelete(topicId int) error {
+	return db.db.ClanMessagesDelete(db.ctx, sqlc.ClanMessagesDeleteParams{
+		Flags: CMFLAG_DELETE,
+		CtID:  int64(topicId),
+	})
+}
+
+func clanMessageFromRow(row sqlc.ClanMessage) *ClanMessage_t {
+	return &ClanMessage_t{
+		Id:     int(row.CmID),
+		Topic:  int(row.CtID),
+		Empire: int(row.EID),
+		Body:   row.CmBody,
+		Time:   time.Unix(row.CmTime, 0).UTC(),
+		Flags:  int(row.CmFlags),
+	}
+}
+
+// ClanNameInUse returns true if a clan has ever used the name.
+// Names of disbanded clans are not released.
+func (db *DB) ClanNameInUse(name string) (bool, error) {
+	n, err := db.db.ClanNameCount(db.ctx, name)
+	return n > 0, err
+}
+
+// ClanNews returns the clan's news events since the given time, oldest first.
+func (db *DB) ClanNews(clanId int, since time.Time) ([]engine.ClanNews_t, error) {
+	rows, err := db.db.ClanNewsFetch(db.ctx, sqlc.ClanNewsFetchParams{
+		CID:    int64(clanId),
+		CnTime: since.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	var list []engine.ClanNews_t
+	for _, row := range rows {
+		list = append(list, engine.ClanNews_t{
+			Time:    time.Unix(row.CnTime, 0).UTC(),
+			Event:   int(row.CnEvent),
+			Clan:    int(row.CID),
+			Empire1: int(row.EID1),
+			Clan2:   int(row.CID2),
+			Empire2: int(row.EID2),
+		})
+	}
+	return list, nil
+}
+
+// ClanNewsCreate adds news events to the clan_news table.
+func (db *DB) ClanNewsCreate(now time.Time, news ...engine.ClanNews_t) error {
+	for _, n := range news {
+		err := db.db.ClanNewsCreate(db.ctx, sqlc.ClanNewsCreateParams{
+			CnTime:  now.Unix(),
+			CID:     int64(n.Clan),
+			EID1:    int64(n.Empire1),
+			CID2:    int64(n.Clan2),
+			EID2:    int64(n.Empire2),
+			CnEvent: int64(n.Event),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClanRelation_t is a relation between two clans.
+// The first clan started the relation; for a war, it is the clan that declared it.
+type ClanRelation_t struct {
+	Id    int
+	Clan1 int
+	Clan2 int
+	Flags int // CRFLAG_ALLY or CRFLAG_WAR, plus CRFLAG_MUTUAL
+	Time  time.Time
+}
+
+// Other returns the id of the clan on the other side of the relation.
+func (rel *ClanRelation_t) Other(clanId int) int {
+	if rel.Clan1 == clanId {
+		return rel.Clan2
+	}
+	return rel.Clan1
+}
+
+// ClanRelation returns the relation.
+func (db *DB) ClanRelation(relationId int) (*ClanRelation_t, error) {
+	row, err := db.db.ClanRelationFetch(db.ctx, int64(relationId))
+	if err != nil {
+		return nil, err
+	}
+	return clanRelationFromRow(row), nil
+}
+
+// ClanRelationCreate adds the relation to the clan_relation table and sets its id.
+func (db *DB) ClanRelationCreate(rel *ClanRelation_t) error {
+	id, err := db.db.ClanRelationCreate(db.ctx, sqlc.ClanRelationCreateParams{
+		CID1:    int64(rel.Clan1),
+		CID2:    int64(rel.Clan2),
+		CrFlags: int64(rel.Flags),
+		CrTime:  rel.Time.Unix(),
+	})
+	if err != nil {
+		return err
+	}
+	rel.Id = int(id)
+	return nil
+}
+
+// ClanRelationDelete removes the relation.
+func (db *DB) ClanRelationDelete(relationId int) error {
+	return db.db.ClanRelationDelete(db.ctx, int64(relationId))
+}
+
+// ClanRelationUpdate saves the relation.
+func (db *DB) ClanRelationUpdate(rel *ClanRelation_t) error {
+	return db.db.ClanRelationUpdate(db.ctx, sqlc.ClanRelationUpdateParams{
+		CID1:    int64(rel.Clan1),
+		CID2:    int64(rel.Clan2),
+		CrFlags: int64(rel.Flags),
+		CrTime:  rel.Time.Unix(),
+		CrID:    int64(rel.Id),
+	})
+}
+
+// ClanRelations returns the clan's relations whose flags, masked by check,
+// are exactly need, from prom_clan::listRelations. Direction is a combination
+// of RELATION_OUTBOUND and RELATION_INBOUND. Outbound relations are listed first.
+func (db *DB) ClanRelations(clanId, check, need, direction int) ([]*ClanRelation_t, error) {
+	rows, err := db.db.ClanRelationsFetch(db.ctx, int64(clanId))
+	if err != nil {
+		return nil, err
+	}
+	var outbound, inbound []*ClanRelation_t
+	for _, row := range rows {
+		if int(row.CrFlags)&check != need {
+			continue
+		}
+		if direction&RELATION_OUTBOUND != 0 && int(row.CID1) == clanId {
+			outbound = append(outbound, clanRelationFromRow(row))
+		} else if direction&RELATION_INBOUND != 0 && int(row.CID2) == clanId {
+			inbound = append(inbound, clanRelationFromRow(row))
+		}
+	}
+	return append(outbound, inbound...), nil
+}
+
+// ClanRelationsDelete removes every relation the clan has with other clans.
+func (db *DB) ClanRelationsDelete(clanId int) error {
+	return db.db.ClanRelationsDelete(db.ctx, int64(clanId))
+}
+
+func clanRelationFromRow(row sqlc.ClanRelation) *ClanRelation_t {
+	return &ClanRelation_t{
+		Id:    int(row.CrID),
+		Clan1: int(row.CID1),
+		Clan2: int(row.CID2),
+		Flags: int(row.CrFlags),
+		Time:  time.Unix(row.CrTime, 0).UTC(),
+	}
+}
+
+// ClanSharing returns the ids of the other members of the clan who share their
+// forces and may send reinforcements, from php/pages/military.php. Dead,
+// disabled, and deleted empires are left out.
+func (db *DB) ClanSharing(clanId, empireId int) ([]int, error) {
+	rows, err := db.db.ClanSharingFetch(db.ctx, sqlc.ClanSharingFetchParams{
+		EID:   int64(empireId),
+		CID:   int64(clanId),
+		Flags: EFLAG_DELETE | EFLAG_DISABLE,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var list []int
+	for _, row := range rows {
+		list = append(list, int(row))
+	}
+	return list, nil
+}
+
+// ClanTopic_t is a topic in the clan forum.
+type ClanTopic_t struct {
+	Id      int
+	Clan    int
+	Subject string
+	Flags   int // CTFLAG_DELETE, CTFLAG_LOCK, CTFLAG_NEWS, CTFLAG_STICKY
+}
+
+// ClanTopic returns the topic.
+func (db *DB) ClanTopic(topicId int) (*ClanTopic_t, error) {
+	row, err := db.db.ClanTopicFetch(db.ctx, int64(topicId))
+	if err != nil {
+		return nil, err
+	}
+	return clanTopicFromRow(row), nil
+}
+
+// ClanTopicCreate adds a topic to the clan forum and returns its id.
+func (db *DB) ClanTopicCreate(clanId int, subject string, flags int) (int, error) {
+	id, err := db.db.ClanTopicCreate(db.ctx, sqlc.ClanTopicCreateParams{
+		CID:       int64(clanId),
+		CtSubject: subject,
+		CtFlags:   int64(flags),
+	})
+	return int(id), err
+}
+
+// ClanTopicUpdate saves the subject and flags of the topic.
+func (db *DB) ClanTopicUpdate(topic *ClanTopic_t) error {
+	return db.db.ClanTopicUpdate(db.ctx, sqlc.ClanTopicUpdateParams{
+		CtSubject: topic.Subject,
+		CtFlags:   int64(topic.Flags),
+		CtID:      int64(topic.Id),
+	})
+}
+
+// ClanTopics returns the clan's topics that are not deleted, oldest first.
+func (db *DB) ClanTopics(clanId int) ([]*ClanTopic_t, error) {
+	rows, err := db.db.ClanTopicsFetch(db.ctx, sqlc.ClanTopicsFetchParams{
+		CID:   int64(clanId),
+		Flags: CTFLAG_DELETE,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var list []*ClanTopic_t
+	for _, row := range rows {
+		list = append(list, clanTopicFromRow(row))
+	}
+	return list, nil
+}
+
+func clanTopicFromRow(row sqlc.ClanTopic) *ClanTopic_t {
+	return &ClanTopic_t{
+		Id:      int(row.CtID),
+		Clan:    int(row.CID),
+		Subject: row.CtSubject,
+		Flags:   int(row.CtFlags),
+	}
+}
+
+// ClanUpdate saves the clan.
+func (db *DB) ClanUpdate(clan *model.Clan_t) error {
+	return db.db.ClanUpdate(db.ctx, sqlc.ClanUpdateParams{
+		CName:     clan.Name,
+		CPassword: clan.Password,
+		CMembers:  int64(clan.Members),
+		EIDLeader: int64(clan.Leader),
+		EIDAsst:   int64(clan.Assistant),
+		EIDFa1:    int64(clan.Minister1),
+		EIDFa2:    int64(clan.Minister2),
+		CTitle:    clan.Title,
+		CUrl:      clan.URL,
+		CPic:      clan.Pic,
+		CID:       int64(clan.Id),
+	})
+}
+
+// ClanWars returns the ids of the clans at war with the clan, from prom_clan::getWars.
+// Wars in either direction count while they are mutual; wars declared on the
+// clan also count while the clan that declared them has offered peace.
+func (db *DB) ClanWars(clanId int) ([]int, error) {
+	mutual, err := db.ClanRelations(clanId, CRFLAG_WAR|CRFLAG_MUTUAL, CRFLAG_WAR|CRFLAG_MUTUAL, RELATION_BOTH)
+	if err != nil {
+		return nil, err
+	}
+	offered, err := db.ClanRelations(clanId, CRFLAG_WAR|CRFLAG_MUTUAL, CRFLAG_WAR, RELATION_INBOUND)
+	if err != nil {
+		return nil, err
+	}
+	var wars []int
+	for _, rel := range append(mutual, offered...) {
+		wars = append(wars, rel.Other(clanId))
+	}
+	return wars, nil
+}
+
+// ClanWarsDeclared returns the number of wars the clan has declared and not
+// offered to end. Only these count towards the passive war tax.
+func (db *DB) ClanWarsDeclared(clanId int) (int, error) {
+	rels, err := db.ClanRelations(clanId, CRFLAG_WAR|CRFLAG_MUTUAL, CRFLAG_WAR|CRFLAG_MUTUAL, RELATION_OUTBOUND)
+	if err != nil {
+		return 0, err
+	}
+	return len(rels), nil
+}
+
+// Clans returns the clans that have not been disbanded.
+func (db *DB) Clans() ([]*model.Clan_t, error) {
+	rows, err := db.db.ClansFetch(db.ctx)
+	if err != nil {
+		return nil, err
+	}
+	var list []*model.Clan_t
+	for _, row := range rows {
+		list = append(list, clanFromRow(row))
+	}
+	return list, nil
+}
+
+// ClansEmpty returns the clans whose last member has left but which have
+// not been removed from the game yet.
+func (db *DB) ClansEmpty() ([]*model.Clan_t, error) {
+	rows, err := db.db.ClansEmptyFetch(db.ctx)
+	if err != nil {
+		return nil, err
+	}
+	var list []*model.Clan_t
+	for _, row := range rows {
+		list = append(list, clanFromRow(row))
+	}
+	return list, nil
+}
+
+func clanFromRow(row sqlc.Clan) *model.Clan_t {
+	return &model.Clan_t{
+		Id:        int(row.CID),
+		Name:      row.CName,
+		Password:  row.CPassword,
+		Members:   int(row.CMembers),
+		Leader:    int(row.EIDLeader),
+		Assistant: int(row.EIDAsst),
+		Minister1: int(row.EIDFa1),
+		Minister2: int(row.EIDFa2),
+		Title:     row.CTitle,
+		URL:       row.CUrl,
+		Pic:       row.CPic,
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"database/sql"
 	"fmt"
 	"github.com/google/uuid"
+	"github.com/mdhender/promisance/app/engine"
 	"github.com/mdhender/promisance/app/model"
 	"github.com/mdhender/promisance/app/orm/sqlc"
 	"log"
@@ -19,6 +20,11 @@ const (
 	CRFLAG_MUTUAL = 0x01 // Clan relation is mutual - set to complete an alliance, clear to stop a war
 	CRFLAG_WAR    = 0x04 // Clan relation describes a war
 
+	// Clan relation directions
+	RELATION_BOTH     = RELATION_OUTBOUND | RELATION_INBOUND
+	RELATION_INBOUND  = 0x02 // Relations other clans have started with the clan
+	RELATION_OUTBOUND = 0x01 // Relations the clan has started with other clans
+
 	// Clan forum thread flags
 	CTFLAG_DELETE = 0x08 // Topic has been deleted
 	CTFLAG_LOCK   = 0x04 // Topic has been locked - normal members may not post
@@ -64,16 +70,6 @@ const (
 	HEFLAG_ADMIN   = EFLAG_ADMIN // Empire was owned by a moderator/administrator
 	HEFLAG_PROTECT = 0x01        // Empire was protected, whether vacation or newly registered
 
-	RACE_DROW    = 8
-	RACE_DWARF   = 3
-	RACE_ELF     = 2
-	RACE_GNOME   = 5
-	RACE_GOBLIN  = 9
-	RACE_GREMLIN = 6
-	RACE_HUMAN   = 1
-	RACE_ORC     = 7
-	RACE_TROLL   = 4
-
 	// User flags
 	UFLAG_ADMIN   = 0x02 // User has Administrator privileges (can grant/revoke privileges, delete/rename empires, login as anyone, edit clans)
 	UFLAG_CLOSED  = 0x10 // User account has been voluntarily closed, cannot create new empires or login to existing ones
@@ -117,16 +113,34 @@ func (db *DB) EmpireCreate(user *model.User_t, name string, race string) (*model
 	var raceFlag int64
 	switch race {
 	case "HUMAN":
-		raceFlag = RACE_HUMAN
+		raceFlag = engine.RACE_HUMAN
+	case "ELF":
+		raceFlag = engine.RACE_ELF
+	case "DWARF":
+		raceFlag = engine.RACE_DWARF
+	case "TROLL":
+		raceFlag = engine.RACE_TROLL
+	case "GNOME":
+		raceFlag = engine.RACE_GNOME
+	case "GREMLIN":
+		raceFlag = engine.RACE_GREMLIN
+	case "ORC":
+		raceFlag = engine.RACE_ORC
+	case "DROW":
+		raceFlag = engine.RACE_DROW
+	case "GOBLIN":
+		raceFlag = engine.RACE_GOBLIN
 	default:
 		return nil, fmt.Errorf("unknown race: %s", race)
 	}
 
+	now := time.Now()
 	var empire model.Empire_t
 	if id, err := db.db.EmpireCreate(db.ctx, sqlc.EmpireCreateParams{
 		UID:   int64(user.Id),
 		EName: name,
 		ERace: raceFlag,
+		EIdle: sql.NullInt64{Valid: true, Int64: now.Unix()},
 	}); err != nil {
 		return nil, err
 	} else {
@@ -134,6 +148,7 @@ func (db *DB) EmpireCreate(user *model.User_t, name string, race string) (*model
 		empire.UserId = user.Id
 		empire.Name = name
 		empire.Race = int(raceFlag)
+		empire.Idle = int(now.Unix())
 	}
 
 	return &empire, nil
@@ -149,6 +164,8 @@ func (db *DB) EmpireAttributesUpdate(empire *model.Empire_t) error {
 		EIdle:        sql.NullInt64{Valid: true, Int64: int64(empire.Idle)},
 		EEra:         sql.NullInt64{Valid: true, Int64: int64(empire.Era)},
 		ERank:        sql.NullInt64{Valid: true, Int64: int64(empire.Rank)},
+		CID:          sql.NullInt64{Valid: true, Int64: int64(empire.CId)},
+		COldid:       sql.NullInt64{Valid: true, Int64: int64(empire.OldCId)},
 		ESharing:     sql.NullInt64{Valid: true, Int64: int64(empire.Sharing)},
 		EAttacks:     sql.NullInt64{Valid: true, Int64: int64(empire.Attacks)},
 		EOffsucc:     sql.NullInt64{Valid: true, Int64: int64(empire.OffSucc)},
@@ -275,6 +292,38 @@ func (db *DB) EmpireFetch(id int) (*model.Empire_t, error) {
 	}, nil
 }
 
+// EmpireIdleUpdate records that the empire was active at the given time.
+func (db *DB) EmpireIdleUpdate(empire *model.Empire_t, now time.Time) error {
+	if err := db.db.EmpireIdleUpdate(db.ctx, sqlc.EmpireIdleUpdateParams{
+		Idle: now.Unix(),
+		EID:  int64(empire.Id),
+	}); err != nil {
+		return err
+	}
+	empire.Idle = int(now.Unix())
+	return nil
+}
+
+// EmpireUnlink unlinks the empire from its user. Messages sent to the empire are deleted,
+// messages it sent are flagged as being from a dead empire, and its market items and
+// lottery tickets are removed.
+func (db *DB) EmpireUnlink(empire *model.Empire_t) error {
+	id := int64(empire.Id)
+	if err := db.db.EmpireMessagesFlagReceived(db.ctx, sqlc.EmpireMessagesFlagReceivedParams{Flags: MFLAG_DELETE, EID: id}); err != nil {
+		return err
+	} else if err := db.db.EmpireMessagesFlagSent(db.ctx, sqlc.EmpireMessagesFlagSentParams{Flags: MFLAG_DEAD, EID: id}); err != nil {
+		return err
+	} else if err := db.db.MarketEmpireItemsDelete(db.ctx, id); err != nil {
+		return err
+	} else if err := db.db.LotteryEmpireTicketsDelete(db.ctx, id); err != nil {
+		return err
+	} else if err := db.db.EmpireUnlink(db.ctx, id); err != nil {
+		return err
+	}
+	empire.OldUserId, empire.UserId = empire.UserId, 0
+	return nil
+}
+
 func (db *DB) EmpireUpdateFlags(empire *model.Empire_t) error {
 	return db.db.EmpireUpdateFlags(db.ctx, sqlc.EmpireUpdateFlagsParams{
 		EFlags: empireFlagsToInt(empire.Flags),
@@ -0,0 +1,66 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package orm
+
+import (
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/orm/sqlc"
+	"time"
+)
+
+// EmpireEffectDelete removes an effect from an empire.
+func (db *DB) EmpireEffectDelete(empireId int, name string) error {
+	return db.db.EmpireEffectDelete(db.ctx, sqlc.EmpireEffectDeleteParams{
+		EID:    int64(empireId),
+		EfName: name,
+	})
+}
+
+// EmpireEffectsCleanup removes expired timed effects, used up turn-based
+// effects, and cleared permanent effects from all empires.
+// It returns the number of effects removed.
+func (db *DB) EmpireEffectsCleanup(now time.Time) (int, error) {
+	timed, err := db.db.EmpireEffectsCleanupTime(db.ctx, now.Unix())
+	if err != nil {
+		return 0, err
+	}
+	turns, err := db.db.EmpireEffectsCleanupTurn(db.ctx)
+	if err != nil {
+		return 0, err
+	}
+	perms, err := db.db.EmpireEffectsCleanupPerm(db.ctx)
+	if err != nil {
+		return 0, err
+	}
+	return int(timed + turns + perms), nil
+}
+
+// EmpireEffectsFetch loads the effects for an empire.
+// The modifiers are granted to the empire while each effect is active.
+func (db *DB) EmpireEffectsFetch(empireId int, mods map[string]engine.Modifiers_t) (*engine.Effects_t, error) {
+	rows, err := db.db.EmpireEffectsFetch(db.ctx, int64(empireId))
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]int{}
+	for _, row := range rows {
+		values[row.EfName] = int(row.EfValue)
+	}
+	return engine.NewEffects(empireId, values, mods), nil
+}
+
+// EmpireEffectsSave writes the changed effects for an empire to the database.
+func (db *DB) EmpireEffectsSave(fx *engine.Effects_t) error {
+	for name, value := range fx.Changed() {
+		err := db.db.EmpireEffectUpsert(db.ctx, sqlc.EmpireEffectUpsertParams{
+			EID:     int64(fx.EmpireId),
+			EfName:  name,
+			EfValue: int64(value),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	fx.Saved()
+	return nil
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package orm
+
+import (
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/orm/sqlc"
+	"time"
+)
+
+// EmpireCount returns the number of empires in the game.
+func (db *DB) EmpireCount() (int, error) {
+	n, err := db.db.EmpireCount(db.ctx)
+	return int(n), err
+}
+
+// LotteryClear removes every lottery ticket.
+func (db *DB) LotteryClear() error {
+	return db.db.LotteryClear(db.ctx)
+}
+
+// LotteryDrawCreate records the result of a lottery drawing and sets its id.
+func (db *DB) LotteryDrawCreate(draw *model.LotteryDraw_t) error {
+	err := db.db.LotteryDrawCreate(db.ctx, sqlc.LotteryDrawCreateParams{
+		LdTime:     draw.Time.Unix(),
+		LdTicket:   int64(draw.Ticket),
+		LdTickets:  int64(draw.Tickets),
+		LdJackpot:  int64(draw.Jackpot),
+		LdIncrease: int64(draw.Increase),
+		EID:        int64(draw.EmpireId),
+		EName:      draw.EmpireName,
+		LdWon:      int64(draw.Won),
+	})
+	return err
+}
+
+// LotteryDraws returns the most recent lottery drawings, newest first.
+func (db *DB) LotteryDraws(limit int) ([]*model.LotteryDraw_t, error) {
+	rows, err := db.db.LotteryDraws(db.ctx, int64(limit))
+	if err != nil {
+		return nil, err
+	}
+	var draws []*model.LotteryDraw_t
+	for _, row := range rows {
+		draws = append(draws, &model.LotteryDraw_t{
+			Id:         int(row.LdID),
+			Time:       time.Unix(row.LdTime, 0).UTC(),
+			Ticket:     int(row.LdTicket),
+			Tickets:    int(row.LdTickets),
+			Jackpot:    int(row.LdJackpot),
+			Increase:   int(row.LdIncrease),
+			EmpireId:   int(row.EID),
+			EmpireName: row.EName,
+			Won:        int(row.LdWon),
+		})
+	}
+	return draws, nil
+}
+
+// LotteryEmpireTickets returns the numbers of the tickets held by the empire.
+func (db *DB) LotteryEmpireTickets(empireId int) ([]int, error) {
+	rows, err := db.db.LotteryEmpireTickets(db.ctx, int64(empireId))
+	if err != nil {
+		return nil, err
+	}
+	var tickets []int
+	for _, row := range rows {
+		tickets = append(tickets, int(row))
+	}
+	return tickets, nil
+}
+
+// LotteryTicketCreate records a ticket bought by the empire.
+func (db *DB) LotteryTicketCreate(empireId, ticket, cost int) error {
+	return db.db.LotteryTicketCreate(db.ctx, sqlc.LotteryTicketCreateParams{EID: int64(empireId), LTicket: int64(ticket), LCash: int64(cost)})
+}
+
+// LotteryTicketHolder returns the empire holding the ticket, or zero if no one holds it.
+func (db *DB) LotteryTicketHolder(ticket int) (int, error) {
+	id, err := db.db.LotteryTicketHolder(db.ctx, int64(ticket))
+	return int(id), err
+}
+
+// LotteryTicketsSold returns the number of tickets bought for the next drawing.
+func (db *DB) LotteryTicketsSold() (int, error) {
+	n, err := db.db.LotteryTicketsSold(db.ctx)
+	return int(n), err
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package orm
+
+import (
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/orm/sqlc"
+	"time"
+)
+
+// MarketPrice_t is the number of goods available on the public market at a single price.
+type MarketPrice_t struct {
+	Type   int
+	Price  int
+	Amount int
+}
+
+// MarketExpiredDelete removes every shipment that arrived on the market at or before the cutoff.
+// It returns the number of shipments removed.
+func (db *DB) MarketExpiredDelete(cutoff time.Time) (int, error) {
+	n, err := db.db.MarketExpiredDelete(db.ctx, cutoff.Unix())
+	return int(n), err
+}
+
+// MarketExpiredFetch returns every shipment that arrived on the market at or before the cutoff.
+func (db *DB) MarketExpiredFetch(cutoff time.Time) ([]*model.MarketItem_t, error) {
+	rows, err := db.db.MarketExpiredFetch(db.ctx, cutoff.Unix())
+	if err != nil {
+		return nil, err
+	}
+	return marketItems(rows), nil
+}
+
+// MarketEmpireItems returns the shipments an empire has on the market or on the way to it.
+func (db *DB) MarketEmpireItems(empireId int) ([]*model.MarketItem_t, error) {
+	rows, err := db.db.MarketEmpireItemsFetch(db.ctx, int64(empireId))
+	if err != nil {
+		return nil, err
+	}
+	return marketItems(rows), nil
+}
+
+// MarketItemCreate adds a shipment to the market and sets its id.
+func (db *DB) MarketItemCreate(item *model.MarketItem_t) error {
+	id, err := db.db.MarketItemCreate(db.ctx, sqlc.MarketItemCreateParams{
+		KType:  int64(item.Type),
+		EID:    int64(item.EmpireId),
+		KAmt:   int64(item.Amount),
+		KPrice: int64(item.Price),
+		KTime:  item.Time.Unix(),
+	})
+	if err != nil {
+		return err
+	}
+	item.Id = int(id)
+	return nil
+}
+
+// MarketItemDelete removes a shipment from the market.
+func (db *DB) MarketItemDelete(id int) error {
+	return db.db.MarketItemDelete(db.ctx, int64(id))
+}
+
+// MarketItemFetch returns a shipment owned by the empire, or nil if there is no such shipment.
+func (db *DB) MarketItemFetch(id, empireId int) (*model.MarketItem_t, error) {
+	rows, err := db.db.MarketItemFetch(db.ctx, sqlc.MarketItemFetchParams{KID: int64(id), EID: int64(empireId)})
+	if err != nil {
+		return nil, err
+	} else if len(rows) == 0 {
+		return nil, nil
+	}
+	return marketItems(rows)[0], nil
+}
+
+// MarketItemUpdateAmount sets the number of goods left in a shipment.
+func (db *DB) MarketItemUpdateAmount(id, amount int) error {
+	return db.db.MarketItemAmountUpdate(db.ctx, sqlc.MarketItemAmountUpdateParams{KAmt: int64(amount), KID: int64(id)})
+}
+
+// MarketItemsForSale returns the shipments of goods that have arrived on the
+// market at or below the price, excluding the buyer's own shipments.
+// The shipments are ordered from the lowest price to the highest, then from oldest to newest.
+func (db *DB) MarketItemsForSale(kind, price, buyerId int, now time.Time) ([]*model.MarketItem_t, error) {
+	rows, err := db.db.MarketItemsForSale(db.ctx, sqlc.MarketItemsForSaleParams{
+		KType:  int64(kind),
+		KPrice: int64(price),
+		EID:    int64(buyerId),
+		KTime:  now.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return marketItems(rows), nil
+}
+
+// MarketPrices returns the lowest price and the number of goods available at
+// that price for each type of goods that has arrived on the market,
+// excluding the buyer's own shipments.
+func (db *DB) MarketPrices(buyerId int, now time.Time) (map[int]MarketPrice_t, error) {
+	rows, err := db.db.MarketPricesFetch(db.ctx, sqlc.MarketPricesFetchParams{EID: int64(buyerId), KTime: now.Unix()})
+	if err != nil {
+		return nil, err
+	}
+	prices := map[int]MarketPrice_t{}
+	for _, row := range rows {
+		// rows are ordered by price, so the first for each type is the lowest
+		if _, ok := prices[int(row.KType)]; !ok {
+			prices[int(row.KType)] = MarketPrice_t{Type: int(row.KType), Price: int(row.KPrice), Amount: int(row.KAmt)}
+		}
+	}
+	return prices, nil
+}
+
+func marketItems(rows []sqlc.Market) []*model.MarketItem_t {
+	var items []*model.MarketItem_t
+	for _, row := range rows {
+		items = append(items, &model.MarketItem_t{
+			Id:       int(row.KID),
+			Type:     int(row.KType),
+			EmpireId: int(row.EID),
+			Amount:   int(row.KAmt),
+			Price:    int(row.KPrice),
+			Time:     time.Unix(row.KTime, 0).UTC(),
+		})
+	}
+	return items
+}
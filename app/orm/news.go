@@ -0,0 +1,76 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package orm
+
+import (
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/orm/sqlc"
+	"time"
+)
+
+// EmpireNewsAttachmentsGotten marks the locked news events for an empire as received and unlocks them.
+func (db *DB) EmpireNewsAttachmentsGotten(empireId int) error {
+	return db.db.EmpireNewsLockedGotten(db.ctx, sqlc.EmpireNewsLockedGottenParams{
+		Gotten: NFLAG_GOTTEN,
+		Lock:   NFLAG_LOCK,
+		EID:    int64(empireId),
+	})
+}
+
+// EmpireNewsAttachmentsLock locks the news events for an empire that have
+// goods attached which have not been received, and returns them, oldest first.
+func (db *DB) EmpireNewsAttachmentsLock(empireId int) ([]engine.News_t, error) {
+	err := db.db.EmpireNewsAttachmentsLock(db.ctx, sqlc.EmpireNewsAttachmentsLockParams{
+		Lock:   NFLAG_LOCK,
+		EID:    int64(empireId),
+		Gotten: NFLAG_GOTTEN,
+		First:  engine.EMPNEWS_ATTACH_FIRST,
+		Last:   engine.EMPNEWS_ATTACH_LAST,
+	})
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.db.EmpireNewsLockedFetch(db.ctx, sqlc.EmpireNewsLockedFetchParams{EID: int64(empireId), Lock: NFLAG_LOCK})
+	if err != nil {
+		return nil, err
+	}
+	var news []engine.News_t
+	for _, row := range rows {
+		news = append(news, engine.News_t{
+			Event:      int(row.NEvent),
+			Source:     int(row.EIDSrc),
+			SourceClan: int(row.CIDSrc),
+			Target:     int(row.EIDDst),
+			TargetClan: int(row.CIDDst),
+			Data:       [9]int{int(row.ND0), int(row.ND1), int(row.ND2), int(row.ND3), int(row.ND4), int(row.ND5), int(row.ND6), int(row.ND7), int(row.ND8)},
+		})
+	}
+	return news, nil
+}
+
+// EmpireNewsCreate adds news events to the empire_news table.
+func (db *DB) EmpireNewsCreate(now time.Time, news ...engine.News_t) error {
+	for _, n := range news {
+		err := db.db.EmpireNewsCreate(db.ctx, sqlc.EmpireNewsCreateParams{
+			NTime:  now.Unix(),
+			EIDSrc: int64(n.Source),
+			CIDSrc: int64(n.SourceClan),
+			EIDDst: int64(n.Target),
+			CIDDst: int64(n.TargetClan),
+			NEvent: int64(n.Event),
+			ND0:    int64(n.Data[0]),
+			ND1:    int64(n.Data[1]),
+			ND2:    int64(n.Data[2]),
+			ND3:    int64(n.Data[3]),
+			ND4:    int64(n.Data[4]),
+			ND5:    int64(n.Data[5]),
+			ND6:    int64(n.Data[6]),
+			ND7:    int64(n.Data[7]),
+			ND8:    int64(n.Data[8]),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package orm
+
+import (
+	"database/sql"
+	"github.com/mdhender/promisance/app/orm/sqlc"
+)
+
+// UserRanking_t is the history summary for a user account.
+type UserRanking_t struct {
+	UserId   int
+	NumPlays int
+	AvgRank  float64
+	BestRank float64
+	SucPlays int
+}
+
+// EmpireNetworthUpdate sets the networth of an empire.
+func (db *DB) EmpireNetworthUpdate(id, networth int) error {
+	return db.db.EmpireNetworthUpdate(db.ctx, sqlc.EmpireNetworthUpdateParams{
+		ENetworth: sql.NullInt64{Valid: true, Int64: int64(networth)},
+		EID:       int64(id),
+	})
+}
+
+// EmpirePlayerIds returns the ids of all empires that are linked to a user.
+func (db *DB) EmpirePlayerIds() ([]int, error) {
+	rows, err := db.db.EmpirePlayerIdsFetch(db.ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ids []int
+	for _, id := range rows {
+		ids = append(ids, int(id))
+	}
+	return ids, nil
+}
+
+// EmpiresUpdateRanks ranks every empire that is linked to a user.
+// Empires are ranked by networth, or by score and then networth if the
+// HRFLAG_SCORE flag is set. Unlinked empires are given a rank of zero.
+// It returns the number of empires ranked.
+func (db *DB) EmpiresUpdateRanks(flags int) (int, error) {
+	if err := db.db.EmpiresClearUnownedRank(db.ctx); err != nil {
+		return 0, err
+	}
+	var ids []int64
+	var err error
+	if flags&HRFLAG_SCORE != 0 {
+		ids, err = db.db.EmpiresRankByScore(db.ctx)
+	} else {
+		ids, err = db.db.EmpiresRankByNetworth(db.ctx)
+	}
+	if err != nil {
+		return 0, err
+	}
+	for n, id := range ids {
+		err := db.db.EmpireRankUpdate(db.ctx, sqlc.EmpireRankUpdateParams{
+			ERank: sql.NullInt64{Valid: true, Int64: int64(n + 1)},
+			EID:   id,
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(ids), nil
+}
+
+// HistoryEmpireMaxRanks returns the lowest rank recorded for each round in the history.
+func (db *DB) HistoryEmpireMaxRanks() (map[int]int, error) {
+	rows, err := db.db.HistoryEmpireMaxRanks(db.ctx)
+	if err != nil {
+		return nil, err
+	}
+	ranks := map[int]int{}
+	for _, row := range rows {
+		ranks[int(row.HrID)] = int(row.Maxrank)
+	}
+	return ranks, nil
+}
+
+// HistoryEmpireUserRanks returns the best rank achieved by a user's empires in each round in the history.
+func (db *DB) HistoryEmpireUserRanks(userId int) (map[int]int, error) {
+	rows, err := db.db.HistoryEmpireUserRanks(db.ctx, int64(userId))
+	if err != nil {
+		return nil, err
+	}
+	ranks := map[int]int{}
+	for _, row := range rows {
+		ranks[int(row.HrID)] = int(row.Myrank)
+	}
+	return ranks, nil
+}
+
+// UserRankingsFetch returns the history summary for every user account.
+func (db *DB) UserRankingsFetch() ([]UserRanking_t, error) {
+	rows, err := db.db.UsersRankingsFetch(db.ctx)
+	if err != nil {
+		return nil, err
+	}
+	var users []UserRanking_t
+	for _, row := range rows {
+		users = append(users, UserRanking_t{
+			UserId:   int(row.UID),
+			NumPlays: nvlInt(row.UNumplays),
+			AvgRank:  nvlFloat(row.UAvgrank),
+			BestRank: nvlFloat(row.UBestrank),
+			SucPlays: nvlInt(row.USucplays),
+		})
+	}
+	return users, nil
+}
+
+// UserRankingsUpdate saves the ranking fields of a user's history summary.
+func (db *DB) UserRankingsUpdate(user UserRanking_t) error {
+	return db.db.UserRankingsUpdate(db.ctx, sqlc.UserRankingsUpdateParams{
+		UAvgrank:  sql.NullFloat64{Valid: true, Float64: user.AvgRank},
+		UBestrank: sql.NullFloat64{Valid: true, Float64: user.BestRank},
+		USucplays: sql.NullInt64{Valid: true, Int64: int64(user.SucPlays)},
+		UID:       int64(user.UserId),
+	})
+}
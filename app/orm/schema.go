@@ -38,6 +38,32 @@ func (db *DB) Close() error {
 	return err
 }
 
+// Transaction runs fn inside a database transaction.
+// The DB passed to fn must be used for all queries that are part of the transaction.
+// If fn returns an error, the transaction is rolled back and the error is returned.
+// Calling Transaction from inside fn runs the nested fn in the existing transaction.
+func (db *DB) Transaction(fn func(tx *DB) error) error {
+	if db.dbSqlite == nil {
+		// already in a transaction
+		return fn(db)
+	}
+	sqlTx, err := db.dbSqlite.BeginTx(db.ctx, nil)
+	if err != nil {
+		return err
+	}
+	tx := &DB{
+		ctx: db.ctx,
+		db:  db.db.WithTx(sqlTx),
+	}
+	if err := fn(tx); err != nil {
+		if rbErr := sqlTx.Rollback(); rbErr != nil {
+			log.Printf("orm: transaction: rollback: %v\n", rbErr)
+		}
+		return err
+	}
+	return sqlTx.Commit()
+}
+
 // CreateSqliteDatabase creates the SQLite database and initializes it.
 // It returns an error if the database already exists.
 func CreateSqliteDatabase(dbName string) (*DB, error) {
@@ -80,6 +106,41 @@ func CreateSqliteDatabase(dbName string) (*DB, error) {
 	return db, nil
 }
 
+// CreateMemoryDatabase creates an in-memory SQLite database and initializes it.
+// The database is discarded when it is closed.
+func CreateMemoryDatabase() (*DB, error) {
+	dbSqlite, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		return nil, err
+	}
+	// every connection to ":memory:" gets its own database, so we must only ever use one
+	dbSqlite.SetMaxOpenConns(1)
+	db := &DB{
+		ctx:      context.Background(),
+		db:       sqlc.New(dbSqlite),
+		dbSqlite: dbSqlite,
+	}
+
+	// confirm that the database has foreign keys enabled
+	var rslt sql.Result
+	checkPragma := "PRAGMA" + " foreign_keys = ON"
+	if rslt, err = dbSqlite.Exec(checkPragma); err != nil {
+		log.Printf("orm: error: foreign keys are disabled\n")
+		return nil, cerr.ErrForeignKeysDisabled
+	} else if rslt == nil {
+		log.Printf("orm: error: foreign keys pragma failed\n")
+		return nil, cerr.ErrPragmaReturnedNil
+	}
+
+	// create the schema
+	if _, err = dbSqlite.Exec(ddlScript); err != nil {
+		log.Printf("orm: failed to create database schema\n")
+		return nil, errors.Join(cerr.ErrCreateSchema, err)
+	}
+
+	return db, nil
+}
+
 // OpenSqliteDatabase opens a SQLite database.
 // It returns an error if the database does not exist.
 func OpenSqliteDatabase(dbName string) (*DB, error) {
@@ -108,9 +169,96 @@ func OpenSqliteDatabase(dbName string) (*DB, error) {
 		return nil, cerr.ErrPragmaReturnedNil
 	}
 
-	// todo: this should be the auto-migrate logic
-	log.Printf("orm: todo: migrate database\n")
+	// bring databases created by older versions up to date
+	if err := migrate(dbSqlite); err != nil {
+		log.Printf("orm: failed to migrate database\n")
+		return nil, errors.Join(cerr.ErrCreateSchema, err)
+	}
 
 	log.Printf("orm: opened database %s", dbName)
 	return db, nil
 }
+
+// migrate updates the schema of a database created by an older version.
+// Each step checks whether it is needed, so it is safe to run on every open.
+func migrate(dbSqlite *sql.DB) error {
+	// turnlog.turn_updates holds the TURNS_NEED_* flags for each entry
+	if ok, err := hasColumn(dbSqlite, "turnlog", "turn_updates"); err != nil {
+		return err
+	} else if !ok {
+		log.Printf("orm: migrate: adding turnlog.turn_updates\n")
+		if _, err := dbSqlite.Exec(`ALTER TABLE turnlog ADD COLUMN turn_updates INTEGER NOT NULL DEFAULT 0`); err != nil {
+			return err
+		}
+	}
+	if _, err := dbSqlite.Exec(`CREATE INDEX IF NOT EXISTS turnlog_turn_time ON turnlog (turn_time)`); err != nil {
+		return err
+	}
+
+	// var_adjust.va_id orders the queued adjustments. sqlite can't add a primary
+	// key to an existing table, so the table is rebuilt, keeping the queue order.
+	if ok, err := hasColumn(dbSqlite, "var_adjust", "va_id"); err != nil {
+		return err
+	} else if !ok {
+		log.Printf("orm: migrate: rebuilding var_adjust with va_id\n")
+		if err := execTx(dbSqlite,
+			`CREATE TABLE var_adjust_new
+			(
+				va_id    INTEGER PRIMARY KEY,
+				v_name   TEXT    NOT NULL DEFAULT '',
+				v_offset INTEGER NOT NULL DEFAULT 0
+			)`,
+			`INSERT INTO var_adjust_new (v_name, v_offset) SELECT v_name, v_offset FROM var_adjust ORDER BY rowid`,
+			`DROP TABLE var_adjust`,
+			`ALTER TABLE var_adjust_new RENAME TO var_adjust`,
+		); err != nil {
+			return err
+		}
+	}
+
+	// lottery_draw records the results of the daily lottery drawings
+	if err := execTx(dbSqlite,
+		`CREATE TABLE IF NOT EXISTS lottery_draw
+		(
+			ld_id       INTEGER PRIMARY KEY,
+			ld_time     INTEGER NOT NULL DEFAULT 0,
+			ld_ticket   INTEGER NOT NULL DEFAULT 0,
+			ld_tickets  INTEGER NOT NULL DEFAULT 0,
+			ld_jackpot  INTEGER NOT NULL DEFAULT 0,
+			ld_increase INTEGER NOT NULL DEFAULT 0,
+			e_id        INTEGER NOT NULL DEFAULT 0,
+			e_name      TEXT    NOT NULL DEFAULT '',
+			ld_won      INTEGER NOT NULL DEFAULT 0
+		)`,
+		`CREATE INDEX IF NOT EXISTS lottery_draw_ld_time ON lottery_draw (ld_time)`,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// execTx runs the statements in a single transaction.
+func execTx(dbSqlite *sql.DB, stmts ...string) error {
+	tx, err := dbSqlite.Begin()
+	if err != nil {
+		return err
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// hasColumn returns true if the table has the column.
+func hasColumn(dbSqlite *sql.DB, table, column string) (bool, error) {
+	var n int
+	err := dbSqlite.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, table, column).Scan(&n)
+	if err != nil {
+		return false, err
+	}
+	return n != 0, nil
+}
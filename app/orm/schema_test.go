@@ -0,0 +1,144 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package orm
+
+import (
+	"github.com/mdhender/promisance/app/model"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMigrateTurnlog(t *testing.T) {
+	dbName := filepath.Join(t.TempDir(), "promisance.sqlite")
+	db, err := CreateSqliteDatabase(dbName)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	// databases created before the turn log recorded updates don't have the column
+	for _, stmt := range []string{
+		`DROP INDEX turnlog_turn_time`,
+		`ALTER TABLE turnlog DROP COLUMN turn_updates`,
+		`INSERT INTO turnlog (turn_time, turn_type, turn_text) VALUES (1711929600, 1, 'old entry')`,
+	} {
+		if _, err := db.dbSqlite.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	_ = db.Close()
+
+	db, err = OpenSqliteDatabase(dbName)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+	now := time.Date(2024, 4, 1, 0, 10, 0, 0, time.UTC)
+	if err := db.TurnlogInsert(&model.TurnLog_t{Time: now, Type: 1, Updates: 3, Text: "new entry"}); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	entries, err := db.TurnlogFetch(-1, 0, time.Time{}, time.Time{}, 10)
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	} else if len(entries) != 2 || entries[0].Updates != 3 || entries[1].Updates != 0 || entries[1].Text != "old entry" {
+		t.Fatalf("fetch: got %+v %+v", entries[0], entries[1])
+	}
+
+	// migrating again changes nothing
+	if err := migrate(db.dbSqlite); err != nil {
+		t.Errorf("migrate: %v", err)
+	}
+}
+
+func TestMigrateVarAdjust(t *testing.T) {
+	dbName := filepath.Join(t.TempDir(), "promisance.sqlite")
+	db, err := CreateSqliteDatabase(dbName)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := db.WorldVarsInitialize(&model.World_t{LottoCurrentJackpot: 1000}); err != nil {
+		t.Fatalf("world: %v", err)
+	}
+	// databases created before adjustments were ordered don't have va_id
+	for _, stmt := range []string{
+		`DROP TABLE var_adjust`,
+		`CREATE TABLE var_adjust (v_name TEXT NOT NULL DEFAULT '', v_offset INTEGER NOT NULL DEFAULT 0)`,
+		`INSERT INTO var_adjust (v_name, v_offset) VALUES ('lotto_current_jackpot', 50), ('lotto_jackpot_increase', 25)`,
+	} {
+		if _, err := db.dbSqlite.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	_ = db.Close()
+
+	db, err = OpenSqliteDatabase(dbName)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+	// the queued adjustments survive the migration
+	if err := db.WorldVarsAdjust("lotto_current_jackpot", 100); err != nil {
+		t.Fatalf("adjust: %v", err)
+	}
+	world, err := db.WorldVarsFetch()
+	if err != nil {
+		t.Fatalf("world: %v", err)
+	}
+	if n, err := db.WorldVarsApplyAdjustments(world); err != nil {
+		t.Fatalf("apply: %v", err)
+	} else if n != 3 || world.LottoCurrentJackpot != 1150 || world.LottoJackpotIncrease != 25 {
+		t.Errorf("apply: want 3 adjustments, got %d: jackpot %d increase %d", n, world.LottoCurrentJackpot, world.LottoJackpotIncrease)
+	}
+	if n, err := db.WorldVarsApplyAdjustments(world); err != nil || n != 0 {
+		t.Errorf("apply again: want 0, got %d %v", n, err)
+	}
+
+	// migrating again changes nothing
+	if err := migrate(db.dbSqlite); err != nil {
+		t.Errorf("migrate: %v", err)
+	}
+}
+
+func TestMigrateLotteryDraw(t *testing.T) {
+	dbName := filepath.Join(t.TempDir(), "promisance.sqlite")
+	db, err := CreateSqliteDatabase(dbName)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	// databases created before the drawings were recorded don't have the table
+	if _, err := db.dbSqlite.Exec(`DROP TABLE lottery_draw`); err != nil {
+		t.Fatalf("drop: %v", err)
+	}
+	_ = db.Close()
+
+	db, err = OpenSqliteDatabase(dbName)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	if err := db.LotteryDrawCreate(&model.LotteryDraw_t{Time: now, Ticket: 7, Tickets: 12, Jackpot: 1_000_000}); err != nil {
+		t.Fatalf("draw: %v", err)
+	}
+	if draws, err := db.LotteryDraws(10); err != nil {
+		t.Fatalf("draws: %v", err)
+	} else if len(draws) != 1 || draws[0].Ticket != 7 || !draws[0].Time.Equal(now) {
+		t.Errorf("draws: got %+v", draws)
+	}
+	var indexes int
+	if err := db.dbSqlite.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name = 'lottery_draw_ld_time'`).Scan(&indexes); err != nil {
+		t.Fatalf("index: %v", err)
+	} else if indexes != 1 {
+		t.Errorf("index: want 1, got %d", indexes)
+	}
+
+	// migrating again changes nothing
+	if err := migrate(db.dbSqlite); err != nil {
+		t.Errorf("migrate: %v", err)
+	}
+}
@@ -73,6 +73,33 @@ func (q *Queries) AuthenticatedUserFetch(ctx context.Context, arg AuthenticatedU
 	return i, err
 }
 
+const clanCreate = `-- name: ClanCreate :one
+INSERT INTO clan (c_name, c_password, c_members, e_id_leader, c_title, c_url, c_pic)
+VALUES (?, ?, ?, ?, ?, '', '')
+RETURNING c_id
+`
+
+type ClanCreateParams struct {
+	CName     string
+	CPassword string
+	CMembers  int64
+	EIDLeader int64
+	CTitle    string
+}
+
+func (q *Queries) ClanCreate(ctx context.Context, arg ClanCreateParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, clanCreate,
+		arg.CName,
+		arg.CPassword,
+		arg.CMembers,
+		arg.EIDLeader,
+		arg.CTitle,
+	)
+	var c_id int64
+	err := row.Scan(&c_id)
+	return c_id, err
+}
+
 const clanFetch = `-- name: ClanFetch :one
 SELECT c_id,
        c_name,
@@ -108,259 +135,1356 @@ func (q *Queries) ClanFetch(ctx context.Context, cID int64) (Clan, error) {
 	return i, err
 }
 
-const empireActiveUserCount = `-- name: EmpireActiveUserCount :one
-SELECT COUNT(*)
-FROM empire
-WHERE u_id != 0
+const clanInviteCreate = `-- name: ClanInviteCreate :one
+INSERT INTO clan_invite (c_id, e_id_1, e_id_2, ci_flags, ci_time)
+VALUES (?, ?, ?, ?, ?)
+RETURNING ci_id
 `
 
-func (q *Queries) EmpireActiveUserCount(ctx context.Context) (int64, error) {
-	row := q.db.QueryRowContext(ctx, empireActiveUserCount)
-	var count int64
-	err := row.Scan(&count)
-	return count, err
+type ClanInviteCreateParams struct {
+	CID     int64
+	EID1    int64
+	EID2    int64
+	CiFlags int64
+	CiTime  int64
 }
 
-const empireAttributesUpdate = `-- name: EmpireAttributesUpdate :exec
-UPDATE empire
-SET e_flags       = ?,
-    e_valcode     = ?,
-    e_reason      = ?,
-    e_vacation    = ?,
-    e_idle        = ?,
-    e_era         = ?,
-    e_rank        = ?,
-    e_sharing     = ?,
-    e_attacks     = ?,
-    e_offsucc     = ?,
-    e_offtotal    = ?,
-    e_defsucc     = ?,
-    e_deftotal    = ?,
-    e_kills       = ?,
-    e_score       = ?,
-    e_killedby    = ?,
-    e_killclan    = ?,
-    e_turns       = ?,
-    e_storedturns = ?,
-    e_turnsused   = ?,
-    e_networth    = ?,
-    e_cash        = ?,
-    e_food        = ?,
-    e_peasants    = ?,
-    e_trparm      = ?,
-    e_trplnd      = ?,
-    e_trpfly      = ?,
-    e_trpsea      = ?,
-    e_trpwiz      = ?,
-    e_health      = ?,
-    e_runes       = ?,
-    e_indarm      = ?,
-    e_indlnd      = ?,
-    e_indfly      = ?,
-    e_indsea      = ?,
-    e_land        = ?,
-    e_bldpop      = ?,
-    e_bldcash     = ?,
-    e_bldtrp      = ?,
-    e_bldcost     = ?,
-    e_bldwiz      = ?,
-    e_bldfood     = ?,
-    e_blddef      = ?,
-    e_freeland    = ?,
-    e_tax         = ?,
-    e_bank        = ?,
-    e_loan        = ?,
-    e_mktarm      = ?,
-    e_mktlnd      = ?,
-    e_mktfly      = ?,
-    e_mktsea      = ?,
-    e_mktfood     = ?,
-    e_mktperarm   = ?,
-    e_mktperlnd   = ?,
-    e_mktperfly   = ?,
-    e_mktpersea   = ?
-WHERE e_id = ?
+func (q *Queries) ClanInviteCreate(ctx context.Context, arg ClanInviteCreateParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, clanInviteCreate,
+		arg.CID,
+		arg.EID1,
+		arg.EID2,
+		arg.CiFlags,
+		arg.CiTime,
+	)
+	var ci_id int64
+	err := row.Scan(&ci_id)
+	return ci_id, err
+}
+
+const clanInviteDelete = `-- name: ClanInviteDelete :exec
+DELETE
+FROM clan_invite
+WHERE ci_id = ?
 `
 
-type EmpireAttributesUpdateParams struct {
-	EFlags       sql.NullInt64
-	EValcode     sql.NullString
-	EReason      sql.NullString
-	EVacation    sql.NullInt64
-	EIdle        sql.NullInt64
-	EEra         sql.NullInt64
-	ERank        sql.NullInt64
-	ESharing     sql.NullInt64
-	EAttacks     sql.NullInt64
-	EOffsucc     sql.NullInt64
-	EOfftotal    sql.NullInt64
-	EDefsucc     sql.NullInt64
-	EDeftotal    sql.NullInt64
-	EKills       sql.NullInt64
-	EScore       sql.NullInt64
-	EKilledby    sql.NullInt64
-	EKillclan    sql.NullInt64
-	ETurns       sql.NullInt64
-	EStoredturns sql.NullInt64
-	ETurnsused   sql.NullInt64
-	ENetworth    sql.NullInt64
-	ECash        sql.NullInt64
-	EFood        sql.NullInt64
-	EPeasants    sql.NullInt64
-	ETrparm      sql.NullInt64
-	ETrplnd      sql.NullInt64
-	ETrpfly      sql.NullInt64
-	ETrpsea      sql.NullInt64
-	ETrpwiz      sql.NullInt64
-	EHealth      sql.NullInt64
-	ERunes       sql.NullInt64
-	EIndarm      sql.NullInt64
-	EIndlnd      sql.NullInt64
-	EIndfly      sql.NullInt64
-	EIndsea      sql.NullInt64
-	ELand        sql.NullInt64
-	EBldpop      sql.NullInt64
-	EBldcash     sql.NullInt64
-	EBldtrp      sql.NullInt64
-	EBldcost     sql.NullInt64
-	EBldwiz      sql.NullInt64
-	EBldfood     sql.NullInt64
-	EBlddef      sql.NullInt64
-	EFreeland    sql.NullInt64
-	ETax         sql.NullInt64
-	EBank        sql.NullInt64
-	ELoan        sql.NullInt64
-	EMktarm      sql.NullInt64
-	EMktlnd      sql.NullInt64
-	EMktfly      sql.NullInt64
-	EMktsea      sql.NullInt64
-	EMktfood     sql.NullInt64
-	EMktperarm   sql.NullInt64
-	EMktperlnd   sql.NullInt64
-	EMktperfly   sql.NullInt64
-	EMktpersea   sql.NullInt64
-	EID          int64
+func (q *Queries) ClanInviteDelete(ctx context.Context, ciID int64) error {
+	_, err := q.db.ExecContext(ctx, clanInviteDelete, ciID)
+	return err
 }
 
-func (q *Queries) EmpireAttributesUpdate(ctx context.Context, arg EmpireAttributesUpdateParams) error {
-	_, err := q.db.ExecContext(ctx, empireAttributesUpdate,
-		arg.EFlags,
-		arg.EValcode,
-		arg.EReason,
-		arg.EVacation,
-		arg.EIdle,
-		arg.EEra,
-		arg.ERank,
-		arg.ESharing,
-		arg.EAttacks,
-		arg.EOffsucc,
-		arg.EOfftotal,
-		arg.EDefsucc,
-		arg.EDeftotal,
-		arg.EKills,
-		arg.EScore,
-		arg.EKilledby,
-		arg.EKillclan,
-		arg.ETurns,
-		arg.EStoredturns,
-		arg.ETurnsused,
-		arg.ENetworth,
-		arg.ECash,
-		arg.EFood,
-		arg.EPeasants,
-		arg.ETrparm,
-		arg.ETrplnd,
-		arg.ETrpfly,
-		arg.ETrpsea,
-		arg.ETrpwiz,
-		arg.EHealth,
-		arg.ERunes,
-		arg.EIndarm,
-		arg.EIndlnd,
-		arg.EIndfly,
-		arg.EIndsea,
-		arg.ELand,
-		arg.EBldpop,
-		arg.EBldcash,
-		arg.EBldtrp,
-		arg.EBldcost,
-		arg.EBldwiz,
-		arg.EBldfood,
-		arg.EBlddef,
-		arg.EFreeland,
-		arg.ETax,
-		arg.EBank,
-		arg.ELoan,
-		arg.EMktarm,
-		arg.EMktlnd,
-		arg.EMktfly,
-		arg.EMktsea,
-		arg.EMktfood,
-		arg.EMktperarm,
-		arg.EMktperlnd,
-		arg.EMktperfly,
-		arg.EMktpersea,
-		arg.EID,
+const clanInviteFetch = `-- name: ClanInviteFetch :one
+SELECT ci_id, c_id, e_id_1, e_id_2, ci_flags, ci_time
+FROM clan_invite
+WHERE ci_id = ?
+`
+
+func (q *Queries) ClanInviteFetch(ctx context.Context, ciID int64) (ClanInvite, error) {
+	row := q.db.QueryRowContext(ctx, clanInviteFetch, ciID)
+	var i ClanInvite
+	err := row.Scan(
+		&i.CiID,
+		&i.CID,
+		&i.EID1,
+		&i.EID2,
+		&i.CiFlags,
+		&i.CiTime,
 	)
-	return err
+	return i, err
 }
 
-const empireCreate = `-- name: EmpireCreate :one
-INSERT INTO empire (u_id, e_name, e_race)
-VALUES (?, ?, ?)
-RETURNING e_id
+const clanInviteFind = `-- name: ClanInviteFind :one
+SELECT ci_id, c_id, e_id_1, e_id_2, ci_flags, ci_time
+FROM clan_invite
+WHERE c_id = ?
+  AND e_id_2 = ?
+ORDER BY ci_id
+LIMIT 1
 `
 
-type EmpireCreateParams struct {
-	UID   int64
-	EName string
-	ERace int64
+type ClanInviteFindParams struct {
+	CID  int64
+	EID2 int64
 }
 
-func (q *Queries) EmpireCreate(ctx context.Context, arg EmpireCreateParams) (int64, error) {
-	row := q.db.QueryRowContext(ctx, empireCreate, arg.UID, arg.EName, arg.ERace)
-	var e_id int64
-	err := row.Scan(&e_id)
-	return e_id, err
+func (q *Queries) ClanInviteFind(ctx context.Context, arg ClanInviteFindParams) (ClanInvite, error) {
+	row := q.db.QueryRowContext(ctx, clanInviteFind, arg.CID, arg.EID2)
+	var i ClanInvite
+	err := row.Scan(
+		&i.CiID,
+		&i.CID,
+		&i.EID1,
+		&i.EID2,
+		&i.CiFlags,
+		&i.CiTime,
+	)
+	return i, err
 }
 
-const empireFetch = `-- name: EmpireFetch :one
-SELECT e_id,
-       u_id,
-       u_oldid,
-       e_signupdate,
-       e_flags,
-       e_valcode,
-       e_reason,
-       e_vacation,
-       e_idle,
-       e_name,
-       e_race,
-       e_era,
-       e_rank,
-       c_id,
-       c_oldid,
-       e_sharing,
-       e_attacks,
-       e_offsucc,
-       e_offtotal,
-       e_defsucc,
-       e_deftotal,
-       e_kills,
-       e_score,
-       e_killedby,
-       e_killclan,
-       e_turns,
-       e_storedturns,
-       e_turnsused,
-       e_networth,
-       e_cash,
-       e_food,
-       e_peasants,
-       e_trparm,
-       e_trplnd,
-       e_trpfly,
-       e_trpsea,
+const clanInviteRecipientsFetch = `-- name: ClanInviteRecipientsFetch :many
+SELECT e_id_2
+FROM clan_invite
+WHERE c_id = ?
+ORDER BY ci_id
+`
+
+func (q *Queries) ClanInviteRecipientsFetch(ctx context.Context, cID int64) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, clanInviteRecipientsFetch, cID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var e_id_2 int64
+		if err := rows.Scan(&e_id_2); err != nil {
+			return nil, err
+		}
+		items = append(items, e_id_2)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const clanInviteUpdate = `-- name: ClanInviteUpdate :exec
+UPDATE clan_invite
+SET e_id_1   = ?,
+    ci_flags = ?,
+    ci_time  = ?
+WHERE ci_id = ?
+`
+
+type ClanInviteUpdateParams struct {
+	EID1    int64
+	CiFlags int64
+	CiTime  int64
+	CiID    int64
+}
+
+func (q *Queries) ClanInviteUpdate(ctx context.Context, arg ClanInviteUpdateParams) error {
+	_, err := q.db.ExecContext(ctx, clanInviteUpdate,
+		arg.EID1,
+		arg.CiFlags,
+		arg.CiTime,
+		arg.CiID,
+	)
+	return err
+}
+
+const clanInvitesDelete = `-- name: ClanInvitesDelete :exec
+DELETE
+FROM clan_invite
+WHERE c_id = ?
+`
+
+func (q *Queries) ClanInvitesDelete(ctx context.Context, cID int64) error {
+	_, err := q.db.ExecContext(ctx, clanInvitesDelete, cID)
+	return err
+}
+
+const clanInvitesDeleteExpired = `-- name: ClanInvitesDeleteExpired :execrows
+DELETE
+FROM clan_invite
+WHERE ci_flags & CAST(? AS INTEGER) = 0
+  AND ci_time <= ?
+`
+
+type ClanInvitesDeleteExpiredParams struct {
+	Flags  int64
+	CiTime int64
+}
+
+func (q *Queries) ClanInvitesDeleteExpired(ctx context.Context, arg ClanInvitesDeleteExpiredParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, clanInvitesDeleteExpired, arg.Flags, arg.CiTime)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const clanInvitesDeleteTemporary = `-- name: ClanInvitesDeleteTemporary :exec
+DELETE
+FROM clan_invite
+WHERE e_id_2 = ?
+  AND ci_flags & CAST(? AS INTEGER) = 0
+`
+
+type ClanInvitesDeleteTemporaryParams struct {
+	EID2  int64
+	Flags int64
+}
+
+func (q *Queries) ClanInvitesDeleteTemporary(ctx context.Context, arg ClanInvitesDeleteTemporaryParams) error {
+	_, err := q.db.ExecContext(ctx, clanInvitesDeleteTemporary, arg.EID2, arg.Flags)
+	return err
+}
+
+const clanInvitesFetch = `-- name: ClanInvitesFetch :many
+SELECT ci_id, c_id, e_id_1, e_id_2, ci_flags, ci_time
+FROM clan_invite
+WHERE c_id = ?
+ORDER BY ci_id
+`
+
+func (q *Queries) ClanInvitesFetch(ctx context.Context, cID int64) ([]ClanInvite, error) {
+	rows, err := q.db.QueryContext(ctx, clanInvitesFetch, cID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClanInvite
+	for rows.Next() {
+		var i ClanInvite
+		if err := rows.Scan(
+			&i.CiID,
+			&i.CID,
+			&i.EID1,
+			&i.EID2,
+			&i.CiFlags,
+			&i.CiTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const clanInvitesReceivedFetch = `-- name: ClanInvitesReceivedFetch :many
+SELECT ci_id, c_id, e_id_1, e_id_2, ci_flags, ci_time
+FROM clan_invite
+WHERE e_id_2 = ?
+ORDER BY ci_id
+`
+
+func (q *Queries) ClanInvitesReceivedFetch(ctx context.Context, eID2 int64) ([]ClanInvite, error) {
+	rows, err := q.db.QueryContext(ctx, clanInvitesReceivedFetch, eID2)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClanInvite
+	for rows.Next() {
+		var i ClanInvite
+		if err := rows.Scan(
+			&i.CiID,
+			&i.CID,
+			&i.EID1,
+			&i.EID2,
+			&i.CiFlags,
+			&i.CiTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const clanMembersFetch = `-- name: ClanMembersFetch :many
+SELECT e_id, e_name, e_flags, e_land, e_networth, e_sharing, e_trparm, e_trplnd, e_trpfly, e_trpsea
+FROM empire
+WHERE c_id = CAST(? AS INTEGER)
+ORDER BY e_networth DESC, e_id
+`
+
+type ClanMembersFetchRow struct {
+	EID       int64
+	EName     string
+	EFlags    sql.NullInt64
+	ELand     sql.NullInt64
+	ENetworth sql.NullInt64
+	ESharing  sql.NullInt64
+	ETrparm   sql.NullInt64
+	ETrplnd   sql.NullInt64
+	ETrpfly   sql.NullInt64
+	ETrpsea   sql.NullInt64
+}
+
+func (q *Queries) ClanMembersFetch(ctx context.Context, cID int64) ([]ClanMembersFetchRow, error) {
+	rows, err := q.db.QueryContext(ctx, clanMembersFetch, cID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClanMembersFetchRow
+	for rows.Next() {
+		var i ClanMembersFetchRow
+		if err := rows.Scan(
+			&i.EID,
+			&i.EName,
+			&i.EFlags,
+			&i.ELand,
+			&i.ENetworth,
+			&i.ESharing,
+			&i.ETrparm,
+			&i.ETrplnd,
+			&i.ETrpfly,
+			&i.ETrpsea,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const clanMessageCreate = `-- name: ClanMessageCreate :one
+INSERT INTO clan_message (ct_id, e_id, cm_body, cm_time, cm_flags)
+VALUES (?, ?, ?, ?, ?)
+RETURNING cm_id
+`
+
+type ClanMessageCreateParams struct {
+	CtID    int64
+	EID     int64
+	CmBody  string
+	CmTime  int64
+	CmFlags int64
+}
+
+func (q *Queries) ClanMessageCreate(ctx context.Context, arg ClanMessageCreateParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, clanMessageCreate,
+		arg.CtID,
+		arg.EID,
+		arg.CmBody,
+		arg.CmTime,
+		arg.CmFlags,
+	)
+	var cm_id int64
+	err := row.Scan(&cm_id)
+	return cm_id, err
+}
+
+const clanMessageFetch = `-- name: ClanMessageFetch :one
+SELECT cm_id, ct_id, e_id, cm_body, cm_time, cm_flags
+FROM clan_message
+WHERE cm_id = ?
+`
+
+func (q *Queries) ClanMessageFetch(ctx context.Context, cmID int64) (ClanMessage, error) {
+	row := q.db.QueryRowContext(ctx, clanMessageFetch, cmID)
+	var i ClanMessage
+	err := row.Scan(
+		&i.CmID,
+		&i.CtID,
+		&i.EID,
+		&i.CmBody,
+		&i.CmTime,
+		&i.CmFlags,
+	)
+	return i, err
+}
+
+const clanMessageUpdate = `-- name: ClanMessageUpdate :exec
+UPDATE clan_message
+SET cm_body  = ?,
+    cm_time  = ?,
+    cm_flags = ?
+WHERE cm_id = ?
+`
+
+type ClanMessageUpdateParams struct {
+	CmBody  string
+	CmTime  int64
+	CmFlags int64
+	CmID    int64
+}
+
+func (q *Queries) ClanMessageUpdate(ctx context.Context, arg ClanMessageUpdateParams) error {
+	_, err := q.db.ExecContext(ctx, clanMessageUpdate,
+		arg.CmBody,
+		arg.CmTime,
+		arg.CmFlags,
+		arg.CmID,
+	)
+	return err
+}
+
+const clanMessagesDelete = `-- name: ClanMessagesDelete :exec
+UPDATE clan_message
+SET cm_flags = cm_flags | CAST(? AS INTEGER)
+WHERE ct_id = ?
+`
+
+type ClanMessagesDeleteParams struct {
+	Flags int64
+	CtID  int64
+}
+
+func (q *Queries) ClanMessagesDelete(ctx context.Context, arg ClanMessagesDeleteParams) error {
+	_, err := q.db.ExecContext(ctx, clanMessagesDelete, arg.Flags, arg.CtID)
+	return err
+}
+
+const clanMessagesFetch = `-- name: ClanMessagesFetch :many
+SELECT cm_id, ct_id, e_id, cm_body, cm_time, cm_flags
+FROM clan_message
+WHERE ct_id = ?
+  AND cm_flags & CAST(? AS INTEGER) = 0
+ORDER BY cm_id
+`
+
+type ClanMessagesFetchParams struct {
+	CtID  int64
+	Flags int64
+}
+
+func (q *Queries) ClanMessagesFetch(ctx context.Context, arg ClanMessagesFetchParams) ([]ClanMessage, error) {
+	rows, err := q.db.QueryContext(ctx, clanMessagesFetch, arg.CtID, arg.Flags)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClanMessage
+	for rows.Next() {
+		var i ClanMessage
+		if err := rows.Scan(
+			&i.CmID,
+			&i.CtID,
+			&i.EID,
+			&i.CmBody,
+			&i.CmTime,
+			&i.CmFlags,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const clanNameCount = `-- name: ClanNameCount :one
+SELECT COUNT(*)
+FROM clan
+WHERE c_name = ?
+  AND c_members >= 0
+`
+
+func (q *Queries) ClanNameCount(ctx context.Context, cName string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, clanNameCount, cName)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const clanNewsCreate = `-- name: ClanNewsCreate :exec
+INSERT INTO clan_news (cn_time, c_id, e_id_1, c_id_2, e_id_2, cn_event)
+VALUES (?, ?, ?, ?, ?, ?)
+`
+
+type ClanNewsCreateParams struct {
+	CnTime  int64
+	CID     int64
+	EID1    int64
+	CID2    int64
+	EID2    int64
+	CnEvent int64
+}
+
+func (q *Queries) ClanNewsCreate(ctx context.Context, arg ClanNewsCreateParams) error {
+	_, err := q.db.ExecContext(ctx, clanNewsCreate,
+		arg.CnTime,
+		arg.CID,
+		arg.EID1,
+		arg.CID2,
+		arg.EID2,
+		arg.CnEvent,
+	)
+	return err
+}
+
+const clanNewsFetch = `-- name: ClanNewsFetch :many
+SELECT cn_id, cn_time, c_id, e_id_1, c_id_2, e_id_2, cn_event
+FROM clan_news
+WHERE c_id = ?
+  AND cn_time > ?
+ORDER BY cn_id
+`
+
+type ClanNewsFetchParams struct {
+	CID    int64
+	CnTime int64
+}
+
+func (q *Queries) ClanNewsFetch(ctx context.Context, arg ClanNewsFetchParams) ([]ClanNews, error) {
+	rows, err := q.db.QueryContext(ctx, clanNewsFetch, arg.CID, arg.CnTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClanNews
+	for rows.Next() {
+		var i ClanNews
+		if err := rows.Scan(
+			&i.CnID,
+			&i.CnTime,
+			&i.CID,
+			&i.EID1,
+			&i.CID2,
+			&i.EID2,
+			&i.CnEvent,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const clanRelationCreate = `-- name: ClanRelationCreate :one
+INSERT INTO clan_relation (c_id_1, c_id_2, cr_flags, cr_time)
+VALUES (?, ?, ?, ?)
+RETURNING cr_id
+`
+
+type ClanRelationCreateParams struct {
+	CID1    int64
+	CID2    int64
+	CrFlags int64
+	CrTime  int64
+}
+
+func (q *Queries) ClanRelationCreate(ctx context.Context, arg ClanRelationCreateParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, clanRelationCreate,
+		arg.CID1,
+		arg.CID2,
+		arg.CrFlags,
+		arg.CrTime,
+	)
+	var cr_id int64
+	err := row.Scan(&cr_id)
+	return cr_id, err
+}
+
+const clanRelationDelete = `-- name: ClanRelationDelete :exec
+DELETE
+FROM clan_relation
+WHERE cr_id = ?
+`
+
+func (q *Queries) ClanRelationDelete(ctx context.Context, crID int64) error {
+	_, err := q.db.ExecContext(ctx, clanRelationDelete, crID)
+	return err
+}
+
+const clanRelationFetch = `-- name: ClanRelationFetch :one
+SELECT cr_id, c_id_1, c_id_2, cr_flags, cr_time
+FROM clan_relation
+WHERE cr_id = ?
+`
+
+func (q *Queries) ClanRelationFetch(ctx context.Context, crID int64) (ClanRelation, error) {
+	row := q.db.QueryRowContext(ctx, clanRelationFetch, crID)
+	var i ClanRelation
+	err := row.Scan(
+		&i.CrID,
+		&i.CID1,
+		&i.CID2,
+		&i.CrFlags,
+		&i.CrTime,
+	)
+	return i, err
+}
+
+const clanRelationUpdate = `-- name: ClanRelationUpdate :exec
+UPDATE clan_relation
+SET c_id_1   = ?,
+    c_id_2   = ?,
+    cr_flags = ?,
+    cr_time  = ?
+WHERE cr_id = ?
+`
+
+type ClanRelationUpdateParams struct {
+	CID1    int64
+	CID2    int64
+	CrFlags int64
+	CrTime  int64
+	CrID    int64
+}
+
+func (q *Queries) ClanRelationUpdate(ctx context.Context, arg ClanRelationUpdateParams) error {
+	_, err := q.db.ExecContext(ctx, clanRelationUpdate,
+		arg.CID1,
+		arg.CID2,
+		arg.CrFlags,
+		arg.CrTime,
+		arg.CrID,
+	)
+	return err
+}
+
+const clanRelationsDelete = `-- name: ClanRelationsDelete :exec
+DELETE
+FROM clan_relation
+WHERE c_id_1 = CAST(? AS INTEGER)
+   OR c_id_2 = CAST(? AS INTEGER)
+`
+
+func (q *Queries) ClanRelationsDelete(ctx context.Context, cID int64) error {
+	_, err := q.db.ExecContext(ctx, clanRelationsDelete, cID, cID)
+	return err
+}
+
+const clanRelationsFetch = `-- name: ClanRelationsFetch :many
+SELECT cr_id, c_id_1, c_id_2, cr_flags, cr_time
+FROM clan_relation
+WHERE c_id_1 = CAST(? AS INTEGER)
+   OR c_id_2 = CAST(? AS INTEGER)
+ORDER BY cr_id
+`
+
+func (q *Queries) ClanRelationsFetch(ctx context.Context, cID int64) ([]ClanRelation, error) {
+	rows, err := q.db.QueryContext(ctx, clanRelationsFetch, cID, cID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClanRelation
+	for rows.Next() {
+		var i ClanRelation
+		if err := rows.Scan(
+			&i.CrID,
+			&i.CID1,
+			&i.CID2,
+			&i.CrFlags,
+			&i.CrTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const clanSharingFetch = `-- name: ClanSharingFetch :many
+SELECT e_id
+FROM empire
+WHERE e_id != CAST(? AS INTEGER)
+  AND c_id = CAST(? AS INTEGER)
+  AND IFNULL(e_sharing, 0) != 0
+  AND IFNULL(e_land, 0) > 0
+  AND IFNULL(e_flags, 0) & CAST(? AS INTEGER) = 0
+ORDER BY e_id
+`
+
+type ClanSharingFetchParams struct {
+	EID   int64
+	CID   int64
+	Flags int64
+}
+
+func (q *Queries) ClanSharingFetch(ctx context.Context, arg ClanSharingFetchParams) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, clanSharingFetch, arg.EID, arg.CID, arg.Flags)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var e_id int64
+		if err := rows.Scan(&e_id); err != nil {
+			return nil, err
+		}
+		items = append(items, e_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const clanTopicCreate = `-- name: ClanTopicCreate :one
+INSERT INTO clan_topic (c_id, ct_subject, ct_flags)
+VALUES (?, ?, ?)
+RETURNING ct_id
+`
+
+type ClanTopicCreateParams struct {
+	CID       int64
+	CtSubject string
+	CtFlags   int64
+}
+
+func (q *Queries) ClanTopicCreate(ctx context.Context, arg ClanTopicCreateParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, clanTopicCreate, arg.CID, arg.CtSubject, arg.CtFlags)
+	var ct_id int64
+	err := row.Scan(&ct_id)
+	return ct_id, err
+}
+
+const clanTopicFetch = `-- name: ClanTopicFetch :one
+SELECT ct_id, c_id, ct_subject, ct_flags
+FROM clan_topic
+WHERE ct_id = ?
+`
+
+func (q *Queries) ClanTopicFetch(ctx context.Context, ctID int64) (ClanTopic, error) {
+	row := q.db.QueryRowContext(ctx, clanTopicFetch, ctID)
+	var i ClanTopic
+	err := row.Scan(
+		&i.CtID,
+		&i.CID,
+		&i.CtSubject,
+		&i.CtFlags,
+	)
+	return i, err
+}
+
+const clanTopicUpdate = `-- name: ClanTopicUpdate :exec
+UPDATE clan_topic
+SET ct_subject = ?,
+    ct_flags   = ?
+WHERE ct_id = ?
+`
+
+type ClanTopicUpdateParams struct {
+	CtSubject string
+	CtFlags   int64
+	CtID      int64
+}
+
+func (q *Queries) ClanTopicUpdate(ctx context.Context, arg ClanTopicUpdateParams) error {
+	_, err := q.db.ExecContext(ctx, clanTopicUpdate, arg.CtSubject, arg.CtFlags, arg.CtID)
+	return err
+}
+
+const clanTopicsFetch = `-- name: ClanTopicsFetch :many
+SELECT ct_id, c_id, ct_subject, ct_flags
+FROM clan_topic
+WHERE c_id = ?
+  AND ct_flags & CAST(? AS INTEGER) = 0
+ORDER BY ct_id
+`
+
+type ClanTopicsFetchParams struct {
+	CID   int64
+	Flags int64
+}
+
+func (q *Queries) ClanTopicsFetch(ctx context.Context, arg ClanTopicsFetchParams) ([]ClanTopic, error) {
+	rows, err := q.db.QueryContext(ctx, clanTopicsFetch, arg.CID, arg.Flags)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClanTopic
+	for rows.Next() {
+		var i ClanTopic
+		if err := rows.Scan(
+			&i.CtID,
+			&i.CID,
+			&i.CtSubject,
+			&i.CtFlags,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const clanUpdate = `-- name: ClanUpdate :exec
+UPDATE clan
+SET c_name      = ?,
+    c_password  = ?,
+    c_members   = ?,
+    e_id_leader = ?,
+    e_id_asst   = ?,
+    e_id_fa1    = ?,
+    e_id_fa2    = ?,
+    c_title     = ?,
+    c_url       = ?,
+    c_pic       = ?
+WHERE c_id = ?
+`
+
+type ClanUpdateParams struct {
+	CName     string
+	CPassword string
+	CMembers  int64
+	EIDLeader int64
+	EIDAsst   int64
+	EIDFa1    int64
+	EIDFa2    int64
+	CTitle    string
+	CUrl      string
+	CPic      string
+	CID       int64
+}
+
+func (q *Queries) ClanUpdate(ctx context.Context, arg ClanUpdateParams) error {
+	_, err := q.db.ExecContext(ctx, clanUpdate,
+		arg.CName,
+		arg.CPassword,
+		arg.CMembers,
+		arg.EIDLeader,
+		arg.EIDAsst,
+		arg.EIDFa1,
+		arg.EIDFa2,
+		arg.CTitle,
+		arg.CUrl,
+		arg.CPic,
+		arg.CID,
+	)
+	return err
+}
+
+const clansEmptyFetch = `-- name: ClansEmptyFetch :many
+SELECT c_id,
+       c_name,
+       c_password,
+       c_members,
+       e_id_leader,
+       e_id_asst,
+       e_id_fa1,
+       e_id_fa2,
+       c_title,
+       c_url,
+       c_pic
+FROM clan
+WHERE c_members = 0
+ORDER BY c_id
+`
+
+func (q *Queries) ClansEmptyFetch(ctx context.Context) ([]Clan, error) {
+	rows, err := q.db.QueryContext(ctx, clansEmptyFetch)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Clan
+	for rows.Next() {
+		var i Clan
+		if err := rows.Scan(
+			&i.CID,
+			&i.CName,
+			&i.CPassword,
+			&i.CMembers,
+			&i.EIDLeader,
+			&i.EIDAsst,
+			&i.EIDFa1,
+			&i.EIDFa2,
+			&i.CTitle,
+			&i.CUrl,
+			&i.CPic,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const clansFetch = `-- name: ClansFetch :many
+SELECT c_id,
+       c_name,
+       c_password,
+       c_members,
+       e_id_leader,
+       e_id_asst,
+       e_id_fa1,
+       e_id_fa2,
+       c_title,
+       c_url,
+       c_pic
+FROM clan
+WHERE c_members > 0
+ORDER BY c_id
+`
+
+func (q *Queries) ClansFetch(ctx context.Context) ([]Clan, error) {
+	rows, err := q.db.QueryContext(ctx, clansFetch)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Clan
+	for rows.Next() {
+		var i Clan
+		if err := rows.Scan(
+			&i.CID,
+			&i.CName,
+			&i.CPassword,
+			&i.CMembers,
+			&i.EIDLeader,
+			&i.EIDAsst,
+			&i.EIDFa1,
+			&i.EIDFa2,
+			&i.CTitle,
+			&i.CUrl,
+			&i.CPic,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const empireActivePlayerCount = `-- name: EmpireActivePlayerCount :one
+SELECT COUNT(*)
+FROM empire
+WHERE u_id != 0
+  AND IFNULL(e_flags, 0) & CAST(? AS INTEGER) = 0
+`
+
+func (q *Queries) EmpireActivePlayerCount(ctx context.Context, flags int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, empireActivePlayerCount, flags)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const empireActiveUserCount = `-- name: EmpireActiveUserCount :one
+SELECT COUNT(*)
+FROM empire
+WHERE u_id != 0
+`
+
+func (q *Queries) EmpireActiveUserCount(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, empireActiveUserCount)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const empireAttributesUpdate = `-- name: EmpireAttributesUpdate :exec
+UPDATE empire
+SET e_flags       = ?,
+    e_valcode     = ?,
+    e_reason      = ?,
+    e_vacation    = ?,
+    e_idle        = ?,
+    e_era         = ?,
+    e_rank        = ?,
+    c_id          = ?,
+    c_oldid       = ?,
+    e_sharing     = ?,
+    e_attacks     = ?,
+    e_offsucc     = ?,
+    e_offtotal    = ?,
+    e_defsucc     = ?,
+    e_deftotal    = ?,
+    e_kills       = ?,
+    e_score       = ?,
+    e_killedby    = ?,
+    e_killclan    = ?,
+    e_turns       = ?,
+    e_storedturns = ?,
+    e_turnsused   = ?,
+    e_networth    = ?,
+    e_cash        = ?,
+    e_food        = ?,
+    e_peasants    = ?,
+    e_trparm      = ?,
+    e_trplnd      = ?,
+    e_trpfly      = ?,
+    e_trpsea      = ?,
+    e_trpwiz      = ?,
+    e_health      = ?,
+    e_runes       = ?,
+    e_indarm      = ?,
+    e_indlnd      = ?,
+    e_indfly      = ?,
+    e_indsea      = ?,
+    e_land        = ?,
+    e_bldpop      = ?,
+    e_bldcash     = ?,
+    e_bldtrp      = ?,
+    e_bldcost     = ?,
+    e_bldwiz      = ?,
+    e_bldfood     = ?,
+    e_blddef      = ?,
+    e_freeland    = ?,
+    e_tax         = ?,
+    e_bank        = ?,
+    e_loan        = ?,
+    e_mktarm      = ?,
+    e_mktlnd      = ?,
+    e_mktfly      = ?,
+    e_mktsea      = ?,
+    e_mktfood     = ?,
+    e_mktperarm   = ?,
+    e_mktperlnd   = ?,
+    e_mktperfly   = ?,
+    e_mktpersea   = ?
+WHERE e_id = ?
+`
+
+type EmpireAttributesUpdateParams struct {
+	EFlags       sql.NullInt64
+	EValcode     sql.NullString
+	EReason      sql.NullString
+	EVacation    sql.NullInt64
+	EIdle        sql.NullInt64
+	EEra         sql.NullInt64
+	ERank        sql.NullInt64
+	CID          sql.NullInt64
+	COldid       sql.NullInt64
+	ESharing     sql.NullInt64
+	EAttacks     sql.NullInt64
+	EOffsucc     sql.NullInt64
+	EOfftotal    sql.NullInt64
+	EDefsucc     sql.NullInt64
+	EDeftotal    sql.NullInt64
+	EKills       sql.NullInt64
+	EScore       sql.NullInt64
+	EKilledby    sql.NullInt64
+	EKillclan    sql.NullInt64
+	ETurns       sql.NullInt64
+	EStoredturns sql.NullInt64
+	ETurnsused   sql.NullInt64
+	ENetworth    sql.NullInt64
+	ECash        sql.NullInt64
+	EFood        sql.NullInt64
+	EPeasants    sql.NullInt64
+	ETrparm      sql.NullInt64
+	ETrplnd      sql.NullInt64
+	ETrpfly      sql.NullInt64
+	ETrpsea      sql.NullInt64
+	ETrpwiz      sql.NullInt64
+	EHealth      sql.NullInt64
+	ERunes       sql.NullInt64
+	EIndarm      sql.NullInt64
+	EIndlnd      sql.NullInt64
+	EIndfly      sql.NullInt64
+	EIndsea      sql.NullInt64
+	ELand        sql.NullInt64
+	EBldpop      sql.NullInt64
+	EBldcash     sql.NullInt64
+	EBldtrp      sql.NullInt64
+	EBldcost     sql.NullInt64
+	EBldwiz      sql.NullInt64
+	EBldfood     sql.NullInt64
+	EBlddef      sql.NullInt64
+	EFreeland    sql.NullInt64
+	ETax         sql.NullInt64
+	EBank        sql.NullInt64
+	ELoan        sql.NullInt64
+	EMktarm      sql.NullInt64
+	EMktlnd      sql.NullInt64
+	EMktfly      sql.NullInt64
+	EMktsea      sql.NullInt64
+	EMktfood     sql.NullInt64
+	EMktperarm   sql.NullInt64
+	EMktperlnd   sql.NullInt64
+	EMktperfly   sql.NullInt64
+	EMktpersea   sql.NullInt64
+	EID          int64
+}
+
+func (q *Queries) EmpireAttributesUpdate(ctx context.Context, arg EmpireAttributesUpdateParams) error {
+	_, err := q.db.ExecContext(ctx, empireAttributesUpdate,
+		arg.EFlags,
+		arg.EValcode,
+		arg.EReason,
+		arg.EVacation,
+		arg.EIdle,
+		arg.EEra,
+		arg.ERank,
+		arg.CID,
+		arg.COldid,
+		arg.ESharing,
+		arg.EAttacks,
+		arg.EOffsucc,
+		arg.EOfftotal,
+		arg.EDefsucc,
+		arg.EDeftotal,
+		arg.EKills,
+		arg.EScore,
+		arg.EKilledby,
+		arg.EKillclan,
+		arg.ETurns,
+		arg.EStoredturns,
+		arg.ETurnsused,
+		arg.ENetworth,
+		arg.ECash,
+		arg.EFood,
+		arg.EPeasants,
+		arg.ETrparm,
+		arg.ETrplnd,
+		arg.ETrpfly,
+		arg.ETrpsea,
+		arg.ETrpwiz,
+		arg.EHealth,
+		arg.ERunes,
+		arg.EIndarm,
+		arg.EIndlnd,
+		arg.EIndfly,
+		arg.EIndsea,
+		arg.ELand,
+		arg.EBldpop,
+		arg.EBldcash,
+		arg.EBldtrp,
+		arg.EBldcost,
+		arg.EBldwiz,
+		arg.EBldfood,
+		arg.EBlddef,
+		arg.EFreeland,
+		arg.ETax,
+		arg.EBank,
+		arg.ELoan,
+		arg.EMktarm,
+		arg.EMktlnd,
+		arg.EMktfly,
+		arg.EMktsea,
+		arg.EMktfood,
+		arg.EMktperarm,
+		arg.EMktperlnd,
+		arg.EMktperfly,
+		arg.EMktpersea,
+		arg.EID,
+	)
+	return err
+}
+
+const empireCount = `-- name: EmpireCount :one
+SELECT COUNT(*)
+FROM empire
+`
+
+func (q *Queries) EmpireCount(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, empireCount)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const empireCreate = `-- name: EmpireCreate :one
+INSERT INTO empire (u_id, e_name, e_race, e_idle)
+VALUES (?, ?, ?, ?)
+RETURNING e_id
+`
+
+type EmpireCreateParams struct {
+	UID   int64
+	EName string
+	ERace int64
+	EIdle sql.NullInt64
+}
+
+func (q *Queries) EmpireCreate(ctx context.Context, arg EmpireCreateParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, empireCreate,
+		arg.UID,
+		arg.EName,
+		arg.ERace,
+		arg.EIdle,
+	)
+	var e_id int64
+	err := row.Scan(&e_id)
+	return e_id, err
+}
+
+const empireEffectDelete = `-- name: EmpireEffectDelete :exec
+DELETE
+FROM empire_effect
+WHERE e_id = ?
+  AND ef_name = ?
+`
+
+type EmpireEffectDeleteParams struct {
+	EID    int64
+	EfName string
+}
+
+func (q *Queries) EmpireEffectDelete(ctx context.Context, arg EmpireEffectDeleteParams) error {
+	_, err := q.db.ExecContext(ctx, empireEffectDelete, arg.EID, arg.EfName)
+	return err
+}
+
+const empireEffectsCleanupPerm = `-- name: EmpireEffectsCleanupPerm :execrows
+DELETE
+FROM empire_effect
+WHERE substr(ef_name, 1, 2) = 'p_'
+  AND ef_value = 0
+`
+
+func (q *Queries) EmpireEffectsCleanupPerm(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, empireEffectsCleanupPerm)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const empireEffectsCleanupTime = `-- name: EmpireEffectsCleanupTime :execrows
+DELETE
+FROM empire_effect
+WHERE substr(ef_name, 1, 2) = 'm_'
+  AND ef_value <= CAST(? AS INTEGER)
+`
+
+func (q *Queries) EmpireEffectsCleanupTime(ctx context.Context, now int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, empireEffectsCleanupTime, now)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const empireEffectsCleanupTurn = `-- name: EmpireEffectsCleanupTurn :execrows
+DELETE
+FROM empire_effect
+WHERE substr(ef_name, 1, 2) = 'r_'
+  AND ef_value <= 0
+`
+
+func (q *Queries) EmpireEffectsCleanupTurn(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, empireEffectsCleanupTurn)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const empireEffectsFetch = `-- name: EmpireEffectsFetch :many
+SELECT e_id, ef_name, ef_value
+FROM empire_effect
+WHERE e_id = ?
+ORDER BY ef_name
+`
+
+func (q *Queries) EmpireEffectsFetch(ctx context.Context, eID int64) ([]EmpireEffect, error) {
+	rows, err := q.db.QueryContext(ctx, empireEffectsFetch, eID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EmpireEffect
+	for rows.Next() {
+		var i EmpireEffect
+		if err := rows.Scan(&i.EID, &i.EfName, &i.EfValue); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const empireEffectUpsert = `-- name: EmpireEffectUpsert :exec
+INSERT INTO empire_effect (e_id, ef_name, ef_value)
+VALUES (?, ?, ?)
+ON CONFLICT (e_id, ef_name) DO UPDATE SET ef_value = excluded.ef_value
+`
+
+type EmpireEffectUpsertParams struct {
+	EID     int64
+	EfName  string
+	EfValue int64
+}
+
+func (q *Queries) EmpireEffectUpsert(ctx context.Context, arg EmpireEffectUpsertParams) error {
+	_, err := q.db.ExecContext(ctx, empireEffectUpsert, arg.EID, arg.EfName, arg.EfValue)
+	return err
+}
+
+const empireFetch = `-- name: EmpireFetch :one
+SELECT e_id,
+       u_id,
+       u_oldid,
+       e_signupdate,
+       e_flags,
+       e_valcode,
+       e_reason,
+       e_vacation,
+       e_idle,
+       e_name,
+       e_race,
+       e_era,
+       e_rank,
+       c_id,
+       c_oldid,
+       e_sharing,
+       e_attacks,
+       e_offsucc,
+       e_offtotal,
+       e_defsucc,
+       e_deftotal,
+       e_kills,
+       e_score,
+       e_killedby,
+       e_killclan,
+       e_turns,
+       e_storedturns,
+       e_turnsused,
+       e_networth,
+       e_cash,
+       e_food,
+       e_peasants,
+       e_trparm,
+       e_trplnd,
+       e_trpfly,
+       e_trpsea,
        e_trpwiz,
        e_health,
        e_runes,
@@ -393,92 +1517,1300 @@ FROM empire
 WHERE e_id = ?
 `
 
-func (q *Queries) EmpireFetch(ctx context.Context, eID int64) (Empire, error) {
-	row := q.db.QueryRowContext(ctx, empireFetch, eID)
-	var i Empire
-	err := row.Scan(
-		&i.EID,
-		&i.UID,
-		&i.UOldid,
-		&i.ESignupdate,
-		&i.EFlags,
-		&i.EValcode,
-		&i.EReason,
-		&i.EVacation,
-		&i.EIdle,
-		&i.EName,
-		&i.ERace,
-		&i.EEra,
-		&i.ERank,
-		&i.CID,
-		&i.COldid,
-		&i.ESharing,
-		&i.EAttacks,
-		&i.EOffsucc,
-		&i.EOfftotal,
-		&i.EDefsucc,
-		&i.EDeftotal,
-		&i.EKills,
-		&i.EScore,
-		&i.EKilledby,
-		&i.EKillclan,
-		&i.ETurns,
-		&i.EStoredturns,
-		&i.ETurnsused,
-		&i.ENetworth,
-		&i.ECash,
-		&i.EFood,
-		&i.EPeasants,
-		&i.ETrparm,
-		&i.ETrplnd,
-		&i.ETrpfly,
-		&i.ETrpsea,
-		&i.ETrpwiz,
-		&i.EHealth,
-		&i.ERunes,
-		&i.EIndarm,
-		&i.EIndlnd,
-		&i.EIndfly,
-		&i.EIndsea,
-		&i.ELand,
-		&i.EBldpop,
-		&i.EBldcash,
-		&i.EBldtrp,
-		&i.EBldcost,
-		&i.EBldwiz,
-		&i.EBldfood,
-		&i.EBlddef,
-		&i.EFreeland,
-		&i.ETax,
-		&i.EBank,
-		&i.ELoan,
-		&i.EMktarm,
-		&i.EMktlnd,
-		&i.EMktfly,
-		&i.EMktsea,
-		&i.EMktfood,
-		&i.EMktperarm,
-		&i.EMktperlnd,
-		&i.EMktperfly,
-		&i.EMktpersea,
+func (q *Queries) EmpireFetch(ctx context.Context, eID int64) (Empire, error) {
+	row := q.db.QueryRowContext(ctx, empireFetch, eID)
+	var i Empire
+	err := row.Scan(
+		&i.EID,
+		&i.UID,
+		&i.UOldid,
+		&i.ESignupdate,
+		&i.EFlags,
+		&i.EValcode,
+		&i.EReason,
+		&i.EVacation,
+		&i.EIdle,
+		&i.EName,
+		&i.ERace,
+		&i.EEra,
+		&i.ERank,
+		&i.CID,
+		&i.COldid,
+		&i.ESharing,
+		&i.EAttacks,
+		&i.EOffsucc,
+		&i.EOfftotal,
+		&i.EDefsucc,
+		&i.EDeftotal,
+		&i.EKills,
+		&i.EScore,
+		&i.EKilledby,
+		&i.EKillclan,
+		&i.ETurns,
+		&i.EStoredturns,
+		&i.ETurnsused,
+		&i.ENetworth,
+		&i.ECash,
+		&i.EFood,
+		&i.EPeasants,
+		&i.ETrparm,
+		&i.ETrplnd,
+		&i.ETrpfly,
+		&i.ETrpsea,
+		&i.ETrpwiz,
+		&i.EHealth,
+		&i.ERunes,
+		&i.EIndarm,
+		&i.EIndlnd,
+		&i.EIndfly,
+		&i.EIndsea,
+		&i.ELand,
+		&i.EBldpop,
+		&i.EBldcash,
+		&i.EBldtrp,
+		&i.EBldcost,
+		&i.EBldwiz,
+		&i.EBldfood,
+		&i.EBlddef,
+		&i.EFreeland,
+		&i.ETax,
+		&i.EBank,
+		&i.ELoan,
+		&i.EMktarm,
+		&i.EMktlnd,
+		&i.EMktfly,
+		&i.EMktsea,
+		&i.EMktfood,
+		&i.EMktperarm,
+		&i.EMktperlnd,
+		&i.EMktperfly,
+		&i.EMktpersea,
+	)
+	return i, err
+}
+
+const empireIdleUpdate = `-- name: EmpireIdleUpdate :exec
+UPDATE empire
+SET e_idle = CAST(? AS INTEGER)
+WHERE e_id = CAST(? AS INTEGER)
+`
+
+type EmpireIdleUpdateParams struct {
+	Idle int64
+	EID  int64
+}
+
+func (q *Queries) EmpireIdleUpdate(ctx context.Context, arg EmpireIdleUpdateParams) error {
+	_, err := q.db.ExecContext(ctx, empireIdleUpdate, arg.Idle, arg.EID)
+	return err
+}
+
+const empireMessagesFlagReceived = `-- name: EmpireMessagesFlagReceived :exec
+UPDATE empire_message
+SET m_flags = m_flags | CAST(? AS INTEGER)
+WHERE e_id_dst = CAST(? AS INTEGER)
+`
+
+type EmpireMessagesFlagReceivedParams struct {
+	Flags int64
+	EID   int64
+}
+
+func (q *Queries) EmpireMessagesFlagReceived(ctx context.Context, arg EmpireMessagesFlagReceivedParams) error {
+	_, err := q.db.ExecContext(ctx, empireMessagesFlagReceived, arg.Flags, arg.EID)
+	return err
+}
+
+const empireMessagesFlagSent = `-- name: EmpireMessagesFlagSent :exec
+UPDATE empire_message
+SET m_flags = m_flags | CAST(? AS INTEGER)
+WHERE e_id_src = CAST(? AS INTEGER)
+`
+
+type EmpireMessagesFlagSentParams struct {
+	Flags int64
+	EID   int64
+}
+
+func (q *Queries) EmpireMessagesFlagSent(ctx context.Context, arg EmpireMessagesFlagSentParams) error {
+	_, err := q.db.ExecContext(ctx, empireMessagesFlagSent, arg.Flags, arg.EID)
+	return err
+}
+
+const empireNetworthUpdate = `-- name: EmpireNetworthUpdate :exec
+UPDATE empire
+SET e_networth = ?
+WHERE e_id = ?
+`
+
+type EmpireNetworthUpdateParams struct {
+	ENetworth sql.NullInt64
+	EID       int64
+}
+
+func (q *Queries) EmpireNetworthUpdate(ctx context.Context, arg EmpireNetworthUpdateParams) error {
+	_, err := q.db.ExecContext(ctx, empireNetworthUpdate, arg.ENetworth, arg.EID)
+	return err
+}
+
+const empireNewsAttachmentsLock = `-- name: EmpireNewsAttachmentsLock :exec
+UPDATE empire_news
+SET n_flags = n_flags | CAST(? AS INTEGER)
+WHERE e_id_dst = CAST(? AS INTEGER)
+  AND n_flags & CAST(? AS INTEGER) = 0
+  AND n_event BETWEEN CAST(? AS INTEGER) AND CAST(? AS INTEGER)
+`
+
+type EmpireNewsAttachmentsLockParams struct {
+	Lock   int64
+	EID    int64
+	Gotten int64
+	First  int64
+	Last   int64
+}
+
+func (q *Queries) EmpireNewsAttachmentsLock(ctx context.Context, arg EmpireNewsAttachmentsLockParams) error {
+	_, err := q.db.ExecContext(ctx, empireNewsAttachmentsLock,
+		arg.Lock,
+		arg.EID,
+		arg.Gotten,
+		arg.First,
+		arg.Last,
+	)
+	return err
+}
+
+const empireNewsClanAidFetch = `-- name: EmpireNewsClanAidFetch :many
+SELECT n_id, n_time, e_id_src, e_id_dst, n_d0, n_d1, n_d2, n_d3, n_d4, n_d5, n_d6, n_d7
+FROM empire_news
+WHERE c_id_src = ?
+  AND n_event = ?
+ORDER BY n_id
+`
+
+type EmpireNewsClanAidFetchParams struct {
+	CIDSrc int64
+	NEvent int64
+}
+
+type EmpireNewsClanAidFetchRow struct {
+	NID    int64
+	NTime  int64
+	EIDSrc int64
+	EIDDst int64
+	ND0    int64
+	ND1    int64
+	ND2    int64
+	ND3    int64
+	ND4    int64
+	ND5    int64
+	ND6    int64
+	ND7    int64
+}
+
+func (q *Queries) EmpireNewsClanAidFetch(ctx context.Context, arg EmpireNewsClanAidFetchParams) ([]EmpireNewsClanAidFetchRow, error) {
+	rows, err := q.db.QueryContext(ctx, empireNewsClanAidFetch, arg.CIDSrc, arg.NEvent)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EmpireNewsClanAidFetchRow
+	for rows.Next() {
+		var i EmpireNewsClanAidFetchRow
+		if err := rows.Scan(
+			&i.NID,
+			&i.NTime,
+			&i.EIDSrc,
+			&i.EIDDst,
+			&i.ND0,
+			&i.ND1,
+			&i.ND2,
+			&i.ND3,
+			&i.ND4,
+			&i.ND5,
+			&i.ND6,
+			&i.ND7,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const empireNewsCreate = `-- name: EmpireNewsCreate :exec
+INSERT INTO empire_news (n_time, e_id_src, c_id_src, e_id_dst, c_id_dst, n_event,
+                         n_d0, n_d1, n_d2, n_d3, n_d4, n_d5, n_d6, n_d7, n_d8)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+type EmpireNewsCreateParams struct {
+	NTime  int64
+	EIDSrc int64
+	CIDSrc int64
+	EIDDst int64
+	CIDDst int64
+	NEvent int64
+	ND0    int64
+	ND1    int64
+	ND2    int64
+	ND3    int64
+	ND4    int64
+	ND5    int64
+	ND6    int64
+	ND7    int64
+	ND8    int64
+}
+
+func (q *Queries) EmpireNewsCreate(ctx context.Context, arg EmpireNewsCreateParams) error {
+	_, err := q.db.ExecContext(ctx, empireNewsCreate,
+		arg.NTime,
+		arg.EIDSrc,
+		arg.CIDSrc,
+		arg.EIDDst,
+		arg.CIDDst,
+		arg.NEvent,
+		arg.ND0,
+		arg.ND1,
+		arg.ND2,
+		arg.ND3,
+		arg.ND4,
+		arg.ND5,
+		arg.ND6,
+		arg.ND7,
+		arg.ND8,
+	)
+	return err
+}
+
+const empireNewsLockedFetch = `-- name: EmpireNewsLockedFetch :many
+SELECT n_id, e_id_src, c_id_src, e_id_dst, c_id_dst, n_event, n_d0, n_d1, n_d2, n_d3, n_d4, n_d5, n_d6, n_d7, n_d8
+FROM empire_news
+WHERE e_id_dst = CAST(? AS INTEGER)
+  AND n_flags & CAST(? AS INTEGER) != 0
+ORDER BY n_time, n_id
+`
+
+type EmpireNewsLockedFetchParams struct {
+	EID  int64
+	Lock int64
+}
+
+type EmpireNewsLockedFetchRow struct {
+	NID    int64
+	EIDSrc int64
+	CIDSrc int64
+	EIDDst int64
+	CIDDst int64
+	NEvent int64
+	ND0    int64
+	ND1    int64
+	ND2    int64
+	ND3    int64
+	ND4    int64
+	ND5    int64
+	ND6    int64
+	ND7    int64
+	ND8    int64
+}
+
+func (q *Queries) EmpireNewsLockedFetch(ctx context.Context, arg EmpireNewsLockedFetchParams) ([]EmpireNewsLockedFetchRow, error) {
+	rows, err := q.db.QueryContext(ctx, empireNewsLockedFetch, arg.EID, arg.Lock)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EmpireNewsLockedFetchRow
+	for rows.Next() {
+		var i EmpireNewsLockedFetchRow
+		if err := rows.Scan(
+			&i.NID,
+			&i.EIDSrc,
+			&i.CIDSrc,
+			&i.EIDDst,
+			&i.CIDDst,
+			&i.NEvent,
+			&i.ND0,
+			&i.ND1,
+			&i.ND2,
+			&i.ND3,
+			&i.ND4,
+			&i.ND5,
+			&i.ND6,
+			&i.ND7,
+			&i.ND8,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const empireNewsLockedGotten = `-- name: EmpireNewsLockedGotten :exec
+UPDATE empire_news
+SET n_flags = (n_flags | CAST(? AS INTEGER)) & ~CAST(? AS INTEGER)
+WHERE e_id_dst = CAST(? AS INTEGER)
+  AND n_flags & CAST(? AS INTEGER) != 0
+`
+
+type EmpireNewsLockedGottenParams struct {
+	Gotten int64
+	Lock   int64
+	EID    int64
+}
+
+func (q *Queries) EmpireNewsLockedGotten(ctx context.Context, arg EmpireNewsLockedGottenParams) error {
+	_, err := q.db.ExecContext(ctx, empireNewsLockedGotten,
+		arg.Gotten,
+		arg.Lock,
+		arg.EID,
+		arg.Lock,
+	)
+	return err
+}
+
+const empirePlayerIdsFetch = `-- name: EmpirePlayerIdsFetch :many
+SELECT e_id
+FROM empire
+WHERE u_id != 0
+ORDER BY e_id
+`
+
+func (q *Queries) EmpirePlayerIdsFetch(ctx context.Context) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, empirePlayerIdsFetch)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var e_id int64
+		if err := rows.Scan(&e_id); err != nil {
+			return nil, err
+		}
+		items = append(items, e_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const empireRankUpdate = `-- name: EmpireRankUpdate :exec
+UPDATE empire
+SET e_rank = ?
+WHERE e_id = ?
+`
+
+type EmpireRankUpdateParams struct {
+	ERank sql.NullInt64
+	EID   int64
+}
+
+func (q *Queries) EmpireRankUpdate(ctx context.Context, arg EmpireRankUpdateParams) error {
+	_, err := q.db.ExecContext(ctx, empireRankUpdate, arg.ERank, arg.EID)
+	return err
+}
+
+const empireUnlink = `-- name: EmpireUnlink :exec
+UPDATE empire
+SET u_oldid = u_id,
+    u_id    = 0
+WHERE e_id = ?
+`
+
+func (q *Queries) EmpireUnlink(ctx context.Context, eID int64) error {
+	_, err := q.db.ExecContext(ctx, empireUnlink, eID)
+	return err
+}
+
+const empiresAccrueScore = `-- name: EmpiresAccrueScore :exec
+UPDATE empire
+SET e_score = CAST(ROUND(e_score + SQRT(e_networth / 1250000.0)) AS INTEGER)
+WHERE e_vacation = 0
+  AND e_flags & CAST(? AS INTEGER) = 0
+  AND u_id != 0
+`
+
+func (q *Queries) EmpiresAccrueScore(ctx context.Context, flags int64) error {
+	_, err := q.db.ExecContext(ctx, empiresAccrueScore, flags)
+	return err
+}
+
+const empiresClearOnline = `-- name: EmpiresClearOnline :exec
+UPDATE empire
+SET e_flags = IFNULL(e_flags, 0) & ~CAST(? AS INTEGER)
+`
+
+func (q *Queries) EmpiresClearOnline(ctx context.Context, online int64) error {
+	_, err := q.db.ExecContext(ctx, empiresClearOnline, online)
+	return err
+}
+
+const empiresClearUnownedRank = `-- name: EmpiresClearUnownedRank :exec
+UPDATE empire
+SET e_rank = 0
+WHERE u_id = 0
+`
+
+func (q *Queries) EmpiresClearUnownedRank(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, empiresClearUnownedRank)
+	return err
+}
+
+const empiresDecrementAttacks = `-- name: EmpiresDecrementAttacks :exec
+UPDATE empire
+SET e_attacks = e_attacks - 1
+WHERE e_attacks > 0
+  AND u_id != 0
+`
+
+func (q *Queries) EmpiresDecrementAttacks(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, empiresDecrementAttacks)
+	return err
+}
+
+const empiresDecrementSharing = `-- name: EmpiresDecrementSharing :exec
+UPDATE empire
+SET e_sharing = e_sharing - 1
+WHERE e_sharing > 0
+  AND u_id != 0
+`
+
+func (q *Queries) EmpiresDecrementSharing(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, empiresDecrementSharing)
+	return err
+}
+
+const empiresEndVacation = `-- name: EmpiresEndVacation :exec
+UPDATE empire
+SET e_vacation = 0,
+    e_idle     = CAST(? AS INTEGER)
+WHERE e_vacation >= CAST(? AS INTEGER)
+  AND u_id != 0
+`
+
+type EmpiresEndVacationParams struct {
+	Idle     int64
+	Vacation int64
+}
+
+func (q *Queries) EmpiresEndVacation(ctx context.Context, arg EmpiresEndVacationParams) error {
+	_, err := q.db.ExecContext(ctx, empiresEndVacation, arg.Idle, arg.Vacation)
+	return err
+}
+
+const empiresFlushOnline = `-- name: EmpiresFlushOnline :exec
+UPDATE empire
+SET e_flags = IFNULL(e_flags, 0) & ~CAST(? AS INTEGER)
+WHERE IFNULL(e_idle, 0) < CAST(? AS INTEGER)
+   OR u_id = 0
+`
+
+type EmpiresFlushOnlineParams struct {
+	Online int64
+	Idle   int64
+}
+
+func (q *Queries) EmpiresFlushOnline(ctx context.Context, arg EmpiresFlushOnlineParams) error {
+	_, err := q.db.ExecContext(ctx, empiresFlushOnline, arg.Online, arg.Idle)
+	return err
+}
+
+const empiresGiveTurns = `-- name: EmpiresGiveTurns :exec
+UPDATE empire
+SET e_turns       = IFNULL(e_turns, 0) + CAST(? AS INTEGER) +
+                    MIN(IFNULL(e_storedturns, 0), CAST(? AS INTEGER)),
+    e_storedturns = IFNULL(e_storedturns, 0) - MIN(IFNULL(e_storedturns, 0), CAST(? AS INTEGER))
+WHERE IFNULL(e_vacation, 0) = 0
+  AND IFNULL(e_flags, 0) & CAST(? AS INTEGER) = 0
+  AND u_id != 0
+`
+
+type EmpiresGiveTurnsParams struct {
+	Turns   int64
+	Unstore int64
+	Flags   int64
+}
+
+func (q *Queries) EmpiresGiveTurns(ctx context.Context, arg EmpiresGiveTurnsParams) error {
+	_, err := q.db.ExecContext(ctx, empiresGiveTurns,
+		arg.Turns,
+		arg.Unstore,
+		arg.Unstore,
+		arg.Flags,
+	)
+	return err
+}
+
+const empiresIncrementVacation = `-- name: EmpiresIncrementVacation :exec
+UPDATE empire
+SET e_vacation = e_vacation + 1
+WHERE e_vacation > 0
+  AND u_id != 0
+`
+
+func (q *Queries) EmpiresIncrementVacation(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, empiresIncrementVacation)
+	return err
+}
+
+const empiresRankByNetworth = `-- name: EmpiresRankByNetworth :many
+SELECT e_id
+FROM empire
+WHERE u_id != 0
+ORDER BY e_networth DESC, e_id
+`
+
+func (q *Queries) EmpiresRankByNetworth(ctx context.Context) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, empiresRankByNetworth)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var e_id int64
+		if err := rows.Scan(&e_id); err != nil {
+			return nil, err
+		}
+		items = append(items, e_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const empiresRankByScore = `-- name: EmpiresRankByScore :many
+SELECT e_id
+FROM empire
+WHERE u_id != 0
+ORDER BY e_score DESC, e_networth DESC, e_id
+`
+
+func (q *Queries) EmpiresRankByScore(ctx context.Context) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, empiresRankByScore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var e_id int64
+		if err := rows.Scan(&e_id); err != nil {
+			return nil, err
+		}
+		items = append(items, e_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const empiresRecoverAttacks = `-- name: EmpiresRecoverAttacks :exec
+UPDATE empire
+SET e_attacks = e_attacks + 1
+WHERE e_attacks < 0
+  AND u_id != 0
+`
+
+func (q *Queries) EmpiresRecoverAttacks(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, empiresRecoverAttacks)
+	return err
+}
+
+const empiresReducePrivateMarketSales = `-- name: EmpiresReducePrivateMarketSales :exec
+UPDATE empire
+SET e_mktperarm = e_mktperarm - MIN(e_mktperarm, CAST(ROUND(100 * (1 + CAST(e_bldcash AS REAL) / e_land)) AS INTEGER)),
+    e_mktperlnd = e_mktperlnd - MIN(e_mktperlnd, CAST(ROUND(100 * (1 + CAST(e_bldcash AS REAL) / e_land)) AS INTEGER)),
+    e_mktperfly = e_mktperfly - MIN(e_mktperfly, CAST(ROUND(100 * (1 + CAST(e_bldcash AS REAL) / e_land)) AS INTEGER)),
+    e_mktpersea = e_mktpersea - MIN(e_mktpersea, CAST(ROUND(100 * (1 + CAST(e_bldcash AS REAL) / e_land)) AS INTEGER))
+WHERE u_id != 0
+  AND e_land != 0
+`
+
+func (q *Queries) EmpiresReducePrivateMarketSales(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, empiresReducePrivateMarketSales)
+	return err
+}
+
+const empiresRefillPrivateMarket = `-- name: EmpiresRefillPrivateMarket :exec
+UPDATE empire
+SET e_mktarm  = CASE WHEN IFNULL(e_mktarm, 0) / 250 < e_land + 2 * e_bldcost THEN IFNULL(e_mktarm, 0) + 8 * (e_land + e_bldcost) ELSE e_mktarm END,
+    e_mktlnd  = CASE WHEN IFNULL(e_mktlnd, 0) / 200 < e_land + 2 * e_bldcost THEN IFNULL(e_mktlnd, 0) + 5 * (e_land + e_bldcost) ELSE e_mktlnd END,
+    e_mktfly  = CASE WHEN IFNULL(e_mktfly, 0) / 180 < e_land + 2 * e_bldcost THEN IFNULL(e_mktfly, 0) + 3 * (e_land + e_bldcost) ELSE e_mktfly END,
+    e_mktsea  = CASE WHEN IFNULL(e_mktsea, 0) / 150 < e_land + 2 * e_bldcost THEN IFNULL(e_mktsea, 0) + 2 * (e_land + e_bldcost) ELSE e_mktsea END,
+    e_mktfood = CASE WHEN IFNULL(e_mktfood, 0) / 2000 < e_land + 2 * e_bldfood THEN IFNULL(e_mktfood, 0) + 50 * (e_land + e_bldfood) ELSE e_mktfood END
+WHERE u_id != 0
+`
+
+func (q *Queries) EmpiresRefillPrivateMarket(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, empiresRefillPrivateMarket)
+	return err
+}
+
+const empiresResetIdle = `-- name: EmpiresResetIdle :exec
+UPDATE empire
+SET e_idle = CAST(? AS INTEGER)
+`
+
+func (q *Queries) EmpiresResetIdle(ctx context.Context, idle int64) error {
+	_, err := q.db.ExecContext(ctx, empiresResetIdle, idle)
+	return err
+}
+
+const empiresStoreTurns = `-- name: EmpiresStoreTurns :exec
+UPDATE empire
+SET e_storedturns = MIN(CAST(? AS INTEGER),
+                        IFNULL(e_storedturns, 0) + e_turns - CAST(? AS INTEGER)),
+    e_turns       = CAST(? AS INTEGER)
+WHERE e_turns > CAST(? AS INTEGER)
+  AND u_id != 0
+`
+
+type EmpiresStoreTurnsParams struct {
+	MaxStored int64
+	MaxTurns  int64
+}
+
+func (q *Queries) EmpiresStoreTurns(ctx context.Context, arg EmpiresStoreTurnsParams) error {
+	_, err := q.db.ExecContext(ctx, empiresStoreTurns,
+		arg.MaxStored,
+		arg.MaxTurns,
+		arg.MaxTurns,
+		arg.MaxTurns,
+	)
+	return err
+}
+
+const empireUpdateFlags = `-- name: EmpireUpdateFlags :exec
+UPDATE empire
+SET e_flags = ?
+WHERE e_id = ?
+`
+
+type EmpireUpdateFlagsParams struct {
+	EFlags sql.NullInt64
+	EID    int64
+}
+
+func (q *Queries) EmpireUpdateFlags(ctx context.Context, arg EmpireUpdateFlagsParams) error {
+	_, err := q.db.ExecContext(ctx, empireUpdateFlags, arg.EFlags, arg.EID)
+	return err
+}
+
+const empiresUnlinkableFetch = `-- name: EmpiresUnlinkableFetch :many
+SELECT e_id
+FROM empire
+WHERE u_id != 0
+  AND IFNULL(e_flags, 0) & CAST(? AS INTEGER) = 0
+  AND ((IFNULL(e_flags, 0) & CAST(? AS INTEGER) = 0 AND IFNULL(e_idle, 0) < CAST(? AS INTEGER))
+    OR (IFNULL(e_flags, 0) & CAST(? AS INTEGER) = CAST(? AS INTEGER) AND IFNULL(e_idle, 0) < CAST(? AS INTEGER))
+    OR (IFNULL(e_flags, 0) & CAST(? AS INTEGER) = 0 AND IFNULL(e_vacation, 0) = 0 AND IFNULL(e_land, 0) > 0 AND IFNULL(e_idle, 0) < CAST(? AS INTEGER))
+    OR (IFNULL(e_land, 0) = 0 AND (IFNULL(e_flags, 0) & CAST(? AS INTEGER) = CAST(? AS INTEGER) OR IFNULL(e_idle, 0) < CAST(? AS INTEGER)))
+    OR (IFNULL(e_flags, 0) & CAST(? AS INTEGER) = CAST(? AS INTEGER) AND (IFNULL(e_turnsused, 0) <= CAST(? AS INTEGER) OR IFNULL(e_idle, 0) < CAST(? AS INTEGER))))
+ORDER BY e_id
+`
+
+type EmpiresUnlinkableFetchParams struct {
+	Admin        int64
+	ValidNotify  int64
+	NewIdle      int64
+	Notify       int64
+	ValidateIdle int64
+	Disable      int64
+	AbandonIdle  int64
+	KilledIdle   int64
+	Delete       int64
+	Protection   int64
+	DeleteIdle   int64
+}
+
+func (q *Queries) EmpiresUnlinkableFetch(ctx context.Context, arg EmpiresUnlinkableFetchParams) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, empiresUnlinkableFetch,
+		arg.Admin,
+		arg.ValidNotify,
+		arg.NewIdle,
+		arg.ValidNotify,
+		arg.Notify,
+		arg.ValidateIdle,
+		arg.Disable,
+		arg.AbandonIdle,
+		arg.Notify,
+		arg.Notify,
+		arg.KilledIdle,
+		arg.Delete,
+		arg.Delete,
+		arg.Protection,
+		arg.DeleteIdle,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var e_id int64
+		if err := rows.Scan(&e_id); err != nil {
+			return nil, err
+		}
+		items = append(items, e_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const historyEmpireMaxRanks = `-- name: HistoryEmpireMaxRanks :many
+SELECT hr_id, CAST(MAX(he_rank) AS INTEGER) AS maxrank
+FROM history_empire
+GROUP BY hr_id
+`
+
+type HistoryEmpireMaxRanksRow struct {
+	HrID    int64
+	Maxrank int64
+}
+
+func (q *Queries) HistoryEmpireMaxRanks(ctx context.Context) ([]HistoryEmpireMaxRanksRow, error) {
+	rows, err := q.db.QueryContext(ctx, historyEmpireMaxRanks)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []HistoryEmpireMaxRanksRow
+	for rows.Next() {
+		var i HistoryEmpireMaxRanksRow
+		if err := rows.Scan(&i.HrID, &i.Maxrank); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const historyEmpireUserRanks = `-- name: HistoryEmpireUserRanks :many
+SELECT hr_id, CAST(MIN(he_rank) AS INTEGER) AS myrank
+FROM history_empire
+WHERE u_id = ?
+GROUP BY hr_id
+`
+
+type HistoryEmpireUserRanksRow struct {
+	HrID   int64
+	Myrank int64
+}
+
+func (q *Queries) HistoryEmpireUserRanks(ctx context.Context, uID int64) ([]HistoryEmpireUserRanksRow, error) {
+	rows, err := q.db.QueryContext(ctx, historyEmpireUserRanks, uID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []HistoryEmpireUserRanksRow
+	for rows.Next() {
+		var i HistoryEmpireUserRanksRow
+		if err := rows.Scan(&i.HrID, &i.Myrank); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lotteryClear = `-- name: LotteryClear :exec
+DELETE
+FROM lottery
+`
+
+func (q *Queries) LotteryClear(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, lotteryClear)
+	return err
+}
+
+const lotteryDrawCreate = `-- name: LotteryDrawCreate :exec
+INSERT INTO lottery_draw (ld_time, ld_ticket, ld_tickets, ld_jackpot, ld_increase, e_id, e_name, ld_won)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+type LotteryDrawCreateParams struct {
+	LdTime     int64
+	LdTicket   int64
+	LdTickets  int64
+	LdJackpot  int64
+	LdIncrease int64
+	EID        int64
+	EName      string
+	LdWon      int64
+}
+
+func (q *Queries) LotteryDrawCreate(ctx context.Context, arg LotteryDrawCreateParams) error {
+	_, err := q.db.ExecContext(ctx, lotteryDrawCreate,
+		arg.LdTime,
+		arg.LdTicket,
+		arg.LdTickets,
+		arg.LdJackpot,
+		arg.LdIncrease,
+		arg.EID,
+		arg.EName,
+		arg.LdWon,
+	)
+	return err
+}
+
+const lotteryDraws = `-- name: LotteryDraws :many
+SELECT *
+FROM lottery_draw
+ORDER BY ld_time DESC, ld_id DESC
+LIMIT ?
+`
+
+func (q *Queries) LotteryDraws(ctx context.Context, limit int64) ([]LotteryDraw, error) {
+	rows, err := q.db.QueryContext(ctx, lotteryDraws, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LotteryDraw
+	for rows.Next() {
+		var i LotteryDraw
+		if err := rows.Scan(
+			&i.LdID,
+			&i.LdTime,
+			&i.LdTicket,
+			&i.LdTickets,
+			&i.LdJackpot,
+			&i.LdIncrease,
+			&i.EID,
+			&i.EName,
+			&i.LdWon,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lotteryEmpireTickets = `-- name: LotteryEmpireTickets :many
+SELECT l_ticket
+FROM lottery
+WHERE e_id = ?
+ORDER BY l_ticket
+`
+
+func (q *Queries) LotteryEmpireTickets(ctx context.Context, eID int64) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, lotteryEmpireTickets, eID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var l_ticket int64
+		if err := rows.Scan(&l_ticket); err != nil {
+			return nil, err
+		}
+		items = append(items, l_ticket)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lotteryEmpireTicketsDelete = `-- name: LotteryEmpireTicketsDelete :exec
+DELETE
+FROM lottery
+WHERE e_id = ?
+`
+
+func (q *Queries) LotteryEmpireTicketsDelete(ctx context.Context, eID int64) error {
+	_, err := q.db.ExecContext(ctx, lotteryEmpireTicketsDelete, eID)
+	return err
+}
+
+const lotteryTicketCreate = `-- name: LotteryTicketCreate :exec
+INSERT INTO lottery (e_id, l_ticket, l_cash)
+VALUES (?, ?, ?)
+`
+
+type LotteryTicketCreateParams struct {
+	EID     int64
+	LTicket int64
+	LCash   int64
+}
+
+func (q *Queries) LotteryTicketCreate(ctx context.Context, arg LotteryTicketCreateParams) error {
+	_, err := q.db.ExecContext(ctx, lotteryTicketCreate, arg.EID, arg.LTicket, arg.LCash)
+	return err
+}
+
+const lotteryTicketHolder = `-- name: LotteryTicketHolder :one
+SELECT CAST(IFNULL(MAX(e_id), 0) AS INTEGER) AS e_id
+FROM lottery
+WHERE e_id > 0
+  AND l_ticket = ?
+`
+
+func (q *Queries) LotteryTicketHolder(ctx context.Context, lTicket int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, lotteryTicketHolder, lTicket)
+	var e_id int64
+	err := row.Scan(&e_id)
+	return e_id, err
+}
+
+const lotteryTicketsSold = `-- name: LotteryTicketsSold :one
+SELECT COUNT(*)
+FROM lottery
+WHERE e_id != 0
+`
+
+func (q *Queries) LotteryTicketsSold(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, lotteryTicketsSold)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const marketEmpireItemsDelete = `-- name: MarketEmpireItemsDelete :exec
+DELETE
+FROM market
+WHERE e_id = ?
+`
+
+func (q *Queries) MarketEmpireItemsDelete(ctx context.Context, eID int64) error {
+	_, err := q.db.ExecContext(ctx, marketEmpireItemsDelete, eID)
+	return err
+}
+
+const marketEmpireItemsFetch = `-- name: MarketEmpireItemsFetch :many
+SELECT k_id, k_type, e_id, k_amt, k_price, k_time
+FROM market
+WHERE e_id = ?
+ORDER BY k_id
+`
+
+func (q *Queries) MarketEmpireItemsFetch(ctx context.Context, eID int64) ([]Market, error) {
+	rows, err := q.db.QueryContext(ctx, marketEmpireItemsFetch, eID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Market
+	for rows.Next() {
+		var i Market
+		if err := rows.Scan(
+			&i.KID,
+			&i.KType,
+			&i.EID,
+			&i.KAmt,
+			&i.KPrice,
+			&i.KTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const marketExpiredDelete = `-- name: MarketExpiredDelete :execrows
+DELETE
+FROM market
+WHERE k_time <= ?
+`
+
+func (q *Queries) MarketExpiredDelete(ctx context.Context, kTime int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, marketExpiredDelete, kTime)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const marketExpiredFetch = `-- name: MarketExpiredFetch :many
+SELECT k_id, k_type, e_id, k_amt, k_price, k_time
+FROM market
+WHERE k_time <= ?
+ORDER BY k_id
+`
+
+func (q *Queries) MarketExpiredFetch(ctx context.Context, kTime int64) ([]Market, error) {
+	rows, err := q.db.QueryContext(ctx, marketExpiredFetch, kTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Market
+	for rows.Next() {
+		var i Market
+		if err := rows.Scan(
+			&i.KID,
+			&i.KType,
+			&i.EID,
+			&i.KAmt,
+			&i.KPrice,
+			&i.KTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const marketItemAmountUpdate = `-- name: MarketItemAmountUpdate :exec
+UPDATE market
+SET k_amt = ?
+WHERE k_id = ?
+`
+
+type MarketItemAmountUpdateParams struct {
+	KAmt int64
+	KID  int64
+}
+
+func (q *Queries) MarketItemAmountUpdate(ctx context.Context, arg MarketItemAmountUpdateParams) error {
+	_, err := q.db.ExecContext(ctx, marketItemAmountUpdate, arg.KAmt, arg.KID)
+	return err
+}
+
+const marketItemCreate = `-- name: MarketItemCreate :one
+INSERT INTO market (k_type, e_id, k_amt, k_price, k_time)
+VALUES (?, ?, ?, ?, ?)
+RETURNING k_id
+`
+
+type MarketItemCreateParams struct {
+	KType  int64
+	EID    int64
+	KAmt   int64
+	KPrice int64
+	KTime  int64
+}
+
+func (q *Queries) MarketItemCreate(ctx context.Context, arg MarketItemCreateParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, marketItemCreate,
+		arg.KType,
+		arg.EID,
+		arg.KAmt,
+		arg.KPrice,
+		arg.KTime,
 	)
-	return i, err
+	var k_id int64
+	err := row.Scan(&k_id)
+	return k_id, err
 }
 
-const empireUpdateFlags = `-- name: EmpireUpdateFlags :exec
-UPDATE empire
-SET e_flags = ?
-WHERE e_id = ?
+const marketItemDelete = `-- name: MarketItemDelete :exec
+DELETE
+FROM market
+WHERE k_id = ?
 `
 
-type EmpireUpdateFlagsParams struct {
-	EFlags sql.NullInt64
+func (q *Queries) MarketItemDelete(ctx context.Context, kID int64) error {
+	_, err := q.db.ExecContext(ctx, marketItemDelete, kID)
+	return err
+}
+
+const marketItemFetch = `-- name: MarketItemFetch :many
+SELECT k_id, k_type, e_id, k_amt, k_price, k_time
+FROM market
+WHERE k_id = ?
+  AND e_id = ?
+`
+
+type MarketItemFetchParams struct {
+	KID int64
+	EID int64
+}
+
+func (q *Queries) MarketItemFetch(ctx context.Context, arg MarketItemFetchParams) ([]Market, error) {
+	rows, err := q.db.QueryContext(ctx, marketItemFetch, arg.KID, arg.EID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Market
+	for rows.Next() {
+		var i Market
+		if err := rows.Scan(
+			&i.KID,
+			&i.KType,
+			&i.EID,
+			&i.KAmt,
+			&i.KPrice,
+			&i.KTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const marketItemsForSale = `-- name: MarketItemsForSale :many
+SELECT k_id, k_type, e_id, k_amt, k_price, k_time
+FROM market
+WHERE k_type = ?
+  AND k_price <= ?
+  AND e_id != ?
+  AND k_time <= ?
+  AND k_amt > 0
+ORDER BY k_price, k_time, k_id
+`
+
+type MarketItemsForSaleParams struct {
+	KType  int64
+	KPrice int64
 	EID    int64
+	KTime  int64
 }
 
-func (q *Queries) EmpireUpdateFlags(ctx context.Context, arg EmpireUpdateFlagsParams) error {
-	_, err := q.db.ExecContext(ctx, empireUpdateFlags, arg.EFlags, arg.EID)
-	return err
+func (q *Queries) MarketItemsForSale(ctx context.Context, arg MarketItemsForSaleParams) ([]Market, error) {
+	rows, err := q.db.QueryContext(ctx, marketItemsForSale,
+		arg.KType,
+		arg.KPrice,
+		arg.EID,
+		arg.KTime,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Market
+	for rows.Next() {
+		var i Market
+		if err := rows.Scan(
+			&i.KID,
+			&i.KType,
+			&i.EID,
+			&i.KAmt,
+			&i.KPrice,
+			&i.KTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const marketPricesFetch = `-- name: MarketPricesFetch :many
+SELECT k_type, k_price, CAST(SUM(k_amt) AS INTEGER) AS k_amt
+FROM market
+WHERE e_id != ?
+  AND k_time <= ?
+GROUP BY k_type, k_price
+ORDER BY k_type, k_price
+`
+
+type MarketPricesFetchParams struct {
+	EID   int64
+	KTime int64
+}
+
+type MarketPricesFetchRow struct {
+	KType  int64
+	KPrice int64
+	KAmt   int64
+}
+
+func (q *Queries) MarketPricesFetch(ctx context.Context, arg MarketPricesFetchParams) ([]MarketPricesFetchRow, error) {
+	rows, err := q.db.QueryContext(ctx, marketPricesFetch, arg.EID, arg.KTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MarketPricesFetchRow
+	for rows.Next() {
+		var i MarketPricesFetchRow
+		if err := rows.Scan(&i.KType, &i.KPrice, &i.KAmt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const sessionCreate = `-- name: SessionCreate :exec
@@ -556,6 +2888,91 @@ func (q *Queries) SessionsPurgeUser(ctx context.Context, sessUid int64) error {
 	return err
 }
 
+const turnlogFetch = `-- name: TurnlogFetch :many
+SELECT turn_id, turn_time, turn_ticks, turn_interval, turn_type, turn_updates, turn_text
+FROM turnlog
+WHERE (CAST(? AS INTEGER) != 0 OR turn_type = CAST(? AS INTEGER))
+  AND turn_updates & CAST(? AS INTEGER) = CAST(? AS INTEGER)
+  AND turn_time >= CAST(? AS INTEGER)
+  AND turn_time < CAST(? AS INTEGER)
+ORDER BY turn_id DESC
+LIMIT CAST(? AS INTEGER)
+`
+
+type TurnlogFetchParams struct {
+	AnyType  int64
+	TurnType int64
+	Updates  int64
+	TimeFrom int64
+	TimeTo   int64
+	MaxRows  int64
+}
+
+func (q *Queries) TurnlogFetch(ctx context.Context, arg TurnlogFetchParams) ([]Turnlog, error) {
+	rows, err := q.db.QueryContext(ctx, turnlogFetch,
+		arg.AnyType,
+		arg.TurnType,
+		arg.Updates,
+		arg.Updates,
+		arg.TimeFrom,
+		arg.TimeTo,
+		arg.MaxRows,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Turnlog
+	for rows.Next() {
+		var i Turnlog
+		if err := rows.Scan(
+			&i.TurnID,
+			&i.TurnTime,
+			&i.TurnTicks,
+			&i.TurnInterval,
+			&i.TurnType,
+			&i.TurnUpdates,
+			&i.TurnText,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const turnlogInsert = `-- name: TurnlogInsert :exec
+INSERT INTO turnlog (turn_time, turn_ticks, turn_interval, turn_type, turn_updates, turn_text)
+VALUES (?, ?, ?, ?, ?, ?)
+`
+
+type TurnlogInsertParams struct {
+	TurnTime     int64
+	TurnTicks    int64
+	TurnInterval int64
+	TurnType     int64
+	TurnUpdates  int64
+	TurnText     string
+}
+
+func (q *Queries) TurnlogInsert(ctx context.Context, arg TurnlogInsertParams) error {
+	_, err := q.db.ExecContext(ctx, turnlogInsert,
+		arg.TurnTime,
+		arg.TurnTicks,
+		arg.TurnInterval,
+		arg.TurnType,
+		arg.TurnUpdates,
+		arg.TurnText,
+	)
+	return err
+}
+
 const userAccessUpdate = `-- name: UserAccessUpdate :one
 UPDATE users
 SET u_lastip   = ?,
@@ -798,6 +3215,129 @@ func (q *Queries) UserPasswordUpdate(ctx context.Context, arg UserPasswordUpdate
 	return u_lastdate, err
 }
 
+const userRankingsUpdate = `-- name: UserRankingsUpdate :exec
+UPDATE users
+SET u_avgrank  = ?,
+    u_bestrank = ?,
+    u_sucplays = ?
+WHERE u_id = ?
+`
+
+type UserRankingsUpdateParams struct {
+	UAvgrank  sql.NullFloat64
+	UBestrank sql.NullFloat64
+	USucplays sql.NullInt64
+	UID       int64
+}
+
+func (q *Queries) UserRankingsUpdate(ctx context.Context, arg UserRankingsUpdateParams) error {
+	_, err := q.db.ExecContext(ctx, userRankingsUpdate,
+		arg.UAvgrank,
+		arg.UBestrank,
+		arg.USucplays,
+		arg.UID,
+	)
+	return err
+}
+
+const usersRankingsFetch = `-- name: UsersRankingsFetch :many
+SELECT u_id, u_numplays, u_avgrank, u_bestrank, u_sucplays
+FROM users
+ORDER BY u_id
+`
+
+type UsersRankingsFetchRow struct {
+	UID       int64
+	UNumplays sql.NullInt64
+	UAvgrank  sql.NullFloat64
+	UBestrank sql.NullFloat64
+	USucplays sql.NullInt64
+}
+
+func (q *Queries) UsersRankingsFetch(ctx context.Context) ([]UsersRankingsFetchRow, error) {
+	rows, err := q.db.QueryContext(ctx, usersRankingsFetch)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UsersRankingsFetchRow
+	for rows.Next() {
+		var i UsersRankingsFetchRow
+		if err := rows.Scan(
+			&i.UID,
+			&i.UNumplays,
+			&i.UAvgrank,
+			&i.UBestrank,
+			&i.USucplays,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const varAdjustCreate = `-- name: VarAdjustCreate :exec
+INSERT INTO var_adjust (v_name, v_offset)
+VALUES (?, ?)
+`
+
+type VarAdjustCreateParams struct {
+	VName   string
+	VOffset int64
+}
+
+func (q *Queries) VarAdjustCreate(ctx context.Context, arg VarAdjustCreateParams) error {
+	_, err := q.db.ExecContext(ctx, varAdjustCreate, arg.VName, arg.VOffset)
+	return err
+}
+
+const varAdjustDelete = `-- name: VarAdjustDelete :exec
+DELETE
+FROM var_adjust
+WHERE va_id <= ?
+`
+
+func (q *Queries) VarAdjustDelete(ctx context.Context, vaID int64) error {
+	_, err := q.db.ExecContext(ctx, varAdjustDelete, vaID)
+	return err
+}
+
+const varAdjustFetch = `-- name: VarAdjustFetch :many
+SELECT va_id, v_name, v_offset
+FROM var_adjust
+ORDER BY va_id
+`
+
+func (q *Queries) VarAdjustFetch(ctx context.Context) ([]VarAdjust, error) {
+	rows, err := q.db.QueryContext(ctx, varAdjustFetch)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []VarAdjust
+	for rows.Next() {
+		var i VarAdjust
+		if err := rows.Scan(&i.VaID, &i.VName, &i.VOffset); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const worldVarsFetch = `-- name: WorldVarsFetch :one
 SELECT wv_id,
        lotto_current_jackpot,
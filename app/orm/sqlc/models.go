@@ -25,45 +25,45 @@ type Clan struct {
 
 type ClanInvite struct {
 	CiID    int64
-	CID     interface{}
-	EID1    interface{}
-	EID2    interface{}
-	CiFlags interface{}
+	CID     int64
+	EID1    int64
+	EID2    int64
+	CiFlags int64
 	CiTime  int64
 }
 
 type ClanMessage struct {
 	CmID    int64
-	CtID    interface{}
-	EID     interface{}
+	CtID    int64
+	EID     int64
 	CmBody  string
 	CmTime  int64
-	CmFlags interface{}
+	CmFlags int64
 }
 
 type ClanNews struct {
 	CnID    int64
 	CnTime  int64
-	CID     interface{}
-	EID1    interface{}
-	CID2    interface{}
-	EID2    interface{}
-	CnEvent interface{}
+	CID     int64
+	EID1    int64
+	CID2    int64
+	EID2    int64
+	CnEvent int64
 }
 
 type ClanRelation struct {
 	CrID    int64
-	CID1    interface{}
-	CID2    interface{}
-	CrFlags interface{}
+	CID1    int64
+	CID2    int64
+	CrFlags int64
 	CrTime  int64
 }
 
 type ClanTopic struct {
 	CtID      int64
-	CID       interface{}
+	CID       int64
 	CtSubject string
-	CtFlags   interface{}
+	CtFlags   int64
 }
 
 type Empire struct {
@@ -134,8 +134,8 @@ type Empire struct {
 }
 
 type EmpireEffect struct {
-	EID     interface{}
-	EfName  interface{}
+	EID     int64
+	EfName  string
 	EfValue int64
 }
 
@@ -153,11 +153,11 @@ type EmpireMessage struct {
 type EmpireNews struct {
 	NID    int64
 	NTime  int64
-	EIDSrc interface{}
-	CIDSrc interface{}
-	EIDDst interface{}
-	CIDDst interface{}
-	NEvent interface{}
+	EIDSrc int64
+	CIDSrc int64
+	EIDDst int64
+	CIDDst int64
+	NEvent int64
 	ND0    int64
 	ND1    int64
 	ND2    int64
@@ -167,7 +167,7 @@ type EmpireNews struct {
 	ND6    int64
 	ND7    int64
 	ND8    int64
-	NFlags interface{}
+	NFlags int64
 }
 
 type HistoryClan struct {
@@ -181,22 +181,22 @@ type HistoryClan struct {
 
 type HistoryEmpire struct {
 	HrID       int64
-	HeFlags    interface{}
-	UID        interface{}
-	HeID       interface{}
+	HeFlags    int64
+	UID        int64
+	HeID       int64
 	HeName     string
 	HeRace     string
 	HeEra      string
-	HcID       interface{}
-	HeOffsucc  interface{}
-	HeOfftotal interface{}
-	HeDefsucc  interface{}
-	HeDeftotal interface{}
-	HeKills    interface{}
+	HcID       int64
+	HeOffsucc  int64
+	HeOfftotal int64
+	HeDefsucc  int64
+	HeDeftotal int64
+	HeKills    int64
 	HeScore    int64
-	HeNetworth interface{}
-	HeLand     interface{}
-	HeRank     interface{}
+	HeNetworth int64
+	HeLand     int64
+	HeRank     int64
 }
 
 type HistoryRound struct {
@@ -235,17 +235,29 @@ type Log struct {
 }
 
 type Lottery struct {
-	EID     interface{}
-	LTicket interface{}
-	LCash   interface{}
+	EID     int64
+	LTicket int64
+	LCash   int64
+}
+
+type LotteryDraw struct {
+	LdID       int64
+	LdTime     int64
+	LdTicket   int64
+	LdTickets  int64
+	LdJackpot  int64
+	LdIncrease int64
+	EID        int64
+	EName      string
+	LdWon      int64
 }
 
 type Market struct {
 	KID    int64
-	KType  interface{}
-	EID    interface{}
-	KAmt   interface{}
-	KPrice interface{}
+	KType  int64
+	EID    int64
+	KAmt   int64
+	KPrice int64
 	KTime  int64
 }
 
@@ -271,10 +283,11 @@ type Session struct {
 
 type Turnlog struct {
 	TurnID       int64
-	TurnTime     interface{}
-	TurnTicks    interface{}
-	TurnInterval interface{}
-	TurnType     interface{}
+	TurnTime     int64
+	TurnTicks    int64
+	TurnInterval int64
+	TurnType     int64
+	TurnUpdates  int64
 	TurnText     string
 }
 
@@ -311,7 +324,8 @@ type Var struct {
 }
 
 type VarAdjust struct {
-	VName   interface{}
+	VaID    int64
+	VName   string
 	VOffset int64
 }
 
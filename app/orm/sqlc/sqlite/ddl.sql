@@ -25,11 +25,11 @@ DROP TABLE IF EXISTS clan_invite;
 CREATE TABLE clan_invite
 (
     ci_id    INTEGER PRIMARY KEY,
-    c_id     int unsigned     NOT NULL DEFAULT 0,
-    e_id_1   int unsigned     NOT NULL DEFAULT 0,
-    e_id_2   int unsigned     NOT NULL DEFAULT 0,
-    ci_flags tinyint unsigned NOT NULL DEFAULT 0,
-    ci_time  int              NOT NULL DEFAULT 0
+    c_id     INTEGER NOT NULL DEFAULT 0, -- int unsigned     NOT NULL DEFAULT 0,
+    e_id_1   INTEGER NOT NULL DEFAULT 0, -- int unsigned     NOT NULL DEFAULT 0,
+    e_id_2   INTEGER NOT NULL DEFAULT 0, -- int unsigned     NOT NULL DEFAULT 0,
+    ci_flags INTEGER NOT NULL DEFAULT 0, -- tinyint unsigned NOT NULL DEFAULT 0,
+    ci_time  INTEGER NOT NULL DEFAULT 0  -- int              NOT NULL DEFAULT 0
 );
 CREATE INDEX clan_invite_c_id ON clan_invite (c_id);
 CREATE INDEX clan_invite_e_id_2 ON clan_invite (e_id_2);
@@ -38,11 +38,11 @@ DROP TABLE IF EXISTS clan_message;
 CREATE TABLE clan_message
 (
     cm_id    INTEGER PRIMARY KEY,
-    ct_id    int unsigned     NOT NULL DEFAULT 0,
-    e_id     int unsigned     NOT NULL DEFAULT 0,
-    cm_body  text             NOT NULL,
-    cm_time  int              NOT NULL DEFAULT 0,
-    cm_flags tinyint unsigned NOT NULL DEFAULT 0
+    ct_id    INTEGER NOT NULL DEFAULT 0, -- int unsigned     NOT NULL DEFAULT 0,
+    e_id     INTEGER NOT NULL DEFAULT 0, -- int unsigned     NOT NULL DEFAULT 0,
+    cm_body  TEXT    NOT NULL,           -- text             NOT NULL,
+    cm_time  INTEGER NOT NULL DEFAULT 0, -- int              NOT NULL DEFAULT 0,
+    cm_flags INTEGER NOT NULL DEFAULT 0  -- tinyint unsigned NOT NULL DEFAULT 0
 );
 CREATE INDEX clan_message_e_id ON clan_message (e_id);
 CREATE INDEX clan_message_cm_time ON clan_message (cm_time);
@@ -51,12 +51,12 @@ DROP TABLE IF EXISTS clan_news;
 CREATE TABLE clan_news
 (
     cn_id    INTEGER PRIMARY KEY,
-    cn_time  int               NOT NULL DEFAULT 0,
-    c_id     int unsigned      NOT NULL DEFAULT 0,
-    e_id_1   int unsigned      NOT NULL DEFAULT 0,
-    c_id_2   int unsigned      NOT NULL DEFAULT 0,
-    e_id_2   int unsigned      NOT NULL DEFAULT 0,
-    cn_event smallint unsigned NOT NULL DEFAULT 0
+    cn_time  INTEGER NOT NULL DEFAULT 0, -- int               NOT NULL DEFAULT 0,
+    c_id     INTEGER NOT NULL DEFAULT 0, -- int unsigned      NOT NULL DEFAULT 0,
+    e_id_1   INTEGER NOT NULL DEFAULT 0, -- int unsigned      NOT NULL DEFAULT 0,
+    c_id_2   INTEGER NOT NULL DEFAULT 0, -- int unsigned      NOT NULL DEFAULT 0,
+    e_id_2   INTEGER NOT NULL DEFAULT 0, -- int unsigned      NOT NULL DEFAULT 0,
+    cn_event INTEGER NOT NULL DEFAULT 0  -- smallint unsigned NOT NULL DEFAULT 0
 );
 CREATE INDEX clan_news_c_id ON clan_news (c_id);
 CREATE INDEX clan_news_e_id_1 ON clan_news (e_id_1);
@@ -68,10 +68,10 @@ DROP TABLE IF EXISTS clan_relation;
 CREATE TABLE clan_relation
 (
     cr_id    INTEGER PRIMARY KEY,
-    c_id_1   int unsigned     NOT NULL DEFAULT 0,
-    c_id_2   int unsigned     NOT NULL DEFAULT 0,
-    cr_flags tinyint unsigned NOT NULL DEFAULT 0,
-    cr_time  int              NOT NULL DEFAULT 0
+    c_id_1   INTEGER NOT NULL DEFAULT 0, -- int unsigned     NOT NULL DEFAULT 0,
+    c_id_2   INTEGER NOT NULL DEFAULT 0, -- int unsigned     NOT NULL DEFAULT 0,
+    cr_flags INTEGER NOT NULL DEFAULT 0, -- tinyint unsigned NOT NULL DEFAULT 0,
+    cr_time  INTEGER NOT NULL DEFAULT 0  -- int              NOT NULL DEFAULT 0
 );
 CREATE INDEX clan_relation_c_id_1 ON clan_relation (c_id_1);
 CREATE INDEX clan_relation_c_id_2 ON clan_relation (c_id_2);
@@ -81,9 +81,9 @@ DROP TABLE IF EXISTS clan_topic;
 CREATE TABLE clan_topic
 (
     ct_id      INTEGER PRIMARY KEY,
-    c_id       int unsigned     NOT NULL DEFAULT 0,
-    ct_subject varchar(255)     NOT NULL DEFAULT '',
-    ct_flags   tinyint unsigned NOT NULL DEFAULT 0
+    c_id       INTEGER NOT NULL DEFAULT 0,  -- int unsigned     NOT NULL DEFAULT 0,
+    ct_subject TEXT    NOT NULL DEFAULT '', -- varchar(255)     NOT NULL DEFAULT '',
+    ct_flags   INTEGER NOT NULL DEFAULT 0   -- tinyint unsigned NOT NULL DEFAULT 0
 );
 CREATE INDEX clan_topic_c_id ON clan_topic (c_id);
 
@@ -163,9 +163,9 @@ CREATE INDEX empire_c_id ON empire (c_id);
 DROP TABLE IF EXISTS empire_effect;
 CREATE TABLE empire_effect
 (
-    e_id     int unsigned   NOT NULL DEFAULT 0,
-    ef_name  varbinary(255) NOT NULL DEFAULT '',
-    ef_value int            NOT NULL DEFAULT 0,
+    e_id     INTEGER NOT NULL DEFAULT 0,  -- int unsigned   NOT NULL DEFAULT 0,
+    ef_name  TEXT    NOT NULL DEFAULT '', -- varbinary(255) NOT NULL DEFAULT '',
+    ef_value INTEGER NOT NULL DEFAULT 0,  -- int            NOT NULL DEFAULT 0,
     PRIMARY KEY (e_id, ef_name)
 );
 
@@ -190,22 +190,22 @@ DROP TABLE IF EXISTS empire_news;
 CREATE TABLE empire_news
 (
     n_id     INTEGER PRIMARY KEY,
-    n_time   int               NOT NULL DEFAULT 0,
-    e_id_src int unsigned      NOT NULL DEFAULT 0,
-    c_id_src int unsigned      NOT NULL DEFAULT 0,
-    e_id_dst int unsigned      NOT NULL DEFAULT 0,
-    c_id_dst int unsigned      NOT NULL DEFAULT 0,
-    n_event  smallint unsigned NOT NULL DEFAULT 0,
-    n_d0     bigint            NOT NULL DEFAULT 0,
-    n_d1     bigint            NOT NULL DEFAULT 0,
-    n_d2     bigint            NOT NULL DEFAULT 0,
-    n_d3     bigint            NOT NULL DEFAULT 0,
-    n_d4     bigint            NOT NULL DEFAULT 0,
-    n_d5     bigint            NOT NULL DEFAULT 0,
-    n_d6     bigint            NOT NULL DEFAULT 0,
-    n_d7     bigint            NOT NULL DEFAULT 0,
-    n_d8     bigint            NOT NULL DEFAULT 0,
-    n_flags  tinyint unsigned  NOT NULL DEFAULT 0
+    n_time   INTEGER NOT NULL DEFAULT 0, -- int               NOT NULL DEFAULT 0,
+    e_id_src INTEGER NOT NULL DEFAULT 0, -- int unsigned      NOT NULL DEFAULT 0,
+    c_id_src INTEGER NOT NULL DEFAULT 0, -- int unsigned      NOT NULL DEFAULT 0,
+    e_id_dst INTEGER NOT NULL DEFAULT 0, -- int unsigned      NOT NULL DEFAULT 0,
+    c_id_dst INTEGER NOT NULL DEFAULT 0, -- int unsigned      NOT NULL DEFAULT 0,
+    n_event  INTEGER NOT NULL DEFAULT 0, -- smallint unsigned NOT NULL DEFAULT 0,
+    n_d0     INTEGER NOT NULL DEFAULT 0, -- bigint            NOT NULL DEFAULT 0,
+    n_d1     INTEGER NOT NULL DEFAULT 0, -- bigint            NOT NULL DEFAULT 0,
+    n_d2     INTEGER NOT NULL DEFAULT 0, -- bigint            NOT NULL DEFAULT 0,
+    n_d3     INTEGER NOT NULL DEFAULT 0, -- bigint            NOT NULL DEFAULT 0,
+    n_d4     INTEGER NOT NULL DEFAULT 0, -- bigint            NOT NULL DEFAULT 0,
+    n_d5     INTEGER NOT NULL DEFAULT 0, -- bigint            NOT NULL DEFAULT 0,
+    n_d6     INTEGER NOT NULL DEFAULT 0, -- bigint            NOT NULL DEFAULT 0,
+    n_d7     INTEGER NOT NULL DEFAULT 0, -- bigint            NOT NULL DEFAULT 0,
+    n_d8     INTEGER NOT NULL DEFAULT 0, -- bigint            NOT NULL DEFAULT 0,
+    n_flags  INTEGER NOT NULL DEFAULT 0  -- tinyint unsigned  NOT NULL DEFAULT 0
 );
 CREATE INDEX empire_news_e_id_src ON empire_news (e_id_src);
 CREATE INDEX empire_news_c_id_src ON empire_news (c_id_src);
@@ -229,23 +229,23 @@ CREATE TABLE history_clan
 DROP TABLE IF EXISTS history_empire;
 CREATE TABLE history_empire
 (
-    hr_id       smallint           NOT NULL DEFAULT 0,
-    he_flags    tinyint unsigned   NOT NULL DEFAULT 0,
-    u_id        int unsigned       NOT NULL DEFAULT 0,
-    he_id       int unsigned       NOT NULL DEFAULT 0,
-    he_name     varchar(255)       NOT NULL DEFAULT '',
-    he_race     varchar(64)        NOT NULL DEFAULT '',
-    he_era      varchar(64)        NOT NULL DEFAULT '',
-    hc_id       int unsigned       NOT NULL DEFAULT 0,
-    he_offsucc  smallint unsigned  NOT NULL DEFAULT 0,
-    he_offtotal smallint unsigned  NOT NULL DEFAULT 0,
-    he_defsucc  smallint unsigned  NOT NULL DEFAULT 0,
-    he_deftotal smallint unsigned  NOT NULL DEFAULT 0,
-    he_kills    smallint unsigned  NOT NULL DEFAULT 0,
-    he_score    int                NOT NULL DEFAULT 0,
-    he_networth bigint unsigned    NOT NULL DEFAULT 0,
-    he_land     int unsigned       NOT NULL DEFAULT 0,
-    he_rank     mediumint unsigned NOT NULL DEFAULT 0,
+    hr_id       INTEGER NOT NULL DEFAULT 0,  -- smallint           NOT NULL DEFAULT 0,
+    he_flags    INTEGER NOT NULL DEFAULT 0,  -- tinyint unsigned   NOT NULL DEFAULT 0,
+    u_id        INTEGER NOT NULL DEFAULT 0,  -- int unsigned       NOT NULL DEFAULT 0,
+    he_id       INTEGER NOT NULL DEFAULT 0,  -- int unsigned       NOT NULL DEFAULT 0,
+    he_name     TEXT    NOT NULL DEFAULT '', -- varchar(255)       NOT NULL DEFAULT '',
+    he_race     TEXT    NOT NULL DEFAULT '', -- varchar(64)        NOT NULL DEFAULT '',
+    he_era      TEXT    NOT NULL DEFAULT '', -- varchar(64)        NOT NULL DEFAULT '',
+    hc_id       INTEGER NOT NULL DEFAULT 0,  -- int unsigned       NOT NULL DEFAULT 0,
+    he_offsucc  INTEGER NOT NULL DEFAULT 0,  -- smallint unsigned  NOT NULL DEFAULT 0,
+    he_offtotal INTEGER NOT NULL DEFAULT 0,  -- smallint unsigned  NOT NULL DEFAULT 0,
+    he_defsucc  INTEGER NOT NULL DEFAULT 0,  -- smallint unsigned  NOT NULL DEFAULT 0,
+    he_deftotal INTEGER NOT NULL DEFAULT 0,  -- smallint unsigned  NOT NULL DEFAULT 0,
+    he_kills    INTEGER NOT NULL DEFAULT 0,  -- smallint unsigned  NOT NULL DEFAULT 0,
+    he_score    INTEGER NOT NULL DEFAULT 0,  -- int                NOT NULL DEFAULT 0,
+    he_networth INTEGER NOT NULL DEFAULT 0,  -- bigint unsigned    NOT NULL DEFAULT 0,
+    he_land     INTEGER NOT NULL DEFAULT 0,  -- int unsigned       NOT NULL DEFAULT 0,
+    he_rank     INTEGER NOT NULL DEFAULT 0,  -- mediumint unsigned NOT NULL DEFAULT 0,
     PRIMARY KEY (hr_id, he_id)
 );
 
@@ -293,22 +293,37 @@ CREATE TABLE log
 DROP TABLE IF EXISTS lottery;
 CREATE TABLE lottery
 (
-    e_id     int unsigned    NOT NULL DEFAULT 0,
-    l_ticket int unsigned    NOT NULL DEFAULT 0,
-    l_cash   bigint unsigned NOT NULL DEFAULT 0
+    e_id     INTEGER NOT NULL DEFAULT 0, -- int unsigned    NOT NULL DEFAULT 0,
+    l_ticket INTEGER NOT NULL DEFAULT 0, -- int unsigned    NOT NULL DEFAULT 0,
+    l_cash   INTEGER NOT NULL DEFAULT 0  -- bigint unsigned NOT NULL DEFAULT 0
 );
 CREATE INDEX lottery_e_id ON lottery (e_id);
 CREATE INDEX lottery_l_ticket ON lottery (l_ticket);
 
+DROP TABLE IF EXISTS lottery_draw;
+CREATE TABLE lottery_draw
+(
+    ld_id       INTEGER PRIMARY KEY,
+    ld_time     INTEGER NOT NULL DEFAULT 0,  -- turn interval of the drawing
+    ld_ticket   INTEGER NOT NULL DEFAULT 0,  -- the number picked
+    ld_tickets  INTEGER NOT NULL DEFAULT 0,  -- tickets purchased for the drawing
+    ld_jackpot  INTEGER NOT NULL DEFAULT 0,  -- jackpot at the time of the drawing
+    ld_increase INTEGER NOT NULL DEFAULT 0,  -- increase in the jackpot since the previous drawing
+    e_id        INTEGER NOT NULL DEFAULT 0,  -- the winning empire, zero if no one held the ticket
+    e_name      TEXT    NOT NULL DEFAULT '', -- the winning empire's name
+    ld_won      INTEGER NOT NULL DEFAULT 0   -- amount won
+);
+CREATE INDEX lottery_draw_ld_time ON lottery_draw (ld_time);
+
 DROP TABLE IF EXISTS market;
 CREATE TABLE market
 (
     k_id    INTEGER PRIMARY KEY,
-    k_type  tinyint unsigned NOT NULL DEFAULT 0,
-    e_id    int unsigned     NOT NULL DEFAULT 0,
-    k_amt   bigint unsigned  NOT NULL DEFAULT 0,
-    k_price int unsigned     NOT NULL DEFAULT 0,
-    k_time  int              NOT NULL DEFAULT 0
+    k_type  INTEGER NOT NULL DEFAULT 0, -- tinyint unsigned NOT NULL DEFAULT 0,
+    e_id    INTEGER NOT NULL DEFAULT 0, -- int unsigned     NOT NULL DEFAULT 0,
+    k_amt   INTEGER NOT NULL DEFAULT 0, -- bigint unsigned  NOT NULL DEFAULT 0,
+    k_price INTEGER NOT NULL DEFAULT 0, -- int unsigned     NOT NULL DEFAULT 0,
+    k_time  INTEGER NOT NULL DEFAULT 0  -- int              NOT NULL DEFAULT 0
 );
 CREATE INDEX market_e_id ON market (e_id);
 CREATE INDEX market_k_type ON market (k_type);
@@ -345,13 +360,15 @@ DROP TABLE IF EXISTS turnlog;
 CREATE TABLE turnlog
 (
     turn_id       INTEGER PRIMARY KEY,
-    turn_time     int unsigned     NOT NULL DEFAULT 0,
-    turn_ticks    int unsigned     NOT NULL DEFAULT 0,
-    turn_interval int unsigned     NOT NULL DEFAULT 0,
-    turn_type     tinyint unsigned NOT NULL DEFAULT 0,
-    turn_text     text             NOT NULL
+    turn_time     INTEGER NOT NULL DEFAULT 0, -- int unsigned     NOT NULL DEFAULT 0,
+    turn_ticks    INTEGER NOT NULL DEFAULT 0, -- int unsigned     NOT NULL DEFAULT 0,
+    turn_interval INTEGER NOT NULL DEFAULT 0, -- int unsigned     NOT NULL DEFAULT 0,
+    turn_type     INTEGER NOT NULL DEFAULT 0, -- tinyint unsigned NOT NULL DEFAULT 0,
+    turn_updates  INTEGER NOT NULL DEFAULT 0, -- TURNS_NEED_* flags for the interval being processed
+    turn_text     TEXT    NOT NULL            -- text             NOT NULL
 );
 CREATE INDEX turnlog_turn_type ON turnlog (turn_type);
+CREATE INDEX turnlog_turn_time ON turnlog (turn_time);
 
 DROP TABLE IF EXISTS users;
 CREATE TABLE users
@@ -396,8 +413,9 @@ CREATE TABLE var
 DROP TABLE IF EXISTS var_adjust;
 CREATE TABLE var_adjust
 (
-    v_name   varbinary(255) NOT NULL DEFAULT '',
-    v_offset bigint         NOT NULL DEFAULT 0
+    va_id    INTEGER PRIMARY KEY,         -- orders the adjustments so that only those applied are removed
+    v_name   TEXT    NOT NULL DEFAULT '', -- varbinary(255) NOT NULL DEFAULT '',
+    v_offset INTEGER NOT NULL DEFAULT 0   -- bigint         NOT NULL DEFAULT 0
 );
 
 DROP TABLE IF EXISTS world_vars;
@@ -119,10 +119,15 @@ SET lotto_current_jackpot   = ?,
     turns_next_daily        = ?;
 
 -- name: EmpireCreate :one
-INSERT INTO empire (u_id, e_name, e_race)
-VALUES (?, ?, ?)
+INSERT INTO empire (u_id, e_name, e_race, e_idle)
+VALUES (?, ?, ?, ?)
 RETURNING e_id;
 
+-- name: EmpireIdleUpdate :exec
+UPDATE empire
+SET e_idle = CAST(sqlc.arg(idle) AS INTEGER)
+WHERE e_id = CAST(sqlc.arg(e_id) AS INTEGER);
+
 -- name: EmpireActiveUserCount :one
 SELECT COUNT(*)
 FROM empire
@@ -212,6 +217,8 @@ SET e_flags       = ?,
     e_idle        = ?,
     e_era         = ?,
     e_rank        = ?,
+    c_id          = ?,
+    c_oldid       = ?,
     e_sharing     = ?,
     e_attacks     = ?,
     e_offsucc     = ?,
@@ -308,3 +315,617 @@ DELETE
 FROM session
 WHERE sess_uid = ?
    OR sess_expires_at < datetime('now');
+
+-- name: EmpireActivePlayerCount :one
+SELECT COUNT(*)
+FROM empire
+WHERE u_id != 0
+  AND IFNULL(e_flags, 0) & CAST(sqlc.arg(flags) AS INTEGER) = 0;
+
+-- name: EmpiresGiveTurns :exec
+UPDATE empire
+SET e_turns       = IFNULL(e_turns, 0) + CAST(sqlc.arg(turns) AS INTEGER) +
+                    MIN(IFNULL(e_storedturns, 0), CAST(sqlc.arg(unstore) AS INTEGER)),
+    e_storedturns = IFNULL(e_storedturns, 0) - MIN(IFNULL(e_storedturns, 0), CAST(sqlc.arg(unstore) AS INTEGER))
+WHERE IFNULL(e_vacation, 0) = 0
+  AND IFNULL(e_flags, 0) & CAST(sqlc.arg(flags) AS INTEGER) = 0
+  AND u_id != 0;
+
+-- name: EmpiresStoreTurns :exec
+UPDATE empire
+SET e_storedturns = MIN(CAST(sqlc.arg(max_stored) AS INTEGER),
+                        IFNULL(e_storedturns, 0) + e_turns - CAST(sqlc.arg(max_turns) AS INTEGER)),
+    e_turns       = CAST(sqlc.arg(max_turns) AS INTEGER)
+WHERE e_turns > CAST(sqlc.arg(max_turns) AS INTEGER)
+  AND u_id != 0;
+
+-- name: EmpiresDecrementAttacks :exec
+UPDATE empire
+SET e_attacks = e_attacks - 1
+WHERE e_attacks > 0
+  AND u_id != 0;
+
+-- name: EmpiresRecoverAttacks :exec
+UPDATE empire
+SET e_attacks = e_attacks + 1
+WHERE e_attacks < 0
+  AND u_id != 0;
+
+-- name: EmpiresDecrementSharing :exec
+UPDATE empire
+SET e_sharing = e_sharing - 1
+WHERE e_sharing > 0
+  AND u_id != 0;
+
+-- name: EmpiresReducePrivateMarketSales :exec
+UPDATE empire
+SET e_mktperarm = e_mktperarm - MIN(e_mktperarm, CAST(ROUND(100 * (1 + CAST(e_bldcash AS REAL) / e_land)) AS INTEGER)),
+    e_mktperlnd = e_mktperlnd - MIN(e_mktperlnd, CAST(ROUND(100 * (1 + CAST(e_bldcash AS REAL) / e_land)) AS INTEGER)),
+    e_mktperfly = e_mktperfly - MIN(e_mktperfly, CAST(ROUND(100 * (1 + CAST(e_bldcash AS REAL) / e_land)) AS INTEGER)),
+    e_mktpersea = e_mktpersea - MIN(e_mktpersea, CAST(ROUND(100 * (1 + CAST(e_bldcash AS REAL) / e_land)) AS INTEGER))
+WHERE u_id != 0
+  AND e_land != 0;
+
+-- name: EmpiresRefillPrivateMarket :exec
+UPDATE empire
+SET e_mktarm  = CASE WHEN IFNULL(e_mktarm, 0) / 250 < e_land + 2 * e_bldcost THEN IFNULL(e_mktarm, 0) + 8 * (e_land + e_bldcost) ELSE e_mktarm END,
+    e_mktlnd  = CASE WHEN IFNULL(e_mktlnd, 0) / 200 < e_land + 2 * e_bldcost THEN IFNULL(e_mktlnd, 0) + 5 * (e_land + e_bldcost) ELSE e_mktlnd END,
+    e_mktfly  = CASE WHEN IFNULL(e_mktfly, 0) / 180 < e_land + 2 * e_bldcost THEN IFNULL(e_mktfly, 0) + 3 * (e_land + e_bldcost) ELSE e_mktfly END,
+    e_mktsea  = CASE WHEN IFNULL(e_mktsea, 0) / 150 < e_land + 2 * e_bldcost THEN IFNULL(e_mktsea, 0) + 2 * (e_land + e_bldcost) ELSE e_mktsea END,
+    e_mktfood = CASE WHEN IFNULL(e_mktfood, 0) / 2000 < e_land + 2 * e_bldfood THEN IFNULL(e_mktfood, 0) + 50 * (e_land + e_bldfood) ELSE e_mktfood END
+WHERE u_id != 0;
+
+-- name: EmpiresAccrueScore :exec
+UPDATE empire
+SET e_score = CAST(ROUND(e_score + SQRT(e_networth / 1250000.0)) AS INTEGER)
+WHERE e_vacation = 0
+  AND e_flags & CAST(sqlc.arg(flags) AS INTEGER) = 0
+  AND u_id != 0;
+
+-- name: EmpiresIncrementVacation :exec
+UPDATE empire
+SET e_vacation = e_vacation + 1
+WHERE e_vacation > 0
+  AND u_id != 0;
+
+-- name: EmpiresEndVacation :exec
+UPDATE empire
+SET e_vacation = 0,
+    e_idle     = CAST(sqlc.arg(idle) AS INTEGER)
+WHERE e_vacation >= CAST(sqlc.arg(vacation) AS INTEGER)
+  AND u_id != 0;
+
+-- name: EmpiresResetIdle :exec
+UPDATE empire
+SET e_idle = CAST(sqlc.arg(idle) AS INTEGER);
+
+-- name: EmpiresFlushOnline :exec
+UPDATE empire
+SET e_flags = IFNULL(e_flags, 0) & ~CAST(sqlc.arg(online) AS INTEGER)
+WHERE IFNULL(e_idle, 0) < CAST(sqlc.arg(idle) AS INTEGER)
+   OR u_id = 0;
+
+-- name: EmpiresClearOnline :exec
+UPDATE empire
+SET e_flags = IFNULL(e_flags, 0) & ~CAST(sqlc.arg(online) AS INTEGER);
+
+-- name: TurnlogInsert :exec
+INSERT INTO turnlog (turn_time, turn_ticks, turn_interval, turn_type, turn_updates, turn_text)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: TurnlogFetch :many
+SELECT turn_id, turn_time, turn_ticks, turn_interval, turn_type, turn_updates, turn_text
+FROM turnlog
+WHERE (CAST(sqlc.arg(any_type) AS INTEGER) != 0 OR turn_type = CAST(sqlc.arg(turn_type) AS INTEGER))
+  AND turn_updates & CAST(sqlc.arg(updates) AS INTEGER) = CAST(sqlc.arg(updates) AS INTEGER)
+  AND turn_time >= CAST(sqlc.arg(time_from) AS INTEGER)
+  AND turn_time < CAST(sqlc.arg(time_to) AS INTEGER)
+ORDER BY turn_id DESC
+LIMIT CAST(sqlc.arg(max_rows) AS INTEGER);
+
+-- name: EmpireEffectsFetch :many
+SELECT e_id, ef_name, ef_value
+FROM empire_effect
+WHERE e_id = ?
+ORDER BY ef_name;
+
+-- name: EmpireEffectUpsert :exec
+INSERT INTO empire_effect (e_id, ef_name, ef_value)
+VALUES (?, ?, ?)
+ON CONFLICT (e_id, ef_name) DO UPDATE SET ef_value = excluded.ef_value;
+
+-- name: EmpireEffectDelete :exec
+DELETE
+FROM empire_effect
+WHERE e_id = ?
+  AND ef_name = ?;
+
+-- name: EmpireEffectsCleanupTime :execrows
+DELETE
+FROM empire_effect
+WHERE substr(ef_name, 1, 2) = 'm_'
+  AND ef_value <= CAST(sqlc.arg(now) AS INTEGER);
+
+-- name: EmpireEffectsCleanupTurn :execrows
+DELETE
+FROM empire_effect
+WHERE substr(ef_name, 1, 2) = 'r_'
+  AND ef_value <= 0;
+
+-- name: EmpireEffectsCleanupPerm :execrows
+DELETE
+FROM empire_effect
+WHERE substr(ef_name, 1, 2) = 'p_'
+  AND ef_value = 0;
+
+-- name: EmpirePlayerIdsFetch :many
+SELECT e_id
+FROM empire
+WHERE u_id != 0
+ORDER BY e_id;
+
+-- name: EmpireNetworthUpdate :exec
+UPDATE empire
+SET e_networth = ?
+WHERE e_id = ?;
+
+-- name: EmpireRankUpdate :exec
+UPDATE empire
+SET e_rank = ?
+WHERE e_id = ?;
+
+-- name: EmpiresClearUnownedRank :exec
+UPDATE empire
+SET e_rank = 0
+WHERE u_id = 0;
+
+-- name: EmpiresRankByNetworth :many
+SELECT e_id
+FROM empire
+WHERE u_id != 0
+ORDER BY e_networth DESC, e_id;
+
+-- name: EmpiresRankByScore :many
+SELECT e_id
+FROM empire
+WHERE u_id != 0
+ORDER BY e_score DESC, e_networth DESC, e_id;
+
+-- name: HistoryEmpireMaxRanks :many
+SELECT hr_id, CAST(MAX(he_rank) AS INTEGER) AS maxrank
+FROM history_empire
+GROUP BY hr_id;
+
+-- name: HistoryEmpireUserRanks :many
+SELECT hr_id, CAST(MIN(he_rank) AS INTEGER) AS myrank
+FROM history_empire
+WHERE u_id = ?
+GROUP BY hr_id;
+
+-- name: UsersRankingsFetch :many
+SELECT u_id, u_numplays, u_avgrank, u_bestrank, u_sucplays
+FROM users
+ORDER BY u_id;
+
+-- name: UserRankingsUpdate :exec
+UPDATE users
+SET u_avgrank  = ?,
+    u_bestrank = ?,
+    u_sucplays = ?
+WHERE u_id = ?;
+
+-- name: EmpireNewsCreate :exec
+INSERT INTO empire_news (n_time, e_id_src, c_id_src, e_id_dst, c_id_dst, n_event,
+                         n_d0, n_d1, n_d2, n_d3, n_d4, n_d5, n_d6, n_d7, n_d8)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: EmpireNewsAttachmentsLock :exec
+UPDATE empire_news
+SET n_flags = n_flags | CAST(sqlc.arg(lock) AS INTEGER)
+WHERE e_id_dst = CAST(sqlc.arg(e_id) AS INTEGER)
+  AND n_flags & CAST(sqlc.arg(gotten) AS INTEGER) = 0
+  AND n_event BETWEEN CAST(sqlc.arg(first) AS INTEGER) AND CAST(sqlc.arg(last) AS INTEGER);
+
+-- name: EmpireNewsLockedFetch :many
+SELECT n_id, e_id_src, c_id_src, e_id_dst, c_id_dst, n_event, n_d0, n_d1, n_d2, n_d3, n_d4, n_d5, n_d6, n_d7, n_d8
+FROM empire_news
+WHERE e_id_dst = CAST(sqlc.arg(e_id) AS INTEGER)
+  AND n_flags & CAST(sqlc.arg(lock) AS INTEGER) != 0
+ORDER BY n_time, n_id;
+
+-- name: EmpireNewsLockedGotten :exec
+UPDATE empire_news
+SET n_flags = (n_flags | CAST(sqlc.arg(gotten) AS INTEGER)) & ~CAST(sqlc.arg(lock) AS INTEGER)
+WHERE e_id_dst = CAST(sqlc.arg(e_id) AS INTEGER)
+  AND n_flags & CAST(sqlc.arg(lock) AS INTEGER) != 0;
+
+-- name: MarketItemCreate :one
+INSERT INTO market (k_type, e_id, k_amt, k_price, k_time)
+VALUES (?, ?, ?, ?, ?)
+RETURNING k_id;
+
+-- name: MarketItemFetch :many
+SELECT k_id, k_type, e_id, k_amt, k_price, k_time
+FROM market
+WHERE k_id = ?
+  AND e_id = ?;
+
+-- name: MarketItemAmountUpdate :exec
+UPDATE market
+SET k_amt = ?
+WHERE k_id = ?;
+
+-- name: MarketItemDelete :exec
+DELETE
+FROM market
+WHERE k_id = ?;
+
+-- name: MarketItemsForSale :many
+SELECT k_id, k_type, e_id, k_amt, k_price, k_time
+FROM market
+WHERE k_type = ?
+  AND k_price <= ?
+  AND e_id != ?
+  AND k_time <= ?
+  AND k_amt > 0
+ORDER BY k_price, k_time, k_id;
+
+-- name: MarketEmpireItemsFetch :many
+SELECT k_id, k_type, e_id, k_amt, k_price, k_time
+FROM market
+WHERE e_id = ?
+ORDER BY k_id;
+
+-- name: MarketPricesFetch :many
+SELECT k_type, k_price, CAST(SUM(k_amt) AS INTEGER) AS k_amt
+FROM market
+WHERE e_id != ?
+  AND k_time <= ?
+GROUP BY k_type, k_price
+ORDER BY k_type, k_price;
+
+-- name: MarketEmpireItemsDelete :exec
+DELETE
+FROM market
+WHERE e_id = ?;
+
+-- name: MarketExpiredFetch :many
+SELECT k_id, k_type, e_id, k_amt, k_price, k_time
+FROM market
+WHERE k_time <= ?
+ORDER BY k_id;
+
+-- name: MarketExpiredDelete :execrows
+DELETE
+FROM market
+WHERE k_time <= ?;
+
+-- name: EmpireCount :one
+SELECT COUNT(*)
+FROM empire;
+
+-- name: LotteryClear :exec
+DELETE
+FROM lottery;
+
+-- name: LotteryDrawCreate :exec
+INSERT INTO lottery_draw (ld_time, ld_ticket, ld_tickets, ld_jackpot, ld_increase, e_id, e_name, ld_won)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: LotteryDraws :many
+SELECT *
+FROM lottery_draw
+ORDER BY ld_time DESC, ld_id DESC
+LIMIT ?;
+
+-- name: LotteryEmpireTickets :many
+SELECT l_ticket
+FROM lottery
+WHERE e_id = ?
+ORDER BY l_ticket;
+
+-- name: LotteryEmpireTicketsDelete :exec
+DELETE
+FROM lottery
+WHERE e_id = ?;
+
+-- name: LotteryTicketCreate :exec
+INSERT INTO lottery (e_id, l_ticket, l_cash)
+VALUES (?, ?, ?);
+
+-- name: LotteryTicketHolder :one
+SELECT CAST(IFNULL(MAX(e_id), 0) AS INTEGER) AS e_id
+FROM lottery
+WHERE e_id > 0
+  AND l_ticket = ?;
+
+-- name: LotteryTicketsSold :one
+SELECT COUNT(*)
+FROM lottery
+WHERE e_id != 0;
+
+-- name: VarAdjustCreate :exec
+INSERT INTO var_adjust (v_name, v_offset)
+VALUES (?, ?);
+
+-- name: VarAdjustDelete :exec
+DELETE
+FROM var_adjust
+WHERE va_id <= ?;
+
+-- name: VarAdjustFetch :many
+SELECT va_id, v_name, v_offset
+FROM var_adjust
+ORDER BY va_id;
+
+-- name: ClanRelationsFetch :many
+SELECT cr_id, c_id_1, c_id_2, cr_flags, cr_time
+FROM clan_relation
+WHERE c_id_1 = CAST(sqlc.arg(c_id) AS INTEGER)
+   OR c_id_2 = CAST(sqlc.arg(c_id) AS INTEGER)
+ORDER BY cr_id;
+
+-- name: EmpireNewsClanAidFetch :many
+SELECT n_id, n_time, e_id_src, e_id_dst, n_d0, n_d1, n_d2, n_d3, n_d4, n_d5, n_d6, n_d7
+FROM empire_news
+WHERE c_id_src = ?
+  AND n_event = ?
+ORDER BY n_id;
+
+-- name: ClanCreate :one
+INSERT INTO clan (c_name, c_password, c_members, e_id_leader, c_title, c_url, c_pic)
+VALUES (?, ?, ?, ?, ?, '', '')
+RETURNING c_id;
+
+-- name: ClanUpdate :exec
+UPDATE clan
+SET c_name      = ?,
+    c_password  = ?,
+    c_members   = ?,
+    e_id_leader = ?,
+    e_id_asst   = ?,
+    e_id_fa1    = ?,
+    e_id_fa2    = ?,
+    c_title     = ?,
+    c_url       = ?,
+    c_pic       = ?
+WHERE c_id = ?;
+
+-- name: ClanNameCount :one
+SELECT COUNT(*)
+FROM clan
+WHERE c_name = ?
+  AND c_members >= 0;
+
+-- name: ClansFetch :many
+SELECT c_id,
+       c_name,
+       c_password,
+       c_members,
+       e_id_leader,
+       e_id_asst,
+       e_id_fa1,
+       e_id_fa2,
+       c_title,
+       c_url,
+       c_pic
+FROM clan
+WHERE c_members > 0
+ORDER BY c_id;
+
+-- name: ClanMembersFetch :many
+SELECT e_id, e_name, e_flags, e_land, e_networth, e_sharing, e_trparm, e_trplnd, e_trpfly, e_trpsea
+FROM empire
+WHERE c_id = CAST(sqlc.arg(c_id) AS INTEGER)
+ORDER BY e_networth DESC, e_id;
+
+-- name: ClanNewsCreate :exec
+INSERT INTO clan_news (cn_time, c_id, e_id_1, c_id_2, e_id_2, cn_event)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: ClanNewsFetch :many
+SELECT cn_id, cn_time, c_id, e_id_1, c_id_2, e_id_2, cn_event
+FROM clan_news
+WHERE c_id = ?
+  AND cn_time > ?
+ORDER BY cn_id;
+
+-- name: ClanTopicCreate :one
+INSERT INTO clan_topic (c_id, ct_subject, ct_flags)
+VALUES (?, ?, ?)
+RETURNING ct_id;
+
+-- name: ClanMessageCreate :one
+INSERT INTO clan_message (ct_id, e_id, cm_body, cm_time, cm_flags)
+VALUES (?, ?, ?, ?, ?)
+RETURNING cm_id;
+
+-- name: ClanInviteRecipientsFetch :many
+SELECT e_id_2
+FROM clan_invite
+WHERE c_id = ?
+ORDER BY ci_id;
+
+-- name: ClanInvitesDelete :exec
+DELETE
+FROM clan_invite
+WHERE c_id = ?;
+
+-- name: ClanInvitesDeleteTemporary :exec
+DELETE
+FROM clan_invite
+WHERE e_id_2 = ?
+  AND ci_flags & CAST(sqlc.arg(flags) AS INTEGER) = 0;
+
+-- name: EmpiresUnlinkableFetch :many
+SELECT e_id
+FROM empire
+WHERE u_id != 0
+  AND IFNULL(e_flags, 0) & CAST(sqlc.arg(admin) AS INTEGER) = 0
+  AND ((IFNULL(e_flags, 0) & CAST(sqlc.arg(valid_notify) AS INTEGER) = 0 AND IFNULL(e_idle, 0) < CAST(sqlc.arg(new_idle) AS INTEGER))
+    OR (IFNULL(e_flags, 0) & CAST(sqlc.arg(valid_notify) AS INTEGER) = CAST(sqlc.arg(notify) AS INTEGER) AND IFNULL(e_idle, 0) < CAST(sqlc.arg(validate_idle) AS INTEGER))
+    OR (IFNULL(e_flags, 0) & CAST(sqlc.arg(disable) AS INTEGER) = 0 AND IFNULL(e_vacation, 0) = 0 AND IFNULL(e_land, 0) > 0 AND IFNULL(e_idle, 0) < CAST(sqlc.arg(abandon_idle) AS INTEGER))
+    OR (IFNULL(e_land, 0) = 0 AND (IFNULL(e_flags, 0) & CAST(sqlc.arg(notify) AS INTEGER) = CAST(sqlc.arg(notify) AS INTEGER) OR IFNULL(e_idle, 0) < CAST(sqlc.arg(killed_idle) AS INTEGER)))
+    OR (IFNULL(e_flags, 0) & CAST(sqlc.arg(delete) AS INTEGER) = CAST(sqlc.arg(delete) AS INTEGER) AND (IFNULL(e_turnsused, 0) <= CAST(sqlc.arg(protection) AS INTEGER) OR IFNULL(e_idle, 0) < CAST(sqlc.arg(delete_idle) AS INTEGER))))
+ORDER BY e_id;
+
+-- name: EmpireUnlink :exec
+UPDATE empire
+SET u_oldid = u_id,
+    u_id    = 0
+WHERE e_id = ?;
+
+-- name: EmpireMessagesFlagReceived :exec
+UPDATE empire_message
+SET m_flags = m_flags | CAST(sqlc.arg(flags) AS INTEGER)
+WHERE e_id_dst = CAST(sqlc.arg(e_id) AS INTEGER);
+
+-- name: EmpireMessagesFlagSent :exec
+UPDATE empire_message
+SET m_flags = m_flags | CAST(sqlc.arg(flags) AS INTEGER)
+WHERE e_id_src = CAST(sqlc.arg(e_id) AS INTEGER);
+
+-- name: ClanRelationFetch :one
+SELECT cr_id, c_id_1, c_id_2, cr_flags, cr_time
+FROM clan_relation
+WHERE cr_id = ?;
+
+-- name: ClanRelationCreate :one
+INSERT INTO clan_relation (c_id_1, c_id_2, cr_flags, cr_time)
+VALUES (?, ?, ?, ?)
+RETURNING cr_id;
+
+-- name: ClanRelationUpdate :exec
+UPDATE clan_relation
+SET c_id_1   = ?,
+    c_id_2   = ?,
+    cr_flags = ?,
+    cr_time  = ?
+WHERE cr_id = ?;
+
+-- name: ClanRelationDelete :exec
+DELETE
+FROM clan_relation
+WHERE cr_id = ?;
+
+-- name: ClanRelationsDelete :exec
+DELETE
+FROM clan_relation
+WHERE c_id_1 = CAST(sqlc.arg(c_id) AS INTEGER)
+   OR c_id_2 = CAST(sqlc.arg(c_id) AS INTEGER);
+
+-- name: ClansEmptyFetch :many
+SELECT c_id,
+       c_name,
+       c_password,
+       c_members,
+       e_id_leader,
+       e_id_asst,
+       e_id_fa1,
+       e_id_fa2,
+       c_title,
+       c_url,
+       c_pic
+FROM clan
+WHERE c_members = 0
+ORDER BY c_id;
+
+-- name: ClanInviteFetch :one
+SELECT ci_id, c_id, e_id_1, e_id_2, ci_flags, ci_time
+FROM clan_invite
+WHERE ci_id = ?;
+
+-- name: ClanInviteFind :one
+SELECT ci_id, c_id, e_id_1, e_id_2, ci_flags, ci_time
+FROM clan_invite
+WHERE c_id = ?
+  AND e_id_2 = ?
+ORDER BY ci_id
+LIMIT 1;
+
+-- name: ClanInviteCreate :one
+INSERT INTO clan_invite (c_id, e_id_1, e_id_2, ci_flags, ci_time)
+VALUES (?, ?, ?, ?, ?)
+RETURNING ci_id;
+
+-- name: ClanInviteUpdate :exec
+UPDATE clan_invite
+SET e_id_1   = ?,
+    ci_flags = ?,
+    ci_time  = ?
+WHERE ci_id = ?;
+
+-- name: ClanInviteDelete :exec
+DELETE
+FROM clan_invite
+WHERE ci_id = ?;
+
+-- name: ClanInvitesFetch :many
+SELECT ci_id, c_id, e_id_1, e_id_2, ci_flags, ci_time
+FROM clan_invite
+WHERE c_id = ?
+ORDER BY ci_id;
+
+-- name: ClanInvitesReceivedFetch :many
+SELECT ci_id, c_id, e_id_1, e_id_2, ci_flags, ci_time
+FROM clan_invite
+WHERE e_id_2 = ?
+ORDER BY ci_id;
+
+-- name: ClanInvitesDeleteExpired :execrows
+DELETE
+FROM clan_invite
+WHERE ci_flags & CAST(sqlc.arg(flags) AS INTEGER) = 0
+  AND ci_time <= ?;
+
+-- name: ClanTopicFetch :one
+SELECT ct_id, c_id, ct_subject, ct_flags
+FROM clan_topic
+WHERE ct_id = ?;
+
+-- name: ClanTopicUpdate :exec
+UPDATE clan_topic
+SET ct_subject = ?,
+    ct_flags   = ?
+WHERE ct_id = ?;
+
+-- name: ClanTopicsFetch :many
+SELECT ct_id, c_id, ct_subject, ct_flags
+FROM clan_topic
+WHERE c_id = ?
+  AND ct_flags & CAST(sqlc.arg(flags) AS INTEGER) = 0
+ORDER BY ct_id;
+
+-- name: ClanMessageFetch :one
+SELECT cm_id, ct_id, e_id, cm_body, cm_time, cm_flags
+FROM clan_message
+WHERE cm_id = ?;
+
+-- name: ClanMessageUpdate :exec
+UPDATE clan_message
+SET cm_body  = ?,
+    cm_time  = ?,
+    cm_flags = ?
+WHERE cm_id = ?;
+
+-- name: ClanMessagesFetch :many
+SELECT cm_id, ct_id, e_id, cm_body, cm_time, cm_flags
+FROM clan_message
+WHERE ct_id = ?
+  AND cm_flags & CAST(sqlc.arg(flags) AS INTEGER) = 0
+ORDER BY cm_id;
+
+-- name: ClanMessagesDelete :exec
+UPDATE clan_message
+SET cm_flags = cm_flags | CAST(sqlc.arg(flags) AS INTEGER)
+WHERE ct_id = ?;
+
+-- name: ClanSharingFetch :many
+SELECT e_id
+FROM empire
+WHERE e_id != CAST(sqlc.arg(e_id) AS INTEGER)
+  AND c_id = CAST(sqlc.arg(c_id) AS INTEGER)
+  AND IFNULL(e_sharing, 0) != 0
+  AND IFNULL(e_land, 0) > 0
+  AND IFNULL(e_flags, 0) & CAST(sqlc.arg(flags) AS INTEGER) = 0
+ORDER BY e_id;
@@ -0,0 +1,78 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package orm
+
+import (
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/orm/sqlc"
+	"math"
+	"time"
+)
+
+// TurnlogFetch returns turn log entries, newest first.
+// If kind is negative, entries of all types are returned.
+// If updates is not zero, only entries logged while processing all of those TURNS_NEED_* flags are returned.
+// Zero values for from and to leave that end of the date range open.
+func (db *DB) TurnlogFetch(kind, updates int, from, to time.Time, limit int) ([]*model.TurnLog_t, error) {
+	parms := sqlc.TurnlogFetchParams{
+		TurnType: int64(kind),
+		Updates:  int64(updates),
+		TimeFrom: 0,
+		TimeTo:   math.MaxInt64,
+		MaxRows:  int64(limit),
+	}
+	if kind < 0 {
+		parms.AnyType = 1
+	}
+	if !from.IsZero() {
+		parms.TimeFrom = from.Unix()
+	}
+	if !to.IsZero() {
+		parms.TimeTo = to.Unix()
+	}
+	rows, err := db.db.TurnlogFetch(db.ctx, parms)
+	if err != nil {
+		return nil, err
+	}
+	var entries []*model.TurnLog_t
+	for _, row := range rows {
+		entry := &model.TurnLog_t{
+			Id:      int(row.TurnID),
+			Time:    time.Unix(row.TurnTime, row.TurnTicks*1_000).UTC(),
+			Type:    int(row.TurnType),
+			Updates: int(row.TurnUpdates),
+			Text:    row.TurnText,
+		}
+		if row.TurnInterval != 0 {
+			entry.Interval = time.Unix(row.TurnInterval, 0).UTC()
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// TurnlogInsert adds entries to the turn log.
+func (db *DB) TurnlogInsert(entries ...*model.TurnLog_t) error {
+	for _, entry := range entries {
+		err := db.db.TurnlogInsert(db.ctx, sqlc.TurnlogInsertParams{
+			TurnTime:     entry.Time.Unix(),
+			TurnTicks:    int64(entry.Time.Nanosecond() / 1_000),
+			TurnInterval: unixOrZero(entry.Interval),
+			TurnType:     int64(entry.Type),
+			TurnUpdates:  int64(entry.Updates),
+			TurnText:     entry.Text,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unixOrZero returns the Unix time of t, or zero if t is the zero time.
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package orm
+
+import (
+	"github.com/mdhender/promisance/app/model"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestTurnlogFetch(t *testing.T) {
+	db, err := CreateMemoryDatabase()
+	if err != nil {
+		t.Fatalf("database: %v", err)
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+
+	// two turn runs a day apart, the second of which also did the daily update
+	day := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	next := day.Add(24 * time.Hour)
+	if err := db.TurnlogInsert(
+		&model.TurnLog_t{Time: day, Type: 1, Text: "start 1"},
+		&model.TurnLog_t{Time: day.Add(time.Second), Interval: day, Type: 0, Updates: 1, Text: "event 1"},
+		&model.TurnLog_t{Time: day.Add(2 * time.Second), Type: 2, Text: "end 1"},
+		&model.TurnLog_t{Time: next, Type: 1, Text: "start 2"},
+		&model.TurnLog_t{Time: next.Add(time.Second), Interval: next, Type: 0, Updates: 1 | 4, Text: "event 2"},
+		&model.TurnLog_t{Time: next.Add(2 * time.Second), Type: 2, Text: "end 2"},
+	); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+
+	for _, tc := range []struct {
+		id       string
+		kind     int
+		updates  int
+		from, to time.Time
+		limit    int
+		want     []string
+	}{
+		{"all", -1, 0, time.Time{}, time.Time{}, 10, []string{"end 2", "event 2", "start 2", "end 1", "event 1", "start 1"}},
+		{"limit", -1, 0, time.Time{}, time.Time{}, 2, []string{"end 2", "event 2"}},
+		{"type", 1, 0, time.Time{}, time.Time{}, 10, []string{"start 2", "start 1"}},
+		{"updates", -1, 1, time.Time{}, time.Time{}, 10, []string{"event 2", "event 1"}},
+		{"daily", -1, 4, time.Time{}, time.Time{}, 10, []string{"event 2"}},
+		{"from", -1, 0, next, time.Time{}, 10, []string{"end 2", "event 2", "start 2"}},
+		{"to", -1, 0, time.Time{}, next, 10, []string{"end 1", "event 1", "start 1"}},
+		{"range", 0, 0, day.Add(time.Second), next.Add(2 * time.Second), 10, []string{"event 2", "event 1"}},
+		{"empty range", -1, 0, next.Add(time.Hour), time.Time{}, 10, nil},
+	} {
+		entries, err := db.TurnlogFetch(tc.kind, tc.updates, tc.from, tc.to, tc.limit)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.id, err)
+		}
+		var got []string
+		for _, entry := range entries {
+			got = append(got, entry.Text)
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("%s: want %v, got %v", tc.id, tc.want, got)
+		}
+	}
+
+	// the interval and sub-second times are kept
+	entries, err := db.TurnlogFetch(0, 4, time.Time{}, time.Time{}, 1)
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	} else if len(entries) != 1 || !entries[0].Interval.Equal(next) || !entries[0].Time.Equal(next.Add(time.Second)) {
+		t.Errorf("fetch: got %+v", entries)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package orm
+
+import (
+	"github.com/mdhender/promisance/app/orm/sqlc"
+	"time"
+)
+
+// EmpireActivePlayerCount returns the number of empires that are linked
+// to a user and are not administrators.
+func (db *DB) EmpireActivePlayerCount() (int, error) {
+	count, err := db.db.EmpireActivePlayerCount(db.ctx, EFLAG_ADMIN)
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+// EmpiresAccrueScore adds the square root of networth over 1,250,000 to the score
+// of every player's empire that is neither on vacation nor disabled.
+func (db *DB) EmpiresAccrueScore() error {
+	return db.db.EmpiresAccrueScore(db.ctx, EFLAG_DISABLE)
+}
+
+// EmpiresClearOnline marks every empire as being offline.
+func (db *DB) EmpiresClearOnline() error {
+	return db.db.EmpiresClearOnline(db.ctx, EFLAG_ONLINE)
+}
+
+// EmpiresDecrementAttacks reduces the recent attack counter on every empire that has attacked.
+func (db *DB) EmpiresDecrementAttacks() error {
+	return db.db.EmpiresDecrementAttacks(db.ctx)
+}
+
+// EmpiresDecrementSharing counts down the delay on empires that are unsharing clan forces.
+func (db *DB) EmpiresDecrementSharing() error {
+	return db.db.EmpiresDecrementSharing(db.ctx)
+}
+
+// EmpiresEndVacation takes empires off of vacation once they have been on it for at least the given number of hours.
+func (db *DB) EmpiresEndVacation(now time.Time, hours int) error {
+	return db.db.EmpiresEndVacation(db.ctx, sqlc.EmpiresEndVacationParams{
+		Idle:     now.Unix(),
+		Vacation: int64(hours),
+	})
+}
+
+// EmpiresFlushOnline marks empires as being offline if they have been idle since before the cutoff
+// or if they have been unlinked from their user.
+func (db *DB) EmpiresFlushOnline(cutoff time.Time) error {
+	return db.db.EmpiresFlushOnline(db.ctx, sqlc.EmpiresFlushOnlineParams{
+		Online: EFLAG_ONLINE,
+		Idle:   cutoff.Unix(),
+	})
+}
+
+// EmpiresGiveTurns gives turns to every empire that is not on vacation or disabled.
+// Stored turns are released first, and turns over the maximum overflow into stored turns.
+func (db *DB) EmpiresGiveTurns(turns, unstore, maxTurns, maxStored int) error {
+	err := db.db.EmpiresGiveTurns(db.ctx, sqlc.EmpiresGiveTurnsParams{
+		Turns:   int64(turns),
+		Unstore: int64(unstore),
+		Flags:   EFLAG_DISABLE,
+	})
+	if err != nil {
+		return err
+	}
+	return db.db.EmpiresStoreTurns(db.ctx, sqlc.EmpiresStoreTurnsParams{
+		MaxStored: int64(maxStored),
+		MaxTurns:  int64(maxTurns),
+	})
+}
+
+// EmpiresIncrementVacation adds an hour to every empire that is on vacation.
+func (db *DB) EmpiresIncrementVacation() error {
+	return db.db.EmpiresIncrementVacation(db.ctx)
+}
+
+// EmpiresRecoverAttacks counts up the attack penalty on empires that have been attacked.
+func (db *DB) EmpiresRecoverAttacks() error {
+	return db.db.EmpiresRecoverAttacks(db.ctx)
+}
+
+// EmpiresReducePrivateMarketSales lowers the share of troops each empire has recently sold
+// on the private market by 1%, up to 2% for empires with nothing but markets.
+func (db *DB) EmpiresReducePrivateMarketSales() error {
+	return db.db.EmpiresReducePrivateMarketSales(db.ctx)
+}
+
+// EmpiresRefillPrivateMarket restocks each empire's private market, based on the
+// empire's land and markets (or farms, for food), until the stock reaches its limit.
+func (db *DB) EmpiresRefillPrivateMarket() error {
+	return db.db.EmpiresRefillPrivateMarket(db.ctx)
+}
+
+// EmpiresResetIdle sets the idle time of every empire.
+func (db *DB) EmpiresResetIdle(now time.Time) error {
+	return db.db.EmpiresResetIdle(db.ctx, now.Unix())
+}
+
+// UnlinkCutoffs_t holds the idle times before which empires are unlinked from their users.
+type UnlinkCutoffs_t struct {
+	New      time.Time // never prompted for validation
+	Validate time.Time // stalled at the validation prompt
+	Abandon  time.Time // established empires that are not on vacation or disabled
+	Killed   time.Time // dead empires that never saw the notification
+	Delete   time.Time // empires marked for deletion that are out of protection
+}
+
+// EmpiresUnlinkable returns the ids of the empires that should be unlinked from their users
+// because they are idle, dead, or marked for deletion. Empires marked for deletion are
+// returned at once if they have used no more than the protection turns.
+func (db *DB) EmpiresUnlinkable(cutoffs UnlinkCutoffs_t, protection int) ([]int, error) {
+	rows, err := db.db.EmpiresUnlinkableFetch(db.ctx, sqlc.EmpiresUnlinkableFetchParams{
+		Admin:        EFLAG_ADMIN,
+		ValidNotify:  EFLAG_VALID | EFLAG_NOTIFY,
+		NewIdle:      cutoffs.New.Unix(),
+		Notify:       EFLAG_NOTIFY,
+		ValidateIdle: cutoffs.Validate.Unix(),
+		Disable:      EFLAG_DISABLE,
+		AbandonIdle:  cutoffs.Abandon.Unix(),
+		KilledIdle:   cutoffs.Killed.Unix(),
+		Delete:       EFLAG_DELETE,
+		Protection:   int64(protection),
+		DeleteIdle:   cutoffs.Delete.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	var list []int
+	for _, row := range rows {
+		list = append(list, int(row))
+	}
+	return list, nil
+}
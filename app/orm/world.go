@@ -0,0 +1,63 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package orm
+
+import (
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/orm/sqlc"
+)
+
+// WorldVarsAdjust queues an adjustment to a numeric world variable, from prom_vars::adjust.
+// Requests use this instead of updating the world directly so that they
+// don't clobber changes made by the turn updates. The adjustments are
+// applied by WorldVarsApplyAdjustments.
+func (db *DB) WorldVarsAdjust(name string, offset int) error {
+	if worldVar(&model.World_t{}, name) == nil {
+		return cerr.ErrUnknownWorldVar
+	}
+	return db.db.VarAdjustCreate(db.ctx, sqlc.VarAdjustCreateParams{VName: name, VOffset: int64(offset)})
+}
+
+// WorldVarsApplyAdjustments applies the queued adjustments to the world and
+// removes them from the queue, from prom_turns::adjWorld.
+// The caller must save the world in the same transaction.
+// It returns the number of adjustments applied.
+func (db *DB) WorldVarsApplyAdjustments(world *model.World_t) (int, error) {
+	rows, err := db.db.VarAdjustFetch(db.ctx)
+	if err != nil {
+		return 0, err
+	} else if len(rows) == 0 {
+		return 0, nil
+	}
+	for _, row := range rows {
+		v := worldVar(world, row.VName)
+		if v == nil {
+			return 0, cerr.ErrUnknownWorldVar
+		}
+		*v += int(row.VOffset)
+	}
+	// adjustments queued while we were working are left for the next run
+	if err := db.db.VarAdjustDelete(db.ctx, rows[len(rows)-1].VaID); err != nil {
+		return 0, err
+	}
+	return len(rows), nil
+}
+
+// worldVar returns a pointer to the numeric world variable with the given name,
+// or nil if there is no such variable.
+func worldVar(world *model.World_t, name string) *int {
+	switch name {
+	case "lotto_current_jackpot":
+		return &world.LottoCurrentJackpot
+	case "lotto_yesterday_jackpot":
+		return &world.LottoYesterdayJackpot
+	case "lotto_last_picked":
+		return &world.LottoLastPicked
+	case "lotto_last_winner":
+		return &world.LottoLastWinner
+	case "lotto_jackpot_increase":
+		return &world.LottoJackpotIncrease
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/turns"
+	"html"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// turnlogTypes maps the type filter to the TURN_* constants
+var turnlogTypes = map[string]int{
+	"event": turns.TURN_EVENT,
+	"start": turns.TURN_START,
+	"end":   turns.TURN_END,
+	"abort": turns.TURN_ABORT,
+}
+
+// turnlogUpdates maps the update filter to the TURNS_NEED_* flags
+var turnlogUpdates = map[string]int{
+	"normal": turns.TURNS_NEED_NORMAL,
+	"hourly": turns.TURNS_NEED_HOUR,
+	"daily":  turns.TURNS_NEED_DAY,
+	"final":  turns.TURNS_NEED_FINAL,
+}
+
+type turnlogFilter_t struct {
+	Type    string    `json:"type,omitempty"`
+	Update  string    `json:"update,omitempty"`
+	From    time.Time `json:"from,omitempty"`
+	To      time.Time `json:"to,omitempty"`
+	Limit   int       `json:"limit"`
+	kind    int
+	updates int
+}
+
+type turnlogEntry_t struct {
+	Id       int       `json:"id"`
+	Time     time.Time `json:"time"`
+	Interval time.Time `json:"interval,omitempty"`
+	Type     string    `json:"type"`
+	Updates  []string  `json:"updates,omitempty"`
+	Text     string    `json:"text"`
+}
+
+// adminTurnlogGetHandler shows the turn log to administrators.
+func (s *server) adminTurnlogGetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	if s.requireAdmin(w, r) == nil {
+		return
+	}
+	filter, entries, ok := s.turnlogFetch(w, r)
+	if !ok {
+		return
+	}
+
+	selected := func(a, b string) string {
+		if a == b {
+			return ` selected`
+		}
+		return ""
+	}
+	q := r.URL.Query()
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(`<!DOCTYPE html><html lang="en"><head><meta charset="UTF-8"><title>Turn Log</title><link rel="stylesheet" href="https://unpkg.com/missing.css@1.1.1"></head><body>`))
+	_, _ = w.Write([]byte(`<h1>Turn Log</h1>`))
+	_, _ = w.Write([]byte(`<main>`))
+	_, _ = w.Write([]byte(`<form method="get" action="/admin/turnlog" class="box rows">`))
+	_, _ = w.Write([]byte(`<p><label for="type">Type</label> <select name="type" id="type"><option value="">all</option>`))
+	for _, name := range []string{"event", "start", "end", "abort"} {
+		_, _ = w.Write([]byte(fmt.Sprintf(`<option value="%s"%s>%s</option>`, name, selected(name, filter.Type), name)))
+	}
+	_, _ = w.Write([]byte(`</select></p>`))
+	_, _ = w.Write([]byte(`<p><label for="update">Update</label> <select name="update" id="update"><option value="">all</option>`))
+	for _, name := range []string{"normal", "hourly", "daily", "final"} {
+		_, _ = w.Write([]byte(fmt.Sprintf(`<option value="%s"%s>%s</option>`, name, selected(name, filter.Update), name)))
+	}
+	_, _ = w.Write([]byte(`</select></p>`))
+	_, _ = w.Write([]byte(fmt.Sprintf(`<p><label for="from">From</label> <input type="text" name="from" id="from" placeholder="YYYY-MM-DD" value="%s"/>`, html.EscapeString(q.Get("from")))))
+	_, _ = w.Write([]byte(fmt.Sprintf(` <label for="to">To</label> <input type="text" name="to" id="to" placeholder="YYYY-MM-DD" value="%s"/></p>`, html.EscapeString(q.Get("to")))))
+	_, _ = w.Write([]byte(fmt.Sprintf(`<p><label for="limit">Limit</label> <input type="number" name="limit" id="limit" value="%d"/></p>`, filter.Limit)))
+	_, _ = w.Write([]byte(`<input type="submit" value="Filter"/>`))
+	_, _ = w.Write([]byte(`</form>`))
+	_, _ = w.Write([]byte(`<table><thead><tr><th>Time</th><th>Interval</th><th>Type</th><th>Updates</th><th>Message</th></tr></thead><tbody>`))
+	for _, entry := range entries {
+		interval := "----/--/-- --:--"
+		if !entry.Interval.IsZero() {
+			interval = entry.Interval.Format("2006/01/02 15:04")
+		}
+		_, _ = w.Write([]byte(fmt.Sprintf(`<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>`,
+			entry.Time.Format("2006/01/02 15:04:05.000000"), interval, entry.Type, strings.Join(entry.Updates, ", "), html.EscapeString(entry.Text))))
+	}
+	_, _ = w.Write([]byte(`</tbody></table>`))
+	_, _ = w.Write([]byte(`<p><a href="/admin/turnlog.json?` + html.EscapeString(r.URL.RawQuery) + `">JSON</a></p>`))
+	_, _ = w.Write([]byte(`</main>`))
+	_, _ = w.Write([]byte(`</body>`))
+}
+
+// adminTurnlogJsonGetHandler returns the turn log to administrators as JSON.
+func (s *server) adminTurnlogJsonGetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	if s.requireAdmin(w, r) == nil {
+		return
+	}
+	filter, entries, ok := s.turnlogFetch(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(struct {
+		Filter  turnlogFilter_t  `json:"filter"`
+		Entries []turnlogEntry_t `json:"entries"`
+	}{
+		Filter:  filter,
+		Entries: entries,
+	})
+}
+
+// turnlogFetch parses the filter from the request and fetches the matching entries.
+// If the filter is not valid, it responds with an error and returns false.
+func (s *server) turnlogFetch(w http.ResponseWriter, r *http.Request) (turnlogFilter_t, []turnlogEntry_t, bool) {
+	filter, err := turnlogFilterFromRequest(r)
+	if err != nil {
+		log.Printf("%s %s: filter: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return filter, nil, false
+	}
+	rows, err := s.db.TurnlogFetch(filter.kind, filter.updates, filter.From, filter.To, filter.Limit)
+	if err != nil {
+		log.Printf("%s %s: turnlogFetch: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return filter, nil, false
+	}
+	entries := []turnlogEntry_t{}
+	for _, row := range rows {
+		entries = append(entries, turnlogEntryFromModel(row))
+	}
+	return filter, entries, true
+}
+
+// turnlogFilterFromRequest parses the type, update, date range, and limit filters.
+// Dates are either YYYY-MM-DD, which includes the entire day, or RFC 3339 timestamps.
+func turnlogFilterFromRequest(r *http.Request) (turnlogFilter_t, error) {
+	filter := turnlogFilter_t{Limit: 500, kind: -1}
+	q := r.URL.Query()
+	if v := strings.TrimSpace(q.Get("type")); v != "" {
+		kind, ok := turnlogTypes[v]
+		if !ok {
+			return filter, fmt.Errorf("type: unknown value %q", v)
+		}
+		filter.Type, filter.kind = v, kind
+	}
+	if v := strings.TrimSpace(q.Get("update")); v != "" {
+		updates, ok := turnlogUpdates[v]
+		if !ok {
+			return filter, fmt.Errorf("update: unknown value %q", v)
+		}
+		filter.Update, filter.updates = v, updates
+	}
+	if v := strings.TrimSpace(q.Get("from")); v != "" {
+		from, _, err := parseFilterDate(v)
+		if err != nil {
+			return filter, fmt.Errorf("from: %w", err)
+		}
+		filter.From = from
+	}
+	if v := strings.TrimSpace(q.Get("to")); v != "" {
+		to, isDate, err := parseFilterDate(v)
+		if err != nil {
+			return filter, fmt.Errorf("to: %w", err)
+		} else if isDate {
+			// include the entire day
+			to = to.Add(24 * time.Hour)
+		}
+		filter.To = to
+	}
+	if v := strings.TrimSpace(q.Get("limit")); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > 5000 {
+			return filter, fmt.Errorf("limit: must be between 1 and 5000")
+		}
+		filter.Limit = limit
+	}
+	return filter, nil
+}
+
+// parseFilterDate parses a date or a timestamp, returning true if the value was a date.
+func parseFilterDate(v string) (time.Time, bool, error) {
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid date %q", v)
+	}
+	return t.UTC(), false, nil
+}
+
+func turnlogEntryFromModel(row *model.TurnLog_t) turnlogEntry_t {
+	entry := turnlogEntry_t{
+		Id:       row.Id,
+		Time:     row.Time,
+		Interval: row.Interval,
+		Text:     row.Text,
+	}
+	for name, kind := range turnlogTypes {
+		if kind == row.Type {
+			entry.Type = name
+		}
+	}
+	for _, name := range []string{"normal", "hourly", "daily", "final"} {
+		if row.Updates&turnlogUpdates[name] != 0 {
+			entry.Updates = append(entry.Updates, name)
+		}
+	}
+	return entry
+}
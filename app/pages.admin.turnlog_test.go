@@ -0,0 +1,100 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"encoding/json"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/turns"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAdminTurnlog(t *testing.T) {
+	s, emp, sessionId := testServer(t)
+
+	day := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	next := day.Add(24 * time.Hour)
+	if err := s.db.TurnlogInsert(
+		&model.TurnLog_t{Time: day, Type: turns.TURN_START, Text: "start 1"},
+		&model.TurnLog_t{Time: day.Add(time.Second), Interval: day, Type: turns.TURN_EVENT, Updates: turns.TURNS_NEED_NORMAL, Text: "<b>event 1</b>"},
+		&model.TurnLog_t{Time: next, Type: turns.TURN_START, Text: "start 2"},
+		&model.TurnLog_t{Time: next.Add(time.Second), Interval: next, Type: turns.TURN_EVENT, Updates: turns.TURNS_NEED_NORMAL | turns.TURNS_NEED_DAY, Text: "event 2"},
+	); err != nil {
+		t.Fatalf("turnlog: %v", err)
+	}
+
+	// only administrators may see the turn log
+	for _, handler := range []http.HandlerFunc{s.adminTurnlogGetHandler, s.adminTurnlogJsonGetHandler} {
+		if w := testRequest(s, handler, "GET", "/admin/turnlog", sessionId); w.Code != http.StatusForbidden {
+			t.Errorf("player: want %d, got %d", http.StatusForbidden, w.Code)
+		}
+	}
+	user, err := s.db.UserFetch(emp.UserId)
+	if err != nil {
+		t.Fatalf("user: %v", err)
+	}
+	user.Flags.Admin = true
+	if err := s.db.UserAttributesUpdate(user); err != nil {
+		t.Fatalf("user: %v", err)
+	}
+
+	fetch := func(query string) (int, []string) {
+		w := testRequest(s, s.adminTurnlogJsonGetHandler, "GET", "/admin/turnlog.json?"+query, sessionId)
+		if w.Code != http.StatusOK {
+			return w.Code, nil
+		}
+		var body struct {
+			Entries []turnlogEntry_t `json:"entries"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+			t.Fatalf("%s: decode: %v", query, err)
+		}
+		var got []string
+		for _, entry := range body.Entries {
+			got = append(got, entry.Type+" "+strings.Join(entry.Updates, ",")+" "+entry.Text)
+		}
+		return w.Code, got
+	}
+	for _, tc := range []struct {
+		query string
+		code  int
+		want  []string
+	}{
+		{"", http.StatusOK, []string{"event normal,daily event 2", "start  start 2", "event normal <b>event 1</b>", "start  start 1"}},
+		{"type=start", http.StatusOK, []string{"start  start 2", "start  start 1"}},
+		{"update=daily", http.StatusOK, []string{"event normal,daily event 2"}},
+		// dates include the entire day
+		{"from=2024-04-02", http.StatusOK, []string{"event normal,daily event 2", "start  start 2"}},
+		{"to=2024-04-01", http.StatusOK, []string{"event normal <b>event 1</b>", "start  start 1"}},
+		{"type=event&from=2024-04-01T00:00:01Z&to=2024-04-02T00:00:01Z", http.StatusOK, []string{"event normal <b>event 1</b>"}},
+		{"limit=1", http.StatusOK, []string{"event normal,daily event 2"}},
+		{"type=bogus", http.StatusBadRequest, nil},
+		{"from=yesterday", http.StatusBadRequest, nil},
+		{"limit=0", http.StatusBadRequest, nil},
+	} {
+		if code, got := fetch(tc.query); code != tc.code {
+			t.Errorf("%q: want %d, got %d", tc.query, tc.code, code)
+		} else if !slices.Equal(got, tc.want) {
+			t.Errorf("%q: want %q, got %q", tc.query, tc.want, got)
+		}
+	}
+
+	// the page escapes the messages and keeps the filter selected
+	w := testRequest(s, s.adminTurnlogGetHandler, "GET", "/admin/turnlog?type=event", sessionId)
+	if w.Code != http.StatusOK {
+		t.Fatalf("page: want %d, got %d", http.StatusOK, w.Code)
+	}
+	body := w.Body.String()
+	for _, want := range []string{"&lt;b&gt;event 1&lt;/b&gt;", `<option value="event" selected>`, "event 2"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page: want %q, got %q", want, body)
+		}
+	}
+	if strings.Contains(body, "start 1") {
+		t.Errorf("page: want only events, got %q", body)
+	}
+}
@@ -0,0 +1,392 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/mdhender/promisance/app/aid"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"html"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type aidState_t struct {
+	Owned   engine.Cargo_t `json:"owned"`
+	CanSend engine.Cargo_t `json:"canSend"`
+	Convoy  int            `json:"convoy"`
+	Credits int            `json:"credits"`
+	Turns   int            `json:"turns"`
+}
+
+type aidClanShipment_t struct {
+	Time          time.Time      `json:"time"`
+	SenderId      int            `json:"senderId"`
+	SenderName    string         `json:"senderName"`
+	RecipientId   int            `json:"recipientId"`
+	RecipientName string         `json:"recipientName"`
+	Cargo         engine.Cargo_t `json:"cargo"`
+}
+
+// aidGetHandler shows the foreign aid page, from php/pages/aid.php.
+func (s *server) aidGetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	s.aidPage(w, r, emp, 0, nil)
+}
+
+// aidPostHandler sends a shipment of foreign aid.
+func (s *server) aidPostHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	targetId := s.getFormNum(r, "aid_target")
+	cargo := engine.Cargo_t{
+		TrpArm: s.getFormNum(r, "send_trparm"),
+		TrpLnd: s.getFormNum(r, "send_trplnd"),
+		TrpFly: s.getFormNum(r, "send_trpfly"),
+		TrpSea: s.getFormNum(r, "send_trpsea"),
+		Cash:   s.getFormNum(r, "send_cash"),
+		Runes:  s.getFormNum(r, "send_runes"),
+		Food:   s.getFormNum(r, "send_food"),
+	}
+	var notices []string
+	res, err := s.aid.Send(emp.Id, s.roundData(time.Now()), targetId, cargo, time.Now())
+	if err != nil {
+		if _, ok := aidUnavailable(err); !ok {
+			log.Printf("%s %s: send: %v\n", r.Method, r.URL.Path, err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		// the page explains why aid is not available
+	} else {
+		notices = s.aidMessages(res.Messages, html.EscapeString)
+	}
+	s.aidPage(w, r, emp, targetId, notices)
+}
+
+// aidJsonGetHandler returns what the empire can send as JSON.
+func (s *server) aidJsonGetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	state, err := s.aid.State(emp.Id, s.roundData(time.Now()), time.Now())
+	if err != nil {
+		if key, ok := aidUnavailable(err); ok {
+			http.Error(w, s.language.Printf(key), http.StatusConflict)
+			return
+		}
+		log.Printf("%s %s: state: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(aidStateFromService(state))
+}
+
+// aidJsonPostHandler sends a shipment of foreign aid.
+// The request body holds the target empire and the goods to send.
+func (s *server) aidJsonPostHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	var input struct {
+		Target int            `json:"target"`
+		Cargo  engine.Cargo_t `json:"cargo"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, fmt.Sprintf("request: %v", err), http.StatusBadRequest)
+		return
+	}
+	res, err := s.aid.Send(emp.Id, s.roundData(time.Now()), input.Target, input.Cargo, time.Now())
+	if err != nil {
+		if key, ok := aidUnavailable(err); ok {
+			http.Error(w, s.language.Printf(key), http.StatusConflict)
+			return
+		}
+		log.Printf("%s %s: send: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(struct {
+		Sent     bool           `json:"sent"`
+		Cargo    engine.Cargo_t `json:"cargo"`
+		Messages []string       `json:"messages"`
+		State    aidState_t     `json:"state"`
+	}{
+		Sent:     res.Sent,
+		Cargo:    res.Cargo,
+		Messages: s.aidMessages(res.Messages, func(v string) string { return v }),
+		State:    aidStateFromService(res.State),
+	})
+}
+
+// aidClanGetHandler shows the foreign aid sent between members of the empire's clan,
+// from printClanAid in php/includes/news.php.
+func (s *server) aidClanGetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	shipments, ok := s.aidClanFetch(w, r, emp)
+	if !ok {
+		return
+	}
+	era, err := s.tables.Era(emp.Era)
+	if err != nil {
+		log.Printf("%s %s: era: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	xlat := func(key string) string {
+		return html.EscapeString(s.language.Printf(key))
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(`<!DOCTYPE html><html lang="en"><head><meta charset="UTF-8"><title>` + xlat("MANAGE_CLAN_TITLE") + `</title><link rel="stylesheet" href="https://unpkg.com/missing.css@1.1.1"></head><body>`))
+	_, _ = w.Write([]byte(`<h1>` + xlat("MANAGE_CLAN_TITLE") + `</h1>`))
+	_, _ = w.Write([]byte(`<main>`))
+	_, _ = w.Write([]byte(`<table><thead><tr>`))
+	for _, column := range []string{"MESSAGES_COLUMN_DATE", "MESSAGES_COLUMN_INBOX", "MESSAGES_COLUMN_OUTBOX", era.TrpArm, era.TrpLnd, era.TrpFly, era.TrpSea, "ROW_CASH", era.Runes, era.Food} {
+		_, _ = w.Write([]byte(`<th>` + xlat(column) + `</th>`))
+	}
+	_, _ = w.Write([]byte(`</tr></thead><tbody>`))
+	if len(shipments) == 0 {
+		_, _ = w.Write([]byte(`<tr><td colspan="10">No aid has been sent within the clan.</td></tr>`))
+	}
+	for _, shipment := range shipments {
+		_, _ = w.Write([]byte(fmt.Sprintf(`<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>`,
+			shipment.Time.Format("2006/01/02 15:04"), html.EscapeString(s.aidEmpireName(*shipment.Sender)), html.EscapeString(s.aidEmpireName(*shipment.Recipient)),
+			s.language.Number(shipment.Cargo.TrpArm), s.language.Number(shipment.Cargo.TrpLnd), s.language.Number(shipment.Cargo.TrpFly), s.language.Number(shipment.Cargo.TrpSea),
+			s.language.Money(shipment.Cargo.Cash), s.language.Number(shipment.Cargo.Runes), s.language.Number(shipment.Cargo.Food))))
+	}
+	_, _ = w.Write([]byte(`</tbody></table>`))
+	_, _ = w.Write([]byte(`<p><a href="/clan/aid.json">JSON</a></p>`))
+	_, _ = w.Write([]byte(`</main>`))
+	_, _ = w.Write([]byte(`</body>`))
+}
+
+// aidClanJsonGetHandler returns the foreign aid sent between members of the empire's clan as JSON.
+func (s *server) aidClanJsonGetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	shipments, ok := s.aidClanFetch(w, r, emp)
+	if !ok {
+		return
+	}
+	list := []aidClanShipment_t{}
+	for _, shipment := range shipments {
+		list = append(list, aidClanShipment_t{
+			Time:          shipment.Time,
+			SenderId:      shipment.Sender.Id,
+			SenderName:    shipment.Sender.Name,
+			RecipientId:   shipment.Recipient.Id,
+			RecipientName: shipment.Recipient.Name,
+			Cargo:         shipment.Cargo,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(list)
+}
+
+// aidClanFetch returns the foreign aid sent between members of the empire's clan.
+// It writes an error response and returns false if the empire may not view it.
+func (s *server) aidClanFetch(w http.ResponseWriter, r *http.Request, emp *model.Empire_t) ([]*aid.ClanAid_t, bool) {
+	shipments, err := s.aid.ClanAid(emp.Id, s.roundData(time.Now()))
+	if err == nil {
+		return shipments, true
+	}
+	switch {
+	case errors.Is(err, cerr.ErrClanAidHidden):
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+	case errors.Is(err, cerr.ErrRoundFinished):
+		http.Error(w, s.language.Printf("MANAGE_CLAN_UNAVAILABLE_END"), http.StatusConflict)
+	case errors.Is(err, cerr.ErrRoundNotStarted):
+		http.Error(w, s.language.Printf("MANAGE_CLAN_UNAVAILABLE_START"), http.StatusConflict)
+	case errors.Is(err, cerr.ErrEmpireProtected):
+		http.Error(w, s.language.Printf("MANAGE_CLAN_UNAVAILABLE_PROTECT"), http.StatusConflict)
+	case errors.Is(err, cerr.ErrEmpireAdmin):
+		http.Error(w, s.language.Printf("MANAGE_CLAN_UNAVAILABLE_ADMIN"), http.StatusForbidden)
+	case errors.Is(err, cerr.ErrClansDisabled):
+		http.Error(w, s.language.Printf("MANAGE_CLAN_UNAVAILABLE_CONFIG"), http.StatusConflict)
+	case errors.Is(err, cerr.ErrNotClanMember):
+		http.Error(w, s.language.Printf("CLAN_NOT_MEMBER"), http.StatusForbidden)
+	case errors.Is(err, cerr.ErrNotClanOfficer):
+		http.Error(w, s.language.Printf("MANAGE_CLAN_NEED_PERMISSION"), http.StatusForbidden)
+	default:
+		log.Printf("%s %s: clanAid: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+	return nil, false
+}
+
+// aidPage writes the foreign aid page.
+func (s *server) aidPage(w http.ResponseWriter, r *http.Request, emp *model.Empire_t, targetId int, notices []string) {
+	notices = s.pageNoticesHTML(r, notices)
+	state, err := s.aid.State(emp.Id, s.roundData(time.Now()), time.Now())
+	if key, ok := aidUnavailable(err); ok {
+		notices = append(notices, html.EscapeString(s.language.Printf(key)))
+	} else if err != nil {
+		log.Printf("%s %s: state: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	era, err := s.tables.Era(emp.Era)
+	if err != nil {
+		log.Printf("%s %s: era: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	xlat := func(key string) string {
+		return html.EscapeString(s.language.Printf(key))
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(`<!DOCTYPE html><html lang="en"><head><meta charset="UTF-8"><title>` + xlat("AID_TITLE") + `</title><link rel="stylesheet" href="https://unpkg.com/missing.css@1.1.1"></head><body>`))
+	_, _ = w.Write([]byte(`<h1>` + xlat("AID_TITLE") + `</h1>`))
+	_, _ = w.Write([]byte(`<main>`))
+	// the notices contain markup, so they are escaped when they are created
+	for _, notice := range notices {
+		_, _ = w.Write([]byte(`<p class="box">` + notice + `</p>`))
+	}
+	if state == nil {
+		_, _ = w.Write([]byte(`</main>`))
+		_, _ = w.Write([]byte(`</body>`))
+		return
+	}
+
+	// the header contains markup, so it is not escaped
+	shipments := s.language.Plural(state.Credits, "SHIPMENTS_SINGLE", "SHIPMENTS_PLURAL", "")
+	_, _ = w.Write([]byte(`<p>` + s.language.Printf("AID_HEADER", s.language.Number(state.Convoy), s.language.Printf(era.TrpSea), shipments) + `</p>`))
+	_, _ = w.Write([]byte(`<form method="post" action="/aid">`))
+	// the number is not prefixed because the form expects a plain number
+	target := ""
+	if targetId != 0 {
+		target = strconv.Itoa(targetId)
+	}
+	_, _ = w.Write([]byte(`<p><label>` + xlat("LABEL_EMPIRE_RECIPIENT") + ` <input type="text" name="aid_target" size="6" value="` + html.EscapeString(target) + `"/></label></p>`))
+	_, _ = w.Write([]byte(`<table><thead><tr>`))
+	for _, column := range []string{"COLUMN_UNIT", "COLUMN_OWNED", "COLUMN_CANSEND", "COLUMN_SEND"} {
+		_, _ = w.Write([]byte(`<th>` + xlat(column) + `</th>`))
+	}
+	_, _ = w.Write([]byte(`</tr></thead><tbody>`))
+	rows := []struct {
+		field, name    string
+		owned, cansend int
+		money          bool
+	}{
+		{"trparm", era.TrpArm, state.Owned.TrpArm, state.CanSend.TrpArm, false},
+		{"trplnd", era.TrpLnd, state.Owned.TrpLnd, state.CanSend.TrpLnd, false},
+		{"trpfly", era.TrpFly, state.Owned.TrpFly, state.CanSend.TrpFly, false},
+		{"trpsea", era.TrpSea, state.Owned.TrpSea, state.CanSend.TrpSea, false},
+		{"cash", "ROW_CASH", state.Owned.Cash, state.CanSend.Cash, true},
+		{"runes", era.Runes, state.Owned.Runes, state.CanSend.Runes, false},
+		{"food", era.Food, state.Owned.Food, state.CanSend.Food, false},
+	}
+	for _, row := range rows {
+		owned, cansend := s.language.Number(row.owned), s.language.Number(row.cansend)
+		if row.money {
+			owned, cansend = s.language.Money(row.owned), s.language.Money(row.cansend)
+		}
+		_, _ = w.Write([]byte(fmt.Sprintf(`<tr><td>%s</td><td>%s</td><td>%s</td><td><input type="text" name="send_%s" size="8" value="0"/></td></tr>`,
+			xlat(row.name), owned, cansend, row.field)))
+	}
+	_, _ = w.Write([]byte(`</tbody></table>`))
+	_, _ = w.Write([]byte(`<input type="submit" value="` + xlat("AID_SUBMIT") + `"/>`))
+	_, _ = w.Write([]byte(`</form>`))
+	_, _ = w.Write([]byte(`<p><a href="/aid.json">JSON</a></p>`))
+	_, _ = w.Write([]byte(`</main>`))
+	_, _ = w.Write([]byte(`</body>`))
+}
+
+// aidMessages translates the messages from sending foreign aid.
+// Cash (AID_TOO_MUCH_CASH) is formatted as money and other amounts as numbers.
+// AID_COMPLETE contains markup, so the arguments are escaped with the given function.
+func (s *server) aidMessages(messages []engine.Message_t, escape func(string) string) []string {
+	var list []string
+	for _, msg := range messages {
+		var args []any
+		for _, arg := range msg.Args {
+			switch v := arg.(type) {
+			case int:
+				if msg.Key == "AID_TOO_MUCH_CASH" {
+					args = append(args, escape(s.language.Money(v)))
+				} else {
+					args = append(args, escape(s.language.Number(v)))
+				}
+			case string:
+				args = append(args, escape(s.language.Printf(v)))
+			case model.Empire_t:
+				args = append(args, escape(s.aidEmpireName(v)))
+			default:
+				args = append(args, v)
+			}
+		}
+		text := s.language.Printf(msg.Key, args...)
+		if msg.Key != "AID_COMPLETE" {
+			text = escape(text)
+		}
+		list = append(list, text)
+	}
+	return list
+}
+
+// aidEmpireName returns the name and number of the empire.
+func (s *server) aidEmpireName(emp model.Empire_t) string {
+	return s.language.Printf("COMMON_EMPIRE_NAMEID", emp.Name, s.language.Prenum(emp.Id))
+}
+
+// aidUnavailable returns the language key explaining why the empire may not send aid.
+// It returns false if the error is not one of the reasons.
+func aidUnavailable(err error) (string, bool) {
+	switch {
+	case err == nil:
+		return "", false
+	case errors.Is(err, cerr.ErrRoundFinished):
+		return "AID_UNAVAILABLE_END", true
+	case errors.Is(err, cerr.ErrRoundNotStarted):
+		return "AID_UNAVAILABLE_START", true
+	case errors.Is(err, cerr.ErrEmpireProtected):
+		return "AID_UNAVAILABLE_PROTECT", true
+	case errors.Is(err, cerr.ErrEmpireAdmin):
+		return "AID_UNAVAILABLE_ADMIN", true
+	case errors.Is(err, cerr.ErrAidDisabled):
+		return "AID_UNAVAILABLE_CONFIG", true
+	}
+	return "", false
+}
+
+func aidStateFromService(state *aid.State_t) aidState_t {
+	return aidState_t{
+		Owned:   state.Owned,
+		CanSend: state.CanSend,
+		Convoy:  state.Convoy,
+		Credits: state.Credits,
+		Turns:   state.Turns,
+	}
+}
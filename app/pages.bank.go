@@ -0,0 +1,230 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mdhender/promisance/app/bank"
+	"github.com/mdhender/promisance/app/engine"
+	"html"
+	"log"
+	"net/http"
+	"time"
+)
+
+type bankAccount_t struct {
+	Cash      int     `json:"cash"`
+	Savings   int     `json:"savings"`
+	Loan      int     `json:"loan"`
+	SaveRate  float64 `json:"saveRate"`
+	LoanRate  float64 `json:"loanRate"`
+	MaxSave   int     `json:"maxSave"`
+	MaxLoan   int     `json:"maxLoan"`
+	Protected bool    `json:"protected"`
+	CanBorrow bool    `json:"canBorrow"`
+}
+
+// bankGetHandler shows the bank, from php/pages/bank.php.
+func (s *server) bankGetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	s.bankPage(w, r, emp.Id, nil)
+}
+
+// bankPostHandler performs the bank transaction from the posted form.
+func (s *server) bankPostHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	var notices []string
+	if round := s.roundData(time.Now()); round.Started && !round.Finished {
+		action, _ := s.getFormVar(r, "action", "")
+		res, err := s.bank.Transact(emp.Id, round, bank.Action_t(action), s.getFormNum(r, "bank_amount"))
+		if err != nil {
+			log.Printf("%s %s: transact: %v\n", r.Method, r.URL.Path, err)
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		notices = s.bankMessages(res.Messages)
+	}
+	s.bankPage(w, r, emp.Id, notices)
+}
+
+// bankJsonGetHandler returns the empire's accounts as JSON.
+func (s *server) bankJsonGetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	acct, err := s.bank.Account(emp.Id, s.roundData(time.Now()))
+	if err != nil {
+		log.Printf("%s %s: account: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(bankAccountFromService(acct))
+}
+
+// bankJsonPostHandler performs a bank transaction.
+// The request body holds the action ("borrow", "repay", "deposit", or "withdraw") and the amount.
+func (s *server) bankJsonPostHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	var input struct {
+		Action string `json:"action"`
+		Amount int    `json:"amount"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, fmt.Sprintf("request: %v", err), http.StatusBadRequest)
+		return
+	}
+	switch bank.Action_t(input.Action) {
+	case bank.BORROW, bank.REPAY, bank.DEPOSIT, bank.WITHDRAW:
+	default:
+		http.Error(w, fmt.Sprintf("action: unknown value %q", input.Action), http.StatusBadRequest)
+		return
+	}
+	round := s.roundData(time.Now())
+	if round.Finished {
+		http.Error(w, s.language.Printf("BANK_UNAVAILABLE_END"), http.StatusConflict)
+		return
+	} else if !round.Started {
+		http.Error(w, s.language.Printf("BANK_UNAVAILABLE_START"), http.StatusConflict)
+		return
+	}
+	res, err := s.bank.Transact(emp.Id, round, bank.Action_t(input.Action), input.Amount)
+	if err != nil {
+		log.Printf("%s %s: transact: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(struct {
+		Amount   int           `json:"amount"`
+		Messages []string      `json:"messages"`
+		Account  bankAccount_t `json:"account"`
+	}{
+		Amount:   res.Amount,
+		Messages: s.bankMessages(res.Messages),
+		Account:  bankAccountFromService(res.Account),
+	})
+}
+
+// bankPage writes the bank page.
+func (s *server) bankPage(w http.ResponseWriter, r *http.Request, empireId int, notices []string) {
+	notices = s.pageNotices(r, notices)
+	round := s.roundData(time.Now())
+	if round.Finished {
+		notices = append(notices, s.language.Printf("BANK_UNAVAILABLE_END"))
+	} else if !round.Started {
+		notices = append(notices, s.language.Printf("BANK_UNAVAILABLE_START"))
+	}
+	acct, err := s.bank.Account(empireId, round)
+	if err != nil {
+		log.Printf("%s %s: account: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	xlat := func(key string) string {
+		return html.EscapeString(s.language.Printf(key))
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(`<!DOCTYPE html><html lang="en"><head><meta charset="UTF-8"><title>` + xlat("BANK_TITLE") + `</title><link rel="stylesheet" href="https://unpkg.com/missing.css@1.1.1"></head><body>`))
+	_, _ = w.Write([]byte(`<h1>` + xlat("BANK_TITLE") + `</h1>`))
+	_, _ = w.Write([]byte(`<main>`))
+	for _, notice := range notices {
+		_, _ = w.Write([]byte(`<p class="box">` + html.EscapeString(notice) + `</p>`))
+	}
+	if !round.Started || round.Finished {
+		_, _ = w.Write([]byte(`</main>`))
+		_, _ = w.Write([]byte(`</body>`))
+		return
+	}
+	_, _ = w.Write([]byte(`<table>`))
+	_, _ = w.Write([]byte(fmt.Sprintf(`<thead><tr><th colspan="2">%s</th><th colspan="2">%s</th></tr></thead><tbody>`, xlat("BANK_HEADER_SAVINGS"), xlat("BANK_HEADER_LOAN"))))
+	for _, row := range []struct {
+		saveLabel, saveValue string
+		loanLabel, loanValue string
+	}{
+		{"BANK_LABEL_SAVINGS_INTEREST", s.language.Percent(acct.SaveRate, 3), "BANK_LABEL_LOAN_INTEREST", s.language.Percent(acct.LoanRate, 3)},
+		{"BANK_LABEL_MAX_SAVINGS", s.language.Money(acct.MaxSave), "BANK_LABEL_MAX_LOAN", s.language.Money(acct.MaxLoan)},
+		{"BANK_LABEL_CUR_SAVINGS", s.language.Money(acct.Savings), "BANK_LABEL_CUR_LOAN", s.language.Money(acct.Loan)},
+	} {
+		_, _ = w.Write([]byte(fmt.Sprintf(`<tr><th>%s</th><td>%s</td><th>%s</th><td>%s</td></tr>`, xlat(row.saveLabel), row.saveValue, xlat(row.loanLabel), row.loanValue)))
+	}
+	_, _ = w.Write([]byte(`<tr><td colspan="4">` + xlat("BANK_INTEREST_DESCRIPTION") + `</td></tr>`))
+	_, _ = w.Write([]byte(`</tbody></table>`))
+	if acct.Protected {
+		_, _ = w.Write([]byte(`<p><b>` + xlat("BANK_SAVINGS_INTEREST_PROTECT") + `</b></p>`))
+	}
+
+	_, _ = w.Write([]byte(`<table><tbody>`))
+	for _, form := range []struct {
+		action bank.Action_t
+		label  string
+		submit string
+		amount int
+	}{
+		{bank.BORROW, "BANK_LABEL_BORROW", "BANK_BORROW_SUBMIT", 0},
+		{bank.REPAY, "BANK_LABEL_REPAY", "BANK_REPAY_SUBMIT", min(acct.Loan, acct.Cash)},
+		{bank.DEPOSIT, "BANK_LABEL_DEPOSIT", "BANK_DEPOSIT_SUBMIT", 0},
+		{bank.WITHDRAW, "BANK_LABEL_WITHDRAW", "BANK_WITHDRAW_SUBMIT", acct.Savings},
+	} {
+		if form.action == bank.BORROW && !acct.CanBorrow {
+			_, _ = w.Write([]byte(`<tr><td colspan="2">` + xlat("BANK_LOAN_UNAVAILABLE") + `</td></tr>`))
+			continue
+		}
+		_, _ = w.Write([]byte(fmt.Sprintf(`<tr><th>%s</th><td><form method="post" action="/bank"><input type="text" name="bank_amount" value="%s" size="9"/> <input type="hidden" name="action" value="%s"/><input type="submit" value="%s"/></form></td></tr>`,
+			xlat(form.label), s.language.Money(form.amount), form.action, xlat(form.submit))))
+	}
+	_, _ = w.Write([]byte(`</tbody></table>`))
+	_, _ = w.Write([]byte(`<p><a href="/bank.json">JSON</a></p>`))
+	_, _ = w.Write([]byte(`</main>`))
+	_, _ = w.Write([]byte(`</body>`))
+}
+
+// bankMessages translates the messages from the bank. Every amount is money.
+func (s *server) bankMessages(messages []engine.Message_t) []string {
+	var list []string
+	for _, msg := range messages {
+		var args []any
+		for _, arg := range msg.Args {
+			if v, ok := arg.(int); ok {
+				args = append(args, s.language.Money(v))
+			} else {
+				args = append(args, arg)
+			}
+		}
+		list = append(list, s.language.Printf(msg.Key, args...))
+	}
+	return list
+}
+
+func bankAccountFromService(acct *bank.Account_t) bankAccount_t {
+	return bankAccount_t{
+		Cash:      acct.Cash,
+		Savings:   acct.Savings,
+		Loan:      acct.Loan,
+		SaveRate:  acct.SaveRate,
+		LoanRate:  acct.LoanRate,
+		MaxSave:   acct.MaxSave,
+		MaxLoan:   acct.MaxLoan,
+		Protected: acct.Protected,
+		CanBorrow: acct.CanBorrow,
+	}
+}
@@ -0,0 +1,453 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mdhender/promisance/app/build"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"html"
+	"log"
+	"net/http"
+	"time"
+)
+
+type buildBuildings_t struct {
+	BldPop   int `json:"bldpop"`
+	BldCash  int `json:"bldcash"`
+	BldTrp   int `json:"bldtrp"`
+	BldCost  int `json:"bldcost"`
+	BldWiz   int `json:"bldwiz"`
+	BldFood  int `json:"bldfood"`
+	BldDef   int `json:"blddef"`
+	Freeland int `json:"freeland,omitempty"`
+}
+
+type buildState_t struct {
+	Cash         int              `json:"cash"`
+	Turns        int              `json:"turns"`
+	Land         int              `json:"land"`
+	Buildings    buildBuildings_t `json:"buildings"`
+	BuildCost    int              `json:"buildCost"`
+	BuildRate    int              `json:"buildRate"`
+	CanBuild     int              `json:"canBuild"`
+	Salvage      int              `json:"salvage"`
+	DemolishRate int              `json:"demolishRate"`
+	CanDemolish  int              `json:"canDemolish"`
+	DropRate     int              `json:"dropRate"`
+	CanDrop      int              `json:"canDrop"`
+}
+
+type buildResult_t struct {
+	Turns    int          `json:"turns"`
+	Total    int          `json:"total"`
+	Cash     int          `json:"cash"`
+	Messages []string     `json:"messages"`
+	State    buildState_t `json:"state"`
+}
+
+// buildGetHandler shows the construction page, from php/pages/build.php.
+func (s *server) buildGetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	s.buildPage(w, r, emp, nil)
+}
+
+// buildPostHandler builds the structures from the posted form.
+func (s *server) buildPostHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	var notices []string
+	if round := s.roundData(time.Now()); round.Started && !round.Finished {
+		res, err := s.build.Build(emp.Id, round, s.buildFormBuildings(r, "build_"), time.Now())
+		if err != nil {
+			log.Printf("%s %s: build: %v\n", r.Method, r.URL.Path, err)
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		notices = s.buildMessages(res.Messages)
+	}
+	s.buildPage(w, r, emp, notices)
+}
+
+// buildJsonGetHandler returns what the empire can build, demolish, and drop as JSON.
+func (s *server) buildJsonGetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	state, err := s.build.State(emp.Id, time.Now())
+	if err != nil {
+		log.Printf("%s %s: state: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(buildStateFromService(state))
+}
+
+// buildJsonPostHandler builds structures.
+// The request body holds the number of each type of structure to build.
+func (s *server) buildJsonPostHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	var input buildBuildings_t
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, fmt.Sprintf("request: %v", err), http.StatusBadRequest)
+		return
+	}
+	round := s.roundData(time.Now())
+	if round.Finished {
+		http.Error(w, s.language.Printf("BUILD_UNAVAILABLE_END"), http.StatusConflict)
+		return
+	} else if !round.Started {
+		http.Error(w, s.language.Printf("BUILD_UNAVAILABLE_START"), http.StatusConflict)
+		return
+	}
+	res, err := s.build.Build(emp.Id, round, buildBuildingsToEngine(input), time.Now())
+	if err != nil {
+		log.Printf("%s %s: build: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	s.buildJsonResult(w, res)
+}
+
+// demolishGetHandler shows the demolition page, from php/pages/demolish.php.
+func (s *server) demolishGetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	s.demolishPage(w, r, emp, nil)
+}
+
+// demolishPostHandler demolishes the structures or drops the land from the posted form.
+func (s *server) demolishPostHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	var notices []string
+	if round := s.roundData(time.Now()); round.Started && !round.Finished {
+		var res *build.Result_t
+		var err error
+		switch action, _ := s.getFormVar(r, "action", ""); action {
+		case "demolish":
+			res, err = s.build.Demolish(emp.Id, round, s.buildFormBuildings(r, "demo_"), time.Now())
+		case "drop":
+			res, err = s.build.DropLand(emp.Id, round, s.getFormNum(r, "drop_land"), time.Now())
+		default:
+			res = &build.Result_t{}
+		}
+		if err != nil {
+			log.Printf("%s %s: demolish: %v\n", r.Method, r.URL.Path, err)
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		notices = s.buildMessages(res.Messages)
+	}
+	s.demolishPage(w, r, emp, notices)
+}
+
+// demolishJsonPostHandler demolishes structures or drops land.
+// The request body holds the action ("demolish" or "drop"), the number of each
+// type of structure to demolish, and the acres of land to drop.
+func (s *server) demolishJsonPostHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	var input struct {
+		Action    string           `json:"action"`
+		Buildings buildBuildings_t `json:"buildings"`
+		Acres     int              `json:"acres"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, fmt.Sprintf("request: %v", err), http.StatusBadRequest)
+		return
+	} else if input.Action != "demolish" && input.Action != "drop" {
+		http.Error(w, fmt.Sprintf("action: unknown value %q", input.Action), http.StatusBadRequest)
+		return
+	}
+	round := s.roundData(time.Now())
+	if round.Finished {
+		http.Error(w, s.language.Printf("DEMOLISH_UNAVAILABLE_END"), http.StatusConflict)
+		return
+	} else if !round.Started {
+		http.Error(w, s.language.Printf("DEMOLISH_UNAVAILABLE_START"), http.StatusConflict)
+		return
+	}
+	var res *build.Result_t
+	var err error
+	if input.Action == "demolish" {
+		res, err = s.build.Demolish(emp.Id, round, buildBuildingsToEngine(input.Buildings), time.Now())
+	} else {
+		res, err = s.build.DropLand(emp.Id, round, max(input.Acres, 0), time.Now())
+	}
+	if err != nil {
+		log.Printf("%s %s: demolish: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	s.buildJsonResult(w, res)
+}
+
+// buildJsonResult writes the result of building, demolishing, or dropping land.
+func (s *server) buildJsonResult(w http.ResponseWriter, res *build.Result_t) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(buildResult_t{
+		Turns:    res.Turns,
+		Total:    res.Total,
+		Cash:     res.Cash,
+		Messages: s.buildMessages(res.Messages),
+		State:    buildStateFromService(res.State),
+	})
+}
+
+// buildPage writes the construction page.
+func (s *server) buildPage(w http.ResponseWriter, r *http.Request, emp *model.Empire_t, notices []string) {
+	notices = s.pageNotices(r, notices)
+	round := s.roundData(time.Now())
+	if round.Finished {
+		notices = append(notices, s.language.Printf("BUILD_UNAVAILABLE_END"))
+	} else if !round.Started {
+		notices = append(notices, s.language.Printf("BUILD_UNAVAILABLE_START"))
+	}
+	state, rows, ok := s.buildPageData(w, r, emp)
+	if !ok {
+		return
+	}
+	xlat := func(key string) string {
+		return html.EscapeString(s.language.Printf(key))
+	}
+
+	s.buildPageStart(w, "BUILD_TITLE", notices)
+	if !round.Started || round.Finished {
+		s.buildPageEnd(w)
+		return
+	}
+	// the header contains markup, so it is not escaped
+	_, _ = w.Write([]byte(`<p>` + s.language.Printf("BUILD_HEADER", s.language.Money(state.BuildCost), s.language.Number(state.BuildRate), s.language.Number(state.CanBuild)) + `</p>`))
+	_, _ = w.Write([]byte(`<form method="post" action="/build"><table><thead><tr>`))
+	for _, column := range []string{"COLUMN_STRUCTURE", "COLUMN_OWNED", "COLUMN_CANBUILD", "COLUMN_BUILD"} {
+		_, _ = w.Write([]byte(`<th>` + xlat(column) + `</th>`))
+	}
+	_, _ = w.Write([]byte(`</tr></thead><tbody>`))
+	for _, row := range rows {
+		_, _ = w.Write([]byte(fmt.Sprintf(`<tr><td>%s</td><td>%s (%s)</td><td>%s</td><td><input type="text" name="build_%s" size="5" value="0"/></td></tr>`,
+			xlat(row.name), s.language.Number(row.owned), s.buildPercent(row.owned, state.Land), s.language.Number(state.CanBuild), row.field)))
+	}
+	_, _ = w.Write([]byte(fmt.Sprintf(`<tr><td>%s</td><td>%s (%s)</td><td colspan="2"><input type="submit" value="%s"/></td></tr>`,
+		xlat("BUILD_LABEL_FREELAND"), s.language.Number(state.Buildings.Freeland), s.buildPercent(state.Buildings.Freeland, state.Land), xlat("BUILD_SUBMIT"))))
+	_, _ = w.Write([]byte(`</tbody></table></form>`))
+	_, _ = w.Write([]byte(`<p><a href="/demolish">` + xlat("BUILD_LINK_DEMOLISH") + `</a></p>`))
+	_, _ = w.Write([]byte(`<p><a href="/build.json">JSON</a></p>`))
+	s.buildPageEnd(w)
+}
+
+// demolishPage writes the demolition page.
+func (s *server) demolishPage(w http.ResponseWriter, r *http.Request, emp *model.Empire_t, notices []string) {
+	notices = s.pageNotices(r, notices)
+	round := s.roundData(time.Now())
+	if round.Finished {
+		notices = append(notices, s.language.Printf("DEMOLISH_UNAVAILABLE_END"))
+	} else if !round.Started {
+		notices = append(notices, s.language.Printf("DEMOLISH_UNAVAILABLE_START"))
+	}
+	state, rows, ok := s.buildPageData(w, r, emp)
+	if !ok {
+		return
+	}
+	xlat := func(key string) string {
+		return html.EscapeString(s.language.Printf(key))
+	}
+
+	s.buildPageStart(w, "DEMOLISH_TITLE", notices)
+	if !round.Started || round.Finished {
+		s.buildPageEnd(w)
+		return
+	}
+	// the headers contain markup, so they are not escaped
+	_, _ = w.Write([]byte(`<p>` + s.language.Printf("DEMOLISH_HEADER", s.language.Money(state.Salvage), s.language.Number(state.DemolishRate), s.language.Number(state.CanDemolish)) + `</p>`))
+	_, _ = w.Write([]byte(`<form method="post" action="/demolish"><input type="hidden" name="action" value="demolish"/><table><thead><tr>`))
+	for _, column := range []string{"COLUMN_STRUCTURE", "COLUMN_OWNED", "COLUMN_CANDEMO", "COLUMN_DEMO"} {
+		_, _ = w.Write([]byte(`<th>` + xlat(column) + `</th>`))
+	}
+	_, _ = w.Write([]byte(`</tr></thead><tbody>`))
+	for _, row := range rows {
+		_, _ = w.Write([]byte(fmt.Sprintf(`<tr><td>%s</td><td>%s (%s)</td><td>%s</td><td><input type="text" name="demo_%s" size="5" value="0"/></td></tr>`,
+			xlat(row.name), s.language.Number(row.owned), s.buildPercent(row.owned, state.Land), s.language.Number(min(row.owned, state.CanDemolish)), row.field)))
+	}
+	_, _ = w.Write([]byte(`<tr><td colspan="4"><input type="submit" value="` + xlat("DEMOLISH_SUBMIT") + `"/></td></tr>`))
+	_, _ = w.Write([]byte(`</tbody></table></form>`))
+
+	_, _ = w.Write([]byte(`<p>` + s.language.Printf("DEMOLISH_DROP_HEADER", s.language.Number(state.CanDrop), s.language.Number(state.DropRate)) + `</p>`))
+	_, _ = w.Write([]byte(`<form method="post" action="/demolish"><input type="hidden" name="action" value="drop"/><table><thead><tr>`))
+	for _, column := range []string{"COLUMN_STRUCTURE", "COLUMN_OWNED", "COLUMN_CANDROP", "COLUMN_DROP"} {
+		_, _ = w.Write([]byte(`<th>` + xlat(column) + `</th>`))
+	}
+	_, _ = w.Write([]byte(`</tr></thead><tbody>`))
+	_, _ = w.Write([]byte(fmt.Sprintf(`<tr><td>%s</td><td>%s (%s)</td><td>%s</td><td><input type="text" name="drop_land" size="5" value="0"/></td></tr>`,
+		xlat("BUILD_LABEL_FREELAND"), s.language.Number(state.Buildings.Freeland), s.buildPercent(state.Buildings.Freeland, state.Land), s.language.Number(state.CanDrop))))
+	_, _ = w.Write([]byte(`<tr><td colspan="4"><input type="submit" value="` + xlat("DEMOLISH_DROP_SUBMIT") + `"/></td></tr>`))
+	_, _ = w.Write([]byte(`</tbody></table></form>`))
+	_, _ = w.Write([]byte(`<p><a href="/build">` + xlat("DEMOLISH_LINK_BUILD") + `</a></p>`))
+	_, _ = w.Write([]byte(`<p><a href="/demolish.json">JSON</a></p>`))
+	s.buildPageEnd(w)
+}
+
+type buildRow_t struct {
+	field string // suffix of the form field
+	name  string // string ID of the structure's name in the empire's era
+	owned int
+}
+
+// buildPageData returns the state and the structure rows for the construction and demolition pages.
+// It writes an error response and returns false if they can not be loaded.
+func (s *server) buildPageData(w http.ResponseWriter, r *http.Request, emp *model.Empire_t) (*build.State_t, []buildRow_t, bool) {
+	state, err := s.build.State(emp.Id, time.Now())
+	if err != nil {
+		log.Printf("%s %s: state: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	era, err := s.tables.Era(emp.Era)
+	if err != nil {
+		log.Printf("%s %s: era: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	return state, []buildRow_t{
+		{"bldpop", era.BldPop, state.Buildings.BldPop},
+		{"bldcash", era.BldCash, state.Buildings.BldCash},
+		{"bldtrp", era.BldTrp, state.Buildings.BldTrp},
+		{"bldcost", era.BldCost, state.Buildings.BldCost},
+		{"bldwiz", era.BldWiz, state.Buildings.BldWiz},
+		{"bldfood", era.BldFood, state.Buildings.BldFood},
+		{"blddef", era.BldDef, state.Buildings.BldDef},
+	}, true
+}
+
+// buildPageStart writes the top of the construction and demolition pages.
+func (s *server) buildPageStart(w http.ResponseWriter, title string, notices []string) {
+	title = html.EscapeString(s.language.Printf(title))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(`<!DOCTYPE html><html lang="en"><head><meta charset="UTF-8"><title>` + title + `</title><link rel="stylesheet" href="https://unpkg.com/missing.css@1.1.1"></head><body>`))
+	_, _ = w.Write([]byte(`<h1>` + title + `</h1>`))
+	_, _ = w.Write([]byte(`<main>`))
+	for _, notice := range notices {
+		_, _ = w.Write([]byte(`<p class="box">` + html.EscapeString(notice) + `</p>`))
+	}
+}
+
+// buildPageEnd writes the bottom of the construction and demolition pages.
+func (s *server) buildPageEnd(w http.ResponseWriter) {
+	_, _ = w.Write([]byte(`</main>`))
+	_, _ = w.Write([]byte(`</body>`))
+}
+
+// buildPercent formats the share of the empire's land used by a structure.
+func (s *server) buildPercent(n, land int) string {
+	if land == 0 {
+		return s.language.Percent(0, 2)
+	}
+	return s.language.Percent(float64(n)/float64(land)*100, 2)
+}
+
+// buildFormBuildings returns the number of each type of structure from the posted form.
+func (s *server) buildFormBuildings(r *http.Request, prefix string) engine.Buildings_t {
+	return engine.Buildings_t{
+		BldPop:  s.getFormNum(r, prefix+"bldpop"),
+		BldCash: s.getFormNum(r, prefix+"bldcash"),
+		BldTrp:  s.getFormNum(r, prefix+"bldtrp"),
+		BldCost: s.getFormNum(r, prefix+"bldcost"),
+		BldWiz:  s.getFormNum(r, prefix+"bldwiz"),
+		BldFood: s.getFormNum(r, prefix+"bldfood"),
+		BldDef:  s.getFormNum(r, prefix+"blddef"),
+	}
+}
+
+// buildMessages translates the messages from building, demolishing, and dropping land.
+func (s *server) buildMessages(messages []engine.Message_t) []string {
+	var list []string
+	for _, msg := range messages {
+		var args []any
+		switch msg.Key {
+		case "BUILD_COMPLETE":
+			args = []any{s.language.Money(msg.Args[0].(int)), s.buildTurns(msg.Args[1].(int)), s.buildStructures(msg.Args[2].(int))}
+		case "DEMOLISH_COMPLETE":
+			args = []any{s.buildTurns(msg.Args[0].(int)), s.buildStructures(msg.Args[1].(int)), s.language.Money(msg.Args[2].(int))}
+		case "DEMOLISH_DROP_COMPLETE":
+			args = []any{s.buildTurns(msg.Args[0].(int)), s.language.Plural(msg.Args[1].(int), "ACRES_SINGLE", "ACRES_PLURAL", "")}
+		default:
+			args = msg.Args
+		}
+		list = append(list, s.language.Printf(msg.Key, args...))
+	}
+	return list
+}
+
+func (s *server) buildTurns(n int) string {
+	return s.language.Plural(n, "TURNS_SINGLE", "TURNS_PLURAL", "")
+}
+
+func (s *server) buildStructures(n int) string {
+	return s.language.Plural(n, "STRUCTURES_SINGLE", "STRUCTURES_PLURAL", "")
+}
+
+func buildBuildingsToEngine(b buildBuildings_t) engine.Buildings_t {
+	return engine.Buildings_t{
+		BldPop:  max(b.BldPop, 0),
+		BldCash: max(b.BldCash, 0),
+		BldTrp:  max(b.BldTrp, 0),
+		BldCost: max(b.BldCost, 0),
+		BldWiz:  max(b.BldWiz, 0),
+		BldFood: max(b.BldFood, 0),
+		BldDef:  max(b.BldDef, 0),
+	}
+}
+
+func buildStateFromService(state *build.State_t) buildState_t {
+	return buildState_t{
+		Cash:  state.Cash,
+		Turns: state.Turns,
+		Land:  state.Land,
+		Buildings: buildBuildings_t{
+			BldPop:   state.Buildings.BldPop,
+			BldCash:  state.Buildings.BldCash,
+			BldTrp:   state.Buildings.BldTrp,
+			BldCost:  state.Buildings.BldCost,
+			BldWiz:   state.Buildings.BldWiz,
+			BldFood:  state.Buildings.BldFood,
+			BldDef:   state.Buildings.BldDef,
+			Freeland: state.Buildings.Freeland,
+		},
+		BuildCost:    state.BuildCost,
+		BuildRate:    state.BuildRate,
+		CanBuild:     state.CanBuild,
+		Salvage:      state.Salvage,
+		DemolishRate: state.DemolishRate,
+		CanDemolish:  state.CanDemolish,
+		DropRate:     state.DropRate,
+		CanDrop:      state.CanDrop,
+	}
+}
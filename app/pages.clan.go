@@ -0,0 +1,547 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/clans"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"html"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type clanInfo_t struct {
+	Id        int    `json:"id"`
+	Name      string `json:"name"`
+	Title     string `json:"title"`
+	URL       string `json:"url,omitempty"`
+	Pic       string `json:"pic,omitempty"`
+	Members   int    `json:"members"`
+	Leader    int    `json:"leader"`
+	Assistant int    `json:"assistant,omitempty"`
+	Minister1 int    `json:"minister1,omitempty"`
+	Minister2 int    `json:"minister2,omitempty"`
+}
+
+type clanMember_t struct {
+	Id       int    `json:"id"`
+	Name     string `json:"name"`
+	Land     int    `json:"land"`
+	Networth int    `json:"networth"`
+	Sharing  bool   `json:"sharing"`
+}
+
+type clanRelation_t struct {
+	Id     int    `json:"id"`
+	Clan   int    `json:"clan"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Since  string `json:"since"`
+}
+
+type clanInvite_t struct {
+	Id            int    `json:"id"`
+	Clan          int    `json:"clan"`
+	ClanName      string `json:"clan_name"`
+	ClanTitle     string `json:"clan_title,omitempty"`
+	Sender        int    `json:"sender"`
+	SenderName    string `json:"sender_name"`
+	Recipient     int    `json:"recipient"`
+	RecipientName string `json:"recipient_name"`
+	Permanent     bool   `json:"permanent"`
+	Time          string `json:"time"`
+}
+
+type clanState_t struct {
+	Clan      *clanInfo_t      `json:"clan,omitempty"`
+	Members   []clanMember_t   `json:"members,omitempty"`
+	Relations []clanRelation_t `json:"relations,omitempty"`
+	Clans     []clanInfo_t     `json:"clans,omitempty"`
+	Invites   []clanInvite_t   `json:"invites,omitempty"`
+	Wait      int              `json:"wait"`
+	Closing   bool             `json:"closing"`
+	Sharing   bool             `json:"sharing"`
+	Unshare   int              `json:"unshare,omitempty"`
+	Share     engine.Units_t   `json:"share"`
+	Shared    engine.Units_t   `json:"shared"`
+}
+
+type clanResult_t struct {
+	Success  bool        `json:"success"`
+	Messages []string    `json:"messages"`
+	State    clanState_t `json:"state"`
+}
+
+// clanGetHandler shows the empire's clan, or the forms to create or join one, from php/pages/clan.php.
+func (s *server) clanGetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	s.clanPage(w, r, emp, nil)
+}
+
+// clanPostHandler creates, joins, or leaves a clan, changes its password, or shares
+// or unshares the empire's forces, from the posted form.
+func (s *server) clanPostHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	action, _ := s.getFormVar(r, "action", "")
+	var input clanInput_t
+	switch action {
+	case "create":
+		input.Name, _ = s.getFormVar(r, "create_name", "")
+		input.Password, _ = s.getFormVar(r, "create_pass", "")
+		input.Verify, _ = s.getFormVar(r, "create_pass_verify", "")
+	case "join":
+		input.Clan = s.getFormNum(r, "join_id")
+		input.Password, _ = s.getFormVar(r, "join_pass", "")
+	case "invite":
+		input.Clan = s.getFormNum(r, "join_id")
+		input.Invite = s.getFormNum(r, "invite_id")
+	case "leave":
+		confirm, _ := s.getFormVar(r, "confirm", "")
+		input.Confirm = confirm != ""
+	case "password":
+		input.Password, _ = s.getFormVar(r, "new_password", "")
+		input.Verify, _ = s.getFormVar(r, "new_password_verify", "")
+	}
+	var notices []string
+	res, err := s.clanAction(emp, action, input)
+	if err != nil {
+		if key, _, ok := clanUnavailable(err); ok {
+			notices = append(notices, s.language.Printf(key))
+		} else {
+			log.Printf("%s %s: %s: %v\n", r.Method, r.URL.Path, action, err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	} else if res != nil {
+		notices = s.clanMessages(res.Messages)
+	}
+	s.clanPage(w, r, emp, notices)
+}
+
+// clanJsonGetHandler returns the empire's clan, or the clans it may join, as JSON.
+func (s *server) clanJsonGetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	state, err := s.clans.State(emp.Id, s.roundData(time.Now()), time.Now())
+	if err != nil {
+		if key, status, ok := clanUnavailable(err); ok {
+			http.Error(w, s.language.Printf(key), status)
+			return
+		}
+		log.Printf("%s %s: state: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(clanStateFromService(state))
+}
+
+// clanJsonPostHandler creates, joins, or leaves a clan, or changes its password.
+// The request body holds the action, one of "create", "join", "invite", "leave",
+// "password", "share", or "unshare", and the fields that action needs.
+func (s *server) clanJsonPostHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	var input struct {
+		Action string `json:"action"`
+		clanInput_t
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, fmt.Sprintf("request: %v", err), http.StatusBadRequest)
+		return
+	}
+	res, err := s.clanAction(emp, input.Action, input.clanInput_t)
+	if err != nil {
+		if key, status, ok := clanUnavailable(err); ok {
+			http.Error(w, s.language.Printf(key), status)
+			return
+		}
+		log.Printf("%s %s: %s: %v\n", r.Method, r.URL.Path, input.Action, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	} else if res == nil {
+		http.Error(w, fmt.Sprintf("action: unknown value %q", input.Action), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(clanResult_t{
+		Success:  res.Success,
+		Messages: s.clanMessages(res.Messages),
+		State:    clanStateFromService(res.State),
+	})
+}
+
+// clanInput_t holds the fields used by the clan actions.
+type clanInput_t struct {
+	Name     string `json:"name"`
+	Clan     int    `json:"clan"`
+	Password string `json:"password"`
+	Verify   string `json:"verify"`
+	Confirm  bool   `json:"confirm"`
+	Invite   int    `json:"invite"`
+}
+
+// clanAction runs the clan action. It returns a nil result if the action is not known.
+func (s *server) clanAction(emp *model.Empire_t, action string, input clanInput_t) (*clans.Result_t, error) {
+	round := s.roundData(time.Now())
+	switch action {
+	case "create":
+		return s.clans.Create(emp.Id, round, input.Name, input.Password, input.Verify, time.Now())
+	case "join":
+		return s.clans.Join(emp.Id, round, input.Clan, input.Password, time.Now())
+	case "invite":
+		return s.clans.Accept(emp.Id, round, input.Clan, input.Invite, time.Now())
+	case "leave":
+		return s.clans.Leave(emp.Id, round, input.Confirm, time.Now())
+	case "password":
+		return s.clans.SetPassword(emp.Id, round, input.Password, input.Verify, time.Now())
+	case "share":
+		return s.clans.Share(emp.Id, round, time.Now())
+	case "unshare":
+		return s.clans.Unshare(emp.Id, round, time.Now())
+	}
+	return nil, nil
+}
+
+// clanUnavailable returns the message and status explaining why the empire may not use the clan page.
+func clanUnavailable(err error) (string, int, bool) {
+	switch {
+	case errors.Is(err, cerr.ErrRoundFinished):
+		return "CLAN_UNAVAILABLE_END", http.StatusConflict, true
+	case errors.Is(err, cerr.ErrRoundNotStarted):
+		return "CLAN_UNAVAILABLE_START", http.StatusConflict, true
+	case errors.Is(err, cerr.ErrEmpireProtected):
+		return "CLAN_UNAVAILABLE_PROTECT", http.StatusConflict, true
+	case errors.Is(err, cerr.ErrEmpireAdmin):
+		return "CLAN_UNAVAILABLE_ADMIN", http.StatusForbidden, true
+	case errors.Is(err, cerr.ErrClansDisabled):
+		return "CLAN_UNAVAILABLE_CONFIG", http.StatusConflict, true
+	case errors.Is(err, cerr.ErrNotClanMember):
+		return "CLAN_NOT_MEMBER", http.StatusForbidden, true
+	case errors.Is(err, cerr.ErrNotClanOfficer):
+		return "MANAGE_CLAN_NEED_PERMISSION", http.StatusForbidden, true
+	}
+	return "", 0, false
+}
+
+// clanPage writes the clan page.
+func (s *server) clanPage(w http.ResponseWriter, r *http.Request, emp *model.Empire_t, notices []string) {
+	notices = s.pageNotices(r, notices)
+	state, err := s.clans.State(emp.Id, s.roundData(time.Now()), time.Now())
+	if key, _, ok := clanUnavailable(err); ok {
+		notices = append(notices, s.language.Printf(key))
+	} else if err != nil {
+		log.Printf("%s %s: state: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	xlat := func(key string) string {
+		return html.EscapeString(s.language.Printf(key))
+	}
+
+	s.buildPageStart(w, "CLAN_TITLE", notices)
+	if state == nil {
+		s.buildPageEnd(w)
+		return
+	}
+
+	if clan := state.Clan; clan != nil {
+		// the header contains markup, so only the name is escaped
+		_, _ = w.Write([]byte(`<h2>` + s.language.Printf("CLAN_MEMBER_HEADER", html.EscapeString(clan.Name)) + `</h2>`))
+		if clan.Title != "" {
+			_, _ = w.Write([]byte(`<p>` + html.EscapeString(clan.Title) + `</p>`))
+		}
+		_, _ = w.Write([]byte(`<p>` + html.EscapeString(s.language.Printf("CLAN_MEMBERS_HEADER", clan.Name, s.language.Number(clan.Members))) + `</p>`))
+		_, _ = w.Write([]byte(`<table><caption>` + xlat("CLAN_MEMBERS_LABEL") + `</caption><thead><tr>`))
+		for _, column := range []string{"COLUMN_EMPIRE", "COLUMN_LAND", "COLUMN_NETWORTH", "COLUMN_RANK", "COLUMN_SHARING"} {
+			_, _ = w.Write([]byte(`<th>` + xlat(column) + `</th>`))
+		}
+		_, _ = w.Write([]byte(`</tr></thead><tbody>`))
+		for _, member := range state.Members {
+			office := ""
+			switch member.Id {
+			case clan.Leader:
+				office = xlat("CLAN_LEADER_LABEL")
+			case clan.Assistant:
+				office = xlat("CLAN_ASSISTANT_LABEL")
+			case clan.Minister1, clan.Minister2:
+				office = xlat("CLAN_FA_LABEL")
+			}
+			sharing := `<span class="cbad">` + xlat("COMMON_NO") + `</span>`
+			if member.Sharing != 0 {
+				sharing = `<span class="cgood">` + xlat("COMMON_YES") + `</span>`
+			}
+			_, _ = w.Write([]byte(fmt.Sprintf(`<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>`,
+				html.EscapeString(fmt.Sprintf("%s (%s)", member.Name, s.language.Prenum(member.Id))),
+				s.language.Number(member.Land), s.language.Money(member.Networth), office, sharing)))
+		}
+		_, _ = w.Write([]byte(`</tbody></table>`))
+
+		// empires that are unsharing may share again before the delay runs out
+		action, submit := "share", "CLAN_SHARE_SUBMIT"
+		if state.Sharing && state.Unshare == 0 {
+			action, submit = "unshare", "CLAN_UNSHARE_SUBMIT"
+		}
+		_, _ = w.Write([]byte(`<form method="post" action="/clan"><input type="hidden" name="action" value="` + action + `"/>`))
+		_, _ = w.Write([]byte(`<p><input type="submit" value="` + xlat(submit) + `"/></p></form>`))
+		if state.Sharing {
+			if err := s.clanSharedTable(w, emp, state); err != nil {
+				log.Printf("%s %s: shared: %v\n", r.Method, r.URL.Path, err)
+			}
+		}
+
+		// relations are grouped by status, with a label before each group
+		_, _ = w.Write([]byte(`<h3>` + html.EscapeString(s.language.Printf("CLAN_RELATIONS_HEADER", clan.Title)) + `</h3>`))
+		_, _ = w.Write([]byte(`<table><thead><tr><th>` + xlat("CLAN_ALLY_LABEL") + `</th><th>` + xlat("CLAN_WAR_LABEL") + `</th></tr></thead><tbody><tr>`))
+		for _, ally := range []bool{true, false} {
+			var lines []string
+			var label string
+			for _, rel := range state.Relations {
+				if rel.Status.Ally() != ally {
+					continue
+				} else if clanRelationLabel[rel.Status] != label {
+					label = clanRelationLabel[rel.Status]
+					lines = append(lines, `<b>`+xlat(label)+`</b>`)
+				}
+				lines = append(lines, html.EscapeString(rel.Name))
+			}
+			if len(lines) == 0 && ally {
+				lines = append(lines, xlat("CLAN_ALLY_NONE_LABEL"))
+			} else if len(lines) == 0 {
+				lines = append(lines, xlat("CLAN_WAR_NONE_LABEL"))
+			}
+			_, _ = w.Write([]byte(`<td>` + strings.Join(lines, `<br/>`) + `</td>`))
+		}
+		_, _ = w.Write([]byte(`</tr></tbody></table>`))
+		_, _ = w.Write([]byte(`<p><a href="/clan/forum">` + xlat("CLAN_LINK_FORUM") + `</a></p>`))
+		if slices.Contains([]int{clan.Leader, clan.Assistant, clan.Minister1, clan.Minister2}, emp.Id) {
+			_, _ = w.Write([]byte(`<p><a href="/clan/manage">` + xlat("CLAN_LINK_MANAGE") + `</a></p>`))
+		}
+
+		if emp.Id == clan.Leader || emp.Id == clan.Assistant {
+			_, _ = w.Write([]byte(`<form method="post" action="/clan"><input type="hidden" name="action" value="password"/>`))
+			_, _ = w.Write([]byte(`<h3>` + xlat("MANAGE_CLAN_PASSWORD_LABEL") + `</h3>`))
+			_, _ = w.Write([]byte(`<p><label>` + xlat("LABEL_PASSWORD_NEW") + ` <input type="password" name="new_password" size="8"/></label></p>`))
+			_, _ = w.Write([]byte(`<p><label>` + xlat("LABEL_PASSWORD_VERIFY") + ` <input type="password" name="new_password_verify" size="8"/></label></p>`))
+			_, _ = w.Write([]byte(`<p><input type="submit" value="` + xlat("MANAGE_CLAN_PASSWORD_SUBMIT") + `"/></p></form>`))
+		}
+
+		submit, confirm := "CLAN_LEAVE_SUBMIT", "CLAN_LEAVE_CONFIRM"
+		if emp.Id == clan.Leader {
+			submit, confirm = "CLAN_DISBAND_SUBMIT", "CLAN_DISBAND_CONFIRM"
+		}
+		_, _ = w.Write([]byte(`<form method="post" action="/clan"><input type="hidden" name="action" value="leave"/>`))
+		_, _ = w.Write([]byte(`<p><input type="submit" value="` + xlat(submit) + `"/> <label><input type="checkbox" name="confirm" value="1"/> ` + xlat(confirm) + `</label></p></form>`))
+		_, _ = w.Write([]byte(`<p><a href="/clan.json">JSON</a></p>`))
+		s.buildPageEnd(w)
+		return
+	}
+
+	_, _ = w.Write([]byte(`<p>` + xlat("CLAN_NONMEMBER_HEADER") + `</p>`))
+	if len(state.Clans) != 0 {
+		_, _ = w.Write([]byte(`<form method="post" action="/clan"><input type="hidden" name="action" value="join"/>`))
+		_, _ = w.Write([]byte(`<h3>` + xlat("CLAN_JOIN_LABEL") + `</h3>`))
+		_, _ = w.Write([]byte(`<p><select name="join_id">`))
+		for _, clan := range state.Clans {
+			label := clan.Name
+			if clan.Title != "" {
+				label = s.language.Printf("CLAN_JOIN_LABEL_WITH_TITLE", clan.Name, clan.Title)
+			}
+			_, _ = w.Write([]byte(`<option value="` + strconv.Itoa(clan.Id) + `">` + html.EscapeString(label) + `</option>`))
+		}
+		_, _ = w.Write([]byte(`</select></p>`))
+		_, _ = w.Write([]byte(`<p><label>` + xlat("LABEL_PASSWORD") + ` <input type="password" name="join_pass" size="8"/></label></p>`))
+		_, _ = w.Write([]byte(`<p><input type="submit" value="` + xlat("CLAN_JOIN_SUBMIT") + `"/></p></form>`))
+		if len(state.Invites) != 0 {
+			_, _ = w.Write([]byte(`<h3>` + xlat("CLAN_INVITE_HEADER") + `</h3>`))
+			_, _ = w.Write([]byte(`<table><tbody>`))
+			for _, inv := range state.Invites {
+				label, kind := inv.ClanName, "CLAN_INVITE_TEMP_LABEL"
+				if inv.ClanTitle != "" {
+					label = s.language.Printf("CLAN_JOIN_LABEL_WITH_TITLE", inv.ClanName, inv.ClanTitle)
+				}
+				if inv.Permanent {
+					kind = "CLAN_INVITE_PERM_LABEL"
+				}
+				_, _ = w.Write([]byte(`<tr><td>` + html.EscapeString(label) + `</td><td>` + xlat(kind) + `</td>`))
+				_, _ = w.Write([]byte(`<td><form method="post" action="/clan"><input type="hidden" name="action" value="invite"/>`))
+				_, _ = w.Write([]byte(`<input type="hidden" name="join_id" value="` + strconv.Itoa(inv.Clan) + `"/><input type="hidden" name="invite_id" value="` + strconv.Itoa(inv.Id) + `"/>`))
+				_, _ = w.Write([]byte(`<input type="submit" value="` + xlat("CLAN_JOIN_SUBMIT") + `"/></form></td></tr>`))
+			}
+			_, _ = w.Write([]byte(`</tbody></table>`))
+		}
+	} else if state.Closing {
+		_, _ = w.Write([]byte(`<p>` + xlat("CLAN_JOIN_NONE_UNAVAILABLE") + `</p>`))
+	} else {
+		_, _ = w.Write([]byte(`<p>` + xlat("CLAN_JOIN_NONE_AVAILABLE") + `</p>`))
+	}
+	if state.Closing {
+		_, _ = w.Write([]byte(`<p>` + xlat("CLAN_JOIN_LATE") + `</p>`))
+	} else {
+		_, _ = w.Write([]byte(`<form method="post" action="/clan"><input type="hidden" name="action" value="create"/>`))
+		_, _ = w.Write([]byte(`<h3>` + xlat("CLAN_CREATE_LABEL") + `</h3>`))
+		_, _ = w.Write([]byte(`<p><label>` + xlat("LABEL_CLAN") + ` <input type="text" name="create_name" size="8" maxlength="8"/></label></p>`))
+		_, _ = w.Write([]byte(`<p><label>` + xlat("LABEL_PASSWORD") + ` <input type="password" name="create_pass" size="8"/></label></p>`))
+		_, _ = w.Write([]byte(`<p><label>` + xlat("LABEL_PASSWORD_VERIFY") + ` <input type="password" name="create_pass_verify" size="8"/></label></p>`))
+		_, _ = w.Write([]byte(`<p><input type="submit" value="` + xlat("CLAN_CREATE_SUBMIT") + `"/></p></form>`))
+	}
+	_, _ = w.Write([]byte(`<p><a href="/clan.json">JSON</a></p>`))
+	s.buildPageEnd(w)
+}
+
+// clanSharedTable writes the forces the empire and its clan share, from php/pages/clan.php.
+func (s *server) clanSharedTable(w http.ResponseWriter, emp *model.Empire_t, state *clans.State_t) error {
+	era, err := s.tables.Era(emp.Era)
+	if err != nil {
+		return err
+	}
+	xlat := func(key string) string {
+		return html.EscapeString(s.language.Printf(key))
+	}
+	caption := xlat("CLAN_SHARE_HEADER")
+	if state.Unshare > 0 {
+		caption += html.EscapeString(s.language.Printf("CLAN_UNSHARE_HEADER", s.language.Number(state.Unshare)))
+	}
+	_, _ = w.Write([]byte(`<table><caption>` + caption + `</caption><thead><tr>`))
+	for _, column := range []string{"COLUMN_UNIT", "COLUMN_SHAREYOU", "COLUMN_SHARETOTAL"} {
+		_, _ = w.Write([]byte(`<th>` + xlat(column) + `</th>`))
+	}
+	_, _ = w.Write([]byte(`</tr></thead><tbody>`))
+	for _, row := range []struct {
+		name          string
+		share, shared int
+	}{
+		{era.TrpArm, state.Share.TrpArm, state.Shared.TrpArm},
+		{era.TrpLnd, state.Share.TrpLnd, state.Shared.TrpLnd},
+		{era.TrpFly, state.Share.TrpFly, state.Shared.TrpFly},
+		{era.TrpSea, state.Share.TrpSea, state.Shared.TrpSea},
+	} {
+		_, _ = w.Write([]byte(fmt.Sprintf(`<tr><th>%s</th><td>%s</td><td>%s</td></tr>`,
+			xlat(row.name), s.language.Number(row.share), s.language.Number(row.shared))))
+	}
+	_, _ = w.Write([]byte(`</tbody></table>`))
+	return nil
+}
+
+// clanRelationLabel is the label for each group of relations on the clan page.
+// Wars declared by either clan are shown together.
+var clanRelationLabel = map[clans.Status_t]string{
+	clans.ALLY_MUTUAL:         "CLAN_ALLY_MUTUAL_LABEL",
+	clans.ALLY_OUTBOUND:       "CLAN_ALLY_OUTBOUND_LABEL",
+	clans.ALLY_INBOUND:        "CLAN_ALLY_INBOUND_LABEL",
+	clans.WAR_MUTUAL_OUTBOUND: "CLAN_WAR_MUTUAL_LABEL",
+	clans.WAR_MUTUAL_INBOUND:  "CLAN_WAR_MUTUAL_LABEL",
+	clans.WAR_OUTBOUND:        "CLAN_WAR_OUTBOUND_LABEL",
+	clans.WAR_INBOUND:         "CLAN_WAR_INBOUND_LABEL",
+}
+
+// clanMessages translates the messages from clan actions.
+// Numbers are hours and are formatted; strings are clan names and are not translated.
+func (s *server) clanMessages(messages []engine.Message_t) []string {
+	var list []string
+	for _, msg := range messages {
+		var args []any
+		for _, arg := range msg.Args {
+			switch v := arg.(type) {
+			case int:
+				args = append(args, s.language.Number(v))
+			default:
+				args = append(args, v)
+			}
+		}
+		list = append(list, s.language.Printf(msg.Key, args...))
+	}
+	return list
+}
+
+// clanInfoFromModel converts a clan, leaving out its password.
+func clanInfoFromModel(clan *model.Clan_t) clanInfo_t {
+	return clanInfo_t{
+		Id:        clan.Id,
+		Name:      clan.Name,
+		Title:     clan.Title,
+		URL:       clan.URL,
+		Pic:       clan.Pic,
+		Members:   clan.Members,
+		Leader:    clan.Leader,
+		Assistant: clan.Assistant,
+		Minister1: clan.Minister1,
+		Minister2: clan.Minister2,
+	}
+}
+
+func clanStateFromService(state *clans.State_t) clanState_t {
+	out := clanState_t{
+		Wait:    state.Wait,
+		Closing: state.Closing,
+		Sharing: state.Sharing,
+		Unshare: state.Unshare,
+		Share:   state.Share,
+		Shared:  state.Shared,
+	}
+	if state.Clan != nil {
+		clan := clanInfoFromModel(state.Clan)
+		out.Clan = &clan
+	}
+	for _, member := range state.Members {
+		out.Members = append(out.Members, clanMember_t{
+			Id:       member.Id,
+			Name:     member.Name,
+			Land:     member.Land,
+			Networth: member.Networth,
+			Sharing:  member.Sharing != 0,
+		})
+	}
+	for _, rel := range state.Relations {
+		out.Relations = append(out.Relations, clanRelation_t{
+			Id:     rel.Id,
+			Clan:   rel.Clan,
+			Name:   rel.Name,
+			Status: string(rel.Status),
+			Since:  rel.Since.Format(time.RFC3339),
+		})
+	}
+	for _, clan := range state.Clans {
+		out.Clans = append(out.Clans, clanInfoFromModel(clan))
+	}
+	for _, inv := range state.Invites {
+		out.Invites = append(out.Invites, clanInvite_t{
+			Id:            inv.Id,
+			Clan:          inv.Clan,
+			ClanName:      inv.ClanName,
+			ClanTitle:     inv.ClanTitle,
+			Sender:        inv.Sender,
+			SenderName:    inv.SenderName,
+			Recipient:     inv.Recipient,
+			RecipientName: inv.RecipientName,
+			Permanent:     inv.Permanent,
+			Time:          inv.Time.Format(time.RFC3339),
+		})
+	}
+	return out
+}
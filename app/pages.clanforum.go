@@ -0,0 +1,499 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/mdhender/promisance/app/bbcode"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/clans"
+	"github.com/mdhender/promisance/app/model"
+	"html"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type clanForumTopic_t struct {
+	Id             int    `json:"id"`
+	Subject        string `json:"subject,omitempty"`
+	News           bool   `json:"news,omitempty"`
+	Sticky         bool   `json:"sticky,omitempty"`
+	Locked         bool   `json:"locked,omitempty"`
+	Author         int    `json:"author"`
+	AuthorName     string `json:"author_name"`
+	Replies        int    `json:"replies"`
+	LastPoster     int    `json:"last_poster"`
+	LastPosterName string `json:"last_poster_name"`
+	LastPost       string `json:"last_post"`
+}
+
+type clanForumPost_t struct {
+	Id         int    `json:"id"`
+	Author     int    `json:"author"`
+	AuthorName string `json:"author_name"`
+	Rank       string `json:"rank,omitempty"`
+	Body       string `json:"body"`
+	Time       string `json:"time"`
+	Edited     bool   `json:"edited,omitempty"`
+	CanEdit    bool   `json:"can_edit,omitempty"`
+	CanDelete  bool   `json:"can_delete,omitempty"`
+}
+
+type clanForum_t struct {
+	Moderator bool               `json:"moderator"`
+	Ranked    bool               `json:"ranked"`
+	Topics    []clanForumTopic_t `json:"topics,omitempty"`
+	Topic     *clanForumTopic_t  `json:"topic,omitempty"`
+	Posts     []clanForumPost_t  `json:"posts,omitempty"`
+	CanPost   bool               `json:"can_post"`
+	Quote     string             `json:"quote,omitempty"`
+	Post      *clanForumPost_t   `json:"post,omitempty"`
+	First     bool               `json:"first,omitempty"`
+	KillTopic bool               `json:"kill_topic,omitempty"`
+	Page      int                `json:"page"`
+	Pages     int                `json:"pages"`
+}
+
+type clanForumResult_t struct {
+	Success  bool        `json:"success"`
+	Messages []string    `json:"messages"`
+	Forum    clanForum_t `json:"forum"`
+}
+
+// clanForumInput_t holds the fields used by the clan forum actions.
+type clanForumInput_t struct {
+	Topic   int    `json:"topic"`
+	Post    int    `json:"post"`
+	Page    int    `json:"-"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+	Sticky  bool   `json:"sticky"`
+	Locked  bool   `json:"locked"`
+}
+
+// clanForumGetHandler shows the clan forum's index, a topic, or the form to
+// edit or delete a post, from php/pages/clanforum.php.
+func (s *server) clanForumGetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	action, _ := s.getFormVar(r, "action", "")
+	input := clanForumInput_t{
+		Topic: s.getFormNum(r, "topic_id"),
+		Post:  s.getFormNum(r, "post_id"),
+		Page:  s.getFormNum(r, "page"),
+	}
+	var res *clans.Result_t
+	var err error
+	if action == "edit_form" || action == "delete_form" {
+		res, err = s.clanForumAction(emp, action, input)
+	} else {
+		action = "view"
+		res, err = s.clans.Forum(emp.Id, s.roundData(time.Now()), input.Topic, input.Page, input.Post)
+	}
+	if err != nil {
+		if key, _, ok := clanForumUnavailable(err); ok {
+			s.buildPageStart(w, "CLANFORUM_TITLE", s.pageNotices(r, []string{s.language.Printf(key)}))
+			s.buildPageEnd(w)
+			return
+		}
+		log.Printf("%s %s: %s: %v\n", r.Method, r.URL.Path, action, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	s.clanForumPage(w, r, action, res, input)
+}
+
+// clanForumPostHandler starts a topic, replies to one, or edits or deletes a post, from the posted form.
+func (s *server) clanForumPostHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	action, _ := s.getFormVar(r, "action", "")
+	input := clanForumInput_t{
+		Topic: s.getFormNum(r, "topic_id"),
+		Post:  s.getFormNum(r, "post_id"),
+	}
+	input.Subject, _ = s.getFormVar(r, "topic_subject", "")
+	input.Body, _ = s.getFormVar(r, "post_body", "")
+	input.Sticky = s.getFormNum(r, "topic_sticky") != 0
+	input.Locked = s.getFormNum(r, "topic_locked") != 0
+	res, err := s.clanForumAction(emp, action, input)
+	if err == nil && res == nil {
+		// unknown actions show the index
+		res, err = s.clans.Forum(emp.Id, s.roundData(time.Now()), 0, 0, 0)
+	}
+	if err != nil {
+		if key, _, ok := clanForumUnavailable(err); ok {
+			s.buildPageStart(w, "CLANFORUM_TITLE", s.pageNotices(r, []string{s.language.Printf(key)}))
+			s.buildPageEnd(w)
+			return
+		}
+		log.Printf("%s %s: %s: %v\n", r.Method, r.URL.Path, action, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if res.Success {
+		// the post was saved, so the forms start out empty
+		input = clanForumInput_t{}
+	}
+	s.clanForumPage(w, r, action, res, input)
+}
+
+// clanForumJsonGetHandler returns a page of the clan forum's index, or of a
+// topic if topic_id is set, as JSON. If post_id is set, the post's page is returned
+// along with a quote of it.
+func (s *server) clanForumJsonGetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	res, err := s.clans.Forum(emp.Id, s.roundData(time.Now()), s.getFormNum(r, "topic_id"), s.getFormNum(r, "page"), s.getFormNum(r, "post_id"))
+	if err != nil {
+		if key, status, ok := clanForumUnavailable(err); ok {
+			http.Error(w, s.language.Printf(key), status)
+			return
+		}
+		log.Printf("%s %s: forum: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(s.clanForumResult(res))
+}
+
+// clanForumJsonPostHandler starts a topic, replies to one, or edits or deletes a post.
+// The request body holds the action, one of "post", "reply", "edit", "delete",
+// "edit_form", or "delete_form", and the fields that action needs.
+func (s *server) clanForumJsonPostHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	var input struct {
+		Action string `json:"action"`
+		clanForumInput_t
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, fmt.Sprintf("request: %v", err), http.StatusBadRequest)
+		return
+	}
+	res, err := s.clanForumAction(emp, input.Action, input.clanForumInput_t)
+	if err != nil {
+		if key, status, ok := clanForumUnavailable(err); ok {
+			http.Error(w, s.language.Printf(key), status)
+			return
+		}
+		log.Printf("%s %s: %s: %v\n", r.Method, r.URL.Path, input.Action, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	} else if res == nil {
+		http.Error(w, fmt.Sprintf("action: unknown value %q", input.Action), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(s.clanForumResult(res))
+}
+
+// clanForumAction runs the clan forum action. It returns a nil result if the action is not known.
+func (s *server) clanForumAction(emp *model.Empire_t, action string, input clanForumInput_t) (*clans.Result_t, error) {
+	round := s.roundData(time.Now())
+	switch action {
+	case "post":
+		return s.clans.NewTopic(emp.Id, round, input.Subject, input.Body, input.Sticky, input.Locked, time.Now())
+	case "reply":
+		return s.clans.Reply(emp.Id, round, input.Topic, input.Body, time.Now())
+	case "edit":
+		return s.clans.Edit(emp.Id, round, input.Topic, input.Post, input.Subject, input.Body, input.Sticky, input.Locked, time.Now())
+	case "delete":
+		return s.clans.Delete(emp.Id, round, input.Topic, input.Post)
+	case "edit_form":
+		return s.clans.Message(emp.Id, round, input.Topic, input.Post, false)
+	case "delete_form":
+		return s.clans.Message(emp.Id, round, input.Topic, input.Post, true)
+	}
+	return nil, nil
+}
+
+// clanForumUnavailable returns the message and status explaining why the empire may not use the clan forum.
+func clanForumUnavailable(err error) (string, int, bool) {
+	switch {
+	case errors.Is(err, cerr.ErrRoundFinished):
+		return "CLANFORUM_UNAVAILABLE_END", http.StatusConflict, true
+	case errors.Is(err, cerr.ErrRoundNotStarted):
+		return "CLANFORUM_UNAVAILABLE_START", http.StatusConflict, true
+	case errors.Is(err, cerr.ErrEmpireProtected):
+		return "CLANFORUM_UNAVAILABLE_PROTECT", http.StatusConflict, true
+	case errors.Is(err, cerr.ErrEmpireAdmin):
+		return "CLANFORUM_UNAVAILABLE_ADMIN", http.StatusForbidden, true
+	case errors.Is(err, cerr.ErrClansDisabled):
+		return "CLANFORUM_UNAVAILABLE_CONFIG", http.StatusConflict, true
+	case errors.Is(err, cerr.ErrNotClanMember):
+		return "CLAN_NOT_MEMBER", http.StatusForbidden, true
+	}
+	return "", 0, false
+}
+
+// clanForumPage writes the page of the clan forum left by the action.
+// Input holds what was posted, so rejected posts can be fixed and sent again.
+func (s *server) clanForumPage(w http.ResponseWriter, r *http.Request, action string, res *clans.Result_t, input clanForumInput_t) {
+	xlat := func(key string) string {
+		return html.EscapeString(s.language.Printf(key))
+	}
+	f := res.Forum
+	s.buildPageStart(w, "CLANFORUM_TITLE", s.pageNotices(r, s.clanMessages(res.Messages)))
+
+	if f.Topic == nil {
+		_, _ = w.Write([]byte(`<table><thead><tr><th colspan="2">` + xlat("CLANFORUM_COLUMN_TOPICS") + `</th>`))
+		for _, column := range []string{"CLANFORUM_COLUMN_AUTHOR", "CLANFORUM_COLUMN_REPLIES", "CLANFORUM_COLUMN_LASTPOST"} {
+			_, _ = w.Write([]byte(`<th>` + xlat(column) + `</th>`))
+		}
+		_, _ = w.Write([]byte(`</tr></thead><tbody>`))
+		for _, topic := range f.Topics {
+			var icons string
+			if topic.News {
+				icons += xlat("CLANFORUM_ICON_NEWS")
+			}
+			if topic.Sticky {
+				icons += xlat("CLANFORUM_ICON_STICKY")
+			}
+			if topic.Locked {
+				icons += xlat("CLANFORUM_ICON_LOCKED")
+			}
+			_, _ = w.Write([]byte(fmt.Sprintf(`<tr><td>%s</td><td><a href="/clan/forum?topic_id=%d">%s</a></td><td>%s</td><td>%s</td><td>%s<br/>%s</td></tr>`,
+				icons, topic.Id, s.clanForumSubject(topic),
+				html.EscapeString(s.language.Printf("COMMON_EMPIRE_NAMEID", topic.AuthorName, s.language.Prenum(topic.Author))),
+				s.language.Number(topic.Replies),
+				html.EscapeString(s.language.Printf("COMMON_EMPIRE_NAMEID", topic.LastPosterName, s.language.Prenum(topic.LastPoster))),
+				topic.LastPost.Format("2006/01/02 15:04"))))
+		}
+		_, _ = w.Write([]byte(`</tbody></table>`))
+		s.clanForumPages(w, "/clan/forum?", f)
+
+		body, subject := "", ""
+		if action == "post" {
+			body, subject = html.EscapeString(input.Body), html.EscapeString(input.Subject)
+		}
+		_, _ = w.Write([]byte(`<form method="post" action="/clan/forum"><input type="hidden" name="action" value="post"/>`))
+		_, _ = w.Write([]byte(`<p><label>` + xlat("LABEL_SUBJECT") + ` <input type="text" name="topic_subject" size="40" value="` + subject + `"/></label></p>`))
+		_, _ = w.Write([]byte(`<p><textarea name="post_body" rows="15" cols="76">` + body + `</textarea></p>`))
+		if f.Moderator {
+			s.clanForumFlags(w, input.Sticky, input.Locked)
+		}
+		_, _ = w.Write([]byte(`<p><input type="submit" value="` + xlat("CLANFORUM_POST_SUBMIT") + `"/></p></form>`))
+		_, _ = w.Write([]byte(`<p><a href="/clan">` + xlat("CLAN_TITLE") + `</a> | <a href="/clan/forum.json">JSON</a></p>`))
+		s.buildPageEnd(w)
+		return
+	}
+
+	topicLink := fmt.Sprintf("/clan/forum?topic_id=%d", f.Topic.Id)
+	_, _ = w.Write([]byte(`<h2><a href="/clan/forum">` + xlat("CLANFORUM_LINK_INDEX") + `</a>` + xlat("CLANFORUM_SUBJECT_SEP") + s.clanForumSubject(f.Topic) + `</h2>`))
+	_, _ = w.Write([]byte(`<table><thead><tr><th>` + xlat("CLANFORUM_COLUMN_AUTHOR") + `</th><th>` + xlat("CLANFORUM_COLUMN_MESSAGE") + `</th></tr></thead><tbody>`))
+
+	if post := f.Post; post != nil {
+		// the post being edited or deleted, followed by the form
+		_, _ = w.Write([]byte(`<tr><td>` + html.EscapeString(s.language.Printf("COMMON_EMPIRE_NAMEID", post.AuthorName, s.language.Prenum(post.Author))) + `<br/>` + post.Time.Format("2006/01/02 15:04") + `</td>`))
+		_, _ = w.Write([]byte(`<td>` + strings.ReplaceAll(post.Body, "\n", "<br/>") + `</td></tr>`))
+		hidden := fmt.Sprintf(`<input type="hidden" name="topic_id" value="%d"/><input type="hidden" name="post_id" value="%d"/>`, f.Topic.Id, post.Id)
+		if action == "delete_form" {
+			prompt := "CLANFORUM_DELETE_PROMPT_MESSAGE"
+			if f.KillTopic {
+				prompt = "CLANFORUM_DELETE_PROMPT_TOPIC"
+			}
+			_, _ = w.Write([]byte(`<tr><td></td><td><form method="post" action="/clan/forum"><input type="hidden" name="action" value="delete"/>` + hidden))
+			_, _ = w.Write([]byte(`<p>` + xlat(prompt) + `</p>`))
+			_, _ = w.Write([]byte(`<p><input type="submit" value="` + xlat("COMMON_YES") + `"/> <a href="` + topicLink + `">` + xlat("COMMON_NO") + `</a></p></form></td></tr>`))
+		} else {
+			body, subject := bbcode.Decode(post.Body), f.Topic.Subject
+			sticky, locked := f.Topic.Sticky, f.Topic.Locked
+			if action == "edit" {
+				body, subject = html.EscapeString(input.Body), html.EscapeString(input.Subject)
+				sticky, locked = input.Sticky, input.Locked
+			}
+			_, _ = w.Write([]byte(`<tr><td>` + xlat("CLANFORUM_LABEL_EDIT") + `</td><td><form method="post" action="/clan/forum"><input type="hidden" name="action" value="edit"/>` + hidden))
+			if f.First && !f.Topic.News {
+				_, _ = w.Write([]byte(`<p><label>` + xlat("LABEL_SUBJECT") + ` <input type="text" name="topic_subject" size="40" value="` + subject + `"/></label></p>`))
+			}
+			_, _ = w.Write([]byte(`<p><textarea name="post_body" rows="15" cols="76">` + body + `</textarea></p>`))
+			if f.First && !f.Topic.News && f.Moderator {
+				s.clanForumFlags(w, sticky, locked)
+			}
+			_, _ = w.Write([]byte(`<p><input type="submit" value="` + xlat("CLANFORUM_EDIT_SUBMIT") + `"/> <a href="` + topicLink + `">` + xlat("CLANFORUM_EDIT_CANCEL") + `</a></p></form></td></tr>`))
+		}
+		_, _ = w.Write([]byte(`</tbody></table>`))
+		s.buildPageEnd(w)
+		return
+	}
+
+	for _, post := range f.Posts {
+		_, _ = w.Write([]byte(`<tr><td>` + html.EscapeString(s.language.Printf("COMMON_EMPIRE_NAMEID", post.AuthorName, s.language.Prenum(post.Author))) + `<br/>` + xlat(post.Label) + `<br/><br/>` + post.Time.Format("2006/01/02 15:04") + `</td>`))
+		_, _ = w.Write([]byte(`<td>` + strings.ReplaceAll(post.Body, "\n", "<br/>") + `<br/><br/>`))
+		var links []string
+		if post.CanEdit {
+			links = append(links, fmt.Sprintf(`<a href="/clan/forum?action=edit_form&amp;topic_id=%d&amp;post_id=%d">%s</a>`, f.Topic.Id, post.Id, xlat("CLANFORUM_SUBMIT_EDIT")))
+		}
+		if post.CanDelete {
+			links = append(links, fmt.Sprintf(`<a href="/clan/forum?action=delete_form&amp;topic_id=%d&amp;post_id=%d">%s</a>`, f.Topic.Id, post.Id, xlat("CLANFORUM_SUBMIT_DELETE")))
+		}
+		if f.CanPost {
+			links = append(links, fmt.Sprintf(`<a href="/clan/forum?topic_id=%d&amp;post_id=%d">%s</a>`, f.Topic.Id, post.Id, xlat("CLANFORUM_SUBMIT_QUOTE")))
+		}
+		_, _ = w.Write([]byte(strings.Join(links, " ") + `</td></tr>`))
+		_, _ = w.Write([]byte(`<tr><td colspan="2"><hr/></td></tr>`))
+	}
+	if f.CanPost {
+		body := f.Quote
+		if action == "reply" {
+			body = html.EscapeString(input.Body)
+		}
+		_, _ = w.Write([]byte(`<tr><td>` + xlat("CLANFORUM_LABEL_REPLY") + `</td><td><form method="post" action="/clan/forum"><input type="hidden" name="action" value="reply"/>`))
+		_, _ = w.Write([]byte(`<input type="hidden" name="topic_id" value="` + strconv.Itoa(f.Topic.Id) + `"/>`))
+		_, _ = w.Write([]byte(`<p><textarea name="post_body" rows="15" cols="76">` + body + `</textarea></p>`))
+		_, _ = w.Write([]byte(`<p><input type="submit" value="` + xlat("CLANFORUM_REPLY_SUBMIT") + `"/></p></form></td></tr>`))
+	}
+	_, _ = w.Write([]byte(`</tbody></table>`))
+	s.clanForumPages(w, topicLink+"&amp;", f)
+	_, _ = w.Write([]byte(`<p><a href="/clan/forum.json?topic_id=` + strconv.Itoa(f.Topic.Id) + `">JSON</a></p>`))
+	s.buildPageEnd(w)
+}
+
+// clanForumSubject returns the topic's subject for display.
+// Subjects were escaped when they were posted.
+func (s *server) clanForumSubject(topic *clans.Topic_t) string {
+	if topic.News {
+		return html.EscapeString(s.language.Printf("CLANFORUM_SUBJECT_NEWS"))
+	} else if topic.Subject == "" {
+		return html.EscapeString(s.language.Printf("MESSAGES_LABEL_NO_SUBJECT"))
+	}
+	return topic.Subject
+}
+
+// clanForumFlags writes the checkboxes moderators use to make a topic sticky or lock it.
+func (s *server) clanForumFlags(w http.ResponseWriter, sticky, locked bool) {
+	for _, flag := range []struct {
+		name, label string
+		checked     bool
+	}{
+		{"topic_sticky", "CLANFORUM_FLAG_STICKY", sticky},
+		{"topic_locked", "CLANFORUM_FLAG_LOCKED", locked},
+	} {
+		checked := ""
+		if flag.checked {
+			checked = ` checked="checked"`
+		}
+		_, _ = w.Write([]byte(`<p><label><input type="checkbox" name="` + flag.name + `" value="1"` + checked + `/> ` + html.EscapeString(s.language.Printf(flag.label)) + `</label></p>`))
+	}
+}
+
+// clanForumPages writes the links to the other pages of the index or topic, from pagelist in php/includes/html.php.
+// Only the first and last pages, and the three on either side of the current one, are linked.
+func (s *server) clanForumPages(w http.ResponseWriter, link string, f *clans.Forum_t) {
+	if f.Pages < 2 {
+		return
+	}
+	page := func(label string, n int) string {
+		return fmt.Sprintf(`<a href="%spage=%d">%s</a>`, link, n, label)
+	}
+	// the labels for the previous and next pages are markup
+	out := html.EscapeString(s.language.Printf("COMMON_PAGES_LABEL")) + " "
+	if f.Page > 1 {
+		out += page(s.language.Printf("COMMON_PAGES_PREV"), f.Page-1) + " "
+	}
+	for i := 1; i <= f.Pages; i++ {
+		if (i > 1 && i < f.Page-3) || (i > f.Page+3 && i < f.Pages) {
+			continue
+		}
+		if i == f.Page {
+			out += `<b>` + strconv.Itoa(i) + `</b>`
+		} else {
+			out += page(strconv.Itoa(i), i)
+		}
+		if (i == 1 && f.Page > 5) || (i == f.Page+3 && f.Page+4 < f.Pages) {
+			out += html.EscapeString(s.language.Printf("COMMON_PAGES_GAP"))
+		} else if i != f.Pages {
+			out += html.EscapeString(s.language.Printf("COMMON_PAGES_SEP"))
+		}
+	}
+	if f.Page < f.Pages {
+		out += " " + page(s.language.Printf("COMMON_PAGES_NEXT"), f.Page+1)
+	}
+	_, _ = w.Write([]byte(`<p>` + out + `</p>`))
+}
+
+// clanForumResult converts the result of a forum action, translating the messages and ranks.
+func (s *server) clanForumResult(res *clans.Result_t) clanForumResult_t {
+	f := res.Forum
+	out := clanForumResult_t{
+		Success:  res.Success,
+		Messages: s.clanMessages(res.Messages),
+		Forum: clanForum_t{
+			Moderator: f.Moderator,
+			Ranked:    f.Ranked,
+			CanPost:   f.CanPost,
+			Quote:     f.Quote,
+			First:     f.First,
+			KillTopic: f.KillTopic,
+			Page:      f.Page,
+			Pages:     f.Pages,
+		},
+	}
+	topic := func(t *clans.Topic_t) clanForumTopic_t {
+		return clanForumTopic_t{
+			Id:             t.Id,
+			Subject:        t.Subject,
+			News:           t.News,
+			Sticky:         t.Sticky,
+			Locked:         t.Locked,
+			Author:         t.Author,
+			AuthorName:     t.AuthorName,
+			Replies:        t.Replies,
+			LastPoster:     t.LastPoster,
+			LastPosterName: t.LastPosterName,
+			LastPost:       t.LastPost.Format(time.RFC3339),
+		}
+	}
+	post := func(p *clans.Post_t) clanForumPost_t {
+		out := clanForumPost_t{
+			Id:         p.Id,
+			Author:     p.Author,
+			AuthorName: p.AuthorName,
+			Body:       p.Body,
+			Time:       p.Time.Format(time.RFC3339),
+			Edited:     p.Edited,
+			CanEdit:    p.CanEdit,
+			CanDelete:  p.CanDelete,
+		}
+		if p.Label != "" {
+			out.Rank = s.language.Printf(p.Label)
+		}
+		return out
+	}
+	for _, t := range f.Topics {
+		out.Forum.Topics = append(out.Forum.Topics, topic(t))
+	}
+	if f.Topic != nil {
+		t := topic(f.Topic)
+		out.Forum.Topic = &t
+	}
+	for _, p := range f.Posts {
+		out.Forum.Posts = append(out.Forum.Posts, post(p))
+	}
+	if f.Post != nil {
+		p := post(f.Post)
+		out.Forum.Post = &p
+	}
+	return out
+}
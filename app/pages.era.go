@@ -0,0 +1,294 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/eras"
+	"github.com/mdhender/promisance/app/model"
+	"html"
+	"log"
+	"net/http"
+	"time"
+)
+
+type eraChange_t struct {
+	Era     int    `json:"era,omitempty"`
+	Name    string `json:"name,omitempty"` // the era's name in the current language
+	Spell   string `json:"spell"`          // the spell's name in the empire's current era
+	Enabled bool   `json:"enabled"`
+	Allowed bool   `json:"allowed"`
+	Cost    int    `json:"cost"`
+	Turns   int    `json:"turns"`
+}
+
+type eraState_t struct {
+	Era       int         `json:"era"`
+	Name      string      `json:"name"`
+	Runes     int         `json:"runes"`
+	Wizards   int         `json:"wizards"`
+	Turns     int         `json:"turns"`
+	Health    int         `json:"health"`
+	TurnsLeft int         `json:"turnsLeft"`
+	Gate      bool        `json:"gate"`
+	Advance   eraChange_t `json:"advance"`
+	Regress   eraChange_t `json:"regress"`
+}
+
+type eraResult_t struct {
+	Success  bool       `json:"success"`
+	Messages []string   `json:"messages"`
+	State    eraState_t `json:"state"`
+}
+
+// eraGetHandler shows the era change page.
+func (s *server) eraGetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	s.eraPage(w, r, emp, nil)
+}
+
+// eraPostHandler advances or regresses the empire from the posted form.
+func (s *server) eraPostHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	var notices []string
+	if round := s.roundData(time.Now()); round.Started && !round.Finished {
+		var res *eras.Result_t
+		var err error
+		switch action, _ := s.getFormVar(r, "action", ""); action {
+		case "advance":
+			res, err = s.eras.Advance(emp.Id, round, time.Now())
+		case "regress":
+			res, err = s.eras.Regress(emp.Id, round, time.Now())
+		default:
+			res = &eras.Result_t{}
+		}
+		if err != nil {
+			log.Printf("%s %s: era: %v\n", r.Method, r.URL.Path, err)
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		notices = s.eraMessages(res.Messages)
+	}
+	s.eraPage(w, r, emp, notices)
+}
+
+// eraJsonGetHandler returns the empire's era and the cost of changing it as JSON.
+func (s *server) eraJsonGetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	state, err := s.eras.State(emp.Id, time.Now())
+	if err != nil {
+		log.Printf("%s %s: state: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(s.eraStateFromService(state))
+}
+
+// eraJsonPostHandler advances or regresses the empire.
+// The request body holds the action, either "advance" or "regress".
+func (s *server) eraJsonPostHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	var input struct {
+		Action string `json:"action"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, fmt.Sprintf("request: %v", err), http.StatusBadRequest)
+		return
+	} else if input.Action != "advance" && input.Action != "regress" {
+		http.Error(w, fmt.Sprintf("action: unknown value %q", input.Action), http.StatusBadRequest)
+		return
+	}
+	round := s.roundData(time.Now())
+	if round.Finished {
+		http.Error(w, s.language.Printf("ERACHANGE_UNAVAILABLE_END"), http.StatusConflict)
+		return
+	} else if !round.Started {
+		http.Error(w, s.language.Printf("ERACHANGE_UNAVAILABLE_START"), http.StatusConflict)
+		return
+	}
+	var res *eras.Result_t
+	var err error
+	if input.Action == "advance" {
+		res, err = s.eras.Advance(emp.Id, round, time.Now())
+	} else {
+		res, err = s.eras.Regress(emp.Id, round, time.Now())
+	}
+	if err != nil {
+		log.Printf("%s %s: era: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(eraResult_t{
+		Success:  res.Spell != nil && res.Spell.Success,
+		Messages: s.eraMessages(res.Messages),
+		State:    s.eraStateFromService(res.State),
+	})
+}
+
+// eraPage writes the era change page.
+// Every name on the page comes from the empire's current era.
+func (s *server) eraPage(w http.ResponseWriter, r *http.Request, emp *model.Empire_t, notices []string) {
+	notices = s.pageNoticesHTML(r, notices)
+	round := s.roundData(time.Now())
+	state, err := s.eras.State(emp.Id, time.Now())
+	if err != nil {
+		log.Printf("%s %s: state: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	era, err := s.tables.Era(state.Era)
+	if err != nil {
+		log.Printf("%s %s: era: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	xlat := func(key string) string {
+		return html.EscapeString(s.language.Printf(key))
+	}
+
+	s.buildPageStart(w, "ERACHANGE_TITLE", nil)
+	// spell messages contain markup, so they are not escaped
+	for _, notice := range notices {
+		_, _ = w.Write([]byte(`<p class="box">` + notice + `</p>`))
+	}
+	if round.Finished {
+		_, _ = w.Write([]byte(`<p class="box">` + xlat("ERACHANGE_UNAVAILABLE_END") + `</p>`))
+		s.buildPageEnd(w)
+		return
+	} else if !round.Started {
+		_, _ = w.Write([]byte(`<p class="box">` + xlat("ERACHANGE_UNAVAILABLE_START") + `</p>`))
+		s.buildPageEnd(w)
+		return
+	}
+	_, _ = w.Write([]byte(`<p>` + html.EscapeString(s.language.Printf("ERACHANGE_HEADER",
+		s.language.EraTerm(era, "name"), s.language.EraTerm(era, "trpwiz"), s.eraTurns(TURNS_ERA))) + `</p>`))
+
+	_, _ = w.Write([]byte(`<table><tbody>`))
+	for _, row := range []struct {
+		label string
+		value string
+	}{
+		{s.language.EraTerm(era, "trpwiz"), s.language.Number(state.Wizards)},
+		{s.language.EraTerm(era, "runes"), s.language.Number(state.Runes)},
+		{s.language.Printf("ROW_TURNS"), s.language.Number(state.Turns)},
+		{s.language.Printf("ROW_HEALTH"), s.language.Percent(float64(state.Health), 0)},
+	} {
+		_, _ = w.Write([]byte(`<tr><th>` + html.EscapeString(row.label) + `</th><td>` + html.EscapeString(row.value) + `</td></tr>`))
+	}
+	_, _ = w.Write([]byte(`</tbody></table>`))
+
+	for _, change := range []eras.Change_t{state.Advance, state.Regress} {
+		if !change.Enabled || change.Era == 0 {
+			continue
+		}
+		disabled := ""
+		if !change.Allowed {
+			disabled = ` disabled`
+		}
+		_, _ = w.Write([]byte(fmt.Sprintf(`<form method="post" action="/era"><input type="hidden" name="action" value="%s"/><p><input type="submit" value="%s"%s/> %s`,
+			change.Spell, html.EscapeString(s.language.EraSpell(era, change.Spell)), disabled,
+			html.EscapeString(s.language.Printf("ERACHANGE_COST", s.language.Number(change.Cost), s.language.EraTerm(era, "runes"), s.eraTurns(change.Turns))))))
+		if state.TurnsLeft > 0 {
+			_, _ = w.Write([]byte(` ` + html.EscapeString(s.language.Printf("ERACHANGE_WAIT", s.eraTurns(state.TurnsLeft)))))
+		}
+		_, _ = w.Write([]byte(`</p></form>`))
+	}
+
+	if state.Gate {
+		_, _ = w.Write([]byte(`<p>` + html.EscapeString(s.language.Printf("ERACHANGE_GATE_OPEN", s.language.EraEffect(era, "gate"))) + `</p>`))
+	} else {
+		_, _ = w.Write([]byte(`<p>` + html.EscapeString(s.language.Printf("ERACHANGE_GATE_CLOSED", s.language.EraEffect(era, "gate"))) + `</p>`))
+	}
+	_, _ = w.Write([]byte(`<p><a href="/era.json">JSON</a></p>`))
+	s.buildPageEnd(w)
+}
+
+// eraMessages translates the messages from changing eras.
+// String arguments are the names of things in an era and are translated;
+// numbers are formatted, with turns pluralized.
+func (s *server) eraMessages(messages []engine.Message_t) []string {
+	var list []string
+	for _, msg := range messages {
+		var args []any
+		for i, arg := range msg.Args {
+			switch v := arg.(type) {
+			case string:
+				args = append(args, s.language.Printf(v))
+			case int:
+				if (msg.Key == "ERACHANGE_TOO_SOON" && i == 0) || (msg.Key == "ERACHANGE_COMPLETE" && i == 1) {
+					args = append(args, s.eraTurns(v))
+				} else {
+					args = append(args, s.language.Number(v))
+				}
+			default:
+				args = append(args, v)
+			}
+		}
+		list = append(list, s.language.Printf(msg.Key, args...))
+	}
+	return list
+}
+
+func (s *server) eraTurns(n int) string {
+	return s.language.Plural(n, "TURNS_SINGLE", "TURNS_PLURAL", "")
+}
+
+// eraStateFromService converts the state, naming the eras and spells in the current language.
+func (s *server) eraStateFromService(state *eras.State_t) eraState_t {
+	out := eraState_t{
+		Era:       state.Era,
+		Runes:     state.Runes,
+		Wizards:   state.Wizards,
+		Turns:     state.Turns,
+		Health:    state.Health,
+		TurnsLeft: state.TurnsLeft,
+		Gate:      state.Gate,
+	}
+	era, err := s.tables.Era(state.Era)
+	if err != nil {
+		return out
+	}
+	out.Name = s.language.EraTerm(era, "name")
+	for _, c := range []struct {
+		from *eras.Change_t
+		to   *eraChange_t
+	}{
+		{&state.Advance, &out.Advance},
+		{&state.Regress, &out.Regress},
+	} {
+		*c.to = eraChange_t{
+			Era:     c.from.Era,
+			Spell:   s.language.EraSpell(era, c.from.Spell),
+			Enabled: c.from.Enabled,
+			Allowed: c.from.Allowed,
+			Cost:    c.from.Cost,
+			Turns:   c.from.Turns,
+		}
+		if next, err := s.tables.Era(c.from.Era); err == nil {
+			c.to.Name = s.language.EraTerm(next, "name")
+		}
+	}
+	return out
+}
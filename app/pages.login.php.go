@@ -103,10 +103,11 @@ func (s *server) loginGetHandlerOld(w http.ResponseWriter, r *http.Request) {
 	s.jots.Destroy(w)
 
 	// our response variables
+	world := s.worldVars()
 	content := LoginContent{
 		GAME_TITLE:       GAME_TITLE,
 		LOGIN_VERSION:    s.language.PrintfHTML("LOGIN_VERSION", GAME_VERSION),
-		LOGIN_DATE_RANGE: s.language.PrintfHTML("LOGIN_DATE_RANGE", s.world.RoundTimeBegin, s.world.RoundTimeEnd),
+		LOGIN_DATE_RANGE: s.language.PrintfHTML("LOGIN_DATE_RANGE", world.RoundTimeBegin, world.RoundTimeEnd),
 		NOTICES:          s.noticesFromQueryParameter(r, 1),
 		LABEL_USERNAME:   s.language.PrintfHTML("LABEL_USERNAME"),
 		LABEL_PASSWORD:   s.language.PrintfHTML("LABEL_PASSWORD"),
@@ -0,0 +1,332 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/lottery"
+	"github.com/mdhender/promisance/app/model"
+	"html"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type lotteryState_t struct {
+	Cash        int       `json:"cash"`
+	TicketCost  int       `json:"ticketCost"`
+	MaxTickets  int       `json:"maxTickets"`
+	Tickets     []int     `json:"tickets"`
+	TicketsSold int       `json:"ticketsSold"`
+	Jackpot     int       `json:"jackpot"`
+	NextDraw    time.Time `json:"nextDraw"`
+	Closed      bool      `json:"closed"`
+	LastPicked  int       `json:"lastPicked,omitempty"`
+	LastWinner  int       `json:"lastWinner,omitempty"`
+	LastName    string    `json:"lastName,omitempty"`
+	LastJackpot int       `json:"lastJackpot"`
+	Increase    int       `json:"increase"`
+}
+
+type lotteryDraw_t struct {
+	Time       time.Time `json:"time"`
+	Ticket     int       `json:"ticket"`
+	Tickets    int       `json:"tickets"`
+	Jackpot    int       `json:"jackpot"`
+	Increase   int       `json:"increase"`
+	EmpireId   int       `json:"empireId,omitempty"`
+	EmpireName string    `json:"empireName,omitempty"`
+	Won        int       `json:"won"`
+}
+
+// lotteryGetHandler shows the lottery, from php/pages/lottery.php.
+func (s *server) lotteryGetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	s.lotteryPage(w, r, emp.Id, nil)
+}
+
+// lotteryPostHandler buys a lottery ticket.
+func (s *server) lotteryPostHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	var notices []string
+	if round := s.roundData(time.Now()); round.Started && !round.Finished {
+		res, err := s.lottery.Buy(emp.Id, round)
+		if err != nil {
+			log.Printf("%s %s: buy: %v\n", r.Method, r.URL.Path, err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		notices = s.lotteryMessages(res.Messages)
+	}
+	s.lotteryPage(w, r, emp.Id, notices)
+}
+
+// lotteryJsonGetHandler returns the state of the lottery as JSON.
+func (s *server) lotteryJsonGetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	state, err := s.lottery.State(emp.Id)
+	if err != nil {
+		log.Printf("%s %s: state: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(lotteryStateFromService(state))
+}
+
+// lotteryJsonPostHandler buys a lottery ticket. The request body is ignored.
+func (s *server) lotteryJsonPostHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	round := s.roundData(time.Now())
+	if round.Finished {
+		http.Error(w, s.language.Printf("LOTTERY_UNAVAILABLE_END"), http.StatusConflict)
+		return
+	} else if !round.Started {
+		http.Error(w, s.language.Printf("LOTTERY_UNAVAILABLE_START"), http.StatusConflict)
+		return
+	}
+	res, err := s.lottery.Buy(emp.Id, round)
+	if err != nil {
+		log.Printf("%s %s: buy: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(struct {
+		Ticket   int            `json:"ticket,omitempty"`
+		Cost     int            `json:"cost,omitempty"`
+		Messages []string       `json:"messages"`
+		State    lotteryState_t `json:"state"`
+	}{
+		Ticket:   res.Ticket,
+		Cost:     res.Cost,
+		Messages: s.lotteryMessages(res.Messages),
+		State:    lotteryStateFromService(res.State),
+	})
+}
+
+// lotteryHistoryGetHandler shows the results of past drawings.
+func (s *server) lotteryHistoryGetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	if s.requireEmpire(w, r) == nil {
+		return
+	}
+	draws, ok := s.lotteryHistoryFetch(w, r)
+	if !ok {
+		return
+	}
+	xlat := func(key string) string {
+		return html.EscapeString(s.language.Printf(key))
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(`<!DOCTYPE html><html lang="en"><head><meta charset="UTF-8"><title>` + xlat("LOTTERY_TITLE") + `</title><link rel="stylesheet" href="https://unpkg.com/missing.css@1.1.1"></head><body>`))
+	_, _ = w.Write([]byte(`<h1>` + xlat("LOTTERY_TITLE") + `</h1>`))
+	_, _ = w.Write([]byte(`<main>`))
+	_, _ = w.Write([]byte(`<table>`))
+	_, _ = w.Write([]byte(`<thead><tr><th>Drawing</th><th>Ticket</th><th>Tickets Sold</th><th>Jackpot</th><th>Increase</th><th>Winner</th></tr></thead>`))
+	_, _ = w.Write([]byte(`<tbody>`))
+	if len(draws) == 0 {
+		_, _ = w.Write([]byte(`<tr><td colspan="6">No drawings have been held.</td></tr>`))
+	}
+	for _, draw := range draws {
+		winner := "-"
+		if draw.EmpireId != 0 {
+			winner = html.EscapeString(s.language.Printf("COMMON_EMPIRE_NAMEID", draw.EmpireName, s.language.Prenum(draw.EmpireId)))
+		}
+		_, _ = w.Write([]byte(fmt.Sprintf(`<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>`,
+			draw.Time.Format("2006/01/02 15:04"), s.language.Prenum(draw.Ticket), s.language.Number(draw.Tickets),
+			s.language.Money(draw.Jackpot), s.language.Money(draw.Increase), winner)))
+	}
+	_, _ = w.Write([]byte(`</tbody></table>`))
+	_, _ = w.Write([]byte(`<p><a href="/lottery">` + xlat("LOTTERY_TITLE") + `</a> | <a href="/lottery/history.json">JSON</a></p>`))
+	_, _ = w.Write([]byte(`</main>`))
+	_, _ = w.Write([]byte(`</body>`))
+}
+
+// lotteryHistoryJsonGetHandler returns the results of past drawings as JSON.
+func (s *server) lotteryHistoryJsonGetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	if s.requireEmpire(w, r) == nil {
+		return
+	}
+	draws, ok := s.lotteryHistoryFetch(w, r)
+	if !ok {
+		return
+	}
+	list := []lotteryDraw_t{}
+	for _, draw := range draws {
+		list = append(list, lotteryDraw_t{
+			Time:       draw.Time,
+			Ticket:     draw.Ticket,
+			Tickets:    draw.Tickets,
+			Jackpot:    draw.Jackpot,
+			Increase:   draw.Increase,
+			EmpireId:   draw.EmpireId,
+			EmpireName: draw.EmpireName,
+			Won:        draw.Won,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(list)
+}
+
+// lotteryHistoryFetch returns the drawings requested by the "limit" query parameter.
+// It writes an error response and returns false if the request is invalid.
+func (s *server) lotteryHistoryFetch(w http.ResponseWriter, r *http.Request) ([]*model.LotteryDraw_t, bool) {
+	limit := 30
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			http.Error(w, fmt.Sprintf("limit: invalid value %q", value), http.StatusBadRequest)
+			return nil, false
+		}
+		limit = n
+	}
+	draws, err := s.lottery.History(limit)
+	if err != nil {
+		log.Printf("%s %s: history: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return nil, false
+	}
+	return draws, true
+}
+
+// lotteryPage writes the lottery page.
+func (s *server) lotteryPage(w http.ResponseWriter, r *http.Request, empireId int, notices []string) {
+	notices = s.pageNotices(r, notices)
+	round := s.roundData(time.Now())
+	if round.Finished {
+		notices = append(notices, s.language.Printf("LOTTERY_UNAVAILABLE_END"))
+	} else if !round.Started {
+		notices = append(notices, s.language.Printf("LOTTERY_UNAVAILABLE_START"))
+	}
+	state, err := s.lottery.State(empireId)
+	if err != nil {
+		log.Printf("%s %s: state: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	xlat := func(key string) string {
+		return html.EscapeString(s.language.Printf(key))
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(`<!DOCTYPE html><html lang="en"><head><meta charset="UTF-8"><title>` + xlat("LOTTERY_TITLE") + `</title><link rel="stylesheet" href="https://unpkg.com/missing.css@1.1.1"></head><body>`))
+	_, _ = w.Write([]byte(`<h1>` + xlat("LOTTERY_TITLE") + `</h1>`))
+	_, _ = w.Write([]byte(`<main>`))
+	for _, notice := range notices {
+		_, _ = w.Write([]byte(`<p class="box">` + html.EscapeString(notice) + `</p>`))
+	}
+	if !round.Started || round.Finished {
+		_, _ = w.Write([]byte(`</main>`))
+		_, _ = w.Write([]byte(`</body>`))
+		return
+	}
+
+	// the description contains markup, so it is not escaped
+	_, _ = w.Write([]byte(`<p>` + s.language.Printf("LOTTERY_DESC", state.NextDraw.Format("3:04pm")) + `</p>`))
+	if !state.Closed {
+		_, _ = w.Write([]byte(`<p>` + html.EscapeString(s.language.Printf("LOTTERY_NEXTTICKET", s.language.Money(state.TicketCost))) + `<br />`))
+		_, _ = w.Write([]byte(html.EscapeString(s.language.Printf("LOTTERY_MAXALLOWED", s.language.Plural(state.MaxTickets, "TICKETS_SINGLE", "TICKETS_PLURAL", ""))) + `</p>`))
+		_, _ = w.Write([]byte(`<p><b>` + xlat("LOTTERY_CURJACKPOT") + `</b> ` + s.language.Money(state.Jackpot) + `</p>`))
+		_, _ = w.Write([]byte(`<p>` + xlat("LOTTERY_TOTALBOUGHT") + ` <span class="cneutral">` + s.language.Number(state.TicketsSold) + `</span></p>`))
+	}
+
+	var last string
+	if state.LastWinner != 0 {
+		winner := s.language.Printf("COMMON_EMPIRE_NAMEID", state.LastName, s.language.Prenum(state.LastWinner))
+		last = s.language.Printf("LOTTERY_LAST_WINNER", s.language.Prenum(state.LastPicked), winner, s.language.Money(state.LastJackpot))
+	} else if state.Closed {
+		last = s.language.Printf("LOTTERY_LAST_NOWINNER_END", s.language.Prenum(state.LastPicked), s.language.Money(state.Jackpot))
+	} else {
+		last = s.language.Printf("LOTTERY_LAST_NOWINNER", s.language.Prenum(state.LastPicked), s.language.Money(state.Increase))
+	}
+	_, _ = w.Write([]byte(`<p>` + html.EscapeString(last) + `<br />`))
+	if state.Closed {
+		// skip entirely
+	} else if len(state.Tickets) != 0 {
+		var tickets []string
+		for _, ticket := range state.Tickets {
+			tickets = append(tickets, s.language.Prenum(ticket))
+		}
+		_, _ = w.Write([]byte(html.EscapeString(s.language.Printf("LOTTERY_HAVE_TICKETS", strings.Join(tickets, s.language.Printf("LOTTERY_TICKET_LISTSEP"))))))
+	} else {
+		_, _ = w.Write([]byte(xlat("LOTTERY_HAVE_NO_TICKETS")))
+	}
+	_, _ = w.Write([]byte(`</p>`))
+
+	if state.Closed {
+		_, _ = w.Write([]byte(`<p>` + xlat("LOTTERY_END_ROUND") + `</p>`))
+	} else if len(state.Tickets) < state.MaxTickets {
+		if state.Cash < state.TicketCost {
+			_, _ = w.Write([]byte(`<p>` + xlat("LOTTERY_CANT_AFFORD") + `</p>`))
+		} else {
+			_, _ = w.Write([]byte(`<form method="post" action="/lottery"><input type="submit" value="` + xlat("LOTTERY_SUBMIT") + `"/></form>`))
+		}
+	}
+	_, _ = w.Write([]byte(`<p><a href="/lottery/history">History</a> | <a href="/lottery.json">JSON</a></p>`))
+	_, _ = w.Write([]byte(`</main>`))
+	_, _ = w.Write([]byte(`</body>`))
+}
+
+// lotteryMessages translates the messages from the lottery.
+// LOTTERY_COMPLETE holds the ticket number and its price.
+func (s *server) lotteryMessages(messages []engine.Message_t) []string {
+	var list []string
+	for _, msg := range messages {
+		var args []any
+		if msg.Key == "LOTTERY_COMPLETE" && len(msg.Args) == 2 {
+			args = []any{s.language.Prenum(msg.Args[0].(int)), s.language.Money(msg.Args[1].(int))}
+		}
+		list = append(list, s.language.Printf(msg.Key, args...))
+	}
+	return list
+}
+
+func lotteryStateFromService(state *lottery.State_t) lotteryState_t {
+	tickets := state.Tickets
+	if tickets == nil {
+		tickets = []int{}
+	}
+	return lotteryState_t{
+		Cash:        state.Cash,
+		TicketCost:  state.TicketCost,
+		MaxTickets:  state.MaxTickets,
+		Tickets:     tickets,
+		TicketsSold: state.TicketsSold,
+		Jackpot:     state.Jackpot,
+		NextDraw:    state.NextDraw,
+		Closed:      state.Closed,
+		LastPicked:  state.LastPicked,
+		LastWinner:  state.LastWinner,
+		LastName:    state.LastName,
+		LastJackpot: state.LastJackpot,
+		Increase:    state.Increase,
+	}
+}
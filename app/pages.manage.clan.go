@@ -0,0 +1,319 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mdhender/promisance/app/clans"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"html"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clanManageGetHandler shows the clan's relations with other clans and its invitations, from php/pages/manage/clan.php.
+func (s *server) clanManageGetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	s.clanManagePage(w, r, emp, nil)
+}
+
+// clanManagePostHandler changes one of the clan's relations or invitations from the posted form.
+func (s *server) clanManagePostHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	action, _ := s.getFormVar(r, "action", "")
+	confirm, _ := s.getFormVar(r, "confirm", "")
+	permanent, _ := s.getFormVar(r, "invite_perm", "")
+	input := clanManageInput_t{
+		Action:    action,
+		Clan:      s.getFormNum(r, "rel_clan"),
+		Relation:  s.getFormNum(r, "rel_id"),
+		Confirm:   confirm != "",
+		Empire:    s.getFormNum(r, "invite_emp"),
+		Permanent: permanent != "",
+		Invite:    s.getFormNum(r, "uninvite_id"),
+	}
+	var notices []string
+	res, err := s.clanManageAction(emp, input)
+	if err != nil {
+		if key, _, ok := clanUnavailable(err); ok {
+			notices = append(notices, html.EscapeString(s.language.Printf(key)))
+		} else {
+			log.Printf("%s %s: %s: %v\n", r.Method, r.URL.Path, action, err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	} else if res != nil {
+		notices = s.clanManageMessages(res.Messages)
+	}
+	s.clanManagePage(w, r, emp, notices)
+}
+
+// clanManageJsonGetHandler returns the empire's clan, its relations, and its invitations as JSON.
+func (s *server) clanManageJsonGetHandler(w http.ResponseWriter, r *http.Request) {
+	s.clanJsonGetHandler(w, r)
+}
+
+// clanManageJsonPostHandler changes one of the clan's relations or invitations.
+// The request body holds the action, one of "ally_request", "ally_cancel",
+// "ally_accept", "ally_deny", "ally_stop", "war_declare", "war_request",
+// "war_resume", "war_restart", or "war_stop", with the other clan and the
+// relation, or "invite", with the empire and whether the invitation is
+// permanent, or "uninvite", with the invitation.
+func (s *server) clanManageJsonPostHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	var input clanManageInput_t
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, fmt.Sprintf("request: %v", err), http.StatusBadRequest)
+		return
+	}
+	res, err := s.clanManageAction(emp, input)
+	if err != nil {
+		if key, status, ok := clanUnavailable(err); ok {
+			http.Error(w, s.language.Printf(key), status)
+			return
+		}
+		log.Printf("%s %s: %s: %v\n", r.Method, r.URL.Path, input.Action, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	} else if res == nil {
+		http.Error(w, fmt.Sprintf("action: unknown value %q", input.Action), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(clanResult_t{
+		Success:  res.Success,
+		Messages: s.clanManageMessages(res.Messages),
+		State:    clanStateFromService(res.State),
+	})
+}
+
+// clanManageInput_t holds the fields used by the clan management actions.
+type clanManageInput_t struct {
+	Action    string `json:"action"`
+	Clan      int    `json:"clan"`
+	Relation  int    `json:"relation"`
+	Confirm   bool   `json:"confirm"`
+	Empire    int    `json:"empire"`
+	Permanent bool   `json:"permanent"`
+	Invite    int    `json:"invite"`
+}
+
+// clanManageAction runs the clan management action. It returns a nil result if the action is not known.
+func (s *server) clanManageAction(emp *model.Empire_t, input clanManageInput_t) (*clans.Result_t, error) {
+	round := s.roundData(time.Now())
+	switch action := clans.Diplomacy_t(input.Action); action {
+	case clans.ALLY_REQUEST, clans.ALLY_CANCEL, clans.ALLY_ACCEPT, clans.ALLY_DENY, clans.ALLY_STOP,
+		clans.WAR_DECLARE, clans.WAR_REQUEST, clans.WAR_RESUME, clans.WAR_RESTART, clans.WAR_STOP:
+		return s.clans.Relate(emp.Id, round, action, input.Clan, input.Relation, input.Confirm, time.Now())
+	}
+	switch input.Action {
+	case "invite":
+		return s.clans.Invite(emp.Id, round, input.Empire, input.Permanent, time.Now())
+	case "uninvite":
+		return s.clans.Uninvite(emp.Id, round, input.Invite, time.Now())
+	}
+	return nil, nil
+}
+
+// clanManagePage writes the clan management page.
+// The notices may contain markup, so they must already be escaped.
+func (s *server) clanManagePage(w http.ResponseWriter, r *http.Request, emp *model.Empire_t, notices []string) {
+	notices = s.pageNoticesHTML(r, notices)
+	state, err := s.clans.State(emp.Id, s.roundData(time.Now()), time.Now())
+	if key, _, ok := clanUnavailable(err); ok {
+		notices = append(notices, html.EscapeString(s.language.Printf(key)))
+	} else if err != nil {
+		log.Printf("%s %s: state: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	xlat := func(key string) string {
+		return html.EscapeString(s.language.Printf(key))
+	}
+
+	s.buildPageStart(w, "MANAGE_CLAN_TITLE", nil)
+	for _, notice := range notices {
+		_, _ = w.Write([]byte(`<p class="box">` + notice + `</p>`))
+	}
+	if state == nil {
+		s.buildPageEnd(w)
+		return
+	}
+	clan := state.Clan
+	if clan == nil {
+		_, _ = w.Write([]byte(`<p class="box">` + xlat("CLAN_NOT_MEMBER") + `</p>`))
+		s.buildPageEnd(w)
+		return
+	} else if !slices.Contains([]int{clan.Leader, clan.Assistant, clan.Minister1, clan.Minister2}, emp.Id) {
+		_, _ = w.Write([]byte(`<p class="box">` + xlat("MANAGE_CLAN_NEED_PERMISSION") + `</p>`))
+		s.buildPageEnd(w)
+		return
+	}
+	_, _ = w.Write([]byte(`<h2>` + s.language.Printf("MANAGE_CLAN_HEADER", html.EscapeString(clan.Name)) + `</h2>`))
+
+	// all officers can change relations
+	_, _ = w.Write([]byte(`<h3>` + html.EscapeString(s.language.Printf("CLAN_RELATIONS_HEADER", clan.Title)) + `</h3>`))
+	_, _ = w.Write([]byte(`<table><thead><tr>`))
+	_, _ = w.Write([]byte(`<th>` + xlat("CLAN_ALLY_LABEL") + `<br/>` + s.language.Printf("CLAN_ALLY_DESC") + `</th>`))
+	_, _ = w.Write([]byte(`<th>` + xlat("CLAN_WAR_LABEL") + `<br/>` + s.language.Printf("CLAN_WAR_DESC") + `</th>`))
+	_, _ = w.Write([]byte(`</tr></thead><tbody>`))
+
+	form := func(action clans.Diplomacy_t, rel *clans.Relation_t, submit, confirm string) string {
+		var sb strings.Builder
+		sb.WriteString(`<form method="post" action="/clan/manage"><input type="hidden" name="action" value="` + string(action) + `"/>`)
+		sb.WriteString(`<input type="hidden" name="rel_id" value="` + strconv.Itoa(rel.Id) + `"/><input type="hidden" name="rel_clan" value="` + strconv.Itoa(rel.Clan) + `"/>`)
+		sb.WriteString(`<input type="submit" value="` + xlat(submit) + `"/>`)
+		if confirm != "" {
+			sb.WriteString(`<br/><label><input type="checkbox" name="confirm" value="1"/> ` + xlat(confirm) + `</label>`)
+		}
+		sb.WriteString(`</form>`)
+		return sb.String()
+	}
+	// clans with no relation yet may be asked for an alliance or have war declared on them
+	related := map[int]bool{}
+	for _, rel := range state.Relations {
+		related[rel.Clan] = true
+	}
+	choose := func(action clans.Diplomacy_t, label, submit string) string {
+		var sb strings.Builder
+		sb.WriteString(`<form method="post" action="/clan/manage"><input type="hidden" name="action" value="` + string(action) + `"/>`)
+		sb.WriteString(xlat(label) + ` <select name="rel_clan"><option value="0"></option>`)
+		for _, other := range state.Clans {
+			if !related[other.Id] {
+				sb.WriteString(`<option value="` + strconv.Itoa(other.Id) + `">` + html.EscapeString(other.Name) + `</option>`)
+			}
+		}
+		sb.WriteString(`</select><br/><input type="submit" value="` + xlat(submit) + `"/></form>`)
+		return sb.String()
+	}
+
+	var allies, wars []string
+	var allyCount, warCount int
+	for _, rel := range state.Relations {
+		cell := `<b>` + html.EscapeString(rel.Name) + `</b><br/>` + s.language.Printf("MANAGE_CLAN_RELATION_STATUS",
+			xlat("MANAGE_CLAN_RELATION_STATUS_"+strings.ToUpper(string(rel.Status))), rel.Since.Format("2006/01/02 15:04"))
+		switch rel.Status {
+		case clans.ALLY_MUTUAL:
+			cell += form(clans.ALLY_STOP, rel, "MANAGE_CLAN_ALLY_STOP_SUBMIT", "MANAGE_CLAN_ALLY_STOP_CONFIRM")
+			allyCount++
+		case clans.ALLY_OUTBOUND:
+			cell += form(clans.ALLY_CANCEL, rel, "MANAGE_CLAN_ALLY_CANCEL_SUBMIT", "")
+			allyCount++
+		case clans.ALLY_INBOUND:
+			cell += form(clans.ALLY_ACCEPT, rel, "MANAGE_CLAN_ALLY_ACCEPT_SUBMIT", "")
+			cell += form(clans.ALLY_DENY, rel, "MANAGE_CLAN_ALLY_DENY_SUBMIT", "")
+		case clans.WAR_MUTUAL_OUTBOUND:
+			cell += form(clans.WAR_REQUEST, rel, "MANAGE_CLAN_WAR_REQUEST_SUBMIT", "MANAGE_CLAN_WAR_REQUEST_CONFIRM")
+			warCount++
+		case clans.WAR_OUTBOUND:
+			cell += form(clans.WAR_RESUME, rel, "MANAGE_CLAN_WAR_RESUME_SUBMIT", "")
+			warCount++
+		case clans.WAR_INBOUND:
+			cell += form(clans.WAR_STOP, rel, "MANAGE_CLAN_WAR_STOP_SUBMIT", "")
+			cell += form(clans.WAR_RESTART, rel, "MANAGE_CLAN_WAR_RESTART_SUBMIT", "MANAGE_CLAN_WAR_RESTART_CONFIRM")
+		}
+		if rel.Status.Ally() {
+			allies = append(allies, cell)
+		} else {
+			wars = append(wars, cell)
+		}
+	}
+	// one form for each open slot
+	for i := allyCount; i < CLAN_MAXALLY; i++ {
+		allies = append(allies, choose(clans.ALLY_REQUEST, "MANAGE_CLAN_ALLY_REQUEST_LABEL", "MANAGE_CLAN_ALLY_REQUEST_SUBMIT"))
+	}
+	for i := warCount; i < CLAN_MAXWAR; i++ {
+		wars = append(wars, choose(clans.WAR_DECLARE, "MANAGE_CLAN_WAR_DECLARE_LABEL", "MANAGE_CLAN_WAR_DECLARE_SUBMIT"))
+	}
+	for i := 0; i < len(allies) || i < len(wars); i++ {
+		_, _ = w.Write([]byte(`<tr>`))
+		for _, column := range [][]string{allies, wars} {
+			if i < len(column) {
+				_, _ = w.Write([]byte(`<td>` + column[i] + `</td>`))
+			} else {
+				_, _ = w.Write([]byte(`<td></td>`))
+			}
+		}
+		_, _ = w.Write([]byte(`</tr>`))
+	}
+	_, _ = w.Write([]byte(`<tr><td colspan="2">` + s.language.Printf("MANAGE_CLAN_RELATION_WARNING", s.language.Number(CLAN_MINRELATE)) + `</td></tr>`))
+	_, _ = w.Write([]byte(`</tbody></table>`))
+
+	// all officers can send invitations, but only the leader and assistant can make them permanent
+	leads := emp.Id == clan.Leader || emp.Id == clan.Assistant
+	_, _ = w.Write([]byte(`<form method="post" action="/clan/manage"><input type="hidden" name="action" value="invite"/>`))
+	_, _ = w.Write([]byte(`<p>` + s.language.Printf("MANAGE_CLAN_INVITE_LABEL", `<input type="text" name="invite_emp" size="4" value="`+html.EscapeString(s.language.Prenum(0))+`"/>`) + `</p>`))
+	if leads {
+		_, _ = w.Write([]byte(`<p><label><input type="checkbox" name="invite_perm" value="1"/> ` + xlat("MANAGE_CLAN_INVITE_PERM_LABEL") + `</label></p>`))
+	}
+	_, _ = w.Write([]byte(`<p><input type="submit" value="` + xlat("MANAGE_CLAN_INVITE_SUBMIT") + `"/></p></form>`))
+	if len(state.Invites) != 0 {
+		_, _ = w.Write([]byte(`<table><thead><tr>`))
+		for _, column := range []string{"MANAGE_CLAN_UNINVITE_EMPIRE_LABEL", "MANAGE_CLAN_UNINVITE_INVITER_LABEL", "MANAGE_CLAN_UNINVITE_TYPE_LABEL", "MANAGE_CLAN_UNINVITE_DATE_LABEL", "MANAGE_CLAN_UNINVITE_ACTION_LABEL"} {
+			_, _ = w.Write([]byte(`<th>` + xlat(column) + `</th>`))
+		}
+		_, _ = w.Write([]byte(`</tr></thead><tbody>`))
+		for _, inv := range state.Invites {
+			kind, remove := "MANAGE_CLAN_UNINVITE_TYPE_TEMP", ""
+			if inv.Permanent {
+				kind = "MANAGE_CLAN_UNINVITE_TYPE_PERM"
+			}
+			// only the leader and assistant leader can remove whitelist entries
+			if leads || !inv.Permanent {
+				remove = `<form method="post" action="/clan/manage"><input type="hidden" name="action" value="uninvite"/>` +
+					`<input type="hidden" name="uninvite_id" value="` + strconv.Itoa(inv.Id) + `"/><input type="submit" value="` + xlat("MANAGE_CLAN_UNINVITE_SUBMIT") + `"/></form>`
+			}
+			_, _ = w.Write([]byte(fmt.Sprintf(`<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>`,
+				html.EscapeString(s.language.Printf("COMMON_EMPIRE_NAMEID", inv.RecipientName, s.language.Prenum(inv.Recipient))),
+				html.EscapeString(s.language.Printf("COMMON_EMPIRE_NAMEID", inv.SenderName, s.language.Prenum(inv.Sender))),
+				xlat(kind), inv.Time.Format("2006/01/02 15:04"), remove)))
+		}
+		_, _ = w.Write([]byte(`</tbody></table>`))
+	}
+	_, _ = w.Write([]byte(`<p><a href="/clan">` + xlat("CLAN_TITLE") + `</a> <a href="/clan/manage.json">JSON</a></p>`))
+	s.buildPageEnd(w)
+}
+
+// clanManageMessages translates the messages from clan management actions.
+// The messages contain markup, so the arguments are escaped instead:
+// numbers are formatted, strings are clan names, and empires are shown
+// with their names and numbers.
+func (s *server) clanManageMessages(messages []engine.Message_t) []string {
+	var list []string
+	for _, msg := range messages {
+		var args []any
+		for _, arg := range msg.Args {
+			switch v := arg.(type) {
+			case int:
+				args = append(args, s.language.Number(v))
+			case string:
+				args = append(args, html.EscapeString(v))
+			case model.Empire_t:
+				args = append(args, html.EscapeString(s.language.Printf("COMMON_EMPIRE_NAMEID", v.Name, s.language.Prenum(v.Id))))
+			default:
+				args = append(args, v)
+			}
+		}
+		list = append(list, s.language.Printf(msg.Key, args...))
+	}
+	return list
+}
@@ -0,0 +1,241 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/military"
+	"github.com/mdhender/promisance/app/model"
+	"html"
+	"log"
+	"net/http"
+	"time"
+)
+
+type militaryUnits_t struct {
+	TrpArm int `json:"trparm"`
+	TrpLnd int `json:"trplnd"`
+	TrpFly int `json:"trpfly"`
+	TrpSea int `json:"trpsea"`
+}
+
+type militaryState_t struct {
+	Units      militaryUnits_t `json:"units"`
+	Industry   militaryUnits_t `json:"industry"`
+	Production militaryUnits_t `json:"production"`
+	Peasants   int             `json:"peasants"`
+}
+
+type militaryResult_t struct {
+	Messages []string        `json:"messages"`
+	State    militaryState_t `json:"state"`
+}
+
+// militaryGetHandler shows the military management page, from php/pages/manage/empire.php.
+func (s *server) militaryGetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	s.militaryPage(w, r, emp, nil)
+}
+
+// militaryPostHandler updates the industry allocation or releases units from the posted form.
+func (s *server) militaryPostHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	var res *military.Result_t
+	var err error
+	switch action, _ := s.getFormVar(r, "action", ""); action {
+	case "industry":
+		res, err = s.military.SetIndustry(emp.Id, s.militaryFormUnits(r, "industry_"), time.Now())
+	case "release":
+		if round := s.roundData(time.Now()); round.Started && !round.Finished {
+			res, err = s.military.Release(emp.Id, round, s.militaryFormUnits(r, "release_"), time.Now())
+		} else {
+			res = &military.Result_t{}
+		}
+	default:
+		res = &military.Result_t{}
+	}
+	if err != nil {
+		log.Printf("%s %s: military: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	s.militaryPage(w, r, emp, s.militaryMessages(res.Messages))
+}
+
+// militaryJsonGetHandler returns the empire's units, industry, and production as JSON.
+func (s *server) militaryJsonGetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	state, err := s.military.State(emp.Id, time.Now())
+	if err != nil {
+		log.Printf("%s %s: state: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(militaryStateFromService(state))
+}
+
+// militaryJsonPostHandler updates the industry allocation or releases units.
+// The request body holds the action ("industry" or "release") and the
+// percentage of industry or the number of each type of unit.
+func (s *server) militaryJsonPostHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	var input struct {
+		Action string          `json:"action"`
+		Units  militaryUnits_t `json:"units"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, fmt.Sprintf("request: %v", err), http.StatusBadRequest)
+		return
+	}
+	units := engine.Units_t{TrpArm: input.Units.TrpArm, TrpLnd: input.Units.TrpLnd, TrpFly: input.Units.TrpFly, TrpSea: input.Units.TrpSea}
+	var res *military.Result_t
+	var err error
+	switch input.Action {
+	case "industry":
+		res, err = s.military.SetIndustry(emp.Id, units, time.Now())
+	case "release":
+		round := s.roundData(time.Now())
+		if !round.Started || round.Finished {
+			http.Error(w, s.language.Printf("MANAGE_EMPIRE_RELEASE_UNAVAILABLE"), http.StatusConflict)
+			return
+		}
+		res, err = s.military.Release(emp.Id, round, units, time.Now())
+	default:
+		http.Error(w, fmt.Sprintf("action: unknown value %q", input.Action), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("%s %s: military: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(militaryResult_t{
+		Messages: s.militaryMessages(res.Messages),
+		State:    militaryStateFromService(res.State),
+	})
+}
+
+// militaryPage writes the military management page.
+func (s *server) militaryPage(w http.ResponseWriter, r *http.Request, emp *model.Empire_t, notices []string) {
+	notices = s.pageNotices(r, notices)
+	round := s.roundData(time.Now())
+	state, err := s.military.State(emp.Id, time.Now())
+	if err != nil {
+		log.Printf("%s %s: state: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	era, err := s.tables.Era(emp.Era)
+	if err != nil {
+		log.Printf("%s %s: era: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	xlat := func(key string) string {
+		return html.EscapeString(s.language.Printf(key))
+	}
+	rows := []struct {
+		field      string // suffix of the form field
+		name       string // string ID of the unit's name in the empire's era
+		owned      int
+		industry   int
+		production int
+	}{
+		{"arm", era.TrpArm, state.Units.TrpArm, state.Industry.TrpArm, state.Production.TrpArm},
+		{"lnd", era.TrpLnd, state.Units.TrpLnd, state.Industry.TrpLnd, state.Production.TrpLnd},
+		{"fly", era.TrpFly, state.Units.TrpFly, state.Industry.TrpFly, state.Production.TrpFly},
+		{"sea", era.TrpSea, state.Units.TrpSea, state.Industry.TrpSea, state.Production.TrpSea},
+	}
+
+	s.buildPageStart(w, "MANAGE_EMPIRE_TITLE", notices)
+	_, _ = w.Write([]byte(`<h2>` + xlat("MANAGE_EMPIRE_INDUSTRY_LABEL") + `</h2>`))
+	_, _ = w.Write([]byte(`<form method="post" action="/manage/empire"><input type="hidden" name="action" value="industry"/><table><thead><tr>`))
+	for _, column := range []string{"COLUMN_UNIT", "COLUMN_OWNED", "MANAGE_EMPIRE_INDUSTRY_PRODUCTION", "MANAGE_EMPIRE_INDUSTRY_PERCENT"} {
+		_, _ = w.Write([]byte(`<th>` + xlat(column) + `</th>`))
+	}
+	_, _ = w.Write([]byte(`</tr></thead><tbody>`))
+	for _, row := range rows {
+		_, _ = w.Write([]byte(fmt.Sprintf(`<tr><td>%s</td><td>%s</td><td>%s</td><td><input type="text" name="industry_%s" size="3" value="%d"/>%%</td></tr>`,
+			xlat(row.name), s.language.Number(row.owned), s.language.Number(row.production), row.field, row.industry)))
+	}
+	_, _ = w.Write([]byte(`<tr><td colspan="4"><input type="submit" value="` + xlat("MANAGE_EMPIRE_INDUSTRY_SUBMIT") + `"/></td></tr>`))
+	_, _ = w.Write([]byte(`</tbody></table></form>`))
+
+	_, _ = w.Write([]byte(`<h2>` + xlat("MANAGE_EMPIRE_RELEASE_LABEL") + `</h2>`))
+	if !round.Started || round.Finished {
+		_, _ = w.Write([]byte(`<p class="box">` + xlat("MANAGE_EMPIRE_RELEASE_UNAVAILABLE") + `</p>`))
+	} else {
+		_, _ = w.Write([]byte(`<form method="post" action="/manage/empire"><input type="hidden" name="action" value="release"/><table><thead><tr>`))
+		for _, column := range []string{"COLUMN_UNIT", "COLUMN_OWNED", "COLUMN_QUANTITY"} {
+			_, _ = w.Write([]byte(`<th>` + xlat(column) + `</th>`))
+		}
+		_, _ = w.Write([]byte(`</tr></thead><tbody>`))
+		for _, row := range rows {
+			_, _ = w.Write([]byte(fmt.Sprintf(`<tr><td>%s</td><td>%s</td><td><input type="text" name="release_%s" size="8" value="0"/></td></tr>`,
+				xlat(row.name), s.language.Number(row.owned), row.field)))
+		}
+		_, _ = w.Write([]byte(fmt.Sprintf(`<tr><td>%s</td><td>%s</td><td><input type="submit" value="%s"/></td></tr>`,
+			xlat(era.Peasants), s.language.Number(state.Peasants), xlat("MANAGE_EMPIRE_RELEASE_SUBMIT"))))
+		_, _ = w.Write([]byte(`</tbody></table></form>`))
+	}
+	_, _ = w.Write([]byte(`<p><a href="/manage/empire.json">JSON</a></p>`))
+	s.buildPageEnd(w)
+}
+
+// militaryFormUnits returns the value posted for each type of unit.
+func (s *server) militaryFormUnits(r *http.Request, prefix string) engine.Units_t {
+	return engine.Units_t{
+		TrpArm: s.getFormNum(r, prefix+"arm"),
+		TrpLnd: s.getFormNum(r, prefix+"lnd"),
+		TrpFly: s.getFormNum(r, prefix+"fly"),
+		TrpSea: s.getFormNum(r, prefix+"sea"),
+	}
+}
+
+// militaryMessages translates the messages from managing the military.
+func (s *server) militaryMessages(messages []engine.Message_t) []string {
+	var list []string
+	for _, msg := range messages {
+		args := msg.Args
+		if msg.Key == "MANAGE_EMPIRE_RELEASE_COMPLETE" {
+			args = []any{s.language.Number(msg.Args[0].(int))}
+		}
+		list = append(list, s.language.Printf(msg.Key, args...))
+	}
+	return list
+}
+
+func militaryUnitsFromEngine(u engine.Units_t) militaryUnits_t {
+	return militaryUnits_t{TrpArm: u.TrpArm, TrpLnd: u.TrpLnd, TrpFly: u.TrpFly, TrpSea: u.TrpSea}
+}
+
+func militaryStateFromService(state *military.State_t) militaryState_t {
+	return militaryState_t{
+		Units:      militaryUnitsFromEngine(state.Units),
+		Industry:   militaryUnitsFromEngine(state.Industry),
+		Production: militaryUnitsFromEngine(state.Production),
+		Peasants:   state.Peasants,
+	}
+}
@@ -0,0 +1,305 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/market"
+	"github.com/mdhender/promisance/app/model"
+	"html"
+	"log"
+	"net/http"
+	"time"
+)
+
+type pvtmarketQuote_t struct {
+	Good      string `json:"good"`
+	Owned     int    `json:"owned"`
+	Stock     int    `json:"stock"`
+	BuyPrice  int    `json:"buyPrice"`
+	CanBuy    int    `json:"canBuy"`
+	SellPrice int    `json:"sellPrice"`
+	CanSell   int    `json:"canSell"`
+}
+
+type pvtmarketOrder_t struct {
+	Good   string `json:"good"`
+	Amount int    `json:"amount"`
+}
+
+// pvtmarketBuyGetHandler shows the private market buy page, from php/pages/pvtmarketbuy.php.
+func (s *server) pvtmarketBuyGetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	s.pvtmarketPage(w, r, emp, "buy", nil)
+}
+
+// pvtmarketBuyPostHandler buys goods from the private market.
+func (s *server) pvtmarketBuyPostHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	var notices []string
+	if round := s.roundData(time.Now()); round.Started && !round.Finished {
+		res, err := s.market.PrivateBuy(emp.Id, round, s.pvtmarketFormOrders(r, "buy_"))
+		if err != nil {
+			log.Printf("%s %s: privateBuy: %v\n", r.Method, r.URL.Path, err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		notices = s.pvtmarketMessages(res.Messages)
+	}
+	s.pvtmarketPage(w, r, emp, "buy", notices)
+}
+
+// pvtmarketSellGetHandler shows the private market sell page, from php/pages/pvtmarketsell.php.
+func (s *server) pvtmarketSellGetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	s.pvtmarketPage(w, r, emp, "sell", nil)
+}
+
+// pvtmarketSellPostHandler sells goods to the private market.
+func (s *server) pvtmarketSellPostHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	var notices []string
+	if round := s.roundData(time.Now()); round.Started && !round.Finished {
+		res, err := s.market.PrivateSell(emp.Id, round, s.pvtmarketFormOrders(r, "sell_"))
+		if err != nil {
+			log.Printf("%s %s: privateSell: %v\n", r.Method, r.URL.Path, err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		notices = s.pvtmarketMessages(res.Messages)
+	}
+	s.pvtmarketPage(w, r, emp, "sell", notices)
+}
+
+// pvtmarketJsonGetHandler returns the empire's private market prices, stock, and limits as JSON.
+func (s *server) pvtmarketJsonGetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	quotes, err := s.market.PrivateQuotes(emp.Id)
+	if err != nil {
+		log.Printf("%s %s: privateQuotes: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	list := []pvtmarketQuote_t{}
+	for _, q := range quotes {
+		list = append(list, pvtmarketQuote_t{
+			Good:      q.Good.String(),
+			Owned:     q.Owned,
+			Stock:     q.Stock,
+			BuyPrice:  q.BuyPrice,
+			CanBuy:    q.CanBuy,
+			SellPrice: q.SellPrice,
+			CanSell:   q.CanSell,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(struct {
+		Cash   int                `json:"cash"`
+		Quotes []pvtmarketQuote_t `json:"quotes"`
+	}{
+		Cash:   emp.Cash,
+		Quotes: list,
+	})
+}
+
+// pvtmarketJsonPostHandler buys or sells goods on the private market.
+// The request body holds the action ("buy" or "sell") and a list of orders.
+func (s *server) pvtmarketJsonPostHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+	emp := s.requireEmpire(w, r)
+	if emp == nil {
+		return
+	}
+	var input struct {
+		Action string             `json:"action"`
+		Orders []pvtmarketOrder_t `json:"orders"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, fmt.Sprintf("request: %v", err), http.StatusBadRequest)
+		return
+	}
+	trade := s.market.PrivateBuy
+	switch input.Action {
+	case "buy":
+	case "sell":
+		trade = s.market.PrivateSell
+	default:
+		http.Error(w, fmt.Sprintf("action: unknown value %q", input.Action), http.StatusBadRequest)
+		return
+	}
+	var orders []market.Order_t
+	for _, order := range input.Orders {
+		g, ok := pvtmarketGood(order.Good)
+		if !ok {
+			http.Error(w, fmt.Sprintf("good: unknown value %q", order.Good), http.StatusBadRequest)
+			return
+		}
+		orders = append(orders, market.Order_t{Good: g, Amount: order.Amount})
+	}
+	round := s.roundData(time.Now())
+	if round.Finished {
+		http.Error(w, s.language.Printf("PVTMARKETBUY_UNAVAILABLE_END"), http.StatusConflict)
+		return
+	} else if !round.Started {
+		http.Error(w, s.language.Printf("PVTMARKETBUY_UNAVAILABLE_START"), http.StatusConflict)
+		return
+	}
+	res, err := trade(emp.Id, round, orders)
+	if err != nil {
+		log.Printf("%s %s: %s: %v\n", r.Method, r.URL.Path, input.Action, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(struct {
+		Cash     int      `json:"cash"`
+		Messages []string `json:"messages"`
+	}{
+		Cash:     res.Cash,
+		Messages: s.pvtmarketMessages(res.Messages),
+	})
+}
+
+// pvtmarketPage writes the buy or sell page for the private market.
+func (s *server) pvtmarketPage(w http.ResponseWriter, r *http.Request, emp *model.Empire_t, action string, notices []string) {
+	notices = s.pageNotices(r, notices)
+	prefix := "PVTMARKETBUY"
+	if action == "sell" {
+		prefix = "PVTMARKETSELL"
+	}
+	round := s.roundData(time.Now())
+	if round.Finished {
+		notices = append(notices, s.language.Printf(prefix+"_UNAVAILABLE_END"))
+	} else if !round.Started {
+		notices = append(notices, s.language.Printf(prefix+"_UNAVAILABLE_START"))
+	}
+	era, err := s.tables.Era(emp.Era)
+	if err != nil {
+		log.Printf("%s %s: era: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	quotes, err := s.market.PrivateQuotes(emp.Id)
+	if err != nil {
+		log.Printf("%s %s: privateQuotes: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	title := html.EscapeString(s.language.Printf(prefix + "_TITLE"))
+	_, _ = w.Write([]byte(`<!DOCTYPE html><html lang="en"><head><meta charset="UTF-8"><title>` + title + `</title><link rel="stylesheet" href="https://unpkg.com/missing.css@1.1.1"></head><body>`))
+	_, _ = w.Write([]byte(`<h1>` + title + `</h1>`))
+	_, _ = w.Write([]byte(`<main>`))
+	for _, notice := range notices {
+		_, _ = w.Write([]byte(`<p class="box">` + html.EscapeString(notice) + `</p>`))
+	}
+	if !round.Started || round.Finished {
+		_, _ = w.Write([]byte(`</main>`))
+		_, _ = w.Write([]byte(`</body>`))
+		return
+	}
+	_, _ = w.Write([]byte(fmt.Sprintf(`<p><a href="/pvtmarket/buy">%s</a> | <a href="/pvtmarket/sell">%s</a></p>`,
+		html.EscapeString(s.language.Printf("PVTMARKET_LINK_BUY")), html.EscapeString(s.language.Printf("PVTMARKET_LINK_SELL")))))
+	_, _ = w.Write([]byte(`<form method="post" action="/pvtmarket/` + action + `">`))
+	_, _ = w.Write([]byte(`<table><thead><tr>`))
+	columns := []string{"COLUMN_UNIT", "COLUMN_OWNED", "COLUMN_AVAIL", "COLUMN_PRICE", "COLUMN_CANBUY", "COLUMN_BUY"}
+	if action == "sell" {
+		columns = []string{"COLUMN_UNIT", "COLUMN_OWNED", "COLUMN_PRICE", "COLUMN_CANSELL", "COLUMN_SELL"}
+	}
+	for _, column := range columns {
+		_, _ = w.Write([]byte(`<th>` + html.EscapeString(s.language.Printf(column)) + `</th>`))
+	}
+	_, _ = w.Write([]byte(`</tr></thead><tbody>`))
+	for _, q := range quotes {
+		name := html.EscapeString(s.language.Printf(q.Good.Name(era)))
+		input := fmt.Sprintf(`<input type="text" name="%s_%s" size="8" value="0"/>`, action, q.Good)
+		if action == "sell" {
+			_, _ = w.Write([]byte(fmt.Sprintf(`<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>`,
+				name, s.language.Number(q.Owned), s.language.Money(q.SellPrice), s.language.Number(q.CanSell), input)))
+		} else {
+			_, _ = w.Write([]byte(fmt.Sprintf(`<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>`,
+				name, s.language.Number(q.Owned), s.language.Number(q.Stock), s.language.Money(q.BuyPrice), s.language.Number(q.CanBuy), input)))
+		}
+	}
+	_, _ = w.Write([]byte(`</tbody></table>`))
+	_, _ = w.Write([]byte(`<input type="submit" value="` + html.EscapeString(s.language.Printf(prefix+"_SUBMIT")) + `"/>`))
+	_, _ = w.Write([]byte(`</form>`))
+	_, _ = w.Write([]byte(`<p><a href="/pvtmarket.json">JSON</a></p>`))
+	_, _ = w.Write([]byte(`</main>`))
+	_, _ = w.Write([]byte(`</body>`))
+}
+
+// pvtmarketFormOrders returns an order for each type of goods in the posted form.
+// Amounts that are missing or not valid are skipped.
+func (s *server) pvtmarketFormOrders(r *http.Request, prefix string) []market.Order_t {
+	var orders []market.Order_t
+	for _, g := range engine.Goods() {
+		amount := s.getFormNum(r, prefix+g.String())
+		if amount == 0 {
+			continue
+		}
+		orders = append(orders, market.Order_t{Good: g, Amount: amount})
+	}
+	return orders
+}
+
+// pvtmarketMessages translates the messages from the private market.
+// Counts are formatted as numbers, and the total cost (always the third argument) as money.
+func (s *server) pvtmarketMessages(messages []engine.Message_t) []string {
+	var list []string
+	for _, msg := range messages {
+		var args []any
+		for i, arg := range msg.Args {
+			switch v := arg.(type) {
+			case int:
+				if i == 2 {
+					args = append(args, s.language.Money(v))
+				} else {
+					args = append(args, s.language.Number(v))
+				}
+			case string:
+				args = append(args, s.language.Printf(v))
+			default:
+				args = append(args, v)
+			}
+		}
+		list = append(list, s.language.Printf(msg.Key, args...))
+	}
+	return list
+}
+
+// pvtmarketGood returns the goods with the given name.
+func pvtmarketGood(name string) (engine.Good_t, bool) {
+	for _, g := range engine.Goods() {
+		if g.String() == name {
+			return g, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,419 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"html/template"
+	"log"
+	"net/http"
+	"time"
+)
+
+// turnsPage_t describes a page that spends turns, from php/pages/farm.php,
+// php/pages/cash.php, and php/pages/land.php.
+type turnsPage_t struct {
+	path   string // route for the page
+	field  string // prefix of the form fields
+	action string // value of the form's action field
+	prefix string // prefix of the page's message keys
+}
+
+var turnsPages = map[engine.Action_t]turnsPage_t{
+	engine.ACTION_CASH: {path: "/cash", field: "cash", action: "cash", prefix: "CASH"},
+	engine.ACTION_FARM: {path: "/farm", field: "farm", action: "farm", prefix: "FARM"},
+	engine.ACTION_LAND: {path: "/land", field: "land", action: "explore", prefix: "LAND"},
+}
+
+// turnsPayload_t is the data for the turns.gohtml template.
+type turnsPayload_t struct {
+	Title         template.HTML
+	Reports       []turnsReportView_t
+	Notices       []string
+	Available     bool // false if turns may not be used
+	Header        template.HTML
+	Path          string
+	Field         string
+	Action        string
+	Label         template.HTML
+	Summary       template.HTML
+	Condensed     bool
+	Submit        template.HTML
+	CashHeader    template.HTML
+	FoodHeader    template.HTML
+	UnitsHeader   template.HTML
+	UnitsNoChange template.HTML
+}
+
+// turnsReportView_t is the status report printed after taking turns.
+type turnsReportView_t struct {
+	Withdraw template.HTML
+	Cash     []turnsReportRow_t
+	Food     []turnsReportRow_t
+	Units    []turnsReportRow_t // only units that changed
+	Notes    []turnsReportNote_t
+}
+
+type turnsReportRow_t struct {
+	Label template.HTML
+	Value string
+	Class string
+}
+
+type turnsReportNote_t struct {
+	Class string
+	Text  template.HTML
+}
+
+type turnsState_t struct {
+	Turns   int `json:"turns"`
+	Explore int `json:"explore"`
+}
+
+type turnsTurn_t struct {
+	Withdraw  int      `json:"withdraw,omitempty"`
+	Income    int      `json:"income"`
+	Expenses  int      `json:"expenses"`
+	WarTax    int      `json:"warTax,omitempty"`
+	LoanPayed int      `json:"loanPayed,omitempty"`
+	Money     int      `json:"money"`
+	TrpArm    int      `json:"trparm"`
+	TrpLnd    int      `json:"trplnd"`
+	TrpFly    int      `json:"trpfly"`
+	TrpSea    int      `json:"trpsea"`
+	FoodPro   int      `json:"foodpro"`
+	FoodCon   int      `json:"foodcon"`
+	Food      int      `json:"food"`
+	Peasants  int      `json:"peasants"`
+	Runes     int      `json:"runes"`
+	TrpWiz    int      `json:"trpwiz"`
+	Trouble   []string `json:"trouble,omitempty"`
+}
+
+type turnsResult_t struct {
+	Action     string        `json:"action"`
+	Taken      int           `json:"taken"`
+	Result     int           `json:"result"`               // land explored, net cash, or net food, depending on the action
+	Halted     bool          `json:"halted"`               // true if turns stopped early
+	StopReason []string      `json:"stopReason,omitempty"` // the trouble that stopped the turns
+	Deserted   int           `json:"deserted"`             // percentage of population and military that deserted
+	Turns      []turnsTurn_t `json:"turns"`
+	Overall    turnsTurn_t   `json:"overall"`
+	Messages   []string      `json:"messages"`
+	State      turnsState_t  `json:"state"`
+}
+
+// turnsGetHandler returns a handler that shows the page for the action.
+func (s *server) turnsGetHandler(action engine.Action_t) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+		emp := s.requireEmpire(w, r)
+		if emp == nil {
+			return
+		}
+		s.turnsPage(w, r, emp, action, nil, nil, true)
+	}
+}
+
+// turnsPostHandler returns a handler that spends the turns from the posted form on the action.
+func (s *server) turnsPostHandler(action engine.Action_t) http.HandlerFunc {
+	page := turnsPages[action]
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+		emp := s.requireEmpire(w, r)
+		if emp == nil {
+			return
+		}
+		condensed, _ := s.getFormVar(r, page.field+"_condensed", "")
+		if form, _ := s.getFormVar(r, "action", ""); form != page.action {
+			s.turnsPage(w, r, emp, action, nil, nil, condensed != "")
+			return
+		}
+		round := s.roundData(time.Now())
+		if round.Finished || !round.Started {
+			s.turnsPage(w, r, emp, action, nil, nil, condensed != "")
+			return
+		}
+		res, err := s.actions.Take(emp.Id, round, action, s.getFormNum(r, page.field+"_turns"), time.Now())
+		if err != nil {
+			log.Printf("%s %s: take: %v\n", r.Method, r.URL.Path, err)
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		s.turnsPage(w, r, emp, action, res.Report, s.turnsMessages(res.Messages), condensed != "")
+	}
+}
+
+// turnsJsonGetHandler returns a handler that returns the turns the empire can spend as JSON.
+func (s *server) turnsJsonGetHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+		emp := s.requireEmpire(w, r)
+		if emp == nil {
+			return
+		}
+		state, err := s.actions.State(emp.Id, time.Now())
+		if err != nil {
+			log.Printf("%s %s: state: %v\n", r.Method, r.URL.Path, err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(turnsState_t{Turns: state.Turns, Explore: state.Explore})
+	}
+}
+
+// turnsJsonPostHandler returns a handler that spends turns on the action.
+// The request body holds the number of turns to spend.
+// The report includes every turn taken and the total for all of them.
+func (s *server) turnsJsonPostHandler(action engine.Action_t) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Printf("%s %s: entered\n", r.Method, r.URL.Path)
+		emp := s.requireEmpire(w, r)
+		if emp == nil {
+			return
+		}
+		var input struct {
+			Turns int `json:"turns"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			http.Error(w, fmt.Sprintf("request: %v", err), http.StatusBadRequest)
+			return
+		}
+		res, err := s.actions.Take(emp.Id, s.roundData(time.Now()), action, input.Turns, time.Now())
+		if errors.Is(err, cerr.ErrRoundFinished) {
+			http.Error(w, s.language.Printf("TURNS_UNAVAILABLE_END"), http.StatusConflict)
+			return
+		} else if errors.Is(err, cerr.ErrRoundNotStarted) {
+			http.Error(w, s.language.Printf("TURNS_UNAVAILABLE_START"), http.StatusConflict)
+			return
+		} else if err != nil {
+			log.Printf("%s %s: take: %v\n", r.Method, r.URL.Path, err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		result := turnsResult_t{
+			Action:   string(action),
+			Turns:    []turnsTurn_t{},
+			Messages: s.turnsMessages(res.Messages),
+			State:    turnsState_t{Turns: res.State.Turns, Explore: res.State.Explore},
+		}
+		if report := res.Report; report != nil {
+			result.Taken, result.Result, result.Halted, result.Deserted = report.Taken, report.Result, report.Halted, report.Deserted
+			if report.Halted {
+				result.StopReason = turnsTrouble(report.Trouble)
+			}
+			for _, turn := range report.Turns {
+				result.Turns = append(result.Turns, turnsTurnFromEngine(turn))
+			}
+			result.Overall = turnsTurnFromEngine(report.Overall)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(result)
+	}
+}
+
+// turnsPage renders the page for the action with the status reports for the turns taken.
+// If condensed is set, a single report is printed for all the turns.
+func (s *server) turnsPage(w http.ResponseWriter, r *http.Request, emp *model.Empire_t, action engine.Action_t, report *engine.Report_t, notices []string, condensed bool) {
+	notices = s.pageNotices(r, notices)
+	page := turnsPages[action]
+	round := s.roundData(time.Now())
+	if round.Finished {
+		notices = append(notices, s.language.Printf("TURNS_UNAVAILABLE_END"))
+	} else if !round.Started {
+		notices = append(notices, s.language.Printf("TURNS_UNAVAILABLE_START"))
+	}
+	state, err := s.actions.State(emp.Id, time.Now())
+	if err != nil {
+		log.Printf("%s %s: state: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	era, err := s.tables.Era(emp.Era)
+	if err != nil {
+		log.Printf("%s %s: era: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	payload := turnsPayload_t{
+		Title:         s.language.PrintfHTML(page.prefix + "_TITLE"),
+		Notices:       notices,
+		Available:     round.Started && !round.Finished,
+		Path:          page.path,
+		Field:         page.field,
+		Action:        page.action,
+		Label:         s.language.PrintfHTML(page.prefix + "_LABEL"),
+		Summary:       s.language.PrintfHTML("COMMON_TURNS_SUMMARY"),
+		Condensed:     condensed,
+		Submit:        s.language.PrintfHTML(page.prefix + "_SUBMIT"),
+		CashHeader:    s.language.PrintfHTML("TURNS_CASH_HEADER"),
+		FoodHeader:    s.language.PrintfHTML("TURNS_FOOD_HEADER"),
+		UnitsHeader:   s.language.PrintfHTML("TURNS_UNITS_HEADER"),
+		UnitsNoChange: s.language.PrintfHTML("TURNS_UNITS_NOCHANGE"),
+	}
+	switch action {
+	case engine.ACTION_CASH:
+		payload.Header = s.language.PrintfHTML("CASH_HEADER", s.language.Printf(era.Peasants))
+	case engine.ACTION_FARM:
+		payload.Header = s.language.PrintfHTML("FARM_HEADER", s.language.Printf(era.BldFood), s.language.Printf(era.Food))
+	case engine.ACTION_LAND:
+		payload.Header = s.language.PrintfHTML("LAND_HEADER", s.language.Plural(state.Explore, "ACRES_SINGLE", "ACRES_PLURAL", ""))
+	}
+	if report != nil && report.Taken != 0 {
+		if condensed {
+			payload.Reports = append(payload.Reports, s.turnsReportView(era, state.Tax, report.Overall, report.Trouble))
+		} else {
+			for _, turn := range report.Turns {
+				payload.Reports = append(payload.Reports, s.turnsReportView(era, state.Tax, turn, turn.Trouble))
+			}
+		}
+		if report.Halted {
+			last := &payload.Reports[len(payload.Reports)-1]
+			last.Notes = append(last.Notes, turnsReportNote_t{Class: "cbad", Text: s.language.PrintfHTML("TURNS_TROUBLE_LOSSES_HALT", s.language.Percent(float64(report.Deserted), 0))})
+		}
+	}
+	s.render(w, r, payload, "turns.gohtml")
+}
+
+// turnsReportView returns the status report for the results of one turn, or of all the turns taken.
+func (s *server) turnsReportView(era *engine.Era_t, tax int, stats engine.Turn_t, trouble int) turnsReportView_t {
+	var view turnsReportView_t
+	if stats.Withdraw > 0 {
+		view.Withdraw = s.language.PrintfHTML("TURNS_WITHDRAW", s.language.Money(stats.Withdraw))
+	}
+
+	view.Cash = append(view.Cash, turnsReportRow_t{Label: s.language.PrintfHTML("TURNS_CASH_INCOME"), Value: s.language.Money(stats.Income), Class: "cneutral"})
+	view.Cash = append(view.Cash, turnsReportRow_t{Label: s.language.PrintfHTML("TURNS_CASH_EXPENSE"), Value: s.language.Money(stats.Expenses), Class: "cneutral"})
+	if stats.WarTax != 0 {
+		view.Cash = append(view.Cash, turnsReportRow_t{Label: s.language.PrintfHTML("TURNS_CASH_WARTAX"), Value: s.language.Money(stats.WarTax), Class: "cneutral"})
+	}
+	if stats.LoanPayed != 0 {
+		view.Cash = append(view.Cash, turnsReportRow_t{Label: s.language.PrintfHTML("TURNS_CASH_LOANPAY"), Value: s.language.Money(turnsAbs(stats.LoanPayed)), Class: turnsColor(stats.LoanPayed, "cwarn")})
+	}
+	view.Cash = append(view.Cash, turnsReportRow_t{Label: s.language.PrintfHTML("TURNS_CASH_NET"), Value: s.language.Money(turnsAbs(stats.Money)), Class: turnsColor(stats.Money, "cbad")})
+
+	view.Food = append(view.Food, turnsReportRow_t{Label: s.language.PrintfHTML("TURNS_FOOD_PRODUCE"), Value: s.language.Number(stats.FoodPro), Class: "cneutral"})
+	view.Food = append(view.Food, turnsReportRow_t{Label: s.language.PrintfHTML("TURNS_FOOD_CONSUME"), Value: s.language.Number(stats.FoodCon), Class: "cneutral"})
+	view.Food = append(view.Food, turnsReportRow_t{Label: s.language.PrintfHTML("TURNS_FOOD_NET"), Value: s.language.Number(turnsAbs(stats.Food)), Class: turnsColor(stats.Food, "cbad")})
+
+	for _, unit := range []struct {
+		name   string
+		change int
+	}{
+		{era.Peasants, stats.Peasants},
+		{era.TrpWiz, stats.TrpWiz},
+		{era.Runes, stats.Runes},
+		{era.TrpArm, stats.TrpArm},
+		{era.TrpLnd, stats.TrpLnd},
+		{era.TrpFly, stats.TrpFly},
+		{era.TrpSea, stats.TrpSea},
+	} {
+		if unit.change != 0 {
+			view.Units = append(view.Units, turnsReportRow_t{Label: s.language.PrintfHTML(unit.name) + ":", Value: s.language.Number(turnsAbs(unit.change)), Class: turnsColor(unit.change, "cbad")})
+		}
+	}
+
+	if tax > 40 && stats.Peasants < 0 {
+		view.Notes = append(view.Notes, turnsReportNote_t{Class: "cbad", Text: s.language.PrintfHTML("TURNS_TAX_HIGH")})
+	} else if tax < 20 && stats.Peasants > 0 {
+		view.Notes = append(view.Notes, turnsReportNote_t{Class: "cgood", Text: s.language.PrintfHTML("TURNS_TAX_LOW")})
+	}
+	if trouble&engine.TURNS_TROUBLE_CASH != 0 {
+		view.Notes = append(view.Notes, turnsReportNote_t{Class: "cwarn", Text: s.language.PrintfHTML("TURNS_TROUBLE_CASH")})
+	}
+	if trouble&engine.TURNS_TROUBLE_LOAN != 0 {
+		view.Notes = append(view.Notes, turnsReportNote_t{Class: "cbad", Text: s.language.PrintfHTML("TURNS_TROUBLE_LOAN")})
+	}
+	if trouble&engine.TURNS_TROUBLE_FOOD != 0 {
+		view.Notes = append(view.Notes, turnsReportNote_t{Class: "cbad", Text: s.language.PrintfHTML("TURNS_TROUBLE_FOOD")})
+	}
+	return view
+}
+
+// turnsMessages translates the messages from taking turns.
+func (s *server) turnsMessages(messages []engine.Message_t) []string {
+	var list []string
+	for _, msg := range messages {
+		var args []any
+		switch msg.Key {
+		case "CASH_COMPLETE":
+			args = []any{s.language.Money(msg.Args[0].(int)), s.turnsTaken(msg.Args[1].(int))}
+		case "FARM_COMPLETE":
+			args = []any{s.language.Number(msg.Args[0].(int)), s.language.Printf(msg.Args[1].(string)), s.turnsTaken(msg.Args[2].(int))}
+		case "LAND_COMPLETE":
+			args = []any{s.language.Plural(msg.Args[0].(int), "ACRES_SINGLE", "ACRES_PLURAL", ""), s.turnsTaken(msg.Args[1].(int))}
+		default:
+			args = msg.Args
+		}
+		list = append(list, s.language.Printf(msg.Key, args...))
+	}
+	return list
+}
+
+func (s *server) turnsTaken(n int) string {
+	return s.language.Plural(n, "TURNS_SINGLE", "TURNS_PLURAL", "")
+}
+
+// turnsAbs returns the magnitude of a change, which is colored to show its sign.
+func turnsAbs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// turnsColor returns the class used to color a change, from colornum in php/includes/misc.php.
+func turnsColor(n int, negative string) string {
+	if n < 0 {
+		return negative
+	} else if n > 0 {
+		return "cgood"
+	}
+	return "cneutral"
+}
+
+// turnsTrouble returns the names of the TURNS_TROUBLE_* flags.
+func turnsTrouble(trouble int) []string {
+	var list []string
+	if trouble&engine.TURNS_TROUBLE_CASH != 0 {
+		list = append(list, "cash")
+	}
+	if trouble&engine.TURNS_TROUBLE_LOAN != 0 {
+		list = append(list, "loan")
+	}
+	if trouble&engine.TURNS_TROUBLE_FOOD != 0 {
+		list = append(list, "food")
+	}
+	return list
+}
+
+func turnsTurnFromEngine(turn engine.Turn_t) turnsTurn_t {
+	return turnsTurn_t{
+		Withdraw:  turn.Withdraw,
+		Income:    turn.Income,
+		Expenses:  turn.Expenses,
+		WarTax:    turn.WarTax,
+		LoanPayed: turn.LoanPayed,
+		Money:     turn.Money,
+		TrpArm:    turn.TrpArm,
+		TrpLnd:    turn.TrpLnd,
+		TrpFly:    turn.TrpFly,
+		TrpSea:    turn.TrpSea,
+		FoodPro:   turn.FoodPro,
+		FoodCon:   turn.FoodCon,
+		Food:      turn.Food,
+		Peasants:  turn.Peasants,
+		Runes:     turn.Runes,
+		TrpWiz:    turn.TrpWiz,
+		Trouble:   turnsTrouble(turn.Trouble),
+	}
+}
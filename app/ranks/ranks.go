@@ -0,0 +1,111 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+// Package ranks implements the empire and user rankings from
+// php/classes/prom_turns.php and php/util/fixranks.php.
+package ranks
+
+import (
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/orm"
+	"math"
+	"sort"
+)
+
+// Result_t is the result of updating the empire rankings.
+type Result_t struct {
+	Networth int // number of empires whose networth was corrected
+	Ranked   int // number of empires ranked
+}
+
+// Update recomputes the networth of every empire linked to a user and then
+// ranks them, all in a single transaction. Empires are ranked by networth,
+// or by score if the orm.HRFLAG_SCORE flag is set.
+func Update(db *orm.DB, e *engine.Engine_t, flags int) (Result_t, error) {
+	var result Result_t
+	err := db.Transaction(func(tx *orm.DB) error {
+		ids, err := tx.EmpirePlayerIds()
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			emp, err := tx.EmpireFetch(id)
+			if err != nil {
+				return err
+			}
+			if networth := e.Networth(emp); networth != emp.NetWorth {
+				if err := tx.EmpireNetworthUpdate(id, networth); err != nil {
+					return err
+				}
+				result.Networth++
+			}
+		}
+		result.Ranked, err = tx.EmpiresUpdateRanks(flags)
+		return err
+	})
+	if err != nil {
+		return Result_t{}, err
+	}
+	return result, nil
+}
+
+// FixUsers recalculates the average rank, best rank, and number of
+// successful plays for every user account from the round history.
+// It returns the number of accounts whose rankings were wrong.
+func FixUsers(db *orm.DB) (int, error) {
+	var fixCount int
+	err := db.Transaction(func(tx *orm.DB) error {
+		maxRanks, err := tx.HistoryEmpireMaxRanks()
+		if err != nil {
+			return err
+		}
+		rounds := make([]int, 0, len(maxRanks))
+		for id := range maxRanks {
+			rounds = append(rounds, id)
+		}
+		sort.Ints(rounds)
+
+		users, err := tx.UserRankingsFetch()
+		if err != nil {
+			return err
+		}
+		for _, user := range users {
+			if user.NumPlays == 0 {
+				continue
+			}
+			myRanks, err := tx.HistoryEmpireUserRanks(user.UserId)
+			if err != nil {
+				return err
+			}
+			var avgRank, bestRank float64
+			var sucPlays int
+			for _, round := range rounds {
+				myRank, ok := myRanks[round]
+				if !ok || myRank > maxRanks[round] {
+					// user did not play in this round
+					continue
+				}
+				thisRank := 1 - float64(myRank-1)/float64(maxRanks[round])
+				avgRank += thisRank
+				bestRank = math.Max(bestRank, thisRank)
+				sucPlays++
+			}
+			if sucPlays != 0 {
+				avgRank /= float64(sucPlays)
+			}
+
+			if math.Abs(user.AvgRank-avgRank) > 0.001 || math.Abs(user.BestRank-bestRank) > 0.001 || user.SucPlays != sucPlays {
+				fixCount++
+			}
+
+			user.AvgRank, user.BestRank, user.SucPlays = avgRank, bestRank, sucPlays
+			if err := tx.UserRankingsUpdate(user); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return fixCount, nil
+}
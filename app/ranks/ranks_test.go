@@ -0,0 +1,73 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package ranks
+
+import (
+	"fmt"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/orm"
+	"testing"
+)
+
+func TestUpdate(t *testing.T) {
+	db, err := orm.CreateMemoryDatabase()
+	if err != nil {
+		t.Fatalf("database: %v", err)
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+	e := engine.New(engine.Config_t{PvtmTrpArm: 500, PvtmTrpLnd: 1000, PvtmTrpFly: 2000, PvtmTrpSea: 3000, PvtmFood: 30}, nil)
+
+	// the smallest empire has the highest score
+	var ids []int
+	for n, land := range []int{250, 1000, 500} {
+		user, err := db.UserCreate(fmt.Sprintf("player%d", n), fmt.Sprintf("player%d@example.com", n))
+		if err != nil {
+			t.Fatalf("user: %v", err)
+		}
+		emp, err := db.EmpireCreate(user, fmt.Sprintf("empire %d", n), "HUMAN")
+		if err != nil {
+			t.Fatalf("empire: %v", err)
+		}
+		emp.Land, emp.Score, emp.NetWorth = land, 3-n, 0
+		if err := db.EmpireAttributesUpdate(emp); err != nil {
+			t.Fatalf("empire: %v", err)
+		}
+		ids = append(ids, emp.Id)
+	}
+
+	for _, tc := range []struct {
+		flags int
+		ranks []int
+	}{
+		{flags: 0, ranks: []int{3, 1, 2}},
+		{flags: orm.HRFLAG_SCORE, ranks: []int{1, 2, 3}},
+	} {
+		result, err := Update(db, e, tc.flags)
+		if err != nil {
+			t.Fatalf("%d: update: %v", tc.flags, err)
+		} else if result.Ranked != len(ids) {
+			t.Errorf("%d: ranked: want %d, got %d", tc.flags, len(ids), result.Ranked)
+		}
+		for i, id := range ids {
+			emp, err := db.EmpireFetch(id)
+			if err != nil {
+				t.Fatalf("%d: fetch: %v", tc.flags, err)
+			}
+			if emp.Rank != tc.ranks[i] {
+				t.Errorf("%d: %s: rank: want %d, got %d", tc.flags, emp.Name, tc.ranks[i], emp.Rank)
+			}
+			if want := e.Networth(emp); emp.NetWorth != want {
+				t.Errorf("%d: %s: networth: want %d, got %d", tc.flags, emp.Name, want, emp.NetWorth)
+			}
+		}
+	}
+
+	// networth is only corrected once
+	if result, err := Update(db, e, 0); err != nil {
+		t.Fatalf("again: update: %v", err)
+	} else if result.Networth != 0 {
+		t.Errorf("again: networth: want 0, got %d", result.Networth)
+	}
+}
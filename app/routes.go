@@ -11,8 +11,10 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/engine"
 	"github.com/mdhender/promisance/app/model"
 	"github.com/mdhender/promisance/app/way"
+	"html"
 	"html/template"
 	"log"
 	"net/http"
@@ -20,13 +22,78 @@ import (
 	"os"
 	"path/filepath"
 	"runtime/debug"
+	"strings"
 	"time"
 )
 
 func (s *server) routes() http.Handler {
 	r := way.NewRouter()
 	r.Handle("GET", "/", s.sessions.Authenticator(s.indexGetHandler))
+	r.Handle("GET", "/admin/turnlog", s.sessions.Authenticator(s.adminTurnlogGetHandler))
+	r.Handle("GET", "/admin/turnlog.json", s.sessions.Authenticator(s.adminTurnlogJsonGetHandler))
+	r.Handle("GET", "/aid", s.sessions.Authenticator(s.aidGetHandler))
+	r.Handle("POST", "/aid", s.sessions.Authenticator(s.aidPostHandler))
+	r.Handle("GET", "/aid.json", s.sessions.Authenticator(s.aidJsonGetHandler))
+	r.Handle("POST", "/aid.json", s.sessions.Authenticator(s.aidJsonPostHandler))
+	r.Handle("GET", "/bank", s.sessions.Authenticator(s.bankGetHandler))
+	r.Handle("POST", "/bank", s.sessions.Authenticator(s.bankPostHandler))
+	r.Handle("GET", "/bank.json", s.sessions.Authenticator(s.bankJsonGetHandler))
+	r.Handle("POST", "/bank.json", s.sessions.Authenticator(s.bankJsonPostHandler))
+	r.Handle("GET", "/build", s.sessions.Authenticator(s.buildGetHandler))
+	r.Handle("POST", "/build", s.sessions.Authenticator(s.buildPostHandler))
+	r.Handle("GET", "/build.json", s.sessions.Authenticator(s.buildJsonGetHandler))
+	r.Handle("POST", "/build.json", s.sessions.Authenticator(s.buildJsonPostHandler))
+	r.Handle("GET", "/cash", s.sessions.Authenticator(s.turnsGetHandler(engine.ACTION_CASH)))
+	r.Handle("POST", "/cash", s.sessions.Authenticator(s.turnsPostHandler(engine.ACTION_CASH)))
+	r.Handle("GET", "/cash.json", s.sessions.Authenticator(s.turnsJsonGetHandler()))
+	r.Handle("POST", "/cash.json", s.sessions.Authenticator(s.turnsJsonPostHandler(engine.ACTION_CASH)))
+	r.Handle("GET", "/clan", s.sessions.Authenticator(s.clanGetHandler))
+	r.Handle("POST", "/clan", s.sessions.Authenticator(s.clanPostHandler))
+	r.Handle("GET", "/clan.json", s.sessions.Authenticator(s.clanJsonGetHandler))
+	r.Handle("POST", "/clan.json", s.sessions.Authenticator(s.clanJsonPostHandler))
+	r.Handle("GET", "/clan/aid", s.sessions.Authenticator(s.aidClanGetHandler))
+	r.Handle("GET", "/clan/aid.json", s.sessions.Authenticator(s.aidClanJsonGetHandler))
+	r.Handle("GET", "/clan/forum", s.sessions.Authenticator(s.clanForumGetHandler))
+	r.Handle("POST", "/clan/forum", s.sessions.Authenticator(s.clanForumPostHandler))
+	r.Handle("GET", "/clan/forum.json", s.sessions.Authenticator(s.clanForumJsonGetHandler))
+	r.Handle("POST", "/clan/forum.json", s.sessions.Authenticator(s.clanForumJsonPostHandler))
+	r.Handle("GET", "/clan/manage", s.sessions.Authenticator(s.clanManageGetHandler))
+	r.Handle("POST", "/clan/manage", s.sessions.Authenticator(s.clanManagePostHandler))
+	r.Handle("GET", "/clan/manage.json", s.sessions.Authenticator(s.clanManageJsonGetHandler))
+	r.Handle("POST", "/clan/manage.json", s.sessions.Authenticator(s.clanManageJsonPostHandler))
+	r.Handle("GET", "/demolish", s.sessions.Authenticator(s.demolishGetHandler))
+	r.Handle("POST", "/demolish", s.sessions.Authenticator(s.demolishPostHandler))
+	r.Handle("GET", "/demolish.json", s.sessions.Authenticator(s.buildJsonGetHandler))
+	r.Handle("POST", "/demolish.json", s.sessions.Authenticator(s.demolishJsonPostHandler))
+	r.Handle("GET", "/era", s.sessions.Authenticator(s.eraGetHandler))
+	r.Handle("POST", "/era", s.sessions.Authenticator(s.eraPostHandler))
+	r.Handle("GET", "/era.json", s.sessions.Authenticator(s.eraJsonGetHandler))
+	r.Handle("POST", "/era.json", s.sessions.Authenticator(s.eraJsonPostHandler))
+	r.Handle("GET", "/farm", s.sessions.Authenticator(s.turnsGetHandler(engine.ACTION_FARM)))
+	r.Handle("POST", "/farm", s.sessions.Authenticator(s.turnsPostHandler(engine.ACTION_FARM)))
+	r.Handle("GET", "/farm.json", s.sessions.Authenticator(s.turnsJsonGetHandler()))
+	r.Handle("POST", "/farm.json", s.sessions.Authenticator(s.turnsJsonPostHandler(engine.ACTION_FARM)))
 	r.Handle("GET", "/home", s.sessions.Authenticator(s.homeGetHandler))
+	r.Handle("GET", "/land", s.sessions.Authenticator(s.turnsGetHandler(engine.ACTION_LAND)))
+	r.Handle("POST", "/land", s.sessions.Authenticator(s.turnsPostHandler(engine.ACTION_LAND)))
+	r.Handle("GET", "/land.json", s.sessions.Authenticator(s.turnsJsonGetHandler()))
+	r.Handle("POST", "/land.json", s.sessions.Authenticator(s.turnsJsonPostHandler(engine.ACTION_LAND)))
+	r.Handle("GET", "/lottery", s.sessions.Authenticator(s.lotteryGetHandler))
+	r.Handle("POST", "/lottery", s.sessions.Authenticator(s.lotteryPostHandler))
+	r.Handle("GET", "/lottery.json", s.sessions.Authenticator(s.lotteryJsonGetHandler))
+	r.Handle("POST", "/lottery.json", s.sessions.Authenticator(s.lotteryJsonPostHandler))
+	r.Handle("GET", "/lottery/history", s.sessions.Authenticator(s.lotteryHistoryGetHandler))
+	r.Handle("GET", "/lottery/history.json", s.sessions.Authenticator(s.lotteryHistoryJsonGetHandler))
+	r.Handle("GET", "/manage/empire", s.sessions.Authenticator(s.militaryGetHandler))
+	r.Handle("POST", "/manage/empire", s.sessions.Authenticator(s.militaryPostHandler))
+	r.Handle("GET", "/manage/empire.json", s.sessions.Authenticator(s.militaryJsonGetHandler))
+	r.Handle("POST", "/manage/empire.json", s.sessions.Authenticator(s.militaryJsonPostHandler))
+	r.Handle("GET", "/pvtmarket/buy", s.sessions.Authenticator(s.pvtmarketBuyGetHandler))
+	r.Handle("POST", "/pvtmarket/buy", s.sessions.Authenticator(s.pvtmarketBuyPostHandler))
+	r.Handle("GET", "/pvtmarket/sell", s.sessions.Authenticator(s.pvtmarketSellGetHandler))
+	r.Handle("POST", "/pvtmarket/sell", s.sessions.Authenticator(s.pvtmarketSellPostHandler))
+	r.Handle("GET", "/pvtmarket.json", s.sessions.Authenticator(s.pvtmarketJsonGetHandler))
+	r.Handle("POST", "/pvtmarket.json", s.sessions.Authenticator(s.pvtmarketJsonPostHandler))
 	r.HandleFunc("GET", "/relogin", s.reloginGetHandler)
 	r.HandleFunc("GET", "/login", s.loginGetHandler)
 	r.HandleFunc("POST", "/login", s.loginPostHandler)
@@ -64,7 +131,7 @@ func (s *server) routes() http.Handler {
 		r.Get("/logout", s.logoutHandler)
 	})
 
-	// , s.checkBannedIP(), s.validate_location(valid_locations), s.turnsCrontab(), s.setRoundTimes())
+	// , s.checkBannedIP(), s.validate_location(valid_locations), s.setRoundTimes())
 
 	// admin pages, authentication required, do not cache
 	router.Group(func(r chi.Router) {
@@ -189,6 +256,15 @@ func (s *server) homeGetHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	_, _ = w.Write([]byte(`<li><a href="/signup">Sign Up</a></li>`))
 	_, _ = w.Write([]byte(`</ol>`))
+	if sess.IsValid() && sess.empireId != 0 {
+		// the latest post in the clan's news topic, from php/pages/main.php
+		if news, err := s.clans.News(sess.empireId); err != nil {
+			log.Printf("%s %s: clan news: %v\n", r.Method, r.URL.Path, err)
+		} else if news != nil {
+			_, _ = w.Write([]byte(`<h2>` + html.EscapeString(s.language.Printf("LABEL_CLAN_NEWS")) + `</h2>`))
+			_, _ = w.Write([]byte(`<p>` + strings.ReplaceAll(news.Body, "\n", "<br/>") + `</p>`))
+		}
+	}
 	if sess.IsValid() {
 		_, _ = w.Write([]byte(`<footer>`))
 		_, _ = w.Write([]byte(fmt.Sprintf(`<p>session %s -- %v</p>`, sess.id, time.Now().Sub(sess.started))))
@@ -358,8 +434,6 @@ func (s *server) indexPhpHandler() http.HandlerFunc {
 		log.Printf("todo: implement valid_locations referer logic (%d pages)\n", len(s.valid_locations))
 	}
 
-	showTurnsCrontabError := true
-
 	return func(w http.ResponseWriter, r *http.Request) {
 		log.Printf("%s %s: entered\n", r.Method, r.URL)
 
@@ -374,46 +448,13 @@ func (s *server) indexPhpHandler() http.HandlerFunc {
 			return
 		}
 		// load world variables
-		if s.world == nil {
+		if s.worldVars() == nil {
 			log.Printf("%s %s: world not initialized\n", r.Method, r.URL)
 			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
 			return
 		}
-		// If we're configured for cron-less turn updates, check them now
-		// turns := fetchSomeTurnData()
-		if !TURNS_CRONTAB {
-			if showTurnsCrontabError {
-				log.Printf("%s %s: turns crontab is not implemented\n", r.Method, r.URL)
-				showTurnsCrontabError = false
-			}
-			// turns.doUpdate()
-		}
-
 		// define constants based on round start/end times
-		if sv.Started.Before(s.world.RoundTimeBegin) { // pre-registration
-			sv.Round.Signup = true
-			sv.Round.Started = false
-			sv.Round.Closing = false
-			sv.Round.Finished = false
-			sv.Round.TimeNotice = s.language.Printf("ROUND_WILL_BEGIN", "ROUND_WILL_BEGIN_FORMAT", s.world.RoundTimeBegin.Sub(sv.Started))
-		} else if sv.Started.Before(s.world.RoundTimeClosing) { // normal gameplay
-			sv.Round.Signup = true
-			sv.Round.Started = true
-			sv.Round.Closing = false
-			sv.Round.Finished = false
-		} else if sv.Started.Before(s.world.RoundTimeEnd) { // final week (or so)
-			sv.Round.Signup = false
-			sv.Round.Started = true
-			sv.Round.Closing = true
-			sv.Round.Finished = false
-			sv.Round.TimeNotice = s.language.Printf("ROUND_WILL_END", "ROUND_WILL_BEGIN_FORMAT", s.world.RoundTimeEnd.Sub(sv.Started))
-		} else { // end of round
-			sv.Round.Signup = false
-			sv.Round.Started = false
-			sv.Round.Closing = false
-			sv.Round.Finished = true
-			sv.Round.TimeNotice = s.language.Printf("ROUND_HAS_ENDED")
-		}
+		sv.Round = s.roundData(sv.Started)
 		// todo: inject round data into request context
 
 		if s.check_banned_ip("ip.address") {
@@ -3,14 +3,32 @@
 package main
 
 import (
+	"github.com/mdhender/promisance/app/actions"
+	"github.com/mdhender/promisance/app/aid"
 	"github.com/mdhender/promisance/app/authn"
+	"github.com/mdhender/promisance/app/bank"
+	"github.com/mdhender/promisance/app/build"
 	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/clans"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/eras"
 	"github.com/mdhender/promisance/app/jot"
+	"github.com/mdhender/promisance/app/lottery"
+	"github.com/mdhender/promisance/app/market"
+	"github.com/mdhender/promisance/app/military"
 	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/news"
 	"github.com/mdhender/promisance/app/orm"
+	"html"
 	"log"
+	"math"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 type server struct {
@@ -23,12 +41,25 @@ type server struct {
 	tz              string
 	baseURL         string
 	db              *orm.DB
-	world           *model.World_t
+	actions         *actions.Actions_t // farming, making money, and exploring
+	aid             *aid.Aid_t
+	bank            *bank.Bank_t
+	build           *build.Build_t   // construction, demolition, and dropping land
+	clans           *clans.Clans_t   // creating, joining, and leaving clans
+	e               *engine.Engine_t // shared by the game services, used to deliver the goods attached to news
+	eras            *eras.Eras_t     // advancing and regressing eras
+	lottery         *lottery.Lottery_t
+	market          *market.Market_t     // public and private markets
+	military        *military.Military_t // industry allocation and releasing units
+	worldMu         sync.Mutex
+	world           *model.World_t // cached world variables, use worldVars to read them
+	worldAt         time.Time      // when the cached world variables were loaded
 	valid_locations map[string]int
 	jots            *jot.Factory_t
 	authenticator   *authn.Authenticator
 	language        *LanguageManager_t
 	sessions        *sessionStore_t
+	tables          *engine.Tables_t // race and era definitions
 }
 
 func (s *server) check_banned_ip(ip string) bool {
@@ -47,11 +78,266 @@ func (s *server) checkAuth(r *http.Request, relogin bool) string {
 		return s.language.Printf("ERROR_LOGIN_NO_SESSION")
 	}
 
-	log.Printf("%s %s: todo: checkAuth is assuming caller sets user1 and emp1 and language\n")
+	log.Printf("%s %s: todo: checkAuth is assuming caller sets user1 and emp1 and language\n", r.Method, r.URL)
 
 	return ""
 }
 
+// requireAdmin returns the user for the request's session if they have administrator privileges.
+// Otherwise, it responds with a redirect or an error and returns nil.
+func (s *server) requireAdmin(w http.ResponseWriter, r *http.Request) *model.User_t {
+	sess := s.sessions.Session(r.Context())
+	if !sess.IsValid() {
+		log.Printf("%s %s: session not valid => /login\n", r.Method, r.URL.Path)
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return nil
+	}
+	user, err := s.db.UserFetch(sess.userId)
+	if err != nil {
+		log.Printf("%s %s: userFetch %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return nil
+	} else if !user.Flags.Admin {
+		log.Printf("%s %s: user %d: not an administrator\n", r.Method, r.URL.Path, user.Id)
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return nil
+	}
+	return user
+}
+
+// requireEmpire returns the empire for the request's session.
+// Otherwise, it responds with a redirect or an error and returns nil.
+// Like page_header in php/includes/auth.php, it first gives the empire the goods
+// attached to its news and adds a notice for each to the session. Empires that
+// can't play get a page explaining why.
+func (s *server) requireEmpire(w http.ResponseWriter, r *http.Request) *model.Empire_t {
+	sess := s.sessions.Session(r.Context())
+	if !sess.IsValid() {
+		log.Printf("%s %s: session not valid => /login\n", r.Method, r.URL.Path)
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return nil
+	} else if sess.empireId == 0 {
+		log.Printf("%s %s: user %d: no empire\n", r.Method, r.URL.Path, sess.userId)
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return nil
+	}
+	delivered, err := news.Give(s.db, s.e, s.tables, sess.empireId, time.Now())
+	if err != nil {
+		log.Printf("%s %s: giveNews %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return nil
+	}
+	sess.notices = append(sess.notices, s.newsMessages(delivered)...)
+	emp, err := s.db.EmpireFetch(sess.empireId)
+	if err != nil {
+		log.Printf("%s %s: empireFetch %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return nil
+	}
+	// update the idle time, unless the empire is in a notification state
+	if !emp.Flags.Notify && emp.UserId == sess.userId {
+		if err := s.db.EmpireIdleUpdate(emp, time.Now()); err != nil {
+			log.Printf("%s %s: empireIdleUpdate %v\n", r.Method, r.URL.Path, err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return nil
+		}
+	}
+	notices, err := s.empireUnavailable(emp, r.URL.Path, time.Now())
+	if err != nil {
+		log.Printf("%s %s: empireUnavailable %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return nil
+	} else if notices != nil {
+		log.Printf("%s %s: empire %d: unavailable\n", r.Method, r.URL.Path, emp.Id)
+		if strings.HasSuffix(r.URL.Path, ".json") {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return nil
+		}
+		for _, notice := range sess.notices {
+			notices = append(notices, html.EscapeString(notice))
+		}
+		s.empireUnavailablePage(w, notices)
+		return nil
+	}
+	return emp
+}
+
+// newsMessages translates the messages for the goods delivered from the news.
+func (s *server) newsMessages(messages []engine.Message_t) []string {
+	var list []string
+	for _, msg := range messages {
+		var args []any
+		for _, arg := range msg.Args {
+			switch v := arg.(type) {
+			case int:
+				args = append(args, s.language.Number(v))
+			case model.Empire_t:
+				args = append(args, s.language.Printf("COMMON_EMPIRE_NAMEID", v.Name, s.language.Prenum(v.Id)))
+			default:
+				args = append(args, arg)
+			}
+		}
+		list = append(list, s.language.Printf(msg.Key, args...))
+	}
+	return list
+}
+
+// pageNotices returns the notices to show at the top of the page: the ones
+// added to the session while handling the request, then the page's own.
+func (s *server) pageNotices(r *http.Request, notices []string) []string {
+	return slices.Concat(s.sessions.Session(r.Context()).notices, notices)
+}
+
+// pageNoticesHTML is pageNotices for pages whose notices contain markup.
+// The notices added to the session are escaped; the page's own are not.
+func (s *server) pageNoticesHTML(r *http.Request, notices []string) []string {
+	var list []string
+	for _, notice := range s.sessions.Session(r.Context()).notices {
+		list = append(list, html.EscapeString(notice))
+	}
+	return append(list, notices...)
+}
+
+// empireUnavailable returns the notices explaining why the empire can't be
+// played, from page_header in php/includes/auth.php. It returns nil if the
+// empire can be played. Empires on vacation may still manage their empire.
+// The notices are HTML.
+func (s *server) empireUnavailable(emp *model.Empire_t, path string, now time.Time) ([]string, error) {
+	if emp.Vacation > 0 && emp.Land == 0 {
+		// the empire went on vacation but was killed before being locked
+		emp.Vacation = 0
+	}
+	if emp.Vacation > 0 && path != "/manage/empire" && path != "/manage/empire.json" {
+		start, limit := int(VACATION_START/time.Hour), int(VACATION_LIMIT/time.Hour)
+		hours := func(n int) string {
+			return s.language.Duration(n * 60 * 60)
+		}
+		if emp.Vacation >= start+limit+1 {
+			return []string{s.language.Printf("VACATION_LOCKED", hours(emp.Vacation-1-start)) + s.language.Printf("VACATION_CAN_UNLOCK")}, nil
+		} else if emp.Vacation >= start+1 {
+			return []string{s.language.Printf("VACATION_LOCKED", hours(emp.Vacation-1-start)) + s.language.Printf("VACATION_CANNOT_UNLOCK", hours(start+limit-(emp.Vacation-1)))}, nil
+		}
+		return []string{s.language.Printf("VACATION_NOT_LOCKED", hours(emp.Vacation-1), hours(start-(emp.Vacation-1)))}, nil
+	}
+
+	if emp.Land == 0 {
+		var notices []string
+		if emp.Flags.Notify {
+			notices = append(notices, s.language.Printf("DEAD_NOTIFIED"))
+		} else {
+			emp.Flags.Notify = true
+			if err := s.db.EmpireUpdateFlags(emp); err != nil {
+				return nil, err
+			}
+			notices = append(notices, s.language.Printf("DEAD_UNNOTIFIED"))
+		}
+		// todo: show the empire's news, like printEmpireNews
+		world := s.worldVars()
+		left := int(world.TurnsNext.Sub(now).Seconds())
+		if world.TurnsNext.Before(world.RoundTimeClosing) {
+			notices = append(notices, s.language.Printf("DEAD_DELETE_NOTICE", s.language.Duration(left)))
+		} else {
+			notices = append(notices, s.language.Printf("DEAD_DELETE_NOTICE_END", s.language.Duration(left)))
+		}
+		return notices, nil
+	}
+
+	if emp.Flags.Disable {
+		reason := html.EscapeString(emp.Reason)
+		if reason == "" {
+			if emp.Flags.Multi {
+				reason = s.language.Printf("DISABLED_DEFAULT_REASON_MULTI")
+			} else {
+				reason = s.language.Printf("DISABLED_DEFAULT_REASON")
+			}
+		}
+		empName := s.language.Printf("COMMON_EMPIRE_NAMEID", emp.Name, s.language.Prenum(emp.Id))
+		subject := url.QueryEscape(s.language.Printf("DISABLED_EMAIL_SUBJECT", GAME_TITLE, empName))
+		var notice string
+		if emp.KilledBy != 0 {
+			admin, err := s.db.EmpireFetch(emp.KilledBy)
+			if err != nil {
+				return nil, err
+			}
+			user, err := s.db.UserFetch(admin.UserId)
+			if err != nil {
+				return nil, err
+			}
+			adminName := html.EscapeString(s.language.Printf("COMMON_EMPIRE_NAMEID", admin.Name, s.language.Prenum(admin.Id)))
+			notice = s.language.Printf("DISABLED_BY_ADMIN", adminName, reason, html.EscapeString(user.Email), subject)
+		} else {
+			notice = s.language.Printf("DISABLED_BY_SCRIPT", reason, MAIL_ADMIN, subject)
+		}
+		return []string{notice + s.language.Printf("DISABLED_EMAIL_REMINDER", GAME_TITLE)}, nil
+	}
+
+	if emp.Flags.Delete {
+		return []string{s.language.Printf("HEADER_EMPIRE_DELETED")}, nil
+	}
+	return nil, nil
+}
+
+// empireUnavailablePage writes the page shown instead of the game when the empire can't be played.
+// The notices are HTML.
+func (s *server) empireUnavailablePage(w http.ResponseWriter, notices []string) {
+	title := html.EscapeString(GAME_TITLE)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusForbidden)
+	_, _ = w.Write([]byte(`<!DOCTYPE html><html lang="en"><head><meta charset="UTF-8"><title>` + title + `</title><link rel="stylesheet" href="https://unpkg.com/missing.css@1.1.1"></head><body>`))
+	_, _ = w.Write([]byte(`<h1>` + title + `</h1>`))
+	_, _ = w.Write([]byte(`<main>`))
+	for _, notice := range notices {
+		_, _ = w.Write([]byte(`<p class="box">` + notice + `</p>`))
+	}
+	_, _ = w.Write([]byte(`</main>`))
+	_, _ = w.Write([]byte(`</body>`))
+}
+
+// worldVarsTTL is how long the cached world variables are used before they are reloaded.
+const worldVarsTTL = 30 * time.Second
+
+// worldVars returns a copy of the world variables. The turn updates change them,
+// so the cached copy is reloaded from the database once it is older than worldVarsTTL.
+// If the reload fails, the stale copy is returned. It returns nil only if the
+// world variables have never been loaded.
+func (s *server) worldVars() *model.World_t {
+	s.worldMu.Lock()
+	defer s.worldMu.Unlock()
+	if s.world == nil || time.Since(s.worldAt) >= worldVarsTTL {
+		if world, err := s.db.WorldVarsFetch(); err != nil {
+			log.Printf("server: world vars: %v\n", err)
+		} else {
+			s.world, s.worldAt = world, time.Now()
+		}
+	}
+	if s.world == nil {
+		return nil
+	}
+	world := *s.world
+	return &world
+}
+
+// roundData returns the state of the round at the given time, based on the round times in the world variables.
+func (s *server) roundData(now time.Time) model.RoundData_t {
+	var round model.RoundData_t
+	world := s.worldVars()
+	if now.Before(world.RoundTimeBegin) { // pre-registration
+		round.Signup = true
+		round.TimeNotice = s.language.Printf("ROUND_WILL_BEGIN", "ROUND_WILL_BEGIN_FORMAT", world.RoundTimeBegin.Sub(now))
+	} else if now.Before(world.RoundTimeClosing) { // normal gameplay
+		round.Signup = true
+		round.Started = true
+	} else if now.Before(world.RoundTimeEnd) { // final week (or so)
+		round.Started = true
+		round.Closing = true
+		round.TimeNotice = s.language.Printf("ROUND_WILL_END", "ROUND_WILL_BEGIN_FORMAT", world.RoundTimeEnd.Sub(now))
+	} else { // end of round
+		round.Finished = true
+		round.TimeNotice = s.language.Printf("ROUND_HAS_ENDED")
+	}
+	return round
+}
+
 // fetches a field from the posted form, trims whitespace
 func (s *server) getFormVar(r *http.Request, key, defaultValue string) (string, bool) {
 	values := r.URL.Query()[key]
@@ -67,6 +353,23 @@ func (s *server) getFormVar(r *http.Request, key, defaultValue string) (string,
 	return strings.TrimSpace(values[0]), true
 }
 
+// fetches a non-negative whole number from the posted form, like fixInputNum.
+// thousands separators and currency symbols are ignored; values that are not valid are zero.
+func (s *server) getFormNum(r *http.Request, key string) int {
+	value, _ := s.getFormVar(r, key, "0")
+	value = strings.Map(func(ch rune) rune {
+		if ch == ',' || ch == '$' {
+			return -1
+		}
+		return ch
+	}, value)
+	num, err := strconv.ParseFloat(value, 64)
+	if err != nil || num < 1 {
+		return 0
+	}
+	return int(math.Floor(num))
+}
+
 // log an event into the database
 func (s *server) logmsg(kind PHPLoggingConstants, msg string) {
 	log.Printf("todo: implement logmsg: %q\n", msg)
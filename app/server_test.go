@@ -0,0 +1,172 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"github.com/mdhender/promisance/app/bank"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/orm"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testServer returns a server for a round that is under way, along with an
+// empire and the id of a session for it.
+func testServer(t *testing.T) (*server, *model.Empire_t, string) {
+	t.Helper()
+	db, err := orm.CreateMemoryDatabase()
+	if err != nil {
+		t.Fatalf("database: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	now := time.Now().UTC()
+	if err := db.WorldVarsInitialize(&model.World_t{
+		RoundTimeBegin:   now.Add(-24 * time.Hour),
+		RoundTimeClosing: now.Add(30 * 24 * time.Hour),
+		RoundTimeEnd:     now.Add(37 * 24 * time.Hour),
+		TurnsNext:        now.Add(10 * time.Minute),
+		TurnsNextHourly:  now.Add(time.Hour),
+		TurnsNextDaily:   now.Add(24 * time.Hour),
+	}); err != nil {
+		t.Fatalf("world: %v", err)
+	}
+	s := &server{db: db, tables: engine.DefaultTables()}
+	if s.language, err = NewLanguageManager("en-US"); err != nil {
+		t.Fatalf("language: %v", err)
+	}
+	s.sessions = NewSessionStore(db, time.Hour, "en-US")
+	s.e = engine.New(engineConfig(), nil)
+	if s.bank, err = bank.New(db, s.e); err != nil {
+		t.Fatalf("bank: %v", err)
+	}
+
+	user, err := db.UserCreate("player", "player@example.com")
+	if err != nil {
+		t.Fatalf("user: %v", err)
+	}
+	emp, err := db.EmpireCreate(user, "player", "HUMAN")
+	if err != nil {
+		t.Fatalf("empire: %v", err)
+	}
+	emp.Era, emp.Land, emp.Cash = engine.ERA_PAST, 250, 100_000
+	if err := db.EmpireAttributesUpdate(emp); err != nil {
+		t.Fatalf("empire: %v", err)
+	}
+	sess, err := s.sessions.Create(user.Id, emp.Id)
+	if err != nil {
+		t.Fatalf("session: %v", err)
+	}
+	return s, emp, sess.id
+}
+
+// testRequest sends the request through the session middleware to the handler.
+func testRequest(s *server, handler http.HandlerFunc, method, path, sessionId string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, nil)
+	r.Header.Set("Authorization", "Bearer "+sessionId)
+	w := httptest.NewRecorder()
+	s.sessions.Authenticator(handler).ServeHTTP(w, r)
+	return w
+}
+
+func TestRequireEmpire(t *testing.T) {
+	s, emp, sessionId := testServer(t)
+
+	update := func(change func(*model.Empire_t)) {
+		got, err := s.db.EmpireFetch(emp.Id)
+		if err != nil {
+			t.Fatalf("fetch: %v", err)
+		}
+		change(got)
+		if err := s.db.EmpireAttributesUpdate(got); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+	}
+
+	if w := testRequest(s, s.bankGetHandler, "GET", "/bank", sessionId); w.Code != http.StatusOK {
+		t.Errorf("playable: want %d, got %d", http.StatusOK, w.Code)
+	}
+
+	for _, tc := range []struct {
+		id     string
+		change func(*model.Empire_t)
+		want   string
+	}{
+		{"vacation", func(e *model.Empire_t) { e.Vacation = 3 }, "placed on vacation 2 hours ago"},
+		{"frozen", func(e *model.Empire_t) { e.Vacation = 20 }, "has been frozen for 7 hours"},
+		{"dead", func(e *model.Empire_t) { e.Vacation, e.Land = 0, 0 }, "only to find it is in ruins"},
+		{"still dead", func(e *model.Empire_t) {}, "Your empire has been destroyed"},
+		{"disabled", func(e *model.Empire_t) {
+			e.Land, e.Reason = 250, "<b>cheating</b>"
+			e.Flags.Disable = true
+		}, "automatically disabled due to &lt;b&gt;cheating&lt;/b&gt;"},
+		{"deleted", func(e *model.Empire_t) {
+			e.Flags.Disable, e.Flags.Delete = false, true
+		}, "marked for deletion"},
+	} {
+		update(tc.change)
+		w := testRequest(s, s.bankGetHandler, "GET", "/bank", sessionId)
+		if w.Code != http.StatusForbidden {
+			t.Errorf("%s: want %d, got %d", tc.id, http.StatusForbidden, w.Code)
+		} else if body := w.Body.String(); !strings.Contains(body, tc.want) {
+			t.Errorf("%s: want %q, got %q", tc.id, tc.want, body)
+		}
+		if w := testRequest(s, s.bankJsonGetHandler, "GET", "/bank.json", sessionId); w.Code != http.StatusForbidden {
+			t.Errorf("%s: json: want %d, got %d", tc.id, http.StatusForbidden, w.Code)
+		}
+	}
+
+	// empires on vacation may still manage their empire
+	vacation := &model.Empire_t{Id: emp.Id, Land: 250, Vacation: 20}
+	if notices, err := s.empireUnavailable(vacation, "/manage/empire", time.Now()); err != nil || notices != nil {
+		t.Errorf("manage: want nil, got %v %v", notices, err)
+	}
+}
+
+func TestGiveNews(t *testing.T) {
+	s, emp, sessionId := testServer(t)
+	buyer, err := s.db.EmpireCreate(&model.User_t{Id: 0}, "buyer", "HUMAN")
+	if err != nil {
+		t.Fatalf("buyer: %v", err)
+	}
+
+	// the lottery and the public market leave the goods attached to the news
+	now := time.Now()
+	if err := s.db.EmpireNewsCreate(now,
+		engine.NewNews(engine.EMPNEWS_ATTACH_LOTTERY, nil, emp, 1_000_000),
+		engine.NewNews(engine.EMPNEWS_ATTACH_MARKET_SELL, buyer, emp, int(engine.MARKET_FOOD), 5_000, 100_000, 95_000),
+	); err != nil {
+		t.Fatalf("news: %v", err)
+	}
+
+	w := testRequest(s, s.bankGetHandler, "GET", "/bank", sessionId)
+	if w.Code != http.StatusOK {
+		t.Fatalf("bank: want %d, got %d", http.StatusOK, w.Code)
+	}
+	for _, want := range []string{"You won the lottery!", "You sold 5,000 "} {
+		if body := w.Body.String(); !strings.Contains(body, want) {
+			t.Errorf("bank: want %q, got %q", want, body)
+		}
+	}
+	if got, err := s.db.EmpireFetch(emp.Id); err != nil {
+		t.Fatalf("fetch: %v", err)
+	} else if want := emp.Cash + 1_000_000 + 95_000; got.Cash != want {
+		t.Errorf("cash: want %d, got %d", want, got.Cash)
+	}
+
+	// the goods are only given once
+	w = testRequest(s, s.bankJsonGetHandler, "GET", "/bank.json", sessionId)
+	if w.Code != http.StatusOK {
+		t.Fatalf("json: want %d, got %d", http.StatusOK, w.Code)
+	}
+	if got, err := s.db.EmpireFetch(emp.Id); err != nil {
+		t.Fatalf("fetch: %v", err)
+	} else if want := emp.Cash + 1_000_000 + 95_000; got.Cash != want {
+		t.Errorf("again: want %d, got %d", want, got.Cash)
+	}
+}
@@ -186,6 +186,7 @@ type session_t struct {
 	expired  bool
 	invalid  bool
 	started  time.Time
+	notices  []string // shown at the top of the page, such as the goods delivered from the news
 }
 
 func (s *session_t) IsExpired() bool {
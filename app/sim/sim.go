@@ -0,0 +1,344 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+// Package sim plays scripted strategies for synthetic empires so that
+// changes to the game settings can be evaluated before shipping a round.
+package sim
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/orm"
+	"io"
+	"math/rand"
+	"strconv"
+	"time"
+)
+
+// Config_t holds the settings for a simulation.
+type Config_t struct {
+	Engine     engine.Config_t
+	Tables     *engine.Tables_t
+	Empires    int           // number of empires to create
+	Races      []string      // races assigned to the empires in turn, e.g. "HUMAN"
+	Strategies []string      // strategies assigned to the empires in turn
+	Turns      int           // number of turns each empire plays
+	Step       int           // number of turns given to each empire between samples
+	Interval   time.Duration // game time that passes between samples, used for timed effects
+	Seed       int64
+	Defaults   model.Empire_t // resources, units, and buildings for new empires
+}
+
+// Sample_t is the state of an empire after it has played a number of turns.
+type Sample_t struct {
+	Turn     int    `json:"turn"`
+	Empire   int    `json:"empire"`
+	Name     string `json:"name"`
+	Race     string `json:"race"`
+	Strategy string `json:"strategy"`
+	NetWorth int    `json:"networth"`
+	Land     int    `json:"land"`
+	Cash     int    `json:"cash"`
+	Food     int    `json:"food"`
+	Peasants int    `json:"peasants"`
+	TrpArm   int    `json:"trparm"`
+	TrpLnd   int    `json:"trplnd"`
+	TrpFly   int    `json:"trpfly"`
+	TrpSea   int    `json:"trpsea"`
+	Army     int    `json:"army"` // total military units, not including wizards
+}
+
+// player is an empire controlled by a strategy.
+type player struct {
+	id       int
+	race     string
+	strategy *strategy_t
+}
+
+// Sim_t runs a simulation against a database.
+type Sim_t struct {
+	db      *orm.DB
+	cfg     Config_t
+	e       *engine.Engine_t
+	round   model.RoundData_t
+	now     time.Time
+	players []*player
+}
+
+// New returns a simulation. The database should be empty; an in-memory
+// database from orm.CreateMemoryDatabase is the usual choice.
+func New(db *orm.DB, cfg Config_t) (*Sim_t, error) {
+	if db == nil {
+		return nil, fmt.Errorf("missing database")
+	} else if cfg.Tables == nil {
+		return nil, fmt.Errorf("missing tables")
+	} else if cfg.Empires <= 0 {
+		return nil, fmt.Errorf("empires must be positive")
+	} else if len(cfg.Races) == 0 {
+		return nil, fmt.Errorf("missing races")
+	} else if len(cfg.Strategies) == 0 {
+		return nil, fmt.Errorf("missing strategies")
+	} else if cfg.Turns <= 0 {
+		return nil, fmt.Errorf("turns must be positive")
+	} else if cfg.Step <= 0 {
+		return nil, fmt.Errorf("step must be positive")
+	}
+	for _, name := range cfg.Strategies {
+		if _, err := lookup(name); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return &Sim_t{
+		db:  db,
+		cfg: cfg,
+		e:   engine.New(cfg.Engine, rand.New(rand.NewSource(cfg.Seed))),
+		// signups stay open so that new empires are protected for their first turns
+		round: model.RoundData_t{Signup: true, Started: true},
+		now:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}, nil
+}
+
+// Run creates the empires and plays their turns.
+// It returns a sample for every empire at the start and after every step.
+func (s *Sim_t) Run() ([]Sample_t, error) {
+	if err := s.createEmpires(); err != nil {
+		return nil, err
+	}
+	samples, err := s.sample(0)
+	if err != nil {
+		return nil, err
+	}
+	for turn := 0; turn < s.cfg.Turns; {
+		step := min(s.cfg.Step, s.cfg.Turns-turn)
+		for _, p := range s.players {
+			if err := s.play(p, step); err != nil {
+				return nil, fmt.Errorf("empire %d: turn %d: %w", p.id, turn, err)
+			}
+		}
+		turn += step
+		s.now = s.now.Add(s.cfg.Interval)
+		rows, err := s.sample(turn)
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, rows...)
+	}
+	return samples, nil
+}
+
+// createEmpires creates a user and an empire for each player.
+func (s *Sim_t) createEmpires() error {
+	for n := 1; n <= s.cfg.Empires; n++ {
+		race := s.cfg.Races[(n-1)%len(s.cfg.Races)]
+		strategy, _ := lookup(s.cfg.Strategies[(n-1)%len(s.cfg.Strategies)])
+		user, err := s.db.UserCreate(fmt.Sprintf("sim%04d", n), fmt.Sprintf("sim%04d@example.com", n))
+		if err != nil {
+			return err
+		}
+		emp, err := s.db.EmpireCreate(user, fmt.Sprintf("%s %d", strategy.name, n), race)
+		if err != nil {
+			return err
+		}
+		created := *emp
+		*emp = s.cfg.Defaults
+		emp.Id, emp.UserId, emp.Name, emp.Race = created.Id, created.UserId, created.Name, created.Race
+		// set rank to the empire ID, placing the empire at the very bottom of the score list
+		emp.Era, emp.Rank = engine.ERA_PAST, emp.Id
+		emp.NetWorth = s.e.Networth(emp)
+		if err := s.db.EmpireAttributesUpdate(emp); err != nil {
+			return err
+		}
+		s.players = append(s.players, &player{id: emp.Id, race: race, strategy: strategy})
+	}
+	return nil
+}
+
+// load fetches an empire and its effects from the database.
+func (s *Sim_t) load(id int) (*model.Empire_t, *engine.Effects_t, error) {
+	emp, err := s.db.EmpireFetch(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	fx, err := s.db.EmpireEffectsFetch(id, s.cfg.Tables.Effects)
+	if err != nil {
+		return nil, nil, err
+	}
+	return emp, fx, nil
+}
+
+// save writes an empire and its effects to the database.
+func (s *Sim_t) save(emp *model.Empire_t, fx *engine.Effects_t) error {
+	if err := s.db.EmpireAttributesUpdate(emp); err != nil {
+		return err
+	}
+	return s.db.EmpireEffectsSave(fx)
+}
+
+// play gives the player more turns and lets their strategy use them.
+func (s *Sim_t) play(p *player, turns int) error {
+	emp, fx, err := s.load(p.id)
+	if err != nil {
+		return err
+	}
+	emp.Turns += turns
+	if p.strategy.attack {
+		if err := s.attack(emp, fx); err != nil {
+			return err
+		}
+	}
+	for emp.Turns > 0 {
+		mods := s.cfg.Tables.Modifiers(emp)
+		if _, rate, canBuild := s.e.BuildAmounts(emp, mods.Add(fx.Modifiers(s.now))); min(rate, canBuild) > 0 {
+			// build as much as possible in a single turn
+			updated, _, err := s.e.Build(*emp, mods, engine.Build_t{
+				Buildings: p.strategy.split(min(rate, canBuild)),
+				Round:     s.round,
+				Effects:   fx,
+				Now:       s.now,
+			})
+			if err != nil {
+				return err
+			}
+			*emp = updated
+			continue
+		}
+		action := p.strategy.action
+		if emp.Freeland == 0 && p.strategy.explore {
+			action = engine.ACTION_LAND
+		}
+		updated, report := s.e.TakeTurns(*emp, mods, engine.TakeTurns_t{
+			Turns:   1,
+			Action:  action,
+			Round:   s.round,
+			Effects: fx,
+			Now:     s.now,
+		})
+		if report.Taken == 0 {
+			break
+		}
+		*emp = updated
+	}
+	return s.save(emp, fx)
+}
+
+// attack has the empire attack the empire with the most land.
+// Attacks that the engine refuses are skipped.
+func (s *Sim_t) attack(emp *model.Empire_t, fx *engine.Effects_t) error {
+	if s.e.IsProtected(emp, s.round) || emp.TrpArm+emp.TrpLnd+emp.TrpFly+emp.TrpSea == 0 {
+		return nil
+	}
+	var target *model.Empire_t
+	var targetFx *engine.Effects_t
+	for _, p := range s.players {
+		if p.id == emp.Id {
+			continue
+		}
+		other, otherFx, err := s.load(p.id)
+		if err != nil {
+			return err
+		}
+		if s.e.IsProtected(other, s.round) || other.Land == 0 {
+			continue
+		} else if target == nil || other.Land > target.Land {
+			target, targetFx = other, otherFx
+		}
+	}
+	if target == nil {
+		return nil
+	}
+	att, def, _, err := s.e.Attack(*emp, *target, engine.Attack_t{
+		Type:            engine.ATTACK_STANDARD,
+		SendAll:         true,
+		Tables:          s.cfg.Tables,
+		AttackerEffects: fx,
+		DefenderEffects: targetFx,
+		Round:           s.round,
+		Now:             s.now,
+	})
+	if err != nil {
+		var refused cerr.Error
+		if errors.As(err, &refused) {
+			return nil
+		}
+		return err
+	}
+	*emp = att
+	emp.NetWorth = s.e.Networth(emp)
+	def.NetWorth = s.e.Networth(&def)
+	return s.save(&def, targetFx)
+}
+
+// sample returns the current state of every empire.
+func (s *Sim_t) sample(turn int) ([]Sample_t, error) {
+	var samples []Sample_t
+	for _, p := range s.players {
+		emp, err := s.db.EmpireFetch(p.id)
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, Sample_t{
+			Turn:     turn,
+			Empire:   emp.Id,
+			Name:     emp.Name,
+			Race:     p.race,
+			Strategy: p.strategy.name,
+			NetWorth: emp.NetWorth,
+			Land:     emp.Land,
+			Cash:     emp.Cash,
+			Food:     emp.Food,
+			Peasants: emp.Peasants,
+			TrpArm:   emp.TrpArm,
+			TrpLnd:   emp.TrpLnd,
+			TrpFly:   emp.TrpFly,
+			TrpSea:   emp.TrpSea,
+			Army:     emp.TrpArm + emp.TrpLnd + emp.TrpFly + emp.TrpSea,
+		})
+	}
+	return samples, nil
+}
+
+// WriteCSV writes the samples as CSV with a header row.
+func WriteCSV(w io.Writer, samples []Sample_t) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"turn", "empire", "name", "race", "strategy", "networth", "land", "cash", "food", "peasants", "trparm", "trplnd", "trpfly", "trpsea", "army"}); err != nil {
+		return err
+	}
+	for _, row := range samples {
+		err := cw.Write([]string{
+			strconv.Itoa(row.Turn),
+			strconv.Itoa(row.Empire),
+			row.Name,
+			row.Race,
+			row.Strategy,
+			strconv.Itoa(row.NetWorth),
+			strconv.Itoa(row.Land),
+			strconv.Itoa(row.Cash),
+			strconv.Itoa(row.Food),
+			strconv.Itoa(row.Peasants),
+			strconv.Itoa(row.TrpArm),
+			strconv.Itoa(row.TrpLnd),
+			strconv.Itoa(row.TrpFly),
+			strconv.Itoa(row.TrpSea),
+			strconv.Itoa(row.Army),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes the samples as an indented JSON array.
+func WriteJSON(w io.Writer, samples []Sample_t) error {
+	if samples == nil {
+		samples = []Sample_t{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(samples)
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package sim
+
+import (
+	"bytes"
+	"errors"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/orm"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testConfig() Config_t {
+	return Config_t{
+		Engine: engine.Config_t{
+			BankSaveRate:    4.0,
+			BankLoanRate:    7.5,
+			IndustryMult:    2.5,
+			BuildCost:       3500,
+			TurnsProtection: 200,
+			ClanEnable:      true,
+			MaxAttacks:      30,
+			PvtmTrpArm:      500,
+			PvtmTrpLnd:      1000,
+			PvtmTrpFly:      2000,
+			PvtmTrpSea:      3000,
+			PvtmFood:        30,
+			DropDelay:       12 * 60 * 60,
+		},
+		Tables:     engine.DefaultTables(),
+		Empires:    4,
+		Races:      []string{"HUMAN", "ORC"},
+		Strategies: Strategies(),
+		Turns:      250,
+		Step:       50,
+		Interval:   500 * time.Minute,
+		Seed:       1,
+		Defaults: model.Empire_t{
+			Cash: 100000, Food: 10000, Peasants: 500,
+			TrpArm: 100, TrpLnd: 50, TrpFly: 20, TrpSea: 10,
+			Land: 250, BldPop: 20, BldCash: 10, BldCost: 5, BldFood: 15, Freeland: 200,
+			IndArm: 25, IndLnd: 25, IndFly: 25, IndSea: 25, Health: 100, Tax: 10,
+		},
+	}
+}
+
+func run(t *testing.T, cfg Config_t) []Sample_t {
+	t.Helper()
+	db, err := orm.CreateMemoryDatabase()
+	if err != nil {
+		t.Fatalf("database: %v", err)
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+	s, err := New(db, cfg)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	samples, err := s.Run()
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	return samples
+}
+
+func TestRun(t *testing.T) {
+	cfg := testConfig()
+	samples := run(t, cfg)
+	if want := cfg.Empires * (cfg.Turns/cfg.Step + 1); len(samples) != want {
+		t.Fatalf("samples: want %d, got %d", want, len(samples))
+	}
+	first, last := samples[:cfg.Empires], samples[len(samples)-cfg.Empires:]
+	for i := range first {
+		if last[i].Turn != cfg.Turns {
+			t.Errorf("%s: want turn %d, got %d", last[i].Name, cfg.Turns, last[i].Turn)
+		}
+		if last[i].Land <= first[i].Land || last[i].NetWorth <= first[i].NetWorth {
+			t.Errorf("%s: want growth, got land %d -> %d, networth %d -> %d", last[i].Name, first[i].Land, last[i].Land, first[i].NetWorth, last[i].NetWorth)
+		}
+	}
+	if last[1].Race != "ORC" || last[3].Strategy != "attack" {
+		t.Errorf("assignments: got %+v", last)
+	}
+
+	// the same seed always plays out the same way
+	again := run(t, cfg)
+	for i := range samples {
+		if samples[i] != again[i] {
+			t.Fatalf("deterministic: sample %d: got %+v and %+v", i, samples[i], again[i])
+		}
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	db, err := orm.CreateMemoryDatabase()
+	if err != nil {
+		t.Fatalf("database: %v", err)
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+	cfg := testConfig()
+	cfg.Strategies = []string{"explore", "turtle"}
+	if _, err := New(db, cfg); !errors.Is(err, cerr.ErrUnknownStrategy) {
+		t.Errorf("strategy: want %v, got %v", cerr.ErrUnknownStrategy, err)
+	}
+	cfg = testConfig()
+	cfg.Races = []string{"MARTIAN"}
+	if s, err := New(db, cfg); err != nil {
+		t.Errorf("race: new: %v", err)
+	} else if _, err := s.Run(); err == nil {
+		t.Errorf("race: want error, got nil")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	samples := []Sample_t{{Turn: 10, Empire: 1, Name: "explore 1", Race: "HUMAN", Strategy: "explore", NetWorth: 1000, Land: 300, Army: 180}}
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, samples); err != nil {
+		t.Fatalf("csv: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "turn,empire,name,race,strategy,networth,land") || lines[1] != "10,1,explore 1,HUMAN,explore,1000,300,0,0,0,0,0,0,0,180" {
+		t.Errorf("csv: got %q", buf.String())
+	}
+	buf.Reset()
+	if err := WriteJSON(&buf, samples); err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"networth": 1000`) {
+		t.Errorf("json: got %s", buf.String())
+	}
+}
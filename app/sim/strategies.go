@@ -0,0 +1,69 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package sim
+
+import (
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/engine"
+)
+
+// strategy_t is a scripted way of spending turns.
+// Each turn, the empire builds on any unused land it can afford to,
+// and otherwise spends the turn on its action.
+type strategy_t struct {
+	name    string
+	mix     engine.Buildings_t // relative number of each building to construct
+	action  engine.Action_t    // action for turns not spent building
+	explore bool               // explore instead of the action when there is no unused land
+	attack  bool               // attack another empire before taking turns
+}
+
+var strategies = []*strategy_t{
+	{name: "explore", mix: engine.Buildings_t{BldPop: 2, BldCash: 2, BldFood: 1, BldCost: 1}, action: engine.ACTION_LAND, explore: true},
+	{name: "farm", mix: engine.Buildings_t{BldPop: 1, BldFood: 3}, action: engine.ACTION_FARM, explore: true},
+	{name: "cash", mix: engine.Buildings_t{BldPop: 1, BldCash: 3}, action: engine.ACTION_CASH, explore: true},
+	{name: "attack", mix: engine.Buildings_t{BldPop: 1, BldCash: 1, BldFood: 1, BldTrp: 1, BldDef: 1}, action: engine.ACTION_CASH, explore: true, attack: true},
+}
+
+// Strategies returns the names of the strategies.
+func Strategies() []string {
+	var names []string
+	for _, s := range strategies {
+		names = append(names, s.name)
+	}
+	return names
+}
+
+// lookup returns the strategy with the given name.
+func lookup(name string) (*strategy_t, error) {
+	for _, s := range strategies {
+		if s.name == name {
+			return s, nil
+		}
+	}
+	return nil, cerr.ErrUnknownStrategy
+}
+
+// split divides n buildings between the types in the strategy's mix.
+// Any remainder goes to the first types in the mix.
+func (s *strategy_t) split(n int) engine.Buildings_t {
+	weights := []*int{&s.mix.BldPop, &s.mix.BldCash, &s.mix.BldTrp, &s.mix.BldCost, &s.mix.BldWiz, &s.mix.BldFood, &s.mix.BldDef}
+	var b engine.Buildings_t
+	counts := []*int{&b.BldPop, &b.BldCash, &b.BldTrp, &b.BldCost, &b.BldWiz, &b.BldFood, &b.BldDef}
+	total := 0
+	for _, w := range weights {
+		total += *w
+	}
+	left := n
+	for i, w := range weights {
+		*counts[i] = n * *w / total
+		left -= *counts[i]
+	}
+	for i := 0; left > 0; i = (i + 1) % len(weights) {
+		if *weights[i] > 0 {
+			*counts[i]++
+			left--
+		}
+	}
+	return b
+}
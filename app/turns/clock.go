@@ -0,0 +1,24 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package turns
+
+import "time"
+
+// Clock_i is the source of the current time for the scheduler.
+// Tests inject a fake clock so that turn runs can be driven
+// without waiting on the wall clock.
+type Clock_i interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock is a Clock_i backed by the time package.
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now().UTC()
+}
+
+func (SystemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
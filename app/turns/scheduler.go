@@ -0,0 +1,43 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package turns
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Run performs turn updates in the background until the context is cancelled.
+// It wakes up when the next update is scheduled, but never sleeps longer than
+// poll so that changes to the round schedule are noticed.
+// If afterUpdate is not nil, it is called after every run that processed an interval.
+func (t *Turns_t) Run(ctx context.Context, poll time.Duration, afterUpdate func()) {
+	if poll <= 0 {
+		poll = time.Minute
+	}
+	for {
+		delay := poll
+		if runs, err := t.DoUpdate(); err != nil {
+			// wait out the full poll interval before retrying
+			log.Printf("turns: update: %v\n", err)
+		} else {
+			if runs != 0 && afterUpdate != nil {
+				afterUpdate()
+			}
+			if world, err := t.db.WorldVarsFetch(); err != nil {
+				log.Printf("turns: world vars: %v\n", err)
+			} else if next := NextRun(world); !next.IsZero() {
+				if d := next.Sub(t.clock.Now()); d < delay {
+					delay = max(d, 0)
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.clock.After(delay):
+		}
+	}
+}
@@ -0,0 +1,604 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+// Package turns implements the periodic turn updates from php/classes/prom_turns.php.
+package turns
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/clans"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/lottery"
+	"github.com/mdhender/promisance/app/market"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/orm"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	TURNS_NEED_DAY    = 0x04
+	TURNS_NEED_FINAL  = 0x08
+	TURNS_NEED_HOUR   = 0x02
+	TURNS_NEED_NORMAL = 0x01
+
+	TURN_EVENT = 0 // Normal turn log entry
+	TURN_START = 1 // Start of a turn run
+	TURN_END   = 2 // End of a turn run
+	TURN_ABORT = 3 // Turn run was aborted due to there being nothing to do
+)
+
+// Config_t holds the settings from config.php that control turn updates.
+type Config_t struct {
+	Freq          int           // how often to give turns, in minutes
+	Offset        int           // offset (in minutes) for giving turns, relative to round start
+	OffsetHourly  int           // offset (in minutes) for performing hourly events, relative to round start
+	OffsetDaily   int           // offset (in minutes) for performing daily events, relative to round start
+	Count         int           // how many turns to give during each period
+	Unstore       int           // how many turns to release from Stored Turns at once
+	MaxTurns      int           // Max accumulated turns
+	MaxStored     int           // Max stored turns
+	MaxAttacks    int           // Maximum number of attacks
+	ScoreEnable   bool          // keep score for empires, and rank them by it instead of networth
+	ClanEnable    bool          // Master enable for clans
+	InviteTime    int           // Number of hours before temporary clan invitations expire
+	VacationStart time.Duration // Delay before empire is protected
+	VacationLimit time.Duration // Minimum vacation length (not including start delay)
+	CronLog       bool          // store turn logs in the database
+	PubmktMaxTime int           // Number of hours before items are automatically removed from the public market (-1 to disallow)
+	IdleNew       int           // Remove new empire if idle for this many days before being prompted to validate
+	IdleValidate  int           // Remove new empire if prompted to validate but fails to do so within this many days
+	IdleAbandon   int           // Remove established empire if idle for this many days (and not on vacation or disabled)
+	IdleKilled    int           // Remove dead empire after this many days if never logged in to see notification
+	IdleDelete    int           // Remove deleted empire after this many days (unless still under protection)
+	Seed          int64         // seed for the random number generator used by the lottery drawing
+	Engine        engine.Config_t
+}
+
+// Entry_t is a single turn log message.
+type Entry_t struct {
+	Time     time.Time // when the message was generated
+	Interval time.Time // the turn interval being processed, zero if none
+	Type     int       // one of the TURN_* constants
+	Updates  int       // TURNS_NEED_* flags for the interval being processed
+	Text     string
+}
+
+// Turns_t runs turn updates against the database.
+type Turns_t struct {
+	mu      sync.Mutex
+	db      *orm.DB
+	cfg     Config_t
+	e       *engine.Engine_t
+	clock   Clock_i
+	time    time.Time // turn interval currently being processed
+	updates int       // TURNS_NEED_* flags for the interval currently being processed
+	output  func(Entry_t)
+	pending []*model.TurnLog_t // log entries waiting to be saved to the database
+}
+
+func New(db *orm.DB, cfg Config_t, clock Clock_i) (*Turns_t, error) {
+	if db == nil {
+		return nil, fmt.Errorf("missing database")
+	} else if cfg.Freq <= 0 {
+		return nil, fmt.Errorf("turn frequency must be positive")
+	}
+	if clock == nil {
+		clock = SystemClock{}
+	}
+	return &Turns_t{
+		db:     db,
+		cfg:    cfg,
+		e:      engine.New(cfg.Engine, rand.New(rand.NewSource(cfg.Seed))),
+		clock:  clock,
+		output: logEntry,
+	}, nil
+}
+
+// SetOutput replaces the function that receives turn log messages.
+func (t *Turns_t) SetOutput(fn func(Entry_t)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if fn == nil {
+		fn = logEntry
+	}
+	t.output = fn
+}
+
+// logEntry is the default output, formatted like the log from turns.php.
+func logEntry(e Entry_t) {
+	if e.Type == TURN_END {
+		return
+	}
+	ts := "----/--/-- --:--"
+	if !e.Interval.IsZero() {
+		ts = e.Interval.UTC().Format("2006/01/02 15:04")
+	}
+	log.Printf("turns: [%s] - %s\n", ts, e.Text)
+}
+
+// String formats the entry like a line from the turns.php log.
+func (e Entry_t) String() string {
+	ts := "----/--/-- --:--"
+	if !e.Interval.IsZero() {
+		ts = e.Interval.UTC().Format("2006/01/02 15:04")
+	}
+	return fmt.Sprintf("%s - [%s] - %s", e.Time.UTC().Format("2006/01/02 15:04:05.000000"), ts, e.Text)
+}
+
+// statecho generates a log message for the current turn interval.
+func (t *Turns_t) statecho(kind int, format string, args ...any) {
+	e := Entry_t{
+		Time:     t.clock.Now(),
+		Interval: t.time,
+		Type:     kind,
+		Updates:  t.updates,
+		Text:     fmt.Sprintf(format, args...),
+	}
+	t.output(e)
+	if t.cfg.CronLog {
+		t.pending = append(t.pending, &model.TurnLog_t{Time: e.Time, Interval: e.Interval, Type: e.Type, Updates: e.Updates, Text: e.Text})
+	}
+}
+
+// flush saves any pending log entries to the turnlog table.
+// The entries are written after the turn run has finished because
+// the run holds the database's write lock while it is in progress.
+func (t *Turns_t) flush() {
+	if len(t.pending) == 0 {
+		return
+	}
+	if err := t.db.TurnlogInsert(t.pending...); err != nil {
+		log.Printf("turns: turnlog: %v\n", err)
+	}
+	t.pending = nil
+}
+
+// NeedsUpdate checks what processing needs to be done as of now.
+// It returns a mask of TURNS_NEED_* flags along with the interval that triggered them.
+// Nothing is needed if any of the turn updates haven't been scheduled.
+func NeedsUpdate(world *model.World_t, now time.Time) (int, time.Time) {
+	if world.TurnsNext.IsZero() || world.TurnsNextHourly.IsZero() || world.TurnsNextDaily.IsZero() {
+		return 0, time.Time{}
+	}
+
+	// find out the next thing that needs to be run
+	next := minTime(now, world.RoundTimeEnd, world.TurnsNextDaily, world.TurnsNextHourly, world.TurnsNext)
+
+	var updates int
+	if next.Equal(world.TurnsNext) {
+		updates |= TURNS_NEED_NORMAL
+	}
+	if next.Equal(world.TurnsNextHourly) {
+		updates |= TURNS_NEED_HOUR
+	}
+	if next.Equal(world.TurnsNextDaily) {
+		updates |= TURNS_NEED_DAY
+	}
+	if next.Equal(world.RoundTimeEnd) {
+		updates |= TURNS_NEED_FINAL
+	}
+	return updates, next
+}
+
+// NextRun returns the time of the next scheduled update.
+// It returns the zero time if turn updates are not scheduled.
+func NextRun(world *model.World_t) time.Time {
+	if world.TurnsNext.IsZero() || world.TurnsNextHourly.IsZero() || world.TurnsNextDaily.IsZero() {
+		return time.Time{}
+	}
+	return minTime(world.RoundTimeEnd, world.TurnsNextDaily, world.TurnsNextHourly, world.TurnsNext)
+}
+
+// DoUpdate performs all turn updates that are due, catching up on any
+// intervals that were missed while the server was down.
+// It returns the number of intervals that were processed.
+func (t *Turns_t) DoUpdate() (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	defer t.flush()
+	return t.doUpdate(t.db)
+}
+
+// DryRun performs the updates that are due, reports them, and then rolls
+// back all of the changes.
+// It returns the number of intervals that would have been processed.
+func (t *Turns_t) DryRun() (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var runs int
+	err := t.db.Transaction(func(tx *orm.DB) error {
+		var err error
+		if runs, err = t.doUpdate(tx); err != nil {
+			return err
+		}
+		return cerr.ErrDryRun
+	})
+	// a dry run never saves its log
+	t.pending = nil
+	if err != nil && !errors.Is(err, cerr.ErrDryRun) {
+		return 0, err
+	}
+	return runs, nil
+}
+
+func (t *Turns_t) doUpdate(db *orm.DB) (int, error) {
+	now := t.clock.Now()
+
+	// first off, check if we need to update turns
+	world, err := db.WorldVarsFetch()
+	if err != nil {
+		return 0, err
+	} else if updates, _ := NeedsUpdate(world, now); updates == 0 {
+		return 0, nil
+	} else {
+		t.updates = updates
+	}
+
+	t.time = minTime(world.TurnsNextDaily, world.TurnsNextHourly, world.TurnsNext)
+	t.statecho(TURN_START, "Beginning turn run")
+
+	var runs int
+	var aborted bool
+	err = db.Transaction(func(tx *orm.DB) error {
+		// the world may have changed while we were waiting on the lock,
+		// so reload it and double-check that we still need to do this.
+		world, err := tx.WorldVarsFetch()
+		if err != nil {
+			return err
+		}
+		updates, next := NeedsUpdate(world, now)
+		if updates == 0 {
+			aborted = true
+			return nil
+		}
+
+		// Special actions to perform on the very first turns run
+		if next.Equal(world.RoundTimeBegin.Add(time.Duration(min(t.cfg.Offset, t.cfg.OffsetHourly, t.cfg.OffsetDaily)) * time.Minute)) {
+			t.statecho(TURN_EVENT, "Round has begun!")
+			// reset everybody's idle time so they won't expire below
+			if err := tx.EmpiresResetIdle(now); err != nil {
+				return err
+			}
+		}
+
+		if err := t.adjWorld(tx, world); err != nil {
+			return err
+		}
+
+		// Give out turns and update other stuff as necessary
+		for updates, _ = NeedsUpdate(world, now); updates != 0; updates, _ = NeedsUpdate(world, now) {
+			t.updates = updates
+			if updates&TURNS_NEED_DAY != 0 {
+				t.time = world.TurnsNextDaily
+				if err := t.updateDaily(tx, world, now); err != nil {
+					return err
+				}
+				world.TurnsNextDaily = world.TurnsNextDaily.Add(24 * time.Hour)
+			}
+			if updates&TURNS_NEED_HOUR != 0 {
+				t.time = world.TurnsNextHourly
+				if err := t.updateHourly(tx, world, now); err != nil {
+					return err
+				}
+				world.TurnsNextHourly = world.TurnsNextHourly.Add(time.Hour)
+			}
+			if updates&TURNS_NEED_NORMAL != 0 {
+				t.time = world.TurnsNext
+				if err := t.updateNormal(tx, world, now); err != nil {
+					return err
+				}
+				world.TurnsNext = world.TurnsNext.Add(time.Duration(t.cfg.Freq) * time.Minute)
+			}
+			runs++
+			// exit out once we hit the end of the round
+			if updates&TURNS_NEED_FINAL != 0 {
+				break
+			}
+		}
+
+		if err := t.cleanMarket(tx, world, now); err != nil {
+			return err
+		} else if err := t.cleanEmpires(tx, now); err != nil {
+			return err
+		} else if err := t.cleanClans(tx, now); err != nil {
+			return err
+		} else if err := t.updateRanks(tx); err != nil {
+			return err
+		} else if err := t.checkEndEarly(tx, world, now); err != nil {
+			return err
+		} else if err := t.flushSessions(tx, now); err != nil {
+			return err
+		}
+
+		// Special actions to perform at the end of the round
+		if updates&TURNS_NEED_FINAL != 0 {
+			t.updates = TURNS_NEED_FINAL
+			t.statecho(TURN_EVENT, "Round has ended!")
+			// mark everybody as offline
+			if err := tx.EmpiresClearOnline(); err != nil {
+				return err
+			}
+			// unschedule all turn updates
+			world.TurnsNextDaily = time.Time{}
+			world.TurnsNextHourly = time.Time{}
+			world.TurnsNext = time.Time{}
+			t.time = time.Time{}
+		}
+
+		return tx.WorldVarsUpdate(world)
+	})
+	if err != nil {
+		t.statecho(TURN_ABORT, "Turn run failed: %v", err)
+		t.time, t.updates = time.Time{}, 0
+		return 0, err
+	} else if aborted {
+		t.statecho(TURN_ABORT, "Turn run aborted")
+		t.time, t.updates = time.Time{}, 0
+		return 0, nil
+	}
+	t.statecho(TURN_END, "")
+	t.time, t.updates = time.Time{}, 0
+	return runs, nil
+}
+
+// adjWorld applies the adjustments to world variables queued since the last turn run.
+func (t *Turns_t) adjWorld(tx *orm.DB, world *model.World_t) error {
+	t.statecho(TURN_EVENT, "Adjusting world variables")
+	if n, err := tx.WorldVarsApplyAdjustments(world); err != nil {
+		return err
+	} else if n != 0 {
+		t.statecho(TURN_EVENT, "Applied %d world variable adjustments", n)
+	}
+	return nil
+}
+
+// updateDaily performs the standard daily events.
+func (t *Turns_t) updateDaily(tx *orm.DB, world *model.World_t, now time.Time) error {
+	t.statecho(TURN_EVENT, "Performing daily events")
+
+	t.statecho(TURN_EVENT, "Running lottery")
+	draw, err := lottery.Draw(tx, t.e, world, t.time, now)
+	if err != nil {
+		return err
+	} else if draw.EmpireId != 0 {
+		t.statecho(TURN_EVENT, "- Lottery results: ticket #%d held by %s (#%d), $%d won.", draw.Ticket, draw.EmpireName, draw.EmpireId, draw.Won)
+	} else {
+		t.statecho(TURN_EVENT, "- Lottery results: ticket #%d not held, no winner.", draw.Ticket)
+	}
+
+	if t.cfg.ScoreEnable {
+		if err := tx.EmpiresAccrueScore(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateHourly performs the standard hourly events.
+func (t *Turns_t) updateHourly(tx *orm.DB, world *model.World_t, now time.Time) error {
+	t.statecho(TURN_EVENT, "Performing hourly events")
+
+	// Various counters
+	if err := tx.EmpiresIncrementVacation(); err != nil {
+		return err
+	}
+	if t.cfg.MaxAttacks > 0 {
+		for i := 0; i < 2; i++ {
+			if err := tx.EmpiresRecoverAttacks(); err != nil {
+				return err
+			}
+		}
+	}
+
+	// the vacation check here MUST match the behavior in prom_empire::is_vacation_done()
+	if !now.Before(world.RoundTimeClosing) {
+		hours := int((t.cfg.VacationStart+t.cfg.VacationLimit)/time.Hour) + 1
+		if err := tx.EmpiresEndVacation(now, hours); err != nil {
+			return err
+		}
+	}
+
+	// clean up expired effects
+	if n, err := tx.EmpireEffectsCleanup(now); err != nil {
+		return err
+	} else if n != 0 {
+		t.statecho(TURN_EVENT, "Removed %d expired effects", n)
+	}
+
+	return nil
+}
+
+// updateNormal performs the standard turn update events.
+func (t *Turns_t) updateNormal(tx *orm.DB, world *model.World_t, now time.Time) error {
+	t.statecho(TURN_EVENT, "Performing normal events")
+
+	// Give turns, overflowing into stored turns if necessary
+	if err := tx.EmpiresGiveTurns(t.cfg.Count, t.cfg.Unstore, t.cfg.MaxTurns, t.cfg.MaxStored); err != nil {
+		return err
+	}
+
+	// Reduce maximum private market sell percentage (by 1% base, up to 2% if the player has nothing but bldcash)
+	if err := tx.EmpiresReducePrivateMarketSales(); err != nil {
+		return err
+	}
+	// Refill private market based on bldcost (except for food, which uses bldfood)
+	if err := tx.EmpiresRefillPrivateMarket(); err != nil {
+		return err
+	}
+
+	// Various other counters
+	if t.cfg.MaxAttacks > 0 {
+		if err := tx.EmpiresDecrementAttacks(); err != nil {
+			return err
+		}
+	}
+	if t.cfg.ClanEnable {
+		if err := tx.EmpiresDecrementSharing(); err != nil {
+			return err
+		}
+	}
+
+	// clean up expired clan invites
+	if t.cfg.ClanEnable {
+		if _, err := clans.ExpireInvites(tx, t.cfg.InviteTime, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// cleanMarket returns goods that have been on the public market too long to their sellers.
+func (t *Turns_t) cleanMarket(tx *orm.DB, world *model.World_t, now time.Time) error {
+	if t.cfg.PubmktMaxTime < 0 {
+		return nil
+	}
+	t.statecho(TURN_EVENT, "Cleaning market")
+	if n, err := market.Clean(tx, t.e, t.cfg.PubmktMaxTime, world, now); err != nil {
+		return err
+	} else if n != 0 {
+		t.statecho(TURN_EVENT, "Returned %d expired market shipments", n)
+	}
+	return nil
+}
+
+// cleanEmpires unlinks idle, dead, and deleted empires from their users,
+// from prom_turns::cleanEmpires. Empires in a clan are removed from it first,
+// passing leadership on to another member.
+func (t *Turns_t) cleanEmpires(tx *orm.DB, now time.Time) error {
+	t.statecho(TURN_EVENT, "Unlinking empires")
+	days := func(n int) time.Time {
+		return now.Add(-time.Duration(n) * 24 * time.Hour)
+	}
+	ids, err := tx.EmpiresUnlinkable(orm.UnlinkCutoffs_t{
+		New:      days(t.cfg.IdleNew),
+		Validate: days(t.cfg.IdleValidate),
+		Abandon:  days(t.cfg.IdleAbandon),
+		Killed:   days(t.cfg.IdleKilled),
+		Delete:   days(t.cfg.IdleDelete),
+	}, t.cfg.Engine.TurnsProtection)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		emp, err := tx.EmpireFetch(id)
+		if err != nil {
+			return err
+		}
+		t.statecho(TURN_EVENT, "- Unlinking empire %s (#%d)", emp.Name, emp.Id)
+		var reason string
+		if t.cfg.ClanEnable && emp.CId != 0 {
+			if reason, err = clans.Remove(tx, emp, now); err != nil {
+				return err
+			}
+		}
+		switch {
+		case emp.Flags.Delete:
+			if emp.KilledBy == emp.Id {
+				reason += "deleted"
+				if emp.Reason != "" {
+					emp.Reason = "Self-deleted: " + emp.Reason
+				} else {
+					emp.Reason = "Self-deleted (no reason)"
+				}
+			} else {
+				reason += "nuked"
+				if emp.Reason != "" {
+					emp.Reason = "Deleted by admin: " + emp.Reason
+				} else {
+					emp.Reason = "Deleted by admin (no reason)"
+				}
+			}
+		case !emp.Flags.Valid && !emp.Flags.Notify:
+			reason += "ignored"
+			emp.Reason, emp.KilledBy = "Auto-deleted, never used enough turns to require validation", 0
+		case !emp.Flags.Valid && emp.Flags.Notify:
+			reason += "unvalidated"
+			emp.Reason, emp.KilledBy = "Auto-deleted, failed to validate", 0
+		case emp.Land == 0:
+			reason += "killed"
+			emp.Reason = "Killed"
+		case !emp.Flags.Disable && emp.Vacation == 0:
+			reason += "abandoned"
+			emp.Reason, emp.KilledBy = "Auto-deleted, left idle for an excessive duration", 0
+		default:
+			reason += "indeterminate"
+			emp.Reason, emp.KilledBy = "Unknown deletion reason", 0
+		}
+		if err := tx.EmpireAttributesUpdate(emp); err != nil {
+			return err
+		} else if err := tx.EmpireUnlink(emp); err != nil {
+			return err
+		}
+		t.statecho(TURN_EVENT, "- Empire %s (#%d) unlinked - %s", emp.Name, emp.Id, reason)
+	}
+	return nil
+}
+
+// cleanClans removes clans that have no members left, from prom_turns::cleanClans.
+func (t *Turns_t) cleanClans(tx *orm.DB, now time.Time) error {
+	if !t.cfg.ClanEnable {
+		return nil
+	}
+	t.statecho(TURN_EVENT, "Removing clans")
+	empty, err := tx.ClansEmpty()
+	if err != nil {
+		return err
+	}
+	for _, clan := range empty {
+		// can't actually delete the row, since news table entries will still refer to it
+		if err := clans.Dissolve(tx, clan, now); err != nil {
+			return err
+		}
+		t.statecho(TURN_EVENT, "- Clan %s (#%d) removed.", clan.Name, clan.Id)
+	}
+	return nil
+}
+
+// updateRanks ranks every empire that is linked to a user.
+func (t *Turns_t) updateRanks(tx *orm.DB) error {
+	t.statecho(TURN_EVENT, "Updating ranks")
+	var flags int
+	if t.cfg.ScoreEnable {
+		flags |= orm.HRFLAG_SCORE
+	}
+	_, err := tx.EmpiresUpdateRanks(flags)
+	return err
+}
+
+// checkEndEarly ends the round if only one player is left after the round has started closing.
+func (t *Turns_t) checkEndEarly(tx *orm.DB, world *model.World_t, now time.Time) error {
+	if now.Before(world.RoundTimeClosing) {
+		return nil
+	}
+	numPlayers, err := tx.EmpireActivePlayerCount()
+	if err != nil {
+		return err
+	} else if numPlayers == 1 {
+		t.statecho(TURN_EVENT, "Only one survivor remains - ending round.")
+		world.RoundTimeEnd = now
+	}
+	return nil
+}
+
+// flushSessions marks empires as offline after they've been idle for 3 turn updates
+// or if they've been unlinked.
+func (t *Turns_t) flushSessions(tx *orm.DB, now time.Time) error {
+	t.statecho(TURN_EVENT, "Flushing sessions")
+	return tx.EmpiresFlushOnline(now.Add(-3 * time.Duration(t.cfg.Freq) * time.Minute))
+}
+
+func minTime(t time.Time, times ...time.Time) time.Time {
+	for _, tt := range times {
+		if tt.Before(t) {
+			t = tt
+		}
+	}
+	return t
+}
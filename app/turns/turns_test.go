@@ -0,0 +1,470 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package turns
+
+import (
+	"errors"
+	"github.com/mdhender/promisance/app/cerr"
+	"github.com/mdhender/promisance/app/engine"
+	"github.com/mdhender/promisance/app/model"
+	"github.com/mdhender/promisance/app/orm"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+var testConfig = Config_t{
+	Freq:          10,
+	OffsetDaily:   12 * 60,
+	Count:         1,
+	Unstore:       1,
+	MaxTurns:      250,
+	MaxStored:     100,
+	MaxAttacks:    30,
+	ClanEnable:    true,
+	VacationStart: 12 * time.Hour,
+	VacationLimit: 72 * time.Hour,
+	IdleNew:       3,
+	IdleValidate:  2,
+	IdleAbandon:   14,
+	IdleKilled:    2,
+	IdleDelete:    3,
+}
+
+func TestNeedsUpdate(t *testing.T) {
+	begin := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	world := &model.World_t{
+		RoundTimeBegin:   begin,
+		RoundTimeClosing: begin.Add(30 * 24 * time.Hour),
+		RoundTimeEnd:     begin.Add(35 * 24 * time.Hour),
+		TurnsNext:        begin,
+		TurnsNextHourly:  begin,
+		TurnsNextDaily:   begin.Add(12 * time.Hour),
+	}
+	for _, tc := range []struct {
+		id   int
+		now  time.Time
+		want int
+	}{
+		{1, begin.Add(-time.Minute), 0},
+		{2, begin, TURNS_NEED_NORMAL | TURNS_NEED_HOUR},
+		{3, begin.Add(48 * time.Hour), TURNS_NEED_NORMAL | TURNS_NEED_HOUR},
+		{4, begin.Add(40 * 24 * time.Hour), TURNS_NEED_NORMAL | TURNS_NEED_HOUR},
+	} {
+		if got, _ := NeedsUpdate(world, tc.now); got != tc.want {
+			t.Errorf("%d: needsUpdate: want %d, got %d", tc.id, tc.want, got)
+		}
+	}
+
+	unscheduled := *world
+	unscheduled.TurnsNext = time.Time{}
+	if got, _ := NeedsUpdate(&unscheduled, begin.Add(time.Hour)); got != 0 {
+		t.Errorf("unscheduled: needsUpdate: want 0, got %d", got)
+	}
+
+	final := *world
+	final.TurnsNext, final.TurnsNextHourly, final.TurnsNextDaily = final.RoundTimeEnd, final.RoundTimeEnd, final.RoundTimeEnd.Add(time.Hour)
+	if got, _ := NeedsUpdate(&final, final.RoundTimeEnd); got != TURNS_NEED_NORMAL|TURNS_NEED_HOUR|TURNS_NEED_FINAL {
+		t.Errorf("final: needsUpdate: want %d, got %d", TURNS_NEED_NORMAL|TURNS_NEED_HOUR|TURNS_NEED_FINAL, got)
+	}
+}
+
+func TestDoUpdateCatchesUp(t *testing.T) {
+	begin := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	db, empireId := testDatabase(t, begin)
+
+	// hourly events remove effects that have expired
+	fx := engine.NewEffects(empireId, nil, nil)
+	for name, value := range map[string]int{"m_gate": 0, "m_shield": 7200, "r_newera": 0, "p_sample": 0} {
+		if err := fx.Set(name, value, begin); err != nil {
+			t.Fatalf("effects: %v", err)
+		}
+	}
+	if err := db.EmpireEffectsSave(fx); err != nil {
+		t.Fatalf("effects: %v", err)
+	}
+	// normal events restock the private market and forget recent sales
+	emp, err := db.EmpireFetch(empireId)
+	if err != nil {
+		t.Fatalf("empire: %v", err)
+	}
+	emp.MktPerArm, emp.BldCash = 1000, 125
+	if err := db.EmpireAttributesUpdate(emp); err != nil {
+		t.Fatalf("empire: %v", err)
+	}
+
+	// queued world variable adjustments are applied by the turn run
+	for _, offset := range []int{500, 250} {
+		if err := db.WorldVarsAdjust("lotto_current_jackpot", offset); err != nil {
+			t.Fatalf("adjust: %v", err)
+		}
+	}
+	if err := db.WorldVarsAdjust("turns_next", 1); !errors.Is(err, cerr.ErrUnknownWorldVar) {
+		t.Errorf("adjust: want %v, got %v", cerr.ErrUnknownWorldVar, err)
+	}
+
+	clock := &fakeClock{now: begin.Add(time.Hour)}
+	tt, err := New(db, testConfig, clock)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	tt.SetOutput(func(Entry_t) {})
+
+	// the server was down for the first hour of the round
+	runs, err := tt.DoUpdate()
+	if err != nil {
+		t.Fatalf("doUpdate: %v", err)
+	} else if runs != 7 {
+		t.Errorf("doUpdate: runs: want 7, got %d", runs)
+	}
+
+	world, err := db.WorldVarsFetch()
+	if err != nil {
+		t.Fatalf("world: %v", err)
+	}
+	if want := begin.Add(70 * time.Minute); !world.TurnsNext.Equal(want) {
+		t.Errorf("turns_next: want %v, got %v", want, world.TurnsNext)
+	}
+	if want := begin.Add(2 * time.Hour); !world.TurnsNextHourly.Equal(want) {
+		t.Errorf("turns_next_hourly: want %v, got %v", want, world.TurnsNextHourly)
+	}
+	if want := begin.Add(12 * time.Hour); !world.TurnsNextDaily.Equal(want) {
+		t.Errorf("turns_next_daily: want %v, got %v", want, world.TurnsNextDaily)
+	}
+	if world.LottoCurrentJackpot != 750 {
+		t.Errorf("lotto_current_jackpot: want 750, got %d", world.LottoCurrentJackpot)
+	}
+
+	empire, err := db.EmpireFetch(empireId)
+	if err != nil {
+		t.Fatalf("empire: %v", err)
+	} else if empire.Turns != 7 {
+		t.Errorf("empire: turns: want 7, got %d", empire.Turns)
+	}
+	if empire.MktArm != 14000 || empire.MktFood != 87500 || empire.MktPerArm != 0 {
+		t.Errorf("empire: private market: want 14000, 87500, 0, got %d, %d, %d", empire.MktArm, empire.MktFood, empire.MktPerArm)
+	}
+
+	if fx, err := db.EmpireEffectsFetch(empireId, nil); err != nil {
+		t.Fatalf("effects: %v", err)
+	} else if names := fx.Names(); len(names) != 1 || names[0] != "m_shield" {
+		t.Errorf("effects: want [m_shield], got %v", names)
+	}
+
+	// nothing is due until the next interval
+	if runs, err := tt.DoUpdate(); err != nil {
+		t.Fatalf("doUpdate: %v", err)
+	} else if runs != 0 {
+		t.Errorf("doUpdate: runs: want 0, got %d", runs)
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	begin := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	db, empireId := testDatabase(t, begin)
+	before, err := db.EmpireFetch(empireId)
+	if err != nil {
+		t.Fatalf("empire: %v", err)
+	}
+
+	// log to the database, so the test can show that the dry run doesn't
+	cfg := testConfig
+	cfg.CronLog = true
+	tt, err := New(db, cfg, &fakeClock{now: begin.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	var reported int
+	tt.SetOutput(func(Entry_t) { reported++ })
+
+	// the dry run reports the updates that are due
+	runs, err := tt.DryRun()
+	if err != nil {
+		t.Fatalf("dryRun: %v", err)
+	} else if runs != 7 {
+		t.Errorf("dryRun: runs: want 7, got %d", runs)
+	} else if reported == 0 {
+		t.Errorf("dryRun: want entries reported")
+	}
+
+	// but leaves the schedule, the empires, and the turn log alone
+	world, err := db.WorldVarsFetch()
+	if err != nil {
+		t.Fatalf("world: %v", err)
+	}
+	if !world.TurnsNext.Equal(begin) || !world.TurnsNextHourly.Equal(begin) || !world.TurnsNextDaily.Equal(begin.Add(12*time.Hour)) {
+		t.Errorf("world: want schedule unchanged, got %v %v %v", world.TurnsNext, world.TurnsNextHourly, world.TurnsNextDaily)
+	}
+	if empire, err := db.EmpireFetch(empireId); err != nil {
+		t.Fatalf("empire: %v", err)
+	} else if empire.Turns != before.Turns {
+		t.Errorf("empire: turns: want %d, got %d", before.Turns, empire.Turns)
+	}
+	if entries, err := db.TurnlogFetch(-1, 0, time.Time{}, time.Time{}, 100); err != nil {
+		t.Fatalf("turnlog: %v", err)
+	} else if len(entries) != 0 {
+		t.Errorf("turnlog: want no entries, got %d", len(entries))
+	}
+
+	// so the same updates are still due
+	if runs, err := tt.DoUpdate(); err != nil {
+		t.Fatalf("doUpdate: %v", err)
+	} else if runs != 7 {
+		t.Errorf("doUpdate: runs: want 7, got %d", runs)
+	}
+	if entries, err := db.TurnlogFetch(-1, 0, time.Time{}, time.Time{}, 100); err != nil {
+		t.Fatalf("turnlog: %v", err)
+	} else if len(entries) == 0 {
+		t.Errorf("turnlog: want entries after update")
+	}
+}
+
+func TestDoUpdateEndsRound(t *testing.T) {
+	begin := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	db, _ := testDatabase(t, begin)
+
+	world, err := db.WorldVarsFetch()
+	if err != nil {
+		t.Fatalf("world: %v", err)
+	}
+	world.RoundTimeClosing = begin.Add(20 * time.Minute)
+	world.RoundTimeEnd = begin.Add(30 * time.Minute)
+	if err := db.WorldVarsUpdate(world); err != nil {
+		t.Fatalf("world: %v", err)
+	}
+
+	tt, err := New(db, testConfig, &fakeClock{now: begin.Add(2 * time.Hour)})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	tt.SetOutput(func(Entry_t) {})
+	if _, err := tt.DoUpdate(); err != nil {
+		t.Fatalf("doUpdate: %v", err)
+	}
+
+	world, err = db.WorldVarsFetch()
+	if err != nil {
+		t.Fatalf("world: %v", err)
+	}
+	if !world.TurnsNext.IsZero() || !world.TurnsNextHourly.IsZero() || !world.TurnsNextDaily.IsZero() {
+		t.Errorf("round end: want turns unscheduled, got %v %v %v", world.TurnsNext, world.TurnsNextHourly, world.TurnsNextDaily)
+	}
+}
+
+// testDatabase creates a database with a single player empire for a round starting at begin.
+func TestDailyScore(t *testing.T) {
+	begin := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	db, empireId := testDatabase(t, begin)
+
+	// only players' empires that are neither on vacation nor disabled keep score
+	user, err := db.UserCreate("player2", "player2@example.com")
+	if err != nil {
+		t.Fatalf("user: %v", err)
+	}
+	ids := map[string]int{"player": empireId}
+	for _, name := range []string{"vacation", "disabled", "unowned"} {
+		owner := user
+		if name == "unowned" {
+			owner = &model.User_t{}
+		}
+		emp, err := db.EmpireCreate(owner, name, "HUMAN")
+		if err != nil {
+			t.Fatalf("empire: %v", err)
+		}
+		ids[name] = emp.Id
+	}
+	for name, id := range ids {
+		emp, err := db.EmpireFetch(id)
+		if err != nil {
+			t.Fatalf("empire: %v", err)
+		}
+		emp.Land, emp.NetWorth, emp.Score = 250, 20_000_000, 10
+		if name == "vacation" {
+			emp.Vacation = 1
+		}
+		emp.Flags.Disable = name == "disabled"
+		if err := db.EmpireAttributesUpdate(emp); err != nil {
+			t.Fatalf("empire: %v", err)
+		}
+	}
+
+	cfg := testConfig
+	cfg.ScoreEnable = true
+	tt, err := New(db, cfg, &fakeClock{now: begin.Add(12 * time.Hour)})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	tt.SetOutput(func(Entry_t) {})
+	if _, err := tt.DoUpdate(); err != nil {
+		t.Fatalf("doUpdate: %v", err)
+	}
+	for name, want := range map[string]int{"player": 14, "vacation": 10, "disabled": 10, "unowned": 10} {
+		if emp, err := db.EmpireFetch(ids[name]); err != nil {
+			t.Fatalf("empire: %v", err)
+		} else if emp.Score != want {
+			t.Errorf("%s: score: want %d, got %d", name, want, emp.Score)
+		}
+	}
+}
+
+func TestCleanEmpires(t *testing.T) {
+	begin := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	db, empireId := testDatabase(t, begin)
+	// the first turn run of the round resets the idle time of every empire
+	clock := &fakeClock{now: begin}
+	tt, err := New(db, testConfig, clock)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	tt.SetOutput(func(Entry_t) {})
+	if _, err := tt.DoUpdate(); err != nil {
+		t.Fatalf("doUpdate: %v", err)
+	}
+
+	now := begin.Add(12 * time.Hour)
+	days := func(n int) int {
+		return int(now.Add(-time.Duration(n) * 24 * time.Hour).Unix())
+	}
+
+	user, err := db.UserCreate("player2", "player2@example.com")
+	if err != nil {
+		t.Fatalf("user: %v", err)
+	}
+	ids := map[string]int{"active": empireId}
+	for _, tc := range []struct {
+		name   string
+		change func(*model.Empire_t)
+	}{
+		{"leader", func(e *model.Empire_t) { e.Flags.Valid = true }},
+		{"ignored", func(e *model.Empire_t) { e.Idle = days(4) }},
+		{"unvalidated", func(e *model.Empire_t) { e.Flags.Notify, e.Idle = true, days(3) }},
+		{"abandoned", func(e *model.Empire_t) { e.Flags.Valid, e.Idle = true, days(15) }},
+		{"vacation", func(e *model.Empire_t) { e.Flags.Valid, e.Vacation, e.Idle = true, 20, days(15) }},
+		{"killed", func(e *model.Empire_t) { e.Flags.Valid, e.Land, e.Idle = true, 0, days(3) }},
+		{"deleted", func(e *model.Empire_t) {
+			e.Flags.Valid, e.Flags.Delete, e.KilledBy = true, true, e.Id
+		}},
+		{"admin", func(e *model.Empire_t) { e.Flags.Admin, e.Idle = true, days(30) }},
+	} {
+		emp, err := db.EmpireCreate(user, tc.name, "HUMAN")
+		if err != nil {
+			t.Fatalf("empire: %v", err)
+		}
+		emp.Land, emp.Idle = 250, int(now.Unix())
+		tc.change(emp)
+		if err := db.EmpireAttributesUpdate(emp); err != nil {
+			t.Fatalf("empire: %v", err)
+		}
+		ids[tc.name] = emp.Id
+	}
+
+	// the dead empire leads a clan, so the leadership passes on before it is unlinked
+	clan := &model.Clan_t{Name: "PIRATES", Password: "arr", Members: 2, Leader: ids["killed"]}
+	if err := db.ClanCreate(clan); err != nil {
+		t.Fatalf("clan: %v", err)
+	}
+	for _, name := range []string{"killed", "leader"} {
+		emp, err := db.EmpireFetch(ids[name])
+		if err != nil {
+			t.Fatalf("empire: %v", err)
+		}
+		emp.CId = clan.Id
+		if err := db.EmpireAttributesUpdate(emp); err != nil {
+			t.Fatalf("empire: %v", err)
+		}
+	}
+
+	clock.now = now
+	if _, err := tt.DoUpdate(); err != nil {
+		t.Fatalf("doUpdate: %v", err)
+	}
+	for name, want := range map[string]string{
+		"active":      "",
+		"leader":      "",
+		"ignored":     "Auto-deleted, never used enough turns to require validation",
+		"unvalidated": "Auto-deleted, failed to validate",
+		"abandoned":   "Auto-deleted, left idle for an excessive duration",
+		"vacation":    "",
+		"killed":      "Killed",
+		"deleted":     "Self-deleted (no reason)",
+		"admin":       "",
+	} {
+		emp, err := db.EmpireFetch(ids[name])
+		if err != nil {
+			t.Fatalf("empire: %v", err)
+		}
+		if unlinked := emp.UserId == 0; unlinked != (want != "") {
+			t.Errorf("%s: unlinked: want %v, got %v", name, want != "", unlinked)
+		} else if emp.Reason != want {
+			t.Errorf("%s: reason: want %q, got %q", name, want, emp.Reason)
+		} else if unlinked && emp.OldUserId == 0 {
+			t.Errorf("%s: old user: want set, got 0", name)
+		}
+	}
+
+	if emp, err := db.EmpireFetch(ids["killed"]); err != nil {
+		t.Fatalf("empire: %v", err)
+	} else if emp.CId != 0 {
+		t.Errorf("killed: clan: want 0, got %d", emp.CId)
+	}
+	if got, err := db.ClanFetch(clan.Id); err != nil {
+		t.Fatalf("clan: %v", err)
+	} else if got.Leader != ids["leader"] || got.Members != 1 {
+		t.Errorf("clan: want leader %d with 1 member, got %d with %d", ids["leader"], got.Leader, got.Members)
+	}
+	// as in prom_turns::removeFromClan, the clan only hears about the change in leadership
+	if news, err := db.ClanNews(clan.Id, begin); err != nil {
+		t.Fatalf("news: %v", err)
+	} else if len(news) != 1 || news[0].Event != engine.CLANNEWS_PERM_MEMBER_INHERIT {
+		t.Errorf("news: want only %d, got %+v", engine.CLANNEWS_PERM_MEMBER_INHERIT, news)
+	}
+}
+
+func testDatabase(t *testing.T, begin time.Time) (*orm.DB, int) {
+	t.Helper()
+	db, err := orm.CreateSqliteDatabase(filepath.Join(t.TempDir(), "promisance.sqlite"))
+	if err != nil {
+		t.Fatalf("database: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	if err := db.WorldVarsInitialize(&model.World_t{
+		RoundTimeBegin:   begin,
+		RoundTimeClosing: begin.Add(30 * 24 * time.Hour),
+		RoundTimeEnd:     begin.Add(35 * 24 * time.Hour),
+		TurnsNext:        begin,
+		TurnsNextHourly:  begin,
+		TurnsNextDaily:   begin.Add(12 * time.Hour),
+	}); err != nil {
+		t.Fatalf("world: %v", err)
+	}
+	user, err := db.UserCreate("player1", "player1@example.com")
+	if err != nil {
+		t.Fatalf("user: %v", err)
+	}
+	empire, err := db.EmpireCreate(user, "Player One", "HUMAN")
+	if err != nil {
+		t.Fatalf("empire: %v", err)
+	}
+	empire.Land = 250
+	if err := db.EmpireAttributesUpdate(empire); err != nil {
+		t.Fatalf("empire: %v", err)
+	}
+	return db, empire.Id
+}
@@ -4,15 +4,17 @@ go 1.22.2
 
 require (
 	github.com/go-chi/chi/v5 v5.0.12
+	github.com/google/uuid v1.6.0
 	github.com/mdhender/semver v0.0.0-20240121182447-31da48bf9537
 	github.com/spf13/cobra v1.8.0
 	github.com/syyongx/php2go v0.9.8
+	golang.org/x/crypto v0.22.0
+	gopkg.in/yaml.v3 v3.0.1
 	modernc.org/sqlite v1.29.8
 )
 
 require (
 	github.com/dustin/go-humanize v1.0.1 // indirect
-	github.com/google/uuid v1.6.0 // indirect
 	github.com/hashicorp/golang-lru/v2 v2.0.7 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect